@@ -21,6 +21,7 @@ import (
 	"log"
 	"os"
 	"strings"
+	"time"
 
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/common/expfmt"
@@ -28,6 +29,8 @@ import (
 	"go.uber.org/zap/zapcore"
 
 	"github.com/FerretDB/FerretDB/internal/clientconn"
+	"github.com/FerretDB/FerretDB/internal/handlers/common"
+	"github.com/FerretDB/FerretDB/internal/handlers/pg/pgdb"
 	"github.com/FerretDB/FerretDB/internal/handlers/registry"
 	"github.com/FerretDB/FerretDB/internal/util/debug"
 	"github.com/FerretDB/FerretDB/internal/util/logging"
@@ -47,6 +50,27 @@ var (
 
 	postgreSQLURLF = flag.String("postgresql-url", "postgres://postgres@127.0.0.1:5432/ferretdb", "PostgreSQL URL")
 
+	postgreSQLFetchBufSizeF = flag.Int(
+		"postgresql-fetch-buffer-size", pgdb.FetchedChannelBufSize, "PostgreSQL: number of fetched batches to buffer",
+	)
+	postgreSQLFetchBatchSizeF = flag.Int(
+		"postgresql-fetch-batch-size", pgdb.FetchedSliceCapacity, "PostgreSQL: number of documents in fetched batch",
+	)
+	postgreSQLTTLMonitorIntervalF = flag.Duration(
+		"postgresql-ttl-monitor-interval", time.Minute, "PostgreSQL: interval between deletions of expired documents",
+	)
+	postgreSQLSortByIDF = flag.Bool(
+		"postgresql-sort-by-id", false, "PostgreSQL: return unsorted find results ordered by _id",
+	)
+
+	maxDocumentDepthF = flag.Int(
+		"max-document-depth", common.DefaultMaxDocumentDepth, "maximum nesting depth of inserted documents",
+	)
+
+	defaultBatchSizeF = flag.Int(
+		"default-batch-size", common.DefaultBatchSize, "number of documents in the first batch when batchSize is not set",
+	)
+
 	logLevelF = flag.String("log-level", "<set in initFlags()>", "<set in initFlags()>")
 
 	testConnTimeoutF = flag.Duration("test-conn-timeout", 0, "test: set connection timeout")
@@ -133,7 +157,16 @@ func main() {
 		Ctx:           ctx,
 		Logger:        logger,
 		PostgreSQLURL: *postgreSQLURLF,
-		TigrisURL:     tigrisURL,
+
+		MaxDocumentDepth: *maxDocumentDepthF,
+		DefaultBatchSize: int32(*defaultBatchSizeF),
+
+		PostgreSQLFetchChannelBufSize: *postgreSQLFetchBufSizeF,
+		PostgreSQLFetchSliceCapacity:  *postgreSQLFetchBatchSizeF,
+		PostgreSQLTTLMonitorInterval:  *postgreSQLTTLMonitorIntervalF,
+		PostgreSQLSortByID:            *postgreSQLSortByIDF,
+
+		TigrisURL: tigrisURL,
 	})
 	if err != nil {
 		logger.Fatal(err.Error())
@@ -0,0 +1,1337 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package integration
+
+import (
+	"context"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/FerretDB/FerretDB/integration/setup"
+)
+
+func TestAggregateDocuments(t *testing.T) {
+	setup.SkipForMongoWithReason(t, "$documents stage requires MongoDB 5.1")
+
+	t.Parallel()
+	ctx, collection := setup.Setup(t)
+
+	pipeline := bson.A{
+		bson.D{{"$documents", bson.A{
+			bson.D{{"_id", "one"}, {"v", "foo"}},
+			bson.D{{"_id", "two"}, {"v", "bar"}},
+			bson.D{{"_id", "three"}, {"v", "foo"}},
+		}}},
+		bson.D{{"$match", bson.D{{"v", "foo"}}}},
+	}
+
+	cursor, err := collection.Database().Aggregate(ctx, pipeline)
+	require.NoError(t, err)
+
+	var actual []bson.D
+	require.NoError(t, cursor.All(ctx, &actual))
+
+	expected := []bson.D{
+		{{"_id", "one"}, {"v", "foo"}},
+		{{"_id", "three"}, {"v", "foo"}},
+	}
+	AssertEqualDocumentsSlice(t, expected, actual)
+}
+
+func TestAggregateDocumentsErrors(t *testing.T) {
+	setup.SkipForMongoWithReason(t, "$documents stage requires MongoDB 5.1")
+
+	t.Parallel()
+	ctx, collection := setup.Setup(t)
+
+	for name, tc := range map[string]struct {
+		command bson.D
+		err     *mongo.CommandError
+	}{
+		"NoCollection": {
+			command: bson.D{
+				{"aggregate", 1},
+				{"pipeline", bson.A{bson.D{{"$match", bson.D{}}}}},
+				{"cursor", bson.D{}},
+			},
+			err: &mongo.CommandError{
+				Code:    73,
+				Name:    "InvalidNamespace",
+				Message: "{aggregate: 1} is not valid for '$match'; a collection is required.",
+			},
+		},
+		"DocumentsNotFirst": {
+			command: bson.D{
+				{"aggregate", 1},
+				{"pipeline", bson.A{
+					bson.D{{"$documents", bson.A{}}},
+					bson.D{{"$documents", bson.A{}}},
+				}},
+				{"cursor", bson.D{}},
+			},
+			err: &mongo.CommandError{
+				Code:    40602,
+				Name:    "Location40602",
+				Message: "$documents is only valid as the first stage in a pipeline",
+			},
+		},
+	} {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			err := collection.Database().RunCommand(ctx, tc.command).Err()
+			AssertEqualError(t, *tc.err, err)
+		})
+	}
+}
+
+func TestAggregateMatch(t *testing.T) {
+	t.Parallel()
+	ctx, collection := setup.Setup(t)
+
+	_, err := collection.InsertMany(ctx, []any{
+		bson.D{{"_id", "one"}, {"v", "foo"}},
+		bson.D{{"_id", "two"}, {"v", "bar"}},
+		bson.D{{"_id", "three"}, {"v", "foo"}},
+	})
+	require.NoError(t, err)
+
+	cursor, err := collection.Aggregate(ctx, bson.A{bson.D{{"$match", bson.D{{"v", "foo"}}}}})
+	require.NoError(t, err)
+
+	var actual []bson.D
+	require.NoError(t, cursor.All(ctx, &actual))
+
+	expected := []bson.D{
+		{{"_id", "one"}, {"v", "foo"}},
+		{{"_id", "three"}, {"v", "foo"}},
+	}
+	AssertEqualDocumentsSlice(t, expected, actual)
+}
+
+func TestAggregateMatchSkipLimit(t *testing.T) {
+	t.Parallel()
+	ctx, collection := setup.Setup(t)
+
+	_, err := collection.InsertMany(ctx, []any{
+		bson.D{{"_id", "foo1"}, {"v", "foo"}},
+		bson.D{{"_id", "bar"}, {"v", "bar"}},
+		bson.D{{"_id", "foo2"}, {"v", bson.A{"bar", "foo"}}},
+		bson.D{{"_id", "foo3"}, {"v", "foo"}},
+		bson.D{{"_id", "foo4"}, {"v", "foo"}},
+	})
+	require.NoError(t, err)
+
+	for name, tc := range map[string]struct {
+		pipeline bson.A
+		n        int
+		err      *mongo.CommandError
+	}{
+		"MatchSkipLimit": {
+			pipeline: bson.A{
+				bson.D{{"$match", bson.D{{"v", "foo"}}}},
+				bson.D{{"$skip", 1}},
+				bson.D{{"$limit", 2}},
+			},
+			n: 2,
+		},
+		"MatchSkipAll": {
+			pipeline: bson.A{
+				bson.D{{"$match", bson.D{{"v", "foo"}}}},
+				bson.D{{"$skip", 4}},
+			},
+			n: 0,
+		},
+		"LimitSkip": {
+			pipeline: bson.A{
+				bson.D{{"$limit", 4}},
+				bson.D{{"$skip", 1}},
+			},
+			n: 3,
+		},
+		"LimitSkipEmpty": {
+			pipeline: bson.A{
+				bson.D{{"$limit", 2}},
+				bson.D{{"$skip", 2}},
+			},
+			n: 0,
+		},
+		"LimitZero": {
+			pipeline: bson.A{bson.D{{"$limit", 0}}},
+			err: &mongo.CommandError{
+				Code:    15958,
+				Name:    "Location15958",
+				Message: "the limit must be positive",
+			},
+		},
+		"SkipNegative": {
+			pipeline: bson.A{bson.D{{"$skip", -1}}},
+			err: &mongo.CommandError{
+				Code:    15956,
+				Name:    "Location15956",
+				Message: "Argument to $skip cannot be negative",
+			},
+		},
+	} {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			cursor, err := collection.Aggregate(ctx, tc.pipeline)
+			if tc.err != nil {
+				AssertEqualError(t, *tc.err, err)
+				return
+			}
+			require.NoError(t, err)
+
+			var actual []bson.D
+			require.NoError(t, cursor.All(ctx, &actual))
+			assert.Len(t, actual, tc.n)
+
+			for _, doc := range actual {
+				assert.Contains(t, []any{"foo1", "foo2", "foo3", "foo4", "bar"}, doc.Map()["_id"])
+			}
+		})
+	}
+}
+
+func TestAggregateMatchLimitNotExact(t *testing.T) {
+	t.Parallel()
+	ctx, collection := setup.Setup(t)
+
+	docs := make([]any, 10)
+	for i := range docs {
+		docs[i] = bson.D{{"_id", int32(i)}, {"v", int32(i % 2)}}
+	}
+
+	_, err := collection.InsertMany(ctx, docs)
+	require.NoError(t, err)
+
+	// the filter on a number can't be pushed down exactly, so documents are filtered and limited in memory
+	pipeline := bson.A{
+		bson.D{{"$match", bson.D{{"v", int32(1)}}}},
+		bson.D{{"$limit", 3}},
+	}
+
+	cursor, err := collection.Aggregate(ctx, pipeline)
+	require.NoError(t, err)
+
+	var actual []bson.D
+	require.NoError(t, cursor.All(ctx, &actual))
+	require.Len(t, actual, 3)
+
+	for _, doc := range actual {
+		assert.Equal(t, int32(1), doc.Map()["v"])
+	}
+}
+
+func TestAggregateStageNotImplemented(t *testing.T) {
+	setup.SkipForMongoWithReason(t, "MongoDB implements all stages")
+
+	t.Parallel()
+	ctx, collection := setup.Setup(t)
+
+	_, err := collection.Aggregate(ctx, bson.A{bson.D{{"$bucketAuto", bson.D{}}}})
+
+	expected := mongo.CommandError{
+		Code:    238,
+		Name:    "NotImplemented",
+		Message: "`aggregate` stage \"$bucketAuto\" is not implemented yet",
+	}
+	AssertEqualError(t, expected, err)
+}
+
+func TestAggregateAddFieldsSize(t *testing.T) {
+	t.Parallel()
+	ctx, collection := setup.Setup(t)
+
+	_, err := collection.InsertMany(ctx, []any{
+		bson.D{{"_id", "empty"}, {"v", bson.A{}}},
+		bson.D{{"_id", "one"}, {"v", bson.A{int32(1)}}},
+		bson.D{{"_id", "three"}, {"v", bson.A{int32(1), int32(2), int32(3)}}},
+		bson.D{{"_id", "string"}, {"s", "foo"}},
+	})
+	require.NoError(t, err)
+
+	for name, tc := range map[string]struct {
+		id       string
+		stage    bson.D
+		expected bson.D
+		err      *mongo.CommandError
+	}{
+		"Empty": {
+			id:       "empty",
+			stage:    bson.D{{"$addFields", bson.D{{"size", bson.D{{"$size", "$v"}}}}}},
+			expected: bson.D{{"_id", "empty"}, {"v", bson.A{}}, {"size", int32(0)}},
+		},
+		"One": {
+			id:       "one",
+			stage:    bson.D{{"$addFields", bson.D{{"size", bson.D{{"$size", "$v"}}}}}},
+			expected: bson.D{{"_id", "one"}, {"v", bson.A{int32(1)}}, {"size", int32(1)}},
+		},
+		"Three": {
+			id:       "three",
+			stage:    bson.D{{"$set", bson.D{{"size", bson.D{{"$size", bson.A{"$v"}}}}}}},
+			expected: bson.D{{"_id", "three"}, {"v", bson.A{int32(1), int32(2), int32(3)}}, {"size", int32(3)}},
+		},
+		"Replace": {
+			id:       "three",
+			stage:    bson.D{{"$addFields", bson.D{{"v", bson.D{{"$size", "$v"}}}}}},
+			expected: bson.D{{"_id", "three"}, {"v", int32(3)}},
+		},
+		"Literal": {
+			id:       "string",
+			stage:    bson.D{{"$addFields", bson.D{{"size", bson.D{{"$size", bson.A{bson.A{"a", "b"}}}}}}}},
+			expected: bson.D{{"_id", "string"}, {"s", "foo"}, {"size", int32(2)}},
+		},
+		"NotArray": {
+			id:    "string",
+			stage: bson.D{{"$addFields", bson.D{{"size", bson.D{{"$size", "$s"}}}}}},
+			err: &mongo.CommandError{
+				Code:    17124,
+				Name:    "Location17124",
+				Message: "The argument to $size must be an array. Type of argument is: string",
+			},
+		},
+		"Missing": {
+			id:    "string",
+			stage: bson.D{{"$addFields", bson.D{{"size", bson.D{{"$size", "$v"}}}}}},
+			err: &mongo.CommandError{
+				Code:    17124,
+				Name:    "Location17124",
+				Message: "The argument to $size must be an array. Type of argument is: missing",
+			},
+		},
+		"TooManyArgs": {
+			id:    "one",
+			stage: bson.D{{"$addFields", bson.D{{"size", bson.D{{"$size", bson.A{"$v", "$v"}}}}}}},
+			err: &mongo.CommandError{
+				Code:    16020,
+				Name:    "Location16020",
+				Message: "Expression $size takes exactly 1 arguments. 2 were passed in.",
+			},
+		},
+		"InvalidStage": {
+			id:    "one",
+			stage: bson.D{{"$addFields", "foo"}},
+			err: &mongo.CommandError{
+				Code:    40272,
+				Name:    "Location40272",
+				Message: "$addFields specification stage must be an object, got string",
+			},
+		},
+	} {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			pipeline := bson.A{bson.D{{"$match", bson.D{{"_id", tc.id}}}}, tc.stage}
+
+			cursor, err := collection.Aggregate(ctx, pipeline)
+			if tc.err != nil {
+				AssertEqualError(t, *tc.err, err)
+				return
+			}
+			require.NoError(t, err)
+
+			var actual []bson.D
+			require.NoError(t, cursor.All(ctx, &actual))
+			AssertEqualDocumentsSlice(t, []bson.D{tc.expected}, actual)
+		})
+	}
+}
+
+func TestAggregateAddFieldsDateArithmetic(t *testing.T) {
+	t.Parallel()
+	ctx, collection := setup.Setup(t)
+
+	jan31 := time.Date(2021, time.January, 31, 10, 0, 0, 0, time.UTC)
+	mar1 := time.Date(2021, time.March, 1, 10, 0, 0, 0, time.UTC)
+
+	_, err := collection.InsertMany(ctx, []any{
+		bson.D{{"_id", "jan31"}, {"v", jan31}},
+		bson.D{{"_id", "mar1"}, {"v", mar1}},
+	})
+	require.NoError(t, err)
+
+	for name, tc := range map[string]struct {
+		id       string
+		expr     bson.D
+		expected any
+		err      *mongo.CommandError
+	}{
+		"AddDay": {
+			id:       "jan31",
+			expr:     bson.D{{"$dateAdd", bson.D{{"startDate", "$v"}, {"unit", "day"}, {"amount", 1}}}},
+			expected: primitive.NewDateTimeFromTime(time.Date(2021, time.February, 1, 10, 0, 0, 0, time.UTC)),
+		},
+		"AddMonth": {
+			id:       "jan31",
+			expr:     bson.D{{"$dateAdd", bson.D{{"startDate", "$v"}, {"unit", "month"}, {"amount", 1}}}},
+			expected: primitive.NewDateTimeFromTime(time.Date(2021, time.February, 28, 10, 0, 0, 0, time.UTC)),
+		},
+		"SubtractDay": {
+			id:       "mar1",
+			expr:     bson.D{{"$dateSubtract", bson.D{{"startDate", "$v"}, {"unit", "day"}, {"amount", 1}}}},
+			expected: primitive.NewDateTimeFromTime(time.Date(2021, time.February, 28, 10, 0, 0, 0, time.UTC)),
+		},
+		"SubtractMonth": {
+			id:       "mar1",
+			expr:     bson.D{{"$dateSubtract", bson.D{{"startDate", "$v"}, {"unit", "month"}, {"amount", 1}}}},
+			expected: primitive.NewDateTimeFromTime(time.Date(2021, time.February, 1, 10, 0, 0, 0, time.UTC)),
+		},
+		"Timezone": {
+			id: "jan31",
+			expr: bson.D{{"$dateAdd", bson.D{
+				{"startDate", "$v"}, {"unit", "month"}, {"amount", 1}, {"timezone", "+15:00"},
+			}}},
+			expected: primitive.NewDateTimeFromTime(time.Date(2021, time.February, 28, 10, 0, 0, 0, time.UTC)),
+		},
+		"NullAmount": {
+			id:       "jan31",
+			expr:     bson.D{{"$dateAdd", bson.D{{"startDate", "$v"}, {"unit", "day"}, {"amount", nil}}}},
+			expected: nil,
+		},
+		"MissingArg": {
+			id:   "jan31",
+			expr: bson.D{{"$dateAdd", bson.D{{"startDate", "$v"}, {"unit", "day"}}}},
+			err: &mongo.CommandError{
+				Code:    5166402,
+				Name:    "Location5166402",
+				Message: "$dateAdd requires startDate, unit, and amount to be present",
+			},
+		},
+	} {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			pipeline := bson.A{
+				bson.D{{"$match", bson.D{{"_id", tc.id}}}},
+				bson.D{{"$addFields", bson.D{{"res", tc.expr}}}},
+			}
+
+			cursor, err := collection.Aggregate(ctx, pipeline)
+			if tc.err != nil {
+				AssertEqualError(t, *tc.err, err)
+				return
+			}
+			require.NoError(t, err)
+
+			var actual []bson.D
+			require.NoError(t, cursor.All(ctx, &actual))
+			require.Len(t, actual, 1)
+			assert.Equal(t, tc.expected, actual[0].Map()["res"])
+		})
+	}
+}
+
+func TestAggregateAddFieldsMath(t *testing.T) {
+	t.Parallel()
+	ctx, collection := setup.Setup(t)
+
+	_, err := collection.InsertOne(ctx, bson.D{{"_id", "math"}, {"v", -10.5}, {"i", int32(1250)}})
+	require.NoError(t, err)
+
+	for name, tc := range map[string]struct {
+		expr     bson.D
+		expected any
+		err      *mongo.CommandError
+	}{
+		"RoundHalfEven": {
+			expr:     bson.D{{"$round", "$v"}},
+			expected: -10.0,
+		},
+		"RoundHalfEvenUp": {
+			expr:     bson.D{{"$round", bson.A{11.5, 0}}},
+			expected: 12.0,
+		},
+		"RoundPlace": {
+			expr:     bson.D{{"$round", bson.A{1234.5678, 2}}},
+			expected: 1234.57,
+		},
+		"RoundNegativePlace": {
+			expr:     bson.D{{"$round", bson.A{1234.5678, -2}}},
+			expected: 1200.0,
+		},
+		"RoundNegativePlaceInt": {
+			expr:     bson.D{{"$round", bson.A{"$i", -2}}},
+			expected: int32(1200),
+		},
+		"TruncPlace": {
+			expr:     bson.D{{"$trunc", bson.A{1234.5678, 2}}},
+			expected: 1234.56,
+		},
+		"TruncNegativePlace": {
+			expr:     bson.D{{"$trunc", bson.A{"$v", -1}}},
+			expected: -10.0,
+		},
+		"Ceil": {
+			expr:     bson.D{{"$ceil", "$v"}},
+			expected: -10.0,
+		},
+		"Floor": {
+			expr:     bson.D{{"$floor", "$v"}},
+			expected: -11.0,
+		},
+		"Abs": {
+			expr:     bson.D{{"$abs", "$v"}},
+			expected: 10.5,
+		},
+		"Sqrt": {
+			expr:     bson.D{{"$sqrt", "$i"}},
+			expected: math.Sqrt(1250),
+		},
+		"RoundPlaceOutOfRange": {
+			expr: bson.D{{"$round", bson.A{"$v", 101}}},
+			err: &mongo.CommandError{
+				Code:    51083,
+				Name:    "Location51083",
+				Message: "cannot apply $round with precision value 101 value must be in [-20, 100]",
+			},
+		},
+		"SqrtNegative": {
+			expr: bson.D{{"$sqrt", "$v"}},
+			err: &mongo.CommandError{
+				Code:    28714,
+				Name:    "Location28714",
+				Message: "$sqrt's argument must be greater than or equal to 0",
+			},
+		},
+	} {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			pipeline := bson.A{bson.D{{"$addFields", bson.D{{"res", tc.expr}}}}}
+
+			cursor, err := collection.Aggregate(ctx, pipeline)
+			if tc.err != nil {
+				AssertEqualError(t, *tc.err, err)
+				return
+			}
+			require.NoError(t, err)
+
+			var actual []bson.D
+			require.NoError(t, cursor.All(ctx, &actual))
+			require.Len(t, actual, 1)
+			assert.Equal(t, tc.expected, actual[0].Map()["res"])
+		})
+	}
+}
+
+func TestAggregateGroup(t *testing.T) {
+	t.Parallel()
+	ctx, collection := setup.Setup(t)
+
+	_, err := collection.InsertMany(ctx, []any{
+		bson.D{{"_id", "int32"}, {"k", "a"}, {"v", int32(1)}},
+		bson.D{{"_id", "int64"}, {"k", "a"}, {"v", int64(2)}},
+		bson.D{{"_id", "double"}, {"k", "b"}, {"v", 1.5}},
+		bson.D{{"_id", "int32-max"}, {"k", "b"}, {"v", int32(math.MaxInt32)}},
+		bson.D{{"_id", "string"}, {"k", "c"}, {"v", "foo"}},
+		bson.D{{"_id", "missing"}, {"k", "c"}},
+	})
+	require.NoError(t, err)
+
+	t.Run("Key", func(t *testing.T) {
+		t.Parallel()
+
+		pipeline := bson.A{bson.D{{"$group", bson.D{
+			{"_id", "$k"},
+			{"sum", bson.D{{"$sum", "$v"}}},
+			{"avg", bson.D{{"$avg", "$v"}}},
+			{"min", bson.D{{"$min", "$v"}}},
+			{"max", bson.D{{"$max", "$v"}}},
+		}}}}
+
+		cursor, err := collection.Aggregate(ctx, pipeline)
+		require.NoError(t, err)
+
+		var res []bson.D
+		require.NoError(t, cursor.All(ctx, &res))
+
+		actual := make(map[string]bson.D, len(res))
+		for _, doc := range res {
+			actual[doc.Map()["_id"].(string)] = doc
+		}
+
+		expected := map[string]bson.D{
+			"a": {{"_id", "a"}, {"sum", int64(3)}, {"avg", 1.5}, {"min", int32(1)}, {"max", int64(2)}},
+			"b": {
+				{"_id", "b"},
+				{"sum", float64(math.MaxInt32) + 1.5},
+				{"avg", (float64(math.MaxInt32) + 1.5) / 2},
+				{"min", 1.5},
+				{"max", int32(math.MaxInt32)},
+			},
+			"c": {{"_id", "c"}, {"sum", int32(0)}, {"avg", nil}, {"min", "foo"}, {"max", "foo"}},
+		}
+		assert.Equal(t, expected, actual)
+	})
+
+	t.Run("Null", func(t *testing.T) {
+		t.Parallel()
+
+		pipeline := bson.A{bson.D{{"$group", bson.D{
+			{"_id", nil},
+			{"count", bson.D{{"$sum", 1}}},
+			{"sum", bson.D{{"$sum", "$v"}}},
+		}}}}
+
+		cursor, err := collection.Aggregate(ctx, pipeline)
+		require.NoError(t, err)
+
+		var actual []bson.D
+		require.NoError(t, cursor.All(ctx, &actual))
+
+		expected := []bson.D{{{"_id", nil}, {"count", int32(6)}, {"sum", float64(math.MaxInt32) + 4.5}}}
+		assert.Equal(t, expected, actual)
+	})
+
+	t.Run("NAccumulators", func(t *testing.T) {
+		t.Parallel()
+
+		pipeline := bson.A{
+			bson.D{{"$group", bson.D{
+				{"_id", "$k"},
+				{"top", bson.D{{"$topN", bson.D{{"n", 1}, {"sortBy", bson.D{{"v", -1}}}, {"output", "$_id"}}}}},
+				{"min", bson.D{{"$minN", bson.D{{"input", "$v"}, {"n", 3}}}}},
+			}}},
+			bson.D{{"$sort", bson.D{{"_id", 1}}}},
+		}
+
+		cursor, err := collection.Aggregate(ctx, pipeline)
+		require.NoError(t, err)
+
+		var actual []bson.D
+		require.NoError(t, cursor.All(ctx, &actual))
+
+		// all groups have fewer than 3 values
+		expected := []bson.D{
+			{{"_id", "a"}, {"top", bson.A{"int64"}}, {"min", bson.A{int32(1), int64(2)}}},
+			{{"_id", "b"}, {"top", bson.A{"int32-max"}}, {"min", bson.A{1.5, int32(math.MaxInt32)}}},
+			{{"_id", "c"}, {"top", bson.A{"string"}}, {"min", bson.A{"foo"}}},
+		}
+		assert.Equal(t, expected, actual)
+	})
+
+	t.Run("StdDev", func(t *testing.T) {
+		t.Parallel()
+
+		pipeline := bson.A{
+			bson.D{{"$match", bson.D{{"k", bson.D{{"$in", bson.A{"a", "c"}}}}}}},
+			bson.D{{"$group", bson.D{
+				{"_id", "$k"},
+				{"pop", bson.D{{"$stdDevPop", "$v"}}},
+				{"samp", bson.D{{"$stdDevSamp", "$v"}}},
+			}}},
+			bson.D{{"$sort", bson.D{{"_id", 1}}}},
+		}
+
+		cursor, err := collection.Aggregate(ctx, pipeline)
+		require.NoError(t, err)
+
+		var actual []bson.D
+		require.NoError(t, cursor.All(ctx, &actual))
+
+		// values of "a" are 1 and 2, "c" has no numeric values
+		expected := []bson.D{
+			{{"_id", "a"}, {"pop", 0.5}, {"samp", math.Sqrt(0.5)}},
+			{{"_id", "c"}, {"pop", nil}, {"samp", nil}},
+		}
+		assert.Equal(t, expected, actual)
+	})
+
+	t.Run("Errors", func(t *testing.T) {
+		t.Parallel()
+
+		for name, tc := range map[string]struct {
+			stage bson.D
+			err   mongo.CommandError
+		}{
+			"MissingID": {
+				stage: bson.D{{"$group", bson.D{{"count", bson.D{{"$sum", 1}}}}}},
+				err: mongo.CommandError{
+					Code:    15955,
+					Name:    "Location15955",
+					Message: "a group specification must include an _id",
+				},
+			},
+			"NotAccumulator": {
+				stage: bson.D{{"$group", bson.D{{"_id", nil}, {"count", 1}}}},
+				err: mongo.CommandError{
+					Code:    40234,
+					Name:    "Location40234",
+					Message: "The field 'count' must be an accumulator object",
+				},
+			},
+		} {
+			name, tc := name, tc
+			t.Run(name, func(t *testing.T) {
+				t.Parallel()
+
+				_, err := collection.Aggregate(ctx, bson.A{tc.stage})
+				AssertEqualError(t, tc.err, err)
+			})
+		}
+	})
+}
+
+func TestAggregateGroupNullID(t *testing.T) {
+	t.Parallel()
+	ctx, collection := setup.Setup(t)
+
+	pipeline := bson.A{bson.D{{"$group", bson.D{
+		{"_id", nil},
+		{"count", bson.D{{"$sum", 1}}},
+		{"total", bson.D{{"$sum", "$v"}}},
+	}}}}
+
+	// there are no groups for an empty collection
+	cursor, err := collection.Aggregate(ctx, pipeline)
+	require.NoError(t, err)
+
+	var actual []bson.D
+	require.NoError(t, cursor.All(ctx, &actual))
+	assert.Empty(t, actual)
+
+	_, err = collection.InsertMany(ctx, []any{
+		bson.D{{"_id", "null"}, {"k", nil}, {"v", int32(1)}},
+		bson.D{{"_id", "missing"}, {"v", int64(2)}},
+		bson.D{{"_id", "value"}, {"k", "a"}, {"v", 3.5}},
+		bson.D{{"_id", "no-value"}, {"k", "a"}},
+	})
+	require.NoError(t, err)
+
+	// all documents are in a single group
+	cursor, err = collection.Aggregate(ctx, pipeline)
+	require.NoError(t, err)
+
+	require.NoError(t, cursor.All(ctx, &actual))
+	assert.Equal(t, []bson.D{{{"_id", nil}, {"count", int32(4)}, {"total", 6.5}}}, actual)
+
+	// null and missing keys are in the same group, unlike the literal null _id grouping all documents
+	cursor, err = collection.Aggregate(ctx, bson.A{
+		bson.D{{"$group", bson.D{{"_id", "$k"}, {"count", bson.D{{"$sum", 1}}}}}},
+		bson.D{{"$sort", bson.D{{"_id", 1}}}},
+	})
+	require.NoError(t, err)
+
+	require.NoError(t, cursor.All(ctx, &actual))
+	assert.Equal(t, []bson.D{{{"_id", nil}, {"count", int32(2)}}, {{"_id", "a"}, {"count", int32(2)}}}, actual)
+}
+
+func TestAggregateSort(t *testing.T) {
+	t.Parallel()
+	ctx, collection := setup.Setup(t)
+
+	_, err := collection.InsertMany(ctx, []any{
+		bson.D{{"_id", "1"}, {"k", "b"}, {"v", bson.D{{"w", int32(2)}}}},
+		bson.D{{"_id", "2"}, {"k", "a"}, {"v", bson.D{{"w", int32(3)}}}},
+		bson.D{{"_id", "3"}, {"v", bson.D{{"w", 1.5}}}},
+		bson.D{{"_id", "4"}, {"k", "a"}, {"v", bson.D{{"w", int32(1)}}}},
+		bson.D{{"_id", "5"}, {"k", "b"}, {"v", int32(1)}},
+	})
+	require.NoError(t, err)
+
+	for name, tc := range map[string]struct {
+		sort     bson.D
+		expected []any
+		err      *mongo.CommandError
+	}{
+		"Missing": {
+			sort:     bson.D{{"k", 1}, {"_id", 1}},
+			expected: []any{"3", "2", "4", "1", "5"},
+		},
+		"Dotted": {
+			sort:     bson.D{{"v.w", -1}},
+			expected: []any{"2", "1", "3", "4", "5"},
+		},
+		"MultipleKeys": {
+			sort:     bson.D{{"k", -1}, {"v.w", 1}},
+			expected: []any{"5", "1", "4", "2", "3"},
+		},
+		"BadOrder": {
+			sort: bson.D{{"k", 2}},
+			err: &mongo.CommandError{
+				Code:    15975,
+				Name:    "Location15975",
+				Message: "$sort key ordering must be 1 (for ascending) or -1 (for descending)",
+			},
+		},
+		"Empty": {
+			sort: bson.D{},
+			err: &mongo.CommandError{
+				Code:    15976,
+				Name:    "Location15976",
+				Message: "$sort stage must have at least one sort key",
+			},
+		},
+	} {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			cursor, err := collection.Aggregate(ctx, bson.A{bson.D{{"$sort", tc.sort}}})
+			if tc.err != nil {
+				AssertEqualError(t, *tc.err, err)
+				return
+			}
+			require.NoError(t, err)
+
+			var res []bson.D
+			require.NoError(t, cursor.All(ctx, &res))
+			assert.Equal(t, tc.expected, CollectIDs(t, res))
+		})
+	}
+}
+
+func TestAggregateSortSkipLimit(t *testing.T) {
+	t.Parallel()
+	ctx, collection := setup.Setup(t)
+
+	docs := make([]any, 7)
+	for i := range docs {
+		docs[i] = bson.D{{"_id", int32(i)}, {"v", int32(len(docs) - i)}}
+	}
+
+	_, err := collection.InsertMany(ctx, docs)
+	require.NoError(t, err)
+
+	for name, tc := range map[string]struct {
+		pipeline bson.A
+		expected []any
+	}{
+		"FirstPage": {
+			pipeline: bson.A{
+				bson.D{{"$sort", bson.D{{"v", 1}}}},
+				bson.D{{"$skip", 0}},
+				bson.D{{"$limit", 3}},
+			},
+			expected: []any{int32(6), int32(5), int32(4)},
+		},
+		"SecondPage": {
+			pipeline: bson.A{
+				bson.D{{"$sort", bson.D{{"v", 1}}}},
+				bson.D{{"$skip", 3}},
+				bson.D{{"$limit", 3}},
+			},
+			expected: []any{int32(3), int32(2), int32(1)},
+		},
+		"LastPage": {
+			pipeline: bson.A{
+				bson.D{{"$sort", bson.D{{"v", 1}}}},
+				bson.D{{"$skip", 6}},
+				bson.D{{"$limit", 3}},
+			},
+			expected: []any{int32(0)},
+		},
+		"PastLastPage": {
+			pipeline: bson.A{
+				bson.D{{"$sort", bson.D{{"v", 1}}}},
+				bson.D{{"$skip", 9}},
+				bson.D{{"$limit", 3}},
+			},
+			expected: []any{},
+		},
+		"LimitSkip": {
+			pipeline: bson.A{
+				bson.D{{"$sort", bson.D{{"_id", -1}}}},
+				bson.D{{"$limit", 4}},
+				bson.D{{"$skip", 2}},
+			},
+			expected: []any{int32(4), int32(3)},
+		},
+		"SkipSort": {
+			pipeline: bson.A{
+				bson.D{{"$sort", bson.D{{"_id", 1}}}},
+				bson.D{{"$skip", 4}},
+				bson.D{{"$sort", bson.D{{"v", 1}}}},
+			},
+			expected: []any{int32(6), int32(5), int32(4)},
+		},
+	} {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			cursor, err := collection.Aggregate(ctx, tc.pipeline)
+			require.NoError(t, err)
+
+			var res []bson.D
+			require.NoError(t, cursor.All(ctx, &res))
+			assert.Equal(t, tc.expected, CollectIDs(t, res))
+		})
+	}
+}
+
+func TestAggregateProject(t *testing.T) {
+	t.Parallel()
+	ctx, collection := setup.Setup(t)
+
+	_, err := collection.InsertMany(ctx, []any{
+		bson.D{{"_id", "1"}, {"a", int32(1)}, {"b", bson.D{{"c", "foo"}, {"d", "bar"}}}, {"e", bson.A{int32(1), int32(2)}}},
+		bson.D{{"_id", "2"}, {"a", int32(2)}},
+	})
+	require.NoError(t, err)
+
+	for name, tc := range map[string]struct {
+		project  bson.D
+		expected []bson.D
+		err      *mongo.CommandError
+	}{
+		"Inclusion": {
+			project: bson.D{{"a", 1}, {"b.c", true}},
+			expected: []bson.D{
+				{{"_id", "1"}, {"a", int32(1)}, {"b", bson.D{{"c", "foo"}}}},
+				{{"_id", "2"}, {"a", int32(2)}},
+			},
+		},
+		"Exclusion": {
+			project: bson.D{{"_id", 0}, {"b", 0}, {"e", false}},
+			expected: []bson.D{
+				{{"a", int32(1)}},
+				{{"a", int32(2)}},
+			},
+		},
+		"Computed": {
+			project: bson.D{{"_id", 0}, {"renamed", "$b.c"}, {"constant", "baz"}},
+			expected: []bson.D{
+				{{"renamed", "foo"}, {"constant", "baz"}},
+				{{"constant", "baz"}},
+			},
+		},
+		"Mixed": {
+			project: bson.D{{"a", 1}, {"e", 0}},
+			err: &mongo.CommandError{
+				Code:    31254,
+				Name:    "Location31254",
+				Message: "Invalid $project :: caused by :: Cannot do exclusion on field e in inclusion projection",
+			},
+		},
+		"Empty": {
+			project: bson.D{},
+			err: &mongo.CommandError{
+				Code:    51272,
+				Name:    "Location51272",
+				Message: "Invalid $project :: caused by :: projection specification must have at least one field",
+			},
+		},
+	} {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			pipeline := bson.A{
+				bson.D{{"$sort", bson.D{{"_id", 1}}}},
+				bson.D{{"$project", tc.project}},
+			}
+
+			cursor, err := collection.Aggregate(ctx, pipeline)
+			if tc.err != nil {
+				AssertEqualError(t, *tc.err, err)
+				return
+			}
+			require.NoError(t, err)
+
+			var actual []bson.D
+			require.NoError(t, cursor.All(ctx, &actual))
+			assert.Equal(t, tc.expected, actual)
+		})
+	}
+}
+
+func TestAggregateCount(t *testing.T) {
+	t.Parallel()
+	ctx, collection := setup.Setup(t)
+
+	_, err := collection.InsertMany(ctx, []any{
+		bson.D{{"_id", "1"}, {"v", int32(1)}},
+		bson.D{{"_id", "2"}, {"v", int32(2)}},
+		bson.D{{"_id", "3"}, {"v", int32(3)}},
+	})
+	require.NoError(t, err)
+
+	for name, tc := range map[string]struct {
+		pipeline bson.A
+		expected []bson.D
+		err      *mongo.CommandError
+	}{
+		"All": {
+			pipeline: bson.A{bson.D{{"$count", "total"}}},
+			expected: []bson.D{{{"total", int32(3)}}},
+		},
+		"Match": {
+			pipeline: bson.A{
+				bson.D{{"$match", bson.D{{"v", bson.D{{"$gt", int32(1)}}}}}},
+				bson.D{{"$count", "total"}},
+			},
+			expected: []bson.D{{{"total", int32(2)}}},
+		},
+		"NoDocuments": {
+			pipeline: bson.A{
+				bson.D{{"$match", bson.D{{"v", int32(42)}}}},
+				bson.D{{"$count", "total"}},
+			},
+			expected: []bson.D{},
+		},
+		"Empty": {
+			pipeline: bson.A{bson.D{{"$count", ""}}},
+			err: &mongo.CommandError{
+				Code:    40157,
+				Name:    "Location40157",
+				Message: "the count field must be a non-empty string",
+			},
+		},
+		"Prefix": {
+			pipeline: bson.A{bson.D{{"$count", "$total"}}},
+			err: &mongo.CommandError{
+				Code:    40158,
+				Name:    "Location40158",
+				Message: "the count field cannot be a $-prefixed path",
+			},
+		},
+		"Dot": {
+			pipeline: bson.A{bson.D{{"$count", "a.total"}}},
+			err: &mongo.CommandError{
+				Code:    40160,
+				Name:    "Location40160",
+				Message: "the count field cannot contain '.'",
+			},
+		},
+	} {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			cursor, err := collection.Aggregate(ctx, tc.pipeline)
+			if tc.err != nil {
+				AssertEqualError(t, *tc.err, err)
+				return
+			}
+			require.NoError(t, err)
+
+			actual := []bson.D{}
+			require.NoError(t, cursor.All(ctx, &actual))
+			assert.Equal(t, tc.expected, actual)
+		})
+	}
+}
+
+func TestAggregateGetMore(t *testing.T) {
+	t.Parallel()
+	ctx, collection := setup.Setup(t)
+
+	docs := make([]any, 5)
+	for i := range docs {
+		docs[i] = bson.D{{"_id", int32(i)}, {"v", "foo"}}
+	}
+	_, err := collection.InsertMany(ctx, docs)
+	require.NoError(t, err)
+
+	pipeline := bson.A{bson.D{{"$match", bson.D{{"v", "foo"}}}}}
+
+	t.Run("Driver", func(t *testing.T) {
+		t.Parallel()
+
+		cursor, err := collection.Aggregate(ctx, pipeline, options.Aggregate().SetBatchSize(2))
+		require.NoError(t, err)
+
+		var actual []bson.D
+		require.NoError(t, cursor.All(ctx, &actual))
+		assert.Len(t, actual, 5)
+	})
+
+	t.Run("Commands", func(t *testing.T) {
+		t.Parallel()
+
+		var res bson.D
+		err := collection.Database().RunCommand(ctx, bson.D{
+			{"aggregate", collection.Name()},
+			{"pipeline", pipeline},
+			{"cursor", bson.D{{"batchSize", 2}}},
+		}).Decode(&res)
+		require.NoError(t, err)
+
+		cursor := res.Map()["cursor"].(bson.D).Map()
+		assert.Len(t, cursor["firstBatch"], 2)
+		assert.Equal(t, collection.Database().Name()+"."+collection.Name(), cursor["ns"])
+
+		id := cursor["id"].(int64)
+		require.NotZero(t, id)
+
+		var seen int
+		for id != 0 {
+			err = collection.Database().RunCommand(ctx, bson.D{
+				{"getMore", id},
+				{"collection", collection.Name()},
+				{"batchSize", 2},
+			}).Decode(&res)
+			require.NoError(t, err)
+
+			cursor = res.Map()["cursor"].(bson.D).Map()
+			batch := cursor["nextBatch"].(bson.A)
+			assert.LessOrEqual(t, len(batch), 2)
+			seen += len(batch)
+			id = cursor["id"].(int64)
+		}
+
+		assert.Equal(t, 3, seen)
+	})
+
+	t.Run("CursorNotFound", func(t *testing.T) {
+		t.Parallel()
+
+		err := collection.Database().RunCommand(ctx, bson.D{
+			{"getMore", int64(1234567)},
+			{"collection", collection.Name()},
+		}).Err()
+
+		expected := mongo.CommandError{
+			Code:    43,
+			Name:    "CursorNotFound",
+			Message: "cursor id 1234567 not found",
+		}
+		AssertEqualError(t, expected, err)
+	})
+}
+
+func TestAggregateChangeStream(t *testing.T) {
+	setup.SkipForTigris(t)
+	setup.SkipForMongoWithReason(t, "change streams require a replica set")
+
+	t.Parallel()
+	ctx, collection := setup.Setup(t)
+
+	_, err := collection.InsertOne(ctx, bson.D{{"_id", "before"}})
+	require.NoError(t, err)
+
+	stream, err := collection.Watch(ctx, mongo.Pipeline{})
+	require.NoError(t, err)
+	defer stream.Close(ctx)
+
+	_, err = collection.InsertMany(ctx, []any{bson.D{{"_id", "first"}, {"v", int32(1)}}, bson.D{{"_id", "second"}}})
+	require.NoError(t, err)
+
+	nextEvent := func(t *testing.T, stream *mongo.ChangeStream) bson.D {
+		t.Helper()
+
+		nextCtx, cancel := context.WithTimeout(ctx, 10*time.Second)
+		defer cancel()
+
+		require.True(t, stream.Next(nextCtx), "%v", stream.Err())
+
+		var event bson.D
+		require.NoError(t, stream.Decode(&event))
+
+		return event
+	}
+
+	event := nextEvent(t, stream).Map()
+	assert.Equal(t, "insert", event["operationType"])
+	assert.Equal(t, bson.D{{"_id", "first"}, {"v", int32(1)}}, event["fullDocument"])
+	assert.Equal(t, bson.D{{"db", collection.Database().Name()}, {"coll", collection.Name()}}, event["ns"])
+	assert.Equal(t, bson.D{{"_id", "first"}}, event["documentKey"])
+	assert.NotEmpty(t, stream.ResumeToken())
+
+	resumeToken := stream.ResumeToken()
+
+	event = nextEvent(t, stream).Map()
+	assert.Equal(t, bson.D{{"_id", "second"}}, event["documentKey"])
+
+	t.Run("ResumeAfter", func(t *testing.T) {
+		resumed, err := collection.Watch(ctx, mongo.Pipeline{}, options.ChangeStream().SetResumeAfter(resumeToken))
+		require.NoError(t, err)
+		defer resumed.Close(ctx)
+
+		event := nextEvent(t, resumed).Map()
+		assert.Equal(t, bson.D{{"_id", "second"}}, event["documentKey"])
+	})
+
+	t.Run("Match", func(t *testing.T) {
+		pipeline := mongo.Pipeline{bson.D{{"$match", bson.D{{"fullDocument.v", int32(2)}}}}}
+		filtered, err := collection.Watch(ctx, pipeline)
+		require.NoError(t, err)
+		defer filtered.Close(ctx)
+
+		_, err = collection.InsertMany(ctx, []any{bson.D{{"_id", "skipped"}}, bson.D{{"_id", "matched"}, {"v", int32(2)}}})
+		require.NoError(t, err)
+
+		event := nextEvent(t, filtered).Map()
+		assert.Equal(t, bson.D{{"_id", "matched"}}, event["documentKey"])
+	})
+}
+
+func TestAggregateMerge(t *testing.T) {
+	setup.SkipForTigris(t)
+
+	t.Parallel()
+	ctx, collection := setup.Setup(t)
+
+	_, err := collection.InsertMany(ctx, []any{
+		bson.D{{"_id", "one"}, {"v", int32(1)}},
+		bson.D{{"_id", "two"}, {"v", int32(2)}},
+	})
+	require.NoError(t, err)
+
+	for name, tc := range map[string]struct {
+		merge    bson.D
+		expected []bson.D
+		err      *mongo.CommandError
+	}{
+		"Default": {
+			merge: bson.D{},
+			expected: []bson.D{
+				{{"_id", "one"}, {"v", int32(1)}, {"old", true}},
+				{{"_id", "three"}, {"v", int32(30)}, {"old", true}},
+				{{"_id", "two"}, {"v", int32(2)}},
+			},
+		},
+		"ReplaceDiscard": {
+			merge: bson.D{{"whenMatched", "replace"}, {"whenNotMatched", "discard"}},
+			expected: []bson.D{
+				{{"_id", "one"}, {"v", int32(1)}},
+				{{"_id", "three"}, {"v", int32(30)}, {"old", true}},
+			},
+		},
+		"KeepExisting": {
+			merge: bson.D{{"whenMatched", "keepExisting"}},
+			expected: []bson.D{
+				{{"_id", "one"}, {"v", int32(10)}, {"old", true}},
+				{{"_id", "three"}, {"v", int32(30)}, {"old", true}},
+				{{"_id", "two"}, {"v", int32(2)}},
+			},
+		},
+		"Pipeline": {
+			merge: bson.D{{"whenMatched", bson.A{
+				bson.D{{"$set", bson.D{{"prev", "$v"}, {"v", "$$new.v"}}}},
+			}}},
+			expected: []bson.D{
+				{{"_id", "one"}, {"v", int32(1)}, {"old", true}, {"prev", int32(10)}},
+				{{"_id", "three"}, {"v", int32(30)}, {"old", true}},
+				{{"_id", "two"}, {"v", int32(2)}},
+			},
+		},
+		"PipelineImmutableID": {
+			merge: bson.D{{"whenMatched", bson.A{
+				bson.D{{"$set", bson.D{{"_id", "$$new.v"}}}},
+			}}},
+			err: &mongo.CommandError{
+				Code: 66,
+				Name: "ImmutableField",
+				Message: "$merge failed to update the matching document, did you attempt to modify the _id or the shard key?" +
+					" :: caused by :: Performing an update on the path '_id' would modify the immutable field '_id'",
+			},
+		},
+		"WhenMatchedFail": {
+			merge: bson.D{{"whenMatched", "fail"}},
+			err: &mongo.CommandError{
+				Code: 11000,
+				Name: "DuplicateKey",
+			},
+		},
+		"WhenNotMatchedFail": {
+			merge: bson.D{{"whenNotMatched", "fail"}},
+			err: &mongo.CommandError{
+				Code: 13113,
+				Name: "MergeStageNoMatchingDocument",
+				Message: "$merge could not find a matching document in the target collection " +
+					"for at least one document in the source collection",
+			},
+		},
+		"WhenMatchedStageNotAllowed": {
+			merge: bson.D{{"whenMatched", bson.A{bson.D{{"$match", bson.D{}}}}}},
+			err: &mongo.CommandError{
+				Code:    72,
+				Name:    "InvalidOptions",
+				Message: "$match is not allowed to be used within an update",
+			},
+		},
+	} {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			target := collection.Database().Collection(collection.Name() + "_" + name)
+			t.Cleanup(func() { require.NoError(t, target.Drop(ctx)) })
+
+			_, err := target.InsertMany(ctx, []any{
+				bson.D{{"_id", "one"}, {"v", int32(10)}, {"old", true}},
+				bson.D{{"_id", "three"}, {"v", int32(30)}, {"old", true}},
+			})
+			require.NoError(t, err)
+
+			merge := append(bson.D{{"into", target.Name()}}, tc.merge...)
+			cursor, err := collection.Aggregate(ctx, bson.A{
+				bson.D{{"$sort", bson.D{{"_id", 1}}}},
+				bson.D{{"$merge", merge}},
+			})
+			if tc.err != nil {
+				if tc.err.Message == "" {
+					var ce mongo.CommandError
+					require.ErrorAs(t, err, &ce)
+					assert.Equal(t, tc.err.Code, ce.Code)
+
+					return
+				}
+
+				AssertEqualError(t, *tc.err, err)
+
+				return
+			}
+			require.NoError(t, err)
+
+			var res []bson.D
+			require.NoError(t, cursor.All(ctx, &res))
+			assert.Empty(t, res)
+
+			cursor, err = target.Find(ctx, bson.D{}, options.Find().SetSort(bson.D{{"_id", 1}}))
+			require.NoError(t, err)
+
+			var actual []bson.D
+			require.NoError(t, cursor.All(ctx, &actual))
+			AssertEqualDocumentsSlice(t, tc.expected, actual)
+		})
+	}
+}
+
+func TestAggregateMergeNotLast(t *testing.T) {
+	setup.SkipForTigris(t)
+
+	t.Parallel()
+	ctx, collection := setup.Setup(t)
+
+	_, err := collection.Aggregate(ctx, bson.A{
+		bson.D{{"$merge", bson.D{{"into", "target"}}}},
+		bson.D{{"$match", bson.D{}}},
+	})
+
+	expected := mongo.CommandError{
+		Code:    40601,
+		Name:    "Location40601",
+		Message: "$merge can only be the final stage in the pipeline",
+	}
+	AssertEqualError(t, expected, err)
+}
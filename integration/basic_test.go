@@ -316,7 +316,10 @@ func TestCollectionName(t *testing.T) {
 						collectionName300,
 					),
 				},
-				alt: fmt.Sprintf("Invalid collection name: 'testcollectionname_err.%s'", collectionName300),
+				alt: fmt.Sprintf(
+					"Invalid collection name: 'testcollectionname_err.%s': name is longer than 120 characters",
+					collectionName300,
+				),
 			},
 			"WithADollarSign": {
 				collection: "collection_name_with_a-$",
@@ -325,7 +328,8 @@ func TestCollectionName(t *testing.T) {
 					Code:    73,
 					Message: `Invalid collection name: collection_name_with_a-$`,
 				},
-				alt: `Invalid collection name: 'testcollectionname_err.collection_name_with_a-$'`,
+				alt: `Invalid collection name: 'testcollectionname_err.collection_name_with_a-$': ` +
+					`name must contain only letters, digits and underscores`,
 			},
 			"Empty": {
 				collection: "",
@@ -334,7 +338,6 @@ func TestCollectionName(t *testing.T) {
 					Code:    73,
 					Message: "Invalid namespace specified 'testcollectionname_err.'",
 				},
-				alt: "Invalid collection name: 'testcollectionname_err.'",
 			},
 		}
 
@@ -361,6 +364,30 @@ func TestCollectionName(t *testing.T) {
 	})
 }
 
+func TestCollectionNameReserved(t *testing.T) {
+	setup.SkipForMongoWithReason(t, "FerretDB-specific reserved prefix is used")
+	setup.SkipForTigris(t)
+
+	t.Parallel()
+	ctx, collection := setup.Setup(t)
+
+	name := "_ferretdb_reserved"
+	expected := mongo.CommandError{
+		Name: "InvalidNamespace",
+		Code: 73,
+		Message: fmt.Sprintf(
+			"Invalid collection name: '%s.%s': name must not start with '_ferretdb_'",
+			collection.Database().Name(), name,
+		),
+	}
+
+	err := collection.Database().CreateCollection(ctx, name)
+	AssertEqualError(t, expected, err)
+
+	_, err = collection.Database().Collection(name).InsertOne(ctx, bson.D{{"_id", "foo"}})
+	AssertEqualError(t, expected, err)
+}
+
 func TestDatabaseName(t *testing.T) {
 	setup.SkipForTigris(t)
 
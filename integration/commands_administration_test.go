@@ -18,6 +18,7 @@ import (
 	"fmt"
 	"math"
 	"net"
+	"regexp"
 	"strconv"
 	"testing"
 	"time"
@@ -138,6 +139,79 @@ func TestCommandsAdministrationCreateDropListDatabases(t *testing.T) {
 	assert.Equal(t, bson.D{{"ok", 1.0}}, res)
 }
 
+func TestCommandsAdministrationDropDatabase(t *testing.T) {
+	t.Parallel()
+	ctx, collection := setup.Setup(t, shareddata.Strings)
+	db := collection.Database()
+	name := db.Name()
+
+	// drop existing database
+	var res bson.D
+	err := db.RunCommand(ctx, bson.D{{"dropDatabase", 1}}).Decode(&res)
+	require.NoError(t, err)
+
+	m := res.Map()
+	assert.Equal(t, 1.0, m["ok"])
+	assert.Equal(t, name, m["dropped"])
+
+	filter := bson.D{{"name", name}}
+	names, err := db.Client().ListDatabaseNames(ctx, filter)
+	require.NoError(t, err)
+	assert.Empty(t, names)
+
+	// drop missing database
+	err = db.RunCommand(ctx, bson.D{{"dropDatabase", 1}}).Decode(&res)
+	require.NoError(t, err)
+	assert.Equal(t, bson.D{{"ok", 1.0}}, res)
+}
+
+func TestCommandsAdministrationListCollections(t *testing.T) {
+	t.Parallel()
+	ctx, collection := setup.Setup(t, shareddata.Strings)
+	db := collection.Database()
+
+	other := collection.Name() + "_other"
+	_, err := db.Collection(other).InsertOne(ctx, bson.D{{"_id", "string"}, {"v", "foo"}})
+	require.NoError(t, err)
+
+	names, err := db.ListCollectionNames(ctx, bson.D{})
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{collection.Name(), other}, names)
+
+	for name, nameOnly := range map[string]bool{
+		"NameOnly": true,
+		"Full":     false,
+	} {
+		name, nameOnly := name, nameOnly
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			var res bson.D
+			err := db.RunCommand(ctx, bson.D{{"listCollections", 1}, {"nameOnly", nameOnly}}).Decode(&res)
+			require.NoError(t, err)
+
+			firstBatch, ok := res.Map()["cursor"].(bson.D).Map()["firstBatch"].(bson.A)
+			require.True(t, ok, "%v", res)
+			require.Len(t, firstBatch, 2)
+
+			for _, c := range firstBatch {
+				c := c.(bson.D)
+				assert.Contains(t, []string{collection.Name(), other}, c.Map()["name"])
+				assert.Equal(t, "collection", c.Map()["type"])
+
+				if nameOnly {
+					assert.Equal(t, []string{"name", "type"}, CollectKeys(t, c))
+					continue
+				}
+
+				setup.SkipForPostgresWithReason(t, "https://github.com/FerretDB/FerretDB/issues/301")
+
+				assert.Contains(t, CollectKeys(t, c), "options")
+			}
+		})
+	}
+}
+
 func TestCommandsAdministrationListDatabases(t *testing.T) {
 	t.Parallel()
 	ctx, collection := setup.Setup(t, shareddata.DocumentsStrings)
@@ -166,6 +240,85 @@ func TestCommandsAdministrationListDatabases(t *testing.T) {
 	assert.NotZero(t, actual.TotalSize, "TotalSize should be non-zero")
 }
 
+func TestCommandsAdministrationListDatabasesFilter(t *testing.T) {
+	setup.SkipForTigrisWithReason(t, "https://github.com/FerretDB/FerretDB/issues/1051")
+
+	t.Parallel()
+	ctx, collection := setup.Setup(t, shareddata.DocumentsStrings)
+	db := collection.Database()
+	name := db.Name()
+
+	// create database and drop its only collection
+	emptyDB := db.Client().Database(name + "_empty")
+	t.Cleanup(func() {
+		require.NoError(t, emptyDB.Drop(ctx))
+	})
+
+	require.NoError(t, emptyDB.CreateCollection(ctx, collection.Name()))
+	require.NoError(t, emptyDB.Collection(collection.Name()).Drop(ctx))
+
+	nameRegex := primitive.Regex{Pattern: "^" + regexp.QuoteMeta(name)}
+
+	t.Run("NotEmpty", func(t *testing.T) {
+		actual, err := db.Client().ListDatabases(ctx, bson.D{{"name", nameRegex}, {"empty", false}})
+		require.NoError(t, err)
+		require.Len(t, actual.Databases, 1)
+
+		assert.Equal(t, name, actual.Databases[0].Name)
+		assert.NotZero(t, actual.Databases[0].SizeOnDisk)
+		assert.False(t, actual.Databases[0].Empty)
+	})
+
+	t.Run("SizeOnDisk", func(t *testing.T) {
+		names, err := db.Client().ListDatabaseNames(ctx, bson.D{{"name", nameRegex}, {"sizeOnDisk", bson.D{{"$gt", 0}}}})
+		require.NoError(t, err)
+		assert.Equal(t, []string{name}, names)
+	})
+
+	t.Run("Empty", func(t *testing.T) {
+		setup.SkipForMongoWithReason(t, "MongoDB removes databases without collections")
+
+		actual, err := db.Client().ListDatabases(ctx, bson.D{{"name", nameRegex}, {"empty", true}})
+		require.NoError(t, err)
+		require.Len(t, actual.Databases, 1)
+
+		assert.Equal(t, emptyDB.Name(), actual.Databases[0].Name)
+		assert.Zero(t, actual.Databases[0].SizeOnDisk)
+		assert.True(t, actual.Databases[0].Empty)
+	})
+
+	t.Run("AuthorizedDatabases", func(t *testing.T) {
+		var res bson.D
+		err := db.Client().Database("admin").RunCommand(ctx, bson.D{
+			{"listDatabases", 1},
+			{"filter", bson.D{{"name", name}}},
+			{"nameOnly", true},
+			{"authorizedDatabases", true},
+		}).Decode(&res)
+		require.NoError(t, err)
+
+		expected := bson.D{
+			{"databases", bson.A{bson.D{{"name", name}}}},
+			{"ok", 1.0},
+		}
+		assert.Equal(t, expected, res)
+
+		err = db.Client().Database("admin").RunCommand(ctx, bson.D{
+			{"listDatabases", 1},
+			{"authorizedDatabases", "foo"},
+		}).Err()
+		expectedErr := mongo.CommandError{
+			Code: 14,
+			Name: "TypeMismatch",
+			Message: "BSON field 'listDatabases.authorizedDatabases' is the wrong type 'string', " +
+				"expected types '[bool, long, int, decimal, double]'",
+		}
+		altMessage := "BSON field 'authorizedDatabases' is the wrong type 'string', " +
+			"expected types '[bool, long, int, decimal, double]'"
+		AssertEqualAltError(t, expectedErr, altMessage, err)
+	})
+}
+
 func TestCommandsAdministrationGetParameter(t *testing.T) {
 	setup.SkipForTigris(t)
 
@@ -626,9 +779,13 @@ func TestCommandsAdministrationBuildInfo(t *testing.T) {
 }
 
 func TestCommandsAdministrationCollStatsEmpty(t *testing.T) {
+	setup.SkipForTigrisWithReason(t, "Tigris collections can't be created without a schema")
+
 	t.Parallel()
 	ctx, collection := setup.Setup(t)
 
+	require.NoError(t, collection.Database().CreateCollection(ctx, collection.Name()))
+
 	var actual bson.D
 	command := bson.D{{"collStats", collection.Name()}}
 	err := collection.Database().RunCommand(ctx, command).Decode(&actual)
@@ -638,7 +795,9 @@ func TestCommandsAdministrationCollStatsEmpty(t *testing.T) {
 	assert.Equal(t, float64(1), must.NotFail(doc.Get("ok")))
 	assert.Equal(t, collection.Database().Name()+"."+collection.Name(), must.NotFail(doc.Get("ns")))
 	assert.Equal(t, int32(0), must.NotFail(doc.Get("count")))
+	assert.Equal(t, int32(1), must.NotFail(doc.Get("nindexes")))
 	assert.Equal(t, int32(1), must.NotFail(doc.Get("scaleFactor")))
+	assert.False(t, doc.Has("avgObjSize"))
 
 	assert.InDelta(t, float64(8012), must.NotFail(doc.Get("size")), 8_012)
 	assert.InDelta(t, float64(4096), must.NotFail(doc.Get("storageSize")), 8_012)
@@ -646,6 +805,23 @@ func TestCommandsAdministrationCollStatsEmpty(t *testing.T) {
 	assert.InDelta(t, float64(4096), must.NotFail(doc.Get("totalSize")), 8_012)
 }
 
+func TestCommandsAdministrationCollStatsNonExistent(t *testing.T) {
+	setup.SkipForTigris(t)
+
+	t.Parallel()
+	ctx, collection := setup.Setup(t)
+
+	command := bson.D{{"collStats", collection.Name()}}
+	err := collection.Database().RunCommand(ctx, command).Err()
+
+	expected := mongo.CommandError{
+		Code:    26,
+		Name:    "NamespaceNotFound",
+		Message: "Collection [" + collection.Database().Name() + "." + collection.Name() + "] not found.",
+	}
+	AssertEqualError(t, expected, err)
+}
+
 func TestCommandsAdministrationCollStats(t *testing.T) {
 	t.Parallel()
 	ctx, collection := setup.Setup(t, shareddata.DocumentsStrings)
@@ -666,6 +842,52 @@ func TestCommandsAdministrationCollStats(t *testing.T) {
 	assert.InDelta(t, float64(4096), must.NotFail(doc.Get("totalSize")), 16_024)
 }
 
+func TestCommandsAdministrationCollStatsScale(t *testing.T) {
+	setup.SkipForTigris(t)
+
+	t.Parallel()
+	ctx, collection := setup.Setup(t, shareddata.DocumentsStrings)
+
+	var unscaled bson.D
+	command := bson.D{{"collStats", collection.Name()}}
+	err := collection.Database().RunCommand(ctx, command).Decode(&unscaled)
+	require.NoError(t, err)
+
+	var scaled bson.D
+	command = bson.D{{"collStats", collection.Name()}, {"scale", int32(1024)}}
+	err = collection.Database().RunCommand(ctx, command).Decode(&scaled)
+	require.NoError(t, err)
+
+	u, s := ConvertDocument(t, unscaled), ConvertDocument(t, scaled)
+	assert.Equal(t, int32(1024), must.NotFail(s.Get("scaleFactor")))
+	assert.Equal(t, int32(4), must.NotFail(s.Get("count")))
+
+	// the average object size is not scaled
+	assert.Equal(t, must.NotFail(u.Get("avgObjSize")), must.NotFail(s.Get("avgObjSize")))
+
+	// sizes could be int32 or int64 depending on the value
+	toInt64 := func(v any) int64 {
+		switch v := v.(type) {
+		case int32:
+			return int64(v)
+		case int64:
+			return v
+		default:
+			t.Fatalf("unexpected type %T", v)
+			panic("not reached")
+		}
+	}
+
+	for _, field := range []string{"size", "storageSize", "totalIndexSize", "totalSize"} {
+		expected := toInt64(must.NotFail(u.Get(field))) / 1024
+		assert.Equal(t, expected, toInt64(must.NotFail(s.Get(field))), field)
+	}
+
+	command = bson.D{{"collStats", collection.Name()}, {"scale", int32(0)}}
+	err = collection.Database().RunCommand(ctx, command).Err()
+	AssertEqualError(t, mongo.CommandError{Code: 2, Name: "BadValue", Message: "scale has to be >= 1"}, err)
+}
+
 func TestCommandsAdministrationDataSize(t *testing.T) {
 	t.Parallel()
 	ctx, collection := setup.Setup(t, shareddata.DocumentsStrings)
@@ -791,6 +1013,80 @@ func TestCommandsAdministrationDBStatsEmptyWithScale(t *testing.T) {
 	// https://github.com/FerretDB/FerretDB/issues/727
 }
 
+func TestCommandsAdministrationDBStatsCollections(t *testing.T) {
+	setup.SkipForTigris(t)
+
+	t.Parallel()
+	ctx, collection := setup.Setup(t)
+
+	db := collection.Database()
+
+	_, err := collection.InsertMany(ctx, []any{
+		bson.D{{"_id", int32(1)}, {"v", "foo"}},
+		bson.D{{"_id", int32(2)}, {"v", "bar"}},
+	})
+	require.NoError(t, err)
+
+	_, err = collection.Indexes().CreateOne(ctx, mongo.IndexModel{Keys: bson.D{{"v", 1}}})
+	require.NoError(t, err)
+
+	_, err = db.Collection(collection.Name()+"_other").InsertOne(ctx, bson.D{{"_id", int32(1)}})
+	require.NoError(t, err)
+
+	var actual bson.D
+	err = db.RunCommand(ctx, bson.D{{"dbStats", int32(1)}}).Decode(&actual)
+	require.NoError(t, err)
+
+	doc := ConvertDocument(t, actual)
+
+	assert.Equal(t, float64(1), must.NotFail(doc.Get("ok")))
+	assert.EqualValues(t, 2, must.NotFail(doc.Get("collections")))
+	assert.EqualValues(t, 3, must.NotFail(doc.Get("objects")))
+	assert.EqualValues(t, 3, must.NotFail(doc.Get("indexes")))
+	assert.EqualValues(t, 1, must.NotFail(doc.Get("scaleFactor")))
+
+	dataSize := must.NotFail(doc.Get("dataSize")).(float64)
+	storageSize := must.NotFail(doc.Get("storageSize")).(float64)
+	indexSize := must.NotFail(doc.Get("indexSize")).(float64)
+	assert.Positive(t, dataSize)
+	assert.Positive(t, indexSize)
+	assert.GreaterOrEqual(t, storageSize, dataSize+indexSize)
+	assert.InDelta(t, dataSize/3, must.NotFail(doc.Get("avgObjSize")), 0.01)
+
+	err = db.RunCommand(ctx, bson.D{{"dbStats", int32(1)}, {"scale", int32(10)}}).Decode(&actual)
+	require.NoError(t, err)
+
+	doc = ConvertDocument(t, actual)
+
+	assert.EqualValues(t, 10, must.NotFail(doc.Get("scaleFactor")))
+	assert.Equal(t, float64(int64(dataSize)/10), must.NotFail(doc.Get("dataSize")))
+	assert.Equal(t, float64(int64(indexSize)/10), must.NotFail(doc.Get("indexSize")))
+
+	// average object size is not scaled
+	assert.InDelta(t, dataSize/3, must.NotFail(doc.Get("avgObjSize")), 0.01)
+}
+
+func TestCommandsAdministrationDBStatsNonExistent(t *testing.T) {
+	setup.SkipForTigris(t)
+
+	t.Parallel()
+	ctx, collection := setup.Setup(t)
+
+	var actual bson.D
+	db := collection.Database().Client().Database(collection.Database().Name() + "_nonexistent")
+	err := db.RunCommand(ctx, bson.D{{"dbStats", int32(1)}}).Decode(&actual)
+	require.NoError(t, err)
+
+	doc := ConvertDocument(t, actual)
+
+	assert.Equal(t, float64(1), must.NotFail(doc.Get("ok")))
+	assert.Equal(t, db.Name(), must.NotFail(doc.Get("db")))
+
+	for _, field := range []string{"collections", "objects", "avgObjSize", "dataSize", "storageSize", "indexes", "indexSize", "totalSize"} {
+		assert.EqualValues(t, 0, must.NotFail(doc.Get(field)), field)
+	}
+}
+
 //nolint:paralleltest // we test a global server status
 func TestCommandsAdministrationServerStatus(t *testing.T) {
 	setup.SkipForTigris(t)
@@ -873,3 +1169,84 @@ func TestCommandsAdministrationWhatsMyURI(t *testing.T) {
 	require.Equal(t, 2, len(ports))
 	assert.NotEqual(t, ports[0], ports[1])
 }
+
+func TestCommandsAdministrationRenameCollection(t *testing.T) {
+	setup.SkipForTigris(t)
+
+	t.Parallel()
+	ctx, collection := setup.Setup(t, shareddata.Scalars)
+	db := collection.Database()
+	admin := db.Client().Database("admin")
+
+	toDB := db.Client().Database(db.Name() + "_to")
+	t.Cleanup(func() {
+		require.NoError(t, toDB.Drop(ctx))
+	})
+
+	expected := FindAll(t, ctx, collection)
+	require.NotEmpty(t, expected)
+
+	from := db.Name() + "." + collection.Name()
+	to := toDB.Name() + ".moved"
+
+	rename := func(from, to string, opts ...bson.E) error {
+		return admin.RunCommand(ctx, append(bson.D{{"renameCollection", from}, {"to", to}}, opts...)).Err()
+	}
+
+	t.Run("Errors", func(t *testing.T) {
+		err := db.RunCommand(ctx, bson.D{{"renameCollection", from}, {"to", to}}).Err()
+		AssertEqualError(t, mongo.CommandError{
+			Code:    13,
+			Name:    "Unauthorized",
+			Message: "renameCollection may only be run against the admin database.",
+		}, err)
+
+		err = rename(from, from)
+		AssertEqualError(t, mongo.CommandError{
+			Code:    20,
+			Name:    "IllegalOperation",
+			Message: "Can't rename a collection to itself",
+		}, err)
+
+		err = rename(from+"_missing", to)
+		AssertEqualError(t, mongo.CommandError{
+			Code:    26,
+			Name:    "NamespaceNotFound",
+			Message: "Source collection " + from + "_missing does not exist",
+		}, err)
+	})
+
+	// move the collection with its documents to another database
+	require.NoError(t, rename(from, to))
+
+	names, err := db.ListCollectionNames(ctx, bson.D{})
+	require.NoError(t, err)
+	assert.NotContains(t, names, collection.Name())
+
+	moved := toDB.Collection("moved")
+	AssertEqualDocumentsSlice(t, expected, FindAll(t, ctx, moved))
+
+	// rename within the same database
+	require.NoError(t, rename(to, toDB.Name()+".renamed"))
+
+	renamed := toDB.Collection("renamed")
+	AssertEqualDocumentsSlice(t, expected, FindAll(t, ctx, renamed))
+
+	// _id index still works
+	_, err = renamed.InsertOne(ctx, bson.D{{"_id", expected[0].Map()["_id"]}})
+	require.Error(t, err)
+
+	// the target collection exists
+	_, err = toDB.Collection("existing").InsertOne(ctx, bson.D{{"_id", "existing"}})
+	require.NoError(t, err)
+
+	err = rename(toDB.Name()+".renamed", toDB.Name()+".existing")
+	AssertEqualError(t, mongo.CommandError{
+		Code:    48,
+		Name:    "NamespaceExists",
+		Message: "target namespace exists",
+	}, err)
+
+	require.NoError(t, rename(toDB.Name()+".renamed", toDB.Name()+".existing", bson.E{"dropTarget", true}))
+	AssertEqualDocumentsSlice(t, expected, FindAll(t, ctx, toDB.Collection("existing")))
+}
@@ -23,6 +23,7 @@ import (
 	"github.com/stretchr/testify/require"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
 
 	"github.com/FerretDB/FerretDB/integration/setup"
 )
@@ -178,3 +179,272 @@ func TestCommandsDiagnosticConnectionStatus(t *testing.T) {
 
 	assert.Equal(t, float64(1), ok)
 }
+
+func TestCommandsDiagnosticExplain(t *testing.T) {
+	setup.SkipForTigris(t)
+	setup.SkipForMongoWithReason(t, "PostgreSQL plans are a FerretDB extension")
+
+	t.Parallel()
+	ctx, collection := setup.Setup(t)
+
+	_, err := collection.InsertMany(ctx, []any{
+		bson.D{{"_id", "foo"}, {"v", int32(1)}},
+		bson.D{{"_id", "bar"}, {"v", int32(2)}},
+	})
+	require.NoError(t, err)
+
+	for name, tc := range map[string]struct {
+		command   bson.D
+		verbosity string
+		stage     string
+	}{
+		"Find": {
+			command:   bson.D{{"find", collection.Name()}, {"filter", bson.D{{"v", int32(1)}}}},
+			verbosity: "queryPlanner",
+			stage:     "COLLSCAN",
+		},
+		"Count": {
+			command:   bson.D{{"count", collection.Name()}, {"query", bson.D{{"v", int32(1)}}}},
+			verbosity: "executionStats",
+			stage:     "COUNT",
+		},
+		"Aggregate": {
+			command: bson.D{
+				{"aggregate", collection.Name()},
+				{"pipeline", bson.A{bson.D{{"$match", bson.D{{"v", int32(1)}}}}}},
+				{"cursor", bson.D{}},
+			},
+			verbosity: "allPlansExecution",
+		},
+	} {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			command := bson.D{{"explain", tc.command}, {"verbosity", tc.verbosity}}
+
+			var res bson.D
+			err := collection.Database().RunCommand(ctx, command).Decode(&res)
+			require.NoError(t, err)
+
+			m := res.Map()
+			assert.Equal(t, float64(1), m["ok"])
+
+			queryPlanner, ok := m["queryPlanner"].(bson.D)
+			require.True(t, ok, "%v", res)
+
+			qp := queryPlanner.Map()
+			assert.Equal(t, collection.Database().Name()+"."+collection.Name(), qp["namespace"])
+			assert.Equal(t, bson.D{{"v", int32(1)}}, qp["parsedQuery"])
+			assert.Equal(t, bson.A{}, qp["rejectedPlans"])
+
+			winningPlan, ok := qp["winningPlan"].(bson.D)
+			require.True(t, ok, "%v", queryPlanner)
+
+			if tc.stage != "" {
+				assert.Equal(t, tc.stage, winningPlan.Map()["stage"])
+			}
+
+			ferretdb, ok := m["ferretdb"].(bson.D)
+			require.True(t, ok, "%v", res)
+
+			postgresExplain, ok := ferretdb.Map()["postgresExplain"].(bson.A)
+			require.True(t, ok, "%v", ferretdb)
+			assert.NotEmpty(t, postgresExplain)
+		})
+	}
+
+	t.Run("InvalidVerbosity", func(t *testing.T) {
+		t.Parallel()
+
+		command := bson.D{{"explain", bson.D{{"find", collection.Name()}}}, {"verbosity", "foo"}}
+		err := collection.Database().RunCommand(ctx, command).Err()
+
+		expected := mongo.CommandError{
+			Code:    2,
+			Name:    "BadValue",
+			Message: "verbosity string must be one of {'queryPlanner', 'executionStats', 'allPlansExecution'}",
+		}
+		AssertEqualError(t, expected, err)
+	})
+}
+
+func TestCommandsDiagnosticExplainDelete(t *testing.T) {
+	setup.SkipForTigris(t)
+	setup.SkipForMongoWithReason(t, "filter pushdown is a FerretDB extension")
+
+	t.Parallel()
+	ctx, collection := setup.Setup(t)
+
+	_, err := collection.InsertMany(ctx, []any{
+		bson.D{{"_id", "foo"}, {"v", int32(1)}},
+		bson.D{{"_id", "bar"}, {"v", int32(2)}},
+	})
+	require.NoError(t, err)
+
+	for name, tc := range map[string]struct {
+		deletes  bson.A
+		expected bson.D
+		err      *mongo.CommandError
+	}{
+		"ID": {
+			deletes: bson.A{bson.D{{"q", bson.D{{"_id", "foo"}}}, {"limit", int32(1)}}},
+			expected: bson.D{
+				{"filter", bson.D{{"_id", "foo"}}},
+				{"pushdown", true},
+				{"deleteStatements", int32(1)},
+			},
+		},
+		"NotPushedDown": {
+			deletes: bson.A{bson.D{{"q", bson.D{{"v", bson.D{{"$exists", true}}}}}, {"limit", int32(0)}}},
+			expected: bson.D{
+				{"filter", bson.D{{"v", bson.D{{"$exists", true}}}}},
+				{"pushdown", false},
+				{"deleteStatements", int32(1)},
+			},
+		},
+		"SeveralStatements": {
+			deletes: bson.A{
+				bson.D{{"q", bson.D{{"_id", "bar"}}}, {"limit", int32(1)}},
+				bson.D{{"q", bson.D{{"_id", "foo"}}}, {"limit", int32(1)}},
+			},
+			expected: bson.D{
+				{"filter", bson.D{{"_id", "bar"}}},
+				{"pushdown", true},
+				{"deleteStatements", int32(2)},
+			},
+		},
+		"Empty": {
+			deletes: bson.A{},
+			err: &mongo.CommandError{
+				Code:    16,
+				Name:    "InvalidLength",
+				Message: "Write batch sizes must be between 1 and 100000. Got 0 operations.",
+			},
+		},
+	} {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			command := bson.D{{"explain", bson.D{{"delete", collection.Name()}, {"deletes", tc.deletes}}}}
+
+			var res bson.D
+			err := collection.Database().RunCommand(ctx, command).Decode(&res)
+			if tc.err != nil {
+				AssertEqualError(t, *tc.err, err)
+				return
+			}
+			require.NoError(t, err)
+
+			ferretdb, ok := res.Map()["ferretdb"].(bson.D)
+			require.True(t, ok, "%v", res)
+
+			m := ferretdb.Map()
+			for _, e := range tc.expected {
+				assert.Equal(t, e.Value, m[e.Key], e.Key)
+			}
+		})
+	}
+
+	// explain does not delete anything
+	n, err := collection.CountDocuments(ctx, bson.D{})
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), n)
+}
+
+func TestCommandsDiagnosticExplainUpdate(t *testing.T) {
+	setup.SkipForTigris(t)
+	setup.SkipForMongoWithReason(t, "match strategy is a FerretDB extension")
+
+	t.Parallel()
+	ctx, collection := setup.Setup(t)
+
+	_, err := collection.InsertMany(ctx, []any{
+		bson.D{{"_id", "foo"}, {"v", int32(1)}},
+		bson.D{{"_id", "bar"}, {"v", int32(2)}},
+	})
+	require.NoError(t, err)
+
+	for name, tc := range map[string]struct {
+		updates  bson.A
+		expected bson.D
+	}{
+		"MultiID": {
+			updates: bson.A{bson.D{
+				{"q", bson.D{{"_id", "foo"}}},
+				{"u", bson.D{{"$set", bson.D{{"v", int32(3)}}}}},
+				{"multi", true},
+			}},
+			expected: bson.D{
+				{"pushdown", true},
+				{"matchStrategy", "IXSCAN"},
+				{"multi", true},
+				{"upsert", false},
+				{"updateStatements", int32(1)},
+			},
+		},
+		"MultiField": {
+			updates: bson.A{bson.D{
+				{"q", bson.D{{"v", bson.D{{"$gt", int32(0)}}}}},
+				{"u", bson.D{{"$set", bson.D{{"v", int32(3)}}}}},
+				{"multi", true},
+			}},
+			expected: bson.D{
+				// comparisons are pushed down, but there is no index to use
+				{"pushdown", true},
+				{"matchStrategy", "COLLSCAN"},
+				{"multi", true},
+				{"upsert", false},
+				{"updateStatements", int32(1)},
+			},
+		},
+		"Upsert": {
+			updates: bson.A{
+				bson.D{
+					{"q", bson.D{{"_id", "baz"}}},
+					{"u", bson.D{{"$set", bson.D{{"v", int32(3)}}}}},
+					{"upsert", true},
+				},
+				bson.D{
+					{"q", bson.D{{"_id", "foo"}}},
+					{"u", bson.D{{"$set", bson.D{{"v", int32(3)}}}}},
+				},
+			},
+			expected: bson.D{
+				{"matchStrategy", "IXSCAN"},
+				{"multi", false},
+				{"upsert", true},
+				{"updateStatements", int32(2)},
+			},
+		},
+	} {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			command := bson.D{{"explain", bson.D{{"update", collection.Name()}, {"updates", tc.updates}}}}
+
+			var res bson.D
+			err := collection.Database().RunCommand(ctx, command).Decode(&res)
+			require.NoError(t, err)
+
+			ferretdb, ok := res.Map()["ferretdb"].(bson.D)
+			require.True(t, ok, "%v", res)
+
+			m := ferretdb.Map()
+			for _, e := range tc.expected {
+				assert.Equal(t, e.Value, m[e.Key], e.Key)
+			}
+		})
+	}
+
+	// explain does not update anything
+	cursor, err := collection.Find(ctx, bson.D{}, options.Find().SetSort(bson.D{{"_id", 1}}))
+	require.NoError(t, err)
+
+	var actual []bson.D
+	require.NoError(t, cursor.All(ctx, &actual))
+
+	expected := []bson.D{
+		{{"_id", "bar"}, {"v", int32(2)}},
+		{{"_id", "foo"}, {"v", int32(1)}},
+	}
+	assert.Equal(t, expected, actual)
+}
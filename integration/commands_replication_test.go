@@ -15,6 +15,7 @@
 package integration
 
 import (
+	"fmt"
 	"testing"
 	"time"
 
@@ -22,6 +23,8 @@ import (
 	"github.com/stretchr/testify/require"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
 
 	"github.com/FerretDB/FerretDB/integration/setup"
 )
@@ -108,3 +111,69 @@ func TestCommandsReplicationHello(t *testing.T) {
 
 	assert.Equal(t, expected, m)
 }
+
+func TestCommandsReplicationHelloCompression(t *testing.T) {
+	t.Parallel()
+	ctx, collection := setup.Setup(t)
+
+	for name, tc := range map[string]struct {
+		compression bson.A
+		expected    any
+	}{
+		"Zlib": {
+			compression: bson.A{"zlib"},
+			expected:    bson.A{"zlib"},
+		},
+		"Zstd": {
+			compression: bson.A{"zstd", "zlib"},
+			expected:    bson.A{"zstd", "zlib"},
+		},
+		"UnknownIgnored": {
+			compression: bson.A{"unknown", "zlib"},
+			expected:    bson.A{"zlib"},
+		},
+		"NotRequested": {
+			compression: bson.A{},
+			expected:    nil,
+		},
+	} {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			var actual bson.D
+			command := bson.D{{"hello", 1}, {"compression", tc.compression}}
+			err := collection.Database().RunCommand(ctx, command).Decode(&actual)
+			require.NoError(t, err)
+
+			assert.Equal(t, tc.expected, actual.Map()["compression"])
+		})
+	}
+}
+
+func TestCommandsReplicationCompressedClient(t *testing.T) {
+	t.Parallel()
+	s := setup.SetupWithOpts(t, nil)
+
+	for _, compressor := range []string{"zlib", "zstd"} {
+		compressor := compressor
+		t.Run(compressor, func(t *testing.T) {
+			t.Parallel()
+
+			uri := fmt.Sprintf("mongodb://127.0.0.1:%d/", s.Port)
+			client, err := mongo.Connect(s.Ctx, options.Client().ApplyURI(uri).SetCompressors([]string{compressor}))
+			require.NoError(t, err)
+			defer client.Disconnect(s.Ctx)
+
+			collection := client.Database(s.Collection.Database().Name()).Collection(s.Collection.Name())
+
+			_, err = collection.InsertOne(s.Ctx, bson.D{{"_id", compressor}, {"v", "value"}})
+			require.NoError(t, err)
+
+			var actual bson.D
+			err = collection.FindOne(s.Ctx, bson.D{{"_id", compressor}}).Decode(&actual)
+			require.NoError(t, err)
+			assert.Equal(t, bson.D{{"_id", compressor}, {"v", "value"}}, actual)
+		})
+	}
+}
@@ -55,3 +55,61 @@ func TestDeleteSimple(t *testing.T) {
 		})
 	}
 }
+
+// TestDeleteOrderedTigrisRollback checks that ordered deletes are executed in a single transaction.
+func TestDeleteOrderedTigrisRollback(t *testing.T) {
+	setup.SkipForMongoWithReason(t, "Tigris-specific transactional behavior is checked")
+	setup.SkipForPostgresWithReason(t, "Tigris-specific transactional behavior is checked")
+
+	t.Parallel()
+	ctx, collection := setup.Setup(t, shareddata.Strings)
+
+	var actual bson.D
+	err := collection.Database().RunCommand(ctx, bson.D{
+		{"delete", collection.Name()},
+		{"deletes", bson.A{
+			bson.D{{"q", bson.D{{"_id", "string"}}}, {"limit", int32(0)}},
+			bson.D{{"q", bson.D{{"$bad", int32(1)}}}, {"limit", int32(0)}},
+		}},
+		{"ordered", true},
+	}).Decode(&actual)
+	require.NoError(t, err)
+
+	m := actual.Map()
+	assert.Equal(t, float64(1), m["ok"])
+	assert.Equal(t, int32(0), m["n"])
+
+	writeErrors, ok := m["writeErrors"].(bson.A)
+	require.True(t, ok, "%v", actual)
+	require.Len(t, writeErrors, 1)
+	assert.Equal(t, int32(1), writeErrors[0].(bson.D).Map()["index"])
+
+	// the first delete is rolled back
+	expectedIDs := []any{"string", "string-double", "string-empty", "string-whole"}
+	assert.Equal(t, expectedIDs, CollectIDs(t, FindAll(t, ctx, collection)))
+}
+
+// TestDeleteDottedPath checks that filters on nested fields select only matching documents.
+func TestDeleteDottedPath(t *testing.T) {
+	t.Parallel()
+	ctx, collection := setup.Setup(t)
+
+	docs := []any{
+		bson.D{{"_id", "1"}, {"address", bson.D{{"city", "X"}, {"street", "A"}}}},
+		bson.D{{"_id", "2"}, {"address", bson.D{{"city", "Y"}, {"street", "A"}}}},
+		bson.D{{"_id", "3"}, {"address", bson.D{{"city", "X"}, {"street", "B"}}}},
+		bson.D{{"_id", "4"}, {"address", bson.D{{"city", "Z"}, {"street", "X"}}}},
+	}
+	_, err := collection.InsertMany(ctx, docs)
+	require.NoError(t, err)
+
+	cursor, err := collection.Find(ctx, bson.D{{"address.city", "X"}})
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []any{"1", "3"}, CollectIDs(t, FetchAll(t, ctx, cursor)))
+
+	res, err := collection.DeleteMany(ctx, bson.D{{"address.city", "X"}})
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), res.DeletedCount)
+
+	assert.Equal(t, []any{"2", "4"}, CollectIDs(t, FindAll(t, ctx, collection)))
+}
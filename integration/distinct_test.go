@@ -0,0 +1,92 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package integration
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+
+	"github.com/FerretDB/FerretDB/integration/setup"
+)
+
+func TestDistinct(t *testing.T) {
+	setup.SkipForTigris(t)
+
+	t.Parallel()
+	ctx, collection := setup.Setup(t)
+
+	_, err := collection.InsertMany(ctx, []any{
+		bson.D{{"_id", "string"}, {"v", "foo"}, {"tag", "a"}},
+		bson.D{{"_id", "int32"}, {"v", int32(42)}, {"tag", "a"}},
+		bson.D{{"_id", "double"}, {"v", 42.0}, {"tag", "b"}},
+		bson.D{{"_id", "array"}, {"v", bson.A{"bar", int32(1), "foo"}}, {"tag", "b"}},
+		bson.D{{"_id", "null"}, {"v", nil}, {"tag", "c"}},
+		bson.D{{"_id", "document"}, {"v", bson.D{{"foo", "baz"}}}, {"tag", "c"}},
+		bson.D{{"_id", "missing"}, {"tag", "c"}},
+	})
+	require.NoError(t, err)
+
+	for name, tc := range map[string]struct {
+		key      string
+		filter   bson.D
+		expected []any
+	}{
+		"All": {
+			key:      "v",
+			filter:   bson.D{},
+			expected: []any{nil, int32(1), int32(42), "bar", "foo", bson.D{{"foo", "baz"}}},
+		},
+		"Query": {
+			key:      "v",
+			filter:   bson.D{{"tag", "b"}},
+			expected: []any{int32(1), 42.0, "bar", "foo"},
+		},
+		"DottedKey": {
+			key:      "v.foo",
+			filter:   bson.D{},
+			expected: []any{"baz"},
+		},
+		"NoValues": {
+			key:      "v",
+			filter:   bson.D{{"tag", "none"}},
+			expected: []any{},
+		},
+	} {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			actual, err := collection.Distinct(ctx, tc.key, tc.filter)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, actual)
+		})
+	}
+
+	t.Run("NonExistentCollection", func(t *testing.T) {
+		t.Parallel()
+
+		var res bson.D
+		err := collection.Database().RunCommand(ctx, bson.D{
+			{"distinct", collection.Name() + "_non_existent"},
+			{"key", "v"},
+		}).Decode(&res)
+		require.NoError(t, err)
+
+		assert.Equal(t, bson.D{{"values", bson.A{}}, {"ok", 1.0}}, res)
+	})
+}
@@ -68,7 +68,7 @@ func TestEmbedded(t *testing.T) {
 	}}
 	names, err := client.ListDatabaseNames(ctx, filter)
 	require.NoError(t, err)
-	assert.Equal(t, []string{"admin", "public"}, names)
+	assert.Equal(t, []string{"admin"}, names)
 
 	require.NoError(t, client.Disconnect(ctx))
 
@@ -16,19 +16,21 @@ package integration
 
 import (
 	"math"
+	"sync"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
 
 	"github.com/FerretDB/FerretDB/integration/setup"
 	"github.com/FerretDB/FerretDB/integration/shareddata"
 )
 
 func TestFindAndModifySimple(t *testing.T) {
-	setup.SkipForTigris(t)
+	setup.SkipForTigrisWithReason(t, "Scalars and Composites shared data sets are not compatible with Tigris")
 
 	t.Parallel()
 
@@ -153,8 +155,6 @@ func TestFindAndModifySimple(t *testing.T) {
 }
 
 func TestFindAndModifyEmptyCollectionName(t *testing.T) {
-	setup.SkipForTigris(t)
-
 	t.Parallel()
 
 	for name, tc := range map[string]struct {
@@ -172,7 +172,7 @@ func TestFindAndModifyEmptyCollectionName(t *testing.T) {
 		name, tc := name, tc
 		t.Run(name, func(t *testing.T) {
 			t.Parallel()
-			ctx, collection := setup.Setup(t, shareddata.Scalars, shareddata.Composites)
+			ctx, collection := setup.Setup(t, shareddata.Strings)
 
 			var actual bson.D
 			err := collection.Database().RunCommand(ctx, bson.D{{"findAndModify", ""}}).Decode(&actual)
@@ -183,8 +183,6 @@ func TestFindAndModifyEmptyCollectionName(t *testing.T) {
 }
 
 func TestFindAndModifyErrors(t *testing.T) {
-	setup.SkipForTigris(t)
-
 	t.Parallel()
 
 	for name, tc := range map[string]struct {
@@ -296,7 +294,7 @@ func TestFindAndModifyErrors(t *testing.T) {
 		name, tc := name, tc
 		t.Run(name, func(t *testing.T) {
 			t.Parallel()
-			ctx, collection := setup.Setup(t, shareddata.Scalars, shareddata.Composites)
+			ctx, collection := setup.Setup(t, shareddata.Strings)
 
 			command := bson.D{{"findAndModify", collection.Name()}}
 			command = append(command, tc.command...)
@@ -313,7 +311,7 @@ func TestFindAndModifyErrors(t *testing.T) {
 }
 
 func TestFindAndModifyUpdate(t *testing.T) {
-	setup.SkipForTigris(t)
+	setup.SkipForTigrisWithReason(t, "Scalars and Composites shared data sets are not compatible with Tigris")
 
 	t.Parallel()
 
@@ -448,7 +446,7 @@ func TestFindAndModifyUpdate(t *testing.T) {
 }
 
 func TestFindAndModifyUpsert(t *testing.T) {
-	setup.SkipForTigris(t)
+	setup.SkipForTigrisWithReason(t, "Scalars and Composites shared data sets are not compatible with Tigris")
 
 	t.Parallel()
 
@@ -504,6 +502,42 @@ func TestFindAndModifyUpsert(t *testing.T) {
 				{"ok", float64(1)},
 			},
 		},
+		"UpsertNoSuchDocumentReturnOld": {
+			command: bson.D{
+				{"query", bson.D{{"_id", "no-such-doc"}}},
+				{"update", bson.D{{"$set", bson.D{{"v", 43.13}}}}},
+				{"upsert", true},
+			},
+			response: bson.D{
+				{"lastErrorObject", bson.D{
+					{"n", int32(1)},
+					{"updatedExisting", false},
+					{"upserted", "no-such-doc"},
+				}},
+				{"ok", float64(1)},
+			},
+		},
+		"UpsertNoSuchDocumentQueryEquality": {
+			command: bson.D{
+				{"query", bson.D{
+					{"_id", "no-such-doc"},
+					{"status", bson.D{{"$eq", "new"}}},
+					{"priority", bson.D{{"$gt", int32(1)}}},
+				}},
+				{"update", bson.D{{"$set", bson.D{{"v", 43.13}}}}},
+				{"upsert", true},
+				{"new", true},
+			},
+			response: bson.D{
+				{"lastErrorObject", bson.D{
+					{"n", int32(1)},
+					{"updatedExisting", false},
+					{"upserted", "no-such-doc"},
+				}},
+				{"value", bson.D{{"_id", "no-such-doc"}, {"status", "new"}, {"v", 43.13}}},
+				{"ok", float64(1)},
+			},
+		},
 		"UpsertNoSuchReplaceDocument": {
 			command: bson.D{
 				{"query", bson.D{{"_id", "no-such-doc"}}},
@@ -536,6 +570,22 @@ func TestFindAndModifyUpsert(t *testing.T) {
 				{"ok", float64(1)},
 			},
 		},
+		"UpsertReplaceRemovesFields": {
+			command: bson.D{
+				{"query", bson.D{{"_id", "document"}}},
+				{"update", bson.D{{"w", "foo"}}},
+				{"upsert", true},
+				{"new", true},
+			},
+			response: bson.D{
+				{"lastErrorObject", bson.D{
+					{"n", int32(1)},
+					{"updatedExisting", true},
+				}},
+				{"value", bson.D{{"_id", "document"}, {"w", "foo"}}},
+				{"ok", float64(1)},
+			},
+		},
 		"UpsertReplaceReturnNew": {
 			command: bson.D{
 				{"query", bson.D{{"_id", "double"}}},
@@ -573,7 +623,7 @@ func TestFindAndModifyUpsert(t *testing.T) {
 }
 
 func TestFindAndModifyUpsertComplex(t *testing.T) {
-	setup.SkipForTigris(t)
+	setup.SkipForTigrisWithReason(t, "Scalars and Composites shared data sets are not compatible with Tigris")
 
 	t.Parallel()
 
@@ -628,8 +678,132 @@ func TestFindAndModifyUpsertComplex(t *testing.T) {
 	}
 }
 
+func TestFindAndModifySort(t *testing.T) {
+	setup.SkipForTigrisWithReason(t, "fields projection is not implemented for Tigris yet")
+
+	t.Parallel()
+	ctx, collection := setup.Setup(t)
+
+	_, err := collection.InsertMany(ctx, []any{
+		bson.D{{"_id", "job1"}, {"status", "new"}, {"priority", int32(1)}},
+		bson.D{{"_id", "job2"}, {"status", "new"}, {"priority", int32(3)}},
+		bson.D{{"_id", "job3"}, {"status", "done"}, {"priority", int32(5)}},
+	})
+	require.NoError(t, err)
+
+	// take the new job with the highest priority
+	var actual bson.D
+	err = collection.Database().RunCommand(ctx, bson.D{
+		{"findAndModify", collection.Name()},
+		{"query", bson.D{{"status", "new"}}},
+		{"sort", bson.D{{"priority", -1}}},
+		{"update", bson.D{{"$set", bson.D{{"status", "running"}}}}},
+		{"new", true},
+		{"fields", bson.D{{"priority", 0}}},
+	}).Decode(&actual)
+	require.NoError(t, err)
+
+	expected := bson.D{
+		{"lastErrorObject", bson.D{{"n", int32(1)}, {"updatedExisting", true}}},
+		{"value", bson.D{{"_id", "job2"}, {"status", "running"}}},
+		{"ok", float64(1)},
+	}
+	AssertEqualDocuments(t, expected, actual)
+
+	// remove the new job with the lowest priority
+	err = collection.Database().RunCommand(ctx, bson.D{
+		{"findAndModify", collection.Name()},
+		{"query", bson.D{{"status", "new"}}},
+		{"sort", bson.D{{"priority", 1}}},
+		{"remove", true},
+	}).Decode(&actual)
+	require.NoError(t, err)
+
+	expected = bson.D{
+		{"lastErrorObject", bson.D{{"n", int32(1)}}},
+		{"value", bson.D{{"_id", "job1"}, {"status", "new"}, {"priority", int32(1)}}},
+		{"ok", float64(1)},
+	}
+	AssertEqualDocuments(t, expected, actual)
+
+	cursor, err := collection.Find(ctx, bson.D{}, options.Find().SetSort(bson.D{{"_id", 1}}))
+	require.NoError(t, err)
+
+	var docs []bson.D
+	require.NoError(t, cursor.All(ctx, &docs))
+
+	expectedDocs := []bson.D{
+		{{"_id", "job2"}, {"status", "running"}, {"priority", int32(3)}},
+		{{"_id", "job3"}, {"status", "done"}, {"priority", int32(5)}},
+	}
+	AssertEqualDocumentsSlice(t, expectedDocs, docs)
+}
+
+func TestFindAndModifyConcurrentClaim(t *testing.T) {
+	setup.SkipForTigrisWithReason(t, "fields projection is not implemented for Tigris yet")
+
+	t.Parallel()
+	ctx, collection := setup.Setup(t)
+
+	jobs := make([]any, 50)
+	for i := range jobs {
+		jobs[i] = bson.D{{"_id", int32(i)}, {"status", "new"}, {"priority", int32(i % 5)}}
+	}
+	_, err := collection.InsertMany(ctx, jobs)
+	require.NoError(t, err)
+
+	var mu sync.Mutex
+	claimed := make(map[int32]int, len(jobs))
+
+	// workers take the new jobs with the highest priority until there are none left
+	var wg sync.WaitGroup
+	for w := 0; w < 5; w++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			for {
+				var res bson.D
+				err := collection.Database().RunCommand(ctx, bson.D{
+					{"findAndModify", collection.Name()},
+					{"query", bson.D{{"status", "new"}}},
+					{"sort", bson.D{{"priority", -1}}},
+					{"update", bson.D{{"$set", bson.D{{"status", "running"}}}}},
+					{"fields", bson.D{{"_id", 1}}},
+				}).Decode(&res)
+				if !assert.NoError(t, err) {
+					return
+				}
+
+				value, ok := res.Map()["value"].(bson.D)
+				if !ok {
+					// no new jobs
+					return
+				}
+
+				mu.Lock()
+				claimed[value.Map()["_id"].(int32)]++
+				mu.Unlock()
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	// each job is claimed exactly once
+	assert.Len(t, claimed, len(jobs))
+	for id, n := range claimed {
+		assert.Equal(t, 1, n, "job %d", id)
+	}
+
+	n, err := collection.CountDocuments(ctx, bson.D{{"status", "running"}})
+	require.NoError(t, err)
+	assert.Equal(t, int64(len(jobs)), n)
+}
+
 func TestFindAndModifyRemove(t *testing.T) {
-	setup.SkipForTigris(t)
+	setup.SkipForTigrisWithReason(t, "Scalars shared data set is not compatible with Tigris")
 
 	t.Parallel()
 
@@ -689,9 +863,186 @@ func TestFindAndModifyRemove(t *testing.T) {
 	}
 }
 
-func TestFindAndModifyBadMaxTimeMSType(t *testing.T) {
-	setup.SkipForTigris(t) // FindAndModify is not implemented for Tigris yet
+func TestFindAndModifyFields(t *testing.T) {
+	setup.SkipForTigrisWithReason(t, "fields projection is not implemented for Tigris yet")
+
+	t.Parallel()
+
+	for name, tc := range map[string]struct {
+		command bson.D
+		value   bson.D
+		stored  bson.D // document stored after the command, nil if removed
+		err     *mongo.CommandError
+	}{
+		"UpdateInclusion": {
+			command: bson.D{
+				{"query", bson.D{{"_id", "doc"}}},
+				{"update", bson.D{{"$set", bson.D{{"a", int32(2)}}}}},
+				{"fields", bson.D{{"a", 1}}},
+			},
+			value:  bson.D{{"_id", "doc"}, {"a", int32(1)}},
+			stored: bson.D{{"_id", "doc"}, {"a", int32(2)}, {"b", "foo"}},
+		},
+		"UpdateNewExclusion": {
+			command: bson.D{
+				{"query", bson.D{{"_id", "doc"}}},
+				{"update", bson.D{{"$set", bson.D{{"a", int32(2)}}}}},
+				{"new", true},
+				{"fields", bson.D{{"b", 0}}},
+			},
+			value:  bson.D{{"_id", "doc"}, {"a", int32(2)}},
+			stored: bson.D{{"_id", "doc"}, {"a", int32(2)}, {"b", "foo"}},
+		},
+		"ReplaceNewWithoutID": {
+			command: bson.D{
+				{"query", bson.D{{"_id", "doc"}}},
+				{"update", bson.D{{"a", int32(3)}, {"b", "bar"}}},
+				{"new", true},
+				{"fields", bson.D{{"_id", 0}, {"b", 1}}},
+			},
+			value:  bson.D{{"b", "bar"}},
+			stored: bson.D{{"_id", "doc"}, {"a", int32(3)}, {"b", "bar"}},
+		},
+		"Remove": {
+			command: bson.D{
+				{"query", bson.D{{"_id", "doc"}}},
+				{"remove", true},
+				{"fields", bson.D{{"b", 1}}},
+			},
+			value: bson.D{{"_id", "doc"}, {"b", "foo"}},
+		},
+		"InvalidProjection": {
+			command: bson.D{
+				{"query", bson.D{{"_id", "doc"}}},
+				{"update", bson.D{{"$set", bson.D{{"a", int32(2)}}}}},
+				{"fields", bson.D{{"a", 1}, {"b", 0}}},
+			},
+			stored: bson.D{{"_id", "doc"}, {"a", int32(1)}, {"b", "foo"}},
+			err: &mongo.CommandError{
+				Code:    31254,
+				Name:    "Location31254",
+				Message: "Cannot do exclusion on field b in inclusion projection",
+			},
+		},
+	} {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			ctx, collection := setup.Setup(t)
+
+			_, err := collection.InsertOne(ctx, bson.D{{"_id", "doc"}, {"a", int32(1)}, {"b", "foo"}})
+			require.NoError(t, err)
+
+			command := append(bson.D{{"findAndModify", collection.Name()}}, tc.command...)
 
+			var actual bson.D
+			err = collection.Database().RunCommand(ctx, command).Decode(&actual)
+
+			if tc.err != nil {
+				AssertEqualError(t, *tc.err, err)
+			} else {
+				require.NoError(t, err)
+				AssertEqualDocuments(t, tc.value, actual.Map()["value"].(bson.D))
+			}
+
+			var stored []bson.D
+			cursor, err := collection.Find(ctx, bson.D{})
+			require.NoError(t, err)
+			require.NoError(t, cursor.All(ctx, &stored))
+
+			if tc.stored == nil {
+				assert.Empty(t, stored)
+				return
+			}
+
+			AssertEqualDocumentsSlice(t, []bson.D{tc.stored}, stored)
+		})
+	}
+}
+
+// TestFindAndModifyStrings checks removes and upserts for the data set that is compatible with all handlers.
+func TestFindAndModifyStrings(t *testing.T) {
+	t.Parallel()
+
+	for name, tc := range map[string]struct {
+		command     bson.D
+		response    bson.D
+		expectedIDs []any
+	}{
+		"Remove": {
+			command: bson.D{
+				{"query", bson.D{{"_id", "string"}}},
+				{"remove", true},
+			},
+			response: bson.D{
+				{"lastErrorObject", bson.D{{"n", int32(1)}}},
+				{"value", bson.D{{"_id", "string"}, {"v", "foo"}}},
+				{"ok", float64(1)},
+			},
+			expectedIDs: []any{"string-double", "string-empty", "string-whole"},
+		},
+		"Update": {
+			command: bson.D{
+				{"query", bson.D{{"_id", "string"}}},
+				{"update", bson.D{{"$set", bson.D{{"v", "bar"}}}}},
+				{"new", true},
+			},
+			response: bson.D{
+				{"lastErrorObject", bson.D{{"n", int32(1)}, {"updatedExisting", true}}},
+				{"value", bson.D{{"_id", "string"}, {"v", "bar"}}},
+				{"ok", float64(1)},
+			},
+			expectedIDs: []any{"string", "string-double", "string-empty", "string-whole"},
+		},
+		"UpdateNotFound": {
+			command: bson.D{
+				{"query", bson.D{{"_id", "string-new"}}},
+				{"update", bson.D{{"$set", bson.D{{"v", "bar"}}}}},
+			},
+			response: bson.D{
+				{"lastErrorObject", bson.D{{"n", int32(0)}, {"updatedExisting", false}}},
+				{"ok", float64(1)},
+			},
+			expectedIDs: []any{"string", "string-double", "string-empty", "string-whole"},
+		},
+		"Upsert": {
+			command: bson.D{
+				{"query", bson.D{{"_id", "string-new"}}},
+				{"update", bson.D{{"_id", "string-new"}, {"v", "bar"}}},
+				{"upsert", true},
+				{"new", true},
+			},
+			response: bson.D{
+				{"lastErrorObject", bson.D{
+					{"n", int32(1)},
+					{"updatedExisting", false},
+					{"upserted", "string-new"},
+				}},
+				{"value", bson.D{{"_id", "string-new"}, {"v", "bar"}}},
+				{"ok", float64(1)},
+			},
+			expectedIDs: []any{"string", "string-double", "string-empty", "string-new", "string-whole"},
+		},
+	} {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			ctx, collection := setup.Setup(t, shareddata.Strings)
+
+			command := append(bson.D{{"findAndModify", collection.Name()}}, tc.command...)
+
+			var actual bson.D
+			err := collection.Database().RunCommand(ctx, command).Decode(&actual)
+			require.NoError(t, err)
+
+			AssertEqualDocuments(t, tc.response, actual)
+
+			assert.Equal(t, tc.expectedIDs, CollectIDs(t, FindAll(t, ctx, collection)))
+		})
+	}
+}
+
+func TestFindAndModifyBadMaxTimeMSType(t *testing.T) {
 	t.Parallel()
 	ctx, collection := setup.Setup(t)
 
@@ -0,0 +1,687 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package integration
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/FerretDB/FerretDB/integration/setup"
+)
+
+func TestIndexesNumeric(t *testing.T) {
+	setup.SkipForMongoWithReason(t, "numeric index option is a FerretDB extension")
+	setup.SkipForTigrisWithReason(t, "numeric index option is PostgreSQL-specific")
+
+	t.Parallel()
+	ctx, collection := setup.Setup(t)
+
+	_, err := collection.InsertMany(ctx, []any{
+		bson.D{{"_id", "int-10"}, {"age", int32(10)}},
+		bson.D{{"_id", "int-30"}, {"age", int32(30)}},
+		bson.D{{"_id", "string-20"}, {"age", "20"}},
+		bson.D{{"_id", "missing"}},
+	})
+	require.NoError(t, err)
+
+	command := bson.D{
+		{"createIndexes", collection.Name()},
+		{"indexes", bson.A{bson.D{{"key", bson.D{{"age", 1}}}, {"name", "age_1"}, {"numeric", true}}}},
+	}
+	var res bson.D
+	require.NoError(t, collection.Database().RunCommand(ctx, command).Decode(&res))
+
+	cursor, err := collection.Find(ctx, bson.D{{"age", bson.D{{"$gt", int32(15)}}}}, options.Find().SetSort(bson.D{{"_id", 1}}))
+	require.NoError(t, err)
+
+	var actual []bson.D
+	require.NoError(t, cursor.All(ctx, &actual))
+	assert.Equal(t, []any{"int-30"}, CollectIDs(t, actual))
+
+	// the index prevents inserting values that can't be used with it
+	_, err = collection.InsertOne(ctx, bson.D{{"_id", "string-foo"}, {"age", "foo"}})
+	require.Error(t, err)
+}
+
+func TestIndexesNumericInvalidValues(t *testing.T) {
+	setup.SkipForMongoWithReason(t, "numeric index option is a FerretDB extension")
+	setup.SkipForTigrisWithReason(t, "numeric index option is PostgreSQL-specific")
+
+	t.Parallel()
+	ctx, collection := setup.Setup(t)
+
+	_, err := collection.InsertOne(ctx, bson.D{{"_id", "string-foo"}, {"age", "foo"}})
+	require.NoError(t, err)
+
+	command := bson.D{
+		{"createIndexes", collection.Name()},
+		{"indexes", bson.A{bson.D{{"key", bson.D{{"age", 1}}}, {"name", "age_1"}, {"numeric", true}}}},
+	}
+	err = collection.Database().RunCommand(ctx, command).Err()
+
+	expected := mongo.CommandError{
+		Code:    67,
+		Name:    "CannotCreateIndex",
+		Message: `Index age_1 can't be created: field "age" contains non-integer values`,
+	}
+	AssertEqualError(t, expected, err)
+}
+
+func TestIndexesTTL(t *testing.T) {
+	setup.SkipForMongoWithReason(t, "MongoDB's TTL monitor runs every 60 seconds")
+	setup.SkipForTigrisWithReason(t, "TTL indexes are not implemented yet")
+
+	t.Parallel()
+	ctx, collection := setup.Setup(t)
+
+	now := time.Now()
+	_, err := collection.InsertMany(ctx, []any{
+		bson.D{{"_id", "expired"}, {"createdAt", primitive.NewDateTimeFromTime(now.Add(-time.Hour))}},
+		bson.D{{"_id", "expired-array"}, {"createdAt", bson.A{
+			primitive.NewDateTimeFromTime(now.Add(time.Hour)),
+			primitive.NewDateTimeFromTime(now.Add(-time.Hour)),
+		}}},
+		bson.D{{"_id", "fresh"}, {"createdAt", primitive.NewDateTimeFromTime(now.Add(time.Hour))}},
+		bson.D{{"_id", "string"}, {"createdAt", "2020-01-01"}},
+		bson.D{{"_id", "missing"}},
+	})
+	require.NoError(t, err)
+
+	command := bson.D{
+		{"createIndexes", collection.Name()},
+		{"indexes", bson.A{bson.D{{"key", bson.D{{"createdAt", 1}}}, {"name", "ttl"}, {"expireAfterSeconds", int32(60)}}}},
+	}
+	require.NoError(t, collection.Database().RunCommand(ctx, command).Err())
+
+	// creating the same index again is a no-op
+	require.NoError(t, collection.Database().RunCommand(ctx, command).Err())
+
+	require.Eventually(t, func() bool {
+		count, err := collection.CountDocuments(ctx, bson.D{})
+		require.NoError(t, err)
+		return count == 3
+	}, 10*time.Second, 100*time.Millisecond)
+
+	actual := FindAll(t, ctx, collection)
+	assert.Equal(t, []any{"fresh", "missing", "string"}, CollectIDs(t, actual))
+}
+
+func TestIndexesTTLErrors(t *testing.T) {
+	setup.SkipForTigrisWithReason(t, "TTL indexes are not implemented yet")
+
+	t.Parallel()
+	ctx, collection := setup.Setup(t)
+
+	for name, index := range map[string]bson.D{
+		"Negative": {{"key", bson.D{{"v", 1}}}, {"name", "ttl"}, {"expireAfterSeconds", int32(-1)}},
+		"Compound": {{"key", bson.D{{"v", 1}, {"w", 1}}}, {"name", "ttl"}, {"expireAfterSeconds", int32(1)}},
+	} {
+		name, index := name, index
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			command := bson.D{{"createIndexes", collection.Name()}, {"indexes", bson.A{index}}}
+			err := collection.Database().RunCommand(ctx, command).Err()
+
+			// messages differ from MongoDB's
+			var cmdErr mongo.CommandError
+			require.ErrorAs(t, err, &cmdErr)
+			assert.Equal(t, int32(67), cmdErr.Code)
+			assert.Equal(t, "CannotCreateIndex", cmdErr.Name)
+		})
+	}
+}
+
+func TestIndexesCreate(t *testing.T) {
+	setup.SkipForTigrisWithReason(t, "secondary indexes are not implemented yet")
+
+	t.Parallel()
+	ctx, collection := setup.Setup(t)
+
+	_, err := collection.InsertOne(ctx, bson.D{{"_id", "foo"}, {"v", int32(42)}})
+	require.NoError(t, err)
+
+	command := bson.D{
+		{"createIndexes", collection.Name()},
+		{"indexes", bson.A{
+			bson.D{{"key", bson.D{{"v", 1}}}, {"name", "v_1"}},
+			bson.D{{"key", bson.D{{"foo.bar", -1}}}, {"name", "foo.bar_-1"}},
+		}},
+	}
+
+	var actual bson.D
+	require.NoError(t, collection.Database().RunCommand(ctx, command).Decode(&actual))
+
+	expected := bson.D{
+		{"numIndexesBefore", int32(1)},
+		{"numIndexesAfter", int32(3)},
+		{"createdCollectionAutomatically", false},
+		{"ok", float64(1)},
+	}
+	AssertEqualDocuments(t, expected, actual)
+
+	// creating the same indexes again is a no-op
+	require.NoError(t, collection.Database().RunCommand(ctx, command).Decode(&actual))
+
+	expected = bson.D{
+		{"numIndexesBefore", int32(3)},
+		{"numIndexesAfter", int32(3)},
+		{"note", "all indexes already exist"},
+		{"ok", float64(1)},
+	}
+	AssertEqualDocuments(t, expected, actual)
+
+	// indexes are used transparently
+	cursor, err := collection.Find(ctx, bson.D{{"v", int32(42)}})
+	require.NoError(t, err)
+
+	var docs []bson.D
+	require.NoError(t, cursor.All(ctx, &docs))
+	assert.Equal(t, []any{"foo"}, CollectIDs(t, docs))
+}
+
+func TestIndexesCreateErrors(t *testing.T) {
+	setup.SkipForTigrisWithReason(t, "secondary indexes are not implemented yet")
+
+	t.Parallel()
+	ctx, collection := setup.Setup(t)
+
+	command := bson.D{
+		{"createIndexes", collection.Name()},
+		{"indexes", bson.A{bson.D{{"key", bson.D{{"v", 1}}}, {"name", "idx"}}}},
+	}
+	require.NoError(t, collection.Database().RunCommand(ctx, command).Err())
+
+	for name, tc := range map[string]struct {
+		index bson.D
+		err   mongo.CommandError
+		alt   string
+	}{
+		"SameNameDifferentKey": {
+			index: bson.D{{"key", bson.D{{"w", 1}}}, {"name", "idx"}},
+			err: mongo.CommandError{
+				Code: 86,
+				Name: "IndexKeySpecsConflict",
+				Message: "An existing index has the same name as the requested index. " +
+					"When index names are not specified, they are auto generated and can cause conflicts. " +
+					"Please refer to our documentation. " +
+					`Requested index: { v: 2, key: { w: 1 }, name: "idx" }, ` +
+					`existing index: { v: 2, key: { v: 1 }, name: "idx" }`,
+			},
+			alt: "An existing index has the same name as the requested index: idx",
+		},
+		"SameKeyDifferentName": {
+			index: bson.D{{"key", bson.D{{"v", 1}}}, {"name", "other"}},
+			err: mongo.CommandError{
+				Code:    85,
+				Name:    "IndexOptionsConflict",
+				Message: "Index already exists with a different name: idx",
+			},
+		},
+		"ZeroKey": {
+			index: bson.D{{"key", bson.D{{"v", 0}}}, {"name", "zero"}},
+			err: mongo.CommandError{
+				Code: 67,
+				Name: "CannotCreateIndex",
+				Message: `Error in specification { key: { v: 0 }, name: "zero" } :: caused by :: ` +
+					`Values in the index key pattern can't be 0.`,
+			},
+			alt: "Index zero: values in the index key pattern can't be 0",
+		},
+	} {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			command := bson.D{{"createIndexes", collection.Name()}, {"indexes", bson.A{tc.index}}}
+			err := collection.Database().RunCommand(ctx, command).Err()
+			AssertEqualAltError(t, tc.err, tc.alt, err)
+		})
+	}
+}
+
+func TestIndexesCreateCompound(t *testing.T) {
+	setup.SkipForMongoWithReason(t, "compound indexes are not implemented yet")
+	setup.SkipForTigrisWithReason(t, "secondary indexes are not implemented yet")
+
+	t.Parallel()
+	ctx, collection := setup.Setup(t)
+
+	command := bson.D{
+		{"createIndexes", collection.Name()},
+		{"indexes", bson.A{bson.D{{"key", bson.D{{"v", 1}, {"w", -1}}}, {"name", "v_1_w_-1"}}}},
+	}
+	err := collection.Database().RunCommand(ctx, command).Err()
+
+	expected := mongo.CommandError{
+		Code:    238,
+		Name:    "NotImplemented",
+		Message: "Index v_1_w_-1: compound indexes are not supported yet",
+	}
+	AssertEqualError(t, expected, err)
+}
+
+func TestIndexesUniqueCompound(t *testing.T) {
+	setup.SkipForTigrisWithReason(t, "secondary indexes are not implemented yet")
+
+	t.Parallel()
+	ctx, collection := setup.Setup(t)
+
+	_, err := collection.InsertMany(ctx, []any{
+		bson.D{{"_id", "1"}, {"v", int32(1)}, {"w", "a"}},
+		bson.D{{"_id", "2"}, {"v", int32(1)}, {"w", "b"}},
+		bson.D{{"_id", "3"}, {"v", int32(2)}, {"w", "a"}},
+	})
+	require.NoError(t, err)
+
+	command := bson.D{
+		{"createIndexes", collection.Name()},
+		{"indexes", bson.A{bson.D{{"key", bson.D{{"v", 1}, {"w", -1}}}, {"name", "v_1_w_-1"}, {"unique", true}}}},
+	}
+	require.NoError(t, collection.Database().RunCommand(ctx, command).Err())
+
+	var actual bson.D
+	err = collection.Database().RunCommand(ctx, bson.D{{"listIndexes", collection.Name()}}).Decode(&actual)
+	require.NoError(t, err)
+
+	firstBatch := actual.Map()["cursor"].(bson.D).Map()["firstBatch"].(bson.A)
+	require.Len(t, firstBatch, 2)
+
+	expected := bson.D{
+		{"v", int32(2)},
+		{"key", bson.D{{"v", int32(1)}, {"w", int32(-1)}}},
+		{"name", "v_1_w_-1"},
+		{"unique", true},
+	}
+	AssertEqualDocuments(t, expected, firstBatch[1].(bson.D))
+
+	_, err = collection.InsertOne(ctx, bson.D{{"_id", "4"}, {"v", int32(1)}, {"w", "a"}})
+
+	var we mongo.WriteException
+	require.ErrorAs(t, err, &we)
+	require.Len(t, we.WriteErrors, 1)
+	assert.Equal(t, 11000, we.WriteErrors[0].Code)
+	assert.Equal(t, fmt.Sprintf(
+		`E11000 duplicate key error collection: %s.%s index: v_1_w_-1 dup key: { v: 1, w: "a" }`,
+		collection.Database().Name(), collection.Name(),
+	), we.WriteErrors[0].Message)
+
+	// missing fields are indexed as nulls
+	_, err = collection.InsertOne(ctx, bson.D{{"_id", "5"}, {"v", int32(3)}})
+	require.NoError(t, err)
+
+	_, err = collection.InsertOne(ctx, bson.D{{"_id", "6"}, {"v", int32(3)}, {"w", nil}})
+	require.ErrorAs(t, err, &we)
+	require.Len(t, we.WriteErrors, 1)
+	assert.Equal(t, 11000, we.WriteErrors[0].Code)
+	assert.Contains(t, we.WriteErrors[0].Message, `index: v_1_w_-1 dup key: { v: 3, w: null }`)
+
+	// updates are checked too
+	_, err = collection.UpdateOne(ctx, bson.D{{"_id", "3"}}, bson.D{{"$set", bson.D{{"v", int32(1)}}}})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `index: v_1_w_-1 dup key: { v: 1, w: "a" }`)
+
+	assert.Equal(t, []any{"1", "2", "3", "5"}, CollectIDs(t, FindAll(t, ctx, collection)))
+
+	t.Run("ExistingDuplicates", func(t *testing.T) {
+		t.Parallel()
+
+		command := bson.D{
+			{"createIndexes", collection.Name()},
+			{"indexes", bson.A{bson.D{{"key", bson.D{{"v", 1}}}, {"name", "v_1"}, {"unique", true}}}},
+		}
+		err := collection.Database().RunCommand(ctx, command).Err()
+
+		var ce mongo.CommandError
+		require.ErrorAs(t, err, &ce)
+		assert.Equal(t, int32(11000), ce.Code)
+	})
+}
+
+func TestIndexesUniqueSparse(t *testing.T) {
+	setup.SkipForTigrisWithReason(t, "secondary indexes are not implemented yet")
+
+	t.Parallel()
+	ctx, collection := setup.Setup(t)
+
+	command := bson.D{
+		{"createIndexes", collection.Name()},
+		{"indexes", bson.A{bson.D{{"key", bson.D{{"v", 1}}}, {"name", "v_1"}, {"unique", true}, {"sparse", true}}}},
+	}
+	require.NoError(t, collection.Database().RunCommand(ctx, command).Err())
+
+	// documents without the field are not indexed, so they do not conflict
+	_, err := collection.InsertMany(ctx, []any{
+		bson.D{{"_id", "1"}},
+		bson.D{{"_id", "2"}, {"w", int32(1)}},
+		bson.D{{"_id", "3"}},
+		bson.D{{"_id", "4"}, {"v", int32(1)}},
+	})
+	require.NoError(t, err)
+
+	_, err = collection.InsertOne(ctx, bson.D{{"_id", "5"}, {"v", int32(1)}})
+
+	var we mongo.WriteException
+	require.ErrorAs(t, err, &we)
+	require.Len(t, we.WriteErrors, 1)
+	assert.Equal(t, 11000, we.WriteErrors[0].Code)
+	assert.Contains(t, we.WriteErrors[0].Message, `index: v_1 dup key: { v: 1 }`)
+
+	var actual bson.D
+	err = collection.Database().RunCommand(ctx, bson.D{{"listIndexes", collection.Name()}}).Decode(&actual)
+	require.NoError(t, err)
+
+	firstBatch := actual.Map()["cursor"].(bson.D).Map()["firstBatch"].(bson.A)
+	require.Len(t, firstBatch, 2)
+
+	expected := bson.D{
+		{"v", int32(2)},
+		{"key", bson.D{{"v", int32(1)}}},
+		{"name", "v_1"},
+		{"unique", true},
+		{"sparse", true},
+	}
+	AssertEqualDocuments(t, expected, firstBatch[1].(bson.D))
+
+	assert.Equal(t, []any{"1", "2", "3", "4"}, CollectIDs(t, FindAll(t, ctx, collection)))
+}
+
+func TestIndexesPartial(t *testing.T) {
+	setup.SkipForTigrisWithReason(t, "secondary indexes are not implemented yet")
+
+	t.Parallel()
+	ctx, collection := setup.Setup(t)
+
+	_, err := collection.InsertMany(ctx, []any{
+		bson.D{{"_id", "small"}, {"v", int32(5)}},
+		bson.D{{"_id", "big"}, {"v", int32(50)}},
+	})
+	require.NoError(t, err)
+
+	partial := bson.D{{"v", bson.D{{"$gt", int32(10)}}}}
+	command := bson.D{
+		{"createIndexes", collection.Name()},
+		{"indexes", bson.A{bson.D{{"key", bson.D{{"v", 1}}}, {"name", "v_partial"}, {"partialFilterExpression", partial}}}},
+	}
+	require.NoError(t, collection.Database().RunCommand(ctx, command).Err())
+
+	// creating the same index again is a no-op
+	require.NoError(t, collection.Database().RunCommand(ctx, command).Err())
+
+	var actual bson.D
+	err = collection.Database().RunCommand(ctx, bson.D{{"listIndexes", collection.Name()}}).Decode(&actual)
+	require.NoError(t, err)
+
+	firstBatch := actual.Map()["cursor"].(bson.D).Map()["firstBatch"].(bson.A)
+	require.Len(t, firstBatch, 2)
+
+	expected := bson.D{
+		{"v", int32(2)},
+		{"key", bson.D{{"v", int32(1)}}},
+		{"name", "v_partial"},
+		{"partialFilterExpression", partial},
+	}
+	AssertEqualDocuments(t, expected, firstBatch[1].(bson.D))
+
+	// the partial index does not change query results
+	cursor, err := collection.Find(ctx, bson.D{{"v", bson.D{{"$gt", int32(1)}}}}, options.Find().SetSort(bson.D{{"_id", 1}}))
+	require.NoError(t, err)
+
+	var docs []bson.D
+	require.NoError(t, cursor.All(ctx, &docs))
+	assert.Equal(t, []any{"big", "small"}, CollectIDs(t, docs))
+
+	t.Run("Explain", func(t *testing.T) {
+		setup.SkipForMongoWithReason(t, "usable indexes are a FerretDB extension")
+
+		for name, tc := range map[string]struct {
+			filter   bson.D
+			expected bson.A
+		}{
+			"Matching": {
+				filter:   bson.D{{"v", bson.D{{"$gt", int32(20)}}}},
+				expected: bson.A{"v_partial"},
+			},
+			"MatchingEquality": {
+				filter:   bson.D{{"_id", "big"}, {"v", int32(50)}},
+				expected: bson.A{"_id_", "v_partial"},
+			},
+			"MatchingAnd": {
+				filter:   bson.D{{"$and", bson.A{bson.D{{"v", bson.D{{"$gte", 11.5}}}}}}},
+				expected: bson.A{"v_partial"},
+			},
+			"NotMatching": {
+				filter:   bson.D{{"v", bson.D{{"$gt", int32(5)}}}},
+				expected: bson.A{},
+			},
+			"OtherField": {
+				filter:   bson.D{{"w", bson.D{{"$gt", int32(20)}}}},
+				expected: bson.A{},
+			},
+		} {
+			name, tc := name, tc
+			t.Run(name, func(t *testing.T) {
+				t.Parallel()
+
+				var res bson.D
+				command := bson.D{{"explain", bson.D{{"find", collection.Name()}, {"filter", tc.filter}}}}
+				require.NoError(t, collection.Database().RunCommand(ctx, command).Decode(&res))
+
+				ferretdb, ok := res.Map()["ferretdb"].(bson.D)
+				require.True(t, ok, "%v", res)
+				assert.Equal(t, tc.expected, ferretdb.Map()["usableIndexes"])
+			})
+		}
+	})
+}
+
+func TestIndexesList(t *testing.T) {
+	setup.SkipForTigrisWithReason(t, "secondary indexes are not implemented yet")
+
+	t.Parallel()
+	ctx, collection := setup.Setup(t)
+
+	_, err := collection.InsertOne(ctx, bson.D{{"_id", "foo"}})
+	require.NoError(t, err)
+
+	command := bson.D{
+		{"createIndexes", collection.Name()},
+		{"indexes", bson.A{
+			bson.D{{"key", bson.D{{"v", -1}}}, {"name", "v_-1"}},
+			bson.D{{"key", bson.D{{"createdAt", 1}}}, {"name", "ttl"}, {"expireAfterSeconds", int32(3600)}},
+		}},
+	}
+	require.NoError(t, collection.Database().RunCommand(ctx, command).Err())
+
+	var actual bson.D
+	err = collection.Database().RunCommand(ctx, bson.D{{"listIndexes", collection.Name()}}).Decode(&actual)
+	require.NoError(t, err)
+
+	expected := bson.D{
+		{"cursor", bson.D{
+			{"id", int64(0)},
+			{"ns", collection.Database().Name() + "." + collection.Name()},
+			{"firstBatch", bson.A{
+				bson.D{{"v", int32(2)}, {"key", bson.D{{"_id", int32(1)}}}, {"name", "_id_"}},
+				bson.D{{"v", int32(2)}, {"key", bson.D{{"v", int32(-1)}}}, {"name", "v_-1"}},
+				bson.D{
+					{"v", int32(2)},
+					{"key", bson.D{{"createdAt", int32(1)}}},
+					{"name", "ttl"},
+					{"expireAfterSeconds", int32(3600)},
+				},
+			}},
+		}},
+		{"ok", float64(1)},
+	}
+	AssertEqualDocuments(t, expected, actual)
+
+	t.Run("NonExistentCollection", func(t *testing.T) {
+		t.Parallel()
+
+		err := collection.Database().RunCommand(ctx, bson.D{{"listIndexes", "non-existent"}}).Err()
+
+		expected := mongo.CommandError{
+			Code:    26,
+			Name:    "NamespaceNotFound",
+			Message: "ns does not exist: " + collection.Database().Name() + ".non-existent",
+		}
+		AssertEqualError(t, expected, err)
+	})
+}
+
+func TestIndexesDrop(t *testing.T) {
+	setup.SkipForTigrisWithReason(t, "secondary indexes are not implemented yet")
+
+	t.Parallel()
+
+	createIndexes := func(t *testing.T, ctx context.Context, collection *mongo.Collection) {
+		t.Helper()
+
+		_, err := collection.InsertOne(ctx, bson.D{{"_id", "foo"}})
+		require.NoError(t, err)
+
+		command := bson.D{
+			{"createIndexes", collection.Name()},
+			{"indexes", bson.A{
+				bson.D{{"key", bson.D{{"v", 1}}}, {"name", "v_1"}},
+				bson.D{{"key", bson.D{{"w", -1}}}, {"name", "w_-1"}},
+			}},
+		}
+		require.NoError(t, collection.Database().RunCommand(ctx, command).Err())
+	}
+
+	listIndexes := func(t *testing.T, ctx context.Context, collection *mongo.Collection) []string {
+		t.Helper()
+
+		cursor, err := collection.Indexes().List(ctx)
+		require.NoError(t, err)
+
+		var indexes []bson.D
+		require.NoError(t, cursor.All(ctx, &indexes))
+
+		var names []string
+		for _, index := range indexes {
+			names = append(names, index.Map()["name"].(string))
+		}
+
+		return names
+	}
+
+	for name, tc := range map[string]struct {
+		index    any
+		expected bson.D
+		names    []string
+	}{
+		"Name": {
+			index:    "v_1",
+			expected: bson.D{{"nIndexesWas", int32(3)}, {"ok", float64(1)}},
+			names:    []string{"_id_", "w_-1"},
+		},
+		"Key": {
+			index:    bson.D{{"w", -1}},
+			expected: bson.D{{"nIndexesWas", int32(3)}, {"ok", float64(1)}},
+			names:    []string{"_id_", "v_1"},
+		},
+		"All": {
+			index: "*",
+			expected: bson.D{
+				{"nIndexesWas", int32(3)},
+				{"msg", "non-_id indexes dropped for collection"},
+				{"ok", float64(1)},
+			},
+			names: []string{"_id_"},
+		},
+	} {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			ctx, collection := setup.Setup(t)
+
+			createIndexes(t, ctx, collection)
+
+			var actual bson.D
+			command := bson.D{{"dropIndexes", collection.Name()}, {"index", tc.index}}
+			require.NoError(t, collection.Database().RunCommand(ctx, command).Decode(&actual))
+			AssertEqualDocuments(t, tc.expected, actual)
+
+			assert.Equal(t, tc.names, listIndexes(t, ctx, collection))
+		})
+	}
+
+	t.Run("Errors", func(t *testing.T) {
+		t.Parallel()
+		ctx, collection := setup.Setup(t)
+
+		createIndexes(t, ctx, collection)
+
+		for name, tc := range map[string]struct {
+			collection string
+			index      any
+			err        mongo.CommandError
+			alt        string
+		}{
+			"IDIndex": {
+				index: "_id_",
+				err:   mongo.CommandError{Code: 72, Name: "InvalidOptions", Message: "cannot drop _id index"},
+			},
+			"IDKey": {
+				index: bson.D{{"_id", 1}},
+				err:   mongo.CommandError{Code: 72, Name: "InvalidOptions", Message: "cannot drop _id index"},
+			},
+			"NonExistentName": {
+				index: "foo",
+				err:   mongo.CommandError{Code: 27, Name: "IndexNotFound", Message: "index not found with name [foo]"},
+			},
+			"NonExistentKey": {
+				index: bson.D{{"foo", 1}},
+				err:   mongo.CommandError{Code: 27, Name: "IndexNotFound", Message: "can't find index with key: { foo: 1 }"},
+			},
+			"NonExistentCollection": {
+				collection: "non-existent",
+				index:      "v_1",
+				err: mongo.CommandError{
+					Code:    26,
+					Name:    "NamespaceNotFound",
+					Message: "ns not found " + collection.Database().Name() + ".non-existent",
+				},
+			},
+		} {
+			name, tc := name, tc
+			t.Run(name, func(t *testing.T) {
+				c := collection.Name()
+				if tc.collection != "" {
+					c = tc.collection
+				}
+
+				command := bson.D{{"dropIndexes", c}, {"index", tc.index}}
+				err := collection.Database().RunCommand(ctx, command).Err()
+				AssertEqualAltError(t, tc.err, tc.alt, err)
+			})
+		}
+
+		// nothing was dropped
+		assert.Equal(t, []string{"_id_", "v_1", "w_-1"}, listIndexes(t, ctx, collection))
+	})
+}
@@ -0,0 +1,237 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package integration
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/FerretDB/FerretDB/integration/setup"
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+)
+
+func TestInsertTigrisSchemaViolation(t *testing.T) {
+	setup.SkipForMongoWithReason(t, "Tigris-specific schema is used")
+	setup.SkipForPostgresWithReason(t, "Tigris-specific schema is used")
+
+	t.Parallel()
+
+	for name, tc := range map[string]struct {
+		ordered     bool
+		expectedIDs []any
+	}{
+		"Ordered": {
+			ordered:     true,
+			expectedIDs: []any{"1", "2"},
+		},
+		"Unordered": {
+			ordered:     false,
+			expectedIDs: []any{"1", "2", "4"},
+		},
+	} {
+		name, tc := name, tc
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			ctx, collection := setup.Setup(t)
+
+			// the first insert creates the collection with the inferred schema: v is int32
+			_, err := collection.InsertOne(ctx, bson.D{{"_id", "1"}, {"v", int32(1)}})
+			require.NoError(t, err)
+
+			docs := []any{
+				bson.D{{"_id", "2"}, {"v", int32(2)}},
+				bson.D{{"_id", "3"}, {"v", "foo"}},
+				bson.D{{"_id", "4"}, {"v", int32(4)}},
+			}
+			_, err = collection.InsertMany(ctx, docs, options.InsertMany().SetOrdered(tc.ordered))
+
+			var we mongo.BulkWriteException
+			require.ErrorAs(t, err, &we)
+			require.Len(t, we.WriteErrors, 1)
+			assert.Equal(t, 1, we.WriteErrors[0].Index)
+			assert.Equal(t, 121, we.WriteErrors[0].Code) // DocumentValidationFailure
+
+			assert.Equal(t, tc.expectedIDs, CollectIDs(t, FindAll(t, ctx, collection)))
+		})
+	}
+}
+
+func TestInsertNewField(t *testing.T) {
+	t.Parallel()
+	ctx, collection := setup.Setup(t)
+
+	_, err := collection.InsertOne(ctx, bson.D{{"_id", "1"}, {"v", int32(1)}})
+	require.NoError(t, err)
+
+	// for Tigris, that insert evolves the collection schema by adding a new field
+	_, err = collection.InsertOne(ctx, bson.D{{"_id", "2"}, {"v", int32(2)}, {"new", "foo"}})
+	require.NoError(t, err)
+
+	cursor, err := collection.Find(ctx, bson.D{{"new", "foo"}})
+	require.NoError(t, err)
+
+	expected := []bson.D{{{"_id", "2"}, {"v", int32(2)}, {"new", "foo"}}}
+	AssertEqualDocumentsSlice(t, expected, FetchAll(t, ctx, cursor))
+
+	assert.Equal(t, []any{"1", "2"}, CollectIDs(t, FindAll(t, ctx, collection)))
+}
+
+func TestInsertManyNewFields(t *testing.T) {
+	t.Parallel()
+	ctx, collection := setup.Setup(t)
+
+	_, err := collection.InsertOne(ctx, bson.D{{"_id", "1"}, {"v", int32(1)}})
+	require.NoError(t, err)
+
+	// for Tigris, new fields of all documents are added to the collection schema at once
+	docs := []any{
+		bson.D{{"_id", "2"}, {"v", int32(2)}, {"foo", "a"}},
+		bson.D{{"_id", "3"}, {"v", int32(3)}, {"bar", int32(42)}},
+		bson.D{{"_id", "4"}, {"v", int32(4)}, {"foo", "b"}, {"baz", bson.D{{"qux", "c"}}}},
+	}
+	res, err := collection.InsertMany(ctx, docs)
+	require.NoError(t, err)
+	assert.Len(t, res.InsertedIDs, 3)
+
+	cursor, err := collection.Find(ctx, bson.D{{"bar", int32(42)}})
+	require.NoError(t, err)
+
+	expected := []bson.D{{{"_id", "3"}, {"v", int32(3)}, {"bar", int32(42)}}}
+	AssertEqualDocumentsSlice(t, expected, FetchAll(t, ctx, cursor))
+
+	assert.Equal(t, []any{"1", "2", "3", "4"}, CollectIDs(t, FindAll(t, ctx, collection)))
+}
+
+func TestInsertOrderedDuplicateKey(t *testing.T) {
+	setup.SkipForTigris(t)
+
+	t.Parallel()
+
+	for name, tc := range map[string]struct {
+		ordered     bool
+		expectedN   int32
+		expectedIDs []any
+	}{
+		"Ordered": {
+			ordered:     true,
+			expectedN:   2,
+			expectedIDs: []any{"1", "2"},
+		},
+		"Unordered": {
+			ordered:     false,
+			expectedN:   4,
+			expectedIDs: []any{"1", "2", "4", "5"},
+		},
+	} {
+		name, tc := name, tc
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			ctx, collection := setup.Setup(t)
+
+			var res bson.D
+			err := collection.Database().RunCommand(ctx, bson.D{
+				{"insert", collection.Name()},
+				{"documents", bson.A{
+					bson.D{{"_id", "1"}},
+					bson.D{{"_id", "2"}},
+					bson.D{{"_id", "1"}},
+					bson.D{{"_id", "4"}},
+					bson.D{{"_id", "5"}},
+				}},
+				{"ordered", tc.ordered},
+			}).Decode(&res)
+			require.NoError(t, err)
+
+			actual := ConvertDocument(t, res)
+			assert.Equal(t, tc.expectedN, must.NotFail(actual.Get("n")))
+
+			writeErrors := must.NotFail(actual.Get("writeErrors")).(*types.Array)
+			require.Equal(t, 1, writeErrors.Len())
+
+			writeError := must.NotFail(writeErrors.Get(0)).(*types.Document)
+			assert.Equal(t, int32(2), must.NotFail(writeError.Get("index")))
+			assert.Equal(t, int32(11000), must.NotFail(writeError.Get("code")))
+			assert.Contains(t, must.NotFail(writeError.Get("errmsg")), `dup key: { _id: "1" }`)
+
+			assert.Equal(t, tc.expectedIDs, CollectIDs(t, FindAll(t, ctx, collection)))
+		})
+	}
+}
+
+func TestInsertMaxNestingDepth(t *testing.T) {
+	t.Parallel()
+	ctx, collection := setup.Setup(t)
+
+	// nested returns a document with the given number of levels, including itself.
+	nested := func(id string, levels int) bson.D {
+		var v any = "foo"
+		for i := 1; i < levels; i++ {
+			v = bson.D{{"v", v}}
+		}
+		return bson.D{{"_id", id}, {"v", v}}
+	}
+
+	var res bson.D
+	err := collection.Database().RunCommand(ctx, bson.D{
+		{"insert", collection.Name()},
+		{"documents", bson.A{
+			nested("limit", 100),
+			nested("exceeded", 101),
+			nested("next", 2),
+		}},
+		{"ordered", false},
+	}).Decode(&res)
+	require.NoError(t, err)
+
+	actual := ConvertDocument(t, res)
+	assert.Equal(t, int32(2), must.NotFail(actual.Get("n")))
+
+	writeErrors := must.NotFail(actual.Get("writeErrors")).(*types.Array)
+	require.Equal(t, 1, writeErrors.Len())
+
+	writeError := must.NotFail(writeErrors.Get(0)).(*types.Document)
+	assert.Equal(t, int32(1), must.NotFail(writeError.Get("index")))
+	assert.Equal(t, int32(15), must.NotFail(writeError.Get("code"))) // Overflow
+	assert.Equal(t, "cannot insert document because it exceeds 100 levels of nesting", must.NotFail(writeError.Get("errmsg")))
+
+	assert.Equal(t, []any{"limit", "next"}, CollectIDs(t, FindAll(t, ctx, collection)))
+}
+
+func TestInsertLargeDocument(t *testing.T) {
+	setup.SkipForTigrisWithReason(t, "Tigris limits the document size")
+
+	t.Parallel()
+	ctx, collection := setup.Setup(t)
+
+	// about 9 MB, much larger than PostgreSQL's TOAST threshold
+	doc := bson.D{{"_id", "large"}, {"v", strings.Repeat("FerretDB ", 1024*1024)}}
+	_, err := collection.InsertOne(ctx, doc)
+	require.NoError(t, err)
+
+	var actual bson.D
+	require.NoError(t, collection.FindOne(ctx, bson.D{{"_id", "large"}}).Decode(&actual))
+	assert.Equal(t, doc, actual)
+}
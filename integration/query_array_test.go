@@ -114,6 +114,34 @@ func TestQueryArraySize(t *testing.T) {
 				Message: `$size may not be negative`,
 			},
 		},
+		"NegativeScalarField": {
+			filter: bson.D{{"_id", "string"}, {"v", bson.D{{"$size", -1}}}},
+			err: &mongo.CommandError{
+				Code:    2,
+				Name:    "BadValue",
+				Message: `$size may not be negative`,
+			},
+		},
+		"ScalarField": {
+			filter:      bson.D{{"_id", "string"}, {"v", bson.D{{"$size", 2}}}},
+			expectedIDs: []any{},
+		},
+		"NestedField": {
+			filter:      bson.D{{"v.v", bson.D{{"$size", 2}}}},
+			expectedIDs: []any{"document"},
+		},
+		"WithRange": {
+			filter:      bson.D{{"v", bson.D{{"$size", 3}, {"$gt", "1"}}}},
+			expectedIDs: []any{"array-three"},
+		},
+		"RangeAsValue": {
+			filter: bson.D{{"v", bson.D{{"$size", bson.D{{"$gte", 1}, {"$lte", 3}}}}}},
+			err: &mongo.CommandError{
+				Code:    2,
+				Name:    "BadValue",
+				Message: `$size needs a number`,
+			},
+		},
 		"InvalidUse": {
 			filter: bson.D{{"$size", 2}},
 			err: &mongo.CommandError{
@@ -235,6 +263,69 @@ func TestQueryArrayDotNotation(t *testing.T) {
 	}
 }
 
+func TestQueryArrayDotNotationDocuments(t *testing.T) {
+	setup.SkipForTigrisWithReason(t, "arrays of documents with different fields are not supported by Tigris schema")
+
+	t.Parallel()
+	ctx, collection := setup.Setup(t)
+
+	_, err := collection.InsertMany(ctx, []any{
+		bson.D{{"_id", "items"}, {"items", bson.A{
+			bson.D{{"price", int32(5)}},
+			bson.D{{"price", int32(10)}, {"tags", bson.A{"a", "b"}}},
+		}}},
+		bson.D{{"_id", "nested"}, {"orders", bson.A{
+			bson.D{{"items", bson.A{bson.D{{"price", int32(1)}}}}},
+			bson.D{{"items", bson.A{bson.D{{"price", int32(2)}}, bson.D{{"price", int32(10)}}}}},
+		}}},
+		bson.D{{"_id", "document"}, {"items", bson.D{{"price", int32(10)}}}},
+	})
+	require.NoError(t, err)
+
+	for name, tc := range map[string]struct {
+		filter      bson.D
+		expectedIDs []any
+	}{
+		"ArrayOfDocuments": {
+			filter:      bson.D{{"items.price", int32(10)}},
+			expectedIDs: []any{"document", "items"},
+		},
+		"Index": {
+			filter:      bson.D{{"items.0.price", int32(5)}},
+			expectedIDs: []any{"items"},
+		},
+		"MultiLevel": {
+			filter:      bson.D{{"orders.items.price", int32(10)}},
+			expectedIDs: []any{"nested"},
+		},
+		"MultiLevelOperators": {
+			filter:      bson.D{{"orders.items.price", bson.D{{"$gt", int32(1)}, {"$lt", int32(3)}}}},
+			expectedIDs: []any{"nested"},
+		},
+		"ArrayInArray": {
+			filter:      bson.D{{"items.tags", "b"}},
+			expectedIDs: []any{"items"},
+		},
+		"Ne": {
+			filter:      bson.D{{"items.price", bson.D{{"$ne", int32(5)}}}},
+			expectedIDs: []any{"document", "nested"},
+		},
+	} {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			cursor, err := collection.Find(ctx, tc.filter, options.Find().SetSort(bson.D{{"_id", 1}}))
+			require.NoError(t, err)
+
+			var actual []bson.D
+			err = cursor.All(ctx, &actual)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expectedIDs, CollectIDs(t, actual))
+		})
+	}
+}
+
 func TestQueryElemMatchOperator(t *testing.T) {
 	setup.SkipForTigris(t)
 
@@ -352,6 +443,85 @@ func TestQueryElemMatchOperator(t *testing.T) {
 	}
 }
 
+func TestQueryElemMatchDocuments(t *testing.T) {
+	setup.SkipForTigris(t)
+
+	t.Parallel()
+	ctx, collection := setup.Setup(t)
+
+	_, err := collection.InsertMany(ctx, []any{
+		bson.D{{"_id", "documents"}, {"v", bson.A{
+			bson.D{{"a", int32(1)}, {"b", int32(5)}},
+			bson.D{{"a", int32(2)}, {"b", int32(1)}},
+		}}},
+		bson.D{{"_id", "nested"}, {"v", bson.A{
+			bson.D{{"a", bson.A{bson.D{{"c", int32(1)}}, bson.D{{"c", int32(2)}}}}},
+			bson.D{{"a", bson.A{bson.D{{"c", int32(3)}}}}},
+		}}},
+		bson.D{{"_id", "nested-arrays"}, {"v", bson.A{bson.A{int32(1), int32(2)}, bson.A{int32(8), int32(9)}}}},
+		bson.D{{"_id", "scalars"}, {"v", bson.A{int32(3), int32(7), int32(12)}}},
+		bson.D{{"_id", "document"}, {"v", bson.D{{"a", int32(1)}, {"b", int32(5)}}}},
+		bson.D{{"_id", "scalar"}, {"v", int32(7)}},
+	})
+	require.NoError(t, err)
+
+	for name, tc := range map[string]struct {
+		elemMatch   bson.D
+		expectedIDs []any
+	}{
+		"Query": {
+			elemMatch:   bson.D{{"a", int32(1)}, {"b", bson.D{{"$gt", int32(2)}}}},
+			expectedIDs: []any{"documents"},
+		},
+		"QueryDifferentElements": {
+			elemMatch:   bson.D{{"a", int32(2)}, {"b", bson.D{{"$gt", int32(2)}}}},
+			expectedIDs: []any{},
+		},
+		"QueryOr": {
+			elemMatch:   bson.D{{"$or", bson.A{bson.D{{"a", int32(3)}}, bson.D{{"b", int32(1)}}}}},
+			expectedIDs: []any{"documents"},
+		},
+		"Operators": {
+			elemMatch:   bson.D{{"$gt", int32(5)}, {"$lt", int32(10)}},
+			expectedIDs: []any{"scalars"},
+		},
+		"OperatorsDifferentElements": {
+			elemMatch:   bson.D{{"$gt", int32(4)}, {"$lt", int32(6)}},
+			expectedIDs: []any{},
+		},
+		"NestedQuery": {
+			elemMatch:   bson.D{{"a", bson.D{{"$elemMatch", bson.D{{"c", int32(3)}}}}}},
+			expectedIDs: []any{"nested"},
+		},
+		"NestedQueryNoMatch": {
+			elemMatch:   bson.D{{"a", bson.D{{"$elemMatch", bson.D{{"c", int32(4)}}}}}},
+			expectedIDs: []any{},
+		},
+		"NestedOperators": {
+			elemMatch:   bson.D{{"$elemMatch", bson.D{{"$gt", int32(8)}}}},
+			expectedIDs: []any{"nested-arrays"},
+		},
+		"NestedOperatorsNoMatch": {
+			elemMatch:   bson.D{{"$elemMatch", bson.D{{"$gt", int32(9)}}}},
+			expectedIDs: []any{},
+		},
+	} {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			filter := bson.D{{"v", bson.D{{"$elemMatch", tc.elemMatch}}}}
+			cursor, err := collection.Find(ctx, filter, options.Find().SetSort(bson.D{{"_id", 1}}))
+			require.NoError(t, err)
+
+			var actual []bson.D
+			err = cursor.All(ctx, &actual)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expectedIDs, CollectIDs(t, actual))
+		})
+	}
+}
+
 func TestArrayEquality(t *testing.T) {
 	setup.SkipForTigris(t)
 
@@ -378,6 +548,22 @@ func TestArrayEquality(t *testing.T) {
 			array:       bson.A{nil, "foo", int32(42)},
 			expectedIDs: []any{"array-three-reverse"},
 		},
+		"ThreeWrongOrder": {
+			array:       bson.A{"foo", int32(42), nil},
+			expectedIDs: []any{},
+		},
+		"ThreeDouble": {
+			array:       bson.A{42.0, "foo", nil},
+			expectedIDs: []any{"array-three"},
+		},
+		"ThreePrefix": {
+			array:       bson.A{int32(42), "foo", nil, nil},
+			expectedIDs: []any{},
+		},
+		"NaN": {
+			array:       bson.A{42.13, math.NaN()},
+			expectedIDs: []any{"array-two"},
+		},
 		"Empty": {
 			array:       bson.A{},
 			expectedIDs: []any{"array-empty", "array-empty-nested"},
@@ -423,6 +609,50 @@ func TestArrayEquality(t *testing.T) {
 	}
 }
 
+func TestArrayEqualityScalar(t *testing.T) {
+	setup.SkipForTigris(t)
+
+	t.Parallel()
+	ctx, collection := setup.Setup(t, shareddata.Composites)
+
+	for name, tc := range map[string]struct {
+		value       any
+		expectedIDs []any
+	}{
+		"String": {
+			value:       "foo",
+			expectedIDs: []any{"array-three", "array-three-reverse"},
+		},
+		"Int32": {
+			value:       int32(42),
+			expectedIDs: []any{"array", "array-three", "array-three-reverse"},
+		},
+		"Double": {
+			value:       42.0,
+			expectedIDs: []any{"array", "array-three", "array-three-reverse"},
+		},
+		"NotFound": {
+			value:       "bar",
+			expectedIDs: []any{},
+		},
+	} {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// scalar matches array elements, but not elements of embedded arrays
+			filter := bson.D{{"v", tc.value}}
+			cursor, err := collection.Find(ctx, filter, options.Find().SetSort(bson.D{{"_id", 1}}))
+			require.NoError(t, err)
+
+			var actual []bson.D
+			err = cursor.All(ctx, &actual)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expectedIDs, CollectIDs(t, actual))
+		})
+	}
+}
+
 // TestQueryArrayAll covers the case where the $all operator is used on an array or scalar.
 func TestQueryArrayAll(t *testing.T) {
 	setup.SkipForTigris(t)
@@ -606,3 +836,87 @@ func TestQueryArrayAll(t *testing.T) {
 		})
 	}
 }
+
+func TestQueryArrayAllElemMatch(t *testing.T) {
+	setup.SkipForTigris(t)
+
+	t.Parallel()
+	ctx, collection := setup.Setup(t)
+
+	_, err := collection.InsertMany(ctx, []any{
+		bson.D{{"_id", "tags"}, {"tags", bson.A{"a", "b", "c"}}},
+		bson.D{{"_id", "tag"}, {"tags", "a"}},
+		bson.D{{"_id", "items"}, {"items", bson.A{
+			bson.D{{"size", "M"}, {"qty", int32(5)}},
+			bson.D{{"size", "L"}, {"qty", int32(1)}},
+		}}},
+	})
+	require.NoError(t, err)
+
+	for name, tc := range map[string]struct {
+		filter      bson.D
+		expectedIDs []any
+		err         *mongo.CommandError
+	}{
+		"Tags": {
+			filter:      bson.D{{"tags", bson.D{{"$all", bson.A{"a", "b"}}}}},
+			expectedIDs: []any{"tags"},
+		},
+		"ScalarSingle": {
+			filter:      bson.D{{"tags", bson.D{{"$all", bson.A{"a"}}}}},
+			expectedIDs: []any{"tag", "tags"},
+		},
+		"ElemMatch": {
+			filter: bson.D{{"items", bson.D{{"$all", bson.A{
+				bson.D{{"$elemMatch", bson.D{{"size", "M"}, {"qty", bson.D{{"$gt", int32(2)}}}}}},
+				bson.D{{"$elemMatch", bson.D{{"qty", int32(1)}}}},
+			}}}}},
+			expectedIDs: []any{"items"},
+		},
+		"ElemMatchNoMatch": {
+			filter: bson.D{{"items", bson.D{{"$all", bson.A{
+				bson.D{{"$elemMatch", bson.D{{"size", "M"}, {"qty", bson.D{{"$gt", int32(2)}}}}}},
+				bson.D{{"$elemMatch", bson.D{{"qty", int32(2)}}}},
+			}}}}},
+			expectedIDs: []any{},
+		},
+		"ElemMatchInconsistent": {
+			filter: bson.D{{"items", bson.D{{"$all", bson.A{
+				bson.D{{"$elemMatch", bson.D{{"qty", int32(1)}}}},
+				bson.D{{"qty", int32(1)}},
+			}}}}},
+			err: &mongo.CommandError{
+				Code:    2,
+				Name:    "BadValue",
+				Message: "$all/$elemMatch has to be consistent",
+			},
+		},
+		"ElemMatchNotFirst": {
+			filter: bson.D{{"items", bson.D{{"$all", bson.A{
+				bson.D{{"qty", int32(1)}},
+				bson.D{{"$elemMatch", bson.D{{"qty", int32(1)}}}},
+			}}}}},
+			err: &mongo.CommandError{
+				Code:    2,
+				Name:    "BadValue",
+				Message: "no $ expressions in $all",
+			},
+		},
+	} {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			cursor, err := collection.Find(ctx, tc.filter, options.Find().SetSort(bson.D{{"_id", 1}}))
+			if tc.err != nil {
+				AssertEqualError(t, *tc.err, err)
+				return
+			}
+			require.NoError(t, err)
+
+			var actual []bson.D
+			require.NoError(t, cursor.All(ctx, &actual))
+			assert.Equal(t, tc.expectedIDs, CollectIDs(t, actual))
+		})
+	}
+}
@@ -35,12 +35,6 @@ func TestQueryBitwiseAllClear(t *testing.T) {
 	t.Parallel()
 	ctx, collection := setup.Setup(t, shareddata.Scalars)
 
-	// TODO: https://github.com/FerretDB/FerretDB/issues/508
-	_, err := collection.DeleteOne(ctx, bson.D{{"_id", "binary"}})
-	require.NoError(t, err)
-	_, err = collection.DeleteOne(ctx, bson.D{{"_id", "binary-empty"}})
-	require.NoError(t, err)
-
 	for name, tc := range map[string]struct {
 		value       any
 		expectedIDs []any
@@ -50,6 +44,7 @@ func TestQueryBitwiseAllClear(t *testing.T) {
 		"Array": {
 			value: primitive.A{1, 5},
 			expectedIDs: []any{
+				"binary-empty",
 				"double-big", "double-negative-zero", "double-zero",
 				"int32-min", "int32-zero",
 				"int64-big", "int64-min", "int64-zero",
@@ -83,6 +78,7 @@ func TestQueryBitwiseAllClear(t *testing.T) {
 		"DoubleWhole": {
 			value: 2.0,
 			expectedIDs: []any{
+				"binary-empty",
 				"double-big", "double-negative-zero", "double-zero",
 				"int32-min", "int32-zero",
 				"int64-big", "int64-min", "int64-zero",
@@ -110,6 +106,7 @@ func TestQueryBitwiseAllClear(t *testing.T) {
 		"Binary": {
 			value: primitive.Binary{Data: []byte{2}},
 			expectedIDs: []any{
+				"binary-empty",
 				"double-big", "double-negative-zero", "double-zero",
 				"int32-min", "int32-zero",
 				"int64-big", "int64-min", "int64-zero",
@@ -118,6 +115,7 @@ func TestQueryBitwiseAllClear(t *testing.T) {
 		"BinaryWithZeroBytes": {
 			value: primitive.Binary{Data: []byte{0, 0, 2}},
 			expectedIDs: []any{
+				"binary", "binary-empty",
 				"double-big", "double-negative-zero", "double-whole", "double-zero",
 				"int32", "int32-min", "int32-zero",
 				"int64", "int64-big", "int64-min", "int64-zero",
@@ -126,6 +124,7 @@ func TestQueryBitwiseAllClear(t *testing.T) {
 		"Binary9Bytes": {
 			value: primitive.Binary{Data: []byte{1, 2, 3, 4, 5, 6, 7, 8, 9}},
 			expectedIDs: []any{
+				"binary-empty",
 				"double-big", "double-negative-zero", "double-whole", "double-zero",
 				"int32", "int32-zero",
 				"int64", "int64-big", "int64-zero",
@@ -135,6 +134,7 @@ func TestQueryBitwiseAllClear(t *testing.T) {
 		"Int32": {
 			value: int32(2),
 			expectedIDs: []any{
+				"binary-empty",
 				"double-big", "double-negative-zero", "double-zero",
 				"int32-min", "int32-zero",
 				"int64-big", "int64-min", "int64-zero",
@@ -152,6 +152,7 @@ func TestQueryBitwiseAllClear(t *testing.T) {
 		"Int64Max": {
 			value: math.MaxInt64,
 			expectedIDs: []any{
+				"binary-empty",
 				"double-negative-zero", "double-zero",
 				"int32-zero",
 				"int64-min", "int64-zero",
@@ -193,12 +194,6 @@ func TestQueryBitwiseAllSet(t *testing.T) {
 	t.Parallel()
 	ctx, collection := setup.Setup(t, shareddata.Scalars)
 
-	// TODO: https://github.com/FerretDB/FerretDB/issues/508
-	_, err := collection.DeleteOne(ctx, bson.D{{"_id", "binary"}})
-	require.NoError(t, err)
-	_, err = collection.DeleteOne(ctx, bson.D{{"_id", "binary-empty"}})
-	require.NoError(t, err)
-
 	for name, tc := range map[string]struct {
 		value       any
 		expectedIDs []any
@@ -207,7 +202,7 @@ func TestQueryBitwiseAllSet(t *testing.T) {
 	}{
 		"Array": {
 			value:       primitive.A{1, 5},
-			expectedIDs: []any{"double-whole", "int32", "int32-max", "int64", "int64-max"},
+			expectedIDs: []any{"binary", "double-whole", "int32", "int32-max", "int64", "int64-max"},
 		},
 		"ArrayNegativeBitPositionValue": {
 			value: primitive.A{-1},
@@ -236,7 +231,7 @@ func TestQueryBitwiseAllSet(t *testing.T) {
 		},
 		"DoubleWhole": {
 			value:       2.0,
-			expectedIDs: []any{"double-whole", "int32", "int32-max", "int64", "int64-max"},
+			expectedIDs: []any{"binary", "double-whole", "int32", "int32-max", "int64", "int64-max"},
 		},
 		"DoubleNegativeValue": {
 			value: -1.0,
@@ -259,7 +254,7 @@ func TestQueryBitwiseAllSet(t *testing.T) {
 
 		"Binary": {
 			value:       primitive.Binary{Data: []byte{2}},
-			expectedIDs: []any{"double-whole", "int32", "int32-max", "int64", "int64-max"},
+			expectedIDs: []any{"binary", "double-whole", "int32", "int32-max", "int64", "int64-max"},
 		},
 		"BinaryWithZeroBytes": {
 			value:       primitive.Binary{Data: []byte{0, 0, 2}},
@@ -268,7 +263,7 @@ func TestQueryBitwiseAllSet(t *testing.T) {
 
 		"Int32": {
 			value:       int32(2),
-			expectedIDs: []any{"double-whole", "int32", "int32-max", "int64", "int64-max"},
+			expectedIDs: []any{"binary", "double-whole", "int32", "int32-max", "int64", "int64-max"},
 		},
 		"Int32NegativeValue": {
 			value: int32(-1),
@@ -319,12 +314,6 @@ func TestQueryBitwiseAnyClear(t *testing.T) {
 	t.Parallel()
 	ctx, collection := setup.Setup(t, shareddata.Scalars)
 
-	// TODO: https://github.com/FerretDB/FerretDB/issues/508
-	_, err := collection.DeleteOne(ctx, bson.D{{"_id", "binary"}})
-	require.NoError(t, err)
-	_, err = collection.DeleteOne(ctx, bson.D{{"_id", "binary-empty"}})
-	require.NoError(t, err)
-
 	for name, tc := range map[string]struct {
 		value       any
 		expectedIDs []any
@@ -334,6 +323,7 @@ func TestQueryBitwiseAnyClear(t *testing.T) {
 		"Array": {
 			value: primitive.A{1, 5},
 			expectedIDs: []any{
+				"binary-empty",
 				"double-big", "double-negative-zero", "double-zero",
 				"int32-min", "int32-zero",
 				"int64-big", "int64-min", "int64-zero",
@@ -367,6 +357,7 @@ func TestQueryBitwiseAnyClear(t *testing.T) {
 		"DoubleWhole": {
 			value: 2.0,
 			expectedIDs: []any{
+				"binary-empty",
 				"double-big", "double-negative-zero", "double-zero",
 				"int32-min", "int32-zero",
 				"int64-big", "int64-min", "int64-zero",
@@ -394,6 +385,7 @@ func TestQueryBitwiseAnyClear(t *testing.T) {
 		"Binary": {
 			value: primitive.Binary{Data: []byte{2}},
 			expectedIDs: []any{
+				"binary-empty",
 				"double-big", "double-negative-zero", "double-zero",
 				"int32-min", "int32-zero",
 				"int64-big", "int64-min", "int64-zero",
@@ -402,6 +394,7 @@ func TestQueryBitwiseAnyClear(t *testing.T) {
 		"BinaryWithZeroBytes": {
 			value: primitive.Binary{Data: []byte{0, 0, 2}},
 			expectedIDs: []any{
+				"binary", "binary-empty",
 				"double-big", "double-negative-zero", "double-whole", "double-zero",
 				"int32", "int32-min", "int32-zero",
 				"int64", "int64-big", "int64-min", "int64-zero",
@@ -411,6 +404,7 @@ func TestQueryBitwiseAnyClear(t *testing.T) {
 		"Int32": {
 			value: int32(2),
 			expectedIDs: []any{
+				"binary-empty",
 				"double-big", "double-negative-zero", "double-zero",
 				"int32-min", "int32-zero",
 				"int64-big", "int64-min", "int64-zero",
@@ -428,6 +422,7 @@ func TestQueryBitwiseAnyClear(t *testing.T) {
 		"Int64Max": {
 			value: math.MaxInt64,
 			expectedIDs: []any{
+				"binary", "binary-empty",
 				"double-big", "double-negative-zero", "double-whole", "double-zero",
 				"int32", "int32-max", "int32-min", "int32-zero",
 				"int64", "int64-big", "int64-min", "int64-zero",
@@ -469,12 +464,6 @@ func TestQueryBitwiseAnySet(t *testing.T) {
 	t.Parallel()
 	ctx, collection := setup.Setup(t, shareddata.Scalars)
 
-	// TODO: https://github.com/FerretDB/FerretDB/issues/508
-	_, err := collection.DeleteOne(ctx, bson.D{{"_id", "binary"}})
-	require.NoError(t, err)
-	_, err = collection.DeleteOne(ctx, bson.D{{"_id", "binary-empty"}})
-	require.NoError(t, err)
-
 	for name, tc := range map[string]struct {
 		value       any
 		expectedIDs []any
@@ -484,6 +473,7 @@ func TestQueryBitwiseAnySet(t *testing.T) {
 		"Array": {
 			value: primitive.A{1, 5},
 			expectedIDs: []any{
+				"binary",
 				"double-whole",
 				"int32", "int32-max",
 				"int64", "int64-max",
@@ -517,6 +507,7 @@ func TestQueryBitwiseAnySet(t *testing.T) {
 		"DoubleWhole": {
 			value: 2.0,
 			expectedIDs: []any{
+				"binary",
 				"double-whole",
 				"int32", "int32-max",
 				"int64", "int64-max",
@@ -543,7 +534,7 @@ func TestQueryBitwiseAnySet(t *testing.T) {
 
 		"Binary": {
 			value:       primitive.Binary{Data: []byte{2}},
-			expectedIDs: []any{"double-whole", "int32", "int32-max", "int64", "int64-max"},
+			expectedIDs: []any{"binary", "double-whole", "int32", "int32-max", "int64", "int64-max"},
 		},
 		"BinaryWithZeroBytes": {
 			value:       primitive.Binary{Data: []byte{0, 0, 2}},
@@ -553,6 +544,7 @@ func TestQueryBitwiseAnySet(t *testing.T) {
 		"Int32": {
 			value: int32(2),
 			expectedIDs: []any{
+				"binary",
 				"double-whole",
 				"int32", "int32-max",
 				"int64", "int64-max",
@@ -570,6 +562,7 @@ func TestQueryBitwiseAnySet(t *testing.T) {
 		"Int64Max": {
 			value: math.MaxInt64,
 			expectedIDs: []any{
+				"binary",
 				"double-big", "double-whole",
 				"int32", "int32-max", "int32-min",
 				"int64", "int64-big", "int64-max",
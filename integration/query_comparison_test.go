@@ -1581,6 +1581,14 @@ func TestQueryComparisonIn(t *testing.T) {
 				Message: `cannot nest $ under $in`,
 			},
 		},
+		"MixedTypes": {
+			value: bson.A{int64(42), "foo", primitive.Regex{Pattern: "^42"}},
+			expectedIDs: []any{
+				"array", "array-three", "array-three-reverse",
+				"double-whole", "int32", "int64",
+				"string", "string-double", "string-whole",
+			},
+		},
 		"Regex": {
 			value:       bson.A{primitive.Regex{Pattern: "foo", Options: "i"}},
 			expectedIDs: []any{"array-three", "array-three-reverse", "regex", "string"},
@@ -223,6 +223,38 @@ func TestQueryElementType(t *testing.T) {
 			v:           []any{5, "binData"},
 			expectedIDs: []any{"binary", "binary-empty"},
 		},
+		"TypeArrayAliasAndCodeDifferent": {
+			v:           []any{"bool", 5},
+			expectedIDs: []any{"binary", "binary-empty", "bool-false", "bool-true"},
+		},
+		"LongTypeCode": {
+			v:           int64(8),
+			expectedIDs: []any{"bool-false", "bool-true"},
+		},
+		"NotSupportedAlias": {
+			v:           "javascript",
+			expectedIDs: []any{},
+		},
+		"NotSupportedCode": {
+			v:           []any{13, "minKey", 127},
+			expectedIDs: []any{},
+		},
+		"BadType": {
+			v: true,
+			err: &mongo.CommandError{
+				Code:    14,
+				Message: "type must be represented as a number or a string",
+				Name:    "TypeMismatch",
+			},
+		},
+		"TypeArrayBadType": {
+			v: []any{"bool", bson.D{}},
+			err: &mongo.CommandError{
+				Code:    14,
+				Message: "type must be represented as a number or a string",
+				Name:    "TypeMismatch",
+			},
+		},
 		"TypeArrayBadValue": {
 			v: []any{"binData", -123},
 			err: &mongo.CommandError{
@@ -288,3 +320,77 @@ func TestQueryElementType(t *testing.T) {
 		})
 	}
 }
+
+func TestQueryElementTypeNull(t *testing.T) {
+	setup.SkipForTigrisWithReason(t, "null values are not supported by Tigris schema")
+
+	t.Parallel()
+	ctx, collection := setup.Setup(t)
+
+	_, err := collection.InsertMany(ctx, []any{
+		bson.D{{"_id", "missing"}},
+		bson.D{{"_id", "null"}, {"v", nil}},
+		bson.D{{"_id", "value"}, {"v", int32(42)}},
+	})
+	require.NoError(t, err)
+
+	for name, tc := range map[string]struct {
+		v           any
+		expectedIDs []any
+	}{
+		"Alias": {
+			v:           "null",
+			expectedIDs: []any{"null"},
+		},
+		"Code": {
+			v:           10,
+			expectedIDs: []any{"null"},
+		},
+		"Array": {
+			v:           []any{"null", "int"},
+			expectedIDs: []any{"null", "value"},
+		},
+	} {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			filter := bson.D{{"v", bson.D{{"$type", tc.v}}}}
+			cursor, err := collection.Find(ctx, filter, options.Find().SetSort(bson.D{{"_id", 1}}))
+			require.NoError(t, err)
+
+			var actual []bson.D
+			err = cursor.All(ctx, &actual)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expectedIDs, CollectIDs(t, actual))
+		})
+	}
+}
+
+func TestQueryElementTypeUndefined(t *testing.T) {
+	setup.SkipForMongoWithReason(t, "MongoDB supports the deprecated undefined type")
+
+	t.Parallel()
+	ctx, collection := setup.Setup(t, shareddata.Strings)
+
+	for name, v := range map[string]any{
+		"Alias":      "undefined",
+		"Code":       6,
+		"FloatCode":  6.0,
+		"ArrayAlias": []any{"string", "undefined"},
+	} {
+		name, v := name, v
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := collection.Find(ctx, bson.D{{"v", bson.D{{"$type", v}}}})
+			expected := mongo.CommandError{
+				Code: 2,
+				Name: "BadValue",
+				Message: `Type 'undefined' is deprecated and not supported; ` +
+					`use {$type: "null"} to match explicit null values or {$exists: false} to match missing fields`,
+			}
+			AssertEqualError(t, expected, err)
+		})
+	}
+}
@@ -278,6 +278,26 @@ func TestQueryEvaluationMod(t *testing.T) {
 				Message: `divisor cannot be 0`,
 			},
 		},
+		"DivisorZeroNonNumeric": {
+			filter: bson.D{{"_id", "String"}, {"v", bson.D{{"$mod", bson.A{0, 1}}}}},
+			err: &mongo.CommandError{
+				Code:    2,
+				Name:    "BadValue",
+				Message: `divisor cannot be 0`,
+			},
+		},
+		"NotArray": {
+			filter: bson.D{{"v", bson.D{{"$mod", 4}}}},
+			err: &mongo.CommandError{
+				Code:    2,
+				Name:    "BadValue",
+				Message: `malformed mod, needs to be an array`,
+			},
+		},
+		"Multiples": {
+			filter:      bson.D{{"v", bson.D{{"$mod", bson.A{4080, 0}}}}},
+			expectedIDs: []any{"Int32_1", "Int32_2", "Int32_3", "NegativeZero", "SmallestNonzeroFloat64", "Zero"},
+		},
 		"ZeroNegativeDevisor": {
 			filter: bson.D{{"v", bson.D{{"$mod", bson.A{math.Copysign(0, -1), 1}}}}},
 			err: &mongo.CommandError{
@@ -424,6 +444,7 @@ func TestQueryEvaluationRegex(t *testing.T) {
 			{"_id", "document-nested-strings"},
 			{"v", bson.D{{"foo", bson.D{{"bar", "quz"}}}}},
 		},
+		bson.D{{"_id", "array-strings"}, {"a", bson.A{int32(42), "baz", "Foo Bar"}}},
 	})
 	require.NoError(t, err)
 
@@ -463,6 +484,22 @@ func TestQueryEvaluationRegex(t *testing.T) {
 			filter:      bson.D{{"no-such-field", bson.D{{"$regex", "foo"}}}},
 			expectedIDs: []any{},
 		},
+		"RegexStringOptionExtended": {
+			filter:      bson.D{{"v", bson.D{{"$regex", "^ b a r # comment"}, {"$options", "x"}}}},
+			expectedIDs: []any{"multiline-string"},
+		},
+		"RegexOptionExtended": {
+			filter:      bson.D{{"v", bson.D{{"$regex", primitive.Regex{Pattern: "^ b a r $", Options: "xm"}}}}},
+			expectedIDs: []any{"multiline-string"},
+		},
+		"RegexArray": {
+			filter:      bson.D{{"a", bson.D{{"$regex", "^foo bar$"}, {"$options", "i"}}}},
+			expectedIDs: []any{"array-strings"},
+		},
+		"RegexArrayNoMatch": {
+			filter:      bson.D{{"a", bson.D{{"$regex", "^42$"}}}},
+			expectedIDs: []any{},
+		},
 		"RegexBadOption": {
 			filter:      bson.D{{"v", bson.D{{"$regex", primitive.Regex{Pattern: "foo", Options: "123"}}}}},
 			expectedIDs: []any{"multiline-string", "string"},
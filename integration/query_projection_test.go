@@ -69,6 +69,26 @@ func TestQueryProjection(t *testing.T) {
 			projection: bson.D{{"_id", false}, {"array", int32(1)}},
 			expected:   bson.D{},
 		},
+		"FindProjectionDottedInclusion": {
+			filter:     bson.D{{"_id", "document-composite"}},
+			projection: bson.D{{"v.foo", int32(1)}},
+			expected:   bson.D{{"_id", "document-composite"}, {"v", bson.D{{"foo", int32(42)}}}},
+		},
+		"FindProjectionNestedInclusion": {
+			filter:     bson.D{{"_id", "document-composite"}},
+			projection: bson.D{{"_id", false}, {"v", bson.D{{"42", true}, {"array", int32(1)}}}},
+			expected:   bson.D{{"v", bson.D{{"42", "foo"}, {"array", bson.A{int32(42), "foo", nil}}}}},
+		},
+		"FindProjectionNestedExclusion": {
+			filter:     bson.D{{"_id", "document-composite"}},
+			projection: bson.D{{"v", bson.D{{"42", false}, {"array", int32(0)}}}},
+			expected:   bson.D{{"_id", "document-composite"}, {"v", bson.D{{"foo", int32(42)}}}},
+		},
+		"FindProjectionDottedArray": {
+			filter:     bson.D{{"_id", "document-composite-2"}},
+			projection: bson.D{{"v.field", int32(0)}},
+			expected:   bson.D{{"_id", "document-composite-2"}, {"v", bson.A{bson.D{}, bson.D{}}}},
+		},
 		"ProjectionSliceNonArrayField": {
 			filter:     bson.D{{"_id", "document"}},
 			projection: bson.D{{"_id", bson.D{{"$slice", 1}}}},
@@ -388,3 +408,124 @@ func TestQueryProjectionSlice(t *testing.T) {
 		})
 	}
 }
+
+func TestQueryProjectionSliceCombined(t *testing.T) {
+	setup.SkipForTigris(t)
+
+	t.Parallel()
+	ctx, collection := setup.Setup(t)
+	_, err := collection.InsertMany(ctx, []any{
+		bson.D{{"_id", "array"}, {"v", bson.A{1, 2, 3, 4}}, {"w", "foo"}, {"x", "bar"}},
+		bson.D{{"_id", "string"}, {"v", "baz"}, {"w", "foo"}, {"x", "bar"}},
+	})
+	require.NoError(t, err)
+
+	for name, tc := range map[string]struct {
+		projection bson.D
+		expected   []bson.D
+	}{
+		"Slice": {
+			projection: bson.D{{"v", bson.D{{"$slice", 2}}}},
+			expected: []bson.D{
+				{{"_id", "array"}, {"v", bson.A{int32(1), int32(2)}}, {"w", "foo"}, {"x", "bar"}},
+				{{"_id", "string"}, {"v", "baz"}, {"w", "foo"}, {"x", "bar"}},
+			},
+		},
+		"Inclusion": {
+			projection: bson.D{{"v", bson.D{{"$slice", -2}}}, {"w", 1}},
+			expected: []bson.D{
+				{{"_id", "array"}, {"v", bson.A{int32(3), int32(4)}}, {"w", "foo"}},
+				{{"_id", "string"}, {"v", "baz"}, {"w", "foo"}},
+			},
+		},
+		"InclusionFirst": {
+			projection: bson.D{{"_id", 0}, {"w", true}, {"v", bson.D{{"$slice", bson.A{1, 2}}}}},
+			expected: []bson.D{
+				{{"v", bson.A{int32(2), int32(3)}}, {"w", "foo"}},
+				{{"v", "baz"}, {"w", "foo"}},
+			},
+		},
+		"Exclusion": {
+			projection: bson.D{{"v", bson.D{{"$slice", 1}}}, {"w", 0}},
+			expected: []bson.D{
+				{{"_id", "array"}, {"v", bson.A{int32(1)}}, {"x", "bar"}},
+				{{"_id", "string"}, {"v", "baz"}, {"x", "bar"}},
+			},
+		},
+	} {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			opts := options.Find().SetProjection(tc.projection).SetSort(bson.D{{"_id", 1}})
+			cursor, err := collection.Find(ctx, bson.D{}, opts)
+			require.NoError(t, err)
+
+			AssertEqualDocumentsSlice(t, tc.expected, FetchAll(t, ctx, cursor))
+		})
+	}
+}
+
+func TestQueryProjectionMetaIndexKey(t *testing.T) {
+	setup.SkipForMongoWithReason(t, "MongoDB omits the field for a collection scan")
+	setup.SkipForTigris(t)
+
+	t.Parallel()
+	ctx, collection := setup.Setup(t)
+	_, err := collection.InsertMany(ctx, []any{
+		bson.D{{"_id", "1"}, {"v", "foo"}, {"w", int32(1)}},
+		bson.D{{"_id", "2"}, {"v", "bar"}, {"w", int32(2)}},
+	})
+	require.NoError(t, err)
+
+	for name, tc := range map[string]struct {
+		projection bson.D
+		expected   []bson.D
+		err        *mongo.CommandError
+	}{
+		"Exclusion": {
+			projection: bson.D{{"k", bson.D{{"$meta", "indexKey"}}}},
+			expected: []bson.D{
+				{{"_id", "1"}, {"v", "foo"}, {"w", int32(1)}, {"k", bson.D{}}},
+				{{"_id", "2"}, {"v", "bar"}, {"w", int32(2)}, {"k", bson.D{}}},
+			},
+		},
+		"Inclusion": {
+			projection: bson.D{{"v", true}, {"k", bson.D{{"$meta", "indexKey"}}}},
+			expected: []bson.D{
+				{{"_id", "1"}, {"v", "foo"}, {"k", bson.D{}}},
+				{{"_id", "2"}, {"v", "bar"}, {"k", bson.D{}}},
+			},
+		},
+		"ExistingField": {
+			projection: bson.D{{"w", false}, {"v", bson.D{{"$meta", "indexKey"}}}},
+			expected: []bson.D{
+				{{"_id", "1"}, {"v", bson.D{}}},
+				{{"_id", "2"}, {"v", bson.D{}}},
+			},
+		},
+		"Unknown": {
+			projection: bson.D{{"k", bson.D{{"$meta", "foo"}}}},
+			err: &mongo.CommandError{
+				Code:    2,
+				Name:    "BadValue",
+				Message: "Unsupported argument to $meta: foo",
+			},
+		},
+	} {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			opts := options.Find().SetProjection(tc.projection).SetSort(bson.D{{"_id", 1}})
+			cursor, err := collection.Find(ctx, bson.D{}, opts)
+			if tc.err != nil {
+				AssertEqualError(t, *tc.err, err)
+				return
+			}
+			require.NoError(t, err)
+
+			AssertEqualDocumentsSlice(t, tc.expected, FetchAll(t, ctx, cursor))
+		})
+	}
+}
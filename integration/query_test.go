@@ -15,6 +15,7 @@
 package integration
 
 import (
+	"fmt"
 	"math"
 	"testing"
 	"time"
@@ -369,6 +370,318 @@ func TestQueryCount(t *testing.T) {
 	}
 }
 
+// TestQueryCountStrings checks count command for the data set that is compatible with all handlers.
+func TestQueryCountStrings(t *testing.T) {
+	t.Parallel()
+	ctx, collection := setup.Setup(t, shareddata.Strings)
+
+	for name, tc := range map[string]struct {
+		command  bson.D
+		response int32
+	}{
+		"All": {
+			command:  bson.D{{"count", collection.Name()}},
+			response: 4,
+		},
+		"Filter": {
+			command: bson.D{
+				{"count", collection.Name()},
+				{"query", bson.D{{"v", "foo"}}},
+			},
+			response: 1,
+		},
+		"FilterOperator": {
+			command: bson.D{
+				{"count", collection.Name()},
+				{"query", bson.D{{"v", bson.D{{"$gt", "4"}}}}},
+			},
+			response: 3,
+		},
+		"FilterLimit": {
+			command: bson.D{
+				{"count", collection.Name()},
+				{"query", bson.D{{"v", bson.D{{"$gt", "4"}}}}},
+				{"limit", int32(2)},
+			},
+			response: 2,
+		},
+		"FilterSkip": {
+			command: bson.D{
+				{"count", collection.Name()},
+				{"query", bson.D{{"v", bson.D{{"$gt", "4"}}}}},
+				{"skip", int32(2)},
+			},
+			response: 1,
+		},
+		"FilterSkipLimit": {
+			command: bson.D{
+				{"count", collection.Name()},
+				{"query", bson.D{{"v", bson.D{{"$gt", "4"}}}}},
+				{"skip", int32(1)},
+				{"limit", int32(1)},
+			},
+			response: 1,
+		},
+		"NoMatch": {
+			command: bson.D{
+				{"count", collection.Name()},
+				{"query", bson.D{{"v", "bar"}}},
+			},
+			response: 0,
+		},
+	} {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			var actual bson.D
+			err := collection.Database().RunCommand(ctx, tc.command).Decode(&actual)
+			require.NoError(t, err)
+
+			m := actual.Map()
+			assert.Equal(t, float64(1), m["ok"])
+			assert.Equal(t, tc.response, m["n"])
+		})
+	}
+}
+
+// TestQueryCountNonExistent checks that count returns zero for collections and databases that do not exist.
+func TestQueryCountNonExistent(t *testing.T) {
+	t.Parallel()
+	ctx, collection := setup.Setup(t, shareddata.Strings)
+
+	for name, tc := range map[string]struct {
+		db      *mongo.Database
+		command bson.D
+	}{
+		"Collection": {
+			db:      collection.Database(),
+			command: bson.D{{"count", "doesnotexist"}},
+		},
+		"CollectionFilterSkipLimit": {
+			db: collection.Database(),
+			command: bson.D{
+				{"count", "doesnotexist"},
+				{"query", bson.D{{"v", "foo"}}},
+				{"skip", int32(1)},
+				{"limit", int32(1)},
+			},
+		},
+		"Database": {
+			db:      collection.Database().Client().Database(collection.Database().Name() + "_doesnotexist"),
+			command: bson.D{{"count", collection.Name()}},
+		},
+	} {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			var actual bson.D
+			err := tc.db.RunCommand(ctx, tc.command).Decode(&actual)
+			require.NoError(t, err)
+
+			m := actual.Map()
+			assert.Equal(t, float64(1), m["ok"])
+			assert.Equal(t, int32(0), m["n"])
+		})
+	}
+}
+
+func TestQuerySkipLimit(t *testing.T) {
+	t.Parallel()
+	ctx, collection := setup.Setup(t)
+
+	docs := make([]any, 5)
+	for i := range docs {
+		docs[i] = bson.D{{"_id", int32(i)}}
+	}
+	_, err := collection.InsertMany(ctx, docs)
+	require.NoError(t, err)
+
+	t.Run("Find", func(t *testing.T) {
+		t.Parallel()
+
+		for name, tc := range map[string]struct {
+			opts        *options.FindOptions
+			expectedIDs []any
+		}{
+			"Skip": {
+				opts:        options.Find().SetSkip(3),
+				expectedIDs: []any{int32(3), int32(4)},
+			},
+			"SkipAll": {
+				opts:        options.Find().SetSkip(10),
+				expectedIDs: []any{},
+			},
+			"SkipLimit": {
+				opts:        options.Find().SetSkip(1).SetLimit(2),
+				expectedIDs: []any{int32(1), int32(2)},
+			},
+			"NegativeLimit": {
+				opts:        options.Find().SetLimit(-2),
+				expectedIDs: []any{int32(0), int32(1)},
+			},
+		} {
+			name, tc := name, tc
+			t.Run(name, func(t *testing.T) {
+				t.Parallel()
+
+				cursor, err := collection.Find(ctx, bson.D{}, tc.opts.SetSort(bson.D{{"_id", 1}}))
+				require.NoError(t, err)
+
+				var actual []bson.D
+				require.NoError(t, cursor.All(ctx, &actual))
+				assert.Equal(t, tc.expectedIDs, CollectIDs(t, actual))
+			})
+		}
+	})
+
+	t.Run("FindNegativeSkip", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := collection.Find(ctx, bson.D{}, options.Find().SetSkip(-1))
+		expected := mongo.CommandError{
+			Code:    2,
+			Name:    "BadValue",
+			Message: "BSON field 'skip' value must be >= 0, actual value '-1'",
+		}
+		AssertEqualError(t, expected, err)
+	})
+
+	t.Run("FindNegativeLimitCommand", func(t *testing.T) {
+		setup.SkipForMongoWithReason(t, "MongoDB rejects negative limit; drivers convert it to singleBatch")
+
+		t.Parallel()
+
+		var res bson.D
+		err := collection.Database().RunCommand(ctx, bson.D{
+			{"find", collection.Name()},
+			{"sort", bson.D{{"_id", 1}}},
+			{"limit", int32(-3)},
+			{"batchSize", int32(2)},
+		}).Decode(&res)
+		require.NoError(t, err)
+
+		// only the first batch is returned, and the cursor is closed
+		cursor := res.Map()["cursor"].(bson.D).Map()
+		assert.Len(t, cursor["firstBatch"], 2)
+		assert.Equal(t, int64(0), cursor["id"])
+	})
+
+	t.Run("FindCommand", func(t *testing.T) {
+		t.Parallel()
+
+		for name, tc := range map[string]struct {
+			command bson.D
+			n       int
+			err     *mongo.CommandError
+		}{
+			"WholeDouble": {
+				command: bson.D{{"skip", 1.0}, {"limit", 5.0}},
+				n:       4,
+			},
+			"FractionalLimit": {
+				command: bson.D{{"limit", 5.5}},
+				err: &mongo.CommandError{
+					Code:    9,
+					Name:    "FailedToParse",
+					Message: "Expected an integer: limit: 5.5",
+				},
+			},
+		} {
+			name, tc := name, tc
+			t.Run(name, func(t *testing.T) {
+				t.Parallel()
+
+				command := append(bson.D{{"find", collection.Name()}}, tc.command...)
+
+				var res bson.D
+				err := collection.Database().RunCommand(ctx, command).Decode(&res)
+				if tc.err != nil {
+					AssertEqualError(t, *tc.err, err)
+					return
+				}
+				require.NoError(t, err)
+
+				cursor := res.Map()["cursor"].(bson.D).Map()
+				assert.Len(t, cursor["firstBatch"], tc.n)
+			})
+		}
+	})
+
+	t.Run("Count", func(t *testing.T) {
+		t.Parallel()
+
+		for name, tc := range map[string]struct {
+			command bson.D
+			n       int32
+			err     *mongo.CommandError
+		}{
+			"Skip": {
+				command: bson.D{{"skip", int32(3)}},
+				n:       2,
+			},
+			"SkipAll": {
+				command: bson.D{{"skip", int64(10)}},
+				n:       0,
+			},
+			"SkipLimit": {
+				command: bson.D{{"skip", int32(1)}, {"limit", int32(3)}},
+				n:       3,
+			},
+			"NegativeLimit": {
+				command: bson.D{{"limit", int32(-2)}},
+				n:       2,
+			},
+			"WholeDouble": {
+				command: bson.D{{"skip", 1.0}, {"limit", 5.0}},
+				n:       4,
+			},
+			"FractionalLimit": {
+				command: bson.D{{"limit", 5.5}},
+				err: &mongo.CommandError{
+					Code:    9,
+					Name:    "FailedToParse",
+					Message: "Expected an integer: limit: 5.5",
+				},
+			},
+			"FractionalSkip": {
+				command: bson.D{{"skip", 1.5}},
+				err: &mongo.CommandError{
+					Code:    9,
+					Name:    "FailedToParse",
+					Message: "Expected an integer: skip: 1.5",
+				},
+			},
+			"NegativeSkip": {
+				command: bson.D{{"skip", int32(-1)}},
+				err: &mongo.CommandError{
+					Code:    2,
+					Name:    "BadValue",
+					Message: "BSON field 'skip' value must be >= 0, actual value '-1'",
+				},
+			},
+		} {
+			name, tc := name, tc
+			t.Run(name, func(t *testing.T) {
+				t.Parallel()
+
+				command := append(bson.D{{"count", collection.Name()}}, tc.command...)
+
+				var actual bson.D
+				err := collection.Database().RunCommand(ctx, command).Decode(&actual)
+				if tc.err != nil {
+					AssertEqualError(t, *tc.err, err)
+					return
+				}
+				require.NoError(t, err)
+
+				assert.Equal(t, tc.n, actual.Map()["n"])
+			})
+		}
+	})
+}
+
 func TestQueryBadFindType(t *testing.T) {
 	setup.SkipForTigris(t)
 
@@ -923,3 +1236,451 @@ func TestQueryNonExistingCollection(t *testing.T) {
 	require.NoError(t, err)
 	require.Len(t, actual, 0)
 }
+
+func TestQueryDefaultBatchSize(t *testing.T) {
+	t.Parallel()
+	ctx, collection := setup.Setup(t)
+
+	// the default batch size is 101, the same as MongoDB's
+	docs := make([]any, 150)
+	for i := range docs {
+		docs[i] = bson.D{{"_id", int32(i)}}
+	}
+	_, err := collection.InsertMany(ctx, docs)
+	require.NoError(t, err)
+
+	for name, tc := range map[string]struct {
+		command   bson.D
+		batchSize int
+	}{
+		"Find": {
+			command:   bson.D{{"find", collection.Name()}},
+			batchSize: 101,
+		},
+		"FindBatchSize": {
+			command:   bson.D{{"find", collection.Name()}, {"batchSize", 10}},
+			batchSize: 10,
+		},
+		"Aggregate": {
+			command:   bson.D{{"aggregate", collection.Name()}, {"pipeline", bson.A{}}, {"cursor", bson.D{}}},
+			batchSize: 101,
+		},
+		"AggregateBatchSize": {
+			command: bson.D{
+				{"aggregate", collection.Name()}, {"pipeline", bson.A{}}, {"cursor", bson.D{{"batchSize", 10}}},
+			},
+			batchSize: 10,
+		},
+	} {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			var res bson.D
+			err := collection.Database().RunCommand(ctx, tc.command).Decode(&res)
+			require.NoError(t, err)
+
+			cursor := res.Map()["cursor"].(bson.D).Map()
+			assert.Len(t, cursor["firstBatch"], tc.batchSize)
+			assert.NotZero(t, cursor["id"])
+		})
+	}
+
+	t.Run("FindAll", func(t *testing.T) {
+		t.Parallel()
+
+		// the rest of documents is fetched with getMore
+		assert.Len(t, FindAll(t, ctx, collection), len(docs))
+	})
+}
+
+func TestQueryGetMoreFilterSkipLimit(t *testing.T) {
+	t.Parallel()
+	ctx, collection := setup.Setup(t)
+
+	docs := make([]any, 250)
+	for i := range docs {
+		docs[i] = bson.D{{"_id", int32(i)}, {"even", i%2 == 0}, {"w", "foo"}}
+	}
+	_, err := collection.InsertMany(ctx, docs)
+	require.NoError(t, err)
+
+	opts := options.Find().
+		SetBatchSize(30).
+		SetSkip(10).
+		SetLimit(100).
+		SetProjection(bson.D{{"w", 0}})
+
+	cursor, err := collection.Find(ctx, bson.D{{"even", true}}, opts)
+	require.NoError(t, err)
+
+	var actual []bson.D
+	require.NoError(t, cursor.All(ctx, &actual))
+
+	// unsorted documents could be returned and skipped in any order
+	require.Len(t, actual, 100)
+
+	seen := make(map[any]struct{}, len(actual))
+	for _, doc := range actual {
+		m := doc.Map()
+		assert.Equal(t, true, m["even"])
+		assert.NotContains(t, m, "w")
+
+		assert.NotContains(t, seen, m["_id"])
+		seen[m["_id"]] = struct{}{}
+	}
+}
+
+func TestQueryGetMore(t *testing.T) {
+	t.Parallel()
+	s := setup.SetupWithOpts(t, nil)
+	ctx, collection := s.Ctx, s.Collection
+
+	docs := make([]any, 5)
+	for i := range docs {
+		docs[i] = bson.D{{"_id", int32(i)}}
+	}
+	_, err := collection.InsertMany(ctx, docs)
+	require.NoError(t, err)
+
+	// find returns cursor ID and the first batch of the given size
+	find := func(t *testing.T, collection *mongo.Collection, batchSize int32) (int64, bson.A) {
+		t.Helper()
+
+		var res bson.D
+		err := collection.Database().RunCommand(
+			ctx, bson.D{{"find", collection.Name()}, {"sort", bson.D{{"_id", 1}}}, {"batchSize", batchSize}},
+		).Decode(&res)
+		require.NoError(t, err)
+
+		cursor := res.Map()["cursor"].(bson.D).Map()
+		return cursor["id"].(int64), cursor["firstBatch"].(bson.A)
+	}
+
+	getMore := func(collection *mongo.Collection, id int64, batchSize int32) (bson.D, error) {
+		var res bson.D
+		err := collection.Database().RunCommand(
+			ctx, bson.D{{"getMore", id}, {"collection", collection.Name()}, {"batchSize", batchSize}},
+		).Decode(&res)
+		return res, err
+	}
+
+	t.Run("Paging", func(t *testing.T) {
+		t.Parallel()
+
+		id, batch := find(t, collection, 2)
+		require.NotZero(t, id)
+		assert.Equal(t, bson.A{bson.D{{"_id", int32(0)}}, bson.D{{"_id", int32(1)}}}, batch)
+
+		res, err := getMore(collection, id, 2)
+		require.NoError(t, err)
+		cursor := res.Map()["cursor"].(bson.D).Map()
+		assert.Equal(t, id, cursor["id"])
+		assert.Equal(t, bson.A{bson.D{{"_id", int32(2)}}, bson.D{{"_id", int32(3)}}}, cursor["nextBatch"])
+
+		res, err = getMore(collection, id, 2)
+		require.NoError(t, err)
+		cursor = res.Map()["cursor"].(bson.D).Map()
+		assert.Equal(t, int64(0), cursor["id"])
+		assert.Equal(t, bson.A{bson.D{{"_id", int32(4)}}}, cursor["nextBatch"])
+
+		// exhausted cursor is removed
+		_, err = getMore(collection, id, 2)
+		expected := mongo.CommandError{
+			Code:    43,
+			Name:    "CursorNotFound",
+			Message: fmt.Sprintf("cursor id %d not found", id),
+		}
+		AssertEqualError(t, expected, err)
+	})
+
+	t.Run("OtherConnection", func(t *testing.T) {
+		setup.SkipForMongoWithReason(t, "MongoDB binds cursors to sessions, not to connections")
+
+		t.Parallel()
+
+		uri := fmt.Sprintf("mongodb://127.0.0.1:%d/", s.Port)
+		client2, err := mongo.Connect(ctx, options.Client().ApplyURI(uri))
+		require.NoError(t, err)
+		collection2 := client2.Database(collection.Database().Name()).Collection(collection.Name())
+
+		id, _ := find(t, collection2, 1)
+		require.NotZero(t, id)
+
+		// cursors can't be used by other connections
+		_, err = getMore(collection, id, 1)
+		expected := mongo.CommandError{
+			Code:    43,
+			Name:    "CursorNotFound",
+			Message: fmt.Sprintf("cursor id %d not found", id),
+		}
+		AssertEqualError(t, expected, err)
+
+		_, err = getMore(collection2, id, 1)
+		require.NoError(t, err)
+
+		// cursors are removed when their connection is closed
+		require.NoError(t, client2.Disconnect(ctx))
+
+		client2, err = mongo.Connect(ctx, options.Client().ApplyURI(uri))
+		require.NoError(t, err)
+		defer client2.Disconnect(ctx)
+		collection2 = client2.Database(collection.Database().Name()).Collection(collection.Name())
+
+		_, err = getMore(collection2, id, 1)
+		AssertEqualError(t, expected, err)
+	})
+}
+
+func TestQueryReadConcern(t *testing.T) {
+	t.Parallel()
+	ctx, collection := setup.Setup(t, shareddata.Scalars)
+
+	expected := FindAll(t, ctx, collection)
+
+	for name, tc := range map[string]struct {
+		readConcern bson.D
+		err         *mongo.CommandError
+	}{
+		"Local": {
+			readConcern: bson.D{{"level", "local"}},
+		},
+		"Majority": {
+			readConcern: bson.D{{"level", "majority"}},
+		},
+		"Snapshot": {
+			readConcern: bson.D{{"level", "snapshot"}},
+		},
+		"NoLevel": {
+			readConcern: bson.D{},
+		},
+		"InvalidLevel": {
+			readConcern: bson.D{{"level", "invalid"}},
+			err: &mongo.CommandError{
+				Code:    2,
+				Name:    "BadValue",
+				Message: "Invalid read concern level: invalid",
+			},
+		},
+	} {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			for _, command := range []bson.D{
+				{{"find", collection.Name()}, {"sort", bson.D{{"_id", 1}}}, {"readConcern", tc.readConcern}},
+				{{"count", collection.Name()}, {"readConcern", tc.readConcern}},
+			} {
+				var res bson.D
+				err := collection.Database().RunCommand(ctx, command).Decode(&res)
+				if tc.err != nil {
+					AssertEqualError(t, *tc.err, err)
+					continue
+				}
+
+				require.NoError(t, err)
+
+				switch command[0].Key {
+				case "find":
+					actual := res.Map()["cursor"].(bson.D).Map()["firstBatch"].(bson.A)
+					assert.Len(t, actual, len(expected))
+				case "count":
+					assert.Equal(t, int32(len(expected)), res.Map()["n"])
+				}
+			}
+		})
+	}
+}
+
+func TestQueryKillCursors(t *testing.T) {
+	t.Parallel()
+	ctx, collection := setup.Setup(t)
+
+	docs := make([]any, 5)
+	for i := range docs {
+		docs[i] = bson.D{{"_id", int32(i)}}
+	}
+	_, err := collection.InsertMany(ctx, docs)
+	require.NoError(t, err)
+
+	var res bson.D
+	err = collection.Database().RunCommand(ctx, bson.D{{"find", collection.Name()}, {"batchSize", 1}}).Decode(&res)
+	require.NoError(t, err)
+
+	id := res.Map()["cursor"].(bson.D).Map()["id"].(int64)
+	require.NotZero(t, id)
+
+	killCursors := func(t *testing.T, ids ...any) bson.M {
+		t.Helper()
+
+		var res bson.D
+		err := collection.Database().RunCommand(
+			ctx, bson.D{{"killCursors", collection.Name()}, {"cursors", bson.A(ids)}},
+		).Decode(&res)
+		require.NoError(t, err)
+
+		return res.Map()
+	}
+
+	unknown := int64(1<<62 + 42)
+
+	m := killCursors(t, id, unknown)
+	assert.Equal(t, bson.A{id}, m["cursorsKilled"])
+	assert.Equal(t, bson.A{unknown}, m["cursorsNotFound"])
+	assert.Equal(t, bson.A{}, m["cursorsAlive"])
+	assert.Equal(t, bson.A{}, m["cursorsUnknown"])
+
+	// killed cursor is not found by getMore and by killCursors
+	err = collection.Database().RunCommand(ctx, bson.D{{"getMore", id}, {"collection", collection.Name()}}).Err()
+	expected := mongo.CommandError{
+		Code:    43,
+		Name:    "CursorNotFound",
+		Message: fmt.Sprintf("cursor id %d not found", id),
+	}
+	AssertEqualError(t, expected, err)
+
+	m = killCursors(t, id)
+	assert.Equal(t, bson.A{}, m["cursorsKilled"])
+	assert.Equal(t, bson.A{id}, m["cursorsNotFound"])
+
+	t.Run("BadCursorType", func(t *testing.T) {
+		err := collection.Database().RunCommand(
+			ctx, bson.D{{"killCursors", collection.Name()}, {"cursors", bson.A{int32(1)}}},
+		).Err()
+		expected := mongo.CommandError{
+			Code:    14,
+			Name:    "TypeMismatch",
+			Message: "BSON field 'killCursors.cursors.0' is the wrong type 'int', expected type 'long'",
+		}
+		AssertEqualError(t, expected, err)
+	})
+}
+
+func TestQuerySortIndexed(t *testing.T) {
+	setup.SkipForTigrisWithReason(t, "Indexes are not supported for Tigris")
+
+	t.Parallel()
+	ctx, collection := setup.Setup(t)
+
+	_, err := collection.InsertMany(ctx, []any{
+		bson.D{{"_id", "int-3"}, {"v", int32(3)}},
+		bson.D{{"_id", "int-1"}, {"v", int32(1)}},
+		bson.D{{"_id", "int-2"}, {"v", int32(2)}},
+		bson.D{{"_id", "null"}, {"v", nil}},
+	})
+	require.NoError(t, err)
+
+	_, err = collection.Indexes().CreateOne(ctx, mongo.IndexModel{Keys: bson.D{{"v", 1}}})
+	require.NoError(t, err)
+
+	// find returns IDs of all documents sorted by v
+	find := func(t *testing.T, order int32) []any {
+		t.Helper()
+
+		cursor, err := collection.Find(ctx, bson.D{}, options.Find().SetSort(bson.D{{"v", order}}))
+		require.NoError(t, err)
+
+		var actual []bson.D
+		require.NoError(t, cursor.All(ctx, &actual))
+
+		return CollectIDs(t, actual)
+	}
+
+	// the order is the same as the in-memory sort's one
+	assert.Equal(t, []any{"null", "int-1", "int-2", "int-3"}, find(t, 1))
+	assert.Equal(t, []any{"int-3", "int-2", "int-1", "null"}, find(t, -1))
+
+	// int32, int64 and double values are compared as numbers, and strings are compared byte by byte
+	_, err = collection.InsertMany(ctx, []any{
+		bson.D{{"_id", "string-a"}, {"v", "a"}},
+		bson.D{{"_id", "string-B"}, {"v", "B"}},
+		bson.D{{"_id", "double"}, {"v", 1.5}},
+		bson.D{{"_id", "double-nan"}, {"v", math.NaN()}},
+		bson.D{{"_id", "long"}, {"v", int64(4)}},
+		bson.D{{"_id", "long-negative"}, {"v", int64(-10)}},
+	})
+	require.NoError(t, err)
+
+	expected := []any{
+		"null", "double-nan", "long-negative", "int-1", "double", "int-2", "int-3", "long", "string-B", "string-a",
+	}
+	assert.Equal(t, expected, find(t, 1))
+
+	expected = []any{
+		"string-a", "string-B", "long", "int-3", "int-2", "double", "int-1", "long-negative", "double-nan", "null",
+	}
+	assert.Equal(t, expected, find(t, -1))
+
+	// documents and other values that PostgreSQL can't sort are sorted in memory
+	_, err = collection.InsertMany(ctx, []any{
+		bson.D{{"_id", "document"}, {"v", bson.D{{"foo", int32(0)}}}},
+		bson.D{{"_id", "bool"}, {"v", true}},
+	})
+	require.NoError(t, err)
+
+	expected = []any{
+		"null", "double-nan", "long-negative", "int-1", "double", "int-2", "int-3", "long", "string-B", "string-a",
+		"document", "bool",
+	}
+	assert.Equal(t, expected, find(t, 1))
+
+	expected = []any{
+		"bool", "document",
+		"string-a", "string-B", "long", "int-3", "int-2", "double", "int-1", "long-negative", "double-nan", "null",
+	}
+	assert.Equal(t, expected, find(t, -1))
+}
+
+func TestQuerySortID(t *testing.T) {
+	setup.SkipForTigrisWithReason(t, "Tigris schema does not allow _id values of different types")
+
+	t.Parallel()
+	ctx, collection := setup.Setup(t)
+
+	// _id values of different types, including ones that PostgreSQL sorts differently
+	_, err := collection.InsertMany(ctx, []any{
+		bson.D{{"_id", int32(3)}},
+		bson.D{{"_id", "b"}},
+		bson.D{{"_id", 1.5}},
+		bson.D{{"_id", int64(4)}},
+		bson.D{{"_id", "A"}},
+		bson.D{{"_id", int32(1)}},
+		bson.D{{"_id", primitive.ObjectID{0x0a}}},
+		bson.D{{"_id", primitive.ObjectID{0x01, 0xff}}},
+	})
+	require.NoError(t, err)
+
+	// page returns IDs of documents of the given page sorted by _id
+	page := func(t *testing.T, order int32, skip, limit int64) []any {
+		t.Helper()
+
+		opts := options.Find().SetSort(bson.D{{"_id", order}}).SetSkip(skip).SetLimit(limit)
+		cursor, err := collection.Find(ctx, bson.D{}, opts)
+		require.NoError(t, err)
+
+		var actual []bson.D
+		require.NoError(t, cursor.All(ctx, &actual))
+
+		return CollectIDs(t, actual)
+	}
+
+	assert.Equal(t, []any{int32(1), 1.5, int32(3)}, page(t, 1, 0, 3))
+	assert.Equal(t, []any{int64(4), "A", "b"}, page(t, 1, 3, 3))
+	assert.Equal(t, []any{primitive.ObjectID{0x01, 0xff}, primitive.ObjectID{0x0a}}, page(t, 1, 6, 3))
+	assert.Equal(t, []any{primitive.ObjectID{0x0a}, primitive.ObjectID{0x01, 0xff}, "b"}, page(t, -1, 0, 3))
+	assert.Equal(t, []any{"A", int64(4), int32(3)}, page(t, -1, 3, 3))
+	assert.Equal(t, []any{1.5, int32(1)}, page(t, -1, 6, 3))
+
+	// batches of a single cursor
+	opts := options.Find().SetSort(bson.D{{"_id", 1}}).SetBatchSize(2)
+	cursor, err := collection.Find(ctx, bson.D{}, opts)
+	require.NoError(t, err)
+
+	var actual []bson.D
+	require.NoError(t, cursor.All(ctx, &actual))
+
+	expected := []any{
+		int32(1), 1.5, int32(3), int64(4), "A", "b", primitive.ObjectID{0x01, 0xff}, primitive.ObjectID{0x0a},
+	}
+	assert.Equal(t, expected, CollectIDs(t, actual))
+}
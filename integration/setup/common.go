@@ -106,6 +106,9 @@ func setupListener(tb testing.TB, ctx context.Context, logger *zap.Logger) int {
 		Logger:        logger,
 		PostgreSQLURL: testutil.PostgreSQLURL(tb, nil),
 		TigrisURL:     testutil.TigrisURL(tb),
+
+		// expire documents quickly in TTL tests
+		PostgreSQLTTLMonitorInterval: time.Second,
 	})
 	require.NoError(tb, err)
 
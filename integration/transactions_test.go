@@ -0,0 +1,157 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package integration
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+
+	"github.com/FerretDB/FerretDB/integration/setup"
+)
+
+func TestTransactionsCommitAbort(t *testing.T) {
+	setup.SkipForMongoWithReason(t, "MongoDB standalone does not support transactions")
+
+	t.Parallel()
+	ctx, collection := setup.Setup(t)
+
+	session, err := collection.Database().Client().StartSession()
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		session.EndSession(ctx)
+	})
+
+	t.Run("Commit", func(t *testing.T) {
+		require.NoError(t, session.StartTransaction())
+
+		err := mongo.WithSession(ctx, session, func(sctx mongo.SessionContext) error {
+			_, err := collection.InsertOne(sctx, bson.D{{"_id", "commit"}})
+			return err
+		})
+		require.NoError(t, err)
+
+		require.NoError(t, session.CommitTransaction(ctx))
+	})
+
+	t.Run("Abort", func(t *testing.T) {
+		require.NoError(t, session.StartTransaction())
+
+		err := mongo.WithSession(ctx, session, func(sctx mongo.SessionContext) error {
+			_, err := collection.InsertOne(sctx, bson.D{{"_id", "abort"}})
+			return err
+		})
+		require.NoError(t, err)
+
+		require.NoError(t, session.AbortTransaction(ctx))
+	})
+
+	t.Run("Commands", func(t *testing.T) {
+		admin := collection.Database().Client().Database("admin")
+
+		for _, command := range []string{"commitTransaction", "abortTransaction"} {
+			var actual bson.D
+			err := admin.RunCommand(ctx, bson.D{{command, int32(1)}}).Decode(&actual)
+			require.NoError(t, err, command)
+			assert.Equal(t, bson.D{{"ok", float64(1)}}, actual, command)
+		}
+	})
+}
+
+func TestTransactionsMultiStatement(t *testing.T) {
+	setup.SkipForMongoWithReason(t, "MongoDB standalone does not support transactions")
+	setup.SkipForTigrisWithReason(t, "multi-document transactions are not supported by Tigris handler yet")
+
+	t.Parallel()
+	ctx, collection := setup.Setup(t)
+
+	// create collection outside of transactions
+	require.NoError(t, collection.Database().CreateCollection(ctx, collection.Name()))
+
+	session, err := collection.Database().Client().StartSession()
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		session.EndSession(ctx)
+	})
+
+	// insertTwo inserts two documents with given _id prefix in the session's transaction
+	// and checks that they are visible inside the transaction, but not outside of it.
+	insertTwo := func(t *testing.T, prefix string) {
+		t.Helper()
+
+		err := mongo.WithSession(ctx, session, func(sctx mongo.SessionContext) error {
+			if _, err := collection.InsertOne(sctx, bson.D{{"_id", prefix + "1"}}); err != nil {
+				return err
+			}
+			if _, err := collection.InsertOne(sctx, bson.D{{"_id", prefix + "2"}}); err != nil {
+				return err
+			}
+
+			cursor, err := collection.Find(sctx, bson.D{})
+			if err != nil {
+				return err
+			}
+
+			var actual []bson.D
+			if err = cursor.All(sctx, &actual); err != nil {
+				return err
+			}
+			assert.Len(t, actual, 2, "inside transaction")
+
+			return nil
+		})
+		require.NoError(t, err)
+
+		assert.Empty(t, FindAll(t, ctx, collection), "outside transaction")
+	}
+
+	t.Run("Abort", func(t *testing.T) {
+		require.NoError(t, session.StartTransaction())
+		insertTwo(t, "abort")
+		require.NoError(t, session.AbortTransaction(ctx))
+
+		assert.Empty(t, FindAll(t, ctx, collection))
+	})
+
+	t.Run("Commit", func(t *testing.T) {
+		require.NoError(t, session.StartTransaction())
+		insertTwo(t, "commit")
+		require.NoError(t, session.CommitTransaction(ctx))
+
+		assert.Equal(t, []any{"commit1", "commit2"}, CollectIDs(t, FindAll(t, ctx, collection)))
+	})
+
+	t.Run("AbortUpdateDelete", func(t *testing.T) {
+		require.NoError(t, session.StartTransaction())
+
+		err := mongo.WithSession(ctx, session, func(sctx mongo.SessionContext) error {
+			if _, err := collection.UpdateOne(sctx, bson.D{{"_id", "commit1"}}, bson.D{{"$set", bson.D{{"v", 42}}}}); err != nil {
+				return err
+			}
+
+			_, err := collection.DeleteOne(sctx, bson.D{{"_id", "commit2"}})
+			return err
+		})
+		require.NoError(t, err)
+
+		require.NoError(t, session.AbortTransaction(ctx))
+
+		expected := []bson.D{{{"_id", "commit1"}}, {{"_id", "commit2"}}}
+		AssertEqualDocumentsSlice(t, expected, FindAll(t, ctx, collection))
+	})
+}
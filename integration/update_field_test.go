@@ -935,6 +935,53 @@ func TestUpdateFieldSet(t *testing.T) {
 	}
 }
 
+func TestUpdateFieldSetFieldOrder(t *testing.T) {
+	t.Parallel()
+
+	for name, tc := range map[string]struct {
+		update   bson.D
+		replace  bool
+		expected bson.D
+	}{
+		"SetModifyAndAdd": {
+			update: bson.D{{"$set", bson.D{{"z", int32(1)}, {"c", "new"}, {"a", int32(2)}, {"y", int32(3)}}}},
+			expected: bson.D{
+				{"_id", "order"}, {"a", int32(2)}, {"b", "foo"}, {"c", "new"}, {"y", int32(3)}, {"z", int32(1)},
+			},
+		},
+		"SetExistingOnly": {
+			update:   bson.D{{"$set", bson.D{{"c", "new"}, {"a", int32(2)}}}},
+			expected: bson.D{{"_id", "order"}, {"a", int32(2)}, {"b", "foo"}, {"c", "new"}},
+		},
+		"Replace": {
+			update:   bson.D{{"z", int32(1)}, {"c", "new"}, {"a", int32(2)}},
+			replace:  true,
+			expected: bson.D{{"_id", "order"}, {"z", int32(1)}, {"c", "new"}, {"a", int32(2)}},
+		},
+	} {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			ctx, collection := setup.Setup(t)
+
+			_, err := collection.InsertOne(ctx, bson.D{{"_id", "order"}, {"a", int32(1)}, {"b", "foo"}, {"c", "bar"}})
+			require.NoError(t, err)
+
+			if tc.replace {
+				_, err = collection.ReplaceOne(ctx, bson.D{{"_id", "order"}}, tc.update)
+			} else {
+				_, err = collection.UpdateOne(ctx, bson.D{{"_id", "order"}}, tc.update)
+			}
+			require.NoError(t, err)
+
+			var actual bson.D
+			require.NoError(t, collection.FindOne(ctx, bson.D{{"_id", "order"}}).Decode(&actual))
+			assert.Equal(t, tc.expected, actual)
+		})
+	}
+}
+
 func TestUpdateFieldSetOnInsert(t *testing.T) {
 	setup.SkipForTigris(t)
 
@@ -1406,3 +1453,381 @@ func TestUpdateFieldPopArrayOperator(t *testing.T) {
 		}
 	})
 }
+
+func TestUpdateFieldPullAllArrayOperator(t *testing.T) {
+	setup.SkipForTigris(t)
+
+	t.Parallel()
+
+	t.Run("Ok", func(t *testing.T) {
+		t.Parallel()
+
+		for name, tc := range map[string]struct {
+			doc      bson.D
+			update   bson.D
+			expected bson.D
+			stat     *mongo.UpdateResult
+		}{
+			"Scalars": {
+				doc:      bson.D{{"_id", "array"}, {"v", bson.A{int32(1), "foo", int64(1), 1.0, "bar", "foo", nil}}},
+				update:   bson.D{{"$pullAll", bson.D{{"v", bson.A{1.0, "foo", nil}}}}},
+				expected: bson.D{{"_id", "array"}, {"v", bson.A{"bar"}}},
+				stat:     &mongo.UpdateResult{MatchedCount: 1, ModifiedCount: 1},
+			},
+			"Documents": {
+				doc: bson.D{{"_id", "array"}, {"v", bson.A{
+					bson.D{{"a", int32(1)}, {"b", int32(2)}},
+					bson.D{{"b", int32(2)}, {"a", int32(1)}},
+					bson.D{{"a", int32(1)}},
+					bson.D{{"a", int32(1)}, {"b", int32(2)}},
+				}}},
+				update: bson.D{{"$pullAll", bson.D{{"v", bson.A{bson.D{{"a", int32(1)}, {"b", int32(2)}}}}}}},
+				expected: bson.D{{"_id", "array"}, {"v", bson.A{
+					bson.D{{"b", int32(2)}, {"a", int32(1)}},
+					bson.D{{"a", int32(1)}},
+				}}},
+				stat: &mongo.UpdateResult{MatchedCount: 1, ModifiedCount: 1},
+			},
+			"Arrays": {
+				doc:      bson.D{{"_id", "array"}, {"v", bson.A{bson.A{int32(1), int32(2)}, bson.A{int32(2), int32(1)}, int32(1)}}},
+				update:   bson.D{{"$pullAll", bson.D{{"v", bson.A{bson.A{int32(1), int32(2)}}}}}},
+				expected: bson.D{{"_id", "array"}, {"v", bson.A{bson.A{int32(2), int32(1)}, int32(1)}}},
+				stat:     &mongo.UpdateResult{MatchedCount: 1, ModifiedCount: 1},
+			},
+			"DotNotation": {
+				doc:      bson.D{{"_id", "array"}, {"v", bson.D{{"array", bson.A{int32(42), "foo", int32(42)}}}}},
+				update:   bson.D{{"$pullAll", bson.D{{"v.array", bson.A{int32(42)}}}}},
+				expected: bson.D{{"_id", "array"}, {"v", bson.D{{"array", bson.A{"foo"}}}}},
+				stat:     &mongo.UpdateResult{MatchedCount: 1, ModifiedCount: 1},
+			},
+			"NoMatches": {
+				doc:      bson.D{{"_id", "array"}, {"v", bson.A{int32(42)}}},
+				update:   bson.D{{"$pullAll", bson.D{{"v", bson.A{"42"}}}}},
+				expected: bson.D{{"_id", "array"}, {"v", bson.A{int32(42)}}},
+				stat:     &mongo.UpdateResult{MatchedCount: 1, ModifiedCount: 0},
+			},
+			"NoSuchKey": {
+				doc:      bson.D{{"_id", "array"}, {"v", bson.A{int32(42)}}},
+				update:   bson.D{{"$pullAll", bson.D{{"foo", bson.A{int32(42)}}}}},
+				expected: bson.D{{"_id", "array"}, {"v", bson.A{int32(42)}}},
+				stat:     &mongo.UpdateResult{MatchedCount: 1, ModifiedCount: 0},
+			},
+		} {
+			name, tc := name, tc
+			t.Run(name, func(t *testing.T) {
+				t.Parallel()
+				ctx, collection := setup.Setup(t)
+
+				_, err := collection.InsertOne(ctx, tc.doc)
+				require.NoError(t, err)
+
+				result, err := collection.UpdateOne(ctx, bson.D{{"_id", "array"}}, tc.update)
+				require.NoError(t, err)
+				require.Equal(t, tc.stat, result)
+
+				var actual bson.D
+				err = collection.FindOne(ctx, bson.D{{"_id", "array"}}).Decode(&actual)
+				require.NoError(t, err)
+
+				AssertEqualDocuments(t, tc.expected, actual)
+			})
+		}
+	})
+
+	t.Run("Err", func(t *testing.T) {
+		t.Parallel()
+
+		for name, tc := range map[string]struct {
+			id     string
+			update bson.D
+			err    *mongo.WriteError
+		}{
+			"NotArrayArgument": {
+				id:     "array",
+				update: bson.D{{"$pullAll", bson.D{{"v", int32(42)}}}},
+				err: &mongo.WriteError{
+					Code:    2,
+					Message: "$pullAll requires an array argument but was given a int",
+				},
+			},
+			"NonArray": {
+				id:     "int32",
+				update: bson.D{{"$pullAll", bson.D{{"v", bson.A{int32(42)}}}}},
+				err: &mongo.WriteError{
+					Code:    2,
+					Message: "Cannot apply $pullAll to a non-array value",
+				},
+			},
+			"DotNotationNonArray": {
+				id:     "document-composite",
+				update: bson.D{{"$pullAll", bson.D{{"v.foo", bson.A{int32(42)}}}}},
+				err: &mongo.WriteError{
+					Code:    2,
+					Message: "Cannot apply $pullAll to a non-array value",
+				},
+			},
+		} {
+			name, tc := name, tc
+			t.Run(name, func(t *testing.T) {
+				t.Parallel()
+				ctx, collection := setup.Setup(t, shareddata.Scalars, shareddata.Composites)
+
+				_, err := collection.UpdateOne(ctx, bson.D{{"_id", tc.id}}, tc.update)
+				require.NotNil(t, tc.err)
+				AssertEqualWriteError(t, *tc.err, err)
+			})
+		}
+	})
+}
+
+func TestUpdateFieldBit(t *testing.T) {
+	setup.SkipForTigris(t)
+
+	t.Parallel()
+
+	t.Run("Ok", func(t *testing.T) {
+		t.Parallel()
+
+		for name, tc := range map[string]struct {
+			v        any
+			update   bson.D
+			expected any
+			stat     *mongo.UpdateResult
+		}{
+			"AndInt32": {
+				v:        int32(13),
+				update:   bson.D{{"$bit", bson.D{{"v", bson.D{{"and", int32(10)}}}}}},
+				expected: int32(8),
+				stat:     &mongo.UpdateResult{MatchedCount: 1, ModifiedCount: 1},
+			},
+			"OrInt32": {
+				v:        int32(13),
+				update:   bson.D{{"$bit", bson.D{{"v", bson.D{{"or", int32(10)}}}}}},
+				expected: int32(15),
+				stat:     &mongo.UpdateResult{MatchedCount: 1, ModifiedCount: 1},
+			},
+			"XorInt32": {
+				v:        int32(13),
+				update:   bson.D{{"$bit", bson.D{{"v", bson.D{{"xor", int32(10)}}}}}},
+				expected: int32(7),
+				stat:     &mongo.UpdateResult{MatchedCount: 1, ModifiedCount: 1},
+			},
+			"AndInt64": {
+				v:        int64(1 << 40),
+				update:   bson.D{{"$bit", bson.D{{"v", bson.D{{"and", int64(1<<40 | 1)}}}}}},
+				expected: int64(1 << 40),
+				stat:     &mongo.UpdateResult{MatchedCount: 1, ModifiedCount: 0},
+			},
+			"OrInt64": {
+				v:        int64(1 << 40),
+				update:   bson.D{{"$bit", bson.D{{"v", bson.D{{"or", int64(1)}}}}}},
+				expected: int64(1<<40 | 1),
+				stat:     &mongo.UpdateResult{MatchedCount: 1, ModifiedCount: 1},
+			},
+			"XorInt64": {
+				v:        int64(1<<40 | 1),
+				update:   bson.D{{"$bit", bson.D{{"v", bson.D{{"xor", int64(1 << 40)}}}}}},
+				expected: int64(1),
+				stat:     &mongo.UpdateResult{MatchedCount: 1, ModifiedCount: 1},
+			},
+			"Int32WithInt64": {
+				v:        int32(1),
+				update:   bson.D{{"$bit", bson.D{{"v", bson.D{{"or", int64(2)}}}}}},
+				expected: int64(3),
+				stat:     &mongo.UpdateResult{MatchedCount: 1, ModifiedCount: 1},
+			},
+			"Several": {
+				v:        int32(13),
+				update:   bson.D{{"$bit", bson.D{{"v", bson.D{{"and", int32(10)}, {"or", int32(1)}}}}}},
+				expected: int32(9),
+				stat:     &mongo.UpdateResult{MatchedCount: 1, ModifiedCount: 1},
+			},
+		} {
+			name, tc := name, tc
+			t.Run(name, func(t *testing.T) {
+				t.Parallel()
+				ctx, collection := setup.Setup(t)
+
+				_, err := collection.InsertOne(ctx, bson.D{{"_id", "bit"}, {"v", tc.v}})
+				require.NoError(t, err)
+
+				result, err := collection.UpdateOne(ctx, bson.D{{"_id", "bit"}}, tc.update)
+				require.NoError(t, err)
+				require.Equal(t, tc.stat, result)
+
+				var actual bson.D
+				err = collection.FindOne(ctx, bson.D{{"_id", "bit"}}).Decode(&actual)
+				require.NoError(t, err)
+
+				AssertEqualDocuments(t, bson.D{{"_id", "bit"}, {"v", tc.expected}}, actual)
+			})
+		}
+	})
+
+	t.Run("NoSuchKey", func(t *testing.T) {
+		t.Parallel()
+		ctx, collection := setup.Setup(t)
+
+		_, err := collection.InsertOne(ctx, bson.D{{"_id", "bit"}})
+		require.NoError(t, err)
+
+		_, err = collection.UpdateOne(ctx, bson.D{{"_id", "bit"}}, bson.D{{"$bit", bson.D{{"v", bson.D{{"or", int32(5)}}}}}})
+		require.NoError(t, err)
+
+		var actual bson.D
+		err = collection.FindOne(ctx, bson.D{{"_id", "bit"}}).Decode(&actual)
+		require.NoError(t, err)
+
+		AssertEqualDocuments(t, bson.D{{"_id", "bit"}, {"v", int32(5)}}, actual)
+	})
+
+	t.Run("Err", func(t *testing.T) {
+		t.Parallel()
+
+		for name, tc := range map[string]struct {
+			id     string
+			update bson.D
+			err    mongo.WriteError
+			alt    string
+		}{
+			"NonInteger": {
+				id:     "double",
+				update: bson.D{{"$bit", bson.D{{"v", bson.D{{"and", int32(1)}}}}}},
+				err: mongo.WriteError{
+					Code: 14,
+					Message: "Cannot apply $bit to a value of non-integral type." +
+						`{ _id: "double" } has the field v of non-integer type double`,
+				},
+				alt: "Cannot apply $bit to a value of non-integral type. " +
+					`{_id: "double"} has the field v of non-integer type double`,
+			},
+			"NotDocument": {
+				id:     "int32",
+				update: bson.D{{"$bit", bson.D{{"v", int32(1)}}}},
+				err: mongo.WriteError{
+					Code:    2,
+					Message: "The $bit modifier is not compatible with a int. You must pass in an embedded document: 1",
+				},
+				alt: "The $bit modifier is not compatible with a int. You must pass in an embedded document",
+			},
+			"DoubleOperand": {
+				id:     "int32",
+				update: bson.D{{"$bit", bson.D{{"v", bson.D{{"and", 1.0}}}}}},
+				err: mongo.WriteError{
+					Code:    2,
+					Message: "The $bit modifier field must be an Integer(32/64 bit); a 'double' is not supported here: {and: 1.0}",
+				},
+				alt: "The $bit modifier field must be an Integer(32/64 bit); a 'double' is not supported here",
+			},
+			"UnknownOperator": {
+				id:     "int32",
+				update: bson.D{{"$bit", bson.D{{"v", bson.D{{"not", int32(1)}}}}}},
+				err: mongo.WriteError{
+					Code:    2,
+					Message: "The $bit modifier only supports 'and', 'or', and 'xor', not 'not' which is an unknown operator: {not: 1}",
+				},
+				alt: "The $bit modifier only supports 'and', 'or', and 'xor', not 'not' which is an unknown operator",
+			},
+		} {
+			name, tc := name, tc
+			t.Run(name, func(t *testing.T) {
+				t.Parallel()
+				ctx, collection := setup.Setup(t, shareddata.Scalars)
+
+				_, err := collection.UpdateOne(ctx, bson.D{{"_id", tc.id}}, tc.update)
+				AssertEqualAltWriteError(t, tc.err, tc.alt, err)
+			})
+		}
+	})
+}
+
+func TestUpdateFieldPositional(t *testing.T) {
+	setup.SkipForTigris(t)
+
+	t.Parallel()
+
+	t.Run("IncNestedField", func(t *testing.T) {
+		t.Parallel()
+		ctx, collection := setup.Setup(t)
+
+		_, err := collection.InsertOne(ctx, bson.D{{"_id", "order"}, {"items", bson.A{
+			bson.D{{"sku", "a"}, {"qty", int32(1)}},
+			bson.D{{"sku", "b"}, {"qty", int32(2)}},
+			bson.D{{"sku", "c"}, {"qty", int32(3)}},
+		}}})
+		require.NoError(t, err)
+
+		filter := bson.D{{"_id", "order"}, {"items.sku", "b"}}
+		result, err := collection.UpdateOne(ctx, filter, bson.D{{"$inc", bson.D{{"items.$.qty", int32(1)}}}})
+		require.NoError(t, err)
+		require.Equal(t, &mongo.UpdateResult{MatchedCount: 1, ModifiedCount: 1}, result)
+
+		var actual bson.D
+		require.NoError(t, collection.FindOne(ctx, bson.D{{"_id", "order"}}).Decode(&actual))
+
+		expected := bson.D{{"_id", "order"}, {"items", bson.A{
+			bson.D{{"sku", "a"}, {"qty", int32(1)}},
+			bson.D{{"sku", "b"}, {"qty", int32(3)}},
+			bson.D{{"sku", "c"}, {"qty", int32(3)}},
+		}}}
+		AssertEqualDocuments(t, expected, actual)
+	})
+
+	t.Run("SetScalar", func(t *testing.T) {
+		t.Parallel()
+		ctx, collection := setup.Setup(t)
+
+		_, err := collection.InsertOne(ctx, bson.D{{"_id", "grades"}, {"v", bson.A{int32(80), int32(90), int32(95)}}})
+		require.NoError(t, err)
+
+		filter := bson.D{{"v", bson.D{{"$gt", int32(85)}}}}
+		_, err = collection.UpdateOne(ctx, filter, bson.D{{"$set", bson.D{{"v.$", int32(100)}}}})
+		require.NoError(t, err)
+
+		var actual bson.D
+		require.NoError(t, collection.FindOne(ctx, bson.D{{"_id", "grades"}}).Decode(&actual))
+		AssertEqualDocuments(t, bson.D{{"_id", "grades"}, {"v", bson.A{int32(80), int32(100), int32(95)}}}, actual)
+	})
+
+	t.Run("FindAndModify", func(t *testing.T) {
+		t.Parallel()
+		ctx, collection := setup.Setup(t)
+
+		_, err := collection.InsertOne(ctx, bson.D{{"_id", "order"}, {"items", bson.A{
+			bson.D{{"sku", "a"}, {"qty", int32(1)}},
+			bson.D{{"sku", "b"}, {"qty", int32(2)}},
+		}}})
+		require.NoError(t, err)
+
+		var actual bson.D
+		err = collection.FindOneAndUpdate(
+			ctx,
+			bson.D{{"items.sku", "a"}},
+			bson.D{{"$inc", bson.D{{"items.$.qty", int32(10)}}}},
+			options.FindOneAndUpdate().SetReturnDocument(options.After),
+		).Decode(&actual)
+		require.NoError(t, err)
+
+		expected := bson.D{{"_id", "order"}, {"items", bson.A{
+			bson.D{{"sku", "a"}, {"qty", int32(11)}},
+			bson.D{{"sku", "b"}, {"qty", int32(2)}},
+		}}}
+		AssertEqualDocuments(t, expected, actual)
+	})
+
+	t.Run("NotMatched", func(t *testing.T) {
+		t.Parallel()
+		ctx, collection := setup.Setup(t)
+
+		_, err := collection.InsertOne(ctx, bson.D{{"_id", "order"}, {"items", bson.A{bson.D{{"qty", int32(1)}}}}})
+		require.NoError(t, err)
+
+		_, err = collection.UpdateOne(ctx, bson.D{{"_id", "order"}}, bson.D{{"$inc", bson.D{{"items.$.qty", int32(1)}}}})
+
+		expected := mongo.WriteError{
+			Code:    2,
+			Message: "The positional operator did not find the match needed from the query.",
+		}
+		AssertEqualWriteError(t, expected, err)
+	})
+}
@@ -250,3 +250,87 @@ func TestUpdateReplaceDocuments(t *testing.T) {
 		})
 	}
 }
+
+func TestUpdateImmutableID(t *testing.T) {
+	setup.SkipForTigris(t)
+
+	t.Parallel()
+
+	const msg = "Performing an update on the path '_id' would modify the immutable field '_id'"
+
+	t.Run("SameID", func(t *testing.T) {
+		t.Parallel()
+		ctx, collection := setup.Setup(t)
+
+		_, err := collection.InsertOne(ctx, bson.D{{"_id", "foo"}, {"v", int32(42)}})
+		require.NoError(t, err)
+
+		res, err := collection.UpdateOne(ctx, bson.D{{"_id", "foo"}}, bson.D{{"$set", bson.D{{"_id", "foo"}}}})
+		require.NoError(t, err)
+		assert.Equal(t, &mongo.UpdateResult{MatchedCount: 1, ModifiedCount: 0}, res)
+	})
+
+	for name, tc := range map[string]struct {
+		update  bson.D
+		replace bool
+		err     mongo.WriteError
+		alt     string
+	}{
+		"Set": {
+			update: bson.D{{"$set", bson.D{{"_id", "bar"}}}},
+			err:    mongo.WriteError{Code: 66, Message: msg},
+		},
+		"SetDifferentType": {
+			update: bson.D{{"$set", bson.D{{"_id", int32(1)}}}},
+			err:    mongo.WriteError{Code: 66, Message: msg},
+		},
+		"Unset": {
+			update: bson.D{{"$unset", bson.D{{"_id", ""}}}},
+			err:    mongo.WriteError{Code: 66, Message: msg},
+		},
+		"Replace": {
+			update:  bson.D{{"_id", "bar"}, {"v", int32(43)}},
+			replace: true,
+			err: mongo.WriteError{
+				Code:    66,
+				Message: `After applying the update, the (immutable) field '_id' was found to have been altered to _id: "bar"`,
+			},
+			alt: msg,
+		},
+	} {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			ctx, collection := setup.Setup(t)
+
+			_, err := collection.InsertOne(ctx, bson.D{{"_id", "foo"}, {"v", int32(42)}})
+			require.NoError(t, err)
+
+			if tc.replace {
+				_, err = collection.ReplaceOne(ctx, bson.D{{"_id", "foo"}}, tc.update)
+			} else {
+				_, err = collection.UpdateOne(ctx, bson.D{{"_id", "foo"}}, tc.update)
+			}
+			AssertEqualAltWriteError(t, tc.err, tc.alt, err)
+
+			// the document is not changed
+			var actual bson.D
+			require.NoError(t, collection.FindOne(ctx, bson.D{{"_id", "foo"}}).Decode(&actual))
+			AssertEqualDocuments(t, bson.D{{"_id", "foo"}, {"v", int32(42)}}, actual)
+		})
+	}
+
+	t.Run("FindAndModifyReplace", func(t *testing.T) {
+		t.Parallel()
+		ctx, collection := setup.Setup(t)
+
+		_, err := collection.InsertOne(ctx, bson.D{{"_id", "foo"}, {"v", int32(42)}})
+		require.NoError(t, err)
+
+		err = collection.FindOneAndReplace(ctx, bson.D{{"_id", "foo"}}, bson.D{{"_id", "bar"}}).Err()
+
+		var cmdErr mongo.CommandError
+		require.ErrorAs(t, err, &cmdErr)
+		assert.Equal(t, int32(66), cmdErr.Code)
+	})
+}
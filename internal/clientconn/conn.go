@@ -139,6 +139,14 @@ func (c *conn) run(ctx context.Context) (err error) {
 		close(done)
 	}()
 
+	// connInfo is shared by all requests of this connection;
+	// closing it releases resources bound to the connection, such as cursors
+	connInfo := &conninfo.ConnInfo{
+		PeerAddr:          c.netConn.RemoteAddr(),
+		AggregationStages: c.m.aggregationStages,
+	}
+	connCtx := conninfo.WithConnInfo(ctx, connInfo)
+
 	bufr := bufio.NewReader(c.netConn)
 	bufw := bufio.NewWriter(c.netConn)
 	defer func() {
@@ -146,6 +154,8 @@ func (c *conn) run(ctx context.Context) (err error) {
 			err = e
 		}
 
+		connInfo.Close()
+
 		if c.proxy != nil {
 			c.proxy.Close()
 		}
@@ -153,7 +163,16 @@ func (c *conn) run(ctx context.Context) (err error) {
 		// c.netConn is closed by the caller
 	}()
 
+	// peekDone receives the result of waiting for the next request (see below)
+	var peekDone chan error
+
 	for {
+		if peekDone != nil {
+			if err = <-peekDone; err != nil {
+				return
+			}
+		}
+
 		var reqHeader *wire.MsgHeader
 		var reqBody wire.MsgBody
 		reqHeader, reqBody, err = wire.ReadMessage(bufr)
@@ -161,6 +180,20 @@ func (c *conn) run(ctx context.Context) (err error) {
 			return
 		}
 
+		// Wait for the next request or disconnect while this one is handled.
+		// If the client disconnects, the request's context is canceled,
+		// so in-flight backend queries are canceled too and release their resources.
+		// Peek does not consume the data, so the next request will be read by ReadMessage above.
+		reqCtx, reqCancel := context.WithCancel(connCtx)
+		peekDone = make(chan error, 1)
+		go func() {
+			_, e := bufr.Peek(1)
+			if e != nil {
+				reqCancel()
+			}
+			peekDone <- e
+		}()
+
 		c.l.Debugf("Request header: %s", reqHeader)
 		c.l.Debugf("Request message:\n%s\n\n\n", reqBody)
 
@@ -173,7 +206,7 @@ func (c *conn) run(ctx context.Context) (err error) {
 		var resBody wire.MsgBody
 		var resCloseConn bool
 		if c.mode != ProxyMode {
-			resHeader, resBody, resCloseConn = c.route(ctx, reqHeader, reqBody)
+			resHeader, resBody, resCloseConn = c.route(reqCtx, reqHeader, reqBody)
 			diffLogLevel = c.logResponse("Response", resHeader, resBody, resCloseConn)
 		}
 
@@ -185,7 +218,7 @@ func (c *conn) run(ctx context.Context) (err error) {
 				panic("proxy addr was nil")
 			}
 
-			proxyHeader, proxyBody, _ = c.proxy.Route(ctx, reqHeader, reqBody)
+			proxyHeader, proxyBody, _ = c.proxy.Route(reqCtx, reqHeader, reqBody)
 			if level := c.logResponse("Proxy response", proxyHeader, proxyBody, resCloseConn); level != diffLogLevel {
 				// In principle, normal and proxy responses should be logged with the same level
 				// as they behave the same way. If it's not true, there is a bug somewhere, so
@@ -194,6 +227,8 @@ func (c *conn) run(ctx context.Context) (err error) {
 			}
 		}
 
+		reqCancel()
+
 		// diff in diff mode
 		if c.mode == DiffNormalMode || c.mode == DiffProxyMode {
 			var diffHeader string
@@ -257,6 +292,7 @@ func (c *conn) run(ctx context.Context) (err error) {
 //
 // Handlers to which it routes, should not panic on bad input, but may do so in "impossible" cases.
 // They also should not use recover(). That allows us to use fuzzing.
+// Panics in OP_MSG command handlers are recovered by handleOpMsg and returned as InternalError.
 func (c *conn) route(ctx context.Context, reqHeader *wire.MsgHeader, reqBody wire.MsgBody) (resHeader *wire.MsgHeader, resBody wire.MsgBody, closeConn bool) { //nolint:lll // argument list is too long
 	requests := c.m.requests.MustCurryWith(prometheus.Labels{"opcode": reqHeader.OpCode.String()})
 	var command string
@@ -268,15 +304,25 @@ func (c *conn) route(ctx context.Context, reqHeader *wire.MsgHeader, reqBody wir
 		c.m.responses.WithLabelValues(resHeader.OpCode.String(), command, *result).Inc()
 	}()
 
-	connInfo := &conninfo.ConnInfo{
-		PeerAddr:          c.netConn.RemoteAddr(),
-		AggregationStages: c.m.aggregationStages,
-	}
-	ctx, cancel := context.WithCancel(conninfo.WithConnInfo(ctx, connInfo))
+	ctx, cancel := context.WithCancel(ctx)
 	defer cancel()
 
 	resHeader = new(wire.MsgHeader)
 	var err error
+
+	// compressed requests are handled as uncompressed ones,
+	// and responses are compressed with the same compressor
+	compressed, isCompressed := reqBody.(*wire.OpCompressed)
+	if isCompressed {
+		reqHeader = &wire.MsgHeader{
+			MessageLength: reqHeader.MessageLength,
+			RequestID:     reqHeader.RequestID,
+			ResponseTo:    reqHeader.ResponseTo,
+			OpCode:        compressed.OriginalOpCode,
+		}
+		reqBody = compressed.Message()
+	}
+
 	switch reqHeader.OpCode {
 	case wire.OpCodeMsg:
 		var document *types.Document
@@ -368,6 +414,14 @@ func (c *conn) route(ctx context.Context, reqHeader *wire.MsgHeader, reqBody wir
 		}
 	}
 
+	if isCompressed {
+		if resBody, err = wire.NewOpCompressed(compressed.CompressorID, resBody); err != nil {
+			result = nil
+			panic(err)
+		}
+		resHeader.OpCode = wire.OpCodeCompressed
+	}
+
 	// TODO Don't call MarshalBinary there. Fix header in the caller?
 	// https://github.com/FerretDB/FerretDB/issues/273
 	b, err := resBody.MarshalBinary()
@@ -386,10 +440,23 @@ func (c *conn) route(ctx context.Context, reqHeader *wire.MsgHeader, reqBody wir
 	return
 }
 
-func (c *conn) handleOpMsg(ctx context.Context, msg *wire.OpMsg, cmd string) (*wire.OpMsg, error) {
-	if cmd, ok := common.Commands[cmd]; ok {
-		if cmd.Handler != nil {
-			return cmd.Handler(c.h, ctx, msg)
+// handleOpMsg calls the handler for the given command.
+//
+// If the handler panics, the panic is logged and converted to InternalError,
+// so the client gets a response and the connection stays usable.
+func (c *conn) handleOpMsg(ctx context.Context, msg *wire.OpMsg, cmd string) (res *wire.OpMsg, err error) {
+	if command, ok := common.Commands[cmd]; ok {
+		if command.Handler != nil {
+			defer func() {
+				if p := recover(); p != nil {
+					c.l.Errorw("Handler panicked", "command", cmd, "panic", p, zap.StackSkip("stack", 1))
+
+					res = nil
+					err = common.NewErrorMsg(common.ErrInternalError, fmt.Sprintf("command %s failed: %v", cmd, p))
+				}
+			}()
+
+			return command.Handler(c.h, ctx, msg)
 		}
 	}
 
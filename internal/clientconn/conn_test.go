@@ -0,0 +1,191 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package clientconn
+
+import (
+	"bufio"
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+
+	"github.com/FerretDB/FerretDB/internal/handlers"
+	"github.com/FerretDB/FerretDB/internal/handlers/common"
+	"github.com/FerretDB/FerretDB/internal/handlers/dummy"
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+	"github.com/FerretDB/FerretDB/internal/util/testutil"
+	"github.com/FerretDB/FerretDB/internal/wire"
+)
+
+// panickingHandler is a handler that panics on ping.
+type panickingHandler struct {
+	handlers.Interface
+}
+
+// MsgPing implements handlers.Interface.
+func (h *panickingHandler) MsgPing(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg, error) {
+	panic("ping panicked")
+}
+
+// blockingHandler is a handler that blocks on ping until the request's context is canceled.
+type blockingHandler struct {
+	handlers.Interface
+	started  chan struct{}
+	canceled chan struct{}
+}
+
+// MsgPing implements handlers.Interface.
+func (h *blockingHandler) MsgPing(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg, error) {
+	close(h.started)
+	<-ctx.Done()
+	close(h.canceled)
+
+	return nil, ctx.Err()
+}
+
+// writeCommand sends the command document to the connection without waiting for the response.
+func writeCommand(t *testing.T, bufw *bufio.Writer, requestID int32, cmd *types.Document) {
+	t.Helper()
+
+	var msg wire.OpMsg
+	require.NoError(t, msg.SetSections(wire.OpMsgSection{Documents: []*types.Document{cmd}}))
+
+	b, err := msg.MarshalBinary()
+	require.NoError(t, err)
+
+	header := &wire.MsgHeader{
+		MessageLength: int32(wire.MsgHeaderLen + len(b)),
+		RequestID:     requestID,
+		OpCode:        wire.OpCodeMsg,
+	}
+	require.NoError(t, wire.WriteMessage(bufw, header, &msg))
+	require.NoError(t, bufw.Flush())
+}
+
+// roundTrip sends the command document to the connection and returns the response document.
+func roundTrip(t *testing.T, bufr *bufio.Reader, bufw *bufio.Writer, requestID int32, cmd *types.Document) *types.Document {
+	t.Helper()
+
+	writeCommand(t, bufw, requestID, cmd)
+
+	resHeader, resBody, err := wire.ReadMessage(bufr)
+	require.NoError(t, err)
+	assert.Equal(t, requestID, resHeader.ResponseTo)
+
+	res, err := resBody.(*wire.OpMsg).Document()
+	require.NoError(t, err)
+
+	return res
+}
+
+func TestConnHandlerPanic(t *testing.T) {
+	t.Parallel()
+
+	ctx := testutil.Ctx(t)
+
+	h, err := dummy.New()
+	require.NoError(t, err)
+
+	serverConn, clientConn := net.Pipe()
+	t.Cleanup(func() {
+		clientConn.Close()
+		serverConn.Close()
+	})
+
+	c, err := newConn(&newConnOpts{
+		netConn:     serverConn,
+		mode:        NormalMode,
+		l:           zaptest.NewLogger(t),
+		handler:     &panickingHandler{Interface: h},
+		connMetrics: newConnMetrics(),
+	})
+	require.NoError(t, err)
+
+	runDone := make(chan error, 1)
+	go func() {
+		runDone <- c.run(ctx)
+	}()
+
+	bufr := bufio.NewReader(clientConn)
+	bufw := bufio.NewWriter(clientConn)
+
+	res := roundTrip(t, bufr, bufw, 1, must.NotFail(types.NewDocument("ping", int32(1), "$db", "admin")))
+	assert.Equal(t, float64(0), must.NotFail(res.Get("ok")))
+	assert.Equal(t, int32(common.ErrInternalError), must.NotFail(res.Get("code")))
+	assert.Equal(t, "InternalError", must.NotFail(res.Get("codeName")))
+	assert.Contains(t, must.NotFail(res.Get("errmsg")), "ping panicked")
+
+	// the connection is still usable after the panic
+	res = roundTrip(t, bufr, bufw, 2, must.NotFail(types.NewDocument("buildInfo", int32(1), "$db", "admin")))
+	assert.Equal(t, float64(1), must.NotFail(res.Get("ok")))
+
+	require.NoError(t, clientConn.Close())
+	require.Error(t, <-runDone)
+}
+
+func TestConnClientDisconnect(t *testing.T) {
+	t.Parallel()
+
+	ctx := testutil.Ctx(t)
+
+	h, err := dummy.New()
+	require.NoError(t, err)
+
+	serverConn, clientConn := net.Pipe()
+	t.Cleanup(func() {
+		clientConn.Close()
+		serverConn.Close()
+	})
+
+	bh := &blockingHandler{
+		Interface: h,
+		started:   make(chan struct{}),
+		canceled:  make(chan struct{}),
+	}
+
+	c, err := newConn(&newConnOpts{
+		netConn:     serverConn,
+		mode:        NormalMode,
+		l:           zaptest.NewLogger(t),
+		handler:     bh,
+		connMetrics: newConnMetrics(),
+	})
+	require.NoError(t, err)
+
+	runDone := make(chan error, 1)
+	go func() {
+		runDone <- c.run(ctx)
+	}()
+
+	bufw := bufio.NewWriter(clientConn)
+
+	// the client disconnects while the request is in progress
+	writeCommand(t, bufw, 1, must.NotFail(types.NewDocument("ping", int32(1), "$db", "admin")))
+	<-bh.started
+	require.NoError(t, clientConn.Close())
+
+	select {
+	case <-bh.canceled:
+	case <-time.After(5 * time.Second):
+		t.Fatal("request context was not canceled")
+	}
+
+	require.Error(t, <-runDone)
+}
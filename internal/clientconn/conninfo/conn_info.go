@@ -19,6 +19,7 @@ package conninfo
 import (
 	"context"
 	"net"
+	"sync"
 
 	"github.com/prometheus/client_golang/prometheus"
 )
@@ -30,9 +31,46 @@ type contextKey struct{}
 var connInfoKey = contextKey{}
 
 // ConnInfo represents connection info.
+//
+// A single instance is shared by all requests of the client connection.
 type ConnInfo struct {
 	PeerAddr          net.Addr
 	AggregationStages *prometheus.CounterVec
+
+	rw      sync.Mutex
+	onClose []func()
+	closed  bool
+}
+
+// OnClose registers a function that is called once the client connection is closed.
+// If the connection is already closed, f is called immediately.
+func (connInfo *ConnInfo) OnClose(f func()) {
+	connInfo.rw.Lock()
+
+	if !connInfo.closed {
+		connInfo.onClose = append(connInfo.onClose, f)
+		connInfo.rw.Unlock()
+
+		return
+	}
+
+	connInfo.rw.Unlock()
+
+	f()
+}
+
+// Close calls functions registered with OnClose in the reverse order.
+// It is called by the client connection when it is done; subsequent calls do nothing.
+func (connInfo *ConnInfo) Close() {
+	connInfo.rw.Lock()
+	onClose := connInfo.onClose
+	connInfo.onClose = nil
+	connInfo.closed = true
+	connInfo.rw.Unlock()
+
+	for i := len(onClose) - 1; i >= 0; i-- {
+		onClose[i]()
+	}
 }
 
 // WithConnInfo returns a new context with the given ConnInfo.
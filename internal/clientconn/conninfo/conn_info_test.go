@@ -47,10 +47,31 @@ func TestConnInfo(t *testing.T) {
 			}
 			ctx = WithConnInfo(ctx, connInfo)
 			actual := GetConnInfo(ctx)
-			assert.Equal(t, *connInfo, *actual)
+			assert.Same(t, connInfo, actual)
+			assert.Equal(t, tc.peerAddr, actual.PeerAddr)
 		})
 	}
 
+	t.Run("OnClose", func(t *testing.T) {
+		t.Parallel()
+
+		connInfo := new(ConnInfo)
+
+		var calls []int
+		connInfo.OnClose(func() { calls = append(calls, 1) })
+		connInfo.OnClose(func() { calls = append(calls, 2) })
+		assert.Empty(t, calls)
+
+		connInfo.Close()
+		assert.Equal(t, []int{2, 1}, calls)
+
+		connInfo.Close()
+		assert.Equal(t, []int{2, 1}, calls)
+
+		connInfo.OnClose(func() { calls = append(calls, 3) })
+		assert.Equal(t, []int{2, 1, 3}, calls)
+	})
+
 	// special cases: if context is not set or something wrong is set in context, it panics.
 	for name, tc := range map[string]struct {
 		ctx context.Context
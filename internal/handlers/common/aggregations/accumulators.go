@@ -0,0 +1,296 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aggregations
+
+import (
+	"math"
+
+	"github.com/FerretDB/FerretDB/internal/types"
+)
+
+// accumulator accumulates values of a single $group field for a single group.
+type accumulator interface {
+	// accumulate adds the given evaluated expression value; nil means a missing value.
+	accumulate(v any)
+
+	// result returns the accumulated value.
+	result() any
+}
+
+// newAccumulatorFunc is a type for a function that creates a new accumulator.
+type newAccumulatorFunc func() accumulator
+
+// accumulators maps all supported $group accumulators.
+var accumulators = map[string]newAccumulatorFunc{
+	// sorted alphabetically
+	"$avg":        func() accumulator { return new(avgAccumulator) },
+	"$first":      func() accumulator { return new(firstAccumulator) },
+	"$last":       func() accumulator { return new(lastAccumulator) },
+	"$max":        func() accumulator { return &minMaxAccumulator{order: types.Descending} },
+	"$min":        func() accumulator { return &minMaxAccumulator{order: types.Ascending} },
+	"$stdDevPop":  func() accumulator { return new(stdDevAccumulator) },
+	"$stdDevSamp": func() accumulator { return &stdDevAccumulator{sample: true} },
+	"$sum":        func() accumulator { return new(sumAccumulator) },
+}
+
+// sumAccumulator implements $sum accumulator.
+// Non-numeric values are ignored.
+type sumAccumulator struct {
+	sum any
+}
+
+// accumulate implements accumulator interface.
+func (s *sumAccumulator) accumulate(v any) {
+	if !isNumber(v) {
+		return
+	}
+
+	if s.sum == nil {
+		s.sum = v
+		return
+	}
+
+	s.sum = sumNumbers(s.sum, v)
+}
+
+// result implements accumulator interface.
+func (s *sumAccumulator) result() any {
+	if s.sum == nil {
+		return int32(0)
+	}
+
+	return s.sum
+}
+
+// avgAccumulator implements $avg accumulator.
+// Non-numeric values are ignored; the result is null if there are no numeric values.
+type avgAccumulator struct {
+	sum   sumAccumulator
+	count int64
+}
+
+// accumulate implements accumulator interface.
+func (a *avgAccumulator) accumulate(v any) {
+	if !isNumber(v) {
+		return
+	}
+
+	a.sum.accumulate(v)
+	a.count++
+}
+
+// result implements accumulator interface.
+func (a *avgAccumulator) result() any {
+	if a.count == 0 {
+		return types.Null
+	}
+
+	return toFloat64(a.sum.result()) / float64(a.count)
+}
+
+// stdDevAccumulator implements $stdDevPop and $stdDevSamp accumulators.
+// Non-numeric values are ignored; the result is null if there are no numeric values,
+// or only one for the sample standard deviation.
+type stdDevAccumulator struct {
+	// true for $stdDevSamp, false for $stdDevPop
+	sample bool
+
+	// running mean and sum of squared differences from it (Welford's algorithm)
+	count int64
+	mean  float64
+	m2    float64
+}
+
+// accumulate implements accumulator interface.
+func (s *stdDevAccumulator) accumulate(v any) {
+	if !isNumber(v) {
+		return
+	}
+
+	x := toFloat64(v)
+
+	s.count++
+	delta := x - s.mean
+	s.mean += delta / float64(s.count)
+	s.m2 += delta * (x - s.mean)
+}
+
+// result implements accumulator interface.
+func (s *stdDevAccumulator) result() any {
+	n := s.count
+	if s.sample {
+		n--
+	}
+
+	if n <= 0 {
+		return types.Null
+	}
+
+	return math.Sqrt(s.m2 / float64(n))
+}
+
+// minMaxAccumulator implements $min and $max accumulators.
+// Missing and null values are ignored; the result is null if there are no other values.
+type minMaxAccumulator struct {
+	value any
+
+	// Ascending for $min, Descending for $max
+	order types.SortType
+}
+
+// accumulate implements accumulator interface.
+func (m *minMaxAccumulator) accumulate(v any) {
+	if v == nil || v == types.Null {
+		return
+	}
+
+	if m.value == nil {
+		m.value = v
+		return
+	}
+
+	res := types.CompareOrder(v, m.value, types.Ascending)
+	if (m.order == types.Ascending && res == types.Less) || (m.order == types.Descending && res == types.Greater) {
+		m.value = v
+	}
+}
+
+// result implements accumulator interface.
+func (m *minMaxAccumulator) result() any {
+	if m.value == nil {
+		return types.Null
+	}
+
+	return m.value
+}
+
+// firstAccumulator implements $first accumulator.
+// Missing value of the first document is null.
+type firstAccumulator struct {
+	value any
+	set   bool
+}
+
+// accumulate implements accumulator interface.
+func (f *firstAccumulator) accumulate(v any) {
+	if f.set {
+		return
+	}
+
+	f.value = v
+	f.set = true
+}
+
+// result implements accumulator interface.
+func (f *firstAccumulator) result() any {
+	if f.value == nil {
+		return types.Null
+	}
+
+	return f.value
+}
+
+// lastAccumulator implements $last accumulator.
+// Missing value of the last document is null.
+type lastAccumulator struct {
+	value any
+}
+
+// accumulate implements accumulator interface.
+func (l *lastAccumulator) accumulate(v any) {
+	l.value = v
+}
+
+// result implements accumulator interface.
+func (l *lastAccumulator) result() any {
+	if l.value == nil {
+		return types.Null
+	}
+
+	return l.value
+}
+
+// isNumber returns true if the given value is float64, int32 or int64.
+func isNumber(v any) bool {
+	switch v.(type) {
+	case float64, int32, int64:
+		return true
+	default:
+		return false
+	}
+}
+
+// toFloat64 converts the given number to float64.
+func toFloat64(v any) float64 {
+	switch v := v.(type) {
+	case float64:
+		return v
+	case int32:
+		return float64(v)
+	case int64:
+		return float64(v)
+	default:
+		panic("toFloat64: not a number")
+	}
+}
+
+// sumNumbers returns the sum of two numbers with MongoDB's type promotion:
+// the sum of int32 values is int32 unless it overflows and becomes int64,
+// the sum of int64 values becomes float64 on overflow,
+// and any float64 operand makes the sum float64.
+func sumNumbers(a, b any) any {
+	x, aInt := toInt64(a)
+	y, bInt := toInt64(b)
+
+	if !aInt || !bInt {
+		return toFloat64(a) + toFloat64(b)
+	}
+
+	sum := x + y
+	if (x > 0 && y > 0 && sum < 0) || (x < 0 && y < 0 && sum >= 0) {
+		return float64(x) + float64(y)
+	}
+
+	_, a32 := a.(int32)
+	_, b32 := b.(int32)
+
+	if a32 && b32 && sum >= math.MinInt32 && sum <= math.MaxInt32 {
+		return int32(sum)
+	}
+
+	return sum
+}
+
+// toInt64 converts int32 or int64 value to int64, returning false for other values.
+func toInt64(v any) (int64, bool) {
+	switch v := v.(type) {
+	case int32:
+		return int64(v), true
+	case int64:
+		return v, true
+	default:
+		return 0, false
+	}
+}
+
+// check interfaces
+var (
+	_ accumulator = (*sumAccumulator)(nil)
+	_ accumulator = (*avgAccumulator)(nil)
+	_ accumulator = (*stdDevAccumulator)(nil)
+	_ accumulator = (*minMaxAccumulator)(nil)
+	_ accumulator = (*firstAccumulator)(nil)
+	_ accumulator = (*lastAccumulator)(nil)
+)
@@ -0,0 +1,341 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aggregations
+
+import (
+	"fmt"
+
+	"golang.org/x/exp/slices"
+
+	"github.com/FerretDB/FerretDB/internal/handlers/common"
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+)
+
+// parseAccumulatorFunc is a type for a function that parses the document argument of an accumulator
+// like {$firstN: {input: "$v", n: 2}}.
+// It returns the expression evaluated for each document and a function that creates a new accumulator.
+type parseAccumulatorFunc func(op string, arg any) (any, newAccumulatorFunc, error)
+
+// nAccumulators maps all supported $group accumulators with n parameter.
+var nAccumulators = map[string]parseAccumulatorFunc{
+	// sorted alphabetically
+	"$bottomN": parseSortedNAccumulator,
+	"$firstN":  parseNAccumulator,
+	"$lastN":   parseNAccumulator,
+	"$maxN":    parseNAccumulator,
+	"$minN":    parseNAccumulator,
+	"$topN":    parseSortedNAccumulator,
+}
+
+// parseNAccumulator parses the argument of $firstN, $lastN, $minN and $maxN accumulators.
+func parseNAccumulator(op string, arg any) (any, newAccumulatorFunc, error) {
+	args, n, err := accumulatorArgs(op, arg, "input")
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var newAccumulator newAccumulatorFunc
+
+	switch op {
+	case "$firstN":
+		newAccumulator = func() accumulator { return &firstNAccumulator{n: n} }
+	case "$lastN":
+		newAccumulator = func() accumulator { return &lastNAccumulator{n: n} }
+	case "$minN":
+		newAccumulator = func() accumulator { return &minMaxNAccumulator{n: n, order: types.Ascending} }
+	case "$maxN":
+		newAccumulator = func() accumulator { return &minMaxNAccumulator{n: n, order: types.Descending} }
+	default:
+		panic(fmt.Sprintf("parseNAccumulator: unexpected accumulator %q", op))
+	}
+
+	return must.NotFail(args.Get("input")), newAccumulator, nil
+}
+
+// parseSortedNAccumulator parses the argument of $topN and $bottomN accumulators.
+//
+// The returned expression evaluates to an array of the output value followed by sortBy field values,
+// so the accumulator could sort them.
+func parseSortedNAccumulator(op string, arg any) (any, newAccumulatorFunc, error) {
+	args, n, err := accumulatorArgs(op, arg, "output", "sortBy")
+	if err != nil {
+		return nil, nil, err
+	}
+
+	sortBy, ok := must.NotFail(args.Get("sortBy")).(*types.Document)
+	if !ok {
+		return nil, nil, common.NewErrorMsg(
+			common.ErrAccumulatorNotObject,
+			fmt.Sprintf("expected 'sortBy' to already be an object in the arguments to %s", op),
+		)
+	}
+
+	if sortBy.Len() == 0 {
+		return nil, nil, common.NewErrorMsg(common.ErrSortMissingKey, "$sort stage must have at least one sort key")
+	}
+
+	// sorting no documents validates sort keys and directions
+	if err = common.SortDocuments(nil, sortBy); err != nil {
+		return nil, nil, err
+	}
+
+	expr := must.NotFail(types.NewArray(must.NotFail(args.Get("output"))))
+	orders := make([]types.SortType, sortBy.Len())
+
+	for i, field := range sortBy.Keys() {
+		must.NoError(expr.Append("$" + field))
+		orders[i] = types.SortType(must.NotFail(common.GetWholeNumberParam(must.NotFail(sortBy.Get(field)))))
+	}
+
+	bottom := op == "$bottomN"
+	newAccumulator := func() accumulator { return &sortedNAccumulator{n: n, orders: orders, bottom: bottom} }
+
+	return expr, newAccumulator, nil
+}
+
+// accumulatorArgs checks the document argument of the given accumulator with n parameter
+// and returns it together with n value.
+// The argument must contain n and all given fields, and nothing else.
+func accumulatorArgs(op string, arg any, fields ...string) (*types.Document, int64, error) {
+	args, ok := arg.(*types.Document)
+	if !ok {
+		return nil, 0, common.NewErrorMsg(
+			common.ErrAccumulatorNotObject,
+			fmt.Sprintf("specification of %s must be an object, got %s", op, common.AliasFromType(arg)),
+		)
+	}
+
+	for _, k := range args.Keys() {
+		if k != "n" && !slices.Contains(fields, k) {
+			return nil, 0, common.NewErrorMsg(
+				common.ErrAccumulatorUnknownArg,
+				fmt.Sprintf("Unknown argument for 'n' operator: %s", k),
+			)
+		}
+	}
+
+	v, err := args.Get("n")
+	if err != nil {
+		return nil, 0, common.NewErrorMsg(common.ErrAccumulatorMissingN, "Missing value for 'n'")
+	}
+
+	n, err := common.GetWholeNumberParam(v)
+	if err != nil {
+		return nil, 0, common.NewErrorMsg(
+			common.ErrAccumulatorNNotIntegral,
+			fmt.Sprintf("Value for 'n' must be of integral type, but found %v", v),
+		)
+	}
+
+	if n <= 0 {
+		return nil, 0, common.NewErrorMsg(
+			common.ErrAccumulatorNNotPositive,
+			fmt.Sprintf("'n' must be greater than 0, found %d", n),
+		)
+	}
+
+	for _, f := range fields {
+		if !args.Has(f) {
+			return nil, 0, common.NewErrorMsg(
+				common.ErrAccumulatorMissingInput,
+				fmt.Sprintf("Missing value for '%s'", f),
+			)
+		}
+	}
+
+	return args, n, nil
+}
+
+// firstNAccumulator implements $firstN accumulator.
+// Missing values are null.
+type firstNAccumulator struct {
+	n      int64
+	values []any
+}
+
+// accumulate implements accumulator interface.
+func (f *firstNAccumulator) accumulate(v any) {
+	if int64(len(f.values)) >= f.n {
+		return
+	}
+
+	if v == nil {
+		v = types.Null
+	}
+
+	f.values = append(f.values, v)
+}
+
+// result implements accumulator interface.
+func (f *firstNAccumulator) result() any {
+	return must.NotFail(types.NewArray(f.values...))
+}
+
+// lastNAccumulator implements $lastN accumulator.
+// Missing values are null.
+type lastNAccumulator struct {
+	n      int64
+	values []any
+}
+
+// accumulate implements accumulator interface.
+func (l *lastNAccumulator) accumulate(v any) {
+	if v == nil {
+		v = types.Null
+	}
+
+	l.values = append(l.values, v)
+
+	if int64(len(l.values)) > l.n {
+		l.values = l.values[1:]
+	}
+}
+
+// result implements accumulator interface.
+func (l *lastNAccumulator) result() any {
+	return must.NotFail(types.NewArray(l.values...))
+}
+
+// minMaxNAccumulator implements $minN and $maxN accumulators.
+// Missing and null values are ignored.
+type minMaxNAccumulator struct {
+	n      int64
+	values []any
+
+	// Ascending for $minN, Descending for $maxN
+	order types.SortType
+}
+
+// accumulate implements accumulator interface.
+func (m *minMaxNAccumulator) accumulate(v any) {
+	if v == nil || v == types.Null {
+		return
+	}
+
+	m.values = append(m.values, v)
+
+	// keep memory usage proportional to n
+	if int64(len(m.values))-m.n > m.n {
+		m.trim()
+	}
+}
+
+// trim sorts values and keeps only n first ones.
+func (m *minMaxNAccumulator) trim() {
+	slices.SortStableFunc(m.values, func(a, b any) bool {
+		res := types.CompareOrder(a, b, types.Ascending)
+		if m.order == types.Ascending {
+			return res == types.Less
+		}
+
+		return res == types.Greater
+	})
+
+	if int64(len(m.values)) > m.n {
+		m.values = m.values[:m.n]
+	}
+}
+
+// result implements accumulator interface.
+func (m *minMaxNAccumulator) result() any {
+	m.trim()
+
+	return must.NotFail(types.NewArray(m.values...))
+}
+
+// sortedNEntry represents a single value of $topN or $bottomN accumulator.
+type sortedNEntry struct {
+	output any
+	key    []any
+}
+
+// sortedNAccumulator implements $topN and $bottomN accumulators.
+// Missing sortBy field values are sorted as nulls; missing output values are null.
+type sortedNAccumulator struct {
+	n       int64
+	orders  []types.SortType
+	bottom  bool
+	entries []sortedNEntry
+}
+
+// accumulate implements accumulator interface.
+func (s *sortedNAccumulator) accumulate(v any) {
+	// see parseSortedNAccumulator
+	arr := v.(*types.Array)
+
+	entry := sortedNEntry{
+		output: must.NotFail(arr.Get(0)),
+		key:    make([]any, arr.Len()-1),
+	}
+
+	for i := range entry.key {
+		entry.key[i] = must.NotFail(arr.Get(i + 1))
+	}
+
+	s.entries = append(s.entries, entry)
+
+	// keep memory usage proportional to n
+	if int64(len(s.entries))-s.n > s.n {
+		s.trim()
+	}
+}
+
+// trim sorts entries and keeps only n first ($topN) or last ($bottomN) ones.
+// Entries with equal keys keep their order.
+func (s *sortedNAccumulator) trim() {
+	slices.SortStableFunc(s.entries, func(a, b sortedNEntry) bool {
+		for k, order := range s.orders {
+			switch types.CompareOrder(a.key[k], b.key[k], order) {
+			case types.Less:
+				return order == types.Ascending
+			case types.Greater:
+				return order == types.Descending
+			}
+		}
+
+		return false
+	})
+
+	if int64(len(s.entries)) <= s.n {
+		return
+	}
+
+	if s.bottom {
+		s.entries = s.entries[int64(len(s.entries))-s.n:]
+	} else {
+		s.entries = s.entries[:s.n]
+	}
+}
+
+// result implements accumulator interface.
+func (s *sortedNAccumulator) result() any {
+	s.trim()
+
+	res := types.MakeArray(len(s.entries))
+	for _, entry := range s.entries {
+		must.NoError(res.Append(entry.output))
+	}
+
+	return res
+}
+
+// check interfaces
+var (
+	_ accumulator = (*firstNAccumulator)(nil)
+	_ accumulator = (*lastNAccumulator)(nil)
+	_ accumulator = (*minMaxNAccumulator)(nil)
+	_ accumulator = (*sortedNAccumulator)(nil)
+)
@@ -0,0 +1,85 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aggregations
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/FerretDB/FerretDB/internal/handlers/common"
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+)
+
+// addFields represents $addFields stage and its $set alias.
+type addFields struct {
+	fields *types.Document
+}
+
+// newAddFields creates a new $addFields or $set stage.
+func newAddFields(stage *types.Document) (Stage, error) {
+	name := stage.Command()
+
+	fields, ok := must.NotFail(stage.Get(name)).(*types.Document)
+	if !ok {
+		return nil, common.NewErrorMsg(
+			common.ErrStageAddFieldsInvalidArg,
+			fmt.Sprintf(
+				"%s specification stage must be an object, got %s",
+				name, common.AliasFromType(must.NotFail(stage.Get(name))),
+			),
+		)
+	}
+
+	return &addFields{
+		fields: fields,
+	}, nil
+}
+
+// Process implements Stage interface.
+func (a *addFields) Process(ctx context.Context, in []*types.Document) ([]*types.Document, error) {
+	vars := getVariables(ctx)
+	res := make([]*types.Document, len(in))
+
+	for i, doc := range in {
+		out := doc.DeepCopy()
+
+		for _, k := range a.fields.Keys() {
+			// expressions are evaluated against the input document
+			v, err := evaluateExpression(doc, vars, must.NotFail(a.fields.Get(k)))
+			if err != nil {
+				return nil, err
+			}
+
+			if v == nil {
+				continue
+			}
+
+			if err = out.SetByPath(types.NewPathFromString(k), v); err != nil {
+				return nil, lazyerrors.Error(err)
+			}
+		}
+
+		res[i] = out
+	}
+
+	return res, nil
+}
+
+// check interfaces
+var (
+	_ Stage = (*addFields)(nil)
+)
@@ -0,0 +1,99 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aggregations
+
+import (
+	"fmt"
+
+	"github.com/FerretDB/FerretDB/internal/handlers/common"
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+)
+
+// ChangeStream represents options of $changeStream stage.
+//
+// Unlike other stages, it is not applied to documents;
+// instead, the handler opens a tailable cursor that returns change events,
+// and the rest of the pipeline is applied to them.
+type ChangeStream struct {
+	// ResumeToken is the _data field of the resume token of the event after which the stream starts;
+	// empty if the stream starts from the current moment.
+	ResumeToken string
+}
+
+// newChangeStream creates a new $changeStream stage options.
+func newChangeStream(stage *types.Document) (*ChangeStream, error) {
+	if stage.Len() != 1 {
+		return nil, common.NewErrorMsg(
+			common.ErrStageInvalid,
+			"A pipeline stage specification object must contain exactly one field.",
+		)
+	}
+
+	v := must.NotFail(stage.Get("$changeStream"))
+
+	opts, ok := v.(*types.Document)
+	if !ok {
+		return nil, common.NewErrorMsg(
+			common.ErrTypeMismatch,
+			fmt.Sprintf("BSON field '$changeStream' is the wrong type '%s', expected type 'object'", common.AliasFromType(v)),
+		)
+	}
+
+	unimplementedFields := []string{
+		"allChangesForCluster",
+		"fullDocumentBeforeChange",
+		"showExpandedEvents",
+		"startAtOperationTime",
+	}
+	if err := common.Unimplemented(opts, unimplementedFields...); err != nil {
+		return nil, err
+	}
+
+	// fullDocument is always included for inserts, so the fullDocument option does not matter
+
+	if opts.Has("resumeAfter") && opts.Has("startAfter") {
+		return nil, common.NewErrorMsg(
+			common.ErrBadValue,
+			"Do not specify both 'startAfter' and 'resumeAfter' in a $changeStream stage.",
+		)
+	}
+
+	var cs ChangeStream
+
+	for _, key := range []string{"resumeAfter", "startAfter"} {
+		v, err := opts.Get(key)
+		if err != nil {
+			continue
+		}
+
+		token, ok := v.(*types.Document)
+		if !ok {
+			return nil, common.NewErrorMsg(
+				common.ErrTypeMismatch,
+				fmt.Sprintf(
+					"BSON field '$changeStream.%s' is the wrong type '%s', expected type 'object'",
+					key, common.AliasFromType(v),
+				),
+			)
+		}
+
+		if cs.ResumeToken, err = common.GetRequiredParam[string](token, "_data"); err != nil {
+			return nil, err
+		}
+	}
+
+	return &cs, nil
+}
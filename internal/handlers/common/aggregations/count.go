@@ -0,0 +1,72 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aggregations
+
+import (
+	"context"
+	"math"
+	"strings"
+
+	"github.com/FerretDB/FerretDB/internal/handlers/common"
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+)
+
+// count represents $count stage.
+type count struct {
+	field string
+}
+
+// newCount creates a new $count stage.
+func newCount(stage *types.Document) (Stage, error) {
+	field, ok := must.NotFail(stage.Get("$count")).(string)
+	if !ok {
+		return nil, common.NewErrorMsg(common.ErrStageCountNonString, "the count field must be a non-empty string")
+	}
+
+	switch {
+	case field == "":
+		return nil, common.NewErrorMsg(common.ErrStageCountNonEmptyString, "the count field must be a non-empty string")
+	case strings.HasPrefix(field, "$"):
+		return nil, common.NewErrorMsg(common.ErrStageCountBadPrefix, "the count field cannot be a $-prefixed path")
+	case strings.Contains(field, "."):
+		return nil, common.NewErrorMsg(common.ErrStageCountBadValue, "the count field cannot contain '.'")
+	}
+
+	return &count{
+		field: field,
+	}, nil
+}
+
+// Process implements Stage interface.
+//
+// Like in MongoDB, no document is returned for no input documents.
+func (c *count) Process(ctx context.Context, in []*types.Document) ([]*types.Document, error) {
+	if len(in) == 0 {
+		return nil, nil
+	}
+
+	var n any = int32(len(in))
+	if len(in) > math.MaxInt32 {
+		n = int64(len(in))
+	}
+
+	return []*types.Document{must.NotFail(types.NewDocument(c.field, n))}, nil
+}
+
+// check interfaces
+var (
+	_ Stage = (*count)(nil)
+)
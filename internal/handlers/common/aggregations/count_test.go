@@ -0,0 +1,82 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aggregations
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/FerretDB/FerretDB/internal/handlers/common"
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+	"github.com/FerretDB/FerretDB/internal/util/testutil"
+)
+
+func TestCount(t *testing.T) {
+	t.Parallel()
+
+	// d is a shortcut for creating documents
+	d := func(pairs ...any) *types.Document { return must.NotFail(types.NewDocument(pairs...)) }
+
+	for name, tc := range map[string]struct {
+		field    any
+		in       []*types.Document
+		expected []*types.Document
+		err      error
+	}{
+		"Count": {
+			field:    "total",
+			in:       []*types.Document{d("_id", int32(1)), d("_id", int32(2)), d("_id", int32(3))},
+			expected: []*types.Document{d("total", int32(3))},
+		},
+		"NoDocuments": {
+			field: "total",
+		},
+		"NotString": {
+			field: int32(1),
+			err:   common.NewErrorMsg(common.ErrStageCountNonString, "the count field must be a non-empty string"),
+		},
+		"Empty": {
+			field: "",
+			err:   common.NewErrorMsg(common.ErrStageCountNonEmptyString, "the count field must be a non-empty string"),
+		},
+		"Prefix": {
+			field: "$total",
+			err:   common.NewErrorMsg(common.ErrStageCountBadPrefix, "the count field cannot be a $-prefixed path"),
+		},
+		"Dot": {
+			field: "a.total",
+			err:   common.NewErrorMsg(common.ErrStageCountBadValue, "the count field cannot contain '.'"),
+		},
+	} {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			stage, err := NewStage(d("$count", tc.field))
+			if tc.err != nil {
+				assert.Equal(t, tc.err, err)
+				return
+			}
+			require.NoError(t, err)
+
+			actual, err := stage.Process(testutil.Ctx(t), tc.in)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, actual)
+		})
+	}
+}
@@ -0,0 +1,67 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aggregations
+
+import (
+	"context"
+
+	"github.com/FerretDB/FerretDB/internal/handlers/common"
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+)
+
+// documents represents $documents stage.
+//
+// It is a source stage: it ignores its input and returns the given literal documents.
+type documents struct {
+	docs []*types.Document
+}
+
+// newDocuments creates a new $documents stage.
+func newDocuments(stage *types.Document) (Stage, error) {
+	arr, ok := must.NotFail(stage.Get("$documents")).(*types.Array)
+	if !ok {
+		return nil, common.NewErrorMsg(common.ErrTypeMismatch, "$documents must evaluate to an array of objects")
+	}
+
+	docs := make([]*types.Document, arr.Len())
+	for i := 0; i < arr.Len(); i++ {
+		doc, ok := must.NotFail(arr.Get(i)).(*types.Document)
+		if !ok {
+			return nil, common.NewErrorMsg(common.ErrTypeMismatch, "$documents must evaluate to an array of objects")
+		}
+
+		docs[i] = doc
+	}
+
+	return &documents{
+		docs: docs,
+	}, nil
+}
+
+// Process implements Stage interface.
+func (d *documents) Process(ctx context.Context, in []*types.Document) ([]*types.Document, error) {
+	res := make([]*types.Document, len(d.docs))
+	for i, doc := range d.docs {
+		res[i] = doc.DeepCopy()
+	}
+
+	return res, nil
+}
+
+// check interfaces
+var (
+	_ Stage = (*documents)(nil)
+)
@@ -0,0 +1,244 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aggregations
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/FerretDB/FerretDB/internal/handlers/common"
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+)
+
+// operatorFunc is a type for a function that evaluates an operator expression
+// with already evaluated arguments.
+type operatorFunc func(args []any) (any, error)
+
+// operator describes an operator expression.
+type operator struct {
+	// nArgs is the number of arguments the operator takes.
+	nArgs int
+
+	// parseArgs, if set, is used instead of nArgs to get argument expressions
+	// from the operator value, for example, from a document of named arguments.
+	parseArgs func(v any) ([]any, error)
+
+	// f evaluates the operator.
+	f operatorFunc
+}
+
+// operators maps all supported operator expressions.
+var operators = map[string]operator{
+	// sorted alphabetically
+	"$abs":          {nArgs: 1, f: evaluateAbs},
+	"$ceil":         {nArgs: 1, f: evaluateCeil},
+	"$dateAdd":      newDateArithmetic("$dateAdd", 1),
+	"$dateSubtract": newDateArithmetic("$dateSubtract", -1),
+	"$floor":        {nArgs: 1, f: evaluateFloor},
+	"$round":        {parseArgs: rangedArgs("$round", 1, 2), f: evaluateRound},
+	"$size":         {nArgs: 1, f: evaluateSize},
+	"$sqrt":         {nArgs: 1, f: evaluateSqrt},
+	"$trunc":        {parseArgs: rangedArgs("$trunc", 1, 2), f: evaluateTrunc},
+}
+
+// evaluateExpression evaluates the given aggregation expression for the given document
+// and variables (that may be nil).
+//
+// It returns nil (not types.Null) if the expression evaluates to a missing value,
+// for example, if it is a path to a field that is not present in the document.
+func evaluateExpression(doc *types.Document, vars variables, expr any) (any, error) {
+	switch expr := expr.(type) {
+	case string:
+		if !strings.HasPrefix(expr, "$") {
+			return expr, nil
+		}
+
+		if strings.HasPrefix(expr, "$$") {
+			return vars.evaluate(expr)
+		}
+
+		v, err := doc.GetByPath(types.NewPathFromString(strings.TrimPrefix(expr, "$")))
+		if err != nil {
+			// missing field
+			return nil, nil
+		}
+
+		return v, nil
+
+	case *types.Array:
+		res := types.MakeArray(expr.Len())
+
+		for i := 0; i < expr.Len(); i++ {
+			v, err := evaluateExpression(doc, vars, must.NotFail(expr.Get(i)))
+			if err != nil {
+				return nil, err
+			}
+
+			// missing values become null in arrays
+			if v == nil {
+				v = types.Null
+			}
+
+			must.NoError(res.Append(v))
+		}
+
+		return res, nil
+
+	case *types.Document:
+		if expr.Len() > 0 && strings.HasPrefix(expr.Keys()[0], "$") {
+			return evaluateOperator(doc, vars, expr)
+		}
+
+		res := types.MakeDocument(expr.Len())
+
+		for _, k := range expr.Keys() {
+			v, err := evaluateExpression(doc, vars, must.NotFail(expr.Get(k)))
+			if err != nil {
+				return nil, err
+			}
+
+			// missing values are not added
+			if v == nil {
+				continue
+			}
+
+			must.NoError(res.Set(k, v))
+		}
+
+		return res, nil
+
+	default:
+		return expr, nil
+	}
+}
+
+// evaluateOperator evaluates the given operator expression document like {$size: "$field"}.
+func evaluateOperator(doc *types.Document, vars variables, expr *types.Document) (any, error) {
+	if expr.Len() != 1 {
+		return nil, common.NewErrorMsg(
+			common.ErrExpressionWrongFields,
+			fmt.Sprintf(
+				"an expression specification must contain exactly one field, "+
+					"the name of the expression. Found %d fields",
+				expr.Len(),
+			),
+		)
+	}
+
+	name := expr.Command()
+
+	op, ok := operators[name]
+	if !ok {
+		return nil, common.NewErrorMsg(
+			common.ErrNotImplemented,
+			fmt.Sprintf("aggregation expression %q is not implemented yet", name),
+		)
+	}
+
+	argExprs, err := operatorArgs(name, op, must.NotFail(expr.Get(name)))
+	if err != nil {
+		return nil, err
+	}
+
+	args := make([]any, len(argExprs))
+	for i, argExpr := range argExprs {
+		if args[i], err = evaluateExpression(doc, vars, argExpr); err != nil {
+			return nil, err
+		}
+	}
+
+	return op.f(args)
+}
+
+// operatorArgs returns argument expressions of the given operator value.
+func operatorArgs(name string, op operator, v any) ([]any, error) {
+	if op.parseArgs != nil {
+		return op.parseArgs(v)
+	}
+
+	argExprs := positionalArgs(v)
+
+	if len(argExprs) != op.nArgs {
+		return nil, common.NewErrorMsg(
+			common.ErrExpressionWrongLenOfArgs,
+			fmt.Sprintf(
+				"Expression %s takes exactly %d arguments. %d were passed in.",
+				name, op.nArgs, len(argExprs),
+			),
+		)
+	}
+
+	return argExprs, nil
+}
+
+// evaluateSize evaluates $size operator expression.
+func evaluateSize(args []any) (any, error) {
+	arr, ok := args[0].(*types.Array)
+	if !ok {
+		alias := "missing"
+		if args[0] != nil {
+			alias = common.AliasFromType(args[0])
+		}
+
+		return nil, common.NewErrorMsg(
+			common.ErrExpressionSizeNotArray,
+			fmt.Sprintf("The argument to $size must be an array. Type of argument is: %s", alias),
+		)
+	}
+
+	return int32(arr.Len()), nil
+}
+
+// positionalArgs returns argument expressions of the given operator value;
+// a single argument may be given as is or wrapped in an array.
+func positionalArgs(v any) []any {
+	arr, ok := v.(*types.Array)
+	if !ok {
+		return []any{v}
+	}
+
+	argExprs := make([]any, arr.Len())
+	for i := 0; i < arr.Len(); i++ {
+		argExprs[i] = must.NotFail(arr.Get(i))
+	}
+
+	return argExprs
+}
+
+// rangedArgs returns a function for operator's parseArgs field
+// for operators that take from minArgs to maxArgs positional arguments.
+//
+// Missing optional arguments are returned as nil expressions.
+func rangedArgs(name string, minArgs, maxArgs int) func(v any) ([]any, error) {
+	return func(v any) ([]any, error) {
+		argExprs := positionalArgs(v)
+
+		if len(argExprs) < minArgs || len(argExprs) > maxArgs {
+			return nil, common.NewErrorMsg(
+				common.ErrInvalidArg,
+				fmt.Sprintf(
+					"Expression %s takes at least %d arguments, and at most %d, but %d were passed in.",
+					name, minArgs, maxArgs, len(argExprs),
+				),
+			)
+		}
+
+		res := make([]any, maxArgs)
+		copy(res, argExprs)
+
+		return res, nil
+	}
+}
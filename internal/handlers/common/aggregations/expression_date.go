@@ -0,0 +1,249 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aggregations
+
+import (
+	"encoding/binary"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/exp/slices"
+
+	"github.com/FerretDB/FerretDB/internal/handlers/common"
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+)
+
+// dateArithmeticArgs contains names of $dateAdd and $dateSubtract arguments
+// in the order they are passed to the evaluation function.
+var dateArithmeticArgs = []string{"startDate", "unit", "amount", "timezone"}
+
+// utcOffsetRe matches time zones specified as UTC offsets like "+05", "-0330" or "+05:30".
+var utcOffsetRe = regexp.MustCompile(`^[+-]\d{2}(:?\d{2})?$`)
+
+// newDateArithmetic returns $dateAdd (sign is 1) or $dateSubtract (sign is -1) operator.
+func newDateArithmetic(name string, sign int64) operator {
+	return operator{
+		parseArgs: func(v any) ([]any, error) {
+			return parseDateArithmeticArgs(name, v)
+		},
+		f: func(args []any) (any, error) {
+			return evaluateDateArithmetic(name, sign, args)
+		},
+	}
+}
+
+// parseDateArithmeticArgs returns argument expressions of $dateAdd or $dateSubtract operator
+// like {startDate: "$date", unit: "day", amount: 1, timezone: "Europe/Berlin"}.
+func parseDateArithmeticArgs(name string, v any) ([]any, error) {
+	spec, ok := v.(*types.Document)
+	if !ok {
+		return nil, common.NewErrorMsg(
+			common.ErrDateArithmeticNotObject,
+			fmt.Sprintf("%s expects an object as its argument", name),
+		)
+	}
+
+	argExprs := make([]any, len(dateArithmeticArgs))
+	present := make([]bool, len(dateArithmeticArgs))
+
+	for _, k := range spec.Keys() {
+		i := slices.Index(dateArithmeticArgs, k)
+		if i < 0 {
+			return nil, common.NewErrorMsg(
+				common.ErrDateArithmeticUnknownArg,
+				fmt.Sprintf(
+					"Unrecognized argument to %s: %s. "+
+						"Expected arguments are startDate, unit, amount, and optionally timezone.",
+					name, k,
+				),
+			)
+		}
+
+		argExprs[i] = must.NotFail(spec.Get(k))
+		present[i] = true
+	}
+
+	if !present[0] || !present[1] || !present[2] {
+		return nil, common.NewErrorMsg(
+			common.ErrDateArithmeticMissingArg,
+			fmt.Sprintf("%s requires startDate, unit, and amount to be present", name),
+		)
+	}
+
+	// absent timezone means UTC, while timezone evaluated to a missing value makes the result null
+	if !present[3] {
+		argExprs[3] = "UTC"
+	}
+
+	return argExprs, nil
+}
+
+// evaluateDateArithmetic evaluates $dateAdd or $dateSubtract operator expression.
+func evaluateDateArithmetic(name string, sign int64, args []any) (any, error) {
+	for _, arg := range args[:3] {
+		if isNullish(arg) {
+			return types.Null, nil
+		}
+	}
+
+	var start time.Time
+
+	switch v := args[0].(type) {
+	case time.Time:
+		start = v
+	case types.Timestamp:
+		start = v.Time()
+	case types.ObjectID:
+		start = time.Unix(int64(binary.BigEndian.Uint32(v[:4])), 0)
+	default:
+		return nil, common.NewErrorMsg(
+			common.ErrDateArithmeticBadStartDate,
+			fmt.Sprintf("%s requires startDate to be convertible to a date", name),
+		)
+	}
+
+	unit, ok := args[1].(string)
+	if !ok {
+		return nil, common.NewErrorMsg(
+			common.ErrDateArithmeticBadUnit,
+			fmt.Sprintf("%s expects string defining the time unit", name),
+		)
+	}
+
+	switch unit {
+	case "year", "quarter", "month", "week", "day", "hour", "minute", "second", "millisecond":
+	default:
+		return nil, common.NewErrorMsg(common.ErrFailedToParse, fmt.Sprintf("unknown time unit value: %s", unit))
+	}
+
+	amount, err := common.GetWholeNumberParam(args[2])
+	if err != nil {
+		return nil, common.NewErrorMsg(
+			common.ErrDateArithmeticBadAmount,
+			fmt.Sprintf("%s expects integer amount of time units", name),
+		)
+	}
+
+	if isNullish(args[3]) {
+		return types.Null, nil
+	}
+
+	loc, err := parseTimezone(args[3])
+	if err != nil {
+		return nil, err
+	}
+
+	return addDate(start.In(loc), unit, sign*amount).UTC(), nil
+}
+
+// addDate adds the given amount of time units to t.
+//
+// Days and larger units are added to the calendar date in t's location,
+// so the result keeps the same wall clock time even across daylight saving time changes.
+// Smaller units are added as fixed durations.
+func addDate(t time.Time, unit string, amount int64) time.Time {
+	switch unit {
+	case "year":
+		return addMonths(t, amount*12)
+	case "quarter":
+		return addMonths(t, amount*3)
+	case "month":
+		return addMonths(t, amount)
+	case "week":
+		return t.AddDate(0, 0, int(amount*7))
+	case "day":
+		return t.AddDate(0, 0, int(amount))
+	case "hour":
+		return t.Add(time.Duration(amount) * time.Hour)
+	case "minute":
+		return t.Add(time.Duration(amount) * time.Minute)
+	case "second":
+		return t.Add(time.Duration(amount) * time.Second)
+	case "millisecond":
+		return t.Add(time.Duration(amount) * time.Millisecond)
+	default:
+		panic(fmt.Sprintf("unexpected time unit %q", unit))
+	}
+}
+
+// addMonths adds the given number of months to t.
+//
+// If the resulting month has fewer days than t's day of month, the last day of that month is used,
+// so January 31 plus one month is February 28 (or 29 in a leap year), not March 3.
+func addMonths(t time.Time, months int64) time.Time {
+	year, month, day := t.Date()
+	hour, min, sec := t.Clock()
+
+	// the first day of the resulting month
+	first := time.Date(year, month+time.Month(months), 1, 0, 0, 0, 0, t.Location())
+
+	// the day before the first day of the next month
+	if last := first.AddDate(0, 1, -1).Day(); day > last {
+		day = last
+	}
+
+	return time.Date(first.Year(), first.Month(), day, hour, min, sec, t.Nanosecond(), t.Location())
+}
+
+// parseTimezone returns the location for the given timezone argument of date expressions.
+//
+// Both Olson time zone identifiers like "America/New_York" and UTC offsets like "+05:30" are supported.
+func parseTimezone(tz any) (*time.Location, error) {
+	s, ok := tz.(string)
+	if !ok {
+		return nil, common.NewErrorMsg(
+			common.ErrTimezoneNotString,
+			fmt.Sprintf("timezone must evaluate to a string, found %s", common.AliasFromType(tz)),
+		)
+	}
+
+	if utcOffsetRe.MatchString(s) {
+		digits := strings.ReplaceAll(s[1:], ":", "")
+		hours := must.NotFail(strconv.Atoi(digits[:2]))
+
+		var minutes int
+		if len(digits) > 2 {
+			minutes = must.NotFail(strconv.Atoi(digits[2:]))
+		}
+
+		offset := hours*60*60 + minutes*60
+		if s[0] == '-' {
+			offset = -offset
+		}
+
+		return time.FixedZone(s, offset), nil
+	}
+
+	// LoadLocation treats empty string and "Local" specially
+	if s != "" && s != "Local" {
+		if loc, err := time.LoadLocation(s); err == nil {
+			return loc, nil
+		}
+	}
+
+	return nil, common.NewErrorMsg(
+		common.ErrTimezoneUnknown,
+		fmt.Sprintf("unrecognized time zone identifier: %q", s),
+	)
+}
+
+// isNullish returns true if the given evaluated value is missing or null.
+func isNullish(v any) bool {
+	return v == nil || v == types.Null
+}
@@ -0,0 +1,217 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aggregations
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/FerretDB/FerretDB/internal/handlers/common"
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+)
+
+func TestEvaluateDateArithmetic(t *testing.T) {
+	t.Parallel()
+
+	// d and a are shortcuts for creating documents and arrays
+	d := func(pairs ...any) *types.Document { return must.NotFail(types.NewDocument(pairs...)) }
+	a := func(values ...any) *types.Array { return must.NotFail(types.NewArray(values...)) }
+
+	// date is a shortcut for creating UTC dates
+	date := func(year int, month time.Month, day, hour int) time.Time {
+		return time.Date(year, month, day, hour, 0, 0, 0, time.UTC)
+	}
+
+	doc := d(
+		"jan31", date(2021, time.January, 31, 10),
+		"mar1", date(2021, time.March, 1, 10),
+		"mar31", date(2021, time.March, 31, 10),
+		"str", "foo",
+	)
+
+	for name, tc := range map[string]struct {
+		expr     any
+		expected any
+		err      error
+	}{
+		"AddDayMonthBoundary": {
+			expr:     d("$dateAdd", d("startDate", "$jan31", "unit", "day", "amount", int32(1))),
+			expected: date(2021, time.February, 1, 10),
+		},
+		"AddWeek": {
+			expr:     d("$dateAdd", d("startDate", "$jan31", "unit", "week", "amount", int64(2))),
+			expected: date(2021, time.February, 14, 10),
+		},
+		"AddMonthEnd": {
+			expr:     d("$dateAdd", d("startDate", "$jan31", "unit", "month", "amount", int32(1))),
+			expected: date(2021, time.February, 28, 10),
+		},
+		"AddMonthLeapYear": {
+			expr:     d("$dateAdd", d("startDate", date(2020, time.January, 31, 10), "unit", "month", "amount", int32(1))),
+			expected: date(2020, time.February, 29, 10),
+		},
+		"AddMonthsAcrossYear": {
+			expr:     d("$dateAdd", d("startDate", "$jan31", "unit", "month", "amount", float64(13))),
+			expected: date(2022, time.February, 28, 10),
+		},
+		"AddQuarter": {
+			expr:     d("$dateAdd", d("startDate", "$jan31", "unit", "quarter", "amount", int32(1))),
+			expected: date(2021, time.April, 30, 10),
+		},
+		"AddYearLeapDay": {
+			expr:     d("$dateAdd", d("startDate", date(2020, time.February, 29, 10), "unit", "year", "amount", int32(1))),
+			expected: date(2021, time.February, 28, 10),
+		},
+		"AddHours": {
+			expr:     d("$dateAdd", d("startDate", "$jan31", "unit", "hour", "amount", int32(14))),
+			expected: date(2021, time.February, 1, 0),
+		},
+		"AddMilliseconds": {
+			expr:     d("$dateAdd", d("startDate", "$jan31", "unit", "millisecond", "amount", int32(1500))),
+			expected: time.Date(2021, time.January, 31, 10, 0, 1, 500_000_000, time.UTC),
+		},
+		"AddNegative": {
+			expr:     d("$dateAdd", d("startDate", "$mar31", "unit", "month", "amount", int32(-1))),
+			expected: date(2021, time.February, 28, 10),
+		},
+		"AddTimestamp": {
+			expr:     d("$dateAdd", d("startDate", types.NextTimestamp(date(2021, time.January, 31, 10)), "unit", "day", "amount", int32(1))),
+			expected: date(2021, time.February, 1, 10),
+		},
+		"SubtractDayMonthBoundary": {
+			expr:     d("$dateSubtract", d("startDate", "$mar1", "unit", "day", "amount", int32(1))),
+			expected: date(2021, time.February, 28, 10),
+		},
+		"SubtractMonthEnd": {
+			expr:     d("$dateSubtract", d("startDate", "$mar31", "unit", "month", "amount", int32(1))),
+			expected: date(2021, time.February, 28, 10),
+		},
+		"SubtractMinutes": {
+			expr:     d("$dateSubtract", d("startDate", "$mar1", "unit", "minute", "amount", int32(630))),
+			expected: time.Date(2021, time.February, 28, 23, 30, 0, 0, time.UTC),
+		},
+		"TimezoneOffset": {
+			// 2021-01-31T20:00Z is February 1 in that time zone
+			expr: d("$dateAdd", d(
+				"startDate", date(2021, time.January, 31, 20), "unit", "month", "amount", int32(1), "timezone", "+05:00",
+			)),
+			expected: date(2021, time.February, 28, 20),
+		},
+		"TimezoneDaylightSaving": {
+			// daylight saving time starts on 2021-03-14 in that time zone
+			expr: d("$dateAdd", d(
+				"startDate", date(2021, time.March, 13, 17), "unit", "day", "amount", int32(1), "timezone", "America/New_York",
+			)),
+			expected: date(2021, time.March, 14, 16),
+		},
+		"TimezoneDaylightSavingHours": {
+			expr: d("$dateAdd", d(
+				"startDate", date(2021, time.March, 13, 17), "unit", "hour", "amount", int32(24), "timezone", "America/New_York",
+			)),
+			expected: date(2021, time.March, 14, 17),
+		},
+		"MissingStartDate": {
+			expr:     d("$dateAdd", d("startDate", "$missing", "unit", "day", "amount", int32(1))),
+			expected: types.Null,
+		},
+		"NullAmount": {
+			expr:     d("$dateAdd", d("startDate", "$jan31", "unit", "day", "amount", types.Null)),
+			expected: types.Null,
+		},
+		"MissingTimezone": {
+			expr:     d("$dateAdd", d("startDate", "$jan31", "unit", "day", "amount", int32(1), "timezone", "$missing")),
+			expected: types.Null,
+		},
+		"NotObject": {
+			expr: d("$dateAdd", a("$jan31", "day", int32(1))),
+			err: common.NewErrorMsg(
+				common.ErrDateArithmeticNotObject,
+				"$dateAdd expects an object as its argument",
+			),
+		},
+		"UnknownArg": {
+			expr: d("$dateSubtract", d("startDate", "$jan31", "unit", "day", "amount", int32(1), "foo", int32(1))),
+			err: common.NewErrorMsg(
+				common.ErrDateArithmeticUnknownArg,
+				"Unrecognized argument to $dateSubtract: foo. "+
+					"Expected arguments are startDate, unit, amount, and optionally timezone.",
+			),
+		},
+		"MissingArg": {
+			expr: d("$dateAdd", d("startDate", "$jan31", "unit", "day")),
+			err: common.NewErrorMsg(
+				common.ErrDateArithmeticMissingArg,
+				"$dateAdd requires startDate, unit, and amount to be present",
+			),
+		},
+		"StartDateNotDate": {
+			expr: d("$dateAdd", d("startDate", "$str", "unit", "day", "amount", int32(1))),
+			err: common.NewErrorMsg(
+				common.ErrDateArithmeticBadStartDate,
+				"$dateAdd requires startDate to be convertible to a date",
+			),
+		},
+		"UnitNotString": {
+			expr: d("$dateAdd", d("startDate", "$jan31", "unit", int32(1), "amount", int32(1))),
+			err: common.NewErrorMsg(
+				common.ErrDateArithmeticBadUnit,
+				"$dateAdd expects string defining the time unit",
+			),
+		},
+		"UnknownUnit": {
+			expr: d("$dateAdd", d("startDate", "$jan31", "unit", "days", "amount", int32(1))),
+			err:  common.NewErrorMsg(common.ErrFailedToParse, "unknown time unit value: days"),
+		},
+		"AmountNotInteger": {
+			expr: d("$dateAdd", d("startDate", "$jan31", "unit", "day", "amount", 1.5)),
+			err: common.NewErrorMsg(
+				common.ErrDateArithmeticBadAmount,
+				"$dateAdd expects integer amount of time units",
+			),
+		},
+		"TimezoneNotString": {
+			expr: d("$dateAdd", d("startDate", "$jan31", "unit", "day", "amount", int32(1), "timezone", int32(5))),
+			err: common.NewErrorMsg(
+				common.ErrTimezoneNotString,
+				"timezone must evaluate to a string, found int",
+			),
+		},
+		"TimezoneUnknown": {
+			expr: d("$dateAdd", d("startDate", "$jan31", "unit", "day", "amount", int32(1), "timezone", "Mars/Olympus")),
+			err: common.NewErrorMsg(
+				common.ErrTimezoneUnknown,
+				`unrecognized time zone identifier: "Mars/Olympus"`,
+			),
+		},
+	} {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			actual, err := evaluateExpression(doc, nil, tc.expr)
+			if tc.err != nil {
+				assert.Equal(t, tc.err, err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, actual)
+		})
+	}
+}
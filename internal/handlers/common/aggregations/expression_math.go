@@ -0,0 +1,266 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aggregations
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+
+	"github.com/FerretDB/FerretDB/internal/handlers/common"
+	"github.com/FerretDB/FerretDB/internal/types"
+)
+
+// Range of place argument values of $round and $trunc.
+const (
+	minRoundPlace = -20
+	maxRoundPlace = 100
+)
+
+// evaluateAbs evaluates $abs operator expression.
+func evaluateAbs(args []any) (any, error) {
+	switch v := args[0].(type) {
+	case float64:
+		return math.Abs(v), nil
+
+	case int32:
+		switch {
+		case v == math.MinInt32:
+			// the result does not fit into int32
+			return -int64(v), nil
+		case v < 0:
+			return -v, nil
+		default:
+			return v, nil
+		}
+
+	case int64:
+		switch {
+		case v == math.MinInt64:
+			return nil, common.NewErrorMsg(common.ErrExpressionAbsLongMin, "can't take $abs of long long min")
+		case v < 0:
+			return -v, nil
+		default:
+			return v, nil
+		}
+
+	default:
+		return numericArgResult("$abs", v)
+	}
+}
+
+// evaluateCeil evaluates $ceil operator expression.
+func evaluateCeil(args []any) (any, error) {
+	switch v := args[0].(type) {
+	case float64:
+		return math.Ceil(v), nil
+	case int32, int64:
+		return v, nil
+	default:
+		return numericArgResult("$ceil", v)
+	}
+}
+
+// evaluateFloor evaluates $floor operator expression.
+func evaluateFloor(args []any) (any, error) {
+	switch v := args[0].(type) {
+	case float64:
+		return math.Floor(v), nil
+	case int32, int64:
+		return v, nil
+	default:
+		return numericArgResult("$floor", v)
+	}
+}
+
+// evaluateSqrt evaluates $sqrt operator expression.
+func evaluateSqrt(args []any) (any, error) {
+	var f float64
+
+	switch v := args[0].(type) {
+	case float64:
+		f = v
+	case int32:
+		f = float64(v)
+	case int64:
+		f = float64(v)
+	default:
+		return numericArgResult("$sqrt", v)
+	}
+
+	if f < 0 {
+		return nil, common.NewErrorMsg(
+			common.ErrExpressionSqrtNegative,
+			"$sqrt's argument must be greater than or equal to 0",
+		)
+	}
+
+	return math.Sqrt(f), nil
+}
+
+// numericArgResult returns the result of a single numeric argument operator
+// for the given non-numeric argument: null for null or missing value, error otherwise.
+func numericArgResult(name string, v any) (any, error) {
+	if isNullish(v) {
+		return types.Null, nil
+	}
+
+	return nil, common.NewErrorMsg(
+		common.ErrExpressionNotNumeric,
+		fmt.Sprintf("%s only supports numeric types, not %s", name, common.AliasFromType(v)),
+	)
+}
+
+// evaluateRound evaluates $round operator expression.
+func evaluateRound(args []any) (any, error) {
+	return roundNumber("$round", args, false)
+}
+
+// evaluateTrunc evaluates $trunc operator expression.
+func evaluateTrunc(args []any) (any, error) {
+	return roundNumber("$trunc", args, true)
+}
+
+// roundNumber rounds (or truncates if trunc is true) the first argument
+// to the number of decimal places given by the optional second argument.
+//
+// Negative place rounds to the left of the decimal point, so 1234 rounded to -2 places is 1200.
+// Rounding uses round half to even (banker's rounding) like MongoDB does, so 2.5 rounds to 2.
+func roundNumber(name string, args []any, trunc bool) (any, error) {
+	v := args[0]
+	if isNullish(v) {
+		return types.Null, nil
+	}
+
+	switch v.(type) {
+	case float64, int32, int64:
+	default:
+		return nil, common.NewErrorMsg(
+			common.ErrExpressionRoundNotNumeric,
+			fmt.Sprintf("%s only supports numeric types, not %s", name, common.AliasFromType(v)),
+		)
+	}
+
+	var place int64
+
+	if args[1] != nil {
+		if args[1] == types.Null {
+			return types.Null, nil
+		}
+
+		var err error
+		if place, err = common.GetWholeNumberParam(args[1]); err != nil {
+			return nil, common.NewErrorMsg(
+				common.ErrExpressionRoundPlaceNotIntegral,
+				fmt.Sprintf("precision argument to %s must be a integral value", name),
+			)
+		}
+
+		if place < minRoundPlace || place > maxRoundPlace {
+			return nil, common.NewErrorMsg(
+				common.ErrExpressionRoundPlaceOutOfRange,
+				fmt.Sprintf(
+					"cannot apply %s with precision value %d value must be in [%d, %d]",
+					name, place, minRoundPlace, maxRoundPlace,
+				),
+			)
+		}
+	}
+
+	switch v := v.(type) {
+	case float64:
+		if math.IsNaN(v) || math.IsInf(v, 0) {
+			return v, nil
+		}
+
+		// Use the shortest decimal representation of v rather than its exact binary value,
+		// so 2.675 rounded to 2 places is 2.68 even though it is stored as 2.67499999999999982236431605997495353221893310546875.
+		r, _ := new(big.Rat).SetString(strconv.FormatFloat(v, 'g', -1, 64))
+
+		res, _ := roundRat(r, place, trunc).Float64()
+
+		return res, nil
+
+	case int32:
+		if place >= 0 {
+			return v, nil
+		}
+
+		res := roundRat(new(big.Rat).SetInt64(int64(v)), place, trunc).Num().Int64()
+		if res < math.MinInt32 || res > math.MaxInt32 {
+			return res, nil
+		}
+
+		return int32(res), nil
+
+	case int64:
+		if place >= 0 {
+			return v, nil
+		}
+
+		res := roundRat(new(big.Rat).SetInt64(v), place, trunc).Num()
+		if !res.IsInt64() {
+			f, _ := new(big.Float).SetInt(res).Float64()
+			return f, nil
+		}
+
+		return res.Int64(), nil
+
+	default:
+		panic(fmt.Sprintf("unexpected type %T", v))
+	}
+}
+
+// roundRat returns r rounded (or truncated if trunc is true) to the given number of decimal places.
+//
+// Rounding uses round half to even.
+func roundRat(r *big.Rat, place int64, trunc bool) *big.Rat {
+	scale := new(big.Rat).SetInt(new(big.Int).Exp(big.NewInt(10), big.NewInt(abs(place)), nil))
+
+	scaled := new(big.Rat)
+	if place >= 0 {
+		scaled.Mul(r, scale)
+	} else {
+		scaled.Quo(r, scale)
+	}
+
+	// quotient is truncated toward zero, remainder has the sign of the numerator
+	q, rem := new(big.Int).QuoRem(scaled.Num(), scaled.Denom(), new(big.Int))
+
+	if !trunc {
+		// compare the absolute value of remainder with the half of denominator
+		cmp := new(big.Int).Mul(new(big.Int).Abs(rem), big.NewInt(2)).Cmp(scaled.Denom())
+		if cmp > 0 || (cmp == 0 && q.Bit(0) == 1) {
+			q.Add(q, big.NewInt(int64(scaled.Num().Sign())))
+		}
+	}
+
+	res := new(big.Rat).SetInt(q)
+	if place >= 0 {
+		return res.Quo(res, scale)
+	}
+
+	return res.Mul(res, scale)
+}
+
+// abs returns the absolute value of v.
+func abs(v int64) int64 {
+	if v < 0 {
+		return -v
+	}
+
+	return v
+}
@@ -0,0 +1,230 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aggregations
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/FerretDB/FerretDB/internal/handlers/common"
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+)
+
+func TestEvaluateMath(t *testing.T) {
+	t.Parallel()
+
+	// d and a are shortcuts for creating documents and arrays
+	d := func(pairs ...any) *types.Document { return must.NotFail(types.NewDocument(pairs...)) }
+	a := func(values ...any) *types.Array { return must.NotFail(types.NewArray(values...)) }
+
+	doc := d(
+		"double", -2.5,
+		"int", int32(-7),
+		"long", int64(1250),
+		"str", "foo",
+	)
+
+	for name, tc := range map[string]struct {
+		expr     any
+		expected any
+		err      error
+	}{
+		"AbsDouble": {
+			expr:     d("$abs", "$double"),
+			expected: 2.5,
+		},
+		"AbsInt": {
+			expr:     d("$abs", "$int"),
+			expected: int32(7),
+		},
+		"AbsIntMin": {
+			expr:     d("$abs", int32(math.MinInt32)),
+			expected: int64(-math.MinInt32),
+		},
+		"AbsLongMin": {
+			expr: d("$abs", int64(math.MinInt64)),
+			err:  common.NewErrorMsg(common.ErrExpressionAbsLongMin, "can't take $abs of long long min"),
+		},
+		"AbsMissing": {
+			expr:     d("$abs", "$missing"),
+			expected: types.Null,
+		},
+		"AbsString": {
+			expr: d("$abs", "$str"),
+			err: common.NewErrorMsg(
+				common.ErrExpressionNotNumeric,
+				"$abs only supports numeric types, not string",
+			),
+		},
+		"CeilDouble": {
+			expr:     d("$ceil", "$double"),
+			expected: -2.0,
+		},
+		"CeilInt": {
+			expr:     d("$ceil", "$int"),
+			expected: int32(-7),
+		},
+		"FloorDouble": {
+			expr:     d("$floor", "$double"),
+			expected: -3.0,
+		},
+		"FloorNull": {
+			expr:     d("$floor", types.Null),
+			expected: types.Null,
+		},
+		"FloorString": {
+			expr: d("$floor", "$str"),
+			err: common.NewErrorMsg(
+				common.ErrExpressionNotNumeric,
+				"$floor only supports numeric types, not string",
+			),
+		},
+		"Sqrt": {
+			expr:     d("$sqrt", int32(25)),
+			expected: 5.0,
+		},
+		"SqrtNegative": {
+			expr: d("$sqrt", "$double"),
+			err: common.NewErrorMsg(
+				common.ErrExpressionSqrtNegative,
+				"$sqrt's argument must be greater than or equal to 0",
+			),
+		},
+		"RoundHalfEvenDown": {
+			expr:     d("$round", "$double"),
+			expected: -2.0,
+		},
+		"RoundHalfEvenUp": {
+			expr:     d("$round", a(3.5)),
+			expected: 4.0,
+		},
+		"RoundPlace": {
+			expr:     d("$round", a(1.2345, int32(2))),
+			expected: 1.23,
+		},
+		"RoundPlaceHalfEven": {
+			expr:     d("$round", a(1.225, int32(2))),
+			expected: 1.22,
+		},
+		"RoundPlaceDecimal": {
+			expr:     d("$round", a(2.675, int64(2))),
+			expected: 2.68,
+		},
+		"RoundNegativePlaceDouble": {
+			expr:     d("$round", a(1234.5678, int32(-2))),
+			expected: 1200.0,
+		},
+		"RoundNegativePlaceHalfEven": {
+			expr:     d("$round", a("$long", int32(-2))),
+			expected: int64(1200),
+		},
+		"RoundNegativePlaceHalfEvenUp": {
+			expr:     d("$round", a(int32(1350), int32(-2))),
+			expected: int32(1400),
+		},
+		"RoundNegativePlaceIntOverflow": {
+			expr:     d("$round", a(int32(math.MaxInt32), int32(-1))),
+			expected: int64(2147483650),
+		},
+		"RoundNegativePlaceZero": {
+			expr:     d("$round", a(int32(49), int32(-2))),
+			expected: int32(0),
+		},
+		"RoundPositivePlaceInt": {
+			expr:     d("$round", a("$int", int32(2))),
+			expected: int32(-7),
+		},
+		"RoundNaN": {
+			expr:     d("$round", math.NaN()),
+			expected: math.NaN(),
+		},
+		"RoundNullPlace": {
+			expr:     d("$round", a(1.5, types.Null)),
+			expected: types.Null,
+		},
+		"RoundMissing": {
+			expr:     d("$round", a("$missing", int32(1))),
+			expected: types.Null,
+		},
+		"RoundString": {
+			expr: d("$round", "$str"),
+			err: common.NewErrorMsg(
+				common.ErrExpressionRoundNotNumeric,
+				"$round only supports numeric types, not string",
+			),
+		},
+		"RoundPlaceNotIntegral": {
+			expr: d("$round", a(1.5, 0.5)),
+			err: common.NewErrorMsg(
+				common.ErrExpressionRoundPlaceNotIntegral,
+				"precision argument to $round must be a integral value",
+			),
+		},
+		"RoundPlaceOutOfRange": {
+			expr: d("$round", a(1.5, int32(-21))),
+			err: common.NewErrorMsg(
+				common.ErrExpressionRoundPlaceOutOfRange,
+				"cannot apply $round with precision value -21 value must be in [-20, 100]",
+			),
+		},
+		"RoundTooManyArgs": {
+			expr: d("$round", a(1.5, int32(1), int32(2))),
+			err: common.NewErrorMsg(
+				common.ErrInvalidArg,
+				"Expression $round takes at least 1 arguments, and at most 2, but 3 were passed in.",
+			),
+		},
+		"TruncDouble": {
+			expr:     d("$trunc", "$double"),
+			expected: -2.0,
+		},
+		"TruncPlace": {
+			expr:     d("$trunc", a(1.2399, int32(2))),
+			expected: 1.23,
+		},
+		"TruncNegativePlace": {
+			expr:     d("$trunc", a(int64(-1299), int32(-2))),
+			expected: int64(-1200),
+		},
+		"TruncNegativePlaceDouble": {
+			expr:     d("$trunc", a(1299.99, int32(-1))),
+			expected: 1290.0,
+		},
+	} {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			actual, err := evaluateExpression(doc, nil, tc.expr)
+			if tc.err != nil {
+				assert.Equal(t, tc.err, err)
+				return
+			}
+
+			require.NoError(t, err)
+
+			if f, ok := tc.expected.(float64); ok && math.IsNaN(f) {
+				assert.True(t, math.IsNaN(actual.(float64)))
+				return
+			}
+
+			assert.Equal(t, tc.expected, actual)
+		})
+	}
+}
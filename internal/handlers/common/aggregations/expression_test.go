@@ -0,0 +1,168 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aggregations
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/FerretDB/FerretDB/internal/handlers/common"
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+)
+
+func TestEvaluateExpression(t *testing.T) {
+	t.Parallel()
+
+	// d and a are shortcuts for creating documents and arrays
+	d := func(pairs ...any) *types.Document { return must.NotFail(types.NewDocument(pairs...)) }
+	a := func(values ...any) *types.Array { return must.NotFail(types.NewArray(values...)) }
+
+	doc := d(
+		"empty", a(),
+		"three", a(int32(1), "2", a()),
+		"str", "foo",
+		"doc", d("arr", a(int32(1), int32(2))),
+	)
+
+	vars := variables{"new": d("v", int32(42))}
+
+	for name, tc := range map[string]struct {
+		expr     any
+		expected any
+		err      error
+	}{
+		"Literal": {
+			expr:     int32(42),
+			expected: int32(42),
+		},
+		"String": {
+			expr:     "foo",
+			expected: "foo",
+		},
+		"Path": {
+			expr:     "$doc.arr",
+			expected: a(int32(1), int32(2)),
+		},
+		"PathMissing": {
+			expr: "$missing",
+		},
+		"Array": {
+			expr:     a("$str", "$missing"),
+			expected: a("foo", types.Null),
+		},
+		"Document": {
+			expr:     d("s", "$str", "m", "$missing"),
+			expected: d("s", "foo"),
+		},
+		"Variable": {
+			expr:     "$$new",
+			expected: d("v", int32(42)),
+		},
+		"VariablePath": {
+			expr:     "$$new.v",
+			expected: int32(42),
+		},
+		"VariablePathMissing": {
+			expr: "$$new.missing",
+		},
+		"VariableNotImplemented": {
+			expr: "$$ROOT",
+			err: common.NewErrorMsg(
+				common.ErrNotImplemented,
+				`aggregation expression variable "$$ROOT" is not implemented yet`,
+			),
+		},
+		"SizeEmpty": {
+			expr:     d("$size", "$empty"),
+			expected: int32(0),
+		},
+		"SizeThree": {
+			expr:     d("$size", "$three"),
+			expected: int32(3),
+		},
+		"SizeNested": {
+			expr:     d("$size", a("$doc.arr")),
+			expected: int32(2),
+		},
+		"SizeLiteral": {
+			expr:     d("$size", a(a("a", "b", "c", "d"))),
+			expected: int32(4),
+		},
+		"SizeNestedExpression": {
+			expr:     d("$size", a(a("$str", d("$size", "$three")))),
+			expected: int32(2),
+		},
+		"SizeString": {
+			expr: d("$size", "$str"),
+			err: common.NewErrorMsg(
+				common.ErrExpressionSizeNotArray,
+				"The argument to $size must be an array. Type of argument is: string",
+			),
+		},
+		"SizeMissing": {
+			expr: d("$size", "$missing"),
+			err: common.NewErrorMsg(
+				common.ErrExpressionSizeNotArray,
+				"The argument to $size must be an array. Type of argument is: missing",
+			),
+		},
+		"SizeDocument": {
+			expr: d("$size", "$doc"),
+			err: common.NewErrorMsg(
+				common.ErrExpressionSizeNotArray,
+				"The argument to $size must be an array. Type of argument is: object",
+			),
+		},
+		"SizeTooManyArgs": {
+			expr: d("$size", a("$empty", "$three")),
+			err: common.NewErrorMsg(
+				common.ErrExpressionWrongLenOfArgs,
+				"Expression $size takes exactly 1 arguments. 2 were passed in.",
+			),
+		},
+		"SizeExtraField": {
+			expr: d("$size", "$empty", "foo", int32(1)),
+			err: common.NewErrorMsg(
+				common.ErrExpressionWrongFields,
+				"an expression specification must contain exactly one field, "+
+					"the name of the expression. Found 2 fields",
+			),
+		},
+		"NotImplemented": {
+			expr: d("$foo", int32(1)),
+			err: common.NewErrorMsg(
+				common.ErrNotImplemented,
+				`aggregation expression "$foo" is not implemented yet`,
+			),
+		},
+	} {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			actual, err := evaluateExpression(doc, vars, tc.expr)
+			if tc.err != nil {
+				assert.Equal(t, tc.err, err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, actual)
+		})
+	}
+}
@@ -0,0 +1,239 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aggregations
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/FerretDB/FerretDB/internal/handlers/common"
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+)
+
+// group represents $group stage.
+type group struct {
+	id     any
+	fields []groupField
+}
+
+// groupField represents a single accumulated field of $group stage.
+type groupField struct {
+	name           string
+	newAccumulator newAccumulatorFunc
+	expr           any
+}
+
+// groupResult represents a single group of documents with the same key.
+type groupResult struct {
+	id           any
+	accumulators []accumulator
+}
+
+// newGroup creates a new $group stage.
+func newGroup(stage *types.Document) (Stage, error) {
+	spec, ok := must.NotFail(stage.Get("$group")).(*types.Document)
+	if !ok {
+		return nil, common.NewErrorMsg(common.ErrStageGroupInvalidFields, "a group's fields must be specified in an object")
+	}
+
+	id, err := spec.Get("_id")
+	if err != nil {
+		return nil, common.NewErrorMsg(common.ErrStageGroupMissingID, "a group specification must include an _id")
+	}
+
+	res := &group{
+		id: id,
+	}
+
+	for _, name := range spec.Keys() {
+		if name == "_id" {
+			continue
+		}
+
+		acc, ok := must.NotFail(spec.Get(name)).(*types.Document)
+		if !ok || acc.Len() == 0 {
+			return nil, common.NewErrorMsg(
+				common.ErrStageGroupInvalidAccumulator,
+				fmt.Sprintf("The field '%s' must be an accumulator object", name),
+			)
+		}
+
+		if acc.Len() > 1 {
+			return nil, common.NewErrorMsg(
+				common.ErrStageGroupMultipleAccumulator,
+				fmt.Sprintf("The field '%s' must specify one accumulator", name),
+			)
+		}
+
+		op := acc.Command()
+		expr := must.NotFail(acc.Get(op))
+
+		if parse, ok := nAccumulators[op]; ok {
+			expr, newAccumulator, err := parse(op, expr)
+			if err != nil {
+				return nil, err
+			}
+
+			res.fields = append(res.fields, groupField{
+				name:           name,
+				newAccumulator: newAccumulator,
+				expr:           expr,
+			})
+
+			continue
+		}
+
+		newAccumulator, ok := accumulators[op]
+		if !ok {
+			return nil, common.NewErrorMsg(
+				common.ErrNotImplemented,
+				fmt.Sprintf("`$group` accumulator %q is not implemented yet", op),
+			)
+		}
+
+		if _, ok := expr.(*types.Array); ok {
+			return nil, common.NewErrorMsg(
+				common.ErrStageGroupUnaryOperator,
+				fmt.Sprintf("The %s accumulator is a unary operator", op),
+			)
+		}
+
+		res.fields = append(res.fields, groupField{
+			name:           name,
+			newAccumulator: newAccumulator,
+			expr:           expr,
+		})
+	}
+
+	return res, nil
+}
+
+// Process implements Stage interface.
+func (g *group) Process(ctx context.Context, in []*types.Document) ([]*types.Document, error) {
+	vars := getVariables(ctx)
+	groups := map[string]*groupResult{}
+
+	// keys in order of their first appearance
+	var keys []string
+
+	for _, doc := range in {
+		id, err := evaluateExpression(doc, vars, g.id)
+		if err != nil {
+			return nil, err
+		}
+
+		// documents with missing group key are grouped together with documents with null key
+		if id == nil {
+			id = types.Null
+		}
+
+		key := groupKey(id)
+
+		res, ok := groups[key]
+		if !ok {
+			res = &groupResult{
+				id:           id,
+				accumulators: make([]accumulator, len(g.fields)),
+			}
+
+			for i, f := range g.fields {
+				res.accumulators[i] = f.newAccumulator()
+			}
+
+			groups[key] = res
+			keys = append(keys, key)
+		}
+
+		for i, f := range g.fields {
+			v, err := evaluateExpression(doc, vars, f.expr)
+			if err != nil {
+				return nil, err
+			}
+
+			res.accumulators[i].accumulate(v)
+		}
+	}
+
+	out := make([]*types.Document, len(keys))
+
+	for i, key := range keys {
+		res := groups[key]
+
+		doc := must.NotFail(types.NewDocument("_id", res.id))
+		for j, f := range g.fields {
+			must.NoError(doc.Set(f.name, res.accumulators[j].result()))
+		}
+
+		out[i] = doc
+	}
+
+	return out, nil
+}
+
+// groupKey returns the canonical representation of the given group key value.
+//
+// Values that are equal in MongoDB's comparison have the same representation,
+// so numbers of different types with the same value are in the same group.
+func groupKey(v any) string {
+	switch v := v.(type) {
+	case *types.Document:
+		parts := make([]string, 0, v.Len())
+		for _, k := range v.Keys() {
+			parts = append(parts, strconv.Quote(k)+":"+groupKey(must.NotFail(v.Get(k))))
+		}
+
+		return "{" + strings.Join(parts, ",") + "}"
+
+	case *types.Array:
+		parts := make([]string, v.Len())
+		for i := 0; i < v.Len(); i++ {
+			parts[i] = groupKey(must.NotFail(v.Get(i)))
+		}
+
+		return "[" + strings.Join(parts, ",") + "]"
+
+	case float64:
+		if v == math.Trunc(v) && v >= math.MinInt64 && v < math.MaxInt64 {
+			return "number:" + strconv.FormatInt(int64(v), 10)
+		}
+
+		return "number:" + strconv.FormatFloat(v, 'g', -1, 64)
+
+	case int32:
+		return "number:" + strconv.FormatInt(int64(v), 10)
+
+	case int64:
+		return "number:" + strconv.FormatInt(v, 10)
+
+	case string:
+		return "string:" + strconv.Quote(v)
+
+	case time.Time:
+		return "date:" + strconv.FormatInt(v.UnixMilli(), 10)
+
+	default:
+		return fmt.Sprintf("%T:%v", v, v)
+	}
+}
+
+// check interfaces
+var (
+	_ Stage = (*group)(nil)
+)
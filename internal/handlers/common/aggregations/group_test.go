@@ -0,0 +1,375 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aggregations
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/FerretDB/FerretDB/internal/handlers/common"
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+	"github.com/FerretDB/FerretDB/internal/util/testutil"
+)
+
+func TestGroup(t *testing.T) {
+	t.Parallel()
+
+	// d is a shortcut for creating documents
+	d := func(pairs ...any) *types.Document { return must.NotFail(types.NewDocument(pairs...)) }
+
+	docs := []*types.Document{
+		d("_id", "1", "k", "a", "v", int32(1)),
+		d("_id", "2", "k", "a", "v", int64(2)),
+		d("_id", "3", "k", "b", "v", 1.5),
+		d("_id", "4", "k", "b", "v", "foo"),
+		d("_id", "5", "k", int32(1), "v", int32(math.MaxInt32)),
+		d("_id", "6", "k", 1.0, "v", int32(1)),
+		d("_id", "7", "v", types.Null),
+	}
+
+	for name, tc := range map[string]struct {
+		spec     *types.Document
+		expected []*types.Document
+		err      error
+	}{
+		"Null": {
+			spec: d("_id", types.Null, "count", d("$sum", int32(1)), "sum", d("$sum", "$v")),
+			expected: []*types.Document{
+				d("_id", types.Null, "count", int32(7), "sum", float64(math.MaxInt32)+5.5),
+			},
+		},
+		"Sum": {
+			spec: d("_id", "$k", "sum", d("$sum", "$v")),
+			expected: []*types.Document{
+				d("_id", "a", "sum", int64(3)),
+				d("_id", "b", "sum", 1.5),
+				d("_id", int32(1), "sum", int64(math.MaxInt32)+1),
+				d("_id", types.Null, "sum", int32(0)),
+			},
+		},
+		"AvgMinMax": {
+			spec: d("_id", "$k", "avg", d("$avg", "$v"), "min", d("$min", "$v"), "max", d("$max", "$v")),
+			expected: []*types.Document{
+				d("_id", "a", "avg", 1.5, "min", int32(1), "max", int64(2)),
+				d("_id", "b", "avg", 1.5, "min", 1.5, "max", "foo"),
+				d("_id", int32(1), "avg", (float64(math.MaxInt32)+1)/2, "min", int32(1), "max", int32(math.MaxInt32)),
+				d("_id", types.Null, "avg", types.Null, "min", types.Null, "max", types.Null),
+			},
+		},
+		"FirstLast": {
+			spec: d("_id", "$k", "first", d("$first", "$_id"), "last", d("$last", "$v")),
+			expected: []*types.Document{
+				d("_id", "a", "first", "1", "last", int64(2)),
+				d("_id", "b", "first", "3", "last", "foo"),
+				d("_id", int32(1), "first", "5", "last", int32(1)),
+				d("_id", types.Null, "first", "7", "last", types.Null),
+			},
+		},
+		"DocumentKey": {
+			spec: d("_id", d("key", "$k"), "count", d("$sum", int32(1))),
+			expected: []*types.Document{
+				d("_id", d("key", "a"), "count", int32(2)),
+				d("_id", d("key", "b"), "count", int32(2)),
+				d("_id", d("key", int32(1)), "count", int32(2)),
+				d("_id", d(), "count", int32(1)),
+			},
+		},
+		"NotDocument": {
+			spec: nil,
+			err:  common.NewErrorMsg(common.ErrStageGroupInvalidFields, "a group's fields must be specified in an object"),
+		},
+		"MissingID": {
+			spec: d("count", d("$sum", int32(1))),
+			err:  common.NewErrorMsg(common.ErrStageGroupMissingID, "a group specification must include an _id"),
+		},
+		"NotAccumulator": {
+			spec: d("_id", types.Null, "count", int32(1)),
+			err: common.NewErrorMsg(
+				common.ErrStageGroupInvalidAccumulator,
+				"The field 'count' must be an accumulator object",
+			),
+		},
+		"TwoAccumulators": {
+			spec: d("_id", types.Null, "count", d("$sum", int32(1), "$avg", int32(1))),
+			err: common.NewErrorMsg(
+				common.ErrStageGroupMultipleAccumulator,
+				"The field 'count' must specify one accumulator",
+			),
+		},
+		"UnaryOperator": {
+			spec: d("_id", types.Null, "count", d("$sum", must.NotFail(types.NewArray(int32(1))))),
+			err: common.NewErrorMsg(
+				common.ErrStageGroupUnaryOperator,
+				"The $sum accumulator is a unary operator",
+			),
+		},
+		"NotImplemented": {
+			spec: d("_id", types.Null, "all", d("$push", "$v")),
+			err: common.NewErrorMsg(
+				common.ErrNotImplemented,
+				"`$group` accumulator \"$push\" is not implemented yet",
+			),
+		},
+	} {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			var spec any = tc.spec
+			if tc.spec == nil {
+				spec = "foo"
+			}
+
+			stage, err := NewStage(d("$group", spec))
+			if tc.err != nil {
+				assert.Equal(t, tc.err, err)
+				return
+			}
+			require.NoError(t, err)
+
+			actual, err := stage.Process(testutil.Ctx(t), docs)
+			require.NoError(t, err)
+			testutil.AssertEqualSlices(t, tc.expected, actual)
+		})
+	}
+}
+
+func TestGroupNullID(t *testing.T) {
+	t.Parallel()
+
+	// d is a shortcut for creating documents
+	d := func(pairs ...any) *types.Document { return must.NotFail(types.NewDocument(pairs...)) }
+
+	docs := []*types.Document{
+		d("_id", "null", "k", types.Null, "v", int32(1)),
+		d("_id", "missing", "v", int32(2)),
+		d("_id", "value", "k", "a", "v", int32(3)),
+	}
+
+	t.Run("Literal", func(t *testing.T) {
+		t.Parallel()
+
+		stage, err := NewStage(d("$group", d("_id", types.Null, "total", d("$sum", "$v"))))
+		require.NoError(t, err)
+
+		// all documents are in a single group
+		actual, err := stage.Process(testutil.Ctx(t), docs)
+		require.NoError(t, err)
+		assert.Equal(t, []*types.Document{d("_id", types.Null, "total", int32(6))}, actual)
+
+		// but there are no groups without documents
+		actual, err = stage.Process(testutil.Ctx(t), nil)
+		require.NoError(t, err)
+		assert.Empty(t, actual)
+	})
+
+	t.Run("Field", func(t *testing.T) {
+		t.Parallel()
+
+		stage, err := NewStage(d("$group", d("_id", "$k", "ids", d("$first", "$_id"), "total", d("$sum", "$v"))))
+		require.NoError(t, err)
+
+		// documents with null and missing key are grouped together
+		actual, err := stage.Process(testutil.Ctx(t), docs)
+		require.NoError(t, err)
+
+		expected := []*types.Document{
+			d("_id", types.Null, "ids", "null", "total", int32(3)),
+			d("_id", "a", "ids", "value", "total", int32(3)),
+		}
+		testutil.AssertEqualSlices(t, expected, actual)
+	})
+
+	t.Run("Document", func(t *testing.T) {
+		t.Parallel()
+
+		stage, err := NewStage(d("$group", d("_id", d("k", "$k"), "total", d("$sum", "$v"))))
+		require.NoError(t, err)
+
+		// but in a document key, null field value differs from missing field
+		actual, err := stage.Process(testutil.Ctx(t), docs)
+		require.NoError(t, err)
+
+		expected := []*types.Document{
+			d("_id", d("k", types.Null), "total", int32(1)),
+			d("_id", d(), "total", int32(2)),
+			d("_id", d("k", "a"), "total", int32(3)),
+		}
+		testutil.AssertEqualSlices(t, expected, actual)
+	})
+}
+
+func TestGroupStdDev(t *testing.T) {
+	t.Parallel()
+
+	// d is a shortcut for creating documents
+	d := func(pairs ...any) *types.Document { return must.NotFail(types.NewDocument(pairs...)) }
+
+	// population standard deviation of 2, 4, 4, 4, 5, 5, 7, 9 is 2: the mean is 5,
+	// and the sum of squared differences is 9 + 1 + 1 + 1 + 0 + 0 + 4 + 16 = 32;
+	// the sample standard deviation is sqrt(32 / 7)
+	docs := []*types.Document{
+		d("k", "a", "v", int32(2)),
+		d("k", "a", "v", int64(4)),
+		d("k", "a", "v", 4.0),
+		d("k", "a", "v", int32(4)),
+		d("k", "a", "v", "foo"),
+		d("k", "a", "v", int32(5)),
+		d("k", "a", "v", int32(5)),
+		d("k", "a"),
+		d("k", "a", "v", 7.0),
+		d("k", "a", "v", int64(9)),
+		d("k", "b", "v", int32(42)),
+		d("k", "c", "v", types.Null),
+	}
+
+	stage, err := NewStage(d("$group", d(
+		"_id", "$k",
+		"pop", d("$stdDevPop", "$v"),
+		"samp", d("$stdDevSamp", "$v"),
+	)))
+	require.NoError(t, err)
+
+	actual, err := stage.Process(testutil.Ctx(t), docs)
+	require.NoError(t, err)
+	require.Len(t, actual, 3)
+
+	assert.Equal(t, "a", must.NotFail(actual[0].Get("_id")))
+	assert.InDelta(t, 2.0, must.NotFail(actual[0].Get("pop")), 1e-9)
+	assert.InDelta(t, math.Sqrt(32.0/7), must.NotFail(actual[0].Get("samp")), 1e-9)
+
+	// a single value has zero population deviation, and no sample deviation
+	assert.Equal(t, d("_id", "b", "pop", 0.0, "samp", types.Null), actual[1])
+
+	// no numeric values
+	assert.Equal(t, d("_id", "c", "pop", types.Null, "samp", types.Null), actual[2])
+}
+
+func TestGroupNAccumulators(t *testing.T) {
+	t.Parallel()
+
+	// d and a are shortcuts for creating documents and arrays
+	d := func(pairs ...any) *types.Document { return must.NotFail(types.NewDocument(pairs...)) }
+	a := func(values ...any) *types.Array { return must.NotFail(types.NewArray(values...)) }
+
+	docs := []*types.Document{
+		d("_id", "1", "k", "a", "v", int32(3), "s", d("x", int32(2))),
+		d("_id", "2", "k", "a", "v", 1.5, "s", d("x", int32(1))),
+		d("_id", "3", "k", "a", "v", "foo"),
+		d("_id", "4", "k", "a", "v", int64(7), "s", d("x", int32(2))),
+		d("_id", "5", "k", "a", "s", d("x", int32(3))),
+		d("_id", "6", "k", "b", "v", int32(42)),
+	}
+
+	for name, tc := range map[string]struct {
+		spec     *types.Document
+		expected []*types.Document
+		err      error
+	}{
+		"TopN": {
+			spec: d("_id", "$k", "top", d("$topN", d("n", int32(3), "sortBy", d("s.x", int32(-1), "v", 1.0), "output", "$_id"))),
+			expected: []*types.Document{
+				d("_id", "a", "top", a("5", "1", "4")),
+				d("_id", "b", "top", a("6")),
+			},
+		},
+		"BottomN": {
+			spec: d("_id", "$k", "bottom", d("$bottomN", d("n", int64(2), "sortBy", d("s.x", int32(1)), "output", "$v"))),
+			expected: []*types.Document{
+				d("_id", "a", "bottom", a(int64(7), types.Null)),
+				d("_id", "b", "bottom", a(int32(42))),
+			},
+		},
+		"MinN": {
+			spec: d("_id", "$k", "min", d("$minN", d("input", "$v", "n", int32(3)))),
+			expected: []*types.Document{
+				d("_id", "a", "min", a(1.5, int32(3), int64(7))),
+				d("_id", "b", "min", a(int32(42))),
+			},
+		},
+		"MaxN": {
+			spec: d("_id", "$k", "max", d("$maxN", d("input", "$v", "n", int32(2)))),
+			expected: []*types.Document{
+				d("_id", "a", "max", a("foo", int64(7))),
+				d("_id", "b", "max", a(int32(42))),
+			},
+		},
+		"FirstLastN": {
+			spec: d(
+				"_id", "$k",
+				"first", d("$firstN", d("input", "$s.x", "n", int32(2))),
+				"last", d("$lastN", d("input", "$v", "n", 2.0)),
+			),
+			expected: []*types.Document{
+				d("_id", "a", "first", a(int32(2), int32(1)), "last", a(int64(7), types.Null)),
+				d("_id", "b", "first", a(types.Null), "last", a(int32(42))),
+			},
+		},
+		"NotDocument": {
+			spec: d("_id", types.Null, "min", d("$minN", "$v")),
+			err: common.NewErrorMsg(
+				common.ErrAccumulatorNotObject,
+				"specification of $minN must be an object, got string",
+			),
+		},
+		"UnknownArg": {
+			spec: d("_id", types.Null, "min", d("$minN", d("input", "$v", "n", int32(1), "foo", int32(1)))),
+			err:  common.NewErrorMsg(common.ErrAccumulatorUnknownArg, "Unknown argument for 'n' operator: foo"),
+		},
+		"MissingN": {
+			spec: d("_id", types.Null, "first", d("$firstN", d("input", "$v"))),
+			err:  common.NewErrorMsg(common.ErrAccumulatorMissingN, "Missing value for 'n'"),
+		},
+		"NotPositiveN": {
+			spec: d("_id", types.Null, "first", d("$firstN", d("input", "$v", "n", int32(0)))),
+			err:  common.NewErrorMsg(common.ErrAccumulatorNNotPositive, "'n' must be greater than 0, found 0"),
+		},
+		"NotIntegralN": {
+			spec: d("_id", types.Null, "first", d("$firstN", d("input", "$v", "n", 1.5))),
+			err:  common.NewErrorMsg(common.ErrAccumulatorNNotIntegral, "Value for 'n' must be of integral type, but found 1.5"),
+		},
+		"MissingSortBy": {
+			spec: d("_id", types.Null, "top", d("$topN", d("n", int32(1), "output", "$v"))),
+			err:  common.NewErrorMsg(common.ErrAccumulatorMissingInput, "Missing value for 'sortBy'"),
+		},
+		"BadSortBy": {
+			spec: d("_id", types.Null, "top", d("$topN", d("n", int32(1), "output", "$v", "sortBy", d("v", int32(2))))),
+			err: common.NewErrorMsg(
+				common.ErrSortBadOrder,
+				"$sort key ordering must be 1 (for ascending) or -1 (for descending)",
+			),
+		},
+	} {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			stage, err := NewStage(d("$group", tc.spec))
+			if tc.err != nil {
+				assert.Equal(t, tc.err, err)
+				return
+			}
+			require.NoError(t, err)
+
+			actual, err := stage.Process(testutil.Ctx(t), docs)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, actual)
+		})
+	}
+}
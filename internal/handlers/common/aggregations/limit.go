@@ -0,0 +1,54 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aggregations
+
+import (
+	"context"
+
+	"github.com/FerretDB/FerretDB/internal/handlers/common"
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+)
+
+// limit represents $limit stage.
+type limit struct {
+	limit int64
+}
+
+// newLimit creates a new $limit stage.
+func newLimit(stage *types.Document) (Stage, error) {
+	l, err := common.GetWholeNumberParam(must.NotFail(stage.Get("$limit")))
+	if err != nil {
+		return nil, common.NewErrorMsg(common.ErrStageLimitInvalidArg, "the limit must be specified as a number")
+	}
+
+	if l <= 0 {
+		return nil, common.NewErrorMsg(common.ErrStageLimitNotPositive, "the limit must be positive")
+	}
+
+	return &limit{
+		limit: l,
+	}, nil
+}
+
+// Process implements Stage interface.
+func (l *limit) Process(ctx context.Context, in []*types.Document) ([]*types.Document, error) {
+	return common.LimitDocuments(in, l.limit)
+}
+
+// check interfaces
+var (
+	_ Stage = (*limit)(nil)
+)
@@ -0,0 +1,334 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aggregations
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/FerretDB/FerretDB/internal/handlers/common"
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+)
+
+// Values of $merge stage whenMatched and whenNotMatched fields.
+const (
+	MergeReplace      = "replace"
+	MergeKeepExisting = "keepExisting"
+	MergeMerge        = "merge"
+	MergeFail         = "fail"
+	MergeInsert       = "insert"
+	MergeDiscard      = "discard"
+
+	// MergePipeline is used as WhenMatched value when whenMatched is an update pipeline.
+	MergePipeline = "pipeline"
+)
+
+// mergePipelineStages contains names of stages allowed in $merge whenMatched pipeline.
+var mergePipelineStages = map[string]struct{}{
+	"$addFields":   {},
+	"$project":     {},
+	"$replaceRoot": {},
+	"$replaceWith": {},
+	"$set":         {},
+	"$unset":       {},
+}
+
+// Merge represents $merge stage.
+//
+// Unlike other stages, it does not implement Stage interface:
+// it writes documents to the target collection, and that is done by the handler.
+type Merge struct {
+	// DB and Collection of the target collection.
+	DB         string
+	Collection string
+
+	// WhenMatched is one of MergeReplace, MergeKeepExisting, MergeMerge, MergeFail, or MergePipeline.
+	WhenMatched string
+
+	// WhenNotMatched is one of MergeInsert, MergeDiscard, or MergeFail.
+	WhenNotMatched string
+
+	// pipeline is set for MergePipeline
+	pipeline []Stage
+}
+
+// newMerge creates a new $merge stage for the aggregation in the given database.
+func newMerge(stage *types.Document, db string) (*Merge, error) {
+	res := &Merge{
+		DB:             db,
+		WhenMatched:    MergeMerge,
+		WhenNotMatched: MergeInsert,
+	}
+
+	var spec *types.Document
+
+	switch v := must.NotFail(stage.Get("$merge")).(type) {
+	case string:
+		res.Collection = v
+		return res, nil
+
+	case *types.Document:
+		spec = v
+
+	default:
+		return nil, common.NewErrorMsg(
+			common.ErrStageMergeInvalidArg,
+			fmt.Sprintf("$merge only supports a string or object argument, not %s", common.AliasFromType(v)),
+		)
+	}
+
+	for _, k := range spec.Keys() {
+		switch k {
+		case "into", "on", "whenMatched", "whenNotMatched":
+			// handled below
+		case "let":
+			return nil, common.NewErrorMsg(common.ErrNotImplemented, "$merge 'let' is not implemented yet")
+		default:
+			return nil, common.NewErrorMsg(
+				common.ErrFailedToParseInput,
+				fmt.Sprintf("BSON field '$merge.%s' is an unknown field.", k),
+			)
+		}
+	}
+
+	into, err := spec.Get("into")
+	if err != nil {
+		return nil, common.NewErrorMsg(
+			common.ErrMissingField,
+			"BSON field '$merge.into' is missing but a required field",
+		)
+	}
+
+	switch into := into.(type) {
+	case string:
+		res.Collection = into
+
+	case *types.Document:
+		if res.Collection, err = common.GetRequiredParam[string](into, "coll"); err != nil {
+			return nil, err
+		}
+
+		if res.DB, err = common.GetOptionalParam(into, "db", res.DB); err != nil {
+			return nil, err
+		}
+
+	default:
+		return nil, common.NewErrorMsg(
+			common.ErrTypeMismatch,
+			fmt.Sprintf(
+				"BSON field '$merge.into' is the wrong type '%s', expected types '[string, object]'",
+				common.AliasFromType(into),
+			),
+		)
+	}
+
+	if on, _ := spec.Get("on"); on != nil && !isMergeOnID(on) {
+		// other fields require a unique index on them
+		return nil, common.NewErrorMsg(
+			common.ErrNotImplemented,
+			"$merge 'on' fields other than _id are not implemented yet",
+		)
+	}
+
+	whenMatched, _ := spec.Get("whenMatched")
+
+	switch whenMatched := whenMatched.(type) {
+	case string:
+		switch whenMatched {
+		case MergeReplace, MergeKeepExisting, MergeMerge, MergeFail:
+			res.WhenMatched = whenMatched
+		default:
+			return nil, common.NewErrorMsg(
+				common.ErrBadValue,
+				fmt.Sprintf("Enumeration value '%s' for field 'whenMatched' is not a valid value.", whenMatched),
+			)
+		}
+
+	case *types.Array:
+		res.WhenMatched = MergePipeline
+
+		if res.pipeline, err = newMergePipeline(whenMatched); err != nil {
+			return nil, err
+		}
+
+	case nil:
+		// default
+
+	default:
+		return nil, common.NewErrorMsg(
+			common.ErrTypeMismatch,
+			fmt.Sprintf(
+				"BSON field '$merge.whenMatched' is the wrong type '%s', expected types '[string, array]'",
+				common.AliasFromType(whenMatched),
+			),
+		)
+	}
+
+	whenNotMatched := res.WhenNotMatched
+	if whenNotMatched, err = common.GetOptionalParam(spec, "whenNotMatched", whenNotMatched); err != nil {
+		return nil, err
+	}
+
+	switch whenNotMatched {
+	case MergeInsert, MergeDiscard, MergeFail:
+		res.WhenNotMatched = whenNotMatched
+	default:
+		return nil, common.NewErrorMsg(
+			common.ErrBadValue,
+			fmt.Sprintf("Enumeration value '%s' for field 'whenNotMatched' is not a valid value.", whenNotMatched),
+		)
+	}
+
+	return res, nil
+}
+
+// isMergeOnID returns true if the given value of $merge on field specifies only _id field.
+func isMergeOnID(on any) bool {
+	switch on := on.(type) {
+	case string:
+		return on == "_id"
+	case *types.Array:
+		return on.Len() == 1 && must.NotFail(on.Get(0)) == "_id"
+	default:
+		return false
+	}
+}
+
+// newMergePipeline creates stages of $merge whenMatched pipeline.
+func newMergePipeline(pipeline *types.Array) ([]Stage, error) {
+	res := make([]Stage, 0, pipeline.Len())
+
+	for i := 0; i < pipeline.Len(); i++ {
+		stage, ok := must.NotFail(pipeline.Get(i)).(*types.Document)
+		if !ok {
+			return nil, common.NewErrorMsg(
+				common.ErrTypeMismatch,
+				"Each element of the 'pipeline' array must be an object",
+			)
+		}
+
+		if _, ok = mergePipelineStages[stage.Command()]; !ok {
+			return nil, common.NewErrorMsg(
+				common.ErrInvalidOptions,
+				fmt.Sprintf("%s is not allowed to be used within an update", stage.Command()),
+			)
+		}
+
+		s, err := NewStage(stage)
+		if err != nil {
+			return nil, err
+		}
+
+		res = append(res, s)
+	}
+
+	return res, nil
+}
+
+// Filter returns the filter that selects the document of the target collection matching the given document.
+//
+// If the given document does not have _id, a new one is generated and set.
+func (m *Merge) Filter(doc *types.Document) (*types.Document, error) {
+	id, err := doc.Get("_id")
+	if err != nil {
+		id = types.NewObjectID()
+		must.NoError(doc.Set("_id", id))
+	}
+
+	if id == types.Null {
+		return nil, common.NewErrorMsg(
+			common.ErrStageMergeOnFieldInvalid,
+			"$merge write error: 'on' field cannot be missing, null, undefined or an array",
+		)
+	}
+
+	return must.NotFail(types.NewDocument("_id", id)), nil
+}
+
+// Matched returns the document that should replace the existing document matching the given one,
+// or nil if the existing document should be kept.
+//
+// It should not be called for MergeFail; the handler should insert the document instead,
+// and report the duplicate key error.
+func (m *Merge) Matched(ctx context.Context, existing, doc *types.Document) (*types.Document, error) {
+	id := must.NotFail(existing.Get("_id"))
+
+	switch m.WhenMatched {
+	case MergeKeepExisting:
+		return nil, nil
+
+	case MergeReplace:
+		res := doc.DeepCopy()
+		must.NoError(res.Set("_id", id))
+
+		return res, nil
+
+	case MergeMerge:
+		res := existing.DeepCopy()
+		for _, k := range doc.Keys() {
+			must.NoError(res.Set(k, must.NotFail(doc.Get(k))))
+		}
+
+		return res, nil
+
+	case MergePipeline:
+		// the pipeline is applied to the existing document, and the given one is available as $$new
+		out, err := Process(withVariables(ctx, variables{"new": doc}), m.pipeline, []*types.Document{existing.DeepCopy()})
+		if err != nil {
+			return nil, err
+		}
+
+		if len(out) != 1 {
+			return nil, lazyerrors.Errorf("expected 1 document, got %d", len(out))
+		}
+
+		if err = common.CheckImmutableID(id, out[0]); err != nil {
+			return nil, common.NewErrorMsg(
+				common.ErrImmutableField,
+				"$merge failed to update the matching document, did you attempt to modify the _id or the shard key?"+
+					" :: caused by :: Performing an update on the path '_id' would modify the immutable field '_id'",
+			)
+		}
+
+		return out[0], nil
+
+	default:
+		panic(fmt.Sprintf("unexpected whenMatched %q", m.WhenMatched))
+	}
+}
+
+// NotMatched returns the document that should be inserted into the target collection
+// if there is no document matching the given one, or nil if the given document should be discarded.
+func (m *Merge) NotMatched(doc *types.Document) (*types.Document, error) {
+	switch m.WhenNotMatched {
+	case MergeInsert:
+		return doc, nil
+
+	case MergeDiscard:
+		return nil, nil
+
+	case MergeFail:
+		return nil, common.NewErrorMsg(
+			common.ErrMergeStageNoMatchingDocument,
+			"$merge could not find a matching document in the target collection "+
+				"for at least one document in the source collection",
+		)
+
+	default:
+		panic(fmt.Sprintf("unexpected whenNotMatched %q", m.WhenNotMatched))
+	}
+}
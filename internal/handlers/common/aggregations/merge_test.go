@@ -0,0 +1,230 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aggregations
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/FerretDB/FerretDB/internal/handlers/common"
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+)
+
+func TestNewMerge(t *testing.T) {
+	t.Parallel()
+
+	// d and a are shortcuts for creating documents and arrays
+	d := func(pairs ...any) *types.Document { return must.NotFail(types.NewDocument(pairs...)) }
+	a := func(values ...any) *types.Array { return must.NotFail(types.NewArray(values...)) }
+
+	for name, tc := range map[string]struct {
+		spec     any
+		expected *Merge
+		code     common.ErrorCode
+	}{
+		"String": {
+			spec:     "out",
+			expected: &Merge{DB: "test", Collection: "out", WhenMatched: MergeMerge, WhenNotMatched: MergeInsert},
+		},
+		"IntoString": {
+			spec:     d("into", "out", "whenMatched", MergeKeepExisting, "whenNotMatched", MergeDiscard),
+			expected: &Merge{DB: "test", Collection: "out", WhenMatched: MergeKeepExisting, WhenNotMatched: MergeDiscard},
+		},
+		"IntoDocument": {
+			spec:     d("into", d("db", "other", "coll", "out"), "on", a("_id")),
+			expected: &Merge{DB: "other", Collection: "out", WhenMatched: MergeMerge, WhenNotMatched: MergeInsert},
+		},
+		"InvalidArg": {
+			spec: int32(1),
+			code: common.ErrStageMergeInvalidArg,
+		},
+		"IntoMissing": {
+			spec: d("whenMatched", MergeReplace),
+			code: common.ErrMissingField,
+		},
+		"IntoWrongType": {
+			spec: d("into", int32(1)),
+			code: common.ErrTypeMismatch,
+		},
+		"UnknownField": {
+			spec: d("into", "out", "foo", int32(1)),
+			code: common.ErrFailedToParseInput,
+		},
+		"Let": {
+			spec: d("into", "out", "let", d("v", int32(1))),
+			code: common.ErrNotImplemented,
+		},
+		"OnNotID": {
+			spec: d("into", "out", "on", "v"),
+			code: common.ErrNotImplemented,
+		},
+		"WhenMatchedInvalid": {
+			spec: d("into", "out", "whenMatched", "foo"),
+			code: common.ErrBadValue,
+		},
+		"WhenMatchedWrongType": {
+			spec: d("into", "out", "whenMatched", int32(1)),
+			code: common.ErrTypeMismatch,
+		},
+		"WhenMatchedPipelineStageNotAllowed": {
+			spec: d("into", "out", "whenMatched", a(d("$match", d()))),
+			code: common.ErrInvalidOptions,
+		},
+		"WhenNotMatchedInvalid": {
+			spec: d("into", "out", "whenNotMatched", MergeKeepExisting),
+			code: common.ErrBadValue,
+		},
+	} {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			actual, err := newMerge(d("$merge", tc.spec), "test")
+			if tc.code != 0 {
+				require.Error(t, err)
+
+				protoErr, ok := common.ProtocolError(err)
+				require.True(t, ok)
+				assert.Equal(t, tc.code, protoErr.Code(), err.Error())
+
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, actual)
+		})
+	}
+}
+
+func TestMergeMatched(t *testing.T) {
+	t.Parallel()
+
+	// d and a are shortcuts for creating documents and arrays
+	d := func(pairs ...any) *types.Document { return must.NotFail(types.NewDocument(pairs...)) }
+	a := func(values ...any) *types.Array { return must.NotFail(types.NewArray(values...)) }
+
+	for name, tc := range map[string]struct {
+		whenMatched any
+		expected    *types.Document
+		code        common.ErrorCode
+	}{
+		"Replace": {
+			whenMatched: MergeReplace,
+			expected:    d("_id", int32(1), "v", int32(2), "new", true),
+		},
+		"KeepExisting": {
+			whenMatched: MergeKeepExisting,
+		},
+		"Merge": {
+			whenMatched: MergeMerge,
+			expected:    d("_id", int32(1), "v", int32(2), "old", true, "new", true),
+		},
+		"Pipeline": {
+			whenMatched: a(d("$set", d("prev", "$v", "v", "$$new.v")), d("$project", d("old", false))),
+			expected:    d("_id", int32(1), "v", int32(2), "prev", int32(1)),
+		},
+		"PipelineNewDocument": {
+			whenMatched: a(d("$set", d("doc", "$$new"))),
+			expected: d(
+				"_id", int32(1), "v", int32(1), "old", true,
+				"doc", d("_id", int32(1), "v", int32(2), "new", true),
+			),
+		},
+		"PipelineImmutableID": {
+			whenMatched: a(d("$set", d("_id", int32(2)))),
+			code:        common.ErrImmutableField,
+		},
+	} {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			m, err := newMerge(d("$merge", d("into", "out", "whenMatched", tc.whenMatched)), "test")
+			require.NoError(t, err)
+
+			existing := d("_id", int32(1), "v", int32(1), "old", true)
+			doc := d("_id", int32(1), "v", int32(2), "new", true)
+
+			actual, err := m.Matched(testCtx(t), existing, doc)
+			if tc.code != 0 {
+				require.Error(t, err)
+
+				protoErr, ok := common.ProtocolError(err)
+				require.True(t, ok)
+				assert.Equal(t, tc.code, protoErr.Code(), err.Error())
+
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, actual)
+
+			// documents should not be modified
+			assert.Equal(t, d("_id", int32(1), "v", int32(1), "old", true), existing)
+			assert.Equal(t, d("_id", int32(1), "v", int32(2), "new", true), doc)
+		})
+	}
+}
+
+func TestMergeNotMatched(t *testing.T) {
+	t.Parallel()
+
+	doc := must.NotFail(types.NewDocument("_id", int32(1)))
+
+	m := &Merge{WhenNotMatched: MergeInsert}
+	res, err := m.NotMatched(doc)
+	require.NoError(t, err)
+	assert.Equal(t, doc, res)
+
+	m = &Merge{WhenNotMatched: MergeDiscard}
+	res, err = m.NotMatched(doc)
+	require.NoError(t, err)
+	assert.Nil(t, res)
+
+	m = &Merge{WhenNotMatched: MergeFail}
+	_, err = m.NotMatched(doc)
+	expected := common.NewErrorMsg(
+		common.ErrMergeStageNoMatchingDocument,
+		"$merge could not find a matching document in the target collection "+
+			"for at least one document in the source collection",
+	)
+	assert.Equal(t, expected, err)
+}
+
+func TestMergeFilter(t *testing.T) {
+	t.Parallel()
+
+	var m Merge
+
+	filter, err := m.Filter(must.NotFail(types.NewDocument("_id", int32(1), "v", "foo")))
+	require.NoError(t, err)
+	assert.Equal(t, must.NotFail(types.NewDocument("_id", int32(1))), filter)
+
+	doc := must.NotFail(types.NewDocument("v", "foo"))
+	filter, err = m.Filter(doc)
+	require.NoError(t, err)
+	assert.IsType(t, types.ObjectID{}, must.NotFail(filter.Get("_id")))
+	assert.Equal(t, must.NotFail(filter.Get("_id")), must.NotFail(doc.Get("_id")))
+
+	_, err = m.Filter(must.NotFail(types.NewDocument("_id", types.Null)))
+	expected := common.NewErrorMsg(
+		common.ErrStageMergeOnFieldInvalid,
+		"$merge write error: 'on' field cannot be missing, null, undefined or an array",
+	)
+	assert.Equal(t, expected, err)
+}
@@ -0,0 +1,177 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aggregations
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/FerretDB/FerretDB/internal/clientconn/conninfo"
+	"github.com/FerretDB/FerretDB/internal/handlers/common"
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+)
+
+// Params represents aggregate command parameters.
+type Params struct {
+	DB string
+
+	// Collection is empty for the database-level aggregation ({aggregate: 1}),
+	// which starts with $documents stage and does not read any collection.
+	Collection string
+
+	Stages []Stage
+
+	// ChangeStream is set if the pipeline starts with $changeStream stage;
+	// Stages then contain the rest of the pipeline that is applied to change events.
+	ChangeStream *ChangeStream
+
+	// Merge is set if the pipeline ends with $merge stage;
+	// Stages then contain the rest of the pipeline that produces documents to merge.
+	Merge *Merge
+
+	// BatchSize is the maximum number of documents in the first batch of the reply cursor;
+	// negative value means that it was not set, and the handler's default should be used.
+	BatchSize int32
+}
+
+// Namespace returns the namespace used in the aggregate command reply.
+func (p *Params) Namespace() string {
+	if p.Collection == "" {
+		return p.DB + ".$cmd.aggregate"
+	}
+
+	return p.DB + "." + p.Collection
+}
+
+// ParseParams parses aggregate command parameters and pipeline stages.
+// It also updates aggregation stages metrics.
+func ParseParams(ctx context.Context, document *types.Document) (*Params, error) {
+	var params Params
+	var err error
+
+	if params.DB, err = common.GetRequiredParam[string](document, "$db"); err != nil {
+		return nil, err
+	}
+
+	switch collection := must.NotFail(document.Get(document.Command())).(type) {
+	case string:
+		params.Collection = collection
+	default:
+		if n, e := common.GetWholeNumberParam(collection); e != nil || n != 1 {
+			return nil, common.NewErrorMsg(
+				common.ErrFailedToParse,
+				"Invalid command format: the 'aggregate' field must specify a collection name or 1",
+			)
+		}
+	}
+
+	params.BatchSize = -1
+
+	if v, _ := document.Get("cursor"); v != nil {
+		cursor, ok := v.(*types.Document)
+		if !ok {
+			return nil, common.NewErrorMsg(
+				common.ErrTypeMismatch,
+				fmt.Sprintf(
+					"BSON field 'aggregate.cursor' is the wrong type '%s', expected type 'object'",
+					common.AliasFromType(v),
+				),
+			)
+		}
+
+		if cursor.Has("batchSize") {
+			if params.BatchSize, err = common.GetOptionalPositiveNumber(cursor, "batchSize"); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	pipeline, err := common.GetRequiredParam[*types.Array](document, "pipeline")
+	if err != nil {
+		return nil, err
+	}
+
+	m := conninfo.GetConnInfo(ctx).AggregationStages
+
+	params.Stages = make([]Stage, 0, pipeline.Len())
+	for i := 0; i < pipeline.Len(); i++ {
+		stage, ok := must.NotFail(pipeline.Get(i)).(*types.Document)
+		if !ok {
+			return nil, common.NewErrorMsg(
+				common.ErrTypeMismatch,
+				"Each element of the 'pipeline' array must be an object",
+			)
+		}
+
+		m.WithLabelValues(document.Command(), stage.Command()).Inc()
+
+		name := stage.Command()
+
+		switch {
+		case i == 0 && params.Collection == "" && name != "$documents":
+			msg := fmt.Sprintf("{aggregate: 1} is not valid for '%s'; a collection is required.", name)
+			return nil, common.NewErrorMsg(common.ErrInvalidNamespace, msg)
+		case i == 0 && params.Collection != "" && name == "$documents":
+			return nil, common.NewErrorMsg(
+				common.ErrInvalidNamespace,
+				"$documents can only be run with {aggregate: 1}",
+			)
+		case i != 0 && name == "$documents":
+			return nil, common.NewErrorMsg(
+				common.ErrStageNotFirst,
+				"$documents is only valid as the first stage in a pipeline",
+			)
+		case i != 0 && name == "$changeStream":
+			return nil, common.NewErrorMsg(
+				common.ErrStageNotFirst,
+				"$changeStream is only valid as the first stage in a pipeline",
+			)
+		case i != pipeline.Len()-1 && name == "$merge":
+			return nil, common.NewErrorMsg(
+				common.ErrStageMergeNotLast,
+				"$merge can only be the final stage in the pipeline",
+			)
+		case name == "$changeStream":
+			if params.ChangeStream, err = newChangeStream(stage); err != nil {
+				return nil, err
+			}
+
+			continue
+		case name == "$merge":
+			if params.Merge, err = newMerge(stage, params.DB); err != nil {
+				return nil, err
+			}
+
+			continue
+		}
+
+		s, err := NewStage(stage)
+		if err != nil {
+			return nil, err
+		}
+
+		params.Stages = append(params.Stages, s)
+	}
+
+	if params.Collection == "" && pipeline.Len() == 0 {
+		return nil, common.NewErrorMsg(
+			common.ErrInvalidNamespace,
+			"{aggregate: 1} is not valid for an empty pipeline.",
+		)
+	}
+
+	return &params, nil
+}
@@ -0,0 +1,331 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aggregations
+
+import (
+	"context"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/FerretDB/FerretDB/internal/clientconn/conninfo"
+	"github.com/FerretDB/FerretDB/internal/handlers/common"
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+	"github.com/FerretDB/FerretDB/internal/util/testutil"
+)
+
+// testCtx returns a context with connection info needed for aggregation stages metrics.
+func testCtx(t *testing.T) context.Context {
+	t.Helper()
+
+	return conninfo.WithConnInfo(testutil.Ctx(t), &conninfo.ConnInfo{
+		AggregationStages: prometheus.NewCounterVec(prometheus.CounterOpts{Name: "test"}, []string{"command", "stage"}),
+	})
+}
+
+func TestParseParamsDocuments(t *testing.T) {
+	t.Parallel()
+
+	ctx := testCtx(t)
+
+	document := must.NotFail(types.NewDocument(
+		"aggregate", int32(1),
+		"pipeline", must.NotFail(types.NewArray(
+			must.NotFail(types.NewDocument("$documents", must.NotFail(types.NewArray(
+				must.NotFail(types.NewDocument("_id", int32(1), "v", "foo")),
+				must.NotFail(types.NewDocument("_id", int32(2), "v", "bar")),
+				must.NotFail(types.NewDocument("_id", int32(3), "v", "foo")),
+			)))),
+			must.NotFail(types.NewDocument("$match", must.NotFail(types.NewDocument("v", "foo")))),
+		)),
+		"$db", "test",
+	))
+
+	params, err := ParseParams(ctx, document)
+	require.NoError(t, err)
+	assert.Equal(t, "test", params.DB)
+	assert.Empty(t, params.Collection)
+	assert.Equal(t, "test.$cmd.aggregate", params.Namespace())
+
+	res, err := Process(ctx, params.Stages, nil)
+	require.NoError(t, err)
+
+	expected := []*types.Document{
+		must.NotFail(types.NewDocument("_id", int32(1), "v", "foo")),
+		must.NotFail(types.NewDocument("_id", int32(3), "v", "foo")),
+	}
+	assert.Equal(t, expected, res)
+}
+
+func TestParseParamsErrors(t *testing.T) {
+	t.Parallel()
+
+	documents := must.NotFail(types.NewDocument("$documents", must.NotFail(types.NewArray())))
+	match := must.NotFail(types.NewDocument("$match", must.NotFail(types.NewDocument())))
+
+	for name, tc := range map[string]struct {
+		collection any
+		pipeline   *types.Array
+		code       common.ErrorCode
+	}{
+		"InvalidCollection": {
+			collection: int32(2),
+			pipeline:   must.NotFail(types.NewArray(documents)),
+			code:       common.ErrFailedToParse,
+		},
+		"NoCollection": {
+			collection: int32(1),
+			pipeline:   must.NotFail(types.NewArray(match)),
+			code:       common.ErrInvalidNamespace,
+		},
+		"EmptyPipelineNoCollection": {
+			collection: int32(1),
+			pipeline:   must.NotFail(types.NewArray()),
+			code:       common.ErrInvalidNamespace,
+		},
+		"DocumentsWithCollection": {
+			collection: "values",
+			pipeline:   must.NotFail(types.NewArray(documents)),
+			code:       common.ErrInvalidNamespace,
+		},
+		"DocumentsNotFirst": {
+			collection: int32(1),
+			pipeline:   must.NotFail(types.NewArray(documents, documents)),
+			code:       common.ErrStageNotFirst,
+		},
+		"DocumentsNotArray": {
+			collection: int32(1),
+			pipeline:   must.NotFail(types.NewArray(must.NotFail(types.NewDocument("$documents", "foo")))),
+			code:       common.ErrTypeMismatch,
+		},
+		"StageNotDocument": {
+			collection: "values",
+			pipeline:   must.NotFail(types.NewArray("foo")),
+			code:       common.ErrTypeMismatch,
+		},
+		"StageTwoFields": {
+			collection: "values",
+			pipeline:   must.NotFail(types.NewArray(must.NotFail(types.NewDocument("$match", must.NotFail(types.NewDocument()), "$sort", must.NotFail(types.NewDocument()))))),
+			code:       common.ErrStageInvalid,
+		},
+		"MatchNotDocument": {
+			collection: "values",
+			pipeline:   must.NotFail(types.NewArray(must.NotFail(types.NewDocument("$match", "foo")))),
+			code:       common.ErrMatchBadExpression,
+		},
+		"LimitNotNumber": {
+			collection: "values",
+			pipeline:   must.NotFail(types.NewArray(must.NotFail(types.NewDocument("$limit", "foo")))),
+			code:       common.ErrStageLimitInvalidArg,
+		},
+		"LimitZero": {
+			collection: "values",
+			pipeline:   must.NotFail(types.NewArray(must.NotFail(types.NewDocument("$limit", int32(0))))),
+			code:       common.ErrStageLimitNotPositive,
+		},
+		"SkipNotNumber": {
+			collection: "values",
+			pipeline:   must.NotFail(types.NewArray(must.NotFail(types.NewDocument("$skip", "foo")))),
+			code:       common.ErrStageSkipInvalidArg,
+		},
+		"SkipNegative": {
+			collection: "values",
+			pipeline:   must.NotFail(types.NewArray(must.NotFail(types.NewDocument("$skip", int64(-1))))),
+			code:       common.ErrStageSkipNegative,
+		},
+		"ChangeStreamNotFirst": {
+			collection: "values",
+			pipeline:   must.NotFail(types.NewArray(match, must.NotFail(types.NewDocument("$changeStream", must.NotFail(types.NewDocument()))))),
+			code:       common.ErrStageNotFirst,
+		},
+		"ChangeStreamNotDocument": {
+			collection: "values",
+			pipeline:   must.NotFail(types.NewArray(must.NotFail(types.NewDocument("$changeStream", "foo")))),
+			code:       common.ErrTypeMismatch,
+		},
+		"ChangeStreamBothResumeOptions": {
+			collection: "values",
+			pipeline: must.NotFail(types.NewArray(must.NotFail(types.NewDocument("$changeStream", must.NotFail(types.NewDocument(
+				"resumeAfter", must.NotFail(types.NewDocument("_data", "foo")),
+				"startAfter", must.NotFail(types.NewDocument("_data", "foo")),
+			)))))),
+			code: common.ErrBadValue,
+		},
+		"ChangeStreamUnimplementedOption": {
+			collection: "values",
+			pipeline: must.NotFail(types.NewArray(must.NotFail(types.NewDocument("$changeStream", must.NotFail(types.NewDocument(
+				"startAtOperationTime", types.Timestamp(1),
+			)))))),
+			code: common.ErrNotImplemented,
+		},
+		"MergeNotLast": {
+			collection: "values",
+			pipeline:   must.NotFail(types.NewArray(must.NotFail(types.NewDocument("$merge", "out")), match)),
+			code:       common.ErrStageMergeNotLast,
+		},
+		"MergeInvalidArg": {
+			collection: "values",
+			pipeline:   must.NotFail(types.NewArray(must.NotFail(types.NewDocument("$merge", int32(1))))),
+			code:       common.ErrStageMergeInvalidArg,
+		},
+		"UnknownStage": {
+			collection: "values",
+			pipeline:   must.NotFail(types.NewArray(must.NotFail(types.NewDocument("$foo", int32(1))))),
+			code:       common.ErrNotImplemented,
+		},
+	} {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			document := must.NotFail(types.NewDocument(
+				"aggregate", tc.collection,
+				"pipeline", tc.pipeline,
+				"$db", "test",
+			))
+
+			_, err := ParseParams(testCtx(t), document)
+			require.Error(t, err)
+
+			protoErr, ok := common.ProtocolError(err)
+			require.True(t, ok)
+			assert.Equal(t, tc.code, protoErr.Code(), err.Error())
+		})
+	}
+}
+
+func TestParseParamsSkipLimit(t *testing.T) {
+	t.Parallel()
+
+	ctx := testCtx(t)
+
+	var docs []*types.Document
+	for i := int32(1); i <= 5; i++ {
+		docs = append(docs, must.NotFail(types.NewDocument("_id", i)))
+	}
+
+	document := must.NotFail(types.NewDocument(
+		"aggregate", int32(1),
+		"pipeline", must.NotFail(types.NewArray(
+			must.NotFail(types.NewDocument("$documents", must.NotFail(types.NewArray(
+				docs[0], docs[1], docs[2], docs[3], docs[4],
+			)))),
+			must.NotFail(types.NewDocument("$skip", int64(1))),
+			must.NotFail(types.NewDocument("$limit", float64(3))),
+			must.NotFail(types.NewDocument("$skip", int32(1))),
+		)),
+		"$db", "test",
+	))
+
+	params, err := ParseParams(ctx, document)
+	require.NoError(t, err)
+
+	res, err := Process(ctx, params.Stages, nil)
+	require.NoError(t, err)
+	assert.Equal(t, []*types.Document{docs[2], docs[3]}, res)
+}
+
+func TestParseParamsChangeStream(t *testing.T) {
+	t.Parallel()
+
+	ctx := testCtx(t)
+
+	document := must.NotFail(types.NewDocument(
+		"aggregate", "values",
+		"pipeline", must.NotFail(types.NewArray(
+			must.NotFail(types.NewDocument("$changeStream", must.NotFail(types.NewDocument(
+				"resumeAfter", must.NotFail(types.NewDocument("_data", "token")),
+			)))),
+			must.NotFail(types.NewDocument("$match", must.NotFail(types.NewDocument("operationType", "insert")))),
+		)),
+		"$db", "test",
+	))
+
+	params, err := ParseParams(ctx, document)
+	require.NoError(t, err)
+	assert.Equal(t, &ChangeStream{ResumeToken: "token"}, params.ChangeStream)
+	assert.Len(t, params.Stages, 1)
+
+	// pipelines without $changeStream do not set it
+	must.NoError(document.Set("pipeline", must.NotFail(types.NewArray())))
+
+	params, err = ParseParams(ctx, document)
+	require.NoError(t, err)
+	assert.Nil(t, params.ChangeStream)
+}
+
+func TestParseParamsBatchSize(t *testing.T) {
+	t.Parallel()
+
+	ctx := testCtx(t)
+
+	for name, tc := range map[string]struct { //nolint:paralleltest // false positive
+		cursor    any
+		batchSize int32
+		code      common.ErrorCode
+	}{
+		"Default": {
+			batchSize: -1,
+		},
+		"EmptyCursor": {
+			cursor:    must.NotFail(types.NewDocument()),
+			batchSize: -1,
+		},
+		"BatchSize": {
+			cursor:    must.NotFail(types.NewDocument("batchSize", int32(2))),
+			batchSize: 2,
+		},
+		"Zero": {
+			cursor:    must.NotFail(types.NewDocument("batchSize", int64(0))),
+			batchSize: 0,
+		},
+		"Negative": {
+			cursor: must.NotFail(types.NewDocument("batchSize", int32(-1))),
+			code:   common.ErrBadValue,
+		},
+		"InvalidCursor": {
+			cursor: int32(1),
+			code:   common.ErrTypeMismatch,
+		},
+	} {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			document := must.NotFail(types.NewDocument(
+				"aggregate", "values",
+				"pipeline", must.NotFail(types.NewArray()),
+				"$db", "test",
+			))
+			if tc.cursor != nil {
+				must.NoError(document.Set("cursor", tc.cursor))
+			}
+
+			params, err := ParseParams(ctx, document)
+			if tc.code != 0 {
+				var protoErr common.ProtoErr
+				require.ErrorAs(t, err, &protoErr)
+				assert.Equal(t, tc.code, protoErr.Code())
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tc.batchSize, params.BatchSize)
+		})
+	}
+}
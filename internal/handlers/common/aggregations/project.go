@@ -0,0 +1,305 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aggregations
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/FerretDB/FerretDB/internal/handlers/common"
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+)
+
+// projectionField represents a single field of $project stage specification.
+type projectionField struct {
+	path string // dotted path, nested specifications are flattened
+	expr any
+}
+
+// project represents $project stage.
+type project struct {
+	// inclusion is true if only the given fields are returned, and false if the given fields are removed.
+	inclusion bool
+
+	// excludeID is true if _id field is explicitly excluded.
+	excludeID bool
+
+	// paths contains included or excluded field paths other than _id.
+	paths []string
+
+	// computed contains computed fields of inclusion projection in the specification order.
+	computed []projectionField
+}
+
+// newProject creates a new $project stage.
+func newProject(stage *types.Document) (Stage, error) {
+	spec, ok := must.NotFail(stage.Get("$project")).(*types.Document)
+	if !ok {
+		return nil, common.NewErrorMsg(common.ErrStageProjectBadExpression, "$project specification must be an object")
+	}
+
+	if spec.Len() == 0 {
+		return nil, common.NewErrorMsg(
+			common.ErrProjectionEmpty,
+			"Invalid $project :: caused by :: projection specification must have at least one field",
+		)
+	}
+
+	fields, err := flattenProjection("", spec)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = checkProjectionPaths(fields); err != nil {
+		return nil, err
+	}
+
+	var p project
+	var inclusion, exclusion bool
+
+	for _, f := range fields {
+		include, isFlag := projectionFlag(f.expr)
+
+		switch {
+		case isFlag && f.path == "_id":
+			// _id could be excluded from inclusion projection and included into exclusion projection
+			p.excludeID = !include
+			continue
+
+		case !isFlag:
+			if exclusion {
+				return nil, common.NewErrorMsg(
+					common.ErrProjectionExpressionInEx,
+					"Invalid $project :: caused by :: Cannot use expression other than $meta in exclusion projection",
+				)
+			}
+
+			inclusion = true
+			p.computed = append(p.computed, f)
+
+			continue
+
+		case include:
+			if exclusion {
+				return nil, common.NewErrorMsg(
+					common.ErrProjectionInEx,
+					fmt.Sprintf("Invalid $project :: caused by :: Cannot do inclusion on field %s in exclusion projection", f.path),
+				)
+			}
+
+			inclusion = true
+
+		default:
+			if inclusion {
+				return nil, common.NewErrorMsg(
+					common.ErrProjectionExIn,
+					fmt.Sprintf("Invalid $project :: caused by :: Cannot do exclusion on field %s in inclusion projection", f.path),
+				)
+			}
+
+			exclusion = true
+		}
+
+		p.paths = append(p.paths, f.path)
+	}
+
+	// {_id: 1} alone is an inclusion projection, {_id: 0} alone is an exclusion projection
+	p.inclusion = inclusion || (!exclusion && !p.excludeID)
+
+	return &p, nil
+}
+
+// flattenProjection returns fields of the given $project specification
+// with nested specifications like {a: {b: 1}} converted to dotted paths like {"a.b": 1}.
+func flattenProjection(prefix string, spec *types.Document) ([]projectionField, error) {
+	res := make([]projectionField, 0, spec.Len())
+
+	for _, k := range spec.Keys() {
+		path := k
+		if prefix != "" {
+			path = prefix + "." + k
+		}
+
+		v := must.NotFail(spec.Get(k))
+
+		nested, ok := v.(*types.Document)
+		if !ok || (nested.Len() > 0 && strings.HasPrefix(nested.Keys()[0], "$")) {
+			// not a nested specification, but a flag or an expression
+			res = append(res, projectionField{path: path, expr: v})
+			continue
+		}
+
+		if nested.Len() == 0 {
+			return nil, common.NewErrorMsg(
+				common.ErrProjectionEmptySubProjection,
+				"Invalid $project :: caused by :: An empty sub-projection is not a valid value. Found empty object at path",
+			)
+		}
+
+		fields, err := flattenProjection(path, nested)
+		if err != nil {
+			return nil, err
+		}
+
+		res = append(res, fields...)
+	}
+
+	return res, nil
+}
+
+// checkProjectionPaths returns an error if one projection field path is a prefix of another one.
+func checkProjectionPaths(fields []projectionField) error {
+	for _, a := range fields {
+		for _, b := range fields {
+			if !strings.HasPrefix(b.path, a.path+".") {
+				continue
+			}
+
+			return common.NewErrorMsg(
+				common.ErrProjectionPathCollision,
+				fmt.Sprintf(
+					"Invalid $project :: caused by :: Path collision at %s remaining portion %s",
+					b.path, strings.TrimPrefix(b.path, a.path+"."),
+				),
+			)
+		}
+	}
+
+	return nil
+}
+
+// projectionFlag returns inclusion flag and true if the given projection value is a number or a boolean,
+// and false if it is an expression of a computed field.
+func projectionFlag(v any) (include bool, ok bool) {
+	switch v := v.(type) {
+	case bool:
+		return v, true
+	case float64:
+		return v != 0, true
+	case int32:
+		return v != 0, true
+	case int64:
+		return v != 0, true
+	default:
+		return false, false
+	}
+}
+
+// Process implements Stage interface.
+func (p *project) Process(ctx context.Context, in []*types.Document) ([]*types.Document, error) {
+	vars := getVariables(ctx)
+	res := make([]*types.Document, len(in))
+
+	for i, doc := range in {
+		if !p.inclusion {
+			res[i] = p.exclude(doc)
+			continue
+		}
+
+		out, err := p.include(doc, vars)
+		if err != nil {
+			return nil, err
+		}
+
+		res[i] = out
+	}
+
+	return res, nil
+}
+
+// include returns a new document with included and computed fields of the given document.
+func (p *project) include(doc *types.Document, vars variables) (*types.Document, error) {
+	src := doc.DeepCopy()
+	out := must.NotFail(types.NewDocument())
+
+	// _id is the first field unless it is excluded or only some of its fields are projected
+	includeID := !p.excludeID
+	for _, path := range p.paths {
+		if strings.HasPrefix(path, "_id.") {
+			includeID = false
+		}
+	}
+
+	if id, err := src.Get("_id"); err == nil && includeID {
+		must.NoError(out.Set("_id", id))
+	}
+
+	// top-level fields keep their order in the document
+	for _, k := range src.Keys() {
+		for _, path := range p.paths {
+			if path == k {
+				must.NoError(out.Set(k, must.NotFail(src.Get(k))))
+				break
+			}
+		}
+	}
+
+	for _, path := range p.paths {
+		if !strings.ContainsRune(path, '.') {
+			continue
+		}
+
+		v, err := src.GetByPath(types.NewPathFromString(path))
+		if err != nil {
+			// missing field
+			continue
+		}
+
+		if err = out.SetByPath(types.NewPathFromString(path), v); err != nil {
+			return nil, lazyerrors.Error(err)
+		}
+	}
+
+	for _, f := range p.computed {
+		// expressions are evaluated against the input document
+		v, err := evaluateExpression(doc, vars, f.expr)
+		if err != nil {
+			return nil, err
+		}
+
+		if v == nil {
+			continue
+		}
+
+		if err = out.SetByPath(types.NewPathFromString(f.path), v); err != nil {
+			return nil, lazyerrors.Error(err)
+		}
+	}
+
+	return out, nil
+}
+
+// exclude returns a copy of the given document without excluded fields.
+func (p *project) exclude(doc *types.Document) *types.Document {
+	out := doc.DeepCopy()
+
+	for _, path := range p.paths {
+		out.RemoveByPath(types.NewPathFromString(path))
+	}
+
+	if p.excludeID {
+		out.Remove("_id")
+	}
+
+	return out
+}
+
+// check interfaces
+var (
+	_ Stage = (*project)(nil)
+)
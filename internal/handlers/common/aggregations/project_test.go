@@ -0,0 +1,159 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aggregations
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/FerretDB/FerretDB/internal/handlers/common"
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+	"github.com/FerretDB/FerretDB/internal/util/testutil"
+)
+
+func TestProject(t *testing.T) {
+	t.Parallel()
+
+	// d is a shortcut for creating documents
+	d := func(pairs ...any) *types.Document { return must.NotFail(types.NewDocument(pairs...)) }
+
+	// a is a shortcut for creating arrays
+	a := func(values ...any) *types.Array { return must.NotFail(types.NewArray(values...)) }
+
+	doc := d("_id", int32(1), "a", "foo", "b", d("c", int32(2), "d", int32(3)), "e", a(int32(1), int32(2)))
+
+	for name, tc := range map[string]struct {
+		spec     any
+		expected *types.Document
+		err      error
+	}{
+		"Inclusion": {
+			spec:     d("e", int32(1), "a", true),
+			expected: d("_id", int32(1), "a", "foo", "e", a(int32(1), int32(2))),
+		},
+		"InclusionDotted": {
+			spec:     d("b.d", 1.0, "x.y", int32(1)),
+			expected: d("_id", int32(1), "b", d("d", int32(3))),
+		},
+		"InclusionNested": {
+			spec:     d("b", d("c", int64(1))),
+			expected: d("_id", int32(1), "b", d("c", int32(2))),
+		},
+		"InclusionWithoutID": {
+			spec:     d("a", int32(1), "_id", false),
+			expected: d("a", "foo"),
+		},
+		"IDOnly": {
+			spec:     d("_id", int32(1)),
+			expected: d("_id", int32(1)),
+		},
+		"Exclusion": {
+			spec:     d("a", int32(0), "b.c", false),
+			expected: d("_id", int32(1), "b", d("d", int32(3)), "e", a(int32(1), int32(2))),
+		},
+		"ExclusionID": {
+			spec:     d("_id", int32(0)),
+			expected: d("a", "foo", "b", d("c", int32(2), "d", int32(3)), "e", a(int32(1), int32(2))),
+		},
+		"Computed": {
+			spec: d(
+				"renamed", "$a",
+				"nested", "$b.c",
+				"size", d("$size", "$e"),
+				"constant", "bar",
+				"array", a("$a", "$missing"),
+				"missing", "$missing",
+			),
+			expected: d(
+				"_id", int32(1),
+				"renamed", "foo",
+				"nested", int32(2),
+				"size", int32(2),
+				"constant", "bar",
+				"array", a("foo", types.Null),
+			),
+		},
+		"ComputedID": {
+			spec:     d("_id", "$a", "a", int32(1)),
+			expected: d("_id", "foo", "a", "foo"),
+		},
+		"NotDocument": {
+			spec: "a",
+			err:  common.NewErrorMsg(common.ErrStageProjectBadExpression, "$project specification must be an object"),
+		},
+		"Empty": {
+			spec: d(),
+			err: common.NewErrorMsg(
+				common.ErrProjectionEmpty,
+				"Invalid $project :: caused by :: projection specification must have at least one field",
+			),
+		},
+		"EmptyNested": {
+			spec: d("a", d()),
+			err: common.NewErrorMsg(
+				common.ErrProjectionEmptySubProjection,
+				"Invalid $project :: caused by :: An empty sub-projection is not a valid value. Found empty object at path",
+			),
+		},
+		"InclusionInExclusion": {
+			spec: d("a", int32(0), "b", int32(1)),
+			err: common.NewErrorMsg(
+				common.ErrProjectionInEx,
+				"Invalid $project :: caused by :: Cannot do inclusion on field b in exclusion projection",
+			),
+		},
+		"ExclusionInInclusion": {
+			spec: d("a", int32(1), "b", int32(0)),
+			err: common.NewErrorMsg(
+				common.ErrProjectionExIn,
+				"Invalid $project :: caused by :: Cannot do exclusion on field b in inclusion projection",
+			),
+		},
+		"ExpressionInExclusion": {
+			spec: d("a", int32(0), "b", "$a"),
+			err: common.NewErrorMsg(
+				common.ErrProjectionExpressionInEx,
+				"Invalid $project :: caused by :: Cannot use expression other than $meta in exclusion projection",
+			),
+		},
+		"PathCollision": {
+			spec: d("b", int32(1), "b.c", int32(1)),
+			err: common.NewErrorMsg(
+				common.ErrProjectionPathCollision,
+				"Invalid $project :: caused by :: Path collision at b.c remaining portion c",
+			),
+		},
+	} {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			stage, err := NewStage(d("$project", tc.spec))
+			if tc.err != nil {
+				assert.Equal(t, tc.err, err)
+				return
+			}
+			require.NoError(t, err)
+
+			actual, err := stage.Process(testutil.Ctx(t), []*types.Document{doc})
+			require.NoError(t, err)
+			require.Len(t, actual, 1)
+			assert.Equal(t, tc.expected, actual[0])
+		})
+	}
+}
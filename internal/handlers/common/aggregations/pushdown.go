@@ -0,0 +1,92 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aggregations
+
+import (
+	"github.com/FerretDB/FerretDB/internal/types"
+)
+
+// Pushdown represents the leading part of the pipeline that could be executed by the backend.
+type Pushdown struct {
+	// Filter is the filter of the leading $match stage, or nil if there is none.
+	// The backend may use it to select fewer documents; Stages filter them again.
+	Filter *types.Document
+
+	// Skip and Limit combine $skip and $limit stages that directly follow the leading $match stage
+	// or start the pipeline; zero values mean no skip and no limit.
+	// They are set only if the backend selects exactly the documents matching the Filter.
+	Skip  int64
+	Limit int64
+
+	// Stages are stages that should be processed in memory on documents returned by the backend.
+	Stages []Stage
+}
+
+// NewPushdown splits the pipeline stages into the part that could be pushed down to the backend and the rest.
+//
+// The exact function should report whether the backend selects exactly the documents matching the given filter;
+// only in that case $skip and $limit stages following the leading $match stage are pushed down.
+// Stages after any other stage, for example blocking $group or $sort, are never pushed down.
+func NewPushdown(stages []Stage, exact func(filter *types.Document) bool) *Pushdown {
+	res := &Pushdown{
+		Stages: stages,
+	}
+
+	var leading []Stage
+
+	i := 0
+	if len(stages) > 0 {
+		if m, ok := stages[0].(*match); ok {
+			res.Filter = m.filter
+
+			if !exact(m.filter) {
+				return res
+			}
+
+			// filtering exactly matching documents again is harmless
+			leading = append(leading, m)
+			i++
+		}
+	}
+
+loop:
+	for ; i < len(stages); i++ {
+		switch s := stages[i].(type) {
+		case *skip:
+			if res.Limit > 0 {
+				// the result is empty; let the stage be processed in memory
+				if s.skip >= res.Limit {
+					break loop
+				}
+
+				res.Limit -= s.skip
+			}
+
+			res.Skip += s.skip
+
+		case *limit:
+			if res.Limit == 0 || s.limit < res.Limit {
+				res.Limit = s.limit
+			}
+
+		default:
+			break loop
+		}
+	}
+
+	res.Stages = append(leading, stages[i:]...)
+
+	return res
+}
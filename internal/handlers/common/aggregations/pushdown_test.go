@@ -0,0 +1,116 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aggregations
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+)
+
+func TestNewPushdown(t *testing.T) {
+	t.Parallel()
+
+	filter := must.NotFail(types.NewDocument("v", "foo"))
+
+	newStages := func(t *testing.T, stages ...*types.Document) []Stage {
+		t.Helper()
+
+		res := make([]Stage, len(stages))
+		for i, stage := range stages {
+			var err error
+			res[i], err = NewStage(stage)
+			require.NoError(t, err)
+		}
+
+		return res
+	}
+
+	matchStage := must.NotFail(types.NewDocument("$match", filter))
+	skipStage := func(n int32) *types.Document { return must.NotFail(types.NewDocument("$skip", n)) }
+	limitStage := func(n int32) *types.Document { return must.NotFail(types.NewDocument("$limit", n)) }
+
+	for name, tc := range map[string]struct {
+		stages []*types.Document
+		exact  bool
+		filter *types.Document
+		skip   int64
+		limit  int64
+		rest   int // number of stages processed in memory
+	}{
+		"Empty": {},
+		"MatchSkipLimit": {
+			stages: []*types.Document{matchStage, skipStage(1), limitStage(2)},
+			exact:  true,
+			filter: filter,
+			skip:   1,
+			limit:  2,
+			rest:   1,
+		},
+		"MatchNotExact": {
+			stages: []*types.Document{matchStage, skipStage(1), limitStage(2)},
+			filter: filter,
+			rest:   3,
+		},
+		"LimitSkip": {
+			stages: []*types.Document{limitStage(5), skipStage(2)},
+			skip:   2,
+			limit:  3,
+		},
+		"LimitSkipEmpty": {
+			stages: []*types.Document{limitStage(2), skipStage(2)},
+			limit:  2,
+			rest:   1,
+		},
+		"LimitLimit": {
+			stages: []*types.Document{limitStage(5), limitStage(3), limitStage(4)},
+			limit:  3,
+		},
+		"SkipSkip": {
+			stages: []*types.Document{skipStage(1), skipStage(2)},
+			skip:   3,
+		},
+		"SkipMatch": {
+			stages: []*types.Document{skipStage(1), matchStage},
+			exact:  true,
+			skip:   1,
+			rest:   1,
+		},
+		"MatchLimitMatchLimit": {
+			stages: []*types.Document{matchStage, limitStage(3), matchStage, limitStage(1)},
+			exact:  true,
+			filter: filter,
+			limit:  3,
+			rest:   3,
+		},
+	} {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			exact := func(*types.Document) bool { return tc.exact }
+			pushdown := NewPushdown(newStages(t, tc.stages...), exact)
+
+			assert.Equal(t, tc.filter, pushdown.Filter)
+			assert.Equal(t, tc.skip, pushdown.Skip)
+			assert.Equal(t, tc.limit, pushdown.Limit)
+			assert.Len(t, pushdown.Stages, tc.rest)
+		})
+	}
+}
@@ -0,0 +1,58 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aggregations
+
+import (
+	"context"
+
+	"github.com/FerretDB/FerretDB/internal/handlers/common"
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+)
+
+// skip represents $skip stage.
+type skip struct {
+	skip int64
+}
+
+// newSkip creates a new $skip stage.
+func newSkip(stage *types.Document) (Stage, error) {
+	s, err := common.GetWholeNumberParam(must.NotFail(stage.Get("$skip")))
+	if err != nil {
+		return nil, common.NewErrorMsg(common.ErrStageSkipInvalidArg, "Argument to $skip must be a number")
+	}
+
+	if s < 0 {
+		return nil, common.NewErrorMsg(common.ErrStageSkipNegative, "Argument to $skip cannot be negative")
+	}
+
+	return &skip{
+		skip: s,
+	}, nil
+}
+
+// Process implements Stage interface.
+func (s *skip) Process(ctx context.Context, in []*types.Document) ([]*types.Document, error) {
+	if s.skip >= int64(len(in)) {
+		return nil, nil
+	}
+
+	return in[s.skip:], nil
+}
+
+// check interfaces
+var (
+	_ Stage = (*skip)(nil)
+)
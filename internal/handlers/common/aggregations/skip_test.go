@@ -0,0 +1,89 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aggregations
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/FerretDB/FerretDB/internal/handlers/common"
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+	"github.com/FerretDB/FerretDB/internal/util/testutil"
+)
+
+func TestSkip(t *testing.T) {
+	t.Parallel()
+
+	// d is a shortcut for creating documents
+	d := func(pairs ...any) *types.Document { return must.NotFail(types.NewDocument(pairs...)) }
+
+	in := []*types.Document{d("_id", int32(1)), d("_id", int32(2)), d("_id", int32(3))}
+
+	for name, tc := range map[string]struct {
+		skip     any
+		expected []*types.Document
+		err      error
+	}{
+		"Zero": {
+			skip:     int32(0),
+			expected: in,
+		},
+		"Some": {
+			skip:     int64(2),
+			expected: []*types.Document{d("_id", int32(3))},
+		},
+		"All": {
+			skip: int32(3),
+		},
+		"MoreThanAll": {
+			skip: float64(10),
+		},
+		"WholeDouble": {
+			skip:     float64(1),
+			expected: []*types.Document{d("_id", int32(2)), d("_id", int32(3))},
+		},
+		"NotNumber": {
+			skip: "1",
+			err:  common.NewErrorMsg(common.ErrStageSkipInvalidArg, "Argument to $skip must be a number"),
+		},
+		"NotInteger": {
+			skip: 1.5,
+			err:  common.NewErrorMsg(common.ErrStageSkipInvalidArg, "Argument to $skip must be a number"),
+		},
+		"Negative": {
+			skip: int32(-1),
+			err:  common.NewErrorMsg(common.ErrStageSkipNegative, "Argument to $skip cannot be negative"),
+		},
+	} {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			stage, err := NewStage(d("$skip", tc.skip))
+			if tc.err != nil {
+				assert.Equal(t, tc.err, err)
+				return
+			}
+			require.NoError(t, err)
+
+			actual, err := stage.Process(testutil.Ctx(t), in)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, actual)
+		})
+	}
+}
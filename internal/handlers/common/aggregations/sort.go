@@ -0,0 +1,63 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aggregations
+
+import (
+	"context"
+
+	"github.com/FerretDB/FerretDB/internal/handlers/common"
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
+)
+
+// sort represents $sort stage.
+type sort struct {
+	fields *types.Document
+}
+
+// newSort creates a new $sort stage.
+func newSort(stage *types.Document) (Stage, error) {
+	fields, err := common.GetRequiredParam[*types.Document](stage, "$sort")
+	if err != nil {
+		return nil, common.NewErrorMsg(common.ErrSortBadExpression, "the $sort key specification must be an object")
+	}
+
+	if fields.Len() == 0 {
+		return nil, common.NewErrorMsg(common.ErrSortMissingKey, "$sort stage must have at least one sort key")
+	}
+
+	// sorting no documents validates sort keys and directions
+	if err = common.SortDocuments(nil, fields); err != nil {
+		return nil, err
+	}
+
+	return &sort{
+		fields: fields,
+	}, nil
+}
+
+// Process implements Stage interface.
+func (s *sort) Process(ctx context.Context, in []*types.Document) ([]*types.Document, error) {
+	if err := common.SortDocuments(in, s.fields); err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	return in, nil
+}
+
+// check interfaces
+var (
+	_ Stage = (*sort)(nil)
+)
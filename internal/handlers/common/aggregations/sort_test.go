@@ -0,0 +1,110 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aggregations
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/FerretDB/FerretDB/internal/handlers/common"
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+	"github.com/FerretDB/FerretDB/internal/util/testutil"
+)
+
+func TestSort(t *testing.T) {
+	t.Parallel()
+
+	// d is a shortcut for creating documents
+	d := func(pairs ...any) *types.Document { return must.NotFail(types.NewDocument(pairs...)) }
+
+	// docs returns new documents for each test case, as they are sorted in place
+	docs := func() []*types.Document {
+		return []*types.Document{
+			d("_id", "1", "k", "b", "v", d("w", int32(2))),
+			d("_id", "2", "k", "a", "v", d("w", int32(3))),
+			d("_id", "3", "v", d("w", 1.5)),
+			d("_id", "4", "k", "a", "v", d("w", int32(1))),
+			d("_id", "5", "k", "b", "v", int32(1)),
+			d("_id", "6", "k", types.Null, "v", d("w", int32(3))),
+		}
+	}
+
+	for name, tc := range map[string]struct {
+		spec     any
+		expected []any
+		err      error
+	}{
+		"Ascending": {
+			spec:     d("k", int32(1)),
+			expected: []any{"3", "6", "2", "4", "1", "5"},
+		},
+		"Descending": {
+			spec:     d("k", int64(-1)),
+			expected: []any{"1", "5", "2", "4", "3", "6"},
+		},
+		"Dotted": {
+			spec:     d("v.w", 1.0),
+			expected: []any{"5", "4", "3", "1", "2", "6"},
+		},
+		"MultipleKeys": {
+			spec:     d("k", int32(1), "v.w", int32(-1)),
+			expected: []any{"6", "3", "2", "4", "1", "5"},
+		},
+		"NotDocument": {
+			spec: "k",
+			err:  common.NewErrorMsg(common.ErrSortBadExpression, "the $sort key specification must be an object"),
+		},
+		"Empty": {
+			spec: d(),
+			err:  common.NewErrorMsg(common.ErrSortMissingKey, "$sort stage must have at least one sort key"),
+		},
+		"BadOrder": {
+			spec: d("k", int32(2)),
+			err: common.NewErrorMsg(
+				common.ErrSortBadOrder,
+				"$sort key ordering must be 1 (for ascending) or -1 (for descending)",
+			),
+		},
+		"BadValue": {
+			spec: d("k", "asc"),
+			err:  common.NewErrorMsg(common.ErrSortBadValue, "Illegal key in $sort specification: k: asc"),
+		},
+	} {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			stage, err := NewStage(d("$sort", tc.spec))
+			if tc.err != nil {
+				assert.Equal(t, tc.err, err)
+				return
+			}
+			require.NoError(t, err)
+
+			actual, err := stage.Process(testutil.Ctx(t), docs())
+			require.NoError(t, err)
+
+			ids := make([]any, len(actual))
+			for i, doc := range actual {
+				ids[i] = must.NotFail(doc.Get("_id"))
+			}
+
+			assert.Equal(t, tc.expected, ids)
+		})
+	}
+}
@@ -0,0 +1,82 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package aggregations provides aggregation pipelines.
+package aggregations
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/FerretDB/FerretDB/internal/handlers/common"
+	"github.com/FerretDB/FerretDB/internal/types"
+)
+
+// Stage is a common interface for all aggregation stages.
+type Stage interface {
+	// Process applies the stage to the given documents and returns the result.
+	Process(ctx context.Context, in []*types.Document) ([]*types.Document, error)
+}
+
+// newStageFunc is a type for a function that creates a new aggregation stage.
+type newStageFunc func(stage *types.Document) (Stage, error)
+
+// stages maps all supported aggregation stages.
+var stages = map[string]newStageFunc{
+	// sorted alphabetically
+	"$addFields": newAddFields,
+	"$count":     newCount,
+	"$documents": newDocuments,
+	"$group":     newGroup,
+	"$limit":     newLimit,
+	"$match":     newMatch,
+	"$project":   newProject,
+	"$set":       newAddFields,
+	"$skip":      newSkip,
+	"$sort":      newSort,
+}
+
+// NewStage creates a new aggregation stage from the given stage document.
+func NewStage(stage *types.Document) (Stage, error) {
+	if stage.Len() != 1 {
+		return nil, common.NewErrorMsg(
+			common.ErrStageInvalid,
+			"A pipeline stage specification object must contain exactly one field.",
+		)
+	}
+
+	name := stage.Command()
+
+	f, ok := stages[name]
+	if !ok {
+		return nil, common.NewErrorMsg(
+			common.ErrNotImplemented,
+			fmt.Sprintf("`aggregate` stage %q is not implemented yet", name),
+		)
+	}
+
+	return f(stage)
+}
+
+// Process applies all stages to the given documents one by one and returns the result.
+func Process(ctx context.Context, stages []Stage, docs []*types.Document) ([]*types.Document, error) {
+	var err error
+	for _, s := range stages {
+		if docs, err = s.Process(ctx, docs); err != nil {
+			return nil, err
+		}
+	}
+
+	return docs, nil
+}
@@ -0,0 +1,113 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aggregations
+
+import (
+	"context"
+
+	"github.com/FerretDB/FerretDB/internal/types"
+)
+
+// Streamer applies stages to documents as they are fetched from the backend.
+//
+// The leading $match, $addFields, $set, $skip and $limit stages process each document independently
+// (or just count them), so they are applied to every fetched batch of documents right away.
+// Other stages, for example blocking $group or $sort, and all stages after them,
+// are applied to all collected documents by Finish.
+type Streamer struct {
+	streaming []Stage
+	rest      []Stage
+
+	// counters of documents processed by the streaming $skip and $limit stages, by stage index
+	counters []int64
+
+	docs []*types.Document
+	done bool
+}
+
+// NewStreamer creates a new Streamer for the given stages.
+func NewStreamer(stages []Stage) *Streamer {
+	i := 0
+loop:
+	for ; i < len(stages); i++ {
+		switch stages[i].(type) {
+		case *match, *addFields, *skip, *limit:
+			// streaming stage
+		default:
+			break loop
+		}
+	}
+
+	return &Streamer{
+		streaming: stages[:i],
+		rest:      stages[i:],
+		counters:  make([]int64, i),
+	}
+}
+
+// Add applies streaming stages to the given batch of documents and collects the result.
+//
+// It returns true if no more documents are needed because some $limit stage is exhausted;
+// the caller should stop fetching then.
+func (s *Streamer) Add(ctx context.Context, docs []*types.Document) (bool, error) {
+	if s.done {
+		return true, nil
+	}
+
+	for i, stage := range s.streaming {
+		if len(docs) == 0 {
+			break
+		}
+
+		switch stage := stage.(type) {
+		case *skip:
+			n := stage.skip - s.counters[i]
+			if n > int64(len(docs)) {
+				n = int64(len(docs))
+			}
+
+			s.counters[i] += n
+			docs = docs[n:]
+
+		case *limit:
+			n := stage.limit - s.counters[i]
+			if n > int64(len(docs)) {
+				n = int64(len(docs))
+			}
+
+			s.counters[i] += n
+			docs = docs[:n]
+
+			if s.counters[i] == stage.limit {
+				s.done = true
+			}
+
+		default:
+			var err error
+			if docs, err = stage.Process(ctx, docs); err != nil {
+				return false, err
+			}
+		}
+	}
+
+	s.docs = append(s.docs, docs...)
+
+	return s.done, nil
+}
+
+// Finish applies the rest of stages to all collected documents and returns the result.
+func (s *Streamer) Finish(ctx context.Context) ([]*types.Document, error) {
+	return Process(ctx, s.rest, s.docs)
+}
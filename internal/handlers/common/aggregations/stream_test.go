@@ -0,0 +1,121 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aggregations
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+	"github.com/FerretDB/FerretDB/internal/util/testutil"
+)
+
+func TestStreamer(t *testing.T) {
+	t.Parallel()
+
+	// d is a shortcut for creating documents
+	d := func(pairs ...any) *types.Document { return must.NotFail(types.NewDocument(pairs...)) }
+
+	newStages := func(t *testing.T, stages ...*types.Document) []Stage {
+		t.Helper()
+
+		res := make([]Stage, len(stages))
+		for i, stage := range stages {
+			var err error
+			res[i], err = NewStage(stage)
+			require.NoError(t, err)
+		}
+
+		return res
+	}
+
+	// documents with _id from 1 to 6 and v "foo" for odd _id values, fetched in batches of two
+	batches := [][]*types.Document{
+		{d("_id", int32(1), "v", "foo"), d("_id", int32(2), "v", "bar")},
+		{d("_id", int32(3), "v", "foo"), d("_id", int32(4), "v", "bar")},
+		{d("_id", int32(5), "v", "foo"), d("_id", int32(6), "v", "bar")},
+	}
+
+	for name, tc := range map[string]struct {
+		stages   []*types.Document
+		expected []any // _id values
+		batches  int   // number of batches added before done
+	}{
+		"Empty": {
+			expected: []any{int32(1), int32(2), int32(3), int32(4), int32(5), int32(6)},
+			batches:  3,
+		},
+		"MatchLimit": {
+			stages:   []*types.Document{d("$match", d("v", "foo")), d("$limit", int32(2))},
+			expected: []any{int32(1), int32(3)},
+			batches:  2,
+		},
+		"SkipLimit": {
+			stages:   []*types.Document{d("$skip", int32(3)), d("$limit", int32(2))},
+			expected: []any{int32(4), int32(5)},
+			batches:  3,
+		},
+		"LimitSkip": {
+			stages:   []*types.Document{d("$limit", int32(2)), d("$skip", int32(1))},
+			expected: []any{int32(2)},
+			batches:  1,
+		},
+		"LimitMatch": {
+			stages:   []*types.Document{d("$limit", int32(4)), d("$match", d("v", "bar"))},
+			expected: []any{int32(2), int32(4)},
+			batches:  2,
+		},
+		"NotStreaming": {
+			stages:   []*types.Document{d("$match", d("v", "bar")), d("$documents", must.NotFail(types.NewArray())), d("$limit", int32(1))},
+			expected: []any{},
+			batches:  3,
+		},
+	} {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			ctx := testutil.Ctx(t)
+			s := NewStreamer(newStages(t, tc.stages...))
+
+			var added int
+			for _, batch := range batches {
+				added++
+
+				done, err := s.Add(ctx, batch)
+				require.NoError(t, err)
+
+				if done {
+					break
+				}
+			}
+
+			assert.Equal(t, tc.batches, added)
+
+			docs, err := s.Finish(ctx)
+			require.NoError(t, err)
+
+			actual := make([]any, len(docs))
+			for i, doc := range docs {
+				actual[i] = must.NotFail(doc.Get("_id"))
+			}
+
+			assert.Equal(t, tc.expected, actual)
+		})
+	}
+}
@@ -0,0 +1,76 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aggregations
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/FerretDB/FerretDB/internal/handlers/common"
+	"github.com/FerretDB/FerretDB/internal/types"
+)
+
+// variables maps names of aggregation expression variables (without $$ prefix) to their values,
+// for example, "new" to the document processed by $merge stage for $$new variable.
+type variables map[string]any
+
+// variablesKey is a context key for variables.
+type variablesKey struct{}
+
+// withVariables returns a new context with the given variables
+// that are available to expressions evaluated by stages processing documents with that context.
+func withVariables(ctx context.Context, vars variables) context.Context {
+	return context.WithValue(ctx, variablesKey{}, vars)
+}
+
+// getVariables returns variables stored in the given context, or nil.
+func getVariables(ctx context.Context) variables {
+	vars, _ := ctx.Value(variablesKey{}).(variables)
+	return vars
+}
+
+// evaluate evaluates the given variable expression like "$$new" or "$$new.field".
+//
+// It returns nil (not types.Null) if the expression evaluates to a missing value.
+func (vars variables) evaluate(expr string) (any, error) {
+	name, path, _ := strings.Cut(strings.TrimPrefix(expr, "$$"), ".")
+
+	v, ok := vars[name]
+	if !ok {
+		return nil, common.NewErrorMsg(
+			common.ErrNotImplemented,
+			fmt.Sprintf("aggregation expression variable %q is not implemented yet", expr),
+		)
+	}
+
+	if path == "" {
+		return v, nil
+	}
+
+	doc, ok := v.(*types.Document)
+	if !ok {
+		// there are no fields in non-document values
+		return nil, nil
+	}
+
+	res, err := doc.GetByPath(types.NewPathFromString(path))
+	if err != nil {
+		// missing field
+		return nil, nil
+	}
+
+	return res, nil
+}
@@ -0,0 +1,58 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package common
+
+import (
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+	"github.com/FerretDB/FerretDB/internal/wire"
+)
+
+// NegotiateCompression returns the value of `compression` field of hello and isMaster replies
+// for the given request document.
+//
+// It contains names of compressors requested by the client that are supported, in the client's order of preference.
+// It returns nil if the client did not request compression or if none of requested compressors are supported.
+func NegotiateCompression(document *types.Document) *types.Array {
+	v, err := document.Get("compression")
+	if err != nil {
+		return nil
+	}
+
+	arr, ok := v.(*types.Array)
+	if !ok {
+		return nil
+	}
+
+	requested := make([]string, 0, arr.Len())
+
+	for i := 0; i < arr.Len(); i++ {
+		if name, ok := must.NotFail(arr.Get(i)).(string); ok {
+			requested = append(requested, name)
+		}
+	}
+
+	negotiated := wire.NegotiateCompressors(requested)
+	if len(negotiated) == 0 {
+		return nil
+	}
+
+	res := types.MakeArray(len(negotiated))
+	for _, name := range negotiated {
+		must.NoError(res.Append(name))
+	}
+
+	return res
+}
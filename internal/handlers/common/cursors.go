@@ -0,0 +1,440 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package common
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/FerretDB/FerretDB/internal/clientconn/conninfo"
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+)
+
+// DefaultBatchSize is the default number of documents in the first batch of a cursor, the same as MongoDB's.
+// It is used when the client does not specify a batch size and the handler is not configured otherwise.
+const DefaultBatchSize = 101
+
+// DefaultCursorTimeout is the time after which idle cursors are removed, the same as MongoDB's.
+const DefaultCursorTimeout = 10 * time.Minute
+
+// tailPollInterval is the interval between checks for new documents of tailable cursors
+// while getMore is waiting for them.
+const tailPollInterval = 100 * time.Millisecond
+
+// fetchAllChunk is the number of documents fetched from the iterator at once
+// when all remaining documents of the cursor are requested.
+const fetchAllChunk = 1000
+
+// Iterator returns documents of a cursor lazily, so they are not all kept in memory.
+type Iterator interface {
+	// Next returns up to n next documents; n is positive.
+	// It returns fewer documents only if there are no more of them.
+	// It is not called concurrently.
+	Next(ctx context.Context, n int) ([]*types.Document, error)
+
+	// Close releases resources held by the iterator.
+	// It may be called several times and concurrently with Next.
+	Close()
+}
+
+// TailFunc returns documents that appeared since the previous call.
+// It is used by tailable cursors (for example, change streams) to fetch new documents.
+type TailFunc func(ctx context.Context) ([]*types.Document, error)
+
+// Cursors stores server-side cursors with documents that were not returned to the client yet.
+//
+// A single instance is shared by all commands of the handler that return cursors,
+// so getMore could be used for any of them.
+// Each cursor belongs to the client connection that created it:
+// it can't be used by other connections and is removed when the connection is closed.
+// Cursors that were not used for the timeout are removed too.
+// It is safe for concurrent use.
+type Cursors struct {
+	defaultBatchSize int32
+	timeout          time.Duration
+
+	mu     sync.Mutex
+	m      map[int64]*cursor
+	conns  map[*conninfo.ConnInfo]struct{} // connections with registered OnClose functions
+	lastID int64
+}
+
+// cursor represents a single server-side cursor.
+type cursor struct {
+	ns   string
+	conn *conninfo.ConnInfo
+
+	// lastUsed is protected by Cursors.mu.
+	lastUsed time.Time
+
+	// tail is nil for regular cursors that are removed once all documents are returned.
+	tail TailFunc
+
+	// iter, if not nil, returns documents that were not fetched to docs yet.
+	// It is set once on creation and closed when the cursor is removed.
+	iter Iterator
+
+	// mu protects docs and exhausted, and serializes tail and iter.Next calls.
+	mu        sync.Mutex
+	docs      []*types.Document
+	exhausted bool // iter returned all documents
+}
+
+// NewCursors returns a new empty cursors registry with the given default batch size
+// for the first batch; zero or negative value means DefaultBatchSize.
+func NewCursors(defaultBatchSize int32) *Cursors {
+	if defaultBatchSize <= 0 {
+		defaultBatchSize = DefaultBatchSize
+	}
+
+	return &Cursors{
+		defaultBatchSize: defaultBatchSize,
+		timeout:          DefaultCursorTimeout,
+		m:                make(map[int64]*cursor),
+		conns:            make(map[*conninfo.ConnInfo]struct{}),
+	}
+}
+
+// add stores a new cursor for the client connection from the context and returns its ID.
+func (c *Cursors) add(ctx context.Context, cur *cursor) int64 {
+	conn := conninfo.GetConnInfo(ctx)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
+	c.evict(now)
+
+	if _, ok := c.conns[conn]; !ok {
+		c.conns[conn] = struct{}{}
+		conn.OnClose(func() { c.closeConn(conn) })
+	}
+
+	c.lastID++
+	cur.conn = conn
+	cur.lastUsed = now
+	c.m[c.lastID] = cur
+
+	return c.lastID
+}
+
+// get returns the cursor with the given ID that belongs to the client connection from the context,
+// or nil if there is no such cursor.
+func (c *Cursors) get(ctx context.Context, id int64) *cursor {
+	conn := conninfo.GetConnInfo(ctx)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
+	c.evict(now)
+
+	cur := c.m[id]
+	if cur == nil || cur.conn != conn {
+		return nil
+	}
+
+	cur.lastUsed = now
+
+	return cur
+}
+
+// Kill removes the cursor with the given ID and namespace of the client connection from the context.
+// It returns false if there is no such cursor.
+func (c *Cursors) Kill(ctx context.Context, id int64, ns string) bool {
+	conn := conninfo.GetConnInfo(ctx)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	cur := c.m[id]
+	if cur == nil || cur.conn != conn || cur.ns != ns {
+		return false
+	}
+
+	delete(c.m, id)
+	cur.close()
+
+	return true
+}
+
+// remove removes the cursor with the given ID.
+func (c *Cursors) remove(id int64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if cur := c.m[id]; cur != nil {
+		delete(c.m, id)
+		cur.close()
+	}
+}
+
+// evict removes cursors that were not used since now minus timeout.
+//
+// It should be called with c.mu held.
+func (c *Cursors) evict(now time.Time) {
+	for id, cur := range c.m {
+		if now.Sub(cur.lastUsed) > c.timeout {
+			delete(c.m, id)
+			cur.close()
+		}
+	}
+}
+
+// closeConn removes all cursors of the given client connection.
+func (c *Cursors) closeConn(conn *conninfo.ConnInfo) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for id, cur := range c.m {
+		if cur.conn == conn {
+			delete(c.m, id)
+			cur.close()
+		}
+	}
+
+	delete(c.conns, conn)
+}
+
+// FirstBatch returns the "cursor" field value of the command reply with up to batchSize given documents
+// for the given namespace; negative batchSize means the default batch size of the registry.
+//
+// If there are more documents and singleBatch is false, they are stored in a new cursor
+// of the client connection from the context which ID is returned in the reply; otherwise, the cursor ID is 0.
+func (c *Cursors) FirstBatch(ctx context.Context, ns string, docs []*types.Document, batchSize int32, singleBatch bool) *types.Document {
+	if batchSize < 0 {
+		batchSize = c.defaultBatchSize
+	}
+
+	batch, rest := splitBatch(docs, batchSize)
+
+	var id int64
+	if len(rest) > 0 && !singleBatch {
+		id = c.add(ctx, &cursor{ns: ns, docs: rest})
+	}
+
+	return must.NotFail(types.NewDocument(
+		"firstBatch", batch,
+		"id", id,
+		"ns", ns,
+	))
+}
+
+// FirstBatchIterator is like FirstBatch, but documents are fetched from the given iterator lazily.
+//
+// If there are more documents and singleBatch is false, the iterator is stored in a new cursor,
+// so getMore fetches next documents from it; otherwise, the iterator is closed.
+// The iterator is closed when the cursor is exhausted, killed, evicted, or the client connection is closed.
+func (c *Cursors) FirstBatchIterator(ctx context.Context, ns string, iter Iterator, batchSize int32, singleBatch bool) (*types.Document, error) {
+	if batchSize < 0 {
+		batchSize = c.defaultBatchSize
+	}
+
+	cur := &cursor{ns: ns, iter: iter}
+
+	// fetch one more document to know if the cursor is needed
+	if err := cur.fetch(ctx, int(batchSize)+1); err != nil {
+		cur.close()
+		return nil, err
+	}
+
+	batch, rest := splitBatch(cur.docs, batchSize)
+	cur.docs = rest
+
+	var id int64
+	if len(rest) > 0 && !singleBatch {
+		id = c.add(ctx, cur)
+	} else {
+		cur.close()
+	}
+
+	return must.NotFail(types.NewDocument(
+		"firstBatch", batch,
+		"id", id,
+		"ns", ns,
+	)), nil
+}
+
+// NewTailable returns the "cursor" field value of the command reply for a new tailable cursor
+// of the client connection from the context for the given namespace that fetches documents with the given function.
+//
+// The first batch is always empty; documents are returned by getMore.
+// Tailable cursors are never exhausted.
+func (c *Cursors) NewTailable(ctx context.Context, ns string, tail TailFunc) *types.Document {
+	id := c.add(ctx, &cursor{ns: ns, tail: tail})
+
+	return must.NotFail(types.NewDocument(
+		"firstBatch", types.MakeArray(0),
+		"id", id,
+		"ns", ns,
+	))
+}
+
+// NextBatch returns the "cursor" field value of the getMore reply with up to batchSize next documents
+// of the cursor with the given ID and namespace; zero batchSize means all remaining documents.
+//
+// The cursor is removed once all its documents are returned; the cursor ID in the reply is 0 in that case.
+// Tailable cursors are not removed; if there are no documents, NextBatch waits up to maxAwait for new ones.
+// Cursors of other client connections are not found.
+func (c *Cursors) NextBatch(ctx context.Context, id int64, ns string, batchSize int32, maxAwait time.Duration) (*types.Document, error) {
+	cur := c.get(ctx, id)
+	if cur == nil {
+		return nil, NewErrorMsg(ErrCursorNotFound, fmt.Sprintf("cursor id %d not found", id))
+	}
+
+	if cur.ns != ns {
+		return nil, NewErrorMsg(
+			ErrUnauthorized,
+			fmt.Sprintf("Requested getMore on namespace '%s', but cursor belongs to a different namespace %s", ns, cur.ns),
+		)
+	}
+
+	cur.mu.Lock()
+	defer cur.mu.Unlock()
+
+	if cur.tail != nil && len(cur.docs) == 0 {
+		if err := cur.await(ctx, maxAwait); err != nil {
+			return nil, err
+		}
+	}
+
+	var err error
+	if batchSize == 0 {
+		err = cur.fetchAll(ctx)
+		batchSize = int32(len(cur.docs))
+	} else {
+		// fetch one more document to know if the cursor is exhausted
+		err = cur.fetch(ctx, int(batchSize)+1)
+	}
+
+	if err != nil {
+		c.remove(id)
+		return nil, err
+	}
+
+	batch, rest := splitBatch(cur.docs, batchSize)
+
+	cur.docs = rest
+	if len(rest) == 0 && cur.tail == nil {
+		c.remove(id)
+		id = 0
+	}
+
+	return must.NotFail(types.NewDocument(
+		"nextBatch", batch,
+		"id", id,
+		"ns", ns,
+	)), nil
+}
+
+// fetch fetches documents from the iterator until there are at least n of them in docs
+// or the iterator is exhausted.
+//
+// It should be called with cur.mu held (or before the cursor is added).
+func (cur *cursor) fetch(ctx context.Context, n int) error {
+	if cur.iter == nil || cur.exhausted || len(cur.docs) >= n {
+		return nil
+	}
+
+	want := n - len(cur.docs)
+
+	docs, err := cur.iter.Next(ctx, want)
+	if err != nil {
+		return err
+	}
+
+	cur.docs = append(cur.docs, docs...)
+
+	if len(docs) < want {
+		cur.exhausted = true
+		cur.iter.Close()
+	}
+
+	return nil
+}
+
+// fetchAll fetches all remaining documents from the iterator.
+//
+// It should be called with cur.mu held.
+func (cur *cursor) fetchAll(ctx context.Context) error {
+	for cur.iter != nil && !cur.exhausted {
+		if err := cur.fetch(ctx, len(cur.docs)+fetchAllChunk); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// close releases resources of the cursor's iterator, if any.
+//
+// It does not require cur.mu as iter is never changed and could be closed concurrently with Next.
+func (cur *cursor) close() {
+	if cur.iter != nil {
+		cur.iter.Close()
+	}
+}
+
+// await fetches new documents of the tailable cursor until there are some or maxAwait passes.
+//
+// It should be called with cur.mu held.
+func (cur *cursor) await(ctx context.Context, maxAwait time.Duration) error {
+	deadline := time.Now().Add(maxAwait)
+
+	for {
+		docs, err := cur.tail(ctx)
+		if err != nil {
+			return err
+		}
+
+		if len(docs) > 0 {
+			cur.docs = append(cur.docs, docs...)
+			return nil
+		}
+
+		wait := time.Until(deadline)
+		if wait <= 0 {
+			return nil
+		}
+
+		if wait > tailPollInterval {
+			wait = tailPollInterval
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(wait):
+		}
+	}
+}
+
+// splitBatch returns an array with up to batchSize first documents and the remaining documents.
+func splitBatch(docs []*types.Document, batchSize int32) (*types.Array, []*types.Document) {
+	n := len(docs)
+	if int(batchSize) < n {
+		n = int(batchSize)
+	}
+
+	batch := types.MakeArray(n)
+	for _, doc := range docs[:n] {
+		must.NoError(batch.Append(doc))
+	}
+
+	return batch, docs[n:]
+}
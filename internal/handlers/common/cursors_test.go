@@ -0,0 +1,430 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package common
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/FerretDB/FerretDB/internal/clientconn/conninfo"
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+	"github.com/FerretDB/FerretDB/internal/util/testutil"
+)
+
+func TestCursors(t *testing.T) {
+	t.Parallel()
+
+	ctx := conninfo.WithConnInfo(testutil.Ctx(t), new(conninfo.ConnInfo))
+
+	docs := make([]*types.Document, 5)
+	for i := range docs {
+		docs[i] = must.NotFail(types.NewDocument("_id", int32(i)))
+	}
+
+	// batchIDs returns _id values of the batch in the given cursor reply field.
+	batchIDs := func(t *testing.T, cursor *types.Document, field string) []any {
+		t.Helper()
+
+		batch := must.NotFail(cursor.Get(field)).(*types.Array)
+		var res []any
+		for i := 0; i < batch.Len(); i++ {
+			res = append(res, must.NotFail(must.NotFail(batch.Get(i)).(*types.Document).Get("_id")))
+		}
+		return res
+	}
+
+	t.Run("AllInFirstBatch", func(t *testing.T) {
+		t.Parallel()
+
+		c := NewCursors(0)
+		cursor := c.FirstBatch(ctx, "db.coll", docs, -1, false)
+		assert.Equal(t, int64(0), must.NotFail(cursor.Get("id")))
+		assert.Equal(t, "db.coll", must.NotFail(cursor.Get("ns")))
+		assert.Len(t, batchIDs(t, cursor, "firstBatch"), 5)
+		assert.Empty(t, c.m)
+	})
+
+	t.Run("Paging", func(t *testing.T) {
+		t.Parallel()
+
+		c := NewCursors(0)
+		cursor := c.FirstBatch(ctx, "db.coll", docs, 2, false)
+		assert.Equal(t, []any{int32(0), int32(1)}, batchIDs(t, cursor, "firstBatch"))
+
+		id := must.NotFail(cursor.Get("id")).(int64)
+		require.NotZero(t, id)
+
+		cursor, err := c.NextBatch(ctx, id, "db.coll", 2, 0)
+		require.NoError(t, err)
+		assert.Equal(t, []any{int32(2), int32(3)}, batchIDs(t, cursor, "nextBatch"))
+		assert.Equal(t, id, must.NotFail(cursor.Get("id")))
+
+		cursor, err = c.NextBatch(ctx, id, "db.coll", 2, 0)
+		require.NoError(t, err)
+		assert.Equal(t, []any{int32(4)}, batchIDs(t, cursor, "nextBatch"))
+		assert.Equal(t, int64(0), must.NotFail(cursor.Get("id")))
+
+		_, err = c.NextBatch(ctx, id, "db.coll", 2, 0)
+		assert.Equal(t, NewErrorMsg(ErrCursorNotFound, "cursor id 1 not found"), err)
+	})
+
+	t.Run("ConfiguredDefault", func(t *testing.T) {
+		t.Parallel()
+
+		c := NewCursors(3)
+		cursor := c.FirstBatch(ctx, "db.coll", docs, -1, false)
+		assert.Equal(t, []any{int32(0), int32(1), int32(2)}, batchIDs(t, cursor, "firstBatch"))
+		assert.NotZero(t, must.NotFail(cursor.Get("id")))
+
+		// explicit batch size takes precedence
+		cursor = c.FirstBatch(ctx, "db.coll", docs, 1, false)
+		assert.Equal(t, []any{int32(0)}, batchIDs(t, cursor, "firstBatch"))
+	})
+
+	t.Run("SingleBatch", func(t *testing.T) {
+		t.Parallel()
+
+		c := NewCursors(0)
+		cursor := c.FirstBatch(ctx, "db.coll", docs, 2, true)
+		assert.Equal(t, []any{int32(0), int32(1)}, batchIDs(t, cursor, "firstBatch"))
+		assert.Equal(t, int64(0), must.NotFail(cursor.Get("id")))
+		assert.Empty(t, c.m)
+	})
+
+	t.Run("ZeroBatchSize", func(t *testing.T) {
+		t.Parallel()
+
+		c := NewCursors(0)
+		cursor := c.FirstBatch(ctx, "db.coll", docs, 0, false)
+		assert.Empty(t, batchIDs(t, cursor, "firstBatch"))
+
+		id := must.NotFail(cursor.Get("id")).(int64)
+		require.NotZero(t, id)
+
+		// zero batch size for getMore means all remaining documents
+		cursor, err := c.NextBatch(ctx, id, "db.coll", 0, 0)
+		require.NoError(t, err)
+		assert.Len(t, batchIDs(t, cursor, "nextBatch"), 5)
+		assert.Equal(t, int64(0), must.NotFail(cursor.Get("id")))
+	})
+
+	t.Run("OtherNamespace", func(t *testing.T) {
+		t.Parallel()
+
+		c := NewCursors(0)
+		cursor := c.FirstBatch(ctx, "db.coll", docs, 1, false)
+		id := must.NotFail(cursor.Get("id")).(int64)
+
+		_, err := c.NextBatch(ctx, id, "db.other", 1, 0)
+		expected := NewErrorMsg(
+			ErrUnauthorized,
+			"Requested getMore on namespace 'db.other', but cursor belongs to a different namespace db.coll",
+		)
+		assert.Equal(t, expected, err)
+
+		// the cursor is still usable
+		_, err = c.NextBatch(ctx, id, "db.coll", 1, 0)
+		assert.NoError(t, err)
+	})
+
+	t.Run("OtherConnection", func(t *testing.T) {
+		t.Parallel()
+
+		c := NewCursors(0)
+		cursor := c.FirstBatch(ctx, "db.coll", docs, 1, false)
+		id := must.NotFail(cursor.Get("id")).(int64)
+
+		otherCtx := conninfo.WithConnInfo(testutil.Ctx(t), new(conninfo.ConnInfo))
+		_, err := c.NextBatch(otherCtx, id, "db.coll", 1, 0)
+		assert.Equal(t, NewErrorMsg(ErrCursorNotFound, "cursor id 1 not found"), err)
+
+		// the cursor is still usable by its connection
+		_, err = c.NextBatch(ctx, id, "db.coll", 1, 0)
+		assert.NoError(t, err)
+	})
+
+	t.Run("ConnectionClose", func(t *testing.T) {
+		t.Parallel()
+
+		connInfo := new(conninfo.ConnInfo)
+		connCtx := conninfo.WithConnInfo(testutil.Ctx(t), connInfo)
+
+		c := NewCursors(0)
+		c.FirstBatch(connCtx, "db.coll", docs, 1, false)
+		c.FirstBatch(connCtx, "db.coll", docs, 1, false)
+		cursor := c.FirstBatch(ctx, "db.coll", docs, 1, false)
+		id := must.NotFail(cursor.Get("id")).(int64)
+		require.Len(t, c.m, 3)
+
+		connInfo.Close()
+		assert.Len(t, c.m, 1)
+		assert.Contains(t, c.m, id)
+		assert.NotContains(t, c.conns, connInfo)
+	})
+
+	t.Run("Kill", func(t *testing.T) {
+		t.Parallel()
+
+		c := NewCursors(0)
+		cursor := c.FirstBatch(ctx, "db.coll", docs, 1, false)
+		id := must.NotFail(cursor.Get("id")).(int64)
+
+		otherCtx := conninfo.WithConnInfo(testutil.Ctx(t), new(conninfo.ConnInfo))
+		assert.False(t, c.Kill(otherCtx, id, "db.coll"))
+		assert.False(t, c.Kill(ctx, id, "db.other"))
+
+		assert.True(t, c.Kill(ctx, id, "db.coll"))
+		assert.False(t, c.Kill(ctx, id, "db.coll"))
+		assert.Empty(t, c.m)
+	})
+
+	t.Run("Timeout", func(t *testing.T) {
+		t.Parallel()
+
+		c := NewCursors(0)
+		c.timeout = time.Minute
+
+		cursor := c.FirstBatch(ctx, "db.coll", docs, 1, false)
+		abandoned := must.NotFail(cursor.Get("id")).(int64)
+
+		cursor = c.FirstBatch(ctx, "db.coll", docs, 1, false)
+		used := must.NotFail(cursor.Get("id")).(int64)
+
+		c.mu.Lock()
+		c.m[abandoned].lastUsed = time.Now().Add(-2 * time.Minute)
+		c.m[used].lastUsed = time.Now().Add(-30 * time.Second)
+		c.mu.Unlock()
+
+		_, err := c.NextBatch(ctx, abandoned, "db.coll", 1, 0)
+		assert.Equal(t, NewErrorMsg(ErrCursorNotFound, "cursor id 1 not found"), err)
+
+		_, err = c.NextBatch(ctx, used, "db.coll", 1, 0)
+		assert.NoError(t, err)
+	})
+
+	t.Run("Tailable", func(t *testing.T) {
+		t.Parallel()
+
+		var calls int
+		tail := func(ctx context.Context) ([]*types.Document, error) {
+			calls++
+			if calls < 3 {
+				return nil, nil
+			}
+			return docs[:3], nil
+		}
+
+		c := NewCursors(0)
+		cursor := c.NewTailable(ctx, "db.coll", tail)
+		assert.Empty(t, batchIDs(t, cursor, "firstBatch"))
+
+		id := must.NotFail(cursor.Get("id")).(int64)
+		require.NotZero(t, id)
+
+		// no new documents within the await time
+		cursor, err := c.NextBatch(ctx, id, "db.coll", 0, 0)
+		require.NoError(t, err)
+		assert.Empty(t, batchIDs(t, cursor, "nextBatch"))
+		assert.Equal(t, id, must.NotFail(cursor.Get("id")))
+
+		// new documents appear while waiting
+		cursor, err = c.NextBatch(ctx, id, "db.coll", 2, time.Second)
+		require.NoError(t, err)
+		assert.Equal(t, []any{int32(0), int32(1)}, batchIDs(t, cursor, "nextBatch"))
+		assert.Equal(t, id, must.NotFail(cursor.Get("id")))
+
+		// buffered documents are returned without waiting, and the cursor is not removed
+		cursor, err = c.NextBatch(ctx, id, "db.coll", 2, time.Second)
+		require.NoError(t, err)
+		assert.Equal(t, []any{int32(2)}, batchIDs(t, cursor, "nextBatch"))
+		assert.Equal(t, id, must.NotFail(cursor.Get("id")))
+		assert.Equal(t, 3, calls)
+	})
+}
+
+// testIterator is a common.Iterator for tests that returns the given documents
+// and records fetched and closed state.
+type testIterator struct {
+	docs    []*types.Document
+	fetched int
+	closed  bool
+}
+
+// Next implements Iterator.
+func (it *testIterator) Next(ctx context.Context, n int) ([]*types.Document, error) {
+	if n > len(it.docs) {
+		n = len(it.docs)
+	}
+
+	res := it.docs[:n]
+	it.docs = it.docs[n:]
+	it.fetched += n
+
+	return res, nil
+}
+
+// Close implements Iterator.
+func (it *testIterator) Close() {
+	it.closed = true
+}
+
+func TestCursorsIterator(t *testing.T) {
+	t.Parallel()
+
+	ctx := conninfo.WithConnInfo(testutil.Ctx(t), new(conninfo.ConnInfo))
+
+	docs := make([]*types.Document, 5)
+	for i := range docs {
+		docs[i] = must.NotFail(types.NewDocument("_id", int32(i)))
+	}
+
+	t.Run("Paging", func(t *testing.T) {
+		t.Parallel()
+
+		iter := &testIterator{docs: docs}
+
+		c := NewCursors(0)
+		cursor, err := c.FirstBatchIterator(ctx, "db.coll", iter, 2, false)
+		require.NoError(t, err)
+		assert.Equal(t, 2, must.NotFail(cursor.Get("firstBatch")).(*types.Array).Len())
+
+		// documents are fetched lazily, with one extra document to know if there are more
+		assert.Equal(t, 3, iter.fetched)
+		assert.False(t, iter.closed)
+
+		id := must.NotFail(cursor.Get("id")).(int64)
+		require.NotZero(t, id)
+
+		cursor, err = c.NextBatch(ctx, id, "db.coll", 2, 0)
+		require.NoError(t, err)
+		assert.Equal(t, 2, must.NotFail(cursor.Get("nextBatch")).(*types.Array).Len())
+		assert.Equal(t, id, must.NotFail(cursor.Get("id")))
+		assert.Equal(t, 5, iter.fetched)
+
+		cursor, err = c.NextBatch(ctx, id, "db.coll", 2, 0)
+		require.NoError(t, err)
+		assert.Equal(t, 1, must.NotFail(cursor.Get("nextBatch")).(*types.Array).Len())
+		assert.Equal(t, int64(0), must.NotFail(cursor.Get("id")))
+		assert.True(t, iter.closed, "exhausted iterator should be closed")
+		assert.Empty(t, c.m)
+	})
+
+	t.Run("AllRemaining", func(t *testing.T) {
+		t.Parallel()
+
+		iter := &testIterator{docs: docs}
+
+		c := NewCursors(0)
+		cursor, err := c.FirstBatchIterator(ctx, "db.coll", iter, 1, false)
+		require.NoError(t, err)
+
+		id := must.NotFail(cursor.Get("id")).(int64)
+		require.NotZero(t, id)
+
+		cursor, err = c.NextBatch(ctx, id, "db.coll", 0, 0)
+		require.NoError(t, err)
+		assert.Equal(t, 4, must.NotFail(cursor.Get("nextBatch")).(*types.Array).Len())
+		assert.Equal(t, int64(0), must.NotFail(cursor.Get("id")))
+		assert.True(t, iter.closed)
+	})
+
+	t.Run("AllInFirstBatch", func(t *testing.T) {
+		t.Parallel()
+
+		iter := &testIterator{docs: docs}
+
+		c := NewCursors(0)
+		cursor, err := c.FirstBatchIterator(ctx, "db.coll", iter, -1, false)
+		require.NoError(t, err)
+		assert.Equal(t, 5, must.NotFail(cursor.Get("firstBatch")).(*types.Array).Len())
+		assert.Equal(t, int64(0), must.NotFail(cursor.Get("id")))
+		assert.True(t, iter.closed)
+		assert.Empty(t, c.m)
+	})
+
+	t.Run("SingleBatch", func(t *testing.T) {
+		t.Parallel()
+
+		iter := &testIterator{docs: docs}
+
+		c := NewCursors(0)
+		cursor, err := c.FirstBatchIterator(ctx, "db.coll", iter, 2, true)
+		require.NoError(t, err)
+		assert.Equal(t, int64(0), must.NotFail(cursor.Get("id")))
+		assert.True(t, iter.closed)
+		assert.Empty(t, c.m)
+	})
+
+	t.Run("Kill", func(t *testing.T) {
+		t.Parallel()
+
+		iter := &testIterator{docs: docs}
+
+		c := NewCursors(0)
+		cursor, err := c.FirstBatchIterator(ctx, "db.coll", iter, 1, false)
+		require.NoError(t, err)
+
+		id := must.NotFail(cursor.Get("id")).(int64)
+		assert.False(t, iter.closed)
+
+		assert.True(t, c.Kill(ctx, id, "db.coll"))
+		assert.True(t, iter.closed)
+	})
+
+	t.Run("ConnectionClose", func(t *testing.T) {
+		t.Parallel()
+
+		connInfo := new(conninfo.ConnInfo)
+		connCtx := conninfo.WithConnInfo(testutil.Ctx(t), connInfo)
+
+		iter := &testIterator{docs: docs}
+
+		c := NewCursors(0)
+		_, err := c.FirstBatchIterator(connCtx, "db.coll", iter, 1, false)
+		require.NoError(t, err)
+		assert.False(t, iter.closed)
+
+		connInfo.Close()
+		assert.True(t, iter.closed)
+	})
+
+	t.Run("Timeout", func(t *testing.T) {
+		t.Parallel()
+
+		iter := &testIterator{docs: docs}
+
+		c := NewCursors(0)
+		c.timeout = time.Minute
+
+		cursor, err := c.FirstBatchIterator(ctx, "db.coll", iter, 1, false)
+		require.NoError(t, err)
+
+		id := must.NotFail(cursor.Get("id")).(int64)
+
+		c.mu.Lock()
+		c.m[id].lastUsed = time.Now().Add(-2 * time.Minute)
+		c.mu.Unlock()
+
+		_, err = c.NextBatch(ctx, id, "db.coll", 1, 0)
+		assert.Equal(t, NewErrorMsg(ErrCursorNotFound, fmt.Sprintf("cursor id %d not found", id)), err)
+		assert.True(t, iter.closed)
+	})
+}
@@ -0,0 +1,72 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package common
+
+import (
+	"fmt"
+
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+)
+
+// DefaultMaxDocumentDepth is the default maximum nesting depth of inserted documents,
+// the same as MongoDB's limit for user documents.
+const DefaultMaxDocumentDepth = 100
+
+// ValidateDocumentDepth returns Overflow error if the given document has more than maxDepth levels
+// of nested documents and arrays; the document itself is the first level.
+// Zero or negative maxDepth means DefaultMaxDocumentDepth.
+func ValidateDocumentDepth(doc *types.Document, maxDepth int) error {
+	if maxDepth <= 0 {
+		maxDepth = DefaultMaxDocumentDepth
+	}
+
+	if exceedsDepth(doc, 1, maxDepth) {
+		msg := fmt.Sprintf("cannot insert document because it exceeds %d levels of nesting", maxDepth)
+		return NewErrorMsg(ErrOverflow, msg)
+	}
+
+	return nil
+}
+
+// exceedsDepth returns true if the given value located at the given depth
+// has documents or arrays nested deeper than maxDepth.
+func exceedsDepth(value any, depth, maxDepth int) bool {
+	switch value := value.(type) {
+	case *types.Document:
+		if depth > maxDepth {
+			return true
+		}
+
+		for _, k := range value.Keys() {
+			if exceedsDepth(must.NotFail(value.Get(k)), depth+1, maxDepth) {
+				return true
+			}
+		}
+
+	case *types.Array:
+		if depth > maxDepth {
+			return true
+		}
+
+		for i := 0; i < value.Len(); i++ {
+			if exceedsDepth(must.NotFail(value.Get(i)), depth+1, maxDepth) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
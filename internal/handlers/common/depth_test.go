@@ -0,0 +1,91 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package common
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+)
+
+// nestedDocument returns a document with the given number of levels, including itself;
+// odd levels are documents, even levels are arrays.
+func nestedDocument(levels int) *types.Document {
+	var v any = "foo"
+	for i := levels; i > 1; i-- {
+		if i%2 == 0 {
+			v = must.NotFail(types.NewArray(v))
+		} else {
+			v = must.NotFail(types.NewDocument("v", v))
+		}
+	}
+
+	return must.NotFail(types.NewDocument("_id", "nested", "v", v))
+}
+
+func TestValidateDocumentDepth(t *testing.T) {
+	t.Parallel()
+
+	for name, tc := range map[string]struct { //nolint:paralleltest // false positive
+		doc      *types.Document
+		maxDepth int
+		err      string
+	}{
+		"Flat": {
+			doc: must.NotFail(types.NewDocument("_id", "flat", "v", int32(1))),
+		},
+		"DefaultLimit": {
+			doc: nestedDocument(DefaultMaxDocumentDepth),
+		},
+		"DefaultExceeded": {
+			doc: nestedDocument(DefaultMaxDocumentDepth + 1),
+			err: "cannot insert document because it exceeds 100 levels of nesting",
+		},
+		"CustomLimit": {
+			doc:      nestedDocument(3),
+			maxDepth: 3,
+		},
+		"CustomExceeded": {
+			doc:      nestedDocument(4),
+			maxDepth: 3,
+			err:      "cannot insert document because it exceeds 3 levels of nesting",
+		},
+		"EmptyNested": {
+			doc: must.NotFail(types.NewDocument(
+				"v", must.NotFail(types.NewDocument("v", must.NotFail(types.NewArray()))),
+			)),
+			maxDepth: 2,
+			err:      "cannot insert document because it exceeds 2 levels of nesting",
+		},
+	} {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			err := ValidateDocumentDepth(tc.doc, tc.maxDepth)
+			if tc.err == "" {
+				assert.NoError(t, err)
+				return
+			}
+
+			expected := NewErrorMsg(ErrOverflow, tc.err)
+			require.Equal(t, expected, err)
+		})
+	}
+}
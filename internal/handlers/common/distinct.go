@@ -0,0 +1,124 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package common
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+)
+
+// FilterDistinctValues returns distinct values of the given dotted key path in the given documents.
+//
+// Documents without the key are skipped; if the key points to an array, its elements are used instead.
+// Values are de-duplicated the same way as MongoDB does it: numerically equal values
+// of different types (e.g. int32 1 and double 1.0), including ones nested in documents and arrays,
+// are the same value, and the first seen is kept.
+func FilterDistinctValues(docs []*types.Document, key string) (*types.Array, error) {
+	path := types.NewPathFromString(key)
+	distinct := types.MakeArray(0)
+	seen := make(map[string]struct{})
+
+	for _, doc := range docs {
+		val, err := doc.GetByPath(path)
+		if err != nil {
+			continue
+		}
+
+		values := []any{val}
+		if arr, ok := val.(*types.Array); ok {
+			values = make([]any, arr.Len())
+			for i := range values {
+				values[i] = must.NotFail(arr.Get(i))
+			}
+		}
+
+		for _, v := range values {
+			var sb strings.Builder
+			writeDistinctKey(&sb, v)
+
+			k := sb.String()
+			if _, ok := seen[k]; ok {
+				continue
+			}
+			seen[k] = struct{}{}
+
+			if err = distinct.Append(v); err != nil {
+				return nil, lazyerrors.Error(err)
+			}
+		}
+	}
+
+	return distinct, nil
+}
+
+// writeDistinctKey writes the key of the given value to sb.
+// Values are the same distinct value if and only if their keys are equal.
+func writeDistinctKey(sb *strings.Builder, v any) {
+	switch v := v.(type) {
+	case *types.Document:
+		sb.WriteString("{")
+		for _, k := range v.Keys() {
+			sb.WriteString(strconv.Quote(k))
+			sb.WriteString(":")
+			writeDistinctKey(sb, must.NotFail(v.Get(k)))
+			sb.WriteString(",")
+		}
+		sb.WriteString("}")
+
+	case *types.Array:
+		sb.WriteString("[")
+		for i := 0; i < v.Len(); i++ {
+			writeDistinctKey(sb, must.NotFail(v.Get(i)))
+			sb.WriteString(",")
+		}
+		sb.WriteString("]")
+
+	case float64:
+		if math.IsNaN(v) {
+			sb.WriteString("n:NaN")
+			return
+		}
+
+		// whole numbers are written as integers to be equal to int32 and int64 values
+		if v == math.Trunc(v) && v >= math.MinInt64 && v < math.MaxInt64 {
+			sb.WriteString("n:" + strconv.FormatInt(int64(v), 10))
+			return
+		}
+
+		sb.WriteString("n:" + strconv.FormatFloat(v, 'g', -1, 64))
+
+	case int32:
+		sb.WriteString("n:" + strconv.FormatInt(int64(v), 10))
+
+	case int64:
+		sb.WriteString("n:" + strconv.FormatInt(v, 10))
+
+	case string:
+		sb.WriteString("s:" + strconv.Quote(v))
+
+	case time.Time:
+		sb.WriteString("t:" + strconv.FormatInt(v.UnixMilli(), 10))
+
+	default:
+		fmt.Fprintf(sb, "%T:%#v", v, v)
+	}
+}
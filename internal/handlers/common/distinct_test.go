@@ -0,0 +1,101 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package common
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+)
+
+func TestFilterDistinctValues(t *testing.T) {
+	t.Parallel()
+
+	for name, tc := range map[string]struct { //nolint:paralleltest // false positive
+		docs     []*types.Document
+		key      string
+		expected *types.Array
+	}{
+		"NumbersFirstSeenKept": {
+			docs: []*types.Document{
+				must.NotFail(types.NewDocument("v", int32(1))),
+				must.NotFail(types.NewDocument("v", 1.0)),
+				must.NotFail(types.NewDocument("v", int64(1))),
+				must.NotFail(types.NewDocument("v", 2.0)),
+				must.NotFail(types.NewDocument("v", int32(2))),
+			},
+			key:      "v",
+			expected: must.NotFail(types.NewArray(int32(1), 2.0)),
+		},
+		"DifferentTypes": {
+			docs: []*types.Document{
+				must.NotFail(types.NewDocument("v", "1")),
+				must.NotFail(types.NewDocument("v", int32(1))),
+				must.NotFail(types.NewDocument("v", types.Null)),
+				must.NotFail(types.NewDocument("v", types.Null)),
+			},
+			key:      "v",
+			expected: must.NotFail(types.NewArray("1", int32(1), types.Null)),
+		},
+		"ArrayFlattened": {
+			docs: []*types.Document{
+				must.NotFail(types.NewDocument("v", must.NotFail(types.NewArray(int32(1), "foo")))),
+				must.NotFail(types.NewDocument("v", "foo")),
+				must.NotFail(types.NewDocument("v", int64(3))),
+			},
+			key:      "v",
+			expected: must.NotFail(types.NewArray(int32(1), "foo", int64(3))),
+		},
+		"NestedNumbers": {
+			docs: []*types.Document{
+				must.NotFail(types.NewDocument("v", must.NotFail(types.NewDocument("a", int32(1))))),
+				must.NotFail(types.NewDocument("v", must.NotFail(types.NewDocument("a", 1.0)))),
+				must.NotFail(types.NewDocument("v", must.NotFail(types.NewDocument("a", 1.5)))),
+				must.NotFail(types.NewDocument("v", must.NotFail(types.NewArray(
+					must.NotFail(types.NewArray(int64(1), "foo")),
+					must.NotFail(types.NewArray(1.0, "foo")),
+				)))),
+			},
+			key: "v",
+			expected: must.NotFail(types.NewArray(
+				must.NotFail(types.NewDocument("a", int32(1))),
+				must.NotFail(types.NewDocument("a", 1.5)),
+				must.NotFail(types.NewArray(int64(1), "foo")),
+			)),
+		},
+		"DottedPathMissing": {
+			docs: []*types.Document{
+				must.NotFail(types.NewDocument("v", must.NotFail(types.NewDocument("foo", 42.0)))),
+				must.NotFail(types.NewDocument("v", must.NotFail(types.NewDocument("bar", 42.0)))),
+				must.NotFail(types.NewDocument("w", int32(42))),
+			},
+			key:      "v.foo",
+			expected: must.NotFail(types.NewArray(42.0)),
+		},
+	} {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			actual, err := FilterDistinctValues(tc.docs, tc.key)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, actual)
+		})
+	}
+}
@@ -30,8 +30,8 @@ type ErrorCode int32
 const (
 	errUnset = ErrorCode(0) // Unset
 
-	// For ProtocolError only.
-	errInternalError = ErrorCode(1) // InternalError
+	// ErrInternalError indicates an unexpected server-side failure, such as a recovered handler panic.
+	ErrInternalError = ErrorCode(1) // InternalError
 
 	// ErrBadValue indicates wrong input.
 	ErrBadValue = ErrorCode(2) // BadValue
@@ -39,48 +39,150 @@ const (
 	// ErrFailedToParse indicates user input parsing failure.
 	ErrFailedToParse = ErrorCode(9) // FailedToParse
 
+	// ErrUnauthorized indicates that the operation is not allowed, such as getMore on a cursor of other namespace.
+	ErrUnauthorized = ErrorCode(13) // Unauthorized
+
 	// ErrTypeMismatch for $sort indicates that the expression in the $sort is not an object.
 	ErrTypeMismatch = ErrorCode(14) // TypeMismatch
 
+	// ErrOverflow indicates that a value is out of range, such as a too deeply nested document.
+	ErrOverflow = ErrorCode(15) // Overflow
+
+	// ErrInvalidLength indicates that the number of elements is out of range, such as an empty batch of statements.
+	ErrInvalidLength = ErrorCode(16) // InvalidLength
+
+	// ErrIllegalOperation indicates that the operation is not allowed, such as renaming a collection to itself.
+	ErrIllegalOperation = ErrorCode(20) // IllegalOperation
+
 	// ErrNamespaceNotFound indicates that a collection is not found.
 	ErrNamespaceNotFound = ErrorCode(26) // NamespaceNotFound
 
+	// ErrIndexNotFound indicates that an index with the given name or key does not exist.
+	ErrIndexNotFound = ErrorCode(27) // IndexNotFound
+
 	// ErrUnsuitableValueType indicates that field could not be created for given value.
 	ErrUnsuitableValueType = ErrorCode(28) // UnsuitableValueType
 
 	// ErrConflictingUpdateOperators indicates that $set, $inc or $setOnInsert were used together.
 	ErrConflictingUpdateOperators = ErrorCode(40) // ConflictingUpdateOperators
 
+	// ErrCursorNotFound indicates that a cursor with the given ID does not exist.
+	ErrCursorNotFound = ErrorCode(43) // CursorNotFound
+
 	// ErrNamespaceExists indicates that the collection already exists.
 	ErrNamespaceExists = ErrorCode(48) // NamespaceExists
 
 	// ErrCommandNotFound indicates unknown command input.
 	ErrCommandNotFound = ErrorCode(59) // CommandNotFound
 
+	// ErrCannotCreateIndex indicates that index creation process failed.
+	ErrCannotCreateIndex = ErrorCode(67) // CannotCreateIndex
+
+	// ErrImmutableField indicates that the update would modify the immutable field, such as _id.
+	ErrImmutableField = ErrorCode(66) // ImmutableField
+
+	// ErrInvalidOptions indicates that the command options are invalid, such as dropping the _id index.
+	ErrInvalidOptions = ErrorCode(72) // InvalidOptions
+
 	// ErrInvalidNamespace indicates that the collection name is invalid.
 	ErrInvalidNamespace = ErrorCode(73) // InvalidNamespace
 
+	// ErrIndexOptionsConflict indicates that an index with the same name and different options already exists.
+	ErrIndexOptionsConflict = ErrorCode(85) // IndexOptionsConflict
+
+	// ErrIndexKeySpecsConflict indicates that an index with the same name and different key already exists.
+	ErrIndexKeySpecsConflict = ErrorCode(86) // IndexKeySpecsConflict
+
 	// ErrDocumentValidationFailure indicates that document validation failed.
 	ErrDocumentValidationFailure = ErrorCode(121) // DocumentValidationFailure
 
 	// ErrNotImplemented indicates that a flag or command is not implemented.
 	ErrNotImplemented = ErrorCode(238) // NotImplemented
 
+	// ErrNoSuchTransaction indicates that there is no in-progress multi-document transaction
+	// with the given transaction number.
+	ErrNoSuchTransaction = ErrorCode(251) // NoSuchTransaction
+
+	// ErrDuplicateKey indicates duplicate key violation.
+	ErrDuplicateKey = ErrorCode(11000) // DuplicateKey
+
+	// ErrMergeStageNoMatchingDocument indicates that $merge stage with whenNotMatched: "fail"
+	// found no matching document in the target collection.
+	ErrMergeStageNoMatchingDocument = ErrorCode(13113) // MergeStageNoMatchingDocument
+
+	// ErrMissingField indicates that a required field of the command or stage specification is missing.
+	ErrMissingField = ErrorCode(40414) // Location40414
+
 	// ErrFailedToParseInput indicates invalid input (absent or malformed fields).
 	ErrFailedToParseInput = ErrorCode(40415) // Location40415
 
+	// ErrStageGroupInvalidFields indicates that $group stage argument is not a document.
+	ErrStageGroupInvalidFields = ErrorCode(15947) // Location15947
+
+	// ErrStageGroupMissingID indicates that $group stage specification does not include _id.
+	ErrStageGroupMissingID = ErrorCode(15955) // Location15955
+
+	// ErrStageSkipNegative indicates that $skip stage argument is negative.
+	ErrStageSkipNegative = ErrorCode(15956) // Location15956
+
+	// ErrStageLimitInvalidArg indicates that $limit stage argument is not a whole number.
+	ErrStageLimitInvalidArg = ErrorCode(15957) // Location15957
+
+	// ErrStageLimitNotPositive indicates that $limit stage argument is not positive.
+	ErrStageLimitNotPositive = ErrorCode(15958) // Location15958
+
+	// ErrMatchBadExpression indicates that $match stage filter is not a document.
+	ErrMatchBadExpression = ErrorCode(15959) // Location15959
+
+	// ErrStageProjectBadExpression indicates that $project stage specification is not a document.
+	ErrStageProjectBadExpression = ErrorCode(15969) // Location15969
+
+	// ErrStageSkipInvalidArg indicates that $skip stage argument is not a whole number.
+	ErrStageSkipInvalidArg = ErrorCode(15972) // Location15972
+
+	// ErrSortBadExpression indicates that $sort stage specification is not a document.
+	ErrSortBadExpression = ErrorCode(15973) // Location15973
+
 	// ErrSortBadValue indicates bad value in sort input.
 	ErrSortBadValue = ErrorCode(15974) // Location15974
 
 	// ErrSortBadOrder indicates bad sort order input.
 	ErrSortBadOrder = ErrorCode(15975) // Location15975
 
-	// ErrInvalidArg indicates invalid argument in projection document.
+	// ErrSortMissingKey indicates that $sort stage specification is empty.
+	ErrSortMissingKey = ErrorCode(15976) // Location15976
+
+	// ErrExpressionWrongFields indicates that an operator expression document contains other fields.
+	ErrExpressionWrongFields = ErrorCode(15983) // Location15983
+
+	// ErrExpressionWrongLenOfArgs indicates that an aggregation expression got a wrong number of arguments.
+	ErrExpressionWrongLenOfArgs = ErrorCode(16020) // Location16020
+
+	// ErrExpressionSizeNotArray indicates that the argument of $size aggregation expression is not an array.
+	ErrExpressionSizeNotArray = ErrorCode(17124) // Location17124
+
+	// ErrInvalidArg indicates invalid argument in projection document
+	// or a wrong number of arguments of an aggregation expression with optional arguments.
 	ErrInvalidArg = ErrorCode(28667) // Location28667
 
+	// ErrExpressionAbsLongMin indicates that the argument of $abs aggregation expression is the minimal long value.
+	ErrExpressionAbsLongMin = ErrorCode(28680) // Location28680
+
+	// ErrExpressionSqrtNegative indicates that the argument of $sqrt aggregation expression is negative.
+	ErrExpressionSqrtNegative = ErrorCode(28714) // Location28714
+
 	// ErrSliceFirstArg for $slice indicates that the first argument is not an array.
 	ErrSliceFirstArg = ErrorCode(28724) // Location28724
 
+	// ErrExpressionNotNumeric indicates that the argument of a numeric aggregation expression is not a number.
+	ErrExpressionNotNumeric = ErrorCode(28765) // Location28765
+
+	// ErrProjectionPathCollision indicates that a projection field path is a prefix of another one.
+	ErrProjectionPathCollision = ErrorCode(31249) // Location31249
+
+	// ErrProjectionExpressionInEx indicates that an expression is used in exclusion projection.
+	ErrProjectionExpressionInEx = ErrorCode(31252) // Location31252
+
 	// ErrProjectionInEx for $elemMatch indicates that inclusion statement found
 	// while projection document already marked as exlusion.
 	ErrProjectionInEx = ErrorCode(31253) // Location31253
@@ -89,15 +191,115 @@ const (
 	// while projection document already marked as inclusion.
 	ErrProjectionExIn = ErrorCode(31254) // Location31254
 
+	// ErrStageCountNonString indicates that $count stage argument is not a string.
+	ErrStageCountNonString = ErrorCode(40156) // Location40156
+
+	// ErrStageCountNonEmptyString indicates that $count stage argument is an empty string.
+	ErrStageCountNonEmptyString = ErrorCode(40157) // Location40157
+
+	// ErrStageCountBadPrefix indicates that $count stage argument starts with $.
+	ErrStageCountBadPrefix = ErrorCode(40158) // Location40158
+
+	// ErrStageCountBadValue indicates that $count stage argument contains a dot.
+	ErrStageCountBadValue = ErrorCode(40160) // Location40160
+
+	// ErrStageGroupInvalidAccumulator indicates that $group stage field is not an accumulator document.
+	ErrStageGroupInvalidAccumulator = ErrorCode(40234) // Location40234
+
+	// ErrStageGroupUnaryOperator indicates that $group stage accumulator got an array of arguments.
+	ErrStageGroupUnaryOperator = ErrorCode(40237) // Location40237
+
+	// ErrStageGroupMultipleAccumulator indicates that $group stage field specifies more than one accumulator.
+	ErrStageGroupMultipleAccumulator = ErrorCode(40238) // Location40238
+
+	// ErrStageAddFieldsInvalidArg indicates that $addFields or $set stage argument is not a document.
+	ErrStageAddFieldsInvalidArg = ErrorCode(40272) // Location40272
+
+	// ErrStageInvalid indicates that aggregation pipeline stage is not a single-field document.
+	ErrStageInvalid = ErrorCode(40323) // Location40323
+
+	// ErrTimezoneUnknown indicates that a date expression got an unrecognized time zone.
+	ErrTimezoneUnknown = ErrorCode(40485) // Location40485
+
+	// ErrTimezoneNotString indicates that a date expression got a time zone that is not a string.
+	ErrTimezoneNotString = ErrorCode(40517) // Location40517
+
+	// ErrStageMergeNotLast indicates that $merge stage is not the last stage of the pipeline.
+	ErrStageMergeNotLast = ErrorCode(40601) // Location40601
+
+	// ErrStageNotFirst indicates that aggregation pipeline stage can only be the first one.
+	ErrStageNotFirst = ErrorCode(40602) // Location40602
+
 	// ErrFreeMonitoringDisabled indicates that free monitoring is disabled
 	// by command-line or config file.
 	ErrFreeMonitoringDisabled = ErrorCode(50840) // Location50840
 
+	// ErrExpressionRoundNotNumeric indicates that the argument of $round or $trunc aggregation expression
+	// is not a number.
+	ErrExpressionRoundNotNumeric = ErrorCode(51081) // Location51081
+
+	// ErrExpressionRoundPlaceNotIntegral indicates that the place argument of $round or $trunc
+	// aggregation expression is not a whole number.
+	ErrExpressionRoundPlaceNotIntegral = ErrorCode(51082) // Location51082
+
+	// ErrExpressionRoundPlaceOutOfRange indicates that the place argument of $round or $trunc
+	// aggregation expression is out of range.
+	ErrExpressionRoundPlaceOutOfRange = ErrorCode(51083) // Location51083
+
+	// ErrStageMergeOnFieldInvalid indicates that a document processed by $merge stage
+	// has an invalid value of the field used for matching.
+	ErrStageMergeOnFieldInvalid = ErrorCode(51132) // Location51132
+
+	// ErrStageMergeInvalidArg indicates that $merge stage argument is neither a string nor a document.
+	ErrStageMergeInvalidArg = ErrorCode(51182) // Location51182
+
+	// ErrProjectionEmptySubProjection indicates that a projection field value is an empty document.
+	ErrProjectionEmptySubProjection = ErrorCode(51270) // Location51270
+
+	// ErrProjectionEmpty indicates that $project stage specification is empty.
+	ErrProjectionEmpty = ErrorCode(51272) // Location51272
+
 	// ErrRegexOptions indicates regex options error.
 	ErrRegexOptions = ErrorCode(51075) // Location51075
 
 	// ErrRegexMissingParen indicates missing parentheses in regex expression.
 	ErrRegexMissingParen = ErrorCode(51091) // Location51091
+
+	// ErrDateArithmeticNotObject indicates that $dateAdd or $dateSubtract argument is not a document.
+	ErrDateArithmeticNotObject = ErrorCode(5166400) // Location5166400
+
+	// ErrDateArithmeticUnknownArg indicates that $dateAdd or $dateSubtract got an unknown argument.
+	ErrDateArithmeticUnknownArg = ErrorCode(5166401) // Location5166401
+
+	// ErrDateArithmeticMissingArg indicates that $dateAdd or $dateSubtract required argument is missing.
+	ErrDateArithmeticMissingArg = ErrorCode(5166402) // Location5166402
+
+	// ErrDateArithmeticBadStartDate indicates that $dateAdd or $dateSubtract startDate is not a date.
+	ErrDateArithmeticBadStartDate = ErrorCode(5166403) // Location5166403
+
+	// ErrDateArithmeticBadUnit indicates that $dateAdd or $dateSubtract unit is not a string.
+	ErrDateArithmeticBadUnit = ErrorCode(5166404) // Location5166404
+
+	// ErrDateArithmeticBadAmount indicates that $dateAdd or $dateSubtract amount is not a whole number.
+	ErrDateArithmeticBadAmount = ErrorCode(5166405) // Location5166405
+
+	// ErrAccumulatorNotObject indicates that the argument of an accumulator with n parameter is not a document.
+	ErrAccumulatorNotObject = ErrorCode(5787801) // Location5787801
+
+	// ErrAccumulatorUnknownArg indicates that an accumulator with n parameter got an unknown argument.
+	ErrAccumulatorUnknownArg = ErrorCode(5787901) // Location5787901
+
+	// ErrAccumulatorNNotIntegral indicates that n parameter of an accumulator is not a whole number.
+	ErrAccumulatorNNotIntegral = ErrorCode(5787902) // Location5787902
+
+	// ErrAccumulatorMissingN indicates that n parameter of an accumulator is missing.
+	ErrAccumulatorMissingN = ErrorCode(5787906) // Location5787906
+
+	// ErrAccumulatorMissingInput indicates that input, output or sortBy parameter of an accumulator is missing.
+	ErrAccumulatorMissingInput = ErrorCode(5787907) // Location5787907
+
+	// ErrAccumulatorNNotPositive indicates that n parameter of an accumulator is not positive.
+	ErrAccumulatorNNotPositive = ErrorCode(5787908) // Location5787908
 )
 
 // ProtoErr represents protocol error type.
@@ -128,7 +330,7 @@ func ProtocolError(err error) (ProtoErr, bool) {
 		return writeErr, true
 	}
 
-	return NewError(errInternalError, err).(*Error), false
+	return NewError(ErrInternalError, err).(*Error), false
 }
 
 // CommandError represents wire protocol command error.
@@ -275,7 +477,7 @@ func (we *WriteErrors) Append(err error, index int32) {
 		return
 	}
 
-	*we = append(*we, writeError{err: err.Error(), code: errInternalError, index: &index})
+	*we = append(*we, writeError{err: err.Error(), code: ErrInternalError, index: &index})
 }
 
 // writeError represents protocol write error.
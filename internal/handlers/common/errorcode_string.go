@@ -9,56 +9,186 @@ func _() {
 	// Re-run the stringer command to generate them again.
 	var x [1]struct{}
 	_ = x[errUnset-0]
-	_ = x[errInternalError-1]
+	_ = x[ErrInternalError-1]
 	_ = x[ErrBadValue-2]
 	_ = x[ErrFailedToParse-9]
+	_ = x[ErrUnauthorized-13]
 	_ = x[ErrTypeMismatch-14]
+	_ = x[ErrOverflow-15]
+	_ = x[ErrInvalidLength-16]
+	_ = x[ErrIllegalOperation-20]
 	_ = x[ErrNamespaceNotFound-26]
+	_ = x[ErrIndexNotFound-27]
 	_ = x[ErrUnsuitableValueType-28]
 	_ = x[ErrConflictingUpdateOperators-40]
+	_ = x[ErrCursorNotFound-43]
 	_ = x[ErrNamespaceExists-48]
 	_ = x[ErrCommandNotFound-59]
+	_ = x[ErrCannotCreateIndex-67]
+	_ = x[ErrImmutableField-66]
+	_ = x[ErrInvalidOptions-72]
 	_ = x[ErrInvalidNamespace-73]
+	_ = x[ErrIndexOptionsConflict-85]
+	_ = x[ErrIndexKeySpecsConflict-86]
 	_ = x[ErrDocumentValidationFailure-121]
 	_ = x[ErrNotImplemented-238]
+	_ = x[ErrNoSuchTransaction-251]
+	_ = x[ErrDuplicateKey-11000]
+	_ = x[ErrMergeStageNoMatchingDocument-13113]
+	_ = x[ErrMissingField-40414]
 	_ = x[ErrFailedToParseInput-40415]
+	_ = x[ErrStageGroupInvalidFields-15947]
+	_ = x[ErrStageGroupMissingID-15955]
+	_ = x[ErrStageSkipNegative-15956]
+	_ = x[ErrStageLimitInvalidArg-15957]
+	_ = x[ErrStageLimitNotPositive-15958]
+	_ = x[ErrMatchBadExpression-15959]
+	_ = x[ErrStageProjectBadExpression-15969]
+	_ = x[ErrStageSkipInvalidArg-15972]
+	_ = x[ErrSortBadExpression-15973]
 	_ = x[ErrSortBadValue-15974]
 	_ = x[ErrSortBadOrder-15975]
+	_ = x[ErrSortMissingKey-15976]
+	_ = x[ErrExpressionWrongFields-15983]
+	_ = x[ErrExpressionWrongLenOfArgs-16020]
+	_ = x[ErrExpressionSizeNotArray-17124]
 	_ = x[ErrInvalidArg-28667]
+	_ = x[ErrExpressionAbsLongMin-28680]
+	_ = x[ErrExpressionSqrtNegative-28714]
 	_ = x[ErrSliceFirstArg-28724]
+	_ = x[ErrExpressionNotNumeric-28765]
+	_ = x[ErrProjectionPathCollision-31249]
+	_ = x[ErrProjectionExpressionInEx-31252]
 	_ = x[ErrProjectionInEx-31253]
 	_ = x[ErrProjectionExIn-31254]
+	_ = x[ErrStageCountNonString-40156]
+	_ = x[ErrStageCountNonEmptyString-40157]
+	_ = x[ErrStageCountBadPrefix-40158]
+	_ = x[ErrStageCountBadValue-40160]
+	_ = x[ErrStageGroupInvalidAccumulator-40234]
+	_ = x[ErrStageGroupUnaryOperator-40237]
+	_ = x[ErrStageGroupMultipleAccumulator-40238]
+	_ = x[ErrStageAddFieldsInvalidArg-40272]
+	_ = x[ErrStageInvalid-40323]
+	_ = x[ErrTimezoneUnknown-40485]
+	_ = x[ErrTimezoneNotString-40517]
+	_ = x[ErrStageMergeNotLast-40601]
+	_ = x[ErrStageNotFirst-40602]
 	_ = x[ErrFreeMonitoringDisabled-50840]
+	_ = x[ErrExpressionRoundNotNumeric-51081]
+	_ = x[ErrExpressionRoundPlaceNotIntegral-51082]
+	_ = x[ErrExpressionRoundPlaceOutOfRange-51083]
+	_ = x[ErrStageMergeOnFieldInvalid-51132]
+	_ = x[ErrStageMergeInvalidArg-51182]
+	_ = x[ErrProjectionEmptySubProjection-51270]
+	_ = x[ErrProjectionEmpty-51272]
 	_ = x[ErrRegexOptions-51075]
 	_ = x[ErrRegexMissingParen-51091]
+	_ = x[ErrDateArithmeticNotObject-5166400]
+	_ = x[ErrDateArithmeticUnknownArg-5166401]
+	_ = x[ErrDateArithmeticMissingArg-5166402]
+	_ = x[ErrDateArithmeticBadStartDate-5166403]
+	_ = x[ErrDateArithmeticBadUnit-5166404]
+	_ = x[ErrDateArithmeticBadAmount-5166405]
+	_ = x[ErrAccumulatorNotObject-5787801]
+	_ = x[ErrAccumulatorUnknownArg-5787901]
+	_ = x[ErrAccumulatorNNotIntegral-5787902]
+	_ = x[ErrAccumulatorMissingN-5787906]
+	_ = x[ErrAccumulatorMissingInput-5787907]
+	_ = x[ErrAccumulatorNNotPositive-5787908]
 }
 
-const _ErrorCode_name = "UnsetInternalErrorBadValueFailedToParseTypeMismatchNamespaceNotFoundUnsuitableValueTypeConflictingUpdateOperatorsNamespaceExistsCommandNotFoundInvalidNamespaceDocumentValidationFailureNotImplementedLocation15974Location15975Location28667Location28724Location31253Location31254Location40415Location50840Location51075Location51091"
+const _ErrorCode_name = "UnsetInternalErrorBadValueFailedToParseUnauthorizedTypeMismatchOverflowInvalidLengthIllegalOperationNamespaceNotFoundIndexNotFoundUnsuitableValueTypeConflictingUpdateOperatorsCursorNotFoundNamespaceExistsCommandNotFoundImmutableFieldCannotCreateIndexInvalidOptionsInvalidNamespaceIndexOptionsConflictIndexKeySpecsConflictDocumentValidationFailureNotImplementedNoSuchTransactionDuplicateKeyMergeStageNoMatchingDocumentLocation15947Location15955Location15956Location15957Location15958Location15959Location15969Location15972Location15973Location15974Location15975Location15976Location15983Location16020Location17124Location28667Location28680Location28714Location28724Location28765Location31249Location31252Location31253Location31254Location40156Location40157Location40158Location40160Location40234Location40237Location40238Location40272Location40323Location40414Location40415Location40485Location40517Location40601Location40602Location50840Location51075Location51081Location51082Location51083Location51091Location51132Location51182Location51270Location51272Location5166400Location5166401Location5166402Location5166403Location5166404Location5166405Location5787801Location5787901Location5787902Location5787906Location5787907Location5787908"
 
 var _ErrorCode_map = map[ErrorCode]string{
-	0:     _ErrorCode_name[0:5],
-	1:     _ErrorCode_name[5:18],
-	2:     _ErrorCode_name[18:26],
-	9:     _ErrorCode_name[26:39],
-	14:    _ErrorCode_name[39:51],
-	26:    _ErrorCode_name[51:68],
-	28:    _ErrorCode_name[68:87],
-	40:    _ErrorCode_name[87:113],
-	48:    _ErrorCode_name[113:128],
-	59:    _ErrorCode_name[128:143],
-	73:    _ErrorCode_name[143:159],
-	121:   _ErrorCode_name[159:184],
-	238:   _ErrorCode_name[184:198],
-	15974: _ErrorCode_name[198:211],
-	15975: _ErrorCode_name[211:224],
-	28667: _ErrorCode_name[224:237],
-	28724: _ErrorCode_name[237:250],
-	31253: _ErrorCode_name[250:263],
-	31254: _ErrorCode_name[263:276],
-	40415: _ErrorCode_name[276:289],
-	50840: _ErrorCode_name[289:302],
-	51075: _ErrorCode_name[302:315],
-	51091: _ErrorCode_name[315:328],
+	0:       _ErrorCode_name[0:5],
+	1:       _ErrorCode_name[5:18],
+	2:       _ErrorCode_name[18:26],
+	9:       _ErrorCode_name[26:39],
+	13:      _ErrorCode_name[39:51],
+	14:      _ErrorCode_name[51:63],
+	15:      _ErrorCode_name[63:71],
+	16:      _ErrorCode_name[71:84],
+	20:      _ErrorCode_name[84:100],
+	26:      _ErrorCode_name[100:117],
+	27:      _ErrorCode_name[117:130],
+	28:      _ErrorCode_name[130:149],
+	40:      _ErrorCode_name[149:175],
+	43:      _ErrorCode_name[175:189],
+	48:      _ErrorCode_name[189:204],
+	59:      _ErrorCode_name[204:219],
+	66:      _ErrorCode_name[219:233],
+	67:      _ErrorCode_name[233:250],
+	72:      _ErrorCode_name[250:264],
+	73:      _ErrorCode_name[264:280],
+	85:      _ErrorCode_name[280:300],
+	86:      _ErrorCode_name[300:321],
+	121:     _ErrorCode_name[321:346],
+	238:     _ErrorCode_name[346:360],
+	251:     _ErrorCode_name[360:377],
+	11000:   _ErrorCode_name[377:389],
+	13113:   _ErrorCode_name[389:417],
+	15947:   _ErrorCode_name[417:430],
+	15955:   _ErrorCode_name[430:443],
+	15956:   _ErrorCode_name[443:456],
+	15957:   _ErrorCode_name[456:469],
+	15958:   _ErrorCode_name[469:482],
+	15959:   _ErrorCode_name[482:495],
+	15969:   _ErrorCode_name[495:508],
+	15972:   _ErrorCode_name[508:521],
+	15973:   _ErrorCode_name[521:534],
+	15974:   _ErrorCode_name[534:547],
+	15975:   _ErrorCode_name[547:560],
+	15976:   _ErrorCode_name[560:573],
+	15983:   _ErrorCode_name[573:586],
+	16020:   _ErrorCode_name[586:599],
+	17124:   _ErrorCode_name[599:612],
+	28667:   _ErrorCode_name[612:625],
+	28680:   _ErrorCode_name[625:638],
+	28714:   _ErrorCode_name[638:651],
+	28724:   _ErrorCode_name[651:664],
+	28765:   _ErrorCode_name[664:677],
+	31249:   _ErrorCode_name[677:690],
+	31252:   _ErrorCode_name[690:703],
+	31253:   _ErrorCode_name[703:716],
+	31254:   _ErrorCode_name[716:729],
+	40156:   _ErrorCode_name[729:742],
+	40157:   _ErrorCode_name[742:755],
+	40158:   _ErrorCode_name[755:768],
+	40160:   _ErrorCode_name[768:781],
+	40234:   _ErrorCode_name[781:794],
+	40237:   _ErrorCode_name[794:807],
+	40238:   _ErrorCode_name[807:820],
+	40272:   _ErrorCode_name[820:833],
+	40323:   _ErrorCode_name[833:846],
+	40414:   _ErrorCode_name[846:859],
+	40415:   _ErrorCode_name[859:872],
+	40485:   _ErrorCode_name[872:885],
+	40517:   _ErrorCode_name[885:898],
+	40601:   _ErrorCode_name[898:911],
+	40602:   _ErrorCode_name[911:924],
+	50840:   _ErrorCode_name[924:937],
+	51075:   _ErrorCode_name[937:950],
+	51081:   _ErrorCode_name[950:963],
+	51082:   _ErrorCode_name[963:976],
+	51083:   _ErrorCode_name[976:989],
+	51091:   _ErrorCode_name[989:1002],
+	51132:   _ErrorCode_name[1002:1015],
+	51182:   _ErrorCode_name[1015:1028],
+	51270:   _ErrorCode_name[1028:1041],
+	51272:   _ErrorCode_name[1041:1054],
+	5166400: _ErrorCode_name[1054:1069],
+	5166401: _ErrorCode_name[1069:1084],
+	5166402: _ErrorCode_name[1084:1099],
+	5166403: _ErrorCode_name[1099:1114],
+	5166404: _ErrorCode_name[1114:1129],
+	5166405: _ErrorCode_name[1129:1144],
+	5787801: _ErrorCode_name[1144:1159],
+	5787901: _ErrorCode_name[1159:1174],
+	5787902: _ErrorCode_name[1174:1189],
+	5787906: _ErrorCode_name[1189:1204],
+	5787907: _ErrorCode_name[1204:1219],
+	5787908: _ErrorCode_name[1219:1234],
 }
 
 func (i ErrorCode) String() string {
@@ -55,39 +55,7 @@ func FilterDocument(doc, filter *types.Document) (bool, error) {
 func filterDocumentPair(doc *types.Document, filterKey string, filterValue any) (bool, error) {
 	if strings.ContainsRune(filterKey, '.') {
 		// {field1./.../.fieldN: filterValue}
-		path := types.NewPathFromString(filterKey)
-		// we pass the path without the last key because we want {fieldN: *someValue*}, not just *someValue*
-		docValue, err := doc.GetByPath(path.TrimSuffix())
-		if err != nil {
-			return false, nil // no error - the field is just not present
-		}
-
-		switch docValue := docValue.(type) {
-		case *types.Document:
-			doc = docValue
-			filterKey = path.Suffix()
-		case *types.Array:
-			index, err := strconv.Atoi(path.Suffix())
-			if err != nil {
-				return false, nil
-			}
-
-			if docValue.Len() == 0 || docValue.Len() <= index {
-				return false, nil
-			}
-
-			value, err := docValue.Get(index)
-			if err != nil {
-				return false, err
-			}
-
-			if _, ok := value.(*types.Array); ok {
-				result := types.Compare(value, filterValue)
-				return types.ContainsCompareResult(result, types.Equal), nil
-			}
-
-			doc = must.NotFail(types.NewDocument(filterKey, value))
-		}
+		return filterDottedPair(doc, types.NewPathFromString(filterKey), filterValue)
 	}
 
 	if strings.HasPrefix(filterKey, "$") {
@@ -106,8 +74,7 @@ func filterDocumentPair(doc *types.Document, filterKey string, filterValue any)
 		if err != nil {
 			return false, nil // no error - the field is just not present
 		}
-		result := types.Compare(docValue, filterValue)
-		return types.ContainsCompareResult(result, types.Equal), nil
+		return filterFieldArray(docValue, filterValue), nil
 
 	case types.Regex:
 		// {field: /regex/}
@@ -128,9 +95,197 @@ func filterDocumentPair(doc *types.Document, filterKey string, filterValue any)
 			return false, nil // no error - the field is just not present
 		}
 
-		result := types.Compare(docValue, filterValue)
-		return types.ContainsCompareResult(result, types.Equal), nil
+		return filterFieldScalar(docValue, filterValue), nil
+	}
+}
+
+// filterDottedPair handles {field1./.../.fieldN: filterValue} filter.
+//
+// Like in MongoDB, arrays on the path are traversed implicitly:
+// a numeric path element selects an array element by index,
+// and any path element is applied to every document in the array.
+// The filter matches if it matches any of the found values.
+// Negative operators ($ne, $nin and {$exists: false}) match only if none of the found values matches
+// the corresponding positive operator.
+func filterDottedPair(doc *types.Document, path types.Path, filterValue any) (bool, error) {
+	key := path.Suffix()
+
+	containers := pathContainers(doc, path.TrimSuffix().Slice(), key, nil)
+	if len(containers) == 0 {
+		// the field is just not present; that still matches nulls, {$ne: value} and similar filters
+		containers = []*types.Document{must.NotFail(types.NewDocument())}
+	}
+
+	expr, ok := filterValue.(*types.Document)
+	if !ok || expr.Len() == 0 || !strings.HasPrefix(expr.Keys()[0], "$") {
+		return filterContainers(containers, key, filterValue)
+	}
+
+	// {field1./.../.fieldN: {$operator: value, ...}}, operators are ANDed together
+	for _, op := range expr.Keys() {
+		if op == "$options" {
+			// handled by $regex
+			continue
+		}
+
+		opValue := must.NotFail(expr.Get(op))
+
+		var matches bool
+		var err error
+
+		switch op {
+		case "$ne":
+			if _, ok := opValue.(types.Regex); ok {
+				return false, NewErrorMsg(ErrBadValue, "Can't have regex as arg to $ne.")
+			}
+
+			matches, err = filterContainers(containers, key, must.NotFail(types.NewDocument("$eq", opValue)))
+			matches = !matches
+		case "$nin":
+			if _, ok := opValue.(*types.Array); !ok {
+				return false, NewErrorMsg(ErrBadValue, "$nin needs an array")
+			}
+
+			matches, err = filterContainers(containers, key, must.NotFail(types.NewDocument("$in", opValue)))
+			matches = !matches
+		case "$exists":
+			var exists bool
+			if exists, err = filterContainers(containers, key, must.NotFail(types.NewDocument(op, true))); err == nil {
+				matches, err = filterFieldExprExists(exists, opValue)
+			}
+		case "$regex":
+			opExpr := must.NotFail(types.NewDocument(op, opValue))
+			if options, err := expr.Get("$options"); err == nil {
+				must.NoError(opExpr.Set("$options", options))
+			}
+			matches, err = filterContainers(containers, key, opExpr)
+		default:
+			matches, err = filterContainers(containers, key, must.NotFail(types.NewDocument(op, opValue)))
+		}
+
+		if err != nil {
+			return false, err
+		}
+
+		if !matches {
+			return false, nil
+		}
+	}
+
+	return true, nil
+}
+
+// filterContainers returns true if {key: filterValue} filter matches any of the given documents.
+func filterContainers(containers []*types.Document, key string, filterValue any) (bool, error) {
+	for _, c := range containers {
+		matches, err := filterDocumentPair(c, key, filterValue)
+		if err != nil {
+			return false, err
+		}
+
+		if matches {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+// pathContainers appends to res all documents (or single-element documents for array elements selected by index)
+// that could contain the given key at the given path of the value, and returns the extended slice.
+//
+// Arrays on the path are traversed implicitly, but arrays of arrays are not.
+func pathContainers(value any, path []string, key string, res []*types.Document) []*types.Document {
+	if len(path) == 0 {
+		switch value := value.(type) {
+		case *types.Document:
+			res = append(res, value)
+
+		case *types.Array:
+			if index, err := strconv.Atoi(key); err == nil {
+				if elem, err := value.Get(index); err == nil {
+					res = append(res, must.NotFail(types.NewDocument(key, elem)))
+				}
+			}
+
+			for i := 0; i < value.Len(); i++ {
+				if elem, ok := must.NotFail(value.Get(i)).(*types.Document); ok {
+					res = append(res, elem)
+				}
+			}
+		}
+
+		return res
+	}
+
+	switch value := value.(type) {
+	case *types.Document:
+		v, err := value.Get(path[0])
+		if err != nil {
+			return res
+		}
+
+		return pathContainers(v, path[1:], key, res)
+
+	case *types.Array:
+		if index, err := strconv.Atoi(path[0]); err == nil {
+			if elem, err := value.Get(index); err == nil {
+				res = pathContainers(elem, path[1:], key, res)
+			}
+		}
+
+		for i := 0; i < value.Len(); i++ {
+			if elem, ok := must.NotFail(value.Get(i)).(*types.Document); ok {
+				res = pathContainers(elem, path, key, res)
+			}
+		}
+	}
+
+	return res
+}
+
+// filterFieldArray handles {field: [array]} filter.
+//
+// Like in MongoDB, it matches if the field value is an array equal to the filter array
+// (elements are compared in order), or if the field value is an array
+// that contains an element equal to the filter array.
+func filterFieldArray(fieldValue any, filterArr *types.Array) bool {
+	arr, ok := fieldValue.(*types.Array)
+	if !ok {
+		return false
+	}
+
+	if matchArrays(arr, filterArr) {
+		return true
+	}
+
+	for i := 0; i < arr.Len(); i++ {
+		if elem, ok := must.NotFail(arr.Get(i)).(*types.Array); ok && matchArrays(elem, filterArr) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// filterFieldScalar handles {field: value} filter where value is not an array, document or regex.
+//
+// Like in MongoDB, it matches if the field value is equal to the filter value,
+// or if the field value is an array that contains an element equal to the filter value.
+// Elements of embedded arrays are not checked.
+func filterFieldScalar(fieldValue, filterValue any) bool {
+	arr, ok := fieldValue.(*types.Array)
+	if !ok {
+		return matchValues(fieldValue, filterValue)
+	}
+
+	for i := 0; i < arr.Len(); i++ {
+		if matchValues(must.NotFail(arr.Get(i)), filterValue) {
+			return true
+		}
 	}
+
+	return false
 }
 
 // filterOperator handles a top-level operator filter {$operator: filterValue}.
@@ -246,31 +401,17 @@ func filterFieldExpr(doc *types.Document, filterKey string, expr *types.Document
 
 		fieldValue, err := doc.Get(filterKey)
 		if err != nil && exprKey != "$exists" && exprKey != "$not" {
-			// comparing not existent field with null should return true
-			if _, ok := exprValue.(types.NullType); ok {
-				return true, nil
+			// the field is not present, so there is nothing to compare with,
+			// but the operator's value is still validated, so errors do not depend on documents
+			if err = validateFieldExprValue(expr, exprKey, exprValue); err != nil {
+				return false, err
 			}
 
-			// comparing not existent field with {$all: [null, null, ..., null]} should return true
-			// (at least one null needs to be presented in the $all array)
-			if exprKey == "$all" {
-				all, ok := exprValue.(*types.Array)
-				if ok && all.Len() > 0 {
-					isNull := true
-					for i := 0; i < all.Len(); i++ {
-						if _, ok := must.NotFail(all.Get(i)).(types.NullType); !ok {
-							isNull = false
-							break
-						}
-					}
-					if isNull {
-						return true, nil
-					}
-				}
+			if !filterMissingField(exprKey, exprValue) {
+				return false, nil
 			}
 
-			// exit when not $exists or $not filters and no such field
-			return false, nil
+			continue
 		}
 
 		if !strings.HasPrefix(exprKey, "$") {
@@ -366,37 +507,9 @@ func filterFieldExpr(doc *types.Document, filterKey string, expr *types.Document
 				return false, NewErrorMsg(ErrBadValue, "$in needs an array")
 			}
 
-			var found bool
-			for i := 0; i < arr.Len(); i++ {
-				if found {
-					break
-				}
-
-				switch arrValue := must.NotFail(arr.Get(i)).(type) {
-				case *types.Document:
-					for _, key := range arrValue.Keys() {
-						if strings.HasPrefix(key, "$") {
-							return false, NewErrorMsg(ErrBadValue, "cannot nest $ under $in")
-						}
-					}
-					fieldValue, ok := fieldValue.(*types.Document)
-					if ok && matchDocuments(fieldValue, arrValue) {
-						found = true
-					}
-				case types.Regex:
-					match, err := filterFieldRegex(fieldValue, arrValue)
-					switch {
-					case err != nil:
-						return false, err
-					case match:
-						found = true
-					}
-				default:
-					result := types.Compare(fieldValue, arrValue)
-					if types.ContainsCompareResult(result, types.Equal) {
-						found = true
-					}
-				}
+			found, err := filterFieldExprIn(fieldValue, arr)
+			if err != nil {
+				return false, err
 			}
 
 			if !found {
@@ -410,37 +523,9 @@ func filterFieldExpr(doc *types.Document, filterKey string, expr *types.Document
 				return false, NewErrorMsg(ErrBadValue, "$nin needs an array")
 			}
 
-			var found bool
-			for i := 0; i < arr.Len(); i++ {
-				if found {
-					break
-				}
-
-				switch arrValue := must.NotFail(arr.Get(i)).(type) {
-				case *types.Document:
-					for _, key := range arrValue.Keys() {
-						if strings.HasPrefix(key, "$") {
-							return false, NewErrorMsg(ErrBadValue, "cannot nest $ under $in")
-						}
-					}
-					fieldValue, ok := fieldValue.(*types.Document)
-					if ok && matchDocuments(fieldValue, arrValue) {
-						found = true
-					}
-				case types.Regex:
-					match, err := filterFieldRegex(fieldValue, arrValue)
-					switch {
-					case err != nil:
-						return false, err
-					case match:
-						found = true
-					}
-				default:
-					result := types.Compare(fieldValue, arrValue)
-					if types.ContainsCompareResult(result, types.Equal) {
-						found = true
-					}
-				}
+			found, err := filterFieldExprIn(fieldValue, arr)
+			if err != nil {
+				return false, err
 			}
 
 			if found {
@@ -489,7 +574,7 @@ func filterFieldExpr(doc *types.Document, filterKey string, expr *types.Document
 
 		case "$all":
 			// {field: {$all: [value, another_value, ...]}}
-			res, err := filterFieldExprAll(fieldValue, exprValue)
+			res, err := filterFieldExprAll(doc, filterKey, fieldValue, exprValue)
 			if !res || err != nil {
 				return false, err
 			}
@@ -551,13 +636,208 @@ func filterFieldExpr(doc *types.Document, filterKey string, expr *types.Document
 	return true, nil
 }
 
+// validateFieldExprValue returns an error if the value of {field: {exprKey: exprValue}} expression is invalid.
+//
+// It is used for missing fields; for present fields, values are validated by the operators' functions.
+func validateFieldExprValue(expr *types.Document, exprKey string, exprValue any) error {
+	var err error
+
+	// functions below validate the value first and never match a missing (nil) field value
+	switch exprKey {
+	case "$in", "$nin":
+		if _, ok := exprValue.(*types.Array); !ok {
+			return NewErrorMsg(ErrBadValue, exprKey+" needs an array")
+		}
+
+	case "$all":
+		arr, ok := exprValue.(*types.Array)
+		if !ok {
+			return NewErrorMsg(ErrBadValue, "$all needs an array")
+		}
+
+		_, err = validateAll(arr)
+
+	case "$regex":
+		optionsAny, _ := expr.Get("$options")
+		_, err = filterFieldExprRegex(nil, exprValue, optionsAny)
+
+	case "$size":
+		_, err = filterFieldExprSize(nil, exprValue)
+
+	case "$mod":
+		_, err = filterFieldMod(nil, exprValue)
+
+	case "$bitsAllClear":
+		_, err = filterFieldExprBitsAllClear(nil, exprValue)
+
+	case "$bitsAllSet":
+		_, err = filterFieldExprBitsAllSet(nil, exprValue)
+
+	case "$bitsAnyClear":
+		_, err = filterFieldExprBitsAnyClear(nil, exprValue)
+
+	case "$bitsAnySet":
+		_, err = filterFieldExprBitsAnySet(nil, exprValue)
+
+	case "$type":
+		_, err = getTypeCodes(exprValue)
+	}
+
+	return err
+}
+
+// filterMissingField returns true if not present field matches {field: {exprKey: exprValue}} expression.
+//
+// Like in MongoDB, missing field is considered equal to null:
+// {$eq: null}, {$gte: null}, {$lte: null}, {$in: [..., null]} and {$all: [null, ...]} match it,
+// while {$ne: null} and {$nin: [..., null]} do not. {$ne: value} and {$nin: [values]} without null match it too.
+func filterMissingField(exprKey string, exprValue any) bool {
+	switch exprKey {
+	case "$eq", "$gte", "$lte":
+		_, isNull := exprValue.(types.NullType)
+		return isNull
+
+	case "$ne":
+		_, isNull := exprValue.(types.NullType)
+		return !isNull
+
+	case "$in", "$nin":
+		arr, ok := exprValue.(*types.Array)
+		if !ok {
+			return false
+		}
+
+		var hasNull bool
+		for i := 0; i < arr.Len(); i++ {
+			if _, ok := must.NotFail(arr.Get(i)).(types.NullType); ok {
+				hasNull = true
+				break
+			}
+		}
+
+		if exprKey == "$in" {
+			return hasNull
+		}
+
+		return !hasNull
+
+	case "$all":
+		// at least one null needs to be presented in the $all array, and nothing else
+		all, ok := exprValue.(*types.Array)
+		if !ok || all.Len() == 0 {
+			return false
+		}
+
+		for i := 0; i < all.Len(); i++ {
+			if _, ok := must.NotFail(all.Get(i)).(types.NullType); !ok {
+				return false
+			}
+		}
+
+		return true
+
+	default:
+		return false
+	}
+}
+
+// filterFieldExprIn returns true if the field value matches any value of $in or $nin array.
+//
+// Regular expressions match string values; other values match if they are equal according to types.Compare,
+// so values of different BSON types never match, except numbers.
+// If the field value is an array, it matches if the whole array or any of its elements matches.
+// Missing fields are handled by filterMissingField.
+func filterFieldExprIn(fieldValue any, arr *types.Array) (bool, error) {
+	for i := 0; i < arr.Len(); i++ {
+		switch arrValue := must.NotFail(arr.Get(i)).(type) {
+		case *types.Document:
+			for _, key := range arrValue.Keys() {
+				if strings.HasPrefix(key, "$") {
+					// the same message is used for $nin, like in MongoDB
+					return false, NewErrorMsg(ErrBadValue, "cannot nest $ under $in")
+				}
+			}
+
+			if equalOrContains(fieldValue, arrValue) {
+				return true, nil
+			}
+
+		case types.Regex:
+			match, err := filterFieldRegex(fieldValue, arrValue)
+			if err != nil {
+				return false, err
+			}
+
+			if match {
+				return true, nil
+			}
+
+		default:
+			if equalOrContains(fieldValue, arrValue) {
+				return true, nil
+			}
+		}
+	}
+
+	return false, nil
+}
+
+// equalOrContains returns true if the field value is equal to the given value,
+// or if the field value is an array containing an element equal to the given value.
+func equalOrContains(fieldValue, value any) bool {
+	fieldArr, ok := fieldValue.(*types.Array)
+	if !ok {
+		return equal(fieldValue, value)
+	}
+
+	if equal(fieldArr, value) {
+		return true
+	}
+
+	for i := 0; i < fieldArr.Len(); i++ {
+		if equal(must.NotFail(fieldArr.Get(i)), value) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// equal returns true if both values are equal documents, arrays with equal elements, or equal scalars.
+func equal(a, b any) bool {
+	switch a := a.(type) {
+	case *types.Document:
+		b, ok := b.(*types.Document)
+		return ok && matchDocuments(a, b)
+
+	case *types.Array:
+		b, ok := b.(*types.Array)
+		if !ok || a.Len() != b.Len() {
+			return false
+		}
+
+		for i := 0; i < a.Len(); i++ {
+			if !equal(must.NotFail(a.Get(i)), must.NotFail(b.Get(i))) {
+				return false
+			}
+		}
+
+		return true
+
+	default:
+		switch b.(type) {
+		case *types.Document, *types.Array:
+			return false
+		}
+
+		return types.ContainsCompareResult(types.Compare(a, b), types.Equal)
+	}
+}
+
 // filterFieldRegex handles {field: /regex/} filter. Provides regular expression capabilities
 // for pattern matching strings in queries, even if the strings are in an array.
 func filterFieldRegex(fieldValue any, regex types.Regex) (bool, error) {
 	re, err := regex.Compile()
-	if err != nil && err == types.ErrOptionNotImplemented {
-		return false, NewErrorMsg(ErrNotImplemented, `option 'x' not implemented`)
-	}
 	if err != nil {
 		return false, NewError(ErrRegexMissingParen, err)
 	}
@@ -620,11 +900,7 @@ func filterFieldExprRegex(fieldValue any, regexValue, optionsValue any) (bool, e
 
 // filterFieldExprSize handles {field: {$size: sizeValue}} filter.
 func filterFieldExprSize(fieldValue any, sizeValue any) (bool, error) {
-	arr, ok := fieldValue.(*types.Array)
-	if !ok {
-		return false, nil
-	}
-
+	// validate the value first, so errors do not depend on documents
 	size, err := GetWholeNumberParam(sizeValue)
 	if err != nil {
 		switch err {
@@ -641,6 +917,12 @@ func filterFieldExprSize(fieldValue any, sizeValue any) (bool, error) {
 		return false, NewErrorMsg(ErrBadValue, "$size may not be negative")
 	}
 
+	// scalar values never match
+	arr, ok := fieldValue.(*types.Array)
+	if !ok {
+		return false, nil
+	}
+
 	if arr.Len() != int(size) {
 		return false, nil
 	}
@@ -651,238 +933,231 @@ func filterFieldExprSize(fieldValue any, sizeValue any) (bool, error) {
 // filterFieldExprAll handles {field: {$all: [value, another_value, ...]}} filter.
 // The main purpose of $all is to filter arrays.
 // It is possible to filter non-arrays: {field: {$all: [value]}}, but such statement is equivalent to {field: value}.
-func filterFieldExprAll(fieldValue any, allValue any) (bool, error) {
+//
+// If the first value is {$elemMatch: ...} expression, all values should be such expressions,
+// and each of them should match some element of the array.
+// Empty $all array matches nothing.
+func filterFieldExprAll(doc *types.Document, filterKey string, fieldValue any, allValue any) (bool, error) {
 	query, ok := allValue.(*types.Array)
 	if !ok {
 		return false, NewErrorMsg(ErrBadValue, "$all needs an array")
 	}
 
-	if query.Len() == 0 {
-		return false, nil
+	elemMatch, err := validateAll(query)
+	if err != nil {
+		return false, err
 	}
 
-	switch value := fieldValue.(type) {
-	case *types.Document:
-		// For documents we return false as $all doesn't work on documents.
+	if query.Len() == 0 {
 		return false, nil
+	}
 
-	case *types.Array:
-		// For arrays we check that the array contains all the elements of the query.
-		return value.ContainsAll(query), nil
-
-	default:
-		// For other types (scalars) we check that the value is equal to each scalar in the query.
-		// Example: value: 42, query: [42, 42] should give us `true`
+	if elemMatch {
 		for i := 0; i < query.Len(); i++ {
-			res := types.Compare(value, must.NotFail(query.Get(i)))
-			if !slices.Contains(res, types.Equal) || len(res) != 1 {
-				return false, nil
+			expr := must.NotFail(must.NotFail(query.Get(i)).(*types.Document).Get("$elemMatch"))
+
+			res, err := filterFieldExprElemMatch(doc, filterKey, expr)
+			if !res || err != nil {
+				return false, err
 			}
 		}
+
 		return true, nil
 	}
-}
 
-// filterFieldExprBitsAllClear handles {field: {$bitsAllClear: value}} filter.
-func filterFieldExprBitsAllClear(fieldValue, maskValue any) (bool, error) {
-	switch value := fieldValue.(type) {
-	case float64:
-		// TODO check float negative zero
-		if value != math.Trunc(value) ||
-			math.IsNaN(value) ||
-			math.IsInf(value, 0) ||
-			value > math.MaxInt64 {
+	// values are compared the same way as by $eq and $in
+	for i := 0; i < query.Len(); i++ {
+		if !equalOrContains(fieldValue, must.NotFail(query.Get(i))) {
 			return false, nil
 		}
+	}
 
-		bitmask, err := getBinaryMaskParam(maskValue)
-		if err != nil {
-			return false, formatBitwiseOperatorErr(err, "$bitsAllClear", maskValue)
-		}
+	return true, nil
+}
 
-		return (^uint64(value) & bitmask) == bitmask, nil
+// validateAll checks $all array values and reports whether they are {$elemMatch: ...} expressions.
+func validateAll(query *types.Array) (bool, error) {
+	if query.Len() == 0 {
+		return false, nil
+	}
 
-	case types.Binary:
-		// TODO: https://github.com/FerretDB/FerretDB/issues/508
-		return false, NewErrorMsg(ErrNotImplemented, "BinData() not supported yet")
+	isElemMatch := func(v any) bool {
+		d, ok := v.(*types.Document)
+		return ok && d.Len() > 0 && d.Keys()[0] == "$elemMatch"
+	}
 
-	case int32:
-		bitmask, err := getBinaryMaskParam(maskValue)
-		if err != nil {
-			return false, formatBitwiseOperatorErr(err, "$bitsAllClear", maskValue)
-		}
+	elemMatch := isElemMatch(must.NotFail(query.Get(0)))
 
-		return (^uint64(value) & bitmask) == bitmask, nil
+	for i := 0; i < query.Len(); i++ {
+		v := must.NotFail(query.Get(i))
 
-	case int64:
-		bitmask, err := getBinaryMaskParam(maskValue)
-		if err != nil {
-			return false, formatBitwiseOperatorErr(err, "$bitsAllClear", maskValue)
+		if elemMatch {
+			if !isElemMatch(v) || v.(*types.Document).Len() != 1 {
+				return false, NewErrorMsg(ErrBadValue, "$all/$elemMatch has to be consistent")
+			}
+
+			continue
 		}
 
-		return (^uint64(value) & bitmask) == bitmask, nil
+		if d, ok := v.(*types.Document); ok && d.Len() > 0 && strings.HasPrefix(d.Keys()[0], "$") {
+			return false, NewErrorMsg(ErrBadValue, "no $ expressions in $all")
+		}
+	}
 
-	default:
+	return elemMatch, nil
+}
+
+// filterFieldExprBitsAllClear handles {field: {$bitsAllClear: value}} filter.
+func filterFieldExprBitsAllClear(fieldValue, maskValue any) (bool, error) {
+	positions, err := getBitPositionsParam(maskValue)
+	if err != nil {
+		return false, formatBitwiseOperatorErr(err, "$bitsAllClear", maskValue)
+	}
+
+	value, ok := getBitwiseValue(fieldValue)
+	if !ok {
 		return false, nil
 	}
+
+	for _, pos := range positions {
+		if isBitSet(value, pos) {
+			return false, nil
+		}
+	}
+
+	return true, nil
 }
 
 // filterFieldExprBitsAllSet handles {field: {$bitsAllSet: value}} filter.
 func filterFieldExprBitsAllSet(fieldValue, maskValue any) (bool, error) {
-	switch value := fieldValue.(type) {
-	case float64:
-		// TODO check float negative zero
-		if value != math.Trunc(value) ||
-			math.IsNaN(value) ||
-			math.IsInf(value, 0) ||
-			value > math.MaxInt64 {
+	positions, err := getBitPositionsParam(maskValue)
+	if err != nil {
+		return false, formatBitwiseOperatorErr(err, "$bitsAllSet", maskValue)
+	}
+
+	value, ok := getBitwiseValue(fieldValue)
+	if !ok {
+		return false, nil
+	}
+
+	for _, pos := range positions {
+		if !isBitSet(value, pos) {
 			return false, nil
 		}
+	}
 
-		bitmask, err := getBinaryMaskParam(maskValue)
-		if err != nil {
-			return false, formatBitwiseOperatorErr(err, "$bitsAllSet", maskValue)
-		}
+	return true, nil
+}
 
-		return (uint64(value) & bitmask) == bitmask, nil
+// filterFieldExprBitsAnyClear handles {field: {$bitsAnyClear: value}} filter.
+func filterFieldExprBitsAnyClear(fieldValue, maskValue any) (bool, error) {
+	positions, err := getBitPositionsParam(maskValue)
+	if err != nil {
+		return false, formatBitwiseOperatorErr(err, "$bitsAnyClear", maskValue)
+	}
 
-	case types.Binary:
-		// TODO: https://github.com/FerretDB/FerretDB/issues/508
-		return false, NewErrorMsg(ErrNotImplemented, "BinData() not supported yet")
+	value, ok := getBitwiseValue(fieldValue)
+	if !ok {
+		return false, nil
+	}
 
-	case int32:
-		bitmask, err := getBinaryMaskParam(maskValue)
-		if err != nil {
-			return false, formatBitwiseOperatorErr(err, "$bitsAllSet", maskValue)
+	for _, pos := range positions {
+		if !isBitSet(value, pos) {
+			return true, nil
 		}
+	}
 
-		return (uint64(value) & bitmask) == bitmask, nil
-
-	case int64:
-		bitmask, err := getBinaryMaskParam(maskValue)
-		if err != nil {
-			return false, formatBitwiseOperatorErr(err, "$bitsAllSet", maskValue)
-		}
+	return false, nil
+}
 
-		return (uint64(value) & bitmask) == bitmask, nil
+// filterFieldExprBitsAnySet handles {field: {$bitsAnySet: value}} filter.
+func filterFieldExprBitsAnySet(fieldValue, maskValue any) (bool, error) {
+	positions, err := getBitPositionsParam(maskValue)
+	if err != nil {
+		return false, formatBitwiseOperatorErr(err, "$bitsAnySet", maskValue)
+	}
 
-	default:
+	value, ok := getBitwiseValue(fieldValue)
+	if !ok {
 		return false, nil
 	}
+
+	for _, pos := range positions {
+		if isBitSet(value, pos) {
+			return true, nil
+		}
+	}
+
+	return false, nil
 }
 
-// filterFieldExprBitsAnyClear handles {field: {$bitsAnyClear: value}} filter.
-func filterFieldExprBitsAnyClear(fieldValue, maskValue any) (bool, error) {
+// getBitwiseValue returns int64 or types.Binary value that bitwise operators could be applied to.
+// If the value is not an integer number (or a whole double representable as int64) or binary data,
+// it returns false.
+func getBitwiseValue(fieldValue any) (any, bool) {
 	switch value := fieldValue.(type) {
 	case float64:
 		// TODO check float negative zero
 		if value != math.Trunc(value) ||
 			math.IsNaN(value) ||
 			math.IsInf(value, 0) ||
-			value > math.MaxInt64 {
-			return false, nil
-		}
-
-		bitmask, err := getBinaryMaskParam(maskValue)
-		if err != nil {
-			return false, formatBitwiseOperatorErr(err, "$bitsAnyClear", maskValue)
+			value >= math.MaxInt64 ||
+			value < math.MinInt64 {
+			return nil, false
 		}
 
-		return (^uint64(value) & bitmask) != 0, nil
+		return int64(value), true
 
 	case types.Binary:
-		// TODO: https://github.com/FerretDB/FerretDB/issues/508
-		return false, NewErrorMsg(ErrNotImplemented, "BinData() not supported yet")
+		return value, true
 
 	case int32:
-		bitmask, err := getBinaryMaskParam(maskValue)
-		if err != nil {
-			return false, formatBitwiseOperatorErr(err, "$bitsAnyClear", maskValue)
-		}
-
-		return (^uint64(value) & bitmask) != 0, nil
+		return int64(value), true
 
 	case int64:
-		bitmask, err := getBinaryMaskParam(maskValue)
-		if err != nil {
-			return false, formatBitwiseOperatorErr(err, "$bitsAnyClear", maskValue)
-		}
-
-		return (^uint64(value) & bitmask) != 0, nil
+		return value, true
 
 	default:
-		return false, nil
+		return nil, false
 	}
 }
 
-// filterFieldExprBitsAnySet handles {field: {$bitsAnySet: value}} filter.
-func filterFieldExprBitsAnySet(fieldValue, maskValue any) (bool, error) {
-	switch value := fieldValue.(type) {
-	case float64:
-		// TODO check float negative zero
-		if value != math.Trunc(value) ||
-			math.IsNaN(value) ||
-			math.IsInf(value, 0) ||
-			value > math.MaxInt64 {
-			return false, nil
-		}
-
-		bitmask, err := getBinaryMaskParam(maskValue)
-		if err != nil {
-			return false, formatBitwiseOperatorErr(err, "$bitsAnySet", maskValue)
+// isBitSet returns true if the bit at the given position is set in the value returned by getBitwiseValue.
+//
+// Positions beyond 63 are treated as the sign bit for numbers, and as unset bits for binary data
+// shorter than needed.
+func isBitSet(value any, pos int64) bool {
+	switch value := value.(type) {
+	case int64:
+		if pos >= 64 {
+			return value < 0
 		}
 
-		return (uint64(value) & bitmask) != 0, nil
+		return (uint64(value)>>pos)&1 == 1
 
 	case types.Binary:
-		// TODO: https://github.com/FerretDB/FerretDB/issues/508
-		return false, NewErrorMsg(ErrNotImplemented, "BinData() not supported yet")
-
-	case int32:
-		bitmask, err := getBinaryMaskParam(maskValue)
-		if err != nil {
-			return false, formatBitwiseOperatorErr(err, "$bitsAnySet", maskValue)
+		if pos/8 >= int64(len(value.B)) {
+			return false
 		}
-		return (uint64(value) & bitmask) != 0, nil
 
-	case int64:
-		bitmask, err := getBinaryMaskParam(maskValue)
-		if err != nil {
-			return false, formatBitwiseOperatorErr(err, "$bitsAnySet", maskValue)
-		}
-		return (uint64(value) & bitmask) != 0, nil
+		return (value.B[pos/8]>>(pos%8))&1 == 1
 
 	default:
-		return false, nil
+		panic(fmt.Sprintf("isBitSet: unexpected type %T", value))
 	}
 }
 
 // filterFieldMod handles {field: {$mod: [divisor, remainder]}} filter.
 func filterFieldMod(fieldValue, exprValue any) (bool, error) {
 	var field, divisor, remainder int64
+	var divisorInexact, remainderInexact bool
 
-	switch f := fieldValue.(type) {
-	case float64:
-		if math.IsNaN(f) || math.IsInf(f, 0) {
-			return false, nil
-		}
-		f = math.Trunc(f)
-		field = int64(f)
-		if f != float64(field) {
-			return false, nil
-		}
-
-	case int32:
-		field = int64(f)
-
-	case int64:
-		field = f
-
-	default:
-		return false, nil
+	// validate the value first, so errors do not depend on documents
+	arr, ok := exprValue.(*types.Array)
+	if !ok {
+		return false, NewErrorMsg(ErrBadValue, `malformed mod, needs to be an array`)
 	}
 
-	arr := exprValue.(*types.Array)
 	if arr.Len() < 2 {
 		return false, NewErrorMsg(ErrBadValue, `malformed mod, not enough elements`)
 	}
@@ -904,9 +1179,7 @@ func filterFieldMod(fieldValue, exprValue any) (bool, error) {
 		}
 
 		divisor = int64(d)
-		if d != float64(divisor) && field != 0 && d < 9.223372036854775296e+18 {
-			return false, nil
-		}
+		divisorInexact = d != float64(divisor) && d < 9.223372036854775296e+18
 
 	case int32:
 		divisor = int64(d)
@@ -930,9 +1203,7 @@ func filterFieldMod(fieldValue, exprValue any) (bool, error) {
 				`Out of bounds coercing to integral value`)
 		}
 		remainder = int64(r)
-		if r != float64(remainder) {
-			return false, nil
-		}
+		remainderInexact = r != float64(remainder)
 
 	case int32:
 		remainder = int64(r)
@@ -948,6 +1219,32 @@ func filterFieldMod(fieldValue, exprValue any) (bool, error) {
 		return false, NewErrorMsg(ErrBadValue, `divisor cannot be 0`)
 	}
 
+	// non-numeric values never match
+	switch f := fieldValue.(type) {
+	case float64:
+		if math.IsNaN(f) || math.IsInf(f, 0) {
+			return false, nil
+		}
+		f = math.Trunc(f)
+		field = int64(f)
+		if f != float64(field) {
+			return false, nil
+		}
+
+	case int32:
+		field = int64(f)
+
+	case int64:
+		field = f
+
+	default:
+		return false, nil
+	}
+
+	if remainderInexact || (divisorInexact && field != 0) {
+		return false, nil
+	}
+
 	f := field % divisor
 	if f != remainder {
 		return false, nil
@@ -975,111 +1272,54 @@ func filterFieldExprExists(fieldExist bool, exprValue any) (bool, error) {
 }
 
 // filterFieldExprType handles {field: {$type: value}} filter.
+//
+// The value could be a string alias, a numerical code, or an array of them;
+// in the latter case, the field matches if it matches any of them.
 func filterFieldExprType(fieldValue, exprValue any) (bool, error) {
-	switch exprValue := exprValue.(type) {
-	case *types.Array:
-		hasSameType := hasSameTypeElements(exprValue)
-
-		for i := 0; i < exprValue.Len(); i++ {
-			exprValue := must.NotFail(exprValue.Get(i))
-
-			switch exprValue := exprValue.(type) {
-			case float64:
-				if math.IsNaN(exprValue) || math.IsInf(exprValue, 0) {
-					return false, NewErrorMsg(ErrBadValue, `Invalid numerical type code: `+
-						strings.Trim(strings.ToLower(fmt.Sprintf("%v", exprValue)), "+"))
-				}
-				if exprValue != math.Trunc(exprValue) {
-					return false, NewErrorMsg(ErrBadValue, fmt.Sprintf(`Invalid numerical type code: %v`, exprValue))
-				}
-
-				code, err := newTypeCode(int32(exprValue))
-				if err != nil {
-					return false, err
-				}
-
-				if !hasSameType {
-					continue
-				}
-
-				res, err := filterFieldValueByTypeCode(fieldValue, code)
-				if err != nil {
-					return false, err
-				}
-				if res {
-					return true, nil
-				}
-
-			case string:
-				code, err := parseTypeCode(exprValue)
-				if err != nil {
-					return false, err
-				}
-				res, err := filterFieldValueByTypeCode(fieldValue, code)
-				if err != nil {
-					return false, err
-				}
-				if res {
-					return true, nil
-				}
-			case int32:
-				code, err := newTypeCode(exprValue)
-				if err != nil {
-					return false, err
-				}
-
-				if !hasSameType {
-					continue
-				}
-
-				res, err := filterFieldValueByTypeCode(fieldValue, code)
-				if err != nil {
-					return false, err
-				}
-				if res {
-					return true, nil
-				}
-			default:
-				return false, NewErrorMsg(ErrBadValue, fmt.Sprintf(`Invalid numerical type code: %s`, exprValue))
-			}
-		}
-		return false, nil
-
-	case float64:
-		if math.IsNaN(exprValue) || math.IsInf(exprValue, 0) {
-			return false, NewErrorMsg(ErrBadValue, `Invalid numerical type code: `+
-				strings.Trim(strings.ToLower(fmt.Sprintf("%v", exprValue)), "+"))
-		}
-		if exprValue != math.Trunc(exprValue) {
-			return false, NewErrorMsg(ErrBadValue, fmt.Sprintf(`Invalid numerical type code: %v`, exprValue))
-		}
+	// all values are validated first, so errors do not depend on documents
+	codes, err := getTypeCodes(exprValue)
+	if err != nil {
+		return false, err
+	}
 
-		code, err := newTypeCode(int32(exprValue))
+	for _, code := range codes {
+		res, err := filterFieldValueByTypeCode(fieldValue, code)
 		if err != nil {
 			return false, err
 		}
 
-		return filterFieldValueByTypeCode(fieldValue, code)
+		if res {
+			return true, nil
+		}
+	}
 
-	case string:
-		code, err := parseTypeCode(exprValue)
+	return false, nil
+}
+
+// getTypeCodes returns type codes of the $type value, which could be a single type alias or code, or an array of them.
+func getTypeCodes(exprValue any) ([]typeCode, error) {
+	arr, ok := exprValue.(*types.Array)
+	if !ok {
+		code, err := getTypeCode(exprValue)
 		if err != nil {
-			return false, err
+			return nil, err
 		}
 
-		return filterFieldValueByTypeCode(fieldValue, code)
+		return []typeCode{code}, nil
+	}
+
+	codes := make([]typeCode, arr.Len())
 
-	case int32:
-		code, err := newTypeCode(exprValue)
+	for i := 0; i < arr.Len(); i++ {
+		code, err := getTypeCode(must.NotFail(arr.Get(i)))
 		if err != nil {
-			return false, err
+			return nil, err
 		}
 
-		return filterFieldValueByTypeCode(fieldValue, code)
-
-	default:
-		return false, NewErrorMsg(ErrBadValue, fmt.Sprintf(`Invalid numerical type code: %v`, exprValue))
+		codes[i] = code
 	}
+
+	return codes, nil
 }
 
 // filterFieldValueByTypeCode filters fieldValue by given type code.
@@ -1169,8 +1409,10 @@ func filterFieldValueByTypeCode(fieldValue any, code typeCode) (bool, error) {
 		default:
 			return false, nil
 		}
-	case typeCodeDecimal, typeCodeMinKey, typeCodeMaxKey:
-		return false, NewErrorMsg(ErrNotImplemented, fmt.Sprintf(`Type code %v not implemented`, code))
+	case typeCodeDBPointer, typeCodeJavaScript, typeCodeSymbol, typeCodeJavaScriptWithScope,
+		typeCodeDecimal, typeCodeMinKey, typeCodeMaxKey:
+		// values of those types could not be stored
+		return false, nil
 	default:
 		return false, NewErrorMsg(ErrBadValue, fmt.Sprintf(`Unknown type name alias: %s`, code.String()))
 	}
@@ -1178,40 +1420,87 @@ func filterFieldValueByTypeCode(fieldValue any, code typeCode) (bool, error) {
 	return true, nil
 }
 
-// filterFieldExprElemMatch handles {field: {$elemMatch: value}}.
-// Returns false if doc value is not an array.
-// TODO: https://github.com/FerretDB/FerretDB/issues/364
-func filterFieldExprElemMatch(doc *types.Document, filterKey string, exprValue any) (bool, error) {
-	value := must.NotFail(doc.Get(filterKey))
+// hasScalarComparison returns true if the given expression has $gt, $gte, $lt, or $lte operator
+// with a non-array value.
+func hasScalarComparison(expr *types.Document) bool {
+	for _, op := range []string{"$gt", "$gte", "$lt", "$lte"} {
+		v, err := expr.Get(op)
+		if err != nil {
+			continue
+		}
 
-	if _, ok := value.(*types.Array); !ok {
-		return false, nil
+		if _, ok := v.(*types.Array); !ok {
+			return true
+		}
 	}
 
+	return false
+}
+
+// filterFieldExprElemMatch handles {field: {$elemMatch: value}}.
+// Returns false if doc value is not an array.
+//
+// A single array element should satisfy all conditions.
+// If the first key of the value is an operator like $gt, conditions are applied to elements themselves
+// ({arr: {$elemMatch: {$gt: 5, $lt: 10}}}); otherwise, the value is a query for document elements
+// ({arr: {$elemMatch: {a: 1, b: {$gt: 2}}}}).
+func filterFieldExprElemMatch(doc *types.Document, filterKey string, exprValue any) (bool, error) {
 	expr, ok := exprValue.(*types.Document)
 	if !ok {
 		return false, NewErrorMsg(ErrBadValue, "$elemMatch needs an Object")
 	}
 
+	operators := expr.Len() > 0 && strings.HasPrefix(expr.Keys()[0], "$") &&
+		!slices.Contains([]string{"$and", "$or", "$nor"}, expr.Keys()[0])
+
 	for _, key := range expr.Keys() {
 		if slices.Contains([]string{"$text", "$where"}, key) {
 			return false, NewErrorMsg(ErrBadValue, fmt.Sprintf("%s can only be applied to the top-level document", key))
 		}
 
-		// TODO: https://github.com/FerretDB/FerretDB/issues/730
-		if slices.Contains([]string{"$and", "$or", "$nor"}, key) {
-			return false, NewErrorMsg(ErrNotImplemented, fmt.Sprintf("$elemMatch: support for %s not implemented yet", key))
+		if operators && !strings.HasPrefix(key, "$") {
+			return false, NewErrorMsg(ErrBadValue, fmt.Sprintf("unknown operator: %s", key))
 		}
+	}
 
-		// TODO: https://github.com/FerretDB/FerretDB/issues/731
-		if slices.Contains([]string{"$ne", "$not"}, key) {
-			return false, NewErrorMsg(ErrNotImplemented, fmt.Sprintf("$elemMatch: support for %s not implemented yet", key))
+	arr, ok := must.NotFail(doc.Get(filterKey)).(*types.Array)
+	if !ok {
+		return false, nil
+	}
+
+	for i := 0; i < arr.Len(); i++ {
+		elem := must.NotFail(arr.Get(i))
+
+		var matches bool
+		var err error
+
+		if operators {
+			// nested arrays are compared as whole values, not by their elements,
+			// so they can't match comparisons with scalars
+			if _, isArray := elem.(*types.Array); isArray && hasScalarComparison(expr) {
+				continue
+			}
+
+			// {arr: {$elemMatch: {$gt: 5}}} is evaluated as {field: {$gt: 5}} for each element
+			elemDoc := must.NotFail(types.NewDocument(filterKey, elem))
+			matches, err = filterFieldExpr(elemDoc, filterKey, expr)
+		} else {
+			elemDoc, isDoc := elem.(*types.Document)
+			if !isDoc {
+				continue
+			}
+
+			matches, err = FilterDocument(elemDoc, expr)
 		}
 
-		if expr.Len() > 1 && !strings.HasPrefix(key, "$") {
-			return false, NewErrorMsg(ErrBadValue, fmt.Sprintf("unknown operator: %s", key))
+		if err != nil {
+			return false, err
+		}
+
+		if matches {
+			return true, nil
 		}
 	}
 
-	return filterFieldExpr(doc, filterKey, expr)
+	return false, nil
 }
@@ -0,0 +1,812 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package common
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+)
+
+func TestFilterDocumentNull(t *testing.T) {
+	t.Parallel()
+
+	docs := map[string]*types.Document{
+		"null":    must.NotFail(types.NewDocument("_id", "null", "a", types.Null)),
+		"missing": must.NotFail(types.NewDocument("_id", "missing")),
+		"value":   must.NotFail(types.NewDocument("_id", "value", "a", int32(42))),
+	}
+
+	for name, tc := range map[string]struct { //nolint:paralleltest // false positive
+		filter   *types.Document
+		expected map[string]bool
+	}{
+		"Null": {
+			filter:   must.NotFail(types.NewDocument("a", types.Null)),
+			expected: map[string]bool{"null": true, "missing": true, "value": false},
+		},
+		"EqNull": {
+			filter:   must.NotFail(types.NewDocument("a", must.NotFail(types.NewDocument("$eq", types.Null)))),
+			expected: map[string]bool{"null": true, "missing": true, "value": false},
+		},
+		"NeNull": {
+			filter:   must.NotFail(types.NewDocument("a", must.NotFail(types.NewDocument("$ne", types.Null)))),
+			expected: map[string]bool{"null": false, "missing": false, "value": true},
+		},
+		"InNull": {
+			filter: must.NotFail(types.NewDocument(
+				"a", must.NotFail(types.NewDocument("$in", must.NotFail(types.NewArray(types.Null)))),
+			)),
+			expected: map[string]bool{"null": true, "missing": true, "value": false},
+		},
+		"Nin": {
+			filter: must.NotFail(types.NewDocument(
+				"a", must.NotFail(types.NewDocument("$nin", must.NotFail(types.NewArray(int32(1))))),
+			)),
+			expected: map[string]bool{"null": true, "missing": true, "value": true},
+		},
+		"TypeNull": {
+			filter:   must.NotFail(types.NewDocument("a", must.NotFail(types.NewDocument("$type", "null")))),
+			expected: map[string]bool{"null": true, "missing": false, "value": false},
+		},
+		"TypeNullCode": {
+			filter:   must.NotFail(types.NewDocument("a", must.NotFail(types.NewDocument("$type", int32(10))))),
+			expected: map[string]bool{"null": true, "missing": false, "value": false},
+		},
+		"DottedNull": {
+			filter:   must.NotFail(types.NewDocument("a.b", types.Null)),
+			expected: map[string]bool{"null": true, "missing": true, "value": true},
+		},
+	} {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			for docName, doc := range docs {
+				actual, err := FilterDocument(doc, tc.filter)
+				require.NoError(t, err)
+				assert.Equal(t, tc.expected[docName], actual, docName)
+			}
+		})
+	}
+}
+
+func TestFilterDocumentTypeUndefined(t *testing.T) {
+	t.Parallel()
+
+	doc := must.NotFail(types.NewDocument("_id", "null", "a", types.Null))
+
+	for name, v := range map[string]any{ //nolint:paralleltest // false positive
+		"Alias":      "undefined",
+		"Code":       int32(6),
+		"FloatCode":  float64(6),
+		"ArrayAlias": must.NotFail(types.NewArray("null", "undefined")),
+		"ArrayCode":  must.NotFail(types.NewArray(int32(10), int32(6))),
+	} {
+		name, v := name, v
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			filter := must.NotFail(types.NewDocument("a", must.NotFail(types.NewDocument("$type", v))))
+			_, err := FilterDocument(doc, filter)
+			require.Equal(t, errUndefinedTypeCode, err)
+		})
+	}
+}
+
+func TestFilterDocumentArrayEquality(t *testing.T) {
+	t.Parallel()
+
+	docs := map[string]*types.Document{
+		"array":   must.NotFail(types.NewDocument("_id", "array", "a", must.NotFail(types.NewArray("x", "y")))),
+		"reverse": must.NotFail(types.NewDocument("_id", "reverse", "a", must.NotFail(types.NewArray("y", "x")))),
+		"longer":  must.NotFail(types.NewDocument("_id", "longer", "a", must.NotFail(types.NewArray("x", "y", "z")))),
+		"embedded": must.NotFail(types.NewDocument("_id", "embedded", "a", must.NotFail(types.NewArray(
+			"z", must.NotFail(types.NewArray("x", "y")),
+		)))),
+		"numbers": must.NotFail(types.NewDocument("_id", "numbers", "a", must.NotFail(types.NewArray(
+			int32(1), int64(2), 3.0,
+		)))),
+		"scalar":  must.NotFail(types.NewDocument("_id", "scalar", "a", "x")),
+		"missing": must.NotFail(types.NewDocument("_id", "missing")),
+	}
+
+	for name, tc := range map[string]struct { //nolint:paralleltest // false positive
+		filter   *types.Document
+		expected map[string]bool
+	}{
+		"ExactOrder": {
+			filter:   must.NotFail(types.NewDocument("a", must.NotFail(types.NewArray("x", "y")))),
+			expected: map[string]bool{"array": true, "embedded": true},
+		},
+		"ReverseOrder": {
+			filter:   must.NotFail(types.NewDocument("a", must.NotFail(types.NewArray("y", "x")))),
+			expected: map[string]bool{"reverse": true},
+		},
+		"Prefix": {
+			filter:   must.NotFail(types.NewDocument("a", must.NotFail(types.NewArray("x")))),
+			expected: map[string]bool{},
+		},
+		"Numbers": {
+			filter:   must.NotFail(types.NewDocument("a", must.NotFail(types.NewArray(1.0, int32(2), int64(3))))),
+			expected: map[string]bool{"numbers": true},
+		},
+		"ScalarInArray": {
+			filter:   must.NotFail(types.NewDocument("a", "x")),
+			expected: map[string]bool{"array": true, "reverse": true, "longer": true, "scalar": true},
+		},
+		"ScalarInEmbeddedArray": {
+			filter:   must.NotFail(types.NewDocument("a", "y")),
+			expected: map[string]bool{"array": true, "reverse": true, "longer": true},
+		},
+		"NumberInArray": {
+			filter:   must.NotFail(types.NewDocument("a", int64(1))),
+			expected: map[string]bool{"numbers": true},
+		},
+	} {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			for docName, doc := range docs {
+				actual, err := FilterDocument(doc, tc.filter)
+				require.NoError(t, err)
+				assert.Equal(t, tc.expected[docName], actual, docName)
+			}
+		})
+	}
+}
+
+func TestFilterDocumentInNin(t *testing.T) {
+	t.Parallel()
+
+	docs := map[string]*types.Document{
+		"int":    must.NotFail(types.NewDocument("_id", "int", "v", int32(42))),
+		"double": must.NotFail(types.NewDocument("_id", "double", "v", 42.0)),
+		"string": must.NotFail(types.NewDocument("_id", "string", "v", "42")),
+		"foobar": must.NotFail(types.NewDocument("_id", "foobar", "v", "foobar")),
+		"array":  must.NotFail(types.NewDocument("_id", "array", "v", must.NotFail(types.NewArray(int32(1), "foo")))),
+		"nested": must.NotFail(types.NewDocument("_id", "nested", "v", must.NotFail(types.NewArray(
+			must.NotFail(types.NewArray(int32(1), int32(2))),
+		)))),
+		"document": must.NotFail(types.NewDocument("_id", "document", "v", must.NotFail(types.NewDocument("a", int32(1))))),
+		"documents": must.NotFail(types.NewDocument("_id", "documents", "v", must.NotFail(types.NewArray(
+			must.NotFail(types.NewDocument("a", int32(1))),
+		)))),
+		"null":    must.NotFail(types.NewDocument("_id", "null", "v", types.Null)),
+		"missing": must.NotFail(types.NewDocument("_id", "missing")),
+	}
+
+	// all returns expected map with all documents except given ones.
+	all := func(except ...string) map[string]bool {
+		res := make(map[string]bool, len(docs))
+		for name := range docs {
+			res[name] = true
+		}
+		for _, name := range except {
+			delete(res, name)
+		}
+		return res
+	}
+
+	for name, tc := range map[string]struct { //nolint:paralleltest // false positive
+		filter   *types.Document
+		expected map[string]bool
+	}{
+		"InMixedTypes": {
+			filter: must.NotFail(types.NewDocument("v", must.NotFail(types.NewDocument(
+				"$in", must.NotFail(types.NewArray(int64(42), "foo")),
+			)))),
+			expected: map[string]bool{"int": true, "double": true, "array": true},
+		},
+		"InRegex": {
+			filter: must.NotFail(types.NewDocument("v", must.NotFail(types.NewDocument(
+				"$in", must.NotFail(types.NewArray(types.Regex{Pattern: "^foo"}, int32(2))),
+			)))),
+			expected: map[string]bool{"foobar": true, "array": true},
+		},
+		"InNull": {
+			filter: must.NotFail(types.NewDocument("v", must.NotFail(types.NewDocument(
+				"$in", must.NotFail(types.NewArray(types.Null)),
+			)))),
+			expected: map[string]bool{"null": true, "missing": true},
+		},
+		"InDocument": {
+			filter: must.NotFail(types.NewDocument("v", must.NotFail(types.NewDocument(
+				"$in", must.NotFail(types.NewArray(must.NotFail(types.NewDocument("a", int32(1))))),
+			)))),
+			expected: map[string]bool{"document": true, "documents": true},
+		},
+		"InArray": {
+			filter: must.NotFail(types.NewDocument("v", must.NotFail(types.NewDocument(
+				"$in", must.NotFail(types.NewArray(must.NotFail(types.NewArray(int32(1), 2.0)))),
+			)))),
+			expected: map[string]bool{"nested": true},
+		},
+		"InEmpty": {
+			filter: must.NotFail(types.NewDocument("v", must.NotFail(types.NewDocument(
+				"$in", must.NotFail(types.NewArray()),
+			)))),
+			expected: map[string]bool{},
+		},
+		"Nin": {
+			filter: must.NotFail(types.NewDocument("v", must.NotFail(types.NewDocument(
+				"$nin", must.NotFail(types.NewArray(int32(42), types.Regex{Pattern: "^foo"})),
+			)))),
+			expected: all("int", "double", "foobar", "array"),
+		},
+		"NinNull": {
+			filter: must.NotFail(types.NewDocument("v", must.NotFail(types.NewDocument(
+				"$nin", must.NotFail(types.NewArray(types.Null)),
+			)))),
+			expected: all("null", "missing"),
+		},
+	} {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			for docName, doc := range docs {
+				actual, err := FilterDocument(doc, tc.filter)
+				require.NoError(t, err)
+				assert.Equal(t, tc.expected[docName], actual, docName)
+			}
+		})
+	}
+
+	t.Run("NestedOperator", func(t *testing.T) {
+		t.Parallel()
+
+		for _, op := range []string{"$in", "$nin"} {
+			filter := must.NotFail(types.NewDocument("v", must.NotFail(types.NewDocument(
+				op, must.NotFail(types.NewArray(must.NotFail(types.NewDocument("$gt", int32(1))))),
+			))))
+
+			_, err := FilterDocument(docs["document"], filter)
+			assert.Equal(t, NewErrorMsg(ErrBadValue, "cannot nest $ under $in"), err, op)
+		}
+	})
+}
+
+func TestFilterDocumentDottedArrays(t *testing.T) {
+	t.Parallel()
+
+	docs := map[string]*types.Document{
+		"items": must.NotFail(types.NewDocument("_id", "items", "items", must.NotFail(types.NewArray(
+			must.NotFail(types.NewDocument("price", int32(5))),
+			must.NotFail(types.NewDocument("price", int32(10), "tags", must.NotFail(types.NewArray("a", "b")))),
+		)))),
+		"nested": must.NotFail(types.NewDocument("_id", "nested", "orders", must.NotFail(types.NewArray(
+			must.NotFail(types.NewDocument("items", must.NotFail(types.NewArray(
+				must.NotFail(types.NewDocument("price", int32(1))),
+			)))),
+			must.NotFail(types.NewDocument("items", must.NotFail(types.NewArray(
+				must.NotFail(types.NewDocument("price", int32(2))),
+				must.NotFail(types.NewDocument("price", int32(10))),
+			)))),
+		)))),
+		"document": must.NotFail(types.NewDocument("_id", "document", "items", must.NotFail(types.NewDocument(
+			"price", int32(10),
+		)))),
+		"scalars": must.NotFail(types.NewDocument("_id", "scalars", "items", must.NotFail(types.NewArray(int32(10))))),
+	}
+
+	for name, tc := range map[string]struct { //nolint:paralleltest // false positive
+		filter   *types.Document
+		expected map[string]bool
+	}{
+		"ArrayOfDocuments": {
+			filter:   must.NotFail(types.NewDocument("items.price", int32(10))),
+			expected: map[string]bool{"items": true, "document": true},
+		},
+		"ArrayOfDocumentsNoMatch": {
+			filter:   must.NotFail(types.NewDocument("items.price", int32(7))),
+			expected: map[string]bool{},
+		},
+		"Index": {
+			filter:   must.NotFail(types.NewDocument("items.0.price", int32(5))),
+			expected: map[string]bool{"items": true},
+		},
+		"IndexLast": {
+			filter:   must.NotFail(types.NewDocument("items.0", int32(10))),
+			expected: map[string]bool{"scalars": true},
+		},
+		"MultiLevel": {
+			filter:   must.NotFail(types.NewDocument("orders.items.price", int32(10))),
+			expected: map[string]bool{"nested": true},
+		},
+		"MultiLevelOperator": {
+			filter: must.NotFail(types.NewDocument(
+				"orders.items.price", must.NotFail(types.NewDocument("$gt", int32(1), "$lt", int32(3))),
+			)),
+			expected: map[string]bool{"nested": true},
+		},
+		"ArrayInArray": {
+			filter:   must.NotFail(types.NewDocument("items.tags", "b")),
+			expected: map[string]bool{"items": true},
+		},
+		"Ne": {
+			filter: must.NotFail(types.NewDocument(
+				"items.price", must.NotFail(types.NewDocument("$ne", int32(5))),
+			)),
+			expected: map[string]bool{"nested": true, "document": true, "scalars": true},
+		},
+		"Nin": {
+			filter: must.NotFail(types.NewDocument(
+				"orders.items.price", must.NotFail(types.NewDocument("$nin", must.NotFail(types.NewArray(int32(2)))))),
+			),
+			expected: map[string]bool{"items": true, "document": true, "scalars": true},
+		},
+		"ExistsFalse": {
+			filter: must.NotFail(types.NewDocument(
+				"items.tags", must.NotFail(types.NewDocument("$exists", false)),
+			)),
+			expected: map[string]bool{"nested": true, "document": true, "scalars": true},
+		},
+	} {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			for docName, doc := range docs {
+				actual, err := FilterDocument(doc, tc.filter)
+				require.NoError(t, err)
+				assert.Equal(t, tc.expected[docName], actual, docName)
+			}
+		})
+	}
+}
+
+func TestFilterDocumentElemMatch(t *testing.T) {
+	t.Parallel()
+
+	// d and a are shortcuts for creating documents and arrays
+	d := func(pairs ...any) *types.Document { return must.NotFail(types.NewDocument(pairs...)) }
+	a := func(values ...any) *types.Array { return must.NotFail(types.NewArray(values...)) }
+
+	docs := map[string]*types.Document{
+		"documents":    d("a", a(d("x", int32(1), "y", int32(5)), d("x", int32(2), "y", int32(1)))),
+		"scalars":      d("a", a(int32(3), int32(7), int32(12))),
+		"nested":       d("a", a(d("b", a(d("c", int32(1)), d("c", int32(2)))), d("b", a(d("c", int32(3)))))),
+		"nestedArrays": d("a", a(a(int32(1), int32(2)), a(int32(8), int32(9)))),
+		"scalar":       d("a", int32(7)),
+		"document":     d("a", d("x", int32(1), "y", int32(5))),
+	}
+
+	for name, tc := range map[string]struct { //nolint:paralleltest // false positive
+		elemMatch *types.Document
+		expected  []string // names of matching documents
+	}{
+		"Query": {
+			elemMatch: d("x", int32(1), "y", d("$gt", int32(2))),
+			expected:  []string{"documents"},
+		},
+		"QueryDifferentElements": {
+			elemMatch: d("x", int32(2), "y", d("$gt", int32(2))),
+		},
+		"QueryOr": {
+			elemMatch: d("$or", a(d("x", int32(3)), d("y", int32(1)))),
+			expected:  []string{"documents"},
+		},
+		"Operators": {
+			elemMatch: d("$gt", int32(5), "$lt", int32(10)),
+			expected:  []string{"scalars"},
+		},
+		"OperatorsDifferentElements": {
+			elemMatch: d("$gt", int32(4), "$lt", int32(6)),
+		},
+		"Ne": {
+			elemMatch: d("$ne", int32(3)),
+			expected:  []string{"documents", "nested", "nestedArrays", "scalars"},
+		},
+		"NestedQuery": {
+			elemMatch: d("b", d("$elemMatch", d("c", int32(3)))),
+			expected:  []string{"nested"},
+		},
+		"NestedQueryNoMatch": {
+			elemMatch: d("b", d("$elemMatch", d("c", int32(4)))),
+		},
+		"NestedOperators": {
+			elemMatch: d("$elemMatch", d("$gt", int32(8))),
+			expected:  []string{"nestedArrays"},
+		},
+		"NestedOperatorsNoMatch": {
+			elemMatch: d("$elemMatch", d("$gt", int32(9))),
+		},
+	} {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			filter := d("a", d("$elemMatch", tc.elemMatch))
+
+			var actual []string
+			for docName, doc := range docs {
+				matches, err := FilterDocument(doc, filter)
+				require.NoError(t, err)
+
+				if matches {
+					actual = append(actual, docName)
+				}
+			}
+
+			assert.ElementsMatch(t, tc.expected, actual)
+		})
+	}
+}
+
+func TestFilterDocumentSize(t *testing.T) {
+	t.Parallel()
+
+	// d and a are shortcuts for creating documents and arrays
+	d := func(pairs ...any) *types.Document { return must.NotFail(types.NewDocument(pairs...)) }
+	a := func(values ...any) *types.Array { return must.NotFail(types.NewArray(values...)) }
+
+	docs := map[string]*types.Document{
+		"empty":  d("a", a()),
+		"two":    d("a", a(int32(1), "b")),
+		"nested": d("a", a(a(int32(1), int32(2)))),
+		"scalar": d("a", int32(2)),
+		"string": d("a", "ab"),
+	}
+
+	for name, tc := range map[string]struct { //nolint:paralleltest // false positive
+		size     any
+		expected []string // names of matching documents
+		err      error
+	}{
+		"Zero": {
+			size:     int32(0),
+			expected: []string{"empty"},
+		},
+		"One": {
+			size:     int64(1),
+			expected: []string{"nested"},
+		},
+		"Two": {
+			size:     float64(2),
+			expected: []string{"two"},
+		},
+		"Negative": {
+			size: int32(-1),
+			err:  NewErrorMsg(ErrBadValue, "$size may not be negative"),
+		},
+		"Fractional": {
+			size: 1.5,
+			err:  NewErrorMsg(ErrBadValue, "$size must be a whole number"),
+		},
+		"Range": {
+			size: d("$gt", int32(1)),
+			err:  NewErrorMsg(ErrBadValue, "$size needs a number"),
+		},
+	} {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			filter := d("a", d("$size", tc.size))
+
+			var actual []string
+			for docName, doc := range docs {
+				matches, err := FilterDocument(doc, filter)
+				if tc.err != nil {
+					// errors must not depend on the document
+					assert.Equal(t, tc.err, err, docName)
+					continue
+				}
+
+				require.NoError(t, err)
+
+				if matches {
+					actual = append(actual, docName)
+				}
+			}
+
+			if tc.err == nil {
+				assert.ElementsMatch(t, tc.expected, actual)
+			}
+		})
+	}
+}
+
+func TestFilterDocumentAll(t *testing.T) {
+	t.Parallel()
+
+	// d and a are shortcuts for creating documents and arrays
+	d := func(pairs ...any) *types.Document { return must.NotFail(types.NewDocument(pairs...)) }
+	a := func(values ...any) *types.Array { return must.NotFail(types.NewArray(values...)) }
+
+	docs := map[string]*types.Document{
+		"tags":      d("a", a("a", "b", "c")),
+		"tagsOne":   d("a", a("a")),
+		"scalar":    d("a", "a"),
+		"numbers":   d("a", a(int32(1), 2.0, int64(3))),
+		"nested":    d("a", a(a("a", "b"), "c")),
+		"documents": d("a", a(d("x", int32(1), "y", int32(5)), d("x", int32(2), "y", int32(1)))),
+		"document":  d("a", d("x", int32(1))),
+		"missing":   d("b", int32(1)),
+	}
+
+	for name, tc := range map[string]struct {
+		all      *types.Array
+		expected []string // names of matching documents
+		err      error
+	}{
+		"Tags": {
+			all:      a("a", "b"),
+			expected: []string{"tags"},
+		},
+		"Single": {
+			all:      a("a"),
+			expected: []string{"tags", "tagsOne", "scalar"},
+		},
+		"Numbers": {
+			all:      a(int64(1), int32(2), 3.0),
+			expected: []string{"numbers"},
+		},
+		"Empty": {
+			all: a(),
+		},
+		"WholeArray": {
+			all:      a(a("a", "b")),
+			expected: []string{"nested"},
+		},
+		"Document": {
+			all:      a(d("x", int32(1))),
+			expected: []string{"document"},
+		},
+		"ElemMatch": {
+			all: a(
+				d("$elemMatch", d("x", int32(1), "y", d("$gt", int32(2)))),
+				d("$elemMatch", d("y", int32(1))),
+			),
+			expected: []string{"documents"},
+		},
+		"ElemMatchNoMatch": {
+			all: a(
+				d("$elemMatch", d("x", int32(1), "y", d("$gt", int32(2)))),
+				d("$elemMatch", d("y", int32(2))),
+			),
+		},
+		"ElemMatchInconsistent": {
+			all: a(d("$elemMatch", d("x", int32(1))), "a"),
+			err: NewErrorMsg(ErrBadValue, "$all/$elemMatch has to be consistent"),
+		},
+		"ElemMatchNotFirst": {
+			all: a("a", d("$elemMatch", d("x", int32(1)))),
+			err: NewErrorMsg(ErrBadValue, "no $ expressions in $all"),
+		},
+	} {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			filter := d("a", d("$all", tc.all))
+
+			var actual []string
+			for docName, doc := range docs {
+				matches, err := FilterDocument(doc, filter)
+				if tc.err != nil {
+					assert.Equal(t, tc.err, err, docName)
+					continue
+				}
+
+				require.NoError(t, err)
+
+				if matches {
+					actual = append(actual, docName)
+				}
+			}
+
+			if tc.err == nil {
+				assert.ElementsMatch(t, tc.expected, actual)
+			}
+		})
+	}
+
+	t.Run("SameAsIn", func(t *testing.T) {
+		t.Parallel()
+
+		// a single $all value matches the same documents as a single $in value
+		for _, v := range []any{"a", int32(1), 2.0, int64(3), a("a", "b"), a("a"), d("x", int32(1))} {
+			for docName, doc := range docs {
+				all, err := FilterDocument(doc, d("a", d("$all", a(v))))
+				require.NoError(t, err)
+
+				in, err := FilterDocument(doc, d("a", d("$in", a(v))))
+				require.NoError(t, err)
+
+				assert.Equal(t, in, all, "%s: %v", docName, v)
+			}
+		}
+	})
+}
+
+func TestFilterDocumentBitwise(t *testing.T) {
+	t.Parallel()
+
+	// d and a are shortcuts for creating documents and arrays
+	d := func(pairs ...any) *types.Document { return must.NotFail(types.NewDocument(pairs...)) }
+	a := func(values ...any) *types.Array { return must.NotFail(types.NewArray(values...)) }
+
+	docs := map[string]*types.Document{
+		"binary":   d("a", types.Binary{B: []byte{0b101, 0, 0b1}}), // bits 0, 2, 16
+		"int32":    d("a", int32(5)),                               // bits 0, 2
+		"negative": d("a", int64(-1)),                              // all bits
+		"double":   d("a", 4.0),                                    // bit 2
+		"fraction": d("a", 4.5),
+		"string":   d("a", "5"),
+	}
+
+	for name, tc := range map[string]struct { //nolint:paralleltest // false positive
+		operator string
+		mask     any
+		expected []string // names of matching documents
+		err      error
+	}{
+		"AllSetNumber": {
+			operator: "$bitsAllSet",
+			mask:     int32(5),
+			expected: []string{"binary", "int32", "negative"},
+		},
+		"AllSetPositions": {
+			operator: "$bitsAllSet",
+			mask:     a(int32(0), int64(2), int32(16)),
+			expected: []string{"binary", "negative"},
+		},
+		"AllSetLargePosition": {
+			operator: "$bitsAllSet",
+			mask:     a(int32(100)),
+			expected: []string{"negative"},
+		},
+		"AnySetBinary": {
+			operator: "$bitsAnySet",
+			mask:     types.Binary{B: []byte{0b100}},
+			expected: []string{"binary", "int32", "negative", "double"},
+		},
+		"AnySetWholeDoublePosition": {
+			operator: "$bitsAnySet",
+			mask:     a(2.0),
+			expected: []string{"binary", "int32", "negative", "double"},
+		},
+		"AllClearPositions": {
+			operator: "$bitsAllClear",
+			mask:     a(int32(1), int32(100)),
+			expected: []string{"binary", "int32", "double"},
+		},
+		"AnyClearPositions": {
+			operator: "$bitsAnyClear",
+			mask:     a(int32(0), int32(2)),
+			expected: []string{"double"},
+		},
+		"FractionalPosition": {
+			operator: "$bitsAllSet",
+			mask:     a(1.5),
+			err:      NewErrorMsg(ErrBadValue, "bit positions must be an integer but got: 0: 1.5"),
+		},
+		"NegativePosition": {
+			operator: "$bitsAnySet",
+			mask:     a(int64(-1)),
+			err:      NewErrorMsg(ErrBadValue, "bit positions must be >= 0 but got: 0: -1"),
+		},
+		"FractionalMask": {
+			operator: "$bitsAllClear",
+			mask:     1.5,
+			err:      NewErrorMsg(ErrFailedToParse, "Expected an integer: $bitsAllClear: 1.5"),
+		},
+	} {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			filter := d("a", d(tc.operator, tc.mask))
+
+			var actual []string
+			for docName, doc := range docs {
+				matches, err := FilterDocument(doc, filter)
+				if tc.err != nil {
+					// errors must not depend on the document
+					assert.Equal(t, tc.err, err, docName)
+					continue
+				}
+
+				require.NoError(t, err)
+
+				if matches {
+					actual = append(actual, docName)
+				}
+			}
+
+			if tc.err == nil {
+				assert.ElementsMatch(t, tc.expected, actual)
+			}
+		})
+	}
+}
+
+func TestFilterDocumentMissingFieldValidation(t *testing.T) {
+	t.Parallel()
+
+	d := func(pairs ...any) *types.Document { return must.NotFail(types.NewDocument(pairs...)) }
+	a := func(values ...any) *types.Array { return must.NotFail(types.NewArray(values...)) }
+
+	for name, tc := range map[string]struct {
+		expr *types.Document
+		err  error
+	}{
+		"Mod": {
+			expr: d("$mod", "x"),
+			err:  NewErrorMsg(ErrBadValue, "malformed mod, needs to be an array"),
+		},
+		"ModZeroDivisor": {
+			expr: d("$mod", a(int32(0), int32(1))),
+			err:  NewErrorMsg(ErrBadValue, "divisor cannot be 0"),
+		},
+		"Size": {
+			expr: d("$size", int32(-1)),
+			err:  NewErrorMsg(ErrBadValue, "$size may not be negative"),
+		},
+		"In": {
+			expr: d("$in", "x"),
+			err:  NewErrorMsg(ErrBadValue, "$in needs an array"),
+		},
+		"Nin": {
+			expr: d("$nin", "x"),
+			err:  NewErrorMsg(ErrBadValue, "$nin needs an array"),
+		},
+		"All": {
+			expr: d("$all", "x"),
+			err:  NewErrorMsg(ErrBadValue, "$all needs an array"),
+		},
+		"Regex": {
+			expr: d("$regex", int32(1)),
+			err:  NewErrorMsg(ErrBadValue, "$regex has to be a string"),
+		},
+	} {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			filter := d("v", tc.expr)
+
+			// the error is the same whether the field is present or not
+			_, err := FilterDocument(d("_id", int32(1), "v", int32(42)), filter)
+			assert.Equal(t, tc.err, err, "present")
+
+			_, err = FilterDocument(d("_id", int32(1)), filter)
+			assert.Equal(t, tc.err, err, "missing")
+		})
+	}
+
+	t.Run("Valid", func(t *testing.T) {
+		t.Parallel()
+
+		// valid expressions still do not match the missing field
+		for _, expr := range []*types.Document{
+			d("$mod", a(int32(2), int32(0))),
+			d("$size", int32(0)),
+			d("$type", "int"),
+			d("$bitsAllSet", int32(1)),
+		} {
+			res, err := FilterDocument(d("_id", int32(1)), d("v", expr))
+			require.NoError(t, err)
+			assert.False(t, res)
+		}
+	})
+}
@@ -31,3 +31,12 @@ func LimitDocuments(docs []*types.Document, limit int64) ([]*types.Document, err
 		return nil, NewErrorMsg(ErrNotImplemented, "LimitDocuments: negative limit values are not supported")
 	}
 }
+
+// SkipDocuments returns a subslice of given documents according to the given skip value.
+func SkipDocuments(docs []*types.Document, skip int64) []*types.Document {
+	if skip >= int64(len(docs)) {
+		return docs[:0]
+	}
+
+	return docs[skip:]
+}
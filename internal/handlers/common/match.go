@@ -21,6 +21,7 @@ import (
 	"golang.org/x/exp/slices"
 
 	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/must"
 )
 
 // matchDocuments returns true if 2 documents are equal.
@@ -40,3 +41,48 @@ func matchDocuments(a, b *types.Document) bool {
 	}
 	return reflect.DeepEqual(a.Map(), b.Map())
 }
+
+// matchArrays returns true if 2 arrays are equal:
+// they have the same length, and their elements are equal in the same order.
+//
+// Unlike types.Compare, nested arrays are compared as whole values, not by their elements,
+// and numbers of different types are equal if they represent the same value.
+func matchArrays(a, b *types.Array) bool {
+	if a == nil {
+		log.Panicf("%v is nil", a)
+	}
+	if b == nil {
+		log.Panicf("%v is nil", b)
+	}
+
+	if a.Len() != b.Len() {
+		return false
+	}
+
+	for i := 0; i < a.Len(); i++ {
+		if !matchValues(must.NotFail(a.Get(i)), must.NotFail(b.Get(i))) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// matchValues returns true if 2 BSON values are equal.
+func matchValues(a, b any) bool {
+	switch a := a.(type) {
+	case *types.Document:
+		b, ok := b.(*types.Document)
+		return ok && matchDocuments(a, b)
+	case *types.Array:
+		b, ok := b.(*types.Array)
+		return ok && matchArrays(a, b)
+	}
+
+	switch b.(type) {
+	case *types.Document, *types.Array:
+		return false
+	}
+
+	return types.ContainsCompareResult(types.Compare(a, b), types.Equal)
+}
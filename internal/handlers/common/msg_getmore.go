@@ -0,0 +1,89 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package common
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+	"github.com/FerretDB/FerretDB/internal/wire"
+)
+
+// defaultMaxAwaitTimeMS is the default time getMore waits for new documents of tailable cursors, the same as MongoDB's.
+const defaultMaxAwaitTimeMS = 1000
+
+// MsgGetMore is a common implementation of the getMore command
+// for handlers that store cursors in the given registry.
+func MsgGetMore(ctx context.Context, msg *wire.OpMsg, cursors *Cursors) (*wire.OpMsg, error) {
+	document, err := msg.Document()
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	db, err := GetRequiredParam[string](document, "$db")
+	if err != nil {
+		return nil, err
+	}
+
+	id, ok := must.NotFail(document.Get(document.Command())).(int64)
+	if !ok {
+		return nil, NewErrorMsg(
+			ErrTypeMismatch,
+			fmt.Sprintf(
+				"BSON field 'getMore.getMore' is the wrong type '%s', expected type 'long'",
+				AliasFromType(must.NotFail(document.Get(document.Command()))),
+			),
+		)
+	}
+
+	collection, err := GetRequiredParam[string](document, "collection")
+	if err != nil {
+		return nil, err
+	}
+
+	batchSize, err := GetOptionalPositiveNumber(document, "batchSize")
+	if err != nil {
+		return nil, err
+	}
+
+	// for tailable cursors, maxTimeMS is the maximum time to wait for new documents
+	maxTimeMS, err := GetOptionalPositiveNumber(document, "maxTimeMS")
+	if err != nil {
+		return nil, err
+	}
+
+	if maxTimeMS == 0 {
+		maxTimeMS = defaultMaxAwaitTimeMS
+	}
+
+	cursor, err := cursors.NextBatch(ctx, id, db+"."+collection, batchSize, time.Duration(maxTimeMS)*time.Millisecond)
+	if err != nil {
+		return nil, err
+	}
+
+	var reply wire.OpMsg
+	must.NoError(reply.SetSections(wire.OpMsgSection{
+		Documents: []*types.Document{must.NotFail(types.NewDocument(
+			"cursor", cursor,
+			"ok", float64(1),
+		))},
+	}))
+
+	return &reply, nil
+}
@@ -0,0 +1,88 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package common
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+	"github.com/FerretDB/FerretDB/internal/wire"
+)
+
+// MsgKillCursors is a common implementation of the killCursors command
+// for handlers that store cursors in the given registry.
+func MsgKillCursors(ctx context.Context, msg *wire.OpMsg, cursors *Cursors) (*wire.OpMsg, error) {
+	document, err := msg.Document()
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	db, err := GetRequiredParam[string](document, "$db")
+	if err != nil {
+		return nil, err
+	}
+
+	collectionParam := must.NotFail(document.Get(document.Command()))
+	collection, ok := collectionParam.(string)
+	if !ok {
+		return nil, NewErrorMsg(
+			ErrInvalidNamespace,
+			fmt.Sprintf("collection name has invalid type %s", AliasFromType(collectionParam)),
+		)
+	}
+
+	ids, err := GetRequiredParam[*types.Array](document, "cursors")
+	if err != nil {
+		return nil, err
+	}
+
+	ns := db + "." + collection
+	killed := types.MakeArray(0)
+	notFound := types.MakeArray(0)
+
+	for i := 0; i < ids.Len(); i++ {
+		v := must.NotFail(ids.Get(i))
+
+		id, ok := v.(int64)
+		if !ok {
+			return nil, NewErrorMsg(
+				ErrTypeMismatch,
+				fmt.Sprintf("BSON field 'killCursors.cursors.%d' is the wrong type '%s', expected type 'long'", i, AliasFromType(v)),
+			)
+		}
+
+		if cursors.Kill(ctx, id, ns) {
+			must.NoError(killed.Append(id))
+		} else {
+			must.NoError(notFound.Append(id))
+		}
+	}
+
+	var reply wire.OpMsg
+	must.NoError(reply.SetSections(wire.OpMsgSection{
+		Documents: []*types.Document{must.NotFail(types.NewDocument(
+			"cursorsKilled", killed,
+			"cursorsNotFound", notFound,
+			"cursorsAlive", types.MakeArray(0),
+			"cursorsUnknown", types.MakeArray(0),
+			"ok", float64(1),
+		))},
+	}))
+
+	return &reply, nil
+}
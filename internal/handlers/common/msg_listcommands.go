@@ -42,6 +42,10 @@ type command struct {
 // Please keep help text in sync with handlers.Interface methods documentation.
 var Commands = map[string]command{
 	// sorted alphabetically
+	"abortTransaction": {
+		Help:    "Aborts the current multi-document transaction.",
+		Handler: (handlers.Interface).MsgAbortTransaction,
+	},
 	"aggregate": {
 		Help:    "Returns aggregated data.",
 		Handler: (handlers.Interface).MsgAggregate,
@@ -62,6 +66,10 @@ var Commands = map[string]command{
 		Help:    "Returns storage data for a collection.",
 		Handler: (handlers.Interface).MsgCollStats,
 	},
+	"commitTransaction": {
+		Help:    "Commits the current multi-document transaction.",
+		Handler: (handlers.Interface).MsgCommitTransaction,
+	},
 	"connectionStatus": {
 		Help: "Returns information about the current connection, " +
 			"specifically the state of authenticated users and their available permissions.",
@@ -95,6 +103,10 @@ var Commands = map[string]command{
 		Help:    "Deletes documents matched by the query.",
 		Handler: (handlers.Interface).MsgDelete,
 	},
+	"distinct": {
+		Help:    "Returns an array of distinct values for the given field.",
+		Handler: (handlers.Interface).MsgDistinct,
+	},
 	"drop": {
 		Help:    "Drops the collection.",
 		Handler: (handlers.Interface).MsgDrop,
@@ -103,6 +115,10 @@ var Commands = map[string]command{
 		Help:    "Drops production database.",
 		Handler: (handlers.Interface).MsgDropDatabase,
 	},
+	"dropIndexes": {
+		Help:    "Drops indexes on a collection.",
+		Handler: (handlers.Interface).MsgDropIndexes,
+	},
 	"explain": {
 		Help:    "Returns the execution plan.",
 		Handler: (handlers.Interface).MsgExplain,
@@ -127,6 +143,10 @@ var Commands = map[string]command{
 		Help:    "Returns the most recent logged events from memory.",
 		Handler: (handlers.Interface).MsgGetLog,
 	},
+	"getMore": {
+		Help:    "Returns the next batch of documents from a cursor.",
+		Handler: (handlers.Interface).MsgGetMore,
+	},
 	"getParameter": {
 		Help:    "Returns the value of the parameter.",
 		Handler: (handlers.Interface).MsgGetParameter,
@@ -151,6 +171,10 @@ var Commands = map[string]command{
 		Help:    "Returns the role of the FerretDB instance.",
 		Handler: (handlers.Interface).MsgIsMaster,
 	},
+	"killCursors": {
+		Help:    "Closes server-side cursors.",
+		Handler: (handlers.Interface).MsgKillCursors,
+	},
 	"listCollections": {
 		Help:    "Returns the information of the collections and views in the database.",
 		Handler: (handlers.Interface).MsgListCollections,
@@ -163,10 +187,18 @@ var Commands = map[string]command{
 		Help:    "Returns a summary of all the databases.",
 		Handler: (handlers.Interface).MsgListDatabases,
 	},
+	"listIndexes": {
+		Help:    "Returns a summary of indexes of the specified collection.",
+		Handler: (handlers.Interface).MsgListIndexes,
+	},
 	"ping": {
 		Help:    "Returns a pong response.",
 		Handler: (handlers.Interface).MsgPing,
 	},
+	"renameCollection": {
+		Help:    "Changes the name of an existing collection.",
+		Handler: (handlers.Interface).MsgRenameCollection,
+	},
 	"serverStatus": {
 		Help:    "Returns an overview of the databases state.",
 		Handler: (handlers.Interface).MsgServerStatus,
@@ -17,6 +17,7 @@ package common
 import (
 	"fmt"
 	"math"
+	"strconv"
 
 	"github.com/FerretDB/FerretDB/internal/types"
 	"github.com/FerretDB/FerretDB/internal/util/must"
@@ -137,82 +138,106 @@ func GetWholeNumberParam(value any) (int64, error) {
 	}
 }
 
-// getBinaryMaskParam matches value type, returning bit mask and error if match failed.
-// Possible values are: position array ([1,3,5] == 010101), whole number value and types.Binary value.
-func getBinaryMaskParam(mask any) (uint64, error) {
-	var bitmask uint64
+// getBitPositionsParam matches value type, returning bit positions and error if match failed.
+// Possible values are: position array ([1,3,5] == 101010), whole number value and types.Binary value.
+func getBitPositionsParam(mask any) ([]int64, error) {
+	var positions []int64
 
 	switch mask := mask.(type) {
 	case *types.Array:
 		// {field: {$bitsAllClear: [position1, position2]}}
 		for i := 0; i < mask.Len(); i++ {
 			val := must.NotFail(mask.Get(i))
-			b, ok := val.(int32)
-			if !ok {
-				return 0, NewError(ErrBadValue, fmt.Errorf(`bit positions must be an integer but got: %d: %#v`, i, val))
+
+			var b int64
+
+			switch val := val.(type) {
+			case int32:
+				b = int64(val)
+			case int64:
+				b = val
+			case float64:
+				if val != math.Trunc(val) || math.IsInf(val, 0) || val > math.MaxInt64 || val < math.MinInt64 {
+					return nil, NewError(ErrBadValue, fmt.Errorf(`bit positions must be an integer but got: %d: %#v`, i, val))
+				}
+
+				b = int64(val)
+			default:
+				return nil, NewError(ErrBadValue, fmt.Errorf(`bit positions must be an integer but got: %d: %#v`, i, val))
 			}
 
 			if b < 0 {
-				return 0, NewError(ErrBadValue, fmt.Errorf("bit positions must be >= 0 but got: %d: %d", i, b))
+				return nil, NewError(ErrBadValue, fmt.Errorf("bit positions must be >= 0 but got: %d: %d", i, b))
 			}
 
-			bitmask |= 1 << uint64(math.Min(float64(b), 63))
+			positions = append(positions, b)
 		}
 
 	case float64:
 		// {field: {$bitsAllClear: bitmask}}
 		// TODO check float negative zero
 		if mask != math.Trunc(mask) || math.IsNaN(mask) || math.IsInf(mask, 0) {
-			return 0, errNotWholeNumber
+			return nil, errNotWholeNumber
 		}
 
 		if mask < 0 {
-			return 0, errNegativeNumber
+			return nil, errNegativeNumber
 		}
 
-		bitmask = uint64(mask)
+		positions = bitPositions(uint64(mask))
 
 	case types.Binary:
 		// {field: {$bitsAllClear: BinData()}}
-		for b := 0; b < len(mask.B); b++ {
-			byteAt := mask.B[b]
-
-			if byteAt == 0 {
-				continue
-			}
-
-			if b < 8 {
-				bitmask |= uint64(byteAt) << uint64(b*8)
-			} else {
-				bitmask |= 1 << 63
+		for i, byteAt := range mask.B {
+			for bit := 0; bit < 8; bit++ {
+				if byteAt&(1<<bit) != 0 {
+					positions = append(positions, int64(i*8+bit))
+				}
 			}
 		}
 
 	case int32:
 		// {field: {$bitsAllClear: bitmask}}
 		if mask < 0 {
-			return 0, errNegativeNumber
+			return nil, errNegativeNumber
 		}
 
-		bitmask = uint64(mask)
+		positions = bitPositions(uint64(mask))
 
 	case int64:
 		// {field: {$bitsAllClear: bitmask}}
 		if mask < 0 {
-			return 0, errNegativeNumber
+			return nil, errNegativeNumber
 		}
 
-		bitmask = uint64(mask)
+		positions = bitPositions(uint64(mask))
 
 	default:
-		return 0, errNotBinaryMask
+		return nil, errNotBinaryMask
+	}
+
+	return positions, nil
+}
+
+// bitPositions returns positions of set bits of the given bitmask.
+func bitPositions(bitmask uint64) []int64 {
+	var positions []int64
+
+	for i := int64(0); i < 64; i++ {
+		if bitmask&(1<<i) != 0 {
+			positions = append(positions, i)
+		}
 	}
 
-	return bitmask, nil
+	return positions
 }
 
 // parseTypeCode returns typeCode and error by given type code alias.
 func parseTypeCode(alias string) (typeCode, error) {
+	if alias == "undefined" {
+		return 0, errUndefinedTypeCode
+	}
+
 	code, ok := aliasToTypeCode[alias]
 	if !ok {
 		return 0, NewErrorMsg(ErrBadValue, fmt.Sprintf(`Unknown type name alias: %s`, alias))
@@ -307,3 +332,144 @@ func GetOptionalPositiveNumber(document *types.Document, key string) (int32, err
 
 	return value, nil
 }
+
+// GetOptionalIntegerParam returns the value of the optional numeric field of the given document
+// as an integer, such as used for limit and skip.
+//
+// Whole-valued doubles are accepted; doubles with fractional parts return FailedToParse error
+// with the same message as MongoDB.
+func GetOptionalIntegerParam(document *types.Document, key string) (int64, error) {
+	v, err := document.Get(key)
+	if err != nil {
+		return 0, nil
+	}
+
+	switch v := v.(type) {
+	case float64:
+		switch {
+		case math.IsNaN(v):
+			return 0, NewErrorMsg(
+				ErrFailedToParse,
+				fmt.Sprintf("Expected an integer, but found NaN in: %s: nan.0", key),
+			)
+		case v >= math.MaxInt64 || v < math.MinInt64:
+			return 0, NewErrorMsg(
+				ErrFailedToParse,
+				fmt.Sprintf("Cannot represent as a 64-bit integer: %s: %s", key, formatDouble(v)),
+			)
+		case v != math.Trunc(v):
+			return 0, NewErrorMsg(
+				ErrFailedToParse,
+				fmt.Sprintf("Expected an integer: %s: %s", key, formatDouble(v)),
+			)
+		}
+
+		return int64(v), nil
+
+	case int32:
+		return int64(v), nil
+
+	case int64:
+		return v, nil
+
+	default:
+		return 0, NewErrorMsg(
+			ErrTypeMismatch,
+			fmt.Sprintf(
+				"BSON field '%s' is the wrong type '%s', expected types '[long, int, decimal, double]'",
+				key, AliasFromType(v),
+			),
+		)
+	}
+}
+
+// formatDouble formats the given double the same way as MongoDB does in error messages.
+func formatDouble(v float64) string {
+	switch {
+	case math.IsInf(v, 1):
+		return "inf.0"
+	case math.IsInf(v, -1):
+		return "-inf.0"
+	default:
+		return strconv.FormatFloat(v, 'g', -1, 64)
+	}
+}
+
+// GetSkipParam returns the value of the optional skip field of the given command document.
+// Negative values return BadValue error.
+func GetSkipParam(document *types.Document) (int64, error) {
+	skip, err := GetOptionalIntegerParam(document, "skip")
+	if err != nil {
+		return 0, err
+	}
+
+	if skip < 0 {
+		return 0, NewErrorMsg(
+			ErrBadValue,
+			fmt.Sprintf("BSON field 'skip' value must be >= 0, actual value '%d'", skip),
+		)
+	}
+
+	return skip, nil
+}
+
+// GetLimitParam returns the value of the optional limit field of the given command document.
+//
+// Negative values have the legacy meaning: the absolute value is used as the limit,
+// and all results should be returned in a single batch; that is reported by the second returned value.
+func GetLimitParam(document *types.Document) (int64, bool, error) {
+	limit, err := GetOptionalIntegerParam(document, "limit")
+	if err != nil {
+		return 0, false, err
+	}
+
+	if limit < 0 {
+		if limit == math.MinInt64 {
+			return math.MaxInt64, true, nil
+		}
+
+		return -limit, true, nil
+	}
+
+	return limit, false, nil
+}
+
+// GetScaleParam returns the value of the optional scale field of the given command document,
+// such as used by collStats and dbStats.
+//
+// Numbers are truncated to integers; missing and null values mean 1.
+// Values less than 1 and values of other types return BadValue error.
+func GetScaleParam(document *types.Document) (int64, error) {
+	v, err := document.Get("scale")
+	if err != nil {
+		return 1, nil
+	}
+
+	var scale int64
+
+	switch v := v.(type) {
+	case types.NullType:
+		return 1, nil
+	case float64:
+		switch {
+		case math.IsNaN(v) || v < 1:
+			scale = 0
+		case v > math.MaxInt32:
+			scale = math.MaxInt32
+		default:
+			scale = int64(v)
+		}
+	case int32:
+		scale = int64(v)
+	case int64:
+		scale = v
+	default:
+		return 0, NewErrorMsg(ErrBadValue, "scale has to be a number >= 1")
+	}
+
+	if scale < 1 {
+		return 0, NewErrorMsg(ErrBadValue, "scale has to be >= 1")
+	}
+
+	return scale, nil
+}
@@ -0,0 +1,157 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package common
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+)
+
+func TestGetOptionalIntegerParam(t *testing.T) {
+	t.Parallel()
+
+	for name, tc := range map[string]struct {
+		value    any // nil means missing field
+		expected int64
+		err      error
+	}{
+		"Missing": {},
+		"Int32": {
+			value:    int32(5),
+			expected: 5,
+		},
+		"Int64": {
+			value:    int64(-5),
+			expected: -5,
+		},
+		"WholeDouble": {
+			value:    5.0,
+			expected: 5,
+		},
+		"FractionalDouble": {
+			value: 5.5,
+			err:   NewErrorMsg(ErrFailedToParse, "Expected an integer: limit: 5.5"),
+		},
+		"NaN": {
+			value: math.NaN(),
+			err:   NewErrorMsg(ErrFailedToParse, "Expected an integer, but found NaN in: limit: nan.0"),
+		},
+		"Infinity": {
+			value: math.Inf(1),
+			err:   NewErrorMsg(ErrFailedToParse, "Cannot represent as a 64-bit integer: limit: inf.0"),
+		},
+		"String": {
+			value: "5",
+			err: NewErrorMsg(
+				ErrTypeMismatch,
+				"BSON field 'limit' is the wrong type 'string', expected types '[long, int, decimal, double]'",
+			),
+		},
+	} {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			doc := must.NotFail(types.NewDocument())
+			if tc.value != nil {
+				must.NoError(doc.Set("limit", tc.value))
+			}
+
+			actual, err := GetOptionalIntegerParam(doc, "limit")
+			if tc.err != nil {
+				assert.Equal(t, tc.err, err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, actual)
+		})
+	}
+}
+
+func TestGetScaleParam(t *testing.T) {
+	t.Parallel()
+
+	for name, tc := range map[string]struct {
+		value    any // nil means missing field
+		expected int64
+		err      error
+	}{
+		"Missing": {
+			expected: 1,
+		},
+		"Null": {
+			value:    types.Null,
+			expected: 1,
+		},
+		"Int32": {
+			value:    int32(1024),
+			expected: 1024,
+		},
+		"Int64": {
+			value:    int64(1024),
+			expected: 1024,
+		},
+		"FractionalDouble": {
+			value:    1024.9,
+			expected: 1024,
+		},
+		"Zero": {
+			value: int32(0),
+			err:   NewErrorMsg(ErrBadValue, "scale has to be >= 1"),
+		},
+		"Negative": {
+			value: int64(-1),
+			err:   NewErrorMsg(ErrBadValue, "scale has to be >= 1"),
+		},
+		"SmallDouble": {
+			value: 0.5,
+			err:   NewErrorMsg(ErrBadValue, "scale has to be >= 1"),
+		},
+		"NaN": {
+			value: math.NaN(),
+			err:   NewErrorMsg(ErrBadValue, "scale has to be >= 1"),
+		},
+		"String": {
+			value: "1",
+			err:   NewErrorMsg(ErrBadValue, "scale has to be a number >= 1"),
+		},
+	} {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			doc := must.NotFail(types.NewDocument())
+			if tc.value != nil {
+				must.NoError(doc.Set("scale", tc.value))
+			}
+
+			actual, err := GetScaleParam(doc)
+			if tc.err != nil {
+				assert.Equal(t, tc.err, err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, actual)
+		})
+	}
+}
@@ -0,0 +1,170 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package common
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+)
+
+// ResolvePositionalOperator returns the update document with the positional operator `$`
+// in update operators' paths replaced by the index of the first array element matched by the filter,
+// like {$inc: {"items.$.qty": 1}} becomes {$inc: {"items.2.qty": 1}}.
+//
+// The given update document is returned as is if it doesn't contain the positional operator.
+// Passed arguments must not be modified.
+func ResolvePositionalOperator(doc, filter, update *types.Document) (*types.Document, error) {
+	if update == nil {
+		return nil, nil
+	}
+
+	var res *types.Document
+
+	for _, op := range update.Keys() {
+		opValue := must.NotFail(update.Get(op))
+
+		opDoc, ok := opValue.(*types.Document)
+		if !strings.HasPrefix(op, "$") || !ok {
+			continue
+		}
+
+		var resolved *types.Document
+
+		for _, key := range opDoc.Keys() {
+			resolvedKey, err := resolvePositionalPath(doc, filter, key)
+			if err != nil {
+				return nil, err
+			}
+
+			if resolvedKey == key && resolved == nil {
+				continue
+			}
+
+			if resolved == nil {
+				// copy keys that were processed before the first positional one
+				resolved = must.NotFail(types.NewDocument())
+
+				for _, k := range opDoc.Keys() {
+					if k == key {
+						break
+					}
+
+					must.NoError(resolved.Set(k, must.NotFail(opDoc.Get(k))))
+				}
+			}
+
+			must.NoError(resolved.Set(resolvedKey, must.NotFail(opDoc.Get(key))))
+		}
+
+		if resolved == nil {
+			continue
+		}
+
+		if res == nil {
+			res = update.DeepCopy()
+		}
+
+		must.NoError(res.Set(op, resolved))
+	}
+
+	if res == nil {
+		return update, nil
+	}
+
+	return res, nil
+}
+
+// resolvePositionalPath returns the path with the positional operator `$` replaced
+// by the index of the first element of the array at the preceding path matched by the filter.
+// The path is returned as is if it doesn't contain the positional operator.
+func resolvePositionalPath(doc, filter *types.Document, path string) (string, error) {
+	parts := strings.Split(path, ".")
+
+	pos := -1
+
+	for i, part := range parts {
+		if part != "$" {
+			continue
+		}
+
+		if pos != -1 {
+			return "", NewWriteErrorMsg(
+				ErrBadValue,
+				fmt.Sprintf("Too many positional (i.e. '$') elements found in path '%s'", path),
+			)
+		}
+
+		pos = i
+	}
+
+	if pos == -1 {
+		return path, nil
+	}
+
+	notFound := NewWriteErrorMsg(ErrBadValue, "The positional operator did not find the match needed from the query.")
+
+	if pos == 0 || filter == nil {
+		return "", notFound
+	}
+
+	arrayPath := strings.Join(parts[:pos], ".")
+
+	v, err := doc.GetByPath(types.NewPathFromString(arrayPath))
+	if err != nil {
+		return "", notFound
+	}
+
+	arr, ok := v.(*types.Array)
+	if !ok {
+		return "", notFound
+	}
+
+	// only filter conditions on the array itself or on its elements' fields are used
+	arrayFilter := must.NotFail(types.NewDocument())
+
+	for _, key := range filter.Keys() {
+		if key == arrayPath || strings.HasPrefix(key, arrayPath+".") {
+			must.NoError(arrayFilter.Set(key, must.NotFail(filter.Get(key))))
+		}
+	}
+
+	if arrayFilter.Len() == 0 {
+		return "", notFound
+	}
+
+	// check each element separately by replacing the array with a single-element one in the document copy
+	candidate := doc.DeepCopy()
+
+	for i := 0; i < arr.Len(); i++ {
+		single := must.NotFail(types.NewArray(must.NotFail(arr.Get(i))))
+		must.NoError(candidate.SetByPath(types.NewPathFromString(arrayPath), single))
+
+		matches, err := FilterDocument(candidate, arrayFilter)
+		if err != nil {
+			return "", err
+		}
+
+		if matches {
+			parts[pos] = strconv.Itoa(i)
+			return strings.Join(parts, "."), nil
+		}
+	}
+
+	return "", notFound
+}
@@ -0,0 +1,101 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package common
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+)
+
+func TestResolvePositionalOperator(t *testing.T) {
+	t.Parallel()
+
+	doc := must.NotFail(types.NewDocument(
+		"_id", "doc",
+		"items", must.NotFail(types.NewArray(
+			must.NotFail(types.NewDocument("sku", "a", "qty", int32(1))),
+			must.NotFail(types.NewDocument("sku", "b", "qty", int32(2))),
+		)),
+		"grades", must.NotFail(types.NewArray(int32(80), int32(90), int32(95))),
+	))
+
+	for name, tc := range map[string]struct {
+		filter   *types.Document
+		update   *types.Document
+		expected *types.Document
+		err      string
+	}{
+		"NestedField": {
+			filter: must.NotFail(types.NewDocument("items.sku", "b")),
+			update: must.NotFail(types.NewDocument(
+				"$inc", must.NotFail(types.NewDocument("items.$.qty", int32(1))),
+			)),
+			expected: must.NotFail(types.NewDocument(
+				"$inc", must.NotFail(types.NewDocument("items.1.qty", int32(1))),
+			)),
+		},
+		"Scalar": {
+			filter: must.NotFail(types.NewDocument(
+				"_id", "doc",
+				"grades", must.NotFail(types.NewDocument("$gte", int32(85))),
+			)),
+			update: must.NotFail(types.NewDocument(
+				"$set", must.NotFail(types.NewDocument("other", int32(0), "grades.$", int32(0))),
+			)),
+			expected: must.NotFail(types.NewDocument(
+				"$set", must.NotFail(types.NewDocument("other", int32(0), "grades.1", int32(0))),
+			)),
+		},
+		"NoPositional": {
+			filter:   must.NotFail(types.NewDocument("items.sku", "b")),
+			update:   must.NotFail(types.NewDocument("$set", must.NotFail(types.NewDocument("v", int32(1))))),
+			expected: must.NotFail(types.NewDocument("$set", must.NotFail(types.NewDocument("v", int32(1))))),
+		},
+		"NotMatched": {
+			filter: must.NotFail(types.NewDocument("_id", "doc")),
+			update: must.NotFail(types.NewDocument(
+				"$inc", must.NotFail(types.NewDocument("items.$.qty", int32(1))),
+			)),
+			err: "The positional operator did not find the match needed from the query.",
+		},
+		"TooMany": {
+			filter: must.NotFail(types.NewDocument("items.sku", "b")),
+			update: must.NotFail(types.NewDocument(
+				"$inc", must.NotFail(types.NewDocument("items.$.qty.$", int32(1))),
+			)),
+			err: "Too many positional (i.e. '$') elements found in path 'items.$.qty.$'",
+		},
+	} {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			actual, err := ResolvePositionalOperator(doc, tc.filter, tc.update)
+			if tc.err != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tc.err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, actual)
+		})
+	}
+}
@@ -18,6 +18,7 @@ import (
 	"fmt"
 	"math"
 	"strconv"
+	"strings"
 
 	"golang.org/x/exp/slices"
 
@@ -37,6 +38,14 @@ func isProjectionInclusion(projection *types.Document) (inclusion bool, err erro
 		v := must.NotFail(projection.Get(k))
 		switch v := v.(type) {
 		case *types.Document:
+			if v.Has("$meta") {
+				// $meta is neither inclusion nor exclusion
+				if err = validateMetaProjection(v); err != nil {
+					return
+				}
+				continue
+			}
+
 			for _, projectionType := range v.Keys() {
 				supportedProjectionTypes := []string{"$elemMatch", "$slice"}
 				if !slices.Contains(supportedProjectionTypes, projectionType) {
@@ -48,7 +57,8 @@ func isProjectionInclusion(projection *types.Document) (inclusion bool, err erro
 				case "$elemMatch":
 					inclusion = true
 				case "$slice":
-					inclusion = false
+					// $slice is neither inclusion nor exclusion,
+					// so it could be combined with both types of projection
 				default:
 					panic(projectionType + " not supported")
 				}
@@ -101,20 +111,38 @@ func isProjectionInclusion(projection *types.Document) (inclusion bool, err erro
 	return
 }
 
-// ProjectDocuments modifies given documents in places according to the given projection.
-func ProjectDocuments(docs []*types.Document, projection *types.Document) error {
+// ValidateProjection returns an error if the given projection is invalid.
+//
+// It allows commands to reject invalid projections before fetching or modifying any documents.
+func ValidateProjection(projection *types.Document) error {
 	if projection.Len() == 0 {
 		return nil
 	}
 
-	inclusion, err := isProjectionInclusion(projection)
-	if err != nil {
+	_, _, err := parseProjection(projection)
+
+	return err
+}
+
+// ProjectDocuments replaces given documents with their copies with applied projection.
+//
+// The projection is validated even if there are no documents.
+func ProjectDocuments(docs []*types.Document, projection *types.Document) error {
+	if err := ValidateProjection(projection); err != nil {
 		return err
 	}
 
-	for i := 0; i < len(docs); i++ {
-		err = projectDocument(inclusion, docs[i], projection)
-		if err != nil {
+	if projection.Len() == 0 || len(docs) == 0 {
+		return nil
+	}
+
+	fields, inclusion, err := parseProjection(projection)
+	if err != nil {
+		return lazyerrors.Error(err)
+	}
+
+	for i, doc := range docs {
+		if docs[i], err = projectDocument(doc, fields, inclusion); err != nil {
 			return err
 		}
 	}
@@ -122,43 +150,317 @@ func ProjectDocuments(docs []*types.Document, projection *types.Document) error
 	return nil
 }
 
-func projectDocument(inclusion bool, doc *types.Document, projection *types.Document) error {
-	projectionMap := projection.Map()
+// ProjectDocument returns a copy of the given document with applied projection.
+//
+// Projection could be either inclusion like {v: 1, "w.foo": 1} or exclusion like {v: 0, w: {foo: 0}};
+// _id field is included by default, but could be excluded from inclusion projection.
+// The given document is not modified.
+func ProjectDocument(doc, projection *types.Document) (*types.Document, error) {
+	if projection.Len() == 0 {
+		return doc.DeepCopy(), nil
+	}
 
-	for k1 := range doc.Map() {
-		projectionVal, ok := projectionMap[k1]
-		if !ok {
-			if k1 == "_id" { // if _id is not in projection map, do not do anything with it
-				continue
+	fields, inclusion, err := parseProjection(projection)
+	if err != nil {
+		return nil, err
+	}
+
+	return projectDocument(doc, fields, inclusion)
+}
+
+// parseProjection validates the given projection and returns it with nested sub-projections
+// replaced by dotted paths, and the projection type.
+func parseProjection(projection *types.Document) (fields *types.Document, inclusion bool, err error) {
+	fields = types.MakeDocument(projection.Len())
+	if err = flattenProjection("", projection, fields); err != nil {
+		return
+	}
+
+	for _, a := range fields.Keys() {
+		for _, b := range fields.Keys() {
+			if strings.HasPrefix(b, a+".") {
+				err = NewErrorMsg(
+					ErrProjectionPathCollision,
+					fmt.Sprintf("Path collision at %s remaining portion %s", b, strings.TrimPrefix(b, a+".")),
+				)
+
+				return
 			}
-			if inclusion { // k1 from doc is absent in projection, remove from doc only if projection type inclusion
-				doc.Remove(k1)
+		}
+	}
+
+	if inclusion, err = isProjectionInclusion(fields); err != nil {
+		return
+	}
+
+	// {_id: 1} alone is an inclusion projection
+	if fields.Len() == 1 && fields.Has("_id") {
+		inclusion, _ = projectionFlag(must.NotFail(fields.Get("_id")))
+	}
+
+	// _id is included unless excluded explicitly
+	if inclusion && !fields.Has("_id") {
+		must.NoError(fields.Set("_id", true))
+	}
+
+	return
+}
+
+// flattenProjection adds fields of the given projection to res, replacing nested sub-projections
+// like {v: {foo: 1}} with dotted paths like {"v.foo": 1}.
+func flattenProjection(prefix string, projection, res *types.Document) error {
+	for _, k := range projection.Keys() {
+		v := must.NotFail(projection.Get(k))
+
+		path := k
+		if prefix != "" {
+			path = prefix + "." + k
+		}
+
+		sub, ok := v.(*types.Document)
+		if !ok || isOperatorProjection(sub) {
+			if ok && strings.Contains(path, ".") {
+				return NewErrorMsg(
+					ErrNotImplemented,
+					fmt.Sprintf("projection operator on the nested field %q is not implemented yet", path),
+				)
 			}
+
+			must.NoError(res.Set(path, v))
+
 			continue
 		}
 
-		switch projectionVal := projectionVal.(type) { // found in the projection
-		case *types.Document: // field: { $elemMatch: { field2: value }}
-			if err := applyComplexProjection(k1, doc, projectionVal); err != nil {
-				return err
+		if sub.Len() == 0 {
+			return NewErrorMsg(
+				ErrProjectionEmptySubProjection,
+				fmt.Sprintf("An empty sub-projection is not a valid value. Found empty object at path %s", path),
+			)
+		}
+
+		if err := flattenProjection(path, sub, res); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// isOperatorProjection returns true if the given projection value is an operator
+// like {$slice: 1} rather than a sub-projection.
+func isOperatorProjection(v *types.Document) bool {
+	return strings.HasPrefix(v.Command(), "$")
+}
+
+// projectionFlag returns inclusion flag and true if the given projection value is a number or a boolean.
+func projectionFlag(v any) (include bool, ok bool) {
+	switch v := v.(type) {
+	case bool:
+		return v, true
+	case float64:
+		return v != 0, true
+	case int32:
+		return v != 0, true
+	case int64:
+		return v != 0, true
+	default:
+		return false, false
+	}
+}
+
+// projectDocument returns a copy of the given document with applied projection fields
+// returned by parseProjection.
+func projectDocument(doc, fields *types.Document, inclusion bool) (*types.Document, error) {
+	var res *types.Document
+
+	if inclusion {
+		res = includeFields(doc, fields, "")
+	} else {
+		res = doc.DeepCopy()
+		excludeFields(res, fields, "")
+	}
+
+	for _, k := range fields.Keys() {
+		operator, ok := must.NotFail(fields.Get(k)).(*types.Document)
+		if !ok {
+			continue
+		}
+
+		if isMetaProjection(operator) {
+			// $meta "indexKey" is the only one supported (see validateMetaProjection).
+			// Queries always use a collection scan as indexes are not used for them,
+			// so there are no index key fields.
+			must.NoError(res.Set(k, must.NotFail(types.NewDocument())))
+			continue
+		}
+
+		if !res.Has(k) {
+			continue
+		}
+
+		if err := applyComplexProjection(k, res, operator); err != nil {
+			return nil, err
+		}
+	}
+
+	return res, nil
+}
+
+// includeFields returns a copy of the given document (found at prefix path)
+// that contains only fields included by the projection.
+//
+// Fields with projection operators other than $meta are included as is.
+func includeFields(doc, fields *types.Document, prefix string) *types.Document {
+	res := types.MakeDocument(0)
+
+	for _, k := range doc.Keys() {
+		path := k
+		if prefix != "" {
+			path = prefix + "." + k
+		}
+
+		v := must.NotFail(doc.Get(k))
+
+		if projectionVal, err := fields.Get(path); err == nil {
+			if operator, ok := projectionVal.(*types.Document); ok {
+				if !isMetaProjection(operator) {
+					must.NoError(res.Set(k, deepCopyValue(v)))
+				}
+
+				continue
 			}
 
-		case float64, int32, int64: // field: number
-			result := types.Compare(projectionVal, int32(0))
-			if types.ContainsCompareResult(result, types.Equal) {
-				doc.Remove(k1)
+			if include, _ := projectionFlag(projectionVal); include {
+				must.NoError(res.Set(k, deepCopyValue(v)))
 			}
 
-		case bool: // field: bool
-			if !projectionVal {
-				doc.Remove(k1)
+			continue
+		}
+
+		if !hasProjectionPrefix(fields, path) {
+			continue
+		}
+
+		switch v := v.(type) {
+		case *types.Document:
+			must.NoError(res.Set(k, includeFields(v, fields, path)))
+		case *types.Array:
+			must.NoError(res.Set(k, includeArrayFields(v, fields, path)))
+		}
+	}
+
+	return res
+}
+
+// includeArrayFields returns a copy of the given array (found at prefix path)
+// with included fields of documents; other values are removed.
+func includeArrayFields(arr *types.Array, fields *types.Document, prefix string) *types.Array {
+	res := types.MakeArray(arr.Len())
+
+	for i := 0; i < arr.Len(); i++ {
+		switch v := must.NotFail(arr.Get(i)).(type) {
+		case *types.Document:
+			must.NoError(res.Append(includeFields(v, fields, prefix)))
+		case *types.Array:
+			must.NoError(res.Append(includeArrayFields(v, fields, prefix)))
+		}
+	}
+
+	return res
+}
+
+// excludeFields removes fields excluded by the projection from the given document
+// (found at prefix path) in place.
+func excludeFields(doc, fields *types.Document, prefix string) {
+	for _, k := range slices.Clone(doc.Keys()) {
+		path := k
+		if prefix != "" {
+			path = prefix + "." + k
+		}
+
+		if projectionVal, err := fields.Get(path); err == nil {
+			if include, ok := projectionFlag(projectionVal); ok && !include {
+				doc.Remove(k)
 			}
 
+			continue
+		}
+
+		if !hasProjectionPrefix(fields, path) {
+			continue
+		}
+
+		switch v := must.NotFail(doc.Get(k)).(type) {
+		case *types.Document:
+			excludeFields(v, fields, path)
+		case *types.Array:
+			excludeArrayFields(v, fields, path)
+		}
+	}
+}
+
+// excludeArrayFields removes fields excluded by the projection from documents
+// of the given array (found at prefix path) in place.
+func excludeArrayFields(arr *types.Array, fields *types.Document, prefix string) {
+	for i := 0; i < arr.Len(); i++ {
+		switch v := must.NotFail(arr.Get(i)).(type) {
+		case *types.Document:
+			excludeFields(v, fields, prefix)
+		case *types.Array:
+			excludeArrayFields(v, fields, prefix)
+		}
+	}
+}
+
+// hasProjectionPrefix returns true if some projection field is nested in the given path.
+func hasProjectionPrefix(fields *types.Document, path string) bool {
+	for _, k := range fields.Keys() {
+		if strings.HasPrefix(k, path+".") {
+			return true
+		}
+	}
+
+	return false
+}
+
+// deepCopyValue returns a deep copy of the given value if it is a composite type.
+func deepCopyValue(v any) any {
+	switch v := v.(type) {
+	case *types.Document:
+		return v.DeepCopy()
+	case *types.Array:
+		return v.DeepCopy()
+	default:
+		return v
+	}
+}
+
+// validateMetaProjection checks that the given {$meta: <keyword>} projection is supported.
+func validateMetaProjection(meta *types.Document) error {
+	if meta.Len() != 1 {
+		return NewErrorMsg(ErrBadValue, "$meta projection must be the only field in the projection expression")
+	}
+
+	switch keyword := must.NotFail(meta.Get("$meta")).(type) {
+	case string:
+		switch keyword {
+		case "indexKey":
+			return nil
+		case "textScore", "searchScore", "searchHighlights", "randVal",
+			"recordId", "sortKey", "geoNearDistance", "geoNearPoint":
+			return NewErrorMsg(ErrNotImplemented, fmt.Sprintf("$meta %q is not implemented yet", keyword))
 		default:
-			return lazyerrors.Errorf("unsupported operation %s %v (%T)", k1, projectionVal, projectionVal)
+			return NewErrorMsg(ErrBadValue, fmt.Sprintf("Unsupported argument to $meta: %s", keyword))
 		}
+	default:
+		return NewErrorMsg(ErrBadValue, fmt.Sprintf("Unsupported argument to $meta: %s", AliasFromType(keyword)))
 	}
-	return nil
+}
+
+// isMetaProjection returns true if the given projection value is {$meta: <keyword>}.
+func isMetaProjection(projectionVal any) bool {
+	d, ok := projectionVal.(*types.Document)
+	return ok && d.Has("$meta")
 }
 
 func applyComplexProjection(k1 string, doc, projectionVal *types.Document) (err error) {
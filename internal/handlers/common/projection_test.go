@@ -0,0 +1,179 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package common
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+)
+
+func TestProjectDocument(t *testing.T) {
+	t.Parallel()
+
+	// d and a are shortcuts for creating documents and arrays
+	d := func(pairs ...any) *types.Document { return must.NotFail(types.NewDocument(pairs...)) }
+	a := func(values ...any) *types.Array { return must.NotFail(types.NewArray(values...)) }
+
+	doc := d(
+		"_id", int32(1),
+		"v", d("foo", int32(1), "bar", d("baz", "qux", "quux", int32(2))),
+		"arr", a(d("foo", int32(1), "bar", int32(2)), int32(3), a(d("foo", int32(4)))),
+		"w", "bar",
+	)
+
+	for name, tc := range map[string]struct {
+		projection *types.Document
+		expected   *types.Document
+		err        error
+	}{
+		"Empty": {
+			projection: d(),
+			expected:   doc,
+		},
+		"Include": {
+			projection: d("w", int32(1)),
+			expected:   d("_id", int32(1), "w", "bar"),
+		},
+		"IncludeWithoutID": {
+			projection: d("_id", false, "w", true),
+			expected:   d("w", "bar"),
+		},
+		"IncludeOnlyID": {
+			projection: d("_id", int64(1)),
+			expected:   d("_id", int32(1)),
+		},
+		"IncludeDotted": {
+			projection: d("v.bar.baz", int32(1), "w", int32(1)),
+			expected:   d("_id", int32(1), "v", d("bar", d("baz", "qux")), "w", "bar"),
+		},
+		"IncludeNested": {
+			projection: d("v", d("foo", true, "bar", d("quux", 1.0))),
+			expected:   d("_id", int32(1), "v", d("foo", int32(1), "bar", d("quux", int32(2)))),
+		},
+		"IncludeArray": {
+			projection: d("_id", int32(0), "arr.foo", int32(1)),
+			expected:   d("arr", a(d("foo", int32(1)), a(d("foo", int32(4))))),
+		},
+		"IncludeMissing": {
+			projection: d("missing.foo", int32(1)),
+			expected:   d("_id", int32(1)),
+		},
+		"IncludeScalarPrefix": {
+			projection: d("w.foo", int32(1)),
+			expected:   d("_id", int32(1)),
+		},
+		"Exclude": {
+			projection: d("v", int32(0), "arr", false),
+			expected:   d("_id", int32(1), "w", "bar"),
+		},
+		"ExcludeDotted": {
+			projection: d("_id", false, "v.bar.baz", int32(0), "arr.bar", int32(0)),
+			expected: d(
+				"v", d("foo", int32(1), "bar", d("quux", int32(2))),
+				"arr", a(d("foo", int32(1)), int32(3), a(d("foo", int32(4)))),
+				"w", "bar",
+			),
+		},
+		"ExcludeNested": {
+			projection: d("v", d("foo", int32(0), "bar", d("quux", false))),
+			expected: d(
+				"_id", int32(1),
+				"v", d("bar", d("baz", "qux")),
+				"arr", a(d("foo", int32(1), "bar", int32(2)), int32(3), a(d("foo", int32(4)))),
+				"w", "bar",
+			),
+		},
+		"ExcludeOnlyID": {
+			projection: d("_id", int32(0)),
+			expected: d(
+				"v", d("foo", int32(1), "bar", d("baz", "qux", "quux", int32(2))),
+				"arr", a(d("foo", int32(1), "bar", int32(2)), int32(3), a(d("foo", int32(4)))),
+				"w", "bar",
+			),
+		},
+		"Slice": {
+			projection: d("_id", false, "arr", d("$slice", int32(1))),
+			expected: d(
+				"v", d("foo", int32(1), "bar", d("baz", "qux", "quux", int32(2))),
+				"arr", a(d("foo", int32(1), "bar", int32(2))),
+				"w", "bar",
+			),
+		},
+		"SliceInclusion": {
+			projection: d("arr", d("$slice", int32(-1)), "w", int32(1)),
+			expected:   d("_id", int32(1), "arr", a(a(d("foo", int32(4)))), "w", "bar"),
+		},
+		"SliceNotArray": {
+			projection: d("w", d("$slice", a(int32(1), int32(2))), "v", int32(1)),
+			expected:   d("_id", int32(1), "v", d("foo", int32(1), "bar", d("baz", "qux", "quux", int32(2))), "w", "bar"),
+		},
+		"InclusionInExclusion": {
+			projection: d("v.foo", int32(0), "w", int32(1)),
+			err:        NewErrorMsg(ErrProjectionInEx, "Cannot do inclusion on field w in exclusion projection"),
+		},
+		"ExclusionInInclusion": {
+			projection: d("v", d("foo", int32(1), "bar", false)),
+			err:        NewErrorMsg(ErrProjectionExIn, "Cannot do exclusion on field v.bar in inclusion projection"),
+		},
+		"PathCollision": {
+			projection: d("v", int32(1), "v.foo", int32(1)),
+			err:        NewErrorMsg(ErrProjectionPathCollision, "Path collision at v.foo remaining portion foo"),
+		},
+		"EmptySubProjection": {
+			projection: d("v", d("foo", d())),
+			err: NewErrorMsg(
+				ErrProjectionEmptySubProjection,
+				"An empty sub-projection is not a valid value. Found empty object at path v.foo",
+			),
+		},
+	} {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			actual, err := ProjectDocument(doc, tc.projection)
+			if tc.err != nil {
+				assert.Equal(t, tc.err, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, actual)
+
+			// the original document is not modified
+			assert.Equal(t, "qux", must.NotFail(doc.GetByPath(types.NewPathFromString("v.bar.baz"))))
+		})
+	}
+}
+
+func TestValidateProjection(t *testing.T) {
+	t.Parallel()
+
+	d := func(pairs ...any) *types.Document { return must.NotFail(types.NewDocument(pairs...)) }
+
+	assert.NoError(t, ValidateProjection(nil))
+	assert.NoError(t, ValidateProjection(d()))
+	assert.NoError(t, ValidateProjection(d("v", int32(1), "w.foo", true)))
+
+	expected := NewErrorMsg(ErrProjectionPathCollision, "Path collision at v.foo remaining portion foo")
+	assert.Equal(t, expected, ValidateProjection(d("v", int32(1), "v.foo", int32(1))))
+
+	// invalid projection is rejected even if there are no documents
+	assert.Equal(t, expected, ProjectDocuments(nil, d("v", int32(1), "v.foo", int32(1))))
+}
@@ -0,0 +1,78 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package common
+
+import (
+	"fmt"
+
+	"github.com/FerretDB/FerretDB/internal/types"
+)
+
+// ReadConcernLevel represents the level of the command's readConcern.
+type ReadConcernLevel string
+
+// Read concern levels.
+//
+// All levels except snapshot have the same meaning for a single server with a transactional backend.
+const (
+	ReadConcernLocal        = ReadConcernLevel("local")
+	ReadConcernAvailable    = ReadConcernLevel("available")
+	ReadConcernMajority     = ReadConcernLevel("majority")
+	ReadConcernLinearizable = ReadConcernLevel("linearizable")
+	ReadConcernSnapshot     = ReadConcernLevel("snapshot")
+)
+
+// GetReadConcernLevel returns the level of the command's readConcern, or ReadConcernLocal if it is not set.
+func GetReadConcernLevel(document *types.Document) (ReadConcernLevel, error) {
+	v, err := document.Get("readConcern")
+	if err != nil {
+		return ReadConcernLocal, nil
+	}
+
+	readConcern, ok := v.(*types.Document)
+	if !ok {
+		return "", NewErrorMsg(
+			ErrTypeMismatch,
+			fmt.Sprintf(
+				"BSON field '%s.readConcern' is the wrong type '%s', expected type 'object'",
+				document.Command(), AliasFromType(v),
+			),
+		)
+	}
+
+	if err = Unimplemented(readConcern, "afterClusterTime", "atClusterTime"); err != nil {
+		return "", err
+	}
+
+	v, err = readConcern.Get("level")
+	if err != nil {
+		return ReadConcernLocal, nil
+	}
+
+	level, ok := v.(string)
+	if !ok {
+		return "", NewErrorMsg(
+			ErrTypeMismatch,
+			fmt.Sprintf("BSON field 'ReadConcernArgs.level' is the wrong type '%s', expected type 'string'", AliasFromType(v)),
+		)
+	}
+
+	switch l := ReadConcernLevel(level); l {
+	case ReadConcernLocal, ReadConcernAvailable, ReadConcernMajority, ReadConcernLinearizable, ReadConcernSnapshot:
+		return l, nil
+	default:
+		return "", NewErrorMsg(ErrBadValue, fmt.Sprintf("Invalid read concern level: %s", level))
+	}
+}
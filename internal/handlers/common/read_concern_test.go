@@ -0,0 +1,87 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package common
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+)
+
+func TestGetReadConcernLevel(t *testing.T) {
+	t.Parallel()
+
+	for name, tc := range map[string]struct {
+		readConcern any // nil means no readConcern field
+		expected    ReadConcernLevel
+		err         error
+	}{
+		"Absent": {
+			expected: ReadConcernLocal,
+		},
+		"NoLevel": {
+			readConcern: must.NotFail(types.NewDocument()),
+			expected:    ReadConcernLocal,
+		},
+		"Majority": {
+			readConcern: must.NotFail(types.NewDocument("level", "majority")),
+			expected:    ReadConcernMajority,
+		},
+		"Snapshot": {
+			readConcern: must.NotFail(types.NewDocument("level", "snapshot")),
+			expected:    ReadConcernSnapshot,
+		},
+		"InvalidLevel": {
+			readConcern: must.NotFail(types.NewDocument("level", "foo")),
+			err:         NewErrorMsg(ErrBadValue, "Invalid read concern level: foo"),
+		},
+		"LevelType": {
+			readConcern: must.NotFail(types.NewDocument("level", int32(1))),
+			err: NewErrorMsg(
+				ErrTypeMismatch,
+				"BSON field 'ReadConcernArgs.level' is the wrong type 'int', expected type 'string'",
+			),
+		},
+		"Type": {
+			readConcern: "local",
+			err: NewErrorMsg(
+				ErrTypeMismatch,
+				"BSON field 'find.readConcern' is the wrong type 'string', expected type 'object'",
+			),
+		},
+	} {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			doc := must.NotFail(types.NewDocument("find", "coll"))
+			if tc.readConcern != nil {
+				must.NoError(doc.Set("readConcern", tc.readConcern))
+			}
+
+			level, err := GetReadConcernLevel(doc)
+			if tc.err != nil {
+				assert.Equal(t, tc.err, err)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expected, level)
+		})
+	}
+}
@@ -24,23 +24,23 @@ import (
 )
 
 // SortDocuments sorts given documents in place according to the given sorting conditions.
+//
+// Sort keys may be dotted paths; missing fields are sorted as nulls, before values of all other types.
+// The sort is stable: documents with equal sort keys keep their relative order.
+// Documents that are already sorted (for example, by the backend) are left as is.
 func SortDocuments(docs []*types.Document, sort *types.Document) error {
-	if sort.Len() == 0 {
-		return nil
+	if err := ValidateSortDocument(sort); err != nil {
+		return err
 	}
 
-	if sort.Len() > 32 {
-		return lazyerrors.Errorf("maximum sort keys exceeded: %v", sort.Len())
+	if sort.Len() == 0 {
+		return nil
 	}
 
 	sortFuncs := make([]sortFunc, len(sort.Keys()))
 	for i, sortKey := range sort.Keys() {
 		sortField := must.NotFail(sort.Get(sortKey))
-		sortType, err := getSortType(sortKey, sortField)
-		if err != nil {
-			return err
-		}
-
+		sortType := must.NotFail(getSortType(sortKey, sortField))
 		sortFuncs[i] = lessFunc(sortKey, sortType)
 	}
 
@@ -50,18 +50,37 @@ func SortDocuments(docs []*types.Document, sort *types.Document) error {
 	return nil
 }
 
+// ValidateSortDocument returns an error if the given sort document is invalid.
+//
+// It is used when documents are sorted by the backend, and SortDocuments is not called.
+func ValidateSortDocument(sort *types.Document) error {
+	if sort.Len() > 32 {
+		return lazyerrors.Errorf("maximum sort keys exceeded: %v", sort.Len())
+	}
+
+	for _, sortKey := range sort.Keys() {
+		if _, err := getSortType(sortKey, must.NotFail(sort.Get(sortKey))); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
 // lessFunc takes sort key and type and returns sort.Interface's Less function which
 // compares selected key of 2 documents.
 func lessFunc(sortKey string, sortType types.SortType) func(a, b *types.Document) bool {
+	path := types.NewPathFromString(sortKey)
+
 	return func(a, b *types.Document) bool {
-		aField, err := a.Get(sortKey)
+		aField, err := a.GetByPath(path)
 		if err != nil {
-			return false
+			aField = types.Null
 		}
 
-		bField, err := b.Get(sortKey)
+		bField, err := b.GetByPath(path)
 		if err != nil {
-			return false
+			bField = types.Null
 		}
 
 		result := types.CompareOrder(aField, bField, sortType)
@@ -98,7 +117,13 @@ type docsSorter struct {
 
 func (ds *docsSorter) Sort(docs []*types.Document) {
 	ds.docs = docs
-	sort.Sort(ds)
+
+	// checking is cheaper than sorting
+	if sort.IsSorted(ds) {
+		return
+	}
+
+	sort.Stable(ds)
 }
 
 func (ds *docsSorter) Len() int {
@@ -156,3 +181,18 @@ func getSortType(key string, value any) (types.SortType, error) {
 		return 0, NewErrorMsg(ErrSortBadOrder, "$sort key ordering must be 1 (for ascending) or -1 (for descending)")
 	}
 }
+
+// SortArray returns a new array with the values of the given array sorted in ascending BSON order,
+// the same way as MongoDB compares values of different types.
+func SortArray(arr *types.Array) *types.Array {
+	values := make([]any, arr.Len())
+	for i := range values {
+		values[i] = must.NotFail(arr.Get(i))
+	}
+
+	sort.SliceStable(values, func(i, j int) bool {
+		return types.CompareOrder(values[i], values[j], types.Ascending) == types.Less
+	})
+
+	return must.NotFail(types.NewArray(values...))
+}
@@ -0,0 +1,54 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package common
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+)
+
+func TestSortArray(t *testing.T) {
+	t.Parallel()
+
+	date := time.Date(2022, time.October, 1, 0, 0, 0, 0, time.UTC)
+
+	arr := must.NotFail(types.NewArray(
+		"foo", int32(42), must.NotFail(types.NewDocument("v", int32(1))), date, types.Null, 3.5, "bar", true, int64(-1),
+	))
+
+	expected := must.NotFail(types.NewArray(
+		types.Null, int64(-1), 3.5, int32(42), "bar", "foo", must.NotFail(types.NewDocument("v", int32(1))), true, date,
+	))
+
+	actual := SortArray(arr)
+	assert.Equal(t, expected, actual)
+
+	// the original array is not modified
+	assert.Equal(t, "foo", must.NotFail(arr.Get(0)))
+}
+
+func TestValidateSortDocument(t *testing.T) {
+	t.Parallel()
+
+	assert.NoError(t, ValidateSortDocument(nil))
+	assert.NoError(t, ValidateSortDocument(must.NotFail(types.NewDocument("a", int32(1), "b.c", float64(-1)))))
+	assert.Error(t, ValidateSortDocument(must.NotFail(types.NewDocument("a", int32(2)))))
+	assert.Error(t, ValidateSortDocument(must.NotFail(types.NewDocument("a", "asc"))))
+}
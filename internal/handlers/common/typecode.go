@@ -17,10 +17,12 @@ package common
 import (
 	"fmt"
 	"math"
+	"strings"
 	"time"
 
+	"golang.org/x/exp/slices"
+
 	"github.com/FerretDB/FerretDB/internal/types"
-	"github.com/FerretDB/FerretDB/internal/util/must"
 )
 
 //go:generate ../../../bin/stringer -linecomment -type typeCode
@@ -45,66 +47,90 @@ const (
 	typeCodeInt       = typeCode(16) // int
 	typeCodeTimestamp = typeCode(17) // timestamp
 	typeCodeLong      = typeCode(18) // long
-	// Not implemented.
-	typeCodeDecimal = typeCode(19)  // decimal
-	typeCodeMinKey  = typeCode(-1)  // minKey
-	typeCodeMaxKey  = typeCode(127) // maxKey
+	// Not supported by FerretDB; no values of those types could be stored, so they match nothing.
+	typeCodeDBPointer           = typeCode(12)  // dbPointer
+	typeCodeJavaScript          = typeCode(13)  // javascript
+	typeCodeSymbol              = typeCode(14)  // symbol
+	typeCodeJavaScriptWithScope = typeCode(15)  // javascriptWithScope
+	typeCodeDecimal             = typeCode(19)  // decimal
+	typeCodeMinKey              = typeCode(-1)  // minKey
+	typeCodeMaxKey              = typeCode(127) // maxKey
 	// Not actual type code. `number` matches double, int and long.
 	typeCodeNumber = typeCode(-128) // number
 )
 
+// allTypeCodes contains all valid typeCode values.
+var allTypeCodes = []typeCode{
+	typeCodeDouble, typeCodeString, typeCodeObject, typeCodeArray,
+	typeCodeBinData, typeCodeObjectID, typeCodeBool, typeCodeDate, typeCodeNull,
+	typeCodeRegex, typeCodeInt, typeCodeTimestamp, typeCodeLong,
+	typeCodeDBPointer, typeCodeJavaScript, typeCodeSymbol, typeCodeJavaScriptWithScope,
+	typeCodeDecimal, typeCodeMinKey, typeCodeMaxKey,
+	typeCodeNumber,
+}
+
+// undefinedTypeCode is the type code of the deprecated BSON undefined type.
+// It is not a part of typeCode values as FerretDB does not support that type.
+const undefinedTypeCode = int32(6)
+
+// errUndefinedTypeCode is returned when $type operator is used with the deprecated undefined type.
+var errUndefinedTypeCode = NewErrorMsg(
+	ErrBadValue,
+	`Type 'undefined' is deprecated and not supported; `+
+		`use {$type: "null"} to match explicit null values or {$exists: false} to match missing fields`,
+)
+
 // newTypeCode returns typeCde and error by given code.
 func newTypeCode(code int32) (typeCode, error) {
+	if code == undefinedTypeCode {
+		return 0, errUndefinedTypeCode
+	}
+
 	c := typeCode(code)
-	switch c {
-	case typeCodeDouble, typeCodeString, typeCodeObject, typeCodeArray,
-		typeCodeBinData, typeCodeObjectID, typeCodeBool, typeCodeDate,
-		typeCodeNull, typeCodeRegex, typeCodeInt, typeCodeTimestamp, typeCodeLong, typeCodeNumber:
-		return c, nil
-	case typeCodeDecimal, typeCodeMinKey, typeCodeMaxKey:
-		return 0, NewErrorMsg(ErrNotImplemented, fmt.Sprintf(`Type code %v not implemented`, code))
-	default:
+	if !slices.Contains(allTypeCodes, c) {
 		return 0, NewErrorMsg(ErrBadValue, fmt.Sprintf(`Invalid numerical type code: %d`, code))
 	}
-}
 
-// hasSameTypeElements returns true if types.Array elements has the same type.
-// MongoDB consider int32, int64 and float64 that could be converted to int as the same type.
-func hasSameTypeElements(array *types.Array) bool {
-	var prev string
-	for i := 0; i < array.Len(); i++ {
-		var cur string
+	return c, nil
+}
 
-		element := must.NotFail(array.Get(i))
+// getTypeCode returns typeCode for the given $type operator value: the string alias or the numerical code.
+func getTypeCode(v any) (typeCode, error) {
+	switch v := v.(type) {
+	case string:
+		return parseTypeCode(v)
 
-		if isWholeNumber(element) {
-			cur = typeCodeNumber.String()
-		} else {
-			cur = AliasFromType(element)
+	case float64:
+		if math.IsNaN(v) || math.IsInf(v, 0) {
+			return 0, NewErrorMsg(ErrBadValue, `Invalid numerical type code: `+
+				strings.Trim(strings.ToLower(fmt.Sprintf("%v", v)), "+"))
 		}
-
-		if prev == "" {
-			prev = cur
-			continue
+		if v != math.Trunc(v) || v > math.MaxInt32 || v < math.MinInt32 {
+			return 0, NewErrorMsg(ErrBadValue, fmt.Sprintf(`Invalid numerical type code: %v`, v))
 		}
 
-		if prev != cur {
-			return false
+		return newTypeCode(int32(v))
+
+	case int32:
+		return newTypeCode(v)
+
+	case int64:
+		if v > math.MaxInt32 || v < math.MinInt32 {
+			return 0, NewErrorMsg(ErrBadValue, fmt.Sprintf(`Invalid numerical type code: %d`, v))
 		}
-	}
 
-	return true
+		return newTypeCode(int32(v))
+
+	default:
+		return 0, NewErrorMsg(ErrTypeMismatch, `type must be represented as a number or a string`)
+	}
 }
 
 // aliasToTypeCode matches string type aliases to the corresponding typeCode value.
 var aliasToTypeCode = map[string]typeCode{}
 
 func init() {
-	for _, i := range []typeCode{
-		typeCodeDouble, typeCodeString, typeCodeObject, typeCodeArray,
-		typeCodeBinData, typeCodeObjectID, typeCodeBool, typeCodeDate, typeCodeNull,
-		typeCodeRegex, typeCodeInt, typeCodeTimestamp, typeCodeLong, typeCodeNumber,
-	} {
+	for _, i := range allTypeCodes {
 		aliasToTypeCode[i.String()] = i
 	}
 }
@@ -142,17 +168,3 @@ func AliasFromType(v any) string {
 		panic(fmt.Sprintf("not supported type %T", v))
 	}
 }
-
-// isWholeNumber checks is the given value represents a whole number type.
-func isWholeNumber(v any) bool {
-	switch v := v.(type) {
-	case float64:
-		return !(v != math.Trunc(v) || math.IsNaN(v) || math.IsInf(v, 0))
-	case int32:
-		return true
-	case int64:
-		return true
-	default:
-		return false
-	}
-}
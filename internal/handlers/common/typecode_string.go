@@ -21,6 +21,10 @@ func _() {
 	_ = x[typeCodeInt-16]
 	_ = x[typeCodeTimestamp-17]
 	_ = x[typeCodeLong-18]
+	_ = x[typeCodeDBPointer-12]
+	_ = x[typeCodeJavaScript-13]
+	_ = x[typeCodeSymbol-14]
+	_ = x[typeCodeJavaScriptWithScope-15]
 	_ = x[typeCodeDecimal-19]
 	_ = x[typeCodeMinKey - -1]
 	_ = x[typeCodeMaxKey-127]
@@ -31,15 +35,13 @@ const (
 	_typeCode_name_0 = "number"
 	_typeCode_name_1 = "minKey"
 	_typeCode_name_2 = "doublestringobjectarraybinData"
-	_typeCode_name_3 = "objectIdbooldatenullregex"
-	_typeCode_name_4 = "inttimestamplongdecimal"
-	_typeCode_name_5 = "maxKey"
+	_typeCode_name_3 = "objectIdbooldatenullregexdbPointerjavascriptsymboljavascriptWithScopeinttimestamplongdecimal"
+	_typeCode_name_4 = "maxKey"
 )
 
 var (
 	_typeCode_index_2 = [...]uint8{0, 6, 12, 18, 23, 30}
-	_typeCode_index_3 = [...]uint8{0, 8, 12, 16, 20, 25}
-	_typeCode_index_4 = [...]uint8{0, 3, 12, 16, 23}
+	_typeCode_index_3 = [...]uint8{0, 8, 12, 16, 20, 25, 34, 44, 50, 69, 72, 81, 85, 92}
 )
 
 func (i typeCode) String() string {
@@ -51,14 +53,11 @@ func (i typeCode) String() string {
 	case 1 <= i && i <= 5:
 		i -= 1
 		return _typeCode_name_2[_typeCode_index_2[i]:_typeCode_index_2[i+1]]
-	case 7 <= i && i <= 11:
+	case 7 <= i && i <= 19:
 		i -= 7
 		return _typeCode_name_3[_typeCode_index_3[i]:_typeCode_index_3[i+1]]
-	case 16 <= i && i <= 19:
-		i -= 16
-		return _typeCode_name_4[_typeCode_index_4[i]:_typeCode_index_4[i+1]]
 	case i == 127:
-		return _typeCode_name_5
+		return _typeCode_name_4
 	default:
 		return "typeCode(" + strconv.FormatInt(int64(i), 10) + ")"
 	}
@@ -18,45 +18,82 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
-
-	"github.com/FerretDB/FerretDB/internal/types"
-	"github.com/FerretDB/FerretDB/internal/util/must"
+	"github.com/stretchr/testify/require"
 )
 
-func TestHasSameTypeElements(t *testing.T) {
+func TestGetTypeCode(t *testing.T) {
 	t.Parallel()
 
 	for name, tc := range map[string]struct { //nolint:paralleltest // false positive
-		array *types.Array
-		same  bool
+		v        any
+		expected typeCode
+		err      error
 	}{
-		"ArrayInt": {
-			array: must.NotFail(types.NewArray(must.NotFail(types.NewArray(int32(1), int32(2))), int32(3))),
-			same:  false,
+		"Alias": {
+			v:        "string",
+			expected: typeCodeString,
+		},
+		"AliasNumber": {
+			v:        "number",
+			expected: typeCodeNumber,
+		},
+		"AliasNotSupported": {
+			v:        "javascript",
+			expected: typeCodeJavaScript,
+		},
+		"AliasUnknown": {
+			v:   "float",
+			err: NewErrorMsg(ErrBadValue, "Unknown type name alias: float"),
+		},
+		"AliasUndefined": {
+			v:   "undefined",
+			err: errUndefinedTypeCode,
 		},
-		"IntSame": {
-			array: must.NotFail(types.NewArray(int32(1), int32(2))),
-			same:  true,
+		"Int32": {
+			v:        int32(2),
+			expected: typeCodeString,
 		},
-		"IntDouble": {
-			array: must.NotFail(types.NewArray(int32(1), 42.3)),
-			same:  false,
+		"Int64": {
+			v:        int64(16),
+			expected: typeCodeInt,
 		},
-		"IntDoubleWhole": {
-			array: must.NotFail(types.NewArray(int32(1), 42.0)),
-			same:  true,
+		"Double": {
+			v:        18.0,
+			expected: typeCodeLong,
 		},
-		"IntLong": {
-			array: must.NotFail(types.NewArray(int32(1), int64(42))),
-			same:  true,
+		"MinKey": {
+			v:        int32(-1),
+			expected: typeCodeMinKey,
+		},
+		"DoubleFractional": {
+			v:   2.5,
+			err: NewErrorMsg(ErrBadValue, "Invalid numerical type code: 2.5"),
+		},
+		"CodeUnknown": {
+			v:   int32(42),
+			err: NewErrorMsg(ErrBadValue, "Invalid numerical type code: 42"),
+		},
+		"CodeUndefined": {
+			v:   int64(6),
+			err: errUndefinedTypeCode,
+		},
+		"Bool": {
+			v:   true,
+			err: NewErrorMsg(ErrTypeMismatch, "type must be represented as a number or a string"),
 		},
 	} {
 		tc, name := tc, name
 		t.Run(name, func(t *testing.T) {
 			t.Parallel()
 
-			result := hasSameTypeElements(tc.array)
-			assert.Equal(t, tc.same, result)
+			actual, err := getTypeCode(tc.v)
+			if tc.err != nil {
+				assert.Equal(t, tc.err, err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, actual)
 		})
 	}
 }
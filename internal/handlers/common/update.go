@@ -17,6 +17,7 @@ package common
 import (
 	"fmt"
 	"math"
+	"reflect"
 	"sort"
 	"strings"
 	"time"
@@ -29,13 +30,53 @@ import (
 
 // UpdateDocument updates the given document with a series of update operators.
 // Returns true if document was changed.
+//
+// It returns an error if the document's _id would be modified.
 func UpdateDocument(doc, update *types.Document) (bool, error) {
+	var id any
+	if doc.Has("_id") {
+		id = must.NotFail(doc.Get("_id"))
+	}
+
+	changed, err := updateDocument(doc, update)
+	if err != nil {
+		return false, err
+	}
+
+	if id == nil {
+		return changed, nil
+	}
+
+	if err = CheckImmutableID(id, doc); err != nil {
+		return false, err
+	}
+
+	return changed, nil
+}
+
+// CheckImmutableID returns an error if the document's _id is absent or differs from the given original _id.
+// Numbers of different types are considered different.
+func CheckImmutableID(id any, doc *types.Document) error {
+	newID, err := doc.Get("_id")
+	if err == nil && reflect.TypeOf(id) == reflect.TypeOf(newID) && equal(id, newID) {
+		return nil
+	}
+
+	return NewWriteErrorMsg(
+		ErrImmutableField,
+		"Performing an update on the path '_id' would modify the immutable field '_id'",
+	)
+}
+
+// updateDocument updates the given document with a series of update operators.
+// Returns true if document was changed.
+func updateDocument(doc, update *types.Document) (bool, error) {
 	var changed bool
 	var err error
 
 	if update.Len() == 0 {
 		// replace to empty doc
-		for _, key := range doc.Keys() {
+		for _, key := range slices.Clone(doc.Keys()) {
 			if key != "_id" {
 				changed = true
 
@@ -91,18 +132,29 @@ func UpdateDocument(doc, update *types.Document) (bool, error) {
 				return false, err
 			}
 
+		case "$pullAll":
+			changed, err = processPullAllFieldExpression(doc, updateV.(*types.Document))
+			if err != nil {
+				return false, err
+			}
+
+		case "$bit":
+			changed, err = processBitFieldExpression(doc, updateV.(*types.Document))
+			if err != nil {
+				return false, err
+			}
+
 		default:
 			if strings.HasPrefix(updateOp, "$") {
 				return false, NewError(ErrNotImplemented, fmt.Errorf("UpdateDocument: unhandled operation %q", updateOp))
 			}
 
 			// Treats the update as a Replacement object.
+			// The replaced document keeps _id first and has other fields in the order of the replacement.
 			setDoc := update
 
-			sort.Strings(setDoc.Keys())
-
-			for _, setKey := range doc.Keys() {
-				if !setDoc.Has(setKey) && setKey != "_id" {
+			for _, setKey := range slices.Clone(doc.Keys()) {
+				if setKey != "_id" {
 					doc.Remove(setKey)
 				}
 			}
@@ -123,12 +175,16 @@ func UpdateDocument(doc, update *types.Document) (bool, error) {
 
 // processSetFieldExpression changes document according to $set and $setOnInsert operators.
 // If the document was changed it returns true.
+//
+// Like in MongoDB, existing fields keep their positions, and new fields are appended
+// in the lexicographic order of their paths.
 func processSetFieldExpression(doc, setDoc *types.Document, setOnInsert bool) (bool, error) {
 	var changed bool
 
-	sort.Strings(setDoc.Keys())
+	setKeys := slices.Clone(setDoc.Keys())
+	sort.Strings(setKeys)
 
-	for _, setKey := range setDoc.Keys() {
+	for _, setKey := range setKeys {
 		setValue := must.NotFail(setDoc.Get(setKey))
 
 		path := types.NewPathFromString(setKey)
@@ -217,6 +273,168 @@ func processPopFieldExpression(doc *types.Document, update *types.Document) (boo
 	return changed, nil
 }
 
+// processPullAllFieldExpression changes document according to $pullAll operator,
+// removing all array elements equal to any of the given values.
+// If the document was changed it returns true.
+func processPullAllFieldExpression(doc *types.Document, update *types.Document) (bool, error) {
+	var changed bool
+
+	for _, key := range update.Keys() {
+		pullValue := must.NotFail(update.Get(key))
+
+		values, ok := pullValue.(*types.Array)
+		if !ok {
+			return false, NewWriteErrorMsg(
+				ErrBadValue,
+				fmt.Sprintf("$pullAll requires an array argument but was given a %s", AliasFromType(pullValue)),
+			)
+		}
+
+		path := types.NewPathFromString(key)
+
+		if !doc.HasByPath(path) {
+			continue
+		}
+
+		val, err := doc.GetByPath(path)
+		if err != nil {
+			return false, err
+		}
+
+		array, ok := val.(*types.Array)
+		if !ok {
+			return false, NewWriteErrorMsg(ErrBadValue, "Cannot apply $pullAll to a non-array value")
+		}
+
+		// iterate backwards to keep indexes of remaining elements valid
+		for i := array.Len() - 1; i >= 0; i-- {
+			elem := must.NotFail(array.Get(i))
+
+			for j := 0; j < values.Len(); j++ {
+				if equal(elem, must.NotFail(values.Get(j))) {
+					array.Remove(i)
+					changed = true
+
+					break
+				}
+			}
+		}
+
+		if err = doc.SetByPath(path, array); err != nil {
+			return false, err
+		}
+	}
+
+	return changed, nil
+}
+
+// processBitFieldExpression changes document according to $bit operator,
+// applying bitwise and, or, and xor operations to integer fields.
+// Missing fields are set to the result of the operation on zero.
+// If the document was changed it returns true.
+func processBitFieldExpression(doc *types.Document, update *types.Document) (bool, error) {
+	var changed bool
+
+	for _, key := range update.Keys() {
+		bitValue := must.NotFail(update.Get(key))
+
+		bitDoc, ok := bitValue.(*types.Document)
+		if !ok {
+			return false, NewWriteErrorMsg(
+				ErrBadValue,
+				fmt.Sprintf(
+					"The $bit modifier is not compatible with a %s. You must pass in an embedded document",
+					AliasFromType(bitValue),
+				),
+			)
+		}
+
+		path := types.NewPathFromString(key)
+
+		var val any = int32(0)
+		if doc.HasByPath(path) {
+			val = must.NotFail(doc.GetByPath(path))
+		}
+
+		switch val.(type) {
+		case int32, int64:
+		default:
+			return false, NewWriteErrorMsg(
+				ErrTypeMismatch,
+				fmt.Sprintf(
+					"Cannot apply $bit to a value of non-integral type. "+
+						`{_id: "%s"} has the field %s of non-integer type %s`,
+					must.NotFail(doc.Get("_id")), key, AliasFromType(val),
+				),
+			)
+		}
+
+		result := val
+
+		for _, op := range bitDoc.Keys() {
+			operand := must.NotFail(bitDoc.Get(op))
+
+			switch operand.(type) {
+			case int32, int64:
+			default:
+				return false, NewWriteErrorMsg(
+					ErrBadValue,
+					fmt.Sprintf(
+						"The $bit modifier field must be an Integer(32/64 bit); a '%s' is not supported here",
+						AliasFromType(operand),
+					),
+				)
+			}
+
+			var err error
+			if result, err = applyBitOp(op, result, operand); err != nil {
+				return false, err
+			}
+		}
+
+		if doc.HasByPath(path) && result == val {
+			continue
+		}
+
+		if err := doc.SetByPath(path, result); err != nil {
+			return false, NewWriteErrorMsg(ErrUnsuitableValueType, err.Error())
+		}
+
+		changed = true
+	}
+
+	return changed, nil
+}
+
+// applyBitOp applies bitwise operation of $bit operator to int32 or int64 values.
+// The result is int64 if any of the values is int64.
+func applyBitOp(op string, a, b any) (any, error) {
+	var f func(x, y int64) int64
+
+	switch op {
+	case "and":
+		f = func(x, y int64) int64 { return x & y }
+	case "or":
+		f = func(x, y int64) int64 { return x | y }
+	case "xor":
+		f = func(x, y int64) int64 { return x ^ y }
+	default:
+		return nil, NewWriteErrorMsg(
+			ErrBadValue,
+			fmt.Sprintf("The $bit modifier only supports 'and', 'or', and 'xor', not '%s' which is an unknown operator", op),
+		)
+	}
+
+	a32, aOk := a.(int32)
+	b32, bOk := b.(int32)
+
+	if aOk && bOk {
+		return int32(f(int64(a32), int64(b32))), nil
+	}
+
+	return f(must.NotFail(GetWholeNumberParam(a)), must.NotFail(GetWholeNumberParam(b))), nil
+}
+
 // processIncFieldExpression changes document according to $inc operator.
 // If the document was changed it returns true.
 func processIncFieldExpression(doc *types.Document, updateV any) (bool, error) {
@@ -355,6 +573,49 @@ func processCurrentDateFieldExpression(doc *types.Document, currentDateVal any)
 	return changed, nil
 }
 
+// NewUpsertDocument returns a new document to be inserted by upsert with update operators.
+// It contains top-level equality conditions of the given query, both `{field: value}` and `{field: {$eq: value}}`.
+// Query operators and conditions on other operators are skipped.
+//
+// TODO Handle dotted paths and equality conditions inside $and.
+func NewUpsertDocument(query *types.Document) *types.Document {
+	doc := must.NotFail(types.NewDocument())
+
+	if query == nil {
+		return doc
+	}
+
+	for _, key := range query.Keys() {
+		if strings.HasPrefix(key, "$") || strings.Contains(key, ".") {
+			continue
+		}
+
+		value := must.NotFail(query.Get(key))
+
+		if expr, ok := value.(*types.Document); ok {
+			keys := expr.Keys()
+			if len(keys) > 0 && strings.HasPrefix(keys[0], "$") {
+				if len(keys) != 1 || keys[0] != "$eq" {
+					continue
+				}
+
+				value = must.NotFail(expr.Get("$eq"))
+			}
+		}
+
+		switch value := value.(type) {
+		case *types.Document:
+			must.NoError(doc.Set(key, value.DeepCopy()))
+		case *types.Array:
+			must.NoError(doc.Set(key, value.DeepCopy()))
+		default:
+			must.NoError(doc.Set(key, value))
+		}
+	}
+
+	return doc
+}
+
 // ValidateUpdateOperators validates update statement.
 func ValidateUpdateOperators(update *types.Document) error {
 	var err error
@@ -387,6 +648,16 @@ func ValidateUpdateOperators(update *types.Document) error {
 		return err
 	}
 
+	_, err = extractValueFromUpdateOperator("$pullAll", update)
+	if err != nil {
+		return err
+	}
+
+	_, err = extractValueFromUpdateOperator("$bit", update)
+	if err != nil {
+		return err
+	}
+
 	if err = checkConflictingChanges(set, inc); err != nil {
 		return err
 	}
@@ -413,6 +684,10 @@ func HasSupportedUpdateModifiers(update *types.Document) (bool, error) {
 		case "$unset":
 			fallthrough
 		case "$pop":
+			fallthrough
+		case "$pullAll":
+			fallthrough
+		case "$bit":
 			updateModifier = true
 		default:
 			if strings.HasPrefix(updateOp, "$") {
@@ -0,0 +1,122 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package common
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+)
+
+func TestUpdateDocumentFieldOrder(t *testing.T) {
+	t.Parallel()
+
+	for name, tc := range map[string]struct { //nolint:paralleltest // false positive
+		update       *types.Document
+		expectedKeys []string
+	}{
+		"Set": {
+			update: must.NotFail(types.NewDocument("$set", must.NotFail(types.NewDocument(
+				"z", int32(1), "c", "new", "a", int32(2), "y", int32(3),
+			)))),
+			expectedKeys: []string{"_id", "a", "b", "c", "y", "z"},
+		},
+		"SetDotted": {
+			update: must.NotFail(types.NewDocument("$set", must.NotFail(types.NewDocument(
+				"d.y", int32(1), "b", "new", "d.x", int32(2),
+			)))),
+			expectedKeys: []string{"_id", "a", "b", "c", "d"},
+		},
+		"Replacement": {
+			update:       must.NotFail(types.NewDocument("z", int32(1), "c", "new", "a", int32(2))),
+			expectedKeys: []string{"_id", "z", "c", "a"},
+		},
+	} {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			doc := must.NotFail(types.NewDocument("_id", "order", "a", int32(1), "b", "foo", "c", "bar"))
+			update := tc.update.DeepCopy()
+
+			changed, err := UpdateDocument(doc, update)
+			require.NoError(t, err)
+			assert.True(t, changed)
+			assert.Equal(t, tc.expectedKeys, doc.Keys())
+
+			// the update itself is not modified
+			assert.Equal(t, tc.update, update)
+		})
+	}
+}
+
+func TestNewUpsertDocument(t *testing.T) {
+	t.Parallel()
+
+	for name, tc := range map[string]struct {
+		query    *types.Document
+		expected *types.Document
+	}{
+		"Nil": {
+			query:    nil,
+			expected: must.NotFail(types.NewDocument()),
+		},
+		"Equality": {
+			query: must.NotFail(types.NewDocument(
+				"_id", "upsert",
+				"v", int32(42),
+				"doc", must.NotFail(types.NewDocument("foo", "bar")),
+			)),
+			expected: must.NotFail(types.NewDocument(
+				"_id", "upsert",
+				"v", int32(42),
+				"doc", must.NotFail(types.NewDocument("foo", "bar")),
+			)),
+		},
+		"Eq": {
+			query: must.NotFail(types.NewDocument(
+				"v", must.NotFail(types.NewDocument("$eq", "foo")),
+			)),
+			expected: must.NotFail(types.NewDocument("v", "foo")),
+		},
+		"Operators": {
+			query: must.NotFail(types.NewDocument(
+				"_id", must.NotFail(types.NewDocument("$gt", int32(1))),
+				"v", must.NotFail(types.NewDocument("$eq", int32(1), "$lt", int32(2))),
+				"$comment", "test",
+				"w", "bar",
+			)),
+			expected: must.NotFail(types.NewDocument("w", "bar")),
+		},
+		"Dotted": {
+			query: must.NotFail(types.NewDocument(
+				"v.foo", int32(1),
+			)),
+			expected: must.NotFail(types.NewDocument()),
+		},
+	} {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			actual := NewUpsertDocument(tc.query)
+			assert.Equal(t, tc.expected, actual)
+		})
+	}
+}
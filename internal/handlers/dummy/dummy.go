@@ -29,7 +29,9 @@ func notImplemented(command string) error {
 
 // Handler implements handlers.Interface by stubbing all methods except the following handler-independent commands:
 //
+//   - abortTransaction;
 //   - buildInfo;
+//   - commitTransaction;
 //   - connectionStatus;
 //   - debugError;
 //   - getCmdLineOpts;
@@ -41,6 +41,9 @@ type Interface interface {
 
 	// OP_MSG commands, sorted alphabetically
 
+	// MsgAbortTransaction aborts the current multi-document transaction.
+	MsgAbortTransaction(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg, error)
+
 	// MsgAggregate returns aggregated data.
 	MsgAggregate(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg, error)
 
@@ -53,6 +56,9 @@ type Interface interface {
 	// MsgCollStats returns storage data for a collection.
 	MsgCollStats(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg, error)
 
+	// MsgCommitTransaction commits the current multi-document transaction.
+	MsgCommitTransaction(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg, error)
+
 	// MsgConnectionStatus returns information about the current connection,
 	// specifically the state of authenticated users and their available permissions.
 	MsgConnectionStatus(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg, error)
@@ -78,12 +84,18 @@ type Interface interface {
 	// MsgDelete deletes documents matched by the query.
 	MsgDelete(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg, error)
 
+	// MsgDistinct returns an array of distinct values for the given field.
+	MsgDistinct(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg, error)
+
 	// MsgDrop drops the collection.
 	MsgDrop(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg, error)
 
 	// MsgDropDatabase drops production database.
 	MsgDropDatabase(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg, error)
 
+	// MsgDropIndexes drops indexes on a collection.
+	MsgDropIndexes(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg, error)
+
 	// MsgExplain returns the execution plan.
 	MsgExplain(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg, error)
 
@@ -102,6 +114,9 @@ type Interface interface {
 	// MsgGetLog returns the most recent logged events from memory.
 	MsgGetLog(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg, error)
 
+	// MsgGetMore returns the next batch of documents from a cursor.
+	MsgGetMore(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg, error)
+
 	// MsgGetParameter returns the value of the parameter.
 	MsgGetParameter(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg, error)
 
@@ -117,6 +132,9 @@ type Interface interface {
 	// MsgIsMaster returns the role of the FerretDB instance.
 	MsgIsMaster(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg, error)
 
+	// MsgKillCursors closes server-side cursors.
+	MsgKillCursors(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg, error)
+
 	// MsgListCollections returns the information of the collections and views in the database.
 	MsgListCollections(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg, error)
 
@@ -126,9 +144,15 @@ type Interface interface {
 	// MsgListDatabases returns a summary of all the databases.
 	MsgListDatabases(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg, error)
 
+	// MsgListIndexes returns a summary of indexes of the specified collection.
+	MsgListIndexes(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg, error)
+
 	// MsgPing returns a pong response.
 	MsgPing(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg, error)
 
+	// MsgRenameCollection changes the name of an existing collection.
+	MsgRenameCollection(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg, error)
+
 	// MsgServerStatus returns an overview of the databases state.
 	MsgServerStatus(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg, error)
 
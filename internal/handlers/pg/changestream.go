@@ -0,0 +1,102 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pg
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v4"
+
+	"github.com/FerretDB/FerretDB/internal/handlers/common"
+	"github.com/FerretDB/FerretDB/internal/handlers/common/aggregations"
+	"github.com/FerretDB/FerretDB/internal/handlers/pg/pgdb"
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+	"github.com/FerretDB/FerretDB/internal/wire"
+)
+
+// changeStream handles aggregate command with $changeStream first stage.
+//
+// It opens a tailable cursor that returns insert events for documents inserted into the collection
+// after the stream was opened or after the event with the given resume token.
+// Inserts are detected by polling rows with greater ctids; see pgdb.QueryInsertedDocuments for limitations.
+// Other operations are not reported.
+func (h *Handler) changeStream(ctx context.Context, params *aggregations.Params) (*wire.OpMsg, error) {
+	// resume token's data is ctid of the last returned row
+	ctid := params.ChangeStream.ResumeToken
+
+	if ctid == "" {
+		err := h.pgPool.InTransaction(ctx, func(tx pgx.Tx) error {
+			var err error
+			ctid, err = pgdb.LastCTID(ctx, tx, params.DB, params.Collection)
+			return err
+		})
+		if err != nil {
+			return nil, lazyerrors.Error(err)
+		}
+	}
+
+	if !pgdb.IsValidCTID(ctid) {
+		return nil, common.NewErrorMsg(common.ErrBadValue, fmt.Sprintf("Invalid resume token: %q", ctid))
+	}
+
+	tail := func(ctx context.Context) ([]*types.Document, error) {
+		var inserted []pgdb.InsertedDocument
+		err := h.pgPool.InTransaction(ctx, func(tx pgx.Tx) error {
+			var err error
+			inserted, err = pgdb.QueryInsertedDocuments(ctx, tx, params.DB, params.Collection, ctid)
+			return err
+		})
+		if err != nil {
+			return nil, lazyerrors.Error(err)
+		}
+
+		if len(inserted) == 0 {
+			return nil, nil
+		}
+
+		ctid = inserted[len(inserted)-1].CTID
+
+		events := make([]*types.Document, len(inserted))
+		for i, d := range inserted {
+			events[i] = insertEvent(params.DB, params.Collection, d)
+		}
+
+		return aggregations.Process(ctx, params.Stages, events)
+	}
+
+	var reply wire.OpMsg
+	must.NoError(reply.SetSections(wire.OpMsgSection{
+		Documents: []*types.Document{must.NotFail(types.NewDocument(
+			"cursor", h.cursors.NewTailable(ctx, params.Namespace(), tail),
+			"ok", float64(1),
+		))},
+	}))
+
+	return &reply, nil
+}
+
+// insertEvent returns a change event for the inserted document.
+func insertEvent(db, collection string, d pgdb.InsertedDocument) *types.Document {
+	return must.NotFail(types.NewDocument(
+		"_id", must.NotFail(types.NewDocument("_data", d.CTID)),
+		"operationType", "insert",
+		"fullDocument", d.Doc,
+		"ns", must.NotFail(types.NewDocument("db", db, "coll", collection)),
+		"documentKey", must.NotFail(types.NewDocument("_id", must.NotFail(d.Doc.Get("_id")))),
+	))
+}
@@ -16,12 +16,232 @@ package pg
 
 import (
 	"context"
+	"time"
+
+	"github.com/jackc/pgx/v4"
 
 	"github.com/FerretDB/FerretDB/internal/handlers/common"
+	"github.com/FerretDB/FerretDB/internal/handlers/common/aggregations"
+	"github.com/FerretDB/FerretDB/internal/handlers/pg/pgdb"
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
+	"github.com/FerretDB/FerretDB/internal/util/must"
 	"github.com/FerretDB/FerretDB/internal/wire"
 )
 
 // MsgAggregate implements HandlerInterface.
 func (h *Handler) MsgAggregate(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg, error) {
-	return common.MsgAggregate(ctx, msg)
+	document, err := msg.Document()
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	unimplementedFields := []string{
+		"explain",
+		"collation",
+		"let",
+	}
+	if err = common.Unimplemented(document, unimplementedFields...); err != nil {
+		return nil, err
+	}
+
+	ignoredFields := []string{
+		"allowDiskUse",
+		"bypassDocumentValidation",
+		"comment",
+		"hint",
+		"writeConcern",
+	}
+	common.Ignored(document, h.l, ignoredFields...)
+
+	// the read concern level is used by inTransaction
+	if _, err = common.GetReadConcernLevel(document); err != nil {
+		return nil, err
+	}
+
+	params, err := aggregations.ParseParams(ctx, document)
+	if err != nil {
+		return nil, err
+	}
+
+	if params.ChangeStream != nil {
+		return h.changeStream(ctx, params)
+	}
+
+	maxTimeMS, err := common.GetOptionalPositiveNumber(document, "maxTimeMS")
+	if err != nil {
+		return nil, err
+	}
+
+	if maxTimeMS != 0 {
+		ctxWithTimeout, cancel := context.WithTimeout(ctx, time.Duration(maxTimeMS)*time.Millisecond)
+		defer cancel()
+
+		ctx = ctxWithTimeout
+	}
+
+	stages := params.Stages
+
+	var sp *pgdb.SQLParam
+	if params.Collection != "" {
+		pushdown := aggregations.NewPushdown(params.Stages, pgdb.IsFilterExact)
+		stages = pushdown.Stages
+
+		sp = &pgdb.SQLParam{
+			DB:         params.DB,
+			Collection: params.Collection,
+			Filter:     pushdown.Filter,
+			Skip:       pushdown.Skip,
+			Limit:      pushdown.Limit,
+		}
+	}
+
+	streamer := aggregations.NewStreamer(stages)
+
+	if sp != nil {
+		err = h.inTransaction(ctx, document, func(tx pgx.Tx) error {
+			fetchedChan, closeFetch, err := h.pgPool.QueryDocuments(ctx, tx, *sp)
+			defer closeFetch()
+
+			if err != nil {
+				return err
+			}
+
+			for fetchedItem := range fetchedChan {
+				if fetchedItem.Err != nil {
+					return fetchedItem.Err
+				}
+
+				done, err := streamer.Add(ctx, fetchedItem.Docs)
+				if err != nil {
+					return err
+				}
+
+				// closeFetch stops fetching of documents that are not needed
+				if done {
+					break
+				}
+			}
+
+			return nil
+		})
+
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	docs, err := streamer.Finish(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if params.Merge != nil {
+		if err = h.merge(ctx, document, params.Merge, docs); err != nil {
+			return nil, err
+		}
+
+		// $merge does not return documents
+		docs = []*types.Document{}
+	}
+
+	var reply wire.OpMsg
+	must.NoError(reply.SetSections(wire.OpMsgSection{
+		Documents: []*types.Document{must.NotFail(types.NewDocument(
+			"cursor", h.cursors.FirstBatch(ctx, params.Namespace(), docs, params.BatchSize, false),
+			"ok", float64(1),
+		))},
+	}))
+
+	return &reply, nil
+}
+
+// merge writes documents to the target collection of $merge stage.
+func (h *Handler) merge(ctx context.Context, document *types.Document, m *aggregations.Merge, docs []*types.Document) error {
+	return h.inTransaction(ctx, document, func(tx pgx.Tx) error {
+		for _, doc := range docs {
+			filter, err := m.Filter(doc)
+			if err != nil {
+				return err
+			}
+
+			sp := pgdb.SQLParam{
+				DB:         m.DB,
+				Collection: m.Collection,
+				Filter:     filter,
+			}
+
+			existing, err := h.fetchMatching(ctx, tx, sp)
+			if err != nil {
+				return err
+			}
+
+			switch {
+			case existing == nil:
+				res, err := m.NotMatched(doc)
+				if err != nil {
+					return err
+				}
+
+				if res == nil {
+					continue
+				}
+
+				if err = h.insert(ctx, tx, sp, res); err != nil {
+					return err
+				}
+
+			case m.WhenMatched == aggregations.MergeFail:
+				// insert fails with the duplicate key error, as in MongoDB
+				if err = h.insert(ctx, tx, sp, doc); err != nil {
+					return err
+				}
+
+			default:
+				res, err := m.Matched(ctx, existing, doc)
+				if err != nil {
+					return err
+				}
+
+				if res == nil {
+					continue
+				}
+
+				if _, err = h.update(ctx, tx, &sp, res); err != nil {
+					return err
+				}
+			}
+		}
+
+		return nil
+	})
+}
+
+// fetchMatching returns the first document matching the filter of the given query parameters, or nil.
+func (h *Handler) fetchMatching(ctx context.Context, tx pgx.Tx, sp pgdb.SQLParam) (*types.Document, error) {
+	fetchedChan, closeFetch, err := h.pgPool.QueryDocuments(ctx, tx, sp)
+	defer closeFetch()
+
+	if err != nil {
+		return nil, err
+	}
+
+	for fetchedItem := range fetchedChan {
+		if fetchedItem.Err != nil {
+			return nil, fetchedItem.Err
+		}
+
+		for _, doc := range fetchedItem.Docs {
+			matches, err := common.FilterDocument(doc, sp.Filter)
+			if err != nil {
+				return nil, err
+			}
+
+			if matches {
+				return doc, nil
+			}
+		}
+	}
+
+	return nil, nil
 }
@@ -16,8 +16,13 @@ package pg
 
 import (
 	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v4"
 
 	"github.com/FerretDB/FerretDB/internal/handlers/common"
+	"github.com/FerretDB/FerretDB/internal/handlers/pg/pgdb"
 	"github.com/FerretDB/FerretDB/internal/types"
 	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
 	"github.com/FerretDB/FerretDB/internal/util/must"
@@ -41,23 +46,59 @@ func (h *Handler) MsgCollStats(ctx context.Context, msg *wire.OpMsg) (*wire.OpMs
 		return nil, err
 	}
 
-	stats, err := h.pgPool.SchemaStats(ctx, db, collection)
+	scale, err := common.GetScaleParam(document)
 	if err != nil {
+		return nil, err
+	}
+
+	var stats *pgdb.CollStats
+	var indexes []pgdb.Index
+	err = h.pgPool.InTransaction(ctx, func(tx pgx.Tx) error {
+		var err error
+		if stats, err = pgdb.CollectionStats(ctx, tx, db, collection); err != nil {
+			return err
+		}
+
+		indexes, err = pgdb.Indexes(ctx, tx, db, collection)
+		return err
+	})
+
+	switch {
+	case err == nil:
+		// nothing
+	case errors.Is(err, pgdb.ErrTableNotExist):
+		return nil, common.NewErrorMsg(
+			common.ErrNamespaceNotFound,
+			fmt.Sprintf("Collection [%s.%s] not found.", db, collection),
+		)
+	default:
 		return nil, lazyerrors.Error(err)
 	}
 
+	res := must.NotFail(types.NewDocument(
+		"ns", db+"."+collection,
+		"count", int32(stats.CountObjects),
+		"size", stats.SizeData/scale,
+	))
+
+	// as in MongoDB, the average object size is not scaled and is absent for empty collections
+	if stats.CountObjects > 0 {
+		must.NoError(res.Set("avgObjSize", stats.SizeData/stats.CountObjects))
+	}
+
+	// the unique _id index always exists, but it is not stored in the settings table
+	must.NoError(res.Set("storageSize", stats.SizeTotal/scale))
+	must.NoError(res.Set("nindexes", int32(len(indexes)+1)))
+	must.NoError(res.Set("totalIndexSize", stats.SizeIndexes/scale))
+
+	// the storage size already includes indexes
+	must.NoError(res.Set("totalSize", stats.SizeTotal/scale))
+	must.NoError(res.Set("scaleFactor", int32(scale)))
+	must.NoError(res.Set("ok", float64(1)))
+
 	var reply wire.OpMsg
 	err = reply.SetSections(wire.OpMsgSection{
-		Documents: []*types.Document{must.NotFail(types.NewDocument(
-			"ns", db+"."+collection,
-			"count", stats.CountRows,
-			"size", stats.SizeTotal,
-			"storageSize", stats.SizeRelation,
-			"totalIndexSize", stats.SizeIndexes,
-			"totalSize", stats.SizeTotal,
-			"scaleFactor", int32(1),
-			"ok", float64(1),
-		))},
+		Documents: []*types.Document{res},
 	})
 	if err != nil {
 		return nil, lazyerrors.Error(err)
@@ -36,7 +36,6 @@ func (h *Handler) MsgCount(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg, e
 	}
 
 	unimplementedFields := []string{
-		"skip",
 		"collation",
 	}
 	if err := common.Unimplemented(document, unimplementedFields...); err != nil {
@@ -44,21 +43,29 @@ func (h *Handler) MsgCount(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg, e
 	}
 	ignoredFields := []string{
 		"hint",
-		"readConcern",
 		"comment",
 	}
 	common.Ignored(document, h.l, ignoredFields...)
 
+	// the read concern level is used by inTransaction
+	if _, err = common.GetReadConcernLevel(document); err != nil {
+		return nil, err
+	}
+
 	var filter *types.Document
 	if filter, err = common.GetOptionalParam(document, "query", filter); err != nil {
 		return nil, err
 	}
 
-	var limit int64
-	if l, _ := document.Get("limit"); l != nil {
-		if limit, err = common.GetWholeNumberParam(l); err != nil {
-			return nil, err
-		}
+	skip, err := common.GetSkipParam(document)
+	if err != nil {
+		return nil, err
+	}
+
+	// negative limit is the same as positive for counting
+	limit, _, err := common.GetLimitParam(document)
+	if err != nil {
+		return nil, err
 	}
 
 	var sp pgdb.SQLParam
@@ -77,18 +84,16 @@ func (h *Handler) MsgCount(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg, e
 		)
 	}
 
+	sp.Filter = filter
+
 	resDocs := make([]*types.Document, 0, 16)
-	err = h.pgPool.InTransaction(ctx, func(tx pgx.Tx) error {
-		fetchedChan, err := h.pgPool.QueryDocuments(ctx, tx, sp)
+	err = h.inTransaction(ctx, document, func(tx pgx.Tx) error {
+		fetchedChan, closeFetch, err := h.pgPool.QueryDocuments(ctx, tx, sp)
+		defer closeFetch()
+
 		if err != nil {
 			return err
 		}
-		defer func() {
-			// Drain the channel to prevent leaking goroutines.
-			// TODO Offer a better design instead of channels: https://github.com/FerretDB/FerretDB/issues/898.
-			for range fetchedChan {
-			}
-		}()
 
 		for fetchedItem := range fetchedChan {
 			if fetchedItem.Err != nil {
@@ -116,6 +121,7 @@ func (h *Handler) MsgCount(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg, e
 		return nil, err
 	}
 
+	resDocs = common.SkipDocuments(resDocs, skip)
 	if resDocs, err = common.LimitDocuments(resDocs, limit); err != nil {
 		return nil, err
 	}
@@ -73,7 +73,7 @@ func (h *Handler) MsgCreate(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg,
 
 	err = h.pgPool.InTransaction(ctx, func(tx pgx.Tx) error {
 		if err := pgdb.CreateDatabaseIfNotExists(ctx, tx, db); err != nil {
-			if errors.Is(pgdb.ErrInvalidDatabaseName, err) {
+			if errors.Is(err, pgdb.ErrInvalidDatabaseName) {
 				msg := fmt.Sprintf("Invalid namespace: %s.%s", db, collection)
 				return common.NewErrorMsg(common.ErrInvalidNamespace, msg)
 			}
@@ -85,9 +85,9 @@ func (h *Handler) MsgCreate(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg,
 				msg := fmt.Sprintf("Collection already exists. NS: %s.%s", db, collection)
 				return common.NewErrorMsg(common.ErrNamespaceExists, msg)
 			}
-			if errors.Is(err, pgdb.ErrInvalidTableName) {
-				msg := fmt.Sprintf("Invalid collection name: '%s.%s'", db, collection)
-				return common.NewErrorMsg(common.ErrInvalidNamespace, msg)
+			var nameErr *pgdb.InvalidCollectionNameError
+			if errors.As(err, &nameErr) {
+				return invalidCollectionNameError(db, nameErr)
 			}
 			return lazyerrors.Error(err)
 		}
@@ -106,3 +106,16 @@ func (h *Handler) MsgCreate(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg,
 
 	return &reply, nil
 }
+
+// invalidCollectionNameError returns InvalidNamespace error explaining why the collection name is invalid,
+// including the use of the reserved prefix.
+func invalidCollectionNameError(db string, nameErr *pgdb.InvalidCollectionNameError) error {
+	if nameErr.Reason == pgdb.CollectionNameEmpty {
+		// the same message as MongoDB's
+		msg := fmt.Sprintf("Invalid namespace specified '%s.'", db)
+		return common.NewErrorMsg(common.ErrInvalidNamespace, msg)
+	}
+
+	msg := fmt.Sprintf("Invalid collection name: '%s.%s': %s", db, nameErr.Name, nameErr.Reason)
+	return common.NewErrorMsg(common.ErrInvalidNamespace, msg)
+}
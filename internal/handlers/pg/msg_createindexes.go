@@ -15,9 +15,18 @@
 package pg
 
 import (
+	"bytes"
 	"context"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
 
+	"github.com/jackc/pgx/v4"
+
+	"github.com/FerretDB/FerretDB/internal/fjson"
 	"github.com/FerretDB/FerretDB/internal/handlers/common"
+	"github.com/FerretDB/FerretDB/internal/handlers/pg/pgdb"
 	"github.com/FerretDB/FerretDB/internal/types"
 	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
 	"github.com/FerretDB/FerretDB/internal/util/must"
@@ -35,11 +44,88 @@ func (h *Handler) MsgCreateIndexes(ctx context.Context, msg *wire.OpMsg) (*wire.
 
 	common.Ignored(document, h.l, "writeConcern", "commitQuorum", "comment")
 
+	command := document.Command()
+
+	db, err := common.GetRequiredParam[string](document, "$db")
+	if err != nil {
+		return nil, err
+	}
+
+	collection, err := common.GetRequiredParam[string](document, command)
+	if err != nil {
+		return nil, err
+	}
+
+	indexes, err := common.GetRequiredParam[*types.Array](document, "indexes")
+	if err != nil {
+		return nil, err
+	}
+
+	specs, err := parseIndexSpecs(indexes)
+	if err != nil {
+		return nil, err
+	}
+
+	var created bool
+	var before, after int32
+
+	err = h.pgPool.InTransaction(ctx, func(tx pgx.Tx) error {
+		if created, err = pgdb.CreateCollectionIfNotExist(ctx, tx, db, collection); err != nil {
+			return lazyerrors.Error(err)
+		}
+
+		existing, err := pgdb.Indexes(ctx, tx, db, collection)
+		if err != nil {
+			return lazyerrors.Error(err)
+		}
+
+		// the unique _id index always exists, but it is not stored in the settings table
+		existing = append([]pgdb.Index{idIndex}, existing...)
+		before = int32(len(existing))
+
+		for i := range specs {
+			spec := &specs[i]
+
+			exists, err := checkExistingIndex(spec, existing)
+			if err != nil {
+				return err
+			}
+
+			if exists {
+				continue
+			}
+
+			if err = h.createIndex(ctx, tx, db, collection, spec); err != nil {
+				return err
+			}
+
+			existing = append(existing, spec.index())
+		}
+
+		after = int32(len(existing))
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	res := must.NotFail(types.NewDocument(
+		"numIndexesBefore", before,
+		"numIndexesAfter", after,
+	))
+
+	if before == after {
+		must.NoError(res.Set("note", "all indexes already exist"))
+	} else {
+		must.NoError(res.Set("createdCollectionAutomatically", created))
+	}
+
+	must.NoError(res.Set("ok", float64(1)))
+
 	var reply wire.OpMsg
 	err = reply.SetSections(wire.OpMsgSection{
-		Documents: []*types.Document{must.NotFail(types.NewDocument(
-			"ok", float64(1),
-		))},
+		Documents: []*types.Document{res},
 	})
 	if err != nil {
 		return nil, lazyerrors.Error(err)
@@ -47,3 +133,322 @@ func (h *Handler) MsgCreateIndexes(ctx context.Context, msg *wire.OpMsg) (*wire.
 
 	return &reply, nil
 }
+
+// indexSpec represents a single index specification of createIndexes command.
+type indexSpec struct {
+	name string
+	key  *types.Document
+
+	// numeric is a FerretDB extension: if true, the user promises that key fields contain only integers,
+	// and an expression index usable for numeric range filters is created.
+	numeric bool
+
+	// unique is true for unique indexes.
+	unique bool
+
+	// sparse is true for indexes that skip documents without key fields.
+	sparse bool
+
+	// expireAfterSeconds is set only for TTL indexes.
+	expireAfterSeconds *int32
+
+	// partialFilterExpression is set only for partial indexes.
+	partialFilterExpression *types.Document
+}
+
+// parseIndexSpecs parses index specifications of createIndexes command.
+func parseIndexSpecs(indexes *types.Array) ([]indexSpec, error) {
+	if indexes.Len() == 0 {
+		return nil, common.NewErrorMsg(common.ErrBadValue, "Must specify at least one index to create")
+	}
+
+	specs := make([]indexSpec, indexes.Len())
+
+	for i := 0; i < indexes.Len(); i++ {
+		doc, ok := must.NotFail(indexes.Get(i)).(*types.Document)
+		if !ok {
+			return nil, common.NewErrorMsg(
+				common.ErrTypeMismatch,
+				fmt.Sprintf("The field 'indexes.%d' must be an object", i),
+			)
+		}
+
+		var err error
+		if specs[i].key, err = common.GetRequiredParam[*types.Document](doc, "key"); err != nil {
+			return nil, err
+		}
+
+		if specs[i].key.Len() == 0 {
+			return nil, common.NewErrorMsg(common.ErrCannotCreateIndex, "Index keys cannot be an empty field")
+		}
+
+		if specs[i].name, err = common.GetRequiredParam[string](doc, "name"); err != nil {
+			return nil, err
+		}
+
+		if err = common.Unimplemented(doc, "collation", "hidden"); err != nil {
+			return nil, err
+		}
+
+		if err = validateIndexKey(&specs[i]); err != nil {
+			return nil, err
+		}
+
+		if specs[i].numeric, err = common.GetOptionalParam(doc, "numeric", false); err != nil {
+			return nil, err
+		}
+
+		if specs[i].unique, err = common.GetBoolOptionalParam(doc, "unique"); err != nil {
+			return nil, err
+		}
+
+		if specs[i].sparse, err = common.GetBoolOptionalParam(doc, "sparse"); err != nil {
+			return nil, err
+		}
+
+		if specs[i].expireAfterSeconds, err = parseExpireAfterSeconds(doc, &specs[i]); err != nil {
+			return nil, err
+		}
+
+		if specs[i].partialFilterExpression, err = parsePartialFilterExpression(doc, &specs[i]); err != nil {
+			return nil, err
+		}
+
+		if specs[i].sparse && specs[i].partialFilterExpression != nil {
+			return nil, common.NewErrorMsg(
+				common.ErrCannotCreateIndex,
+				`cannot mix "partialFilterExpression" and "sparse" options`,
+			)
+		}
+
+		if (specs[i].unique || specs[i].sparse) && (specs[i].numeric || specs[i].partialFilterExpression != nil) {
+			return nil, common.NewErrorMsg(
+				common.ErrNotImplemented,
+				fmt.Sprintf("Index %s: unique and sparse numeric or partial indexes are not supported yet", specs[i].name),
+			)
+		}
+
+		if !specs[i].numeric {
+			if specs[i].key.Len() > 1 && !specs[i].unique {
+				return nil, common.NewErrorMsg(
+					common.ErrNotImplemented,
+					fmt.Sprintf("Index %s: compound indexes are not supported yet", specs[i].name),
+				)
+			}
+
+			continue
+		}
+
+		for _, field := range specs[i].key.Keys() {
+			if strings.ContainsRune(field, '.') {
+				return nil, common.NewErrorMsg(
+					common.ErrNotImplemented,
+					fmt.Sprintf("Index %s: numeric indexes on dotted fields are not supported", specs[i].name),
+				)
+			}
+		}
+	}
+
+	return specs, nil
+}
+
+// validateIndexKey checks that all index key values are non-zero numbers
+// representing ascending or descending order.
+func validateIndexKey(spec *indexSpec) error {
+	for _, field := range spec.key.Keys() {
+		switch v := must.NotFail(spec.key.Get(field)).(type) {
+		case float64, int32, int64:
+			if n, err := common.GetWholeNumberParam(v); err == nil && n == 0 {
+				return common.NewErrorMsg(
+					common.ErrCannotCreateIndex,
+					fmt.Sprintf("Index %s: values in the index key pattern can't be 0", spec.name),
+				)
+			}
+
+		case string:
+			return common.NewErrorMsg(
+				common.ErrNotImplemented,
+				fmt.Sprintf("Index %s: index type %q is not supported", spec.name, v),
+			)
+
+		default:
+			return common.NewErrorMsg(
+				common.ErrCannotCreateIndex,
+				fmt.Sprintf(
+					"Index %s: values in the index key pattern cannot be of type %s, only numbers are allowed",
+					spec.name, common.AliasFromType(v),
+				),
+			)
+		}
+	}
+
+	return nil
+}
+
+// parseExpireAfterSeconds returns the expireAfterSeconds option of the index specification,
+// or nil if it is not set.
+func parseExpireAfterSeconds(doc *types.Document, spec *indexSpec) (*int32, error) {
+	v, err := doc.Get("expireAfterSeconds")
+	if err != nil {
+		return nil, nil
+	}
+
+	if spec.key.Len() > 1 {
+		return nil, common.NewErrorMsg(
+			common.ErrCannotCreateIndex,
+			fmt.Sprintf("Index %s: TTL indexes are single-field indexes, compound indexes do not support TTL", spec.name),
+		)
+	}
+
+	seconds, err := common.GetWholeNumberParam(v)
+	if err != nil || seconds < 0 || seconds > math.MaxInt32 {
+		return nil, common.NewErrorMsg(
+			common.ErrCannotCreateIndex,
+			fmt.Sprintf(
+				"Index %s: TTL index 'expireAfterSeconds' option must be a whole number between 0 and %d",
+				spec.name, math.MaxInt32,
+			),
+		)
+	}
+
+	res := int32(seconds)
+
+	return &res, nil
+}
+
+// parsePartialFilterExpression returns the partialFilterExpression option of the index specification,
+// or nil if it is not set.
+func parsePartialFilterExpression(doc *types.Document, spec *indexSpec) (*types.Document, error) {
+	v, err := doc.Get("partialFilterExpression")
+	if err != nil {
+		return nil, nil
+	}
+
+	expr, ok := v.(*types.Document)
+	if !ok {
+		return nil, common.NewErrorMsg(
+			common.ErrTypeMismatch,
+			fmt.Sprintf(
+				"Index %s: 'partialFilterExpression' option must be a document, got %s",
+				spec.name, common.AliasFromType(v),
+			),
+		)
+	}
+
+	// check that the expression is a valid filter
+	if _, err = common.FilterDocument(must.NotFail(types.NewDocument()), expr); err != nil {
+		return nil, err
+	}
+
+	return expr, nil
+}
+
+// idIndex represents the unique _id index that exists in every collection.
+var idIndex = pgdb.Index{
+	Name: "_id_",
+	Key:  must.NotFail(types.NewDocument("_id", int32(1))),
+}
+
+// index returns index metadata for the given specification.
+func (spec *indexSpec) index() pgdb.Index {
+	return pgdb.Index{
+		Name:                    spec.name,
+		Key:                     spec.key,
+		ExpireAfterSeconds:      spec.expireAfterSeconds,
+		Numeric:                 spec.numeric,
+		Unique:                  spec.unique,
+		Sparse:                  spec.sparse,
+		PartialFilterExpression: spec.partialFilterExpression,
+	}
+}
+
+// checkExistingIndex returns true if exactly the same index already exists.
+//
+// It returns an error if an existing index has the same name but different key or options,
+// or the same key and options but a different name.
+func checkExistingIndex(spec *indexSpec, existing []pgdb.Index) (bool, error) {
+	for _, index := range existing {
+		// stored keys are compared by their canonical representation, including field order
+		sameKey := bytes.Equal(must.NotFail(fjson.Marshal(index.Key)), must.NotFail(fjson.Marshal(spec.key)))
+
+		sameOptions := index.Numeric == spec.numeric && index.Unique == spec.unique && index.Sparse == spec.sparse &&
+			(index.ExpireAfterSeconds == nil) == (spec.expireAfterSeconds == nil)
+		if sameOptions && index.ExpireAfterSeconds != nil {
+			sameOptions = *index.ExpireAfterSeconds == *spec.expireAfterSeconds
+		}
+
+		if sameOptions && (index.PartialFilterExpression != nil || spec.partialFilterExpression != nil) {
+			sameOptions = index.PartialFilterExpression != nil && spec.partialFilterExpression != nil &&
+				bytes.Equal(
+					must.NotFail(fjson.Marshal(index.PartialFilterExpression)),
+					must.NotFail(fjson.Marshal(spec.partialFilterExpression)),
+				)
+		}
+
+		switch {
+		case index.Name == spec.name && sameKey && sameOptions:
+			return true, nil
+
+		case index.Name == spec.name && !sameKey:
+			return false, common.NewErrorMsg(
+				common.ErrIndexKeySpecsConflict,
+				fmt.Sprintf("An existing index has the same name as the requested index: %s", spec.name),
+			)
+
+		case index.Name == spec.name:
+			return false, common.NewErrorMsg(
+				common.ErrIndexOptionsConflict,
+				fmt.Sprintf("An existing index has the same name as the requested index: %s", spec.name),
+			)
+
+		case sameKey && sameOptions:
+			return false, common.NewErrorMsg(
+				common.ErrIndexOptionsConflict,
+				fmt.Sprintf("Index already exists with a different name: %s", index.Name),
+			)
+		}
+	}
+
+	return false, nil
+}
+
+// createIndex creates a new index for the given specification.
+func (h *Handler) createIndex(ctx context.Context, tx pgx.Tx, db, collection string, spec *indexSpec) error {
+	index := spec.index()
+
+	if !spec.numeric {
+		err := pgdb.CreateIndex(ctx, tx, db, collection, &index)
+
+		var dupErr *pgdb.DuplicateKeyError
+		if errors.As(err, &dupErr) {
+			return duplicateKeyError(db, collection, dupErr)
+		}
+
+		if err != nil {
+			return lazyerrors.Error(err)
+		}
+
+		return nil
+	}
+
+	// numeric indexes are used instead of regular ones
+	if err := pgdb.CreateIndexMetadata(ctx, tx, db, collection, &index); err != nil {
+		return lazyerrors.Error(err)
+	}
+
+	for _, field := range spec.key.Keys() {
+		err := pgdb.CreateNumericIndex(ctx, tx, db, collection, field)
+		if errors.Is(err, pgdb.ErrIndexInvalidValue) {
+			return common.NewErrorMsg(
+				common.ErrCannotCreateIndex,
+				fmt.Sprintf("Index %s can't be created: field %q contains non-integer values", spec.name, field),
+			)
+		}
+
+		if err != nil {
+			return lazyerrors.Error(err)
+		}
+	}
+
+	return nil
+}
@@ -16,8 +16,12 @@ package pg
 
 import (
 	"context"
+	"errors"
+
+	"github.com/jackc/pgx/v4"
 
 	"github.com/FerretDB/FerretDB/internal/handlers/common"
+	"github.com/FerretDB/FerretDB/internal/handlers/pg/pgdb"
 	"github.com/FerretDB/FerretDB/internal/types"
 	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
 	"github.com/FerretDB/FerretDB/internal/util/must"
@@ -36,36 +40,72 @@ func (h *Handler) MsgDBStats(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg,
 		return nil, err
 	}
 
-	m := document.Map()
-	scale, ok := m["scale"].(float64)
-	if !ok {
-		scale = 1
+	scale, err := common.GetScaleParam(document)
+	if err != nil {
+		return nil, err
 	}
 
-	stats, err := h.pgPool.SchemaStats(ctx, db, "")
+	// statistics of all collections are summed up; for non-existent database they are all zeros
+	var stats pgdb.CollStats
+	var collections []string
+	var indexes int32
+	err = h.pgPool.InTransaction(ctx, func(tx pgx.Tx) error {
+		var err error
+		if collections, err = pgdb.Collections(ctx, tx, db); err != nil {
+			if errors.Is(err, pgdb.ErrSchemaNotExist) {
+				return nil
+			}
+
+			return err
+		}
+
+		for _, collection := range collections {
+			var collStats *pgdb.CollStats
+			if collStats, err = pgdb.CollectionStats(ctx, tx, db, collection); err != nil {
+				return err
+			}
+
+			stats.CountObjects += collStats.CountObjects
+			stats.SizeData += collStats.SizeData
+			stats.SizeTotal += collStats.SizeTotal
+			stats.SizeIndexes += collStats.SizeIndexes
+
+			var collIndexes []pgdb.Index
+			if collIndexes, err = pgdb.Indexes(ctx, tx, db, collection); err != nil {
+				return err
+			}
+
+			// the unique _id index always exists, but it is not stored in the settings table
+			indexes += int32(len(collIndexes) + 1)
+		}
+
+		return nil
+	})
 	if err != nil {
 		return nil, lazyerrors.Error(err)
 	}
 
 	var avgObjSize float64
-	if stats.CountRows > 0 {
-		avgObjSize = float64(stats.SizeRelation) / float64(stats.CountRows)
+	if stats.CountObjects > 0 {
+		avgObjSize = float64(stats.SizeData) / float64(stats.CountObjects)
 	}
 
 	var reply wire.OpMsg
 	err = reply.SetSections(wire.OpMsgSection{
 		Documents: []*types.Document{must.NotFail(types.NewDocument(
 			"db", db,
-			"collections", stats.CountTables,
+			"collections", int32(len(collections)),
 			// TODO https://github.com/FerretDB/FerretDB/issues/176
 			"views", int32(0),
-			"objects", stats.CountRows,
+			"objects", int32(stats.CountObjects),
 			"avgObjSize", avgObjSize,
-			"dataSize", float64(stats.SizeRelation)/scale,
-			"indexes", stats.CountIndexes,
-			"indexSize", float64(stats.SizeIndexes)/scale,
-			"totalSize", float64(stats.SizeTotal)/scale,
-			"scaleFactor", scale,
+			"dataSize", float64(stats.SizeData/scale),
+			// as for collStats, the storage size already includes indexes
+			"storageSize", float64(stats.SizeTotal/scale),
+			"indexes", indexes,
+			"indexSize", float64(stats.SizeIndexes/scale),
+			"totalSize", float64(stats.SizeTotal/scale),
+			"scaleFactor", float64(scale),
 			"ok", float64(1),
 		))},
 	})
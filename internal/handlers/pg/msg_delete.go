@@ -101,21 +101,19 @@ func (h *Handler) MsgDelete(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg,
 			return err
 		}
 
+		sp.Filter = filter
+
 		resDocs := make([]*types.Document, 0, 16)
-		err = h.pgPool.InTransaction(ctx, func(tx pgx.Tx) error {
+		err = h.inTransaction(ctx, document, func(tx pgx.Tx) error {
 			// fetch current items from collection
-			fetchedChan, err := h.pgPool.QueryDocuments(ctx, tx, sp)
+			fetchedChan, closeFetch, err := h.pgPool.QueryDocuments(ctx, tx, sp)
+			defer closeFetch()
+
 			if err != nil {
 				return err
 			}
-			defer func() {
-				// Drain the channel to prevent leaking goroutines.
-				// TODO Offer a better design instead of channels: https://github.com/FerretDB/FerretDB/issues/898.
-				for range fetchedChan {
-				}
-			}()
 
-			// iterate through every row and delete matching ones
+			// iterate through every row and collect matching ones
 			for fetchedItem := range fetchedChan {
 				if fetchedItem.Err != nil {
 					return fetchedItem.Err
@@ -133,24 +131,24 @@ func (h *Handler) MsgDelete(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg,
 
 					resDocs = append(resDocs, doc)
 				}
+			}
 
-				if resDocs, err = common.LimitDocuments(resDocs, limit); err != nil {
-					return err
-				}
-
-				// if no field is matched in a row, go to the next one
-				if len(resDocs) == 0 {
-					continue
-				}
+			// delete matching documents after fetching is done, as the transaction's connection is busy until then
+			if resDocs, err = common.LimitDocuments(resDocs, limit); err != nil {
+				return err
+			}
 
-				rowsDeleted, err := h.delete(ctx, &sp, resDocs)
-				if err != nil {
-					return err
-				}
+			if len(resDocs) == 0 {
+				return nil
+			}
 
-				deleted += int32(rowsDeleted)
+			rowsDeleted, err := h.delete(ctx, tx, &sp, resDocs)
+			if err != nil {
+				return err
 			}
 
+			deleted += int32(rowsDeleted)
+
 			return nil
 		})
 
@@ -202,15 +200,15 @@ func (h *Handler) MsgDelete(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg,
 	return &reply, nil
 }
 
-// delete deletes documents by _id.
-func (h *Handler) delete(ctx context.Context, sp *pgdb.SQLParam, docs []*types.Document) (int64, error) {
+// delete deletes documents by _id in the given transaction.
+func (h *Handler) delete(ctx context.Context, tx pgx.Tx, sp *pgdb.SQLParam, docs []*types.Document) (int64, error) {
 	ids := make([]any, len(docs))
 	for i, doc := range docs {
 		id := must.NotFail(doc.Get("_id"))
 		ids[i] = id
 	}
 
-	rowsDeleted, err := h.pgPool.DeleteDocumentsByID(ctx, sp, ids)
+	rowsDeleted, err := pgdb.DeleteDocumentsByID(ctx, tx, sp, ids)
 	if err != nil {
 		// TODO check error code
 		return 0, common.NewError(common.ErrNamespaceNotFound, fmt.Errorf("delete: ns not found: %w", err))
@@ -16,10 +16,8 @@ package pg
 
 import (
 	"context"
-	"errors"
 
 	"github.com/FerretDB/FerretDB/internal/handlers/common"
-	"github.com/FerretDB/FerretDB/internal/handlers/pg/pgdb"
 	"github.com/FerretDB/FerretDB/internal/types"
 	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
 	"github.com/FerretDB/FerretDB/internal/util/must"
@@ -40,15 +38,14 @@ func (h *Handler) MsgDropDatabase(ctx context.Context, msg *wire.OpMsg) (*wire.O
 		return nil, err
 	}
 
+	existed, err := h.pgPool.DropDatabase(ctx, db)
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
 	res := must.NotFail(types.NewDocument())
-	err = h.pgPool.DropDatabase(ctx, db)
-	switch {
-	case err == nil:
+	if existed {
 		res.Set("dropped", db)
-	case errors.Is(err, pgdb.ErrSchemaNotExist):
-		// nothing
-	default:
-		return nil, lazyerrors.Error(err)
 	}
 
 	res.Set("ok", float64(1))
@@ -0,0 +1,213 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pg
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/jackc/pgx/v4"
+
+	"github.com/FerretDB/FerretDB/internal/fjson"
+	"github.com/FerretDB/FerretDB/internal/handlers/common"
+	"github.com/FerretDB/FerretDB/internal/handlers/pg/pgdb"
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+	"github.com/FerretDB/FerretDB/internal/wire"
+)
+
+// MsgDropIndexes implements HandlerInterface.
+func (h *Handler) MsgDropIndexes(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg, error) {
+	document, err := msg.Document()
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	common.Ignored(document, h.l, "writeConcern", "comment")
+
+	command := document.Command()
+
+	db, err := common.GetRequiredParam[string](document, "$db")
+	if err != nil {
+		return nil, err
+	}
+
+	collection, err := common.GetRequiredParam[string](document, command)
+	if err != nil {
+		return nil, err
+	}
+
+	index, err := document.Get("index")
+	if err != nil {
+		return nil, common.NewErrorMsg(common.ErrFailedToParse, "BSON field 'dropIndexes.index' is missing but a required field")
+	}
+
+	var nIndexesWas int32
+	var all bool
+
+	err = h.pgPool.InTransaction(ctx, func(tx pgx.Tx) error {
+		exists, err := pgdb.CollectionExists(ctx, tx, db, collection)
+		if err != nil {
+			return lazyerrors.Error(err)
+		}
+
+		if !exists {
+			return common.NewErrorMsg(common.ErrNamespaceNotFound, fmt.Sprintf("ns not found %s.%s", db, collection))
+		}
+
+		existing, err := pgdb.Indexes(ctx, tx, db, collection)
+		if err != nil {
+			return lazyerrors.Error(err)
+		}
+
+		// the unique _id index always exists, but it is not stored in the settings table
+		nIndexesWas = int32(len(existing) + 1)
+
+		var names []string
+		if names, all, err = indexesToDrop(index, existing); err != nil {
+			return err
+		}
+
+		for _, name := range names {
+			if err = pgdb.DropIndex(ctx, tx, db, collection, name); err != nil {
+				return lazyerrors.Error(err)
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	res := must.NotFail(types.NewDocument(
+		"nIndexesWas", nIndexesWas,
+	))
+
+	if all {
+		must.NoError(res.Set("msg", "non-_id indexes dropped for collection"))
+	}
+
+	must.NoError(res.Set("ok", float64(1)))
+
+	var reply wire.OpMsg
+	err = reply.SetSections(wire.OpMsgSection{
+		Documents: []*types.Document{res},
+	})
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	return &reply, nil
+}
+
+// indexesToDrop returns names of existing indexes specified by the index parameter of dropIndexes command:
+// an index name, "*" for all indexes except _id, an array of names, or an index key document.
+// It also returns true if all indexes were requested.
+func indexesToDrop(index any, existing []pgdb.Index) ([]string, bool, error) {
+	byName := func(name string) error {
+		if name == idIndex.Name {
+			return common.NewErrorMsg(common.ErrInvalidOptions, "cannot drop _id index")
+		}
+
+		for _, e := range existing {
+			if e.Name == name {
+				return nil
+			}
+		}
+
+		return common.NewErrorMsg(common.ErrIndexNotFound, fmt.Sprintf("index not found with name [%s]", name))
+	}
+
+	switch index := index.(type) {
+	case string:
+		if index == "*" {
+			names := make([]string, len(existing))
+			for i, e := range existing {
+				names[i] = e.Name
+			}
+
+			return names, true, nil
+		}
+
+		if err := byName(index); err != nil {
+			return nil, false, err
+		}
+
+		return []string{index}, false, nil
+
+	case *types.Array:
+		names := make([]string, index.Len())
+
+		for i := 0; i < index.Len(); i++ {
+			name, ok := must.NotFail(index.Get(i)).(string)
+			if !ok {
+				return nil, false, common.NewErrorMsg(
+					common.ErrTypeMismatch,
+					"dropIndexes: index names must be strings",
+				)
+			}
+
+			if err := byName(name); err != nil {
+				return nil, false, err
+			}
+
+			names[i] = name
+		}
+
+		return names, false, nil
+
+	case *types.Document:
+		// stored keys are compared by their canonical representation, including field order
+		key := must.NotFail(fjson.Marshal(index))
+
+		if bytes.Equal(key, must.NotFail(fjson.Marshal(idIndex.Key))) {
+			return nil, false, common.NewErrorMsg(common.ErrInvalidOptions, "cannot drop _id index")
+		}
+
+		for _, e := range existing {
+			if bytes.Equal(key, must.NotFail(fjson.Marshal(e.Key))) {
+				return []string{e.Name}, false, nil
+			}
+		}
+
+		return nil, false, common.NewErrorMsg(
+			common.ErrIndexNotFound,
+			fmt.Sprintf("can't find index with key: %s", formatIndexKey(index)),
+		)
+
+	default:
+		return nil, false, common.NewErrorMsg(
+			common.ErrTypeMismatch,
+			fmt.Sprintf(
+				"BSON field 'dropIndexes.index' is the wrong type '%s', expected types '[string, object]'",
+				common.AliasFromType(index),
+			),
+		)
+	}
+}
+
+// formatIndexKey formats index key document for error messages, like { v: 1, w: -1 }.
+func formatIndexKey(key *types.Document) string {
+	fields := make([]string, key.Len())
+	for i, k := range key.Keys() {
+		fields[i] = fmt.Sprintf("%s: %v", k, must.NotFail(key.Get(k)))
+	}
+
+	return "{ " + strings.Join(fields, ", ") + " }"
+}
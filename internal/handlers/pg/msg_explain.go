@@ -43,7 +43,21 @@ func (h *Handler) MsgExplain(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg,
 		return nil, lazyerrors.Error(err)
 	}
 
-	common.Ignored(document, h.l, "verbosity")
+	// all verbosity levels return the same output for now
+	verbosity, err := common.GetOptionalParam(document, "verbosity", "allPlansExecution")
+	if err != nil {
+		return nil, err
+	}
+
+	switch verbosity {
+	case "queryPlanner", "executionStats", "allPlansExecution":
+		// nothing
+	default:
+		return nil, common.NewErrorMsg(
+			common.ErrBadValue,
+			"verbosity string must be one of {'queryPlanner', 'executionStats', 'allPlansExecution'}",
+		)
+	}
 
 	command, err := common.GetRequiredParam[*types.Document](document, document.Command())
 	if err != nil {
@@ -54,12 +68,59 @@ func (h *Handler) MsgExplain(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg,
 		return nil, lazyerrors.Error(err)
 	}
 
+	// statement is the first (planned) statement of explained delete or update command
+	var statement *types.Document
+	var statements int32
+
+	switch command.Command() {
+	case "count", "findAndModify":
+		if sp.Filter, err = common.GetOptionalParam(command, "query", sp.Filter); err != nil {
+			return nil, err
+		}
+
+	case "delete":
+		if statement, statements, err = explainStatement(command, "deletes"); err != nil {
+			return nil, err
+		}
+
+	case "update":
+		if statement, statements, err = explainStatement(command, "updates"); err != nil {
+			return nil, err
+		}
+
+	case "aggregate":
+		if sp.Filter, err = explainPipelineFilter(command); err != nil {
+			return nil, err
+		}
+
+	default:
+		if sp.Filter, err = common.GetOptionalParam(command, "filter", sp.Filter); err != nil {
+			return nil, err
+		}
+	}
+
+	if statement != nil {
+		if sp.Filter, err = common.GetOptionalParam(statement, "q", sp.Filter); err != nil {
+			return nil, err
+		}
+	}
+
 	sp.Explain = true
 
-	var queryPlanner *types.Array
+	var postgresExplain *types.Array
+	var indexes []pgdb.Index
+	var pushdown bool
 	err = h.pgPool.InTransaction(ctx, func(tx pgx.Tx) error {
 		var err error
-		queryPlanner, err = pgdb.Explain(ctx, tx, sp)
+		if postgresExplain, err = pgdb.Explain(ctx, tx, sp); err != nil {
+			return err
+		}
+
+		if pushdown, err = pgdb.IsFilterPushedDown(ctx, tx, sp); err != nil {
+			return err
+		}
+
+		indexes, err = pgdb.Indexes(ctx, tx, sp.DB, sp.Collection)
 		return err
 	})
 	if err != nil {
@@ -85,6 +146,63 @@ func (h *Handler) MsgExplain(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg,
 		"ferretdbVersion", version.Get().Version,
 	))
 
+	// the unique _id index always exists, but it is not stored in the settings table
+	usableIndexes := must.NotFail(types.NewArray())
+	for _, name := range pgdb.UsableIndexes(append([]pgdb.Index{idIndex}, indexes...), sp.Filter) {
+		must.NoError(usableIndexes.Append(name))
+	}
+
+	filter := sp.Filter
+	if filter == nil {
+		filter = must.NotFail(types.NewDocument())
+	}
+
+	winningPlan := must.NotFail(types.NewDocument("stage", explainScanStage(postgresExplain)))
+	if command.Command() == "count" {
+		winningPlan = must.NotFail(types.NewDocument("stage", "COUNT", "inputStage", winningPlan))
+	}
+
+	queryPlanner := must.NotFail(types.NewDocument(
+		"plannerVersion", int32(1),
+		"namespace", sp.DB+"."+sp.Collection,
+		"indexFilterSet", false,
+		"parsedQuery", filter,
+		"winningPlan", winningPlan,
+		"rejectedPlans", must.NotFail(types.NewArray()),
+	))
+
+	ferretdb := must.NotFail(types.NewDocument(
+		"usableIndexes", usableIndexes,
+		"filter", filter,
+		"pushdown", pushdown,
+		"postgresExplain", postgresExplain,
+	))
+
+	switch command.Command() {
+	case "delete":
+		must.NoError(ferretdb.Set("deleteStatements", statements))
+
+	case "update":
+		var multi, upsert bool
+		if multi, err = common.GetOptionalParam(statement, "multi", multi); err != nil {
+			return nil, err
+		}
+		if upsert, err = common.GetOptionalParam(statement, "upsert", upsert); err != nil {
+			return nil, err
+		}
+
+		// documents are matched with an index scan only if some index could be used for the filter
+		matchStrategy := "COLLSCAN"
+		if usableIndexes.Len() > 0 {
+			matchStrategy = "IXSCAN"
+		}
+
+		must.NoError(ferretdb.Set("matchStrategy", matchStrategy))
+		must.NoError(ferretdb.Set("multi", multi))
+		must.NoError(ferretdb.Set("upsert", upsert))
+		must.NoError(ferretdb.Set("updateStatements", statements))
+	}
+
 	var reply wire.OpMsg
 	err = reply.SetSections(wire.OpMsgSection{
 		Documents: []*types.Document{must.NotFail(types.NewDocument(
@@ -92,6 +210,7 @@ func (h *Handler) MsgExplain(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg,
 			"explainVersion", int32(1),
 			"command", command,
 			"serverInfo", serverInfo,
+			"ferretdb", ferretdb,
 			"ok", float64(1),
 		))},
 	})
@@ -100,3 +219,97 @@ func (h *Handler) MsgExplain(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg,
 	}
 	return &reply, nil
 }
+
+// explainStatement returns the first statement of the explained delete or update command
+// stored in the given field, and the number of statements.
+//
+// Only the first statement is planned.
+func explainStatement(command *types.Document, key string) (*types.Document, int32, error) {
+	statements, err := common.GetRequiredParam[*types.Array](command, key)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	if statements.Len() == 0 {
+		return nil, 0, common.NewErrorMsg(
+			common.ErrInvalidLength,
+			"Write batch sizes must be between 1 and 100000. Got 0 operations.",
+		)
+	}
+
+	statement, err := common.AssertType[*types.Document](must.NotFail(statements.Get(0)))
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return statement, int32(statements.Len()), nil
+}
+
+// explainPipelineFilter returns the filter of the leading $match stage of the explained aggregate command,
+// or nil if there is none.
+//
+// Only that filter is pushed down to PostgreSQL, other stages are processed in memory.
+func explainPipelineFilter(command *types.Document) (*types.Document, error) {
+	pipeline, err := common.GetRequiredParam[*types.Array](command, "pipeline")
+	if err != nil {
+		return nil, err
+	}
+
+	if pipeline.Len() == 0 {
+		return nil, nil
+	}
+
+	stage, err := common.AssertType[*types.Document](must.NotFail(pipeline.Get(0)))
+	if err != nil {
+		return nil, err
+	}
+
+	if stage.Command() != "$match" {
+		return nil, nil
+	}
+
+	return common.GetRequiredParam[*types.Document](stage, "$match")
+}
+
+// explainScanStage returns the MongoDB name of the stage that PostgreSQL uses to select documents
+// in the given plans: "IXSCAN" if any index is scanned, "COLLSCAN" otherwise.
+func explainScanStage(plans *types.Array) string {
+	for i := 0; i < plans.Len(); i++ {
+		plan, ok := must.NotFail(plans.Get(i)).(*types.Document)
+		if !ok {
+			continue
+		}
+
+		node, _ := plan.Get("Plan")
+		if node, ok := node.(*types.Document); ok && explainNodeUsesIndex(node) {
+			return "IXSCAN"
+		}
+	}
+
+	return "COLLSCAN"
+}
+
+// explainNodeUsesIndex reports whether the given PostgreSQL plan node or any of its children scans an index.
+func explainNodeUsesIndex(node *types.Document) bool {
+	nodeType, _ := node.Get("Node Type")
+
+	switch nodeType {
+	case "Index Scan", "Index Only Scan", "Bitmap Index Scan":
+		return true
+	}
+
+	children, _ := node.Get("Plans")
+
+	plans, ok := children.(*types.Array)
+	if !ok {
+		return false
+	}
+
+	for i := 0; i < plans.Len(); i++ {
+		if child, ok := must.NotFail(plans.Get(i)).(*types.Document); ok && explainNodeUsesIndex(child) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -37,7 +37,6 @@ func (h *Handler) MsgFind(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg, er
 	}
 
 	unimplementedFields := []string{
-		"skip",
 		"returnKey",
 		"showRecordId",
 		"tailable",
@@ -54,14 +53,16 @@ func (h *Handler) MsgFind(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg, er
 	}
 	ignoredFields := []string{
 		"hint",
-		"batchSize",
-		"singleBatch",
-		"readConcern",
 		"max",
 		"min",
 	}
 	common.Ignored(document, h.l, ignoredFields...)
 
+	// the read concern level is used by inTransaction
+	if _, err = common.GetReadConcernLevel(document); err != nil {
+		return nil, err
+	}
+
 	var filter, sort, projection *types.Document
 	if filter, err = common.GetOptionalParam(document, "filter", filter); err != nil {
 		return nil, err
@@ -85,13 +86,33 @@ func (h *Handler) MsgFind(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg, er
 		ctx = ctxWithTimeout
 	}
 
-	var limit int64
-	if l, _ := document.Get("limit"); l != nil {
-		if limit, err = common.GetWholeNumberParam(l); err != nil {
+	batchSize := int32(-1) // the handler's default
+	if document.Has("batchSize") {
+		if batchSize, err = common.GetOptionalPositiveNumber(document, "batchSize"); err != nil {
 			return nil, err
 		}
 	}
 
+	singleBatch, err := common.GetBoolOptionalParam(document, "singleBatch")
+	if err != nil {
+		return nil, err
+	}
+
+	skip, err := common.GetSkipParam(document)
+	if err != nil {
+		return nil, err
+	}
+
+	// negative limit means that all results should be returned in a single batch
+	limit, negativeLimit, err := common.GetLimitParam(document)
+	if err != nil {
+		return nil, err
+	}
+
+	if negativeLimit {
+		singleBatch = true
+	}
+
 	var sp pgdb.SQLParam
 	if sp.DB, err = common.GetRequiredParam[string](document, "$db"); err != nil {
 		return nil, err
@@ -119,18 +140,59 @@ func (h *Handler) MsgFind(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg, er
 		}
 	}
 
+	sp.Filter = filter
+	sp.Sort = sort
+	sp.SortByID = h.sortByID
+
+	// validate projection and sort before fetching anything
+	if err = common.ValidateProjection(projection); err != nil {
+		return nil, err
+	}
+
+	if err = common.ValidateSortDocument(sort); err != nil {
+		return nil, err
+	}
+
+	txnParams, err := getTxnParams(document)
+	if err != nil {
+		return nil, err
+	}
+
+	ns := sp.DB + "." + sp.Collection
+
+	// Documents that don't have to be sorted in memory are fetched lazily by the cursor.
+	// Queries of transactions are not, as the transaction could end before getMore.
+	if txnParams == nil && sort.Len() == 0 {
+		iter, err := h.pgPool.NewQueryIterator(ctx, sp)
+		if err != nil {
+			return nil, err
+		}
+
+		cursor, err := h.cursors.FirstBatchIterator(ctx, ns, newFindIterator(iter, filter, projection, skip, limit), batchSize, singleBatch)
+		if err != nil {
+			return nil, err
+		}
+
+		return findReply(cursor)
+	}
+
 	resDocs := make([]*types.Document, 0, 16)
-	err = h.pgPool.InTransaction(ctx, func(tx pgx.Tx) error {
-		fetchedChan, err := h.pgPool.QueryDocuments(ctx, tx, sp)
+
+	// sorted is true if documents were sorted by PostgreSQL the same way as by MongoDB
+	var sorted bool
+
+	err = h.inTransaction(ctx, document, func(tx pgx.Tx) error {
+		var err error
+		if sorted, err = pgdb.IsSortPushedDown(ctx, tx, sp); err != nil {
+			return err
+		}
+
+		fetchedChan, closeFetch, err := h.pgPool.QueryDocuments(ctx, tx, sp)
+		defer closeFetch()
+
 		if err != nil {
 			return err
 		}
-		defer func() {
-			// Drain the channel to prevent leaking goroutines.
-			// TODO Offer a better design instead of channels: https://github.com/FerretDB/FerretDB/issues/898.
-			for range fetchedChan {
-			}
-		}()
 
 		for fetchedItem := range fetchedChan {
 			if fetchedItem.Err != nil {
@@ -147,6 +209,10 @@ func (h *Handler) MsgFind(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg, er
 					continue
 				}
 
+				if sorted && !pgdb.IsSortExact(doc, sort) {
+					sorted = false
+				}
+
 				resDocs = append(resDocs, doc)
 			}
 		}
@@ -158,9 +224,13 @@ func (h *Handler) MsgFind(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg, er
 		return nil, err
 	}
 
-	if err = common.SortDocuments(resDocs, sort); err != nil {
-		return nil, err
+	if !sorted {
+		if err = common.SortDocuments(resDocs, sort); err != nil {
+			return nil, err
+		}
 	}
+
+	resDocs = common.SkipDocuments(resDocs, skip)
 	if resDocs, err = common.LimitDocuments(resDocs, limit); err != nil {
 		return nil, err
 	}
@@ -168,21 +238,15 @@ func (h *Handler) MsgFind(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg, er
 		return nil, err
 	}
 
-	firstBatch := types.MakeArray(len(resDocs))
-	for _, doc := range resDocs {
-		if err = firstBatch.Append(doc); err != nil {
-			return nil, err
-		}
-	}
+	return findReply(h.cursors.FirstBatch(ctx, ns, resDocs, batchSize, singleBatch))
+}
 
+// findReply returns the find command reply with the given cursor.
+func findReply(cursor *types.Document) (*wire.OpMsg, error) {
 	var reply wire.OpMsg
-	err = reply.SetSections(wire.OpMsgSection{
+	err := reply.SetSections(wire.OpMsgSection{
 		Documents: []*types.Document{must.NotFail(types.NewDocument(
-			"cursor", must.NotFail(types.NewDocument(
-				"firstBatch", firstBatch,
-				"id", int64(0), // TODO
-				"ns", sp.DB+"."+sp.Collection,
-			)),
+			"cursor", cursor,
 			"ok", float64(1),
 		))},
 	})
@@ -192,3 +256,87 @@ func (h *Handler) MsgFind(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg, er
 
 	return &reply, nil
 }
+
+// findIterator filters, skips, limits and projects documents of the query iterator in memory.
+// It implements common.Iterator for find cursors.
+type findIterator struct {
+	iter               *pgdb.QueryIterator
+	filter, projection *types.Document
+	skip, limit        int64 // zero limit means no limit
+	returned           int64
+}
+
+// newFindIterator returns a new iterator for the given query iterator and find parameters.
+// The projection should be already validated.
+func newFindIterator(iter *pgdb.QueryIterator, filter, projection *types.Document, skip, limit int64) *findIterator {
+	return &findIterator{
+		iter:       iter,
+		filter:     filter,
+		projection: projection,
+		skip:       skip,
+		limit:      limit,
+	}
+}
+
+// Next implements common.Iterator.
+func (it *findIterator) Next(ctx context.Context, n int) ([]*types.Document, error) {
+	res := make([]*types.Document, 0, n)
+
+	for len(res) < n {
+		if it.limit != 0 && it.returned >= it.limit {
+			break
+		}
+
+		want := n - len(res)
+
+		docs, err := it.iter.Next(ctx, want)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, doc := range docs {
+			matches, err := common.FilterDocument(doc, it.filter)
+			if err != nil {
+				return nil, err
+			}
+
+			if !matches {
+				continue
+			}
+
+			if it.skip > 0 {
+				it.skip--
+				continue
+			}
+
+			if it.limit != 0 && it.returned >= it.limit {
+				break
+			}
+
+			if it.projection.Len() != 0 {
+				if doc, err = common.ProjectDocument(doc, it.projection); err != nil {
+					return nil, err
+				}
+			}
+
+			res = append(res, doc)
+			it.returned++
+		}
+
+		if len(docs) < want {
+			break
+		}
+	}
+
+	return res, nil
+}
+
+// Close implements common.Iterator.
+func (it *findIterator) Close() {
+	it.iter.Close()
+}
+
+// check interfaces
+var (
+	_ common.Iterator = (*findIterator)(nil)
+)
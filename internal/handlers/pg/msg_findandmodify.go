@@ -16,6 +16,7 @@ package pg
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"time"
 
@@ -39,7 +40,6 @@ func (h *Handler) MsgFindAndModify(ctx context.Context, msg *wire.OpMsg) (*wire.
 	unimplementedFields := []string{
 		"arrayFilters",
 		"let",
-		"fields",
 	}
 	if err := common.Unimplemented(document, unimplementedFields...); err != nil {
 		return nil, err
@@ -58,6 +58,11 @@ func (h *Handler) MsgFindAndModify(ctx context.Context, msg *wire.OpMsg) (*wire.
 		return nil, err
 	}
 
+	// validate projection before modifying anything
+	if err = common.ValidateProjection(params.fields); err != nil {
+		return nil, err
+	}
+
 	if params.maxTimeMS != 0 {
 		ctxWithTimeout, cancel := context.WithTimeout(ctx, time.Duration(params.maxTimeMS)*time.Millisecond)
 		defer cancel()
@@ -65,55 +70,74 @@ func (h *Handler) MsgFindAndModify(ctx context.Context, msg *wire.OpMsg) (*wire.
 		ctx = ctxWithTimeout
 	}
 
-	// This is not very optimal as we need to fetch everything from the database to have a proper sort.
-	// We might consider rewriting it later.
-	resDocs := make([]*types.Document, 0, 16)
-	err = h.pgPool.InTransaction(ctx, func(tx pgx.Tx) error {
-		fetchedChan, err := h.pgPool.QueryDocuments(ctx, tx, params.sqlParam)
-		if err != nil {
-			return err
-		}
-		defer func() {
-			// Drain the channel to prevent leaking goroutines.
-			// TODO Offer a better design instead of channels: https://github.com/FerretDB/FerretDB/issues/898.
-			for range fetchedChan {
+	var resDoc *types.Document
+	err = h.inTransaction(ctx, document, func(tx pgx.Tx) error {
+		// The found document could be changed or removed by a concurrent command before it is modified.
+		// In that case, nothing is modified, and the query is repeated with a new snapshot,
+		// so concurrent commands never return the same document.
+		for {
+			resDoc, err = h.findAndModify(ctx, tx, params)
+			if !errors.Is(err, errDocumentChanged) {
+				return err
 			}
-		}()
 
-		var fetchedDocs []*types.Document
-		for fetchedItem := range fetchedChan {
-			if fetchedItem.Err != nil {
-				return fetchedItem.Err
+			if err = ctx.Err(); err != nil {
+				return err
 			}
-
-			fetchedDocs = append(fetchedDocs, fetchedItem.Docs...)
 		}
+	})
+	if err != nil {
+		return nil, err
+	}
 
-		err = common.SortDocuments(fetchedDocs, params.sort)
-		if err != nil {
-			return err
-		}
+	var reply wire.OpMsg
+	must.NoError(reply.SetSections(wire.OpMsgSection{
+		Documents: []*types.Document{resDoc},
+	}))
 
-		for _, doc := range fetchedDocs {
-			matches, err := common.FilterDocument(doc, params.query)
-			if err != nil {
-				return err
-			}
+	return &reply, nil
+}
 
-			if !matches {
-				continue
-			}
+// findAndModify finds, updates or removes a single document in the given transaction
+// and returns the command's reply document.
+func (h *Handler) findAndModify(ctx context.Context, tx pgx.Tx, params *findAndModifyParams) (*types.Document, error) {
+	// This is not very optimal as we need to fetch everything from the database to have a proper sort.
+	// We might consider rewriting it later.
+	fetchedChan, closeFetch, err := h.pgPool.QueryDocuments(ctx, tx, params.sqlParam)
+	defer closeFetch()
 
-			resDocs = append(resDocs, doc)
+	if err != nil {
+		return nil, err
+	}
+
+	var fetchedDocs []*types.Document
+	for fetchedItem := range fetchedChan {
+		if fetchedItem.Err != nil {
+			return nil, fetchedItem.Err
 		}
 
-		return nil
-	})
+		fetchedDocs = append(fetchedDocs, fetchedItem.Docs...)
+	}
 
+	err = common.SortDocuments(fetchedDocs, params.sort)
 	if err != nil {
 		return nil, err
 	}
 
+	resDocs := make([]*types.Document, 0, 16)
+	for _, doc := range fetchedDocs {
+		matches, err := common.FilterDocument(doc, params.query)
+		if err != nil {
+			return nil, err
+		}
+
+		if !matches {
+			continue
+		}
+
+		resDocs = append(resDocs, doc)
+	}
+
 	// findAndModify always works with a single document
 	if resDocs, err = common.LimitDocuments(resDocs, 1); err != nil {
 		return nil, err
@@ -130,34 +154,34 @@ func (h *Handler) MsgFindAndModify(ctx context.Context, msg *wire.OpMsg) (*wire.
 				update:             params.update,
 				sqlParam:           params.sqlParam,
 			}
-			upsert, upserted, err = h.upsert(ctx, resDocs, p)
+			upsert, upserted, err = h.upsert(ctx, tx, resDocs, p)
 			if err != nil {
 				return nil, err
 			}
 		} else { // process update as usual
 			if len(resDocs) == 0 {
-				var reply wire.OpMsg
-				must.NoError(reply.SetSections(wire.OpMsgSection{
-					Documents: []*types.Document{must.NotFail(types.NewDocument(
-						"lastErrorObject", must.NotFail(types.NewDocument("n", int32(0), "updatedExisting", false)),
-						"ok", float64(1),
-					))},
-				}))
-
-				return &reply, nil
+				return must.NotFail(types.NewDocument(
+					"lastErrorObject", must.NotFail(types.NewDocument("n", int32(0), "updatedExisting", false)),
+					"ok", float64(1),
+				)), nil
 			}
 
 			if params.hasUpdateOperators {
 				upsert = resDocs[0].DeepCopy()
-				_, err = common.UpdateDocument(upsert, params.update)
+
+				update, err := common.ResolvePositionalOperator(upsert, params.query, params.update)
 				if err != nil {
 					return nil, err
 				}
 
-				_, err = h.update(ctx, &params.sqlParam, upsert)
+				_, err = common.UpdateDocument(upsert, update)
 				if err != nil {
 					return nil, err
 				}
+
+				if err = h.updateIfUnchanged(ctx, tx, &params.sqlParam, resDocs[0], upsert); err != nil {
+					return nil, err
+				}
 			} else {
 				upsert = params.update
 
@@ -165,18 +189,14 @@ func (h *Handler) MsgFindAndModify(ctx context.Context, msg *wire.OpMsg) (*wire.
 					must.NoError(upsert.Set("_id", must.NotFail(resDocs[0].Get("_id"))))
 				}
 
-				_, err = h.update(ctx, &params.sqlParam, upsert)
-				if err != nil {
+				if err = common.CheckImmutableID(must.NotFail(resDocs[0].Get("_id")), upsert); err != nil {
 					return nil, err
 				}
-			}
-		}
 
-		var resultDoc *types.Document
-		if params.returnNewDocument || len(resDocs) == 0 {
-			resultDoc = upsert
-		} else {
-			resultDoc = resDocs[0]
+				if err = h.updateIfUnchanged(ctx, tx, &params.sqlParam, resDocs[0], upsert); err != nil {
+					return nil, err
+				}
+			}
 		}
 
 		lastErrorObject := must.NotFail(types.NewDocument(
@@ -185,48 +205,55 @@ func (h *Handler) MsgFindAndModify(ctx context.Context, msg *wire.OpMsg) (*wire.
 		))
 
 		if upserted {
-			must.NoError(lastErrorObject.Set("upserted", must.NotFail(resultDoc.Get("_id"))))
+			must.NoError(lastErrorObject.Set("upserted", must.NotFail(upsert.Get("_id"))))
 		}
 
-		var reply wire.OpMsg
-		must.NoError(reply.SetSections(wire.OpMsgSection{
-			Documents: []*types.Document{must.NotFail(types.NewDocument(
+		// there is no pre-image for the inserted document
+		if !params.returnNewDocument && upserted {
+			return must.NotFail(types.NewDocument(
 				"lastErrorObject", lastErrorObject,
-				"value", resultDoc,
 				"ok", float64(1),
-			))},
-		}))
+			)), nil
+		}
+
+		resultDoc := upsert
+		if !params.returnNewDocument {
+			resultDoc = resDocs[0]
+		}
+
+		if resultDoc, err = common.ProjectDocument(resultDoc, params.fields); err != nil {
+			return nil, err
+		}
 
-		return &reply, nil
+		return must.NotFail(types.NewDocument(
+			"lastErrorObject", lastErrorObject,
+			"value", resultDoc,
+			"ok", float64(1),
+		)), nil
 	}
 
 	if params.remove {
 		if len(resDocs) == 0 {
-			var reply wire.OpMsg
-			must.NoError(reply.SetSections(wire.OpMsgSection{
-				Documents: []*types.Document{must.NotFail(types.NewDocument(
-					"lastErrorObject", must.NotFail(types.NewDocument("n", int32(0))),
-					"ok", float64(1),
-				))},
-			}))
+			return must.NotFail(types.NewDocument(
+				"lastErrorObject", must.NotFail(types.NewDocument("n", int32(0))),
+				"ok", float64(1),
+			)), nil
+		}
 
-			return &reply, nil
+		if err = h.deleteIfUnchanged(ctx, tx, &params.sqlParam, resDocs[0]); err != nil {
+			return nil, err
 		}
 
-		_, err = h.delete(ctx, &params.sqlParam, resDocs)
+		value, err := common.ProjectDocument(resDocs[0], params.fields)
 		if err != nil {
 			return nil, err
 		}
 
-		var reply wire.OpMsg
-		must.NoError(reply.SetSections(wire.OpMsgSection{
-			Documents: []*types.Document{must.NotFail(types.NewDocument(
-				"lastErrorObject", must.NotFail(types.NewDocument("n", int32(1))),
-				"value", resDocs[0],
-				"ok", float64(1),
-			))},
-		}))
-		return &reply, nil
+		return must.NotFail(types.NewDocument(
+			"lastErrorObject", must.NotFail(types.NewDocument("n", int32(1))),
+			"value", value,
+			"ok", float64(1),
+		)), nil
 	}
 
 	return nil, lazyerrors.New("bad flags combination")
@@ -241,28 +268,36 @@ type upsertParams struct {
 
 // upsert inserts new document if no documents in query result or updates given document.
 // When inserting new document we must check that `_id` is present, so we must extract `_id` from query or generate a new one.
-func (h *Handler) upsert(ctx context.Context, docs []*types.Document, params *upsertParams) (*types.Document, bool, error) {
+func (h *Handler) upsert(ctx context.Context, tx pgx.Tx, docs []*types.Document, params *upsertParams) (*types.Document, bool, error) {
 	if len(docs) == 0 {
-		upsert := must.NotFail(types.NewDocument())
+		var upsert *types.Document
 
 		if params.hasUpdateOperators {
-			_, err := common.UpdateDocument(upsert, params.update)
+			// like in MongoDB, the inserted document contains equality conditions of the query
+			upsert = common.NewUpsertDocument(params.query)
+
+			// the positional operator can't be resolved for the inserted document; that returns an error
+			update, err := common.ResolvePositionalOperator(upsert, params.query, params.update)
 			if err != nil {
 				return nil, false, err
 			}
+
+			if _, err = common.UpdateDocument(upsert, update); err != nil {
+				return nil, false, err
+			}
 		} else {
-			upsert = params.update
+			upsert = params.update.DeepCopy()
 		}
 
 		if !upsert.Has("_id") {
-			if params.query.Has("_id") {
-				must.NoError(upsert.Set("_id", must.NotFail(params.query.Get("_id"))))
+			if id, err := common.NewUpsertDocument(params.query).Get("_id"); err == nil {
+				must.NoError(upsert.Set("_id", id))
 			} else {
 				must.NoError(upsert.Set("_id", types.NewObjectID()))
 			}
 		}
 
-		err := h.insert(ctx, params.sqlParam, upsert)
+		err := h.insert(ctx, tx, params.sqlParam, upsert)
 		if err != nil {
 			return nil, false, err
 		}
@@ -273,29 +308,72 @@ func (h *Handler) upsert(ctx context.Context, docs []*types.Document, params *up
 	upsert := docs[0].DeepCopy()
 
 	if params.hasUpdateOperators {
-		_, err := common.UpdateDocument(upsert, params.update)
+		update, err := common.ResolvePositionalOperator(upsert, params.query, params.update)
 		if err != nil {
 			return nil, false, err
 		}
+
+		if _, err = common.UpdateDocument(upsert, update); err != nil {
+			return nil, false, err
+		}
 	} else {
-		for _, k := range params.update.Keys() {
-			must.NoError(upsert.Set(k, must.NotFail(params.update.Get(k))))
+		// replacement document replaces all fields except _id that can't be changed
+		if _, err := common.UpdateDocument(upsert, params.update); err != nil {
+			return nil, false, err
 		}
 	}
 
-	_, err := h.update(ctx, &params.sqlParam, upsert)
-	if err != nil {
+	if err := h.updateIfUnchanged(ctx, tx, &params.sqlParam, docs[0], upsert); err != nil {
 		return nil, false, err
 	}
 
 	return upsert, false, nil
 }
 
+// errDocumentChanged is returned if the document found by findAndModify
+// was changed or removed by a concurrent command before it was modified.
+var errDocumentChanged = errors.New("document was changed concurrently")
+
+// updateIfUnchanged replaces the old document with the new one.
+// It returns errDocumentChanged if the stored document is not the same as the old one anymore.
+func (h *Handler) updateIfUnchanged(ctx context.Context, tx pgx.Tx, sp *pgdb.SQLParam, old, doc *types.Document) error {
+	rowsUpdated, err := pgdb.SetDocumentIfUnchanged(ctx, tx, sp, old, doc)
+	if err != nil {
+		var dupErr *pgdb.DuplicateKeyError
+		if errors.As(err, &dupErr) {
+			return duplicateKeyError(sp.DB, sp.Collection, dupErr)
+		}
+
+		return err
+	}
+
+	if rowsUpdated == 0 {
+		return errDocumentChanged
+	}
+
+	return nil
+}
+
+// deleteIfUnchanged deletes the given document.
+// It returns errDocumentChanged if the stored document is not the same anymore.
+func (h *Handler) deleteIfUnchanged(ctx context.Context, tx pgx.Tx, sp *pgdb.SQLParam, doc *types.Document) error {
+	rowsDeleted, err := pgdb.DeleteDocumentIfUnchanged(ctx, tx, sp, doc)
+	if err != nil {
+		return lazyerrors.Error(err)
+	}
+
+	if rowsDeleted == 0 {
+		return errDocumentChanged
+	}
+
+	return nil
+}
+
 // findAndModifyParams represent all findAndModify requests' fields.
 // It's filled by calling prepareFindAndModifyParams.
 type findAndModifyParams struct {
 	sqlParam                              pgdb.SQLParam
-	query, sort, update                   *types.Document
+	query, sort, update, fields           *types.Document
 	remove, upsert                        bool
 	returnNewDocument, hasUpdateOperators bool
 	maxTimeMS                             int32
@@ -345,6 +423,11 @@ func prepareFindAndModifyParams(document *types.Document) (*findAndModifyParams,
 		return nil, err
 	}
 
+	var fields *types.Document
+	if fields, err = common.GetOptionalParam(document, "fields", fields); err != nil {
+		return nil, err
+	}
+
 	maxTimeMS, err := common.GetOptionalPositiveNumber(document, "maxTimeMS")
 	if err != nil {
 		return nil, err
@@ -405,6 +488,7 @@ func prepareFindAndModifyParams(document *types.Document) (*findAndModifyParams,
 		query:              query,
 		update:             update,
 		sort:               sort,
+		fields:             fields,
 		remove:             remove,
 		upsert:             upsert,
 		returnNewDocument:  returnNewDocument,
@@ -18,6 +18,7 @@ import (
 	"context"
 	"time"
 
+	"github.com/FerretDB/FerretDB/internal/handlers/common"
 	"github.com/FerretDB/FerretDB/internal/types"
 	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
 	"github.com/FerretDB/FerretDB/internal/util/must"
@@ -30,22 +31,34 @@ func (h *Handler) MsgHello(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg, e
 		return nil, err
 	}
 
+	document, err := msg.Document()
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	res := must.NotFail(types.NewDocument(
+		"isWritablePrimary", true,
+		// topologyVersion
+		"maxBsonObjectSize", int32(types.MaxDocumentLen),
+		"maxMessageSizeBytes", int32(wire.MaxMsgLen),
+		"maxWriteBatchSize", int32(100000),
+		"localTime", time.Now(),
+		"logicalSessionTimeoutMinutes", int32(logicalSessionTimeoutMinutes),
+		// connectionId
+		"minWireVersion", int32(13),
+		"maxWireVersion", int32(13),
+		"readOnly", false,
+	))
+
+	if compression := common.NegotiateCompression(document); compression != nil {
+		must.NoError(res.Set("compression", compression))
+	}
+
+	must.NoError(res.Set("ok", float64(1)))
+
 	var reply wire.OpMsg
-	err := reply.SetSections(wire.OpMsgSection{
-		Documents: []*types.Document{must.NotFail(types.NewDocument(
-			"isWritablePrimary", true,
-			// topologyVersion
-			"maxBsonObjectSize", int32(types.MaxDocumentLen),
-			"maxMessageSizeBytes", int32(wire.MaxMsgLen),
-			"maxWriteBatchSize", int32(100000),
-			"localTime", time.Now(),
-			// logicalSessionTimeoutMinutes
-			// connectionId
-			"minWireVersion", int32(13),
-			"maxWireVersion", int32(13),
-			"readOnly", false,
-			"ok", float64(1),
-		))},
+	err = reply.SetSections(wire.OpMsgSection{
+		Documents: []*types.Document{res},
 	})
 	if err != nil {
 		return nil, lazyerrors.Error(err)
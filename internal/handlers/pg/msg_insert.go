@@ -18,6 +18,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"strings"
 
 	"github.com/jackc/pgx/v4"
 
@@ -36,7 +37,7 @@ func (h *Handler) MsgInsert(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg,
 		return nil, lazyerrors.Error(err)
 	}
 
-	common.Ignored(document, h.l, "ordered", "writeConcern", "bypassDocumentValidation", "comment")
+	common.Ignored(document, h.l, "writeConcern", "bypassDocumentValidation", "comment")
 
 	var sp pgdb.SQLParam
 	if sp.DB, err = common.GetRequiredParam[string](document, "$db"); err != nil {
@@ -59,37 +60,66 @@ func (h *Handler) MsgInsert(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg,
 		return nil, err
 	}
 
+	ordered := true
+	if ordered, err = common.GetOptionalParam(document, "ordered", ordered); err != nil {
+		return nil, err
+	}
+
 	var inserted int32
+	insErrors := new(common.WriteErrors)
+
 	for i := 0; i < docs.Len(); i++ {
 		doc, err := docs.Get(i)
 		if err != nil {
 			return nil, lazyerrors.Error(err)
 		}
 
-		err = h.insert(ctx, sp, doc)
-		if err != nil {
+		err = h.inTransaction(ctx, document, func(tx pgx.Tx) error {
+			return h.insert(ctx, tx, sp, doc)
+		})
+
+		var cmdErr *common.CommandError
+		switch {
+		case err == nil:
+			inserted++
+			continue
+		case errors.As(err, &cmdErr) && (cmdErr.Code() == common.ErrDuplicateKey || cmdErr.Code() == common.ErrOverflow):
+			// each document is inserted in a separate transaction, so previous ones stay inserted
+			insErrors.Append(err, int32(i))
+		default:
 			return nil, err
 		}
 
-		inserted++
+		// If `ordered` is set as `true`, we don't insert the remaining documents
+		// after the first failure.
+		if ordered {
+			break
+		}
 	}
 
-	var reply wire.OpMsg
-	err = reply.SetSections(wire.OpMsgSection{
-		Documents: []*types.Document{must.NotFail(types.NewDocument(
-			"n", inserted,
+	var replyDoc *types.Document
+
+	// if there are insert errors append writeErrors field
+	if len(*insErrors) > 0 {
+		replyDoc = insErrors.Document()
+	} else {
+		replyDoc = must.NotFail(types.NewDocument(
 			"ok", float64(1),
-		))},
-	})
-	if err != nil {
-		return nil, lazyerrors.Error(err)
+		))
 	}
 
+	must.NoError(replyDoc.Set("n", inserted))
+
+	var reply wire.OpMsg
+	must.NoError(reply.SetSections(wire.OpMsgSection{
+		Documents: []*types.Document{replyDoc},
+	}))
+
 	return &reply, nil
 }
 
-// insert prepares and executes actual INSERT request to Postgres.
-func (h *Handler) insert(ctx context.Context, sp pgdb.SQLParam, doc any) error {
+// insert prepares and executes actual INSERT request to Postgres in the given transaction.
+func (h *Handler) insert(ctx context.Context, tx pgx.Tx, sp pgdb.SQLParam, doc any) error {
 	d, ok := doc.(*types.Document)
 	if !ok {
 		return common.NewErrorMsg(
@@ -98,16 +128,57 @@ func (h *Handler) insert(ctx context.Context, sp pgdb.SQLParam, doc any) error {
 		)
 	}
 
-	err := h.pgPool.InTransaction(ctx, func(tx pgx.Tx) error {
-		if err := pgdb.InsertDocument(ctx, tx, sp.DB, sp.Collection, d); err != nil {
-			if errors.Is(pgdb.ErrInvalidTableName, err) ||
-				errors.Is(pgdb.ErrInvalidDatabaseName, err) {
-				msg := fmt.Sprintf("Invalid namespace: %s.%s", sp.DB, sp.Collection)
-				return common.NewErrorMsg(common.ErrInvalidNamespace, msg)
-			}
-			return lazyerrors.Error(err)
+	if err := common.ValidateDocumentDepth(d, h.maxDocumentDepth); err != nil {
+		return err
+	}
+
+	if err := pgdb.InsertDocument(ctx, tx, sp.DB, sp.Collection, d); err != nil {
+		var nameErr *pgdb.InvalidCollectionNameError
+		if errors.As(err, &nameErr) {
+			return invalidCollectionNameError(sp.DB, nameErr)
 		}
-		return nil
-	})
-	return err
+		if errors.Is(err, pgdb.ErrInvalidDatabaseName) {
+			msg := fmt.Sprintf("Invalid namespace: %s.%s", sp.DB, sp.Collection)
+			return common.NewErrorMsg(common.ErrInvalidNamespace, msg)
+		}
+
+		var dupErr *pgdb.DuplicateKeyError
+		if errors.As(err, &dupErr) {
+			return duplicateKeyError(sp.DB, sp.Collection, dupErr)
+		}
+
+		return lazyerrors.Error(err)
+	}
+
+	return nil
+}
+
+// duplicateKeyError returns duplicate key error for the given *pgdb.DuplicateKeyError.
+func duplicateKeyError(db, collection string, dupErr *pgdb.DuplicateKeyError) error {
+	msg := fmt.Sprintf("E11000 duplicate key error collection: %s.%s index: %s", db, collection, dupErr.Index)
+
+	if dupErr.Key == nil {
+		return common.NewErrorMsg(common.ErrDuplicateKey, "Index build failed: "+msg)
+	}
+
+	fields := make([]string, 0, dupErr.Key.Len())
+	for _, field := range dupErr.Key.Keys() {
+		fields = append(fields, field+": "+formatDuplicateKey(must.NotFail(dupErr.Key.Get(field))))
+	}
+
+	return common.NewErrorMsg(common.ErrDuplicateKey, msg+" dup key: { "+strings.Join(fields, ", ")+" }")
+}
+
+// formatDuplicateKey formats key value for duplicate key error message.
+func formatDuplicateKey(id any) string {
+	switch id := id.(type) {
+	case string:
+		return fmt.Sprintf("%q", id)
+	case types.ObjectID:
+		return fmt.Sprintf("ObjectId('%x')", id[:])
+	case types.NullType:
+		return "null"
+	default:
+		return fmt.Sprintf("%v", id)
+	}
 }
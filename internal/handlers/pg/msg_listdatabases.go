@@ -39,7 +39,13 @@ func (h *Handler) MsgListDatabases(ctx context.Context, msg *wire.OpMsg) (*wire.
 		return nil, err
 	}
 
-	common.Ignored(document, h.l, "comment", "authorizedDatabases")
+	common.Ignored(document, h.l, "comment")
+
+	// there is no authentication yet, so all databases are authorized;
+	// the value is only validated
+	if _, err = common.GetBoolOptionalParam(document, "authorizedDatabases"); err != nil {
+		return nil, err
+	}
 
 	nameOnly, err := common.GetBoolOptionalParam(document, "nameOnly")
 	if err != nil {
@@ -0,0 +1,126 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pg
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v4"
+
+	"github.com/FerretDB/FerretDB/internal/handlers/common"
+	"github.com/FerretDB/FerretDB/internal/handlers/pg/pgdb"
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+	"github.com/FerretDB/FerretDB/internal/wire"
+)
+
+// MsgListIndexes implements HandlerInterface.
+func (h *Handler) MsgListIndexes(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg, error) {
+	document, err := msg.Document()
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	common.Ignored(document, h.l, "comment", "cursor")
+
+	command := document.Command()
+
+	db, err := common.GetRequiredParam[string](document, "$db")
+	if err != nil {
+		return nil, err
+	}
+
+	collection, err := common.GetRequiredParam[string](document, command)
+	if err != nil {
+		return nil, err
+	}
+
+	var indexes []pgdb.Index
+
+	err = h.pgPool.InTransaction(ctx, func(tx pgx.Tx) error {
+		exists, err := pgdb.CollectionExists(ctx, tx, db, collection)
+		if err != nil {
+			return lazyerrors.Error(err)
+		}
+
+		if !exists {
+			return common.NewErrorMsg(
+				common.ErrNamespaceNotFound,
+				fmt.Sprintf("ns does not exist: %s.%s", db, collection),
+			)
+		}
+
+		if indexes, err = pgdb.Indexes(ctx, tx, db, collection); err != nil {
+			return lazyerrors.Error(err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	firstBatch := types.MakeArray(len(indexes) + 1)
+
+	// the unique _id index always exists, but it is not stored in the settings table
+	for _, index := range append([]pgdb.Index{idIndex}, indexes...) {
+		doc := must.NotFail(types.NewDocument(
+			"v", int32(2),
+			"key", index.Key,
+			"name", index.Name,
+		))
+
+		if index.Unique {
+			must.NoError(doc.Set("unique", true))
+		}
+
+		if index.Sparse {
+			must.NoError(doc.Set("sparse", true))
+		}
+
+		if index.ExpireAfterSeconds != nil {
+			must.NoError(doc.Set("expireAfterSeconds", *index.ExpireAfterSeconds))
+		}
+
+		if index.Numeric {
+			must.NoError(doc.Set("numeric", true))
+		}
+
+		if index.PartialFilterExpression != nil {
+			must.NoError(doc.Set("partialFilterExpression", index.PartialFilterExpression))
+		}
+
+		must.NoError(firstBatch.Append(doc))
+	}
+
+	var reply wire.OpMsg
+	err = reply.SetSections(wire.OpMsgSection{
+		Documents: []*types.Document{must.NotFail(types.NewDocument(
+			"cursor", must.NotFail(types.NewDocument(
+				"id", int64(0),
+				"ns", db+"."+collection,
+				"firstBatch", firstBatch,
+			)),
+			"ok", float64(1),
+		))},
+	})
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	return &reply, nil
+}
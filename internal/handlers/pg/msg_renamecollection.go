@@ -0,0 +1,136 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pg
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/jackc/pgx/v4"
+
+	"github.com/FerretDB/FerretDB/internal/handlers/common"
+	"github.com/FerretDB/FerretDB/internal/handlers/pg/pgdb"
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+	"github.com/FerretDB/FerretDB/internal/wire"
+)
+
+// MsgRenameCollection implements HandlerInterface.
+func (h *Handler) MsgRenameCollection(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg, error) {
+	document, err := msg.Document()
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	common.Ignored(document, h.l, "writeConcern", "comment")
+
+	command := document.Command()
+
+	db, err := common.GetRequiredParam[string](document, "$db")
+	if err != nil {
+		return nil, err
+	}
+
+	if db != "admin" {
+		return nil, common.NewErrorMsg(
+			common.ErrUnauthorized,
+			"renameCollection may only be run against the admin database.",
+		)
+	}
+
+	from, err := common.GetRequiredParam[string](document, command)
+	if err != nil {
+		return nil, err
+	}
+
+	to, err := common.GetRequiredParam[string](document, "to")
+	if err != nil {
+		return nil, err
+	}
+
+	dropTarget, err := common.GetBoolOptionalParam(document, "dropTarget")
+	if err != nil {
+		return nil, err
+	}
+
+	fromDB, fromCollection, ok := splitNamespace(from)
+	if !ok {
+		return nil, common.NewErrorMsg(common.ErrInvalidNamespace, fmt.Sprintf("Invalid source namespace: %s", from))
+	}
+
+	toDB, toCollection, ok := splitNamespace(to)
+	if !ok {
+		return nil, common.NewErrorMsg(common.ErrInvalidNamespace, fmt.Sprintf("Invalid target namespace: %s", to))
+	}
+
+	if from == to {
+		return nil, common.NewErrorMsg(common.ErrIllegalOperation, "Can't rename a collection to itself")
+	}
+
+	err = h.pgPool.InTransaction(ctx, func(tx pgx.Tx) error {
+		exists, err := pgdb.CollectionExists(ctx, tx, fromDB, fromCollection)
+		if err != nil {
+			return lazyerrors.Error(err)
+		}
+
+		if !exists {
+			return common.NewErrorMsg(common.ErrNamespaceNotFound, fmt.Sprintf("Source collection %s does not exist", from))
+		}
+
+		if dropTarget {
+			err = pgdb.DropCollection(ctx, tx, toDB, toCollection)
+			if err != nil && !errors.Is(err, pgdb.ErrSchemaNotExist) && !errors.Is(err, pgdb.ErrTableNotExist) {
+				return lazyerrors.Error(err)
+			}
+		}
+
+		return pgdb.RenameCollection(ctx, tx, fromDB, fromCollection, toDB, toCollection)
+	})
+
+	var nameErr *pgdb.InvalidCollectionNameError
+
+	switch {
+	case err == nil:
+		// nothing
+	case errors.Is(err, pgdb.ErrAlreadyExist):
+		return nil, common.NewErrorMsg(common.ErrNamespaceExists, "target namespace exists")
+	case errors.As(err, &nameErr), errors.Is(err, pgdb.ErrInvalidDatabaseName):
+		return nil, common.NewErrorMsg(common.ErrInvalidNamespace, fmt.Sprintf("Invalid target namespace: %s", to))
+	default:
+		return nil, lazyerrors.Error(err)
+	}
+
+	var reply wire.OpMsg
+	must.NoError(reply.SetSections(wire.OpMsgSection{
+		Documents: []*types.Document{must.NotFail(types.NewDocument(
+			"ok", float64(1),
+		))},
+	}))
+
+	return &reply, nil
+}
+
+// splitNamespace splits the given namespace into database and collection names.
+func splitNamespace(ns string) (db, collection string, ok bool) {
+	db, collection, ok = strings.Cut(ns, ".")
+	if !ok || db == "" || collection == "" {
+		return "", "", false
+	}
+
+	return db, collection, true
+}
@@ -65,8 +65,11 @@ func (h *Handler) MsgUpdate(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg,
 
 	created, err := pgdb.CreateCollectionIfNotExist(ctx, h.pgPool, sp.DB, sp.Collection)
 	if err != nil {
-		if errors.Is(pgdb.ErrInvalidTableName, err) ||
-			errors.Is(pgdb.ErrInvalidDatabaseName, err) {
+		var nameErr *pgdb.InvalidCollectionNameError
+		if errors.As(err, &nameErr) {
+			return nil, invalidCollectionNameError(sp.DB, nameErr)
+		}
+		if errors.Is(err, pgdb.ErrInvalidDatabaseName) {
 			msg := fmt.Sprintf("Invalid namespace: %s.%s", sp.DB, sp.Collection)
 			return nil, common.NewErrorMsg(common.ErrInvalidNamespace, msg)
 		}
@@ -129,19 +132,15 @@ func (h *Handler) MsgUpdate(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg,
 			return nil, err
 		}
 
-		resDocs := make([]*types.Document, 0, 16)
-		err = h.pgPool.InTransaction(ctx, func(tx pgx.Tx) error {
-			fetchedChan, err := h.pgPool.QueryDocuments(ctx, tx, sp)
+		err = h.inTransaction(ctx, document, func(tx pgx.Tx) error {
+			fetchedChan, closeFetch, err := h.pgPool.QueryDocuments(ctx, tx, sp)
+			defer closeFetch()
+
 			if err != nil {
 				return err
 			}
-			defer func() {
-				// Drain the channel to prevent leaking goroutines.
-				// TODO Offer a better design instead of channels: https://github.com/FerretDB/FerretDB/issues/898.
-				for range fetchedChan {
-				}
-			}()
 
+			resDocs := make([]*types.Document, 0, 16)
 			for fetchedItem := range fetchedChan {
 				if fetchedItem.Err != nil {
 					return fetchedItem.Err
@@ -161,61 +160,73 @@ func (h *Handler) MsgUpdate(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg,
 				}
 			}
 
-			return nil
-		})
+			if len(resDocs) == 0 {
+				if !upsert {
+					// nothing to do, continue to the next update operation
+					return nil
+				}
 
-		if err != nil {
-			return nil, err
-		}
+				doc := q.DeepCopy()
 
-		if len(resDocs) == 0 {
-			if !upsert {
-				// nothing to do, continue to the next update operation
-				continue
-			}
+				// the positional operator can't be resolved for the inserted document; that returns an error
+				du, err := common.ResolvePositionalOperator(doc, q, u)
+				if err != nil {
+					return err
+				}
 
-			doc := q.DeepCopy()
-			if _, err = common.UpdateDocument(doc, u); err != nil {
-				return nil, err
-			}
-			if !doc.Has("_id") {
-				must.NoError(doc.Set("_id", types.NewObjectID()))
-			}
+				if _, err = common.UpdateDocument(doc, du); err != nil {
+					return err
+				}
+				if !doc.Has("_id") {
+					must.NoError(doc.Set("_id", types.NewObjectID()))
+				}
 
-			must.NoError(upserted.Append(must.NotFail(types.NewDocument(
-				"index", int32(0), // TODO
-				"_id", must.NotFail(doc.Get("_id")),
-			))))
+				must.NoError(upserted.Append(must.NotFail(types.NewDocument(
+					"index", int32(0), // TODO
+					"_id", must.NotFail(doc.Get("_id")),
+				))))
 
-			if err = h.insert(ctx, sp, doc); err != nil {
-				return nil, err
+				if err = h.insert(ctx, tx, sp, doc); err != nil {
+					return err
+				}
+
+				matched++
+				return nil
 			}
 
-			matched++
-			continue
-		}
+			if len(resDocs) > 1 && !multi {
+				resDocs = resDocs[:1]
+			}
 
-		if len(resDocs) > 1 && !multi {
-			resDocs = resDocs[:1]
-		}
+			matched += int32(len(resDocs))
 
-		matched += int32(len(resDocs))
+			for _, doc := range resDocs {
+				du, err := common.ResolvePositionalOperator(doc, q, u)
+				if err != nil {
+					return err
+				}
 
-		for _, doc := range resDocs {
-			changed, err := common.UpdateDocument(doc, u)
-			if err != nil {
-				return nil, err
-			}
+				changed, err := common.UpdateDocument(doc, du)
+				if err != nil {
+					return err
+				}
 
-			if !changed {
-				continue
-			}
+				if !changed {
+					continue
+				}
 
-			rowsChanged, err := h.update(ctx, &sp, doc)
-			if err != nil {
-				return nil, err
+				rowsChanged, err := h.update(ctx, tx, &sp, doc)
+				if err != nil {
+					return err
+				}
+				modified += int32(rowsChanged)
 			}
-			modified += int32(rowsChanged)
+
+			return nil
+		})
+
+		if err != nil {
+			return nil, err
 		}
 	}
 
@@ -239,12 +250,17 @@ func (h *Handler) MsgUpdate(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg,
 	return &reply, nil
 }
 
-// update updates documents by _id.
-func (h *Handler) update(ctx context.Context, sp *pgdb.SQLParam, doc *types.Document) (int64, error) {
+// update updates documents by _id in the given transaction.
+func (h *Handler) update(ctx context.Context, tx pgx.Tx, sp *pgdb.SQLParam, doc *types.Document) (int64, error) {
 	id := must.NotFail(doc.Get("_id"))
 
-	rowsUpdated, err := h.pgPool.SetDocumentByID(ctx, sp, id, doc)
+	rowsUpdated, err := pgdb.SetDocumentByID(ctx, tx, sp, id, doc)
 	if err != nil {
+		var dupErr *pgdb.DuplicateKeyError
+		if errors.As(err, &dupErr) {
+			return 0, duplicateKeyError(sp.DB, sp.Collection, dupErr)
+		}
+
 		return 0, err
 	}
 	return rowsUpdated, nil
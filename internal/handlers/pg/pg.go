@@ -16,11 +16,14 @@
 package pg
 
 import (
+	"context"
+	"fmt"
 	"time"
 
 	"go.uber.org/zap"
 
 	"github.com/FerretDB/FerretDB/internal/handlers"
+	"github.com/FerretDB/FerretDB/internal/handlers/common"
 	"github.com/FerretDB/FerretDB/internal/handlers/pg/pgdb"
 )
 
@@ -30,26 +33,89 @@ type Handler struct {
 	pgPool    *pgdb.Pool
 	l         *zap.Logger
 	startTime time.Time
+	sessions  *sessions
+	cursors   *common.Cursors
+
+	maxDocumentDepth int
+	sortByID         bool
+
+	clock          func() time.Time
+	stopTTLMonitor context.CancelFunc
+	ttlMonitorDone chan struct{}
 }
 
 // NewOpts represents handler configuration.
 type NewOpts struct {
 	PgPool *pgdb.Pool
 	L      *zap.Logger
+
+	// MaxDocumentDepth is the maximum nesting depth of inserted documents;
+	// zero value means common.DefaultMaxDocumentDepth.
+	MaxDocumentDepth int
+
+	// DefaultBatchSize is the number of documents in the first batch of find and aggregate cursors
+	// when the client does not specify it; zero value means common.DefaultBatchSize.
+	DefaultBatchSize int32
+
+	// TTLMonitorInterval is the interval between deletions of expired documents of collections
+	// with TTL indexes; zero value means defaultTTLMonitorInterval, negative values are invalid.
+	TTLMonitorInterval time.Duration
+
+	// TTLMonitorClock returns the current time used by the TTL monitor to find expired documents;
+	// nil value means time.Now. It allows tests to expire documents without waiting.
+	TTLMonitorClock func() time.Time
+
+	// SortByID makes find return documents ordered by _id when the sort is not specified,
+	// so repeated reads return them in a stable order.
+	SortByID bool
 }
 
 // New returns a new handler.
 func New(opts *NewOpts) (handlers.Interface, error) {
+	if opts.TTLMonitorInterval < 0 {
+		return nil, fmt.Errorf("pg.New: invalid TTL monitor interval %s", opts.TTLMonitorInterval)
+	}
+
 	h := &Handler{
 		pgPool:    opts.PgPool,
 		l:         opts.L,
 		startTime: time.Now(),
+		sessions:  newSessions(),
+		cursors:   common.NewCursors(opts.DefaultBatchSize),
+
+		maxDocumentDepth: opts.MaxDocumentDepth,
+		sortByID:         opts.SortByID,
+
+		clock:          opts.TTLMonitorClock,
+		ttlMonitorDone: make(chan struct{}),
+	}
+
+	if h.clock == nil {
+		h.clock = time.Now
+	}
+
+	interval := opts.TTLMonitorInterval
+	if interval == 0 {
+		interval = defaultTTLMonitorInterval
 	}
+
+	var ctx context.Context
+	ctx, h.stopTTLMonitor = context.WithCancel(context.Background())
+
+	go func() {
+		defer close(h.ttlMonitorDone)
+		h.runTTLMonitor(ctx, interval)
+	}()
+
 	return h, nil
 }
 
 // Close implements HandlerInterface.
 func (h *Handler) Close() {
+	h.stopTTLMonitor()
+	<-h.ttlMonitorDone
+
+	h.sessions.abortAll(context.Background())
 	h.pgPool.Close()
 }
 
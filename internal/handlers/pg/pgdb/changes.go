@@ -0,0 +1,139 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pgdb
+
+import (
+	"context"
+	"errors"
+	"regexp"
+
+	"github.com/jackc/pgtype/pgxtype"
+	"github.com/jackc/pgx/v4"
+
+	"github.com/FerretDB/FerretDB/internal/fjson"
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
+)
+
+// ZeroCTID is the ctid that is less than ctids of all rows.
+const ZeroCTID = "(0,0)"
+
+// validateCTIDRe validates ctids in the text form.
+var validateCTIDRe = regexp.MustCompile(`^\(\d{1,10},\d{1,5}\)$`)
+
+// InsertedDocument represents a document of the collection together with ctid of its row.
+type InsertedDocument struct {
+	CTID string
+	Doc  *types.Document
+}
+
+// IsValidCTID returns true if the given string is a valid ctid in the text form, like "(0,1)".
+func IsValidCTID(ctid string) bool {
+	return validateCTIDRe.MatchString(ctid)
+}
+
+// LastCTID returns the greatest ctid of the collection's rows.
+//
+// If the collection doesn't exist or is empty, it returns ZeroCTID and no error.
+func LastCTID(ctx context.Context, querier pgxtype.Querier, db, collection string) (string, error) {
+	exists, err := CollectionExists(ctx, querier, db, collection)
+	if err != nil {
+		return "", lazyerrors.Error(err)
+	}
+
+	if !exists {
+		return ZeroCTID, nil
+	}
+
+	table, err := getTableName(ctx, querier, db, collection)
+	if err != nil {
+		return "", lazyerrors.Error(err)
+	}
+
+	sql := `SELECT ctid::text FROM ` + pgx.Identifier{db, table}.Sanitize() + ` ORDER BY ctid DESC LIMIT 1`
+
+	var ctid string
+	if err = querier.QueryRow(ctx, sql).Scan(&ctid); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ZeroCTID, nil
+		}
+
+		return "", lazyerrors.Error(err)
+	}
+
+	return ctid, nil
+}
+
+// QueryInsertedDocuments returns documents of the collection's rows with ctids greater than the given one,
+// ordered by ctid.
+//
+// It is used for tailing inserts: new rows are appended to the end of the table,
+// so their ctids are greater than ctids of all existing rows.
+// That does not hold for rows that reuse space freed by VACUUM,
+// and new row versions created by updates are returned too.
+//
+// If the collection doesn't exist, it returns no documents and no error.
+func QueryInsertedDocuments(ctx context.Context, querier pgxtype.Querier, db, collection, ctid string) ([]InsertedDocument, error) {
+	exists, err := CollectionExists(ctx, querier, db, collection)
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	if !exists {
+		return nil, nil
+	}
+
+	table, err := getTableName(ctx, querier, db, collection)
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	sql := `SELECT ctid::text, _jsonb FROM ` + pgx.Identifier{db, table}.Sanitize() +
+		` WHERE ctid > $1::tid ORDER BY ctid`
+
+	rows, err := querier.Query(ctx, sql, ctid)
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+	defer rows.Close()
+
+	var res []InsertedDocument
+
+	for rows.Next() {
+		var d InsertedDocument
+		var b []byte
+		if err = rows.Scan(&d.CTID, &b); err != nil {
+			return nil, lazyerrors.Error(err)
+		}
+
+		v, err := fjson.Unmarshal(b)
+		if err != nil {
+			return nil, lazyerrors.Errorf("invalid _jsonb %s: %w", truncateJSONB(b), err)
+		}
+
+		var ok bool
+		if d.Doc, ok = v.(*types.Document); !ok {
+			return nil, lazyerrors.Errorf("invalid _jsonb %s: expected document, got %T", truncateJSONB(b), v)
+		}
+
+		res = append(res, d)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	return res, nil
+}
@@ -0,0 +1,98 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pgdb
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+	"github.com/FerretDB/FerretDB/internal/util/testutil"
+)
+
+func TestQueryInsertedDocuments(t *testing.T) {
+	t.Parallel()
+
+	ctx := testutil.Ctx(t)
+
+	pool := getPool(ctx, t, zaptest.NewLogger(t))
+	dbName := testutil.DatabaseName(t)
+	collectionName := testutil.CollectionName(t)
+
+	t.Cleanup(func() {
+		pool.DropDatabase(ctx, dbName)
+	})
+
+	pool.DropDatabase(ctx, dbName)
+	require.NoError(t, CreateDatabase(ctx, pool, dbName))
+
+	// non-existing collection
+	ctid, err := LastCTID(ctx, pool, dbName, collectionName)
+	require.NoError(t, err)
+	assert.Equal(t, ZeroCTID, ctid)
+
+	inserted, err := QueryInsertedDocuments(ctx, pool, dbName, collectionName, ctid)
+	require.NoError(t, err)
+	assert.Empty(t, inserted)
+
+	doc1 := must.NotFail(types.NewDocument("_id", int32(1)))
+	require.NoError(t, InsertDocument(ctx, pool, dbName, collectionName, doc1))
+
+	// documents inserted before the last ctid are not returned
+	ctid, err = LastCTID(ctx, pool, dbName, collectionName)
+	require.NoError(t, err)
+	assert.True(t, IsValidCTID(ctid), ctid)
+	assert.NotEqual(t, ZeroCTID, ctid)
+
+	inserted, err = QueryInsertedDocuments(ctx, pool, dbName, collectionName, ctid)
+	require.NoError(t, err)
+	assert.Empty(t, inserted)
+
+	doc2 := must.NotFail(types.NewDocument("_id", int32(2)))
+	doc3 := must.NotFail(types.NewDocument("_id", int32(3)))
+	require.NoError(t, InsertDocument(ctx, pool, dbName, collectionName, doc2))
+	require.NoError(t, InsertDocument(ctx, pool, dbName, collectionName, doc3))
+
+	inserted, err = QueryInsertedDocuments(ctx, pool, dbName, collectionName, ctid)
+	require.NoError(t, err)
+	require.Len(t, inserted, 2)
+	assert.Equal(t, doc2, inserted[0].Doc)
+	assert.Equal(t, doc3, inserted[1].Doc)
+
+	// all documents are returned after the zero ctid
+	inserted, err = QueryInsertedDocuments(ctx, pool, dbName, collectionName, ZeroCTID)
+	require.NoError(t, err)
+	assert.Len(t, inserted, 3)
+}
+
+func TestIsValidCTID(t *testing.T) {
+	t.Parallel()
+
+	for ctid, expected := range map[string]bool{
+		ZeroCTID:    true,
+		"(12,345)":  true,
+		"":          false,
+		"(1,2":      false,
+		"(1, 2)":    false,
+		"(-1,2)":    false,
+		"(1,2);foo": false,
+	} {
+		assert.Equal(t, expected, IsValidCTID(ctid), ctid)
+	}
+}
@@ -19,6 +19,7 @@ import (
 	"errors"
 	"regexp"
 	"strings"
+	"unicode"
 
 	"github.com/jackc/pgconn"
 	"github.com/jackc/pgerrcode"
@@ -31,10 +32,10 @@ import (
 	"github.com/FerretDB/FerretDB/internal/util/must"
 )
 
-var (
-	// Regex validateCollectionNameRe validates collection names.
-	validateCollectionNameRe = regexp.MustCompile("^[a-zA-Z_][a-zA-Z0-9_]{0,119}$")
+// maxCollectionNameLength is the maximum length of a FerretDB collection name.
+const maxCollectionNameLength = 120
 
+var (
 	// Regex validateDatabaseNameRe validates database names.
 	validateDatabaseNameRe = regexp.MustCompile("^[a-z_][a-z0-9_]{0,62}$")
 )
@@ -84,18 +85,78 @@ func CollectionExists(ctx context.Context, querier pgxtype.Querier, db, collecti
 	return slices.Contains(collections, collection), nil
 }
 
+// CollectionsExist checks existence of several FerretDB collections with a single settings read.
+// It returns a map with an entry for every given collection name.
+func CollectionsExist(ctx context.Context, querier pgxtype.Querier, db string, names []string) (map[string]bool, error) {
+	res := make(map[string]bool, len(names))
+	for _, name := range names {
+		res[name] = false
+	}
+
+	collections, err := Collections(ctx, querier, db)
+	if err != nil {
+		if errors.Is(err, ErrSchemaNotExist) {
+			return res, nil
+		}
+		return nil, err
+	}
+
+	for _, name := range names {
+		res[name] = slices.Contains(collections, name)
+	}
+
+	return res, nil
+}
+
+// ValidateCollectionName checks that the given FerretDB collection name conforms to restrictions.
+//
+// It returns *InvalidCollectionNameError with the failed check reason;
+// it wraps ErrReservedName if the name starts with the reserved prefix and ErrInvalidTableName otherwise.
+func ValidateCollectionName(name string) error {
+	var reason InvalidCollectionNameReason
+
+	switch {
+	case name == "":
+		reason = CollectionNameEmpty
+	case len(name) > maxCollectionNameLength:
+		reason = CollectionNameTooLong
+	case !isNameStartChar(name[0]):
+		reason = CollectionNameBadFirstChar
+	case strings.IndexFunc(name, func(r rune) bool { return r > unicode.MaxASCII || !isNameChar(byte(r)) }) >= 0:
+		reason = CollectionNameBadChar
+	case strings.HasPrefix(name, reservedPrefix):
+		reason = CollectionNameReserved
+	default:
+		return nil
+	}
+
+	return &InvalidCollectionNameError{Name: name, Reason: reason}
+}
+
+// isNameStartChar returns true if c is allowed as the first character of a collection name.
+func isNameStartChar(c byte) bool {
+	return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+// isNameChar returns true if c is allowed in a collection name.
+func isNameChar(c byte) bool {
+	return isNameStartChar(c) || (c >= '0' && c <= '9')
+}
+
 // CreateCollection creates a new FerretDB collection in existing schema.
 //
 // It returns a possibly wrapped error:
-//   - ErrInvalidTableName - if a FerretDB collection name doesn't conform to restrictions.
+//   - ErrInvalidTableName - if a FerretDB collection name doesn't conform to restrictions,
+//     the actual error is *InvalidCollectionNameError.
+//   - ErrReservedName - if a FerretDB collection name starts with the reserved prefix,
+//     the actual error is *InvalidCollectionNameError.
 //   - ErrAlreadyExist - if a FerretDB collection with the given names already exists.
 //   - ErrTableNotExist - is the required FerretDB database does not exist.
 //
 // Please use errors.Is to check the error.
 func CreateCollection(ctx context.Context, querier pgxtype.Querier, db, collection string) error {
-	if !validateCollectionNameRe.MatchString(collection) ||
-		strings.HasPrefix(collection, reservedPrefix) {
-		return ErrInvalidTableName
+	if err := ValidateCollectionName(collection); err != nil {
+		return err
 	}
 
 	schemaExists, err := schemaExists(ctx, querier, db)
@@ -141,23 +202,73 @@ func CreateCollection(ctx context.Context, querier pgxtype.Querier, db, collecti
 	}
 
 	sql := `CREATE TABLE IF NOT EXISTS ` + pgx.Identifier{db, table}.Sanitize() + ` (_jsonb jsonb)`
-	if _, err = querier.Exec(ctx, sql); err == nil {
-		return nil
+	if _, err = querier.Exec(ctx, sql); err != nil {
+		var pgErr *pgconn.PgError
+		if !errors.As(err, &pgErr) {
+			return lazyerrors.Error(err)
+		}
+
+		switch pgErr.Code {
+		case pgerrcode.UniqueViolation, pgerrcode.DuplicateObject, pgerrcode.DuplicateTable:
+			// https://www.postgresql.org/message-id/CA+TgmoZAdYVtwBfp1FL2sMZbiHCWT4UPrzRLNnX1Nb30Ku3-gg@mail.gmail.com
+			// Reproducible by integration tests.
+			return ErrAlreadyExist
+		default:
+			return lazyerrors.Error(err)
+		}
 	}
 
-	var pgErr *pgconn.PgError
-	if !errors.As(err, &pgErr) {
+	// large documents are compressed and moved out of the table row (TOASTed) if needed
+	sql = `ALTER TABLE ` + pgx.Identifier{db, table}.Sanitize() + ` ALTER COLUMN _jsonb SET STORAGE EXTENDED`
+	if _, err = querier.Exec(ctx, sql); err != nil {
 		return lazyerrors.Error(err)
 	}
 
-	switch pgErr.Code {
-	case pgerrcode.UniqueViolation, pgerrcode.DuplicateObject, pgerrcode.DuplicateTable:
-		// https://www.postgresql.org/message-id/CA+TgmoZAdYVtwBfp1FL2sMZbiHCWT4UPrzRLNnX1Nb30Ku3-gg@mail.gmail.com
-		// Reproducible by integration tests.
-		return ErrAlreadyExist
-	default:
+	if err = createIDIndex(ctx, querier, db, table); err != nil {
+		return lazyerrors.Error(err)
+	}
+
+	return nil
+}
+
+// createIDIndex creates unique _id index for the given table if it does not exist.
+// Documents without _id are not affected as NULLs are distinct.
+//
+// The index is built on the fjson representation of _id, so numerically equal _id values
+// of different types (like int32 1, int64 1, and double 1.0) do not conflict, unlike in MongoDB.
+func createIDIndex(ctx context.Context, querier pgxtype.Querier, db, table string) error {
+	sql := `CREATE UNIQUE INDEX IF NOT EXISTS ` + pgx.Identifier{idIndexName(table)}.Sanitize() +
+		` ON ` + pgx.Identifier{db, table}.Sanitize() + ` ((_jsonb->'_id'))`
+	if _, err := querier.Exec(ctx, sql); err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && (pgErr.Code == pgerrcode.UniqueViolation || pgErr.Code == pgerrcode.DuplicateTable) {
+			// the same index was created concurrently
+			return nil
+		}
+
 		return lazyerrors.Error(err)
 	}
+
+	return nil
+}
+
+// idIndexExists returns true if the unique _id index exists for the given table.
+//
+// It may not exist for tables created by older versions.
+func idIndexExists(ctx context.Context, querier pgxtype.Querier, db, table string) (bool, error) {
+	sql := `SELECT EXISTS(SELECT 1 FROM pg_indexes WHERE schemaname = $1 AND tablename = $2 AND indexname = $3)`
+
+	var exists bool
+	if err := querier.QueryRow(ctx, sql, db, table, idIndexName(table)).Scan(&exists); err != nil {
+		return false, lazyerrors.Error(err)
+	}
+
+	return exists, nil
+}
+
+// idIndexName returns the name of unique _id index for the given table.
+func idIndexName(table string) string {
+	return formatCollectionName(table + "_id_idx")
 }
 
 // CreateCollectionIfNotExist ensures that given FerretDB database / PostgreSQL schema
@@ -194,6 +305,151 @@ func CreateCollectionIfNotExist(ctx context.Context, querier pgxtype.Querier, db
 	return true, nil
 }
 
+// RenameCollection renames FerretDB collection, possibly moving it to another FerretDB database.
+// The target database is created if it does not exist.
+//
+// The PostgreSQL table and its indexes are renamed to match the new collection name,
+// and the table is moved to the target schema; settings of both databases are updated.
+//
+// It returns (possibly wrapped):
+//   - ErrInvalidCollectionName or ErrReservedName - if the target collection name is invalid;
+//   - ErrInvalidDatabaseName - if the target database name is invalid;
+//   - ErrTableNotExist - if the source collection does not exist;
+//   - ErrAlreadyExist - if the target collection already exists.
+//
+// Please use errors.Is to check the error.
+func RenameCollection(ctx context.Context, querier pgxtype.Querier, db, collection, toDB, toCollection string) error {
+	if err := ValidateCollectionName(toCollection); err != nil {
+		return err
+	}
+
+	exists, err := CollectionExists(ctx, querier, db, collection)
+	if err != nil {
+		return lazyerrors.Error(err)
+	}
+
+	if !exists {
+		return ErrTableNotExist
+	}
+
+	if err = CreateDatabaseIfNotExists(ctx, querier, toDB); err != nil && !errors.Is(err, ErrAlreadyExist) {
+		return err
+	}
+
+	if exists, err = CollectionExists(ctx, querier, toDB, toCollection); err != nil {
+		return lazyerrors.Error(err)
+	}
+
+	if exists {
+		return ErrAlreadyExist
+	}
+
+	table, err := getTableName(ctx, querier, db, collection)
+	if err != nil {
+		return lazyerrors.Error(err)
+	}
+
+	indexes, err := Indexes(ctx, querier, db, collection)
+	if err != nil {
+		return lazyerrors.Error(err)
+	}
+
+	if err = removeTableFromSettings(ctx, querier, db, collection); err != nil {
+		return lazyerrors.Error(err)
+	}
+
+	toTable := formatCollectionName(toCollection)
+	if toTable != table {
+		if err = renameTable(ctx, querier, db, table, toTable, indexes); err != nil {
+			return lazyerrors.Error(err)
+		}
+	}
+
+	if toDB != db {
+		// indexes are moved together with the table
+		sql := `ALTER TABLE ` + pgx.Identifier{db, toTable}.Sanitize() + ` SET SCHEMA ` + pgx.Identifier{toDB}.Sanitize()
+		if _, err = querier.Exec(ctx, sql); err != nil {
+			return lazyerrors.Error(err)
+		}
+	}
+
+	settings, err := getSettingsTable(ctx, querier, toDB)
+	if err != nil {
+		return lazyerrors.Error(err)
+	}
+
+	collections, ok := must.NotFail(settings.Get("collections")).(*types.Document)
+	if !ok {
+		return lazyerrors.Errorf("invalid settings document")
+	}
+
+	must.NoError(collections.Set(toCollection, toTable))
+	must.NoError(settings.Set("collections", collections))
+	setSettingsIndexes(settings, toCollection, indexes)
+
+	if err = updateSettingsTable(ctx, querier, toDB, settings); err != nil {
+		return lazyerrors.Error(err)
+	}
+
+	return nil
+}
+
+// renameTable renames the table in the given schema, and its _id, numeric, and secondary indexes,
+// as their names are derived from the table name.
+func renameTable(ctx context.Context, querier pgxtype.Querier, schema, table, toTable string, secondary []Index) error {
+	sql := `SELECT indexname, indexdef FROM pg_indexes WHERE schemaname = $1 AND tablename = $2`
+	rows, err := querier.Query(ctx, sql, schema, table)
+	if err != nil {
+		return lazyerrors.Error(err)
+	}
+	defer rows.Close()
+
+	// index names to new names
+	indexes := make(map[string]string)
+
+	for rows.Next() {
+		var name, def string
+		if err = rows.Scan(&name, &def); err != nil {
+			return lazyerrors.Error(err)
+		}
+
+		if name == idIndexName(table) {
+			indexes[name] = idIndexName(toTable)
+			continue
+		}
+
+		if m := numericIndexFieldRe.FindStringSubmatch(def); m != nil {
+			indexes[name] = numericIndexName(toTable, strings.ReplaceAll(m[1], `''`, `'`))
+			continue
+		}
+
+		for _, index := range secondary {
+			if name == indexName(table, index.Name) {
+				indexes[name] = indexName(toTable, index.Name)
+				break
+			}
+		}
+	}
+
+	if err = rows.Err(); err != nil {
+		return lazyerrors.Error(err)
+	}
+
+	sql = `ALTER TABLE ` + pgx.Identifier{schema, table}.Sanitize() + ` RENAME TO ` + pgx.Identifier{toTable}.Sanitize()
+	if _, err = querier.Exec(ctx, sql); err != nil {
+		return lazyerrors.Error(err)
+	}
+
+	for name, toName := range indexes {
+		sql = `ALTER INDEX ` + pgx.Identifier{schema, name}.Sanitize() + ` RENAME TO ` + pgx.Identifier{toName}.Sanitize()
+		if _, err = querier.Exec(ctx, sql); err != nil {
+			return lazyerrors.Error(err)
+		}
+	}
+
+	return nil
+}
+
 // DropCollection drops FerretDB collection.
 //
 // It returns (possibly wrapped) ErrTableNotExist if schema or table does not exist.
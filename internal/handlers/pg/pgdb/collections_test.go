@@ -0,0 +1,190 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pgdb
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+	"github.com/FerretDB/FerretDB/internal/util/testutil"
+)
+
+func TestValidateCollectionName(t *testing.T) {
+	t.Parallel()
+
+	for name, tc := range map[string]struct { //nolint:paralleltest // false positive
+		collection string
+		reason     InvalidCollectionNameReason // zero value means the name is valid
+	}{
+		"Valid": {
+			collection: "Collection_1",
+		},
+		"ValidUnderscore": {
+			collection: "_collection",
+		},
+		"ValidMaxLength": {
+			collection: strings.Repeat("a", maxCollectionNameLength),
+		},
+		"Empty": {
+			collection: "",
+			reason:     CollectionNameEmpty,
+		},
+		"TooLong": {
+			collection: strings.Repeat("a", maxCollectionNameLength+1),
+			reason:     CollectionNameTooLong,
+		},
+		"BadFirstChar": {
+			collection: "1collection",
+			reason:     CollectionNameBadFirstChar,
+		},
+		"BadChar": {
+			collection: "collection-$",
+			reason:     CollectionNameBadChar,
+		},
+		"NonASCII": {
+			collection: "collectioné",
+			reason:     CollectionNameBadChar,
+		},
+		"Reserved": {
+			collection: reservedPrefix + "collection",
+			reason:     CollectionNameReserved,
+		},
+	} {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			err := ValidateCollectionName(tc.collection)
+			if tc.reason == 0 {
+				assert.NoError(t, err)
+				return
+			}
+
+			var nameErr *InvalidCollectionNameError
+			require.True(t, errors.As(err, &nameErr))
+			assert.Equal(t, tc.collection, nameErr.Name)
+			assert.Equal(t, tc.reason, nameErr.Reason)
+
+			if tc.reason == CollectionNameReserved {
+				assert.ErrorIs(t, err, ErrReservedName)
+				assert.NotErrorIs(t, err, ErrInvalidTableName)
+				return
+			}
+
+			assert.ErrorIs(t, err, ErrInvalidTableName)
+			assert.NotErrorIs(t, err, ErrReservedName)
+		})
+	}
+}
+
+func TestCreateCollectionReservedName(t *testing.T) {
+	t.Parallel()
+
+	// the name is checked before any query, so there is no need for a real connection
+	err := CreateCollection(context.Background(), nil, "db", reservedPrefix+"collection")
+	assert.ErrorIs(t, err, ErrReservedName)
+	assert.NotErrorIs(t, err, ErrInvalidTableName)
+
+	err = CreateCollection(context.Background(), nil, "db", "collection-$")
+	assert.ErrorIs(t, err, ErrInvalidTableName)
+	assert.NotErrorIs(t, err, ErrReservedName)
+}
+
+func TestRenameCollection(t *testing.T) {
+	t.Parallel()
+
+	ctx := testutil.Ctx(t)
+
+	pool := getPool(ctx, t, zaptest.NewLogger(t))
+	dbName := testutil.DatabaseName(t)
+	toDBName := dbName + "_to"
+	collectionName := testutil.CollectionName(t)
+
+	t.Cleanup(func() {
+		pool.DropDatabase(ctx, dbName)
+		pool.DropDatabase(ctx, toDBName)
+	})
+
+	pool.DropDatabase(ctx, dbName)
+	pool.DropDatabase(ctx, toDBName)
+	require.NoError(t, CreateDatabase(ctx, pool, dbName))
+
+	for i := int32(1); i <= 3; i++ {
+		doc := must.NotFail(types.NewDocument("_id", i, "v", i*10))
+		require.NoError(t, InsertDocument(ctx, pool, dbName, collectionName, doc))
+	}
+	require.NoError(t, CreateNumericIndex(ctx, pool, dbName, collectionName, "v"))
+
+	// readAll returns _id values of all documents in the given collection.
+	readAll := func(t *testing.T, db, collection string) []any {
+		t.Helper()
+
+		fetchedChan, closeFetch, err := pool.QueryDocuments(ctx, pool, SQLParam{DB: db, Collection: collection})
+		require.NoError(t, err)
+		defer closeFetch()
+
+		var res []any
+		for fetched := range fetchedChan {
+			require.NoError(t, fetched.Err)
+			for _, doc := range fetched.Docs {
+				res = append(res, must.NotFail(doc.Get("_id")))
+			}
+		}
+		return res
+	}
+
+	err := RenameCollection(ctx, pool, dbName, collectionName+"_missing", toDBName, collectionName)
+	require.ErrorIs(t, err, ErrTableNotExist)
+
+	err = RenameCollection(ctx, pool, dbName, collectionName, toDBName, "_ferretdb_foo")
+	require.ErrorIs(t, err, ErrReservedName)
+
+	// move to another database that does not exist yet
+	require.NoError(t, RenameCollection(ctx, pool, dbName, collectionName, toDBName, collectionName+"_moved"))
+
+	exists, err := CollectionExists(ctx, pool, dbName, collectionName)
+	require.NoError(t, err)
+	assert.False(t, exists)
+
+	collections, err := Collections(ctx, pool, toDBName)
+	require.NoError(t, err)
+	assert.Equal(t, []string{collectionName + "_moved"}, collections)
+
+	assert.Equal(t, []any{int32(1), int32(2), int32(3)}, readAll(t, toDBName, collectionName+"_moved"))
+
+	// indexes are renamed too
+	table := formatCollectionName(collectionName + "_moved")
+	exists, err = numericIndexExists(ctx, pool, toDBName, table, "v")
+	require.NoError(t, err)
+	assert.True(t, exists)
+
+	err = InsertDocument(ctx, pool, toDBName, collectionName+"_moved", must.NotFail(types.NewDocument("_id", int32(1))))
+	require.ErrorIs(t, err, ErrDuplicateKey)
+
+	// the old name could be used again
+	require.NoError(t, InsertDocument(ctx, pool, dbName, collectionName, must.NotFail(types.NewDocument("_id", int32(4)))))
+	assert.Equal(t, []any{int32(4)}, readAll(t, dbName, collectionName))
+
+	err = RenameCollection(ctx, pool, dbName, collectionName, toDBName, collectionName+"_moved")
+	require.ErrorIs(t, err, ErrAlreadyExist)
+}
@@ -28,9 +28,12 @@ import (
 )
 
 // Databases returns a sorted list of FerretDB database names / PostgreSQL schema names.
+//
+// Only schemas that contain FerretDB settings table are returned;
+// system schemas and other PostgreSQL schemas are skipped.
 func Databases(ctx context.Context, querier pgxtype.Querier) ([]string, error) {
-	sql := "SELECT schema_name FROM information_schema.schemata ORDER BY schema_name"
-	rows, err := querier.Query(ctx, sql)
+	sql := "SELECT table_schema FROM information_schema.tables WHERE table_name = $1 ORDER BY table_schema"
+	rows, err := querier.Query(ctx, sql, settingsTableName)
 	if err != nil {
 		return nil, lazyerrors.Error(err)
 	}
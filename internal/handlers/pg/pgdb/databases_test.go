@@ -0,0 +1,139 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pgdb
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/jackc/pgx/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+
+	"github.com/FerretDB/FerretDB/internal/util/testutil"
+)
+
+func TestCreateDatabaseName(t *testing.T) {
+	t.Parallel()
+
+	ctx := testutil.Ctx(t)
+	pool := getPool(ctx, t, zaptest.NewLogger(t))
+
+	for name, tc := range map[string]struct {
+		db  string
+		err error
+	}{
+		"Valid": {
+			db: testutil.DatabaseName(t),
+		},
+		"Uppercase": {
+			db:  "TestCreateDatabaseName",
+			err: ErrInvalidDatabaseName,
+		},
+		"TooLong": {
+			db:  strings.Repeat("a", 64),
+			err: ErrInvalidDatabaseName,
+		},
+		"ReservedPrefix": {
+			db:  reservedPrefix + "db",
+			err: ErrInvalidDatabaseName,
+		},
+	} {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			t.Cleanup(func() {
+				pool.DropDatabase(ctx, tc.db)
+			})
+
+			err := CreateDatabase(ctx, pool, tc.db)
+			if tc.err != nil {
+				assert.ErrorIs(t, err, tc.err)
+				return
+			}
+
+			require.NoError(t, err)
+
+			databases, err := Databases(ctx, pool)
+			require.NoError(t, err)
+			assert.Contains(t, databases, tc.db)
+		})
+	}
+}
+
+func TestDatabases(t *testing.T) {
+	t.Parallel()
+
+	ctx := testutil.Ctx(t)
+	pool := getPool(ctx, t, zaptest.NewLogger(t))
+
+	dbName := testutil.DatabaseName(t)
+	db1, db2, plain := dbName+"_1", dbName+"_2", dbName+"_plain"
+
+	t.Cleanup(func() {
+		for _, db := range []string{db1, db2, plain} {
+			pool.DropDatabase(ctx, db)
+		}
+	})
+
+	require.NoError(t, CreateDatabase(ctx, pool, db2))
+	require.NoError(t, CreateDatabase(ctx, pool, db1))
+
+	_, err := pool.Exec(ctx, `CREATE SCHEMA `+pgx.Identifier{plain}.Sanitize())
+	require.NoError(t, err)
+
+	databases, err := Databases(ctx, pool)
+	require.NoError(t, err)
+
+	var actual []string
+	for _, db := range databases {
+		if strings.HasPrefix(db, dbName) {
+			actual = append(actual, db)
+		}
+	}
+
+	assert.Equal(t, []string{db1, db2}, actual)
+	assert.NotContains(t, databases, "public")
+	assert.NotContains(t, databases, "information_schema")
+}
+
+func TestDropDatabase(t *testing.T) {
+	t.Parallel()
+
+	ctx := testutil.Ctx(t)
+	pool := getPool(ctx, t, zaptest.NewLogger(t))
+
+	dbName := testutil.DatabaseName(t)
+
+	t.Cleanup(func() {
+		pool.DropDatabase(ctx, dbName)
+	})
+
+	require.NoError(t, CreateDatabase(ctx, pool, dbName))
+
+	existed, err := pool.DropDatabase(ctx, dbName)
+	require.NoError(t, err)
+	assert.True(t, existed)
+
+	existed, err = pool.DropDatabase(ctx, dbName)
+	require.NoError(t, err)
+	assert.False(t, existed)
+
+	databases, err := Databases(ctx, pool)
+	require.NoError(t, err)
+	assert.NotContains(t, databases, dbName)
+}
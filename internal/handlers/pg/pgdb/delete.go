@@ -16,11 +16,16 @@ package pgdb
 
 import (
 	"context"
+	"encoding/json"
 	"strings"
+	"time"
 
+	"github.com/jackc/pgtype/pgxtype"
 	"github.com/jackc/pgx/v4"
 
 	"github.com/FerretDB/FerretDB/internal/fjson"
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
 	"github.com/FerretDB/FerretDB/internal/util/must"
 )
 
@@ -59,3 +64,73 @@ func DeleteDocumentsByID(ctx context.Context, tx pgx.Tx, sp *SQLParam, ids []any
 
 	return tag.RowsAffected(), nil
 }
+
+// DeleteDocumentIfUnchanged deletes the given document only if the stored document is still the same.
+// It returns 0 if it was changed or removed concurrently.
+func DeleteDocumentIfUnchanged(ctx context.Context, tx pgx.Tx, sp *SQLParam, doc *types.Document) (int64, error) {
+	table, err := getTableName(ctx, tx, sp.DB, sp.Collection)
+	if err != nil {
+		return 0, err
+	}
+
+	sql := `DELETE `
+
+	if sp.Comment != "" {
+		sp.Comment = strings.ReplaceAll(sp.Comment, "/*", "/ *")
+		sp.Comment = strings.ReplaceAll(sp.Comment, "*/", "* /")
+
+		sql += `/* ` + sp.Comment + ` */ `
+	}
+
+	sql += `FROM ` + pgx.Identifier{sp.DB, table}.Sanitize() + ` WHERE _jsonb->'_id' = $1 AND _jsonb = $2`
+
+	id := must.NotFail(doc.Get("_id"))
+
+	tag, err := tx.Exec(ctx, sql, must.NotFail(fjson.Marshal(id)), must.NotFail(fjson.Marshal(doc)))
+	if err != nil {
+		return 0, err
+	}
+
+	return tag.RowsAffected(), nil
+}
+
+// DeleteExpired deletes documents of the collection which (possibly dotted) field value is a date before expireAt,
+// or an array containing such date, and returns the number of deleted documents.
+// Documents without the field or with non-date values are not deleted.
+// It returns ErrTableNotExist if the collection does not exist.
+//
+// Dates are stored as {"$d": milliseconds}, so values are compared with a single SQL/JSON path query
+// which unwraps arrays and skips values of other types.
+func DeleteExpired(ctx context.Context, querier pgxtype.Querier, db, collection, field string, expireAt time.Time) (int64, error) {
+	exists, err := CollectionExists(ctx, querier, db, collection)
+	if err != nil {
+		return 0, lazyerrors.Error(err)
+	}
+
+	if !exists {
+		return 0, ErrTableNotExist
+	}
+
+	table, err := getTableName(ctx, querier, db, collection)
+	if err != nil {
+		return 0, lazyerrors.Error(err)
+	}
+
+	path := `$`
+	for _, f := range strings.Split(field, ".") {
+		path += `.` + string(must.NotFail(json.Marshal(f)))
+	}
+	path += `[*]."$d" ? (@ < $expireAt)`
+
+	vars := must.NotFail(json.Marshal(map[string]int64{"expireAt": expireAt.UnixMilli()}))
+
+	sql := `DELETE FROM ` + pgx.Identifier{db, table}.Sanitize() +
+		` WHERE jsonb_path_exists(_jsonb, $1::jsonpath, $2::jsonb)`
+
+	tag, err := querier.Exec(ctx, sql, path, string(vars))
+	if err != nil {
+		return 0, lazyerrors.Error(err)
+	}
+
+	return tag.RowsAffected(), nil
+}
@@ -0,0 +1,117 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pgdb
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+	"github.com/FerretDB/FerretDB/internal/util/testutil"
+)
+
+func TestDeleteExpired(t *testing.T) {
+	t.Parallel()
+
+	ctx := testutil.Ctx(t)
+
+	pool := getPool(ctx, t, zaptest.NewLogger(t))
+	dbName := testutil.DatabaseName(t)
+	collectionName := testutil.CollectionName(t)
+
+	t.Cleanup(func() {
+		pool.DropDatabase(ctx, dbName)
+	})
+
+	pool.DropDatabase(ctx, dbName)
+
+	_, err := DeleteExpired(ctx, pool, dbName, collectionName, "createdAt", time.Now())
+	require.ErrorIs(t, err, ErrTableNotExist)
+
+	now := time.Date(2022, 10, 1, 12, 0, 0, 0, time.UTC)
+
+	for _, doc := range []*types.Document{
+		must.NotFail(types.NewDocument("_id", "expired", "createdAt", now.Add(-time.Hour))),
+		must.NotFail(types.NewDocument("_id", "expired-array", "createdAt", must.NotFail(types.NewArray(
+			"foo", now.Add(time.Hour), now.Add(-time.Hour),
+		)))),
+		must.NotFail(types.NewDocument("_id", "fresh", "createdAt", now.Add(time.Hour))),
+		must.NotFail(types.NewDocument("_id", "string", "createdAt", "2020-01-01")),
+		must.NotFail(types.NewDocument("_id", "number", "createdAt", int64(0))),
+		must.NotFail(types.NewDocument("_id", "missing")),
+	} {
+		require.NoError(t, InsertDocument(ctx, pool, dbName, collectionName, doc))
+	}
+
+	deleted, err := DeleteExpired(ctx, pool, dbName, collectionName, "createdAt", now)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), deleted)
+
+	// the next sweep at the same time deletes nothing
+	deleted, err = DeleteExpired(ctx, pool, dbName, collectionName, "createdAt", now)
+	require.NoError(t, err)
+	assert.Equal(t, int64(0), deleted)
+
+	// the fresh document expires later
+	deleted, err = DeleteExpired(ctx, pool, dbName, collectionName, "createdAt", now.Add(2*time.Hour))
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), deleted)
+}
+
+func TestDeleteDocumentIfUnchanged(t *testing.T) {
+	t.Parallel()
+
+	ctx := testutil.Ctx(t)
+
+	pool := getPool(ctx, t, zaptest.NewLogger(t))
+	dbName := testutil.DatabaseName(t)
+	collectionName := testutil.CollectionName(t)
+
+	t.Cleanup(func() {
+		pool.DropDatabase(ctx, dbName)
+	})
+
+	pool.DropDatabase(ctx, dbName)
+
+	sp := &SQLParam{DB: dbName, Collection: collectionName}
+	doc := must.NotFail(types.NewDocument("_id", int64(1), "v", 1.5, "w", "foo"))
+	require.NoError(t, InsertDocument(ctx, pool, dbName, collectionName, doc))
+
+	changed := must.NotFail(types.NewDocument("_id", int64(1), "v", 1.5, "w", "bar"))
+
+	err := pool.InTransaction(ctx, func(tx pgx.Tx) error {
+		deleted, err := DeleteDocumentIfUnchanged(ctx, tx, sp, changed)
+		require.NoError(t, err)
+		assert.Equal(t, int64(0), deleted)
+
+		deleted, err = DeleteDocumentIfUnchanged(ctx, tx, sp, doc)
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), deleted)
+
+		// already deleted
+		deleted, err = DeleteDocumentIfUnchanged(ctx, tx, sp, doc)
+		require.NoError(t, err)
+		assert.Equal(t, int64(0), deleted)
+
+		return nil
+	})
+	require.NoError(t, err)
+}
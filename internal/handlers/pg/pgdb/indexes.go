@@ -0,0 +1,596 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pgdb
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"strings"
+
+	"github.com/jackc/pgconn"
+	"github.com/jackc/pgerrcode"
+	"github.com/jackc/pgtype/pgxtype"
+	"github.com/jackc/pgx/v4"
+	"golang.org/x/exp/slices"
+
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+)
+
+// numericIndexFieldRe extracts the field name from the definition of the numeric index
+// returned by PostgreSQL; see numericFieldExpr.
+var numericIndexFieldRe = regexp.MustCompile(`\(_jsonb ->> '((?:[^']|'')*)'::text\)\)::bigint`)
+
+// ErrIndexInvalidValue indicates that the index can't be created because of existing values.
+var ErrIndexInvalidValue = errors.New("index can't be created for existing values")
+
+// CreateNumericIndex creates an expression index on the given top-level field values cast to bigint,
+// so numeric range filters on that field could be pushed down to PostgreSQL and use that index.
+//
+// By creating such index, the user promises that the field contains only int32 values
+// or strings representing integers (or is missing or null).
+// Inserting or updating a document with other values of that field fails.
+// If existing documents have other values, the index is not created, and ErrIndexInvalidValue is returned.
+//
+// The index is created only if it does not exist yet.
+func CreateNumericIndex(ctx context.Context, querier pgxtype.Querier, db, collection, field string) error {
+	table, err := getTableName(ctx, querier, db, collection)
+	if err != nil {
+		return lazyerrors.Error(err)
+	}
+
+	sql := `CREATE INDEX IF NOT EXISTS ` + pgx.Identifier{numericIndexName(table, field)}.Sanitize() +
+		` ON ` + pgx.Identifier{db, table}.Sanitize() + ` (` + numericFieldExpr(field) + ` NULLS FIRST)`
+	if _, err = querier.Exec(ctx, sql); err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) {
+			switch pgErr.Code {
+			case pgerrcode.InvalidTextRepresentation, pgerrcode.NumericValueOutOfRange:
+				return lazyerrors.Errorf("%w: %s", ErrIndexInvalidValue, pgErr.Message)
+			case pgerrcode.UniqueViolation, pgerrcode.DuplicateTable:
+				// the same index was created concurrently
+				return nil
+			}
+		}
+
+		return lazyerrors.Error(err)
+	}
+
+	return nil
+}
+
+// numericIndexExists returns true if the numeric index for the given field exists.
+func numericIndexExists(ctx context.Context, querier pgxtype.Querier, db, table, field string) (bool, error) {
+	sql := `SELECT EXISTS(SELECT 1 FROM pg_indexes WHERE schemaname = $1 AND tablename = $2 AND indexname = $3)`
+
+	var exists bool
+	if err := querier.QueryRow(ctx, sql, db, table, numericIndexName(table, field)).Scan(&exists); err != nil {
+		return false, lazyerrors.Error(err)
+	}
+
+	return exists, nil
+}
+
+// numericIndexName returns the name of the numeric index for the given table and field.
+//
+// Unlike names returned by indexName and idIndexName, the name before hashing does not end with "_idx",
+// so it can't collide with the name of any user index (for example, "<field>_numeric").
+func numericIndexName(table, field string) string {
+	return formatCollectionName(table + "_" + field + "_numeric")
+}
+
+// numericFieldExpr returns SQL expression of the given top-level field value cast to bigint.
+// It is used both for creating numeric indexes and for using them in the WHERE clause.
+func numericFieldExpr(field string) string {
+	return `((_jsonb->>` + quoteString(field) + `)::bigint)`
+}
+
+// quoteString returns SQL string literal for the given string.
+func quoteString(s string) string {
+	return `'` + strings.ReplaceAll(s, `'`, `''`) + `'`
+}
+
+// Index represents index metadata stored in the settings table.
+type Index struct {
+	Name string
+	Key  *types.Document
+
+	// ExpireAfterSeconds is set only for TTL indexes.
+	ExpireAfterSeconds *int32
+
+	// Numeric is true for numeric indexes, see CreateNumericIndex.
+	Numeric bool
+
+	// Unique is true for unique indexes, see CreateIndex.
+	Unique bool
+
+	// Sparse is true for indexes that skip documents without any of the key fields, see CreateIndex.
+	Sparse bool
+
+	// PartialFilterExpression is set only for partial indexes.
+	// It is not used by PostgreSQL indexes, but determines whether the index could be used; see UsableIndexes.
+	PartialFilterExpression *types.Document
+}
+
+// TTLIndex represents TTL index metadata together with its database and collection.
+type TTLIndex struct {
+	DB         string
+	Collection string
+	Index
+}
+
+// CreateIndex stores index metadata in the settings table and, for single-field keys,
+// creates PostgreSQL expression index on the field value.
+// For unique indexes, PostgreSQL unique expression index on all key fields is created instead.
+// For sparse indexes, PostgreSQL index is partial and skips documents without any of the key fields.
+//
+// It returns ErrAlreadyExist if the collection already has an index with the same name,
+// ErrTableNotExist if the collection does not exist,
+// and (possibly wrapped) *DuplicateKeyError if existing documents violate the unique index.
+func CreateIndex(ctx context.Context, querier pgxtype.Querier, db, collection string, index *Index) error {
+	if err := CreateIndexMetadata(ctx, querier, db, collection, index); err != nil {
+		return err
+	}
+
+	if !index.Unique && index.Key.Len() != 1 {
+		return nil
+	}
+
+	table, err := getTableName(ctx, querier, db, collection)
+	if err != nil {
+		return lazyerrors.Error(err)
+	}
+
+	if index.Unique {
+		return createUniqueIndex(ctx, querier, db, table, index)
+	}
+
+	field := index.Key.Keys()[0]
+
+	// missing fields are sorted first, like in MongoDB, so the index could be used for ORDER BY;
+	// see prepareOrderBy
+	order := " NULLS FIRST"
+	if isDescending(must.NotFail(index.Key.Get(field))) {
+		order = " DESC NULLS LAST"
+	}
+
+	sql := `CREATE INDEX ` + pgx.Identifier{indexName(table, index.Name)}.Sanitize() +
+		` ON ` + pgx.Identifier{db, table}.Sanitize() + ` ((` + fieldExpr(field) + `)` + order + `)`
+	if index.Sparse {
+		sql += sparseWhere(index.Key)
+	}
+
+	if _, err = querier.Exec(ctx, sql); err != nil {
+		return lazyerrors.Error(err)
+	}
+
+	return nil
+}
+
+// createUniqueIndex creates PostgreSQL unique expression index for the given index metadata.
+//
+// Missing fields are indexed as null values, so, like in MongoDB, only one document
+// could have null or missing values of all key fields, unless the index is sparse.
+// Numerically equal values of different types do not conflict, unlike in MongoDB.
+func createUniqueIndex(ctx context.Context, querier pgxtype.Querier, db, table string, index *Index) error {
+	name := indexName(table, index.Name)
+
+	exprs := make([]string, index.Key.Len())
+	for i, field := range index.Key.Keys() {
+		exprs[i] = `(` + uniqueFieldExpr(field) + `)`
+	}
+
+	sql := `CREATE UNIQUE INDEX ` + pgx.Identifier{name}.Sanitize() +
+		` ON ` + pgx.Identifier{db, table}.Sanitize() + ` (` + strings.Join(exprs, `, `) + `)`
+	if index.Sparse {
+		sql += sparseWhere(index.Key)
+	}
+
+	if _, err := querier.Exec(ctx, sql); err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == pgerrcode.UniqueViolation && pgErr.ConstraintName == name {
+			return lazyerrors.Error(&DuplicateKeyError{Index: index.Name})
+		}
+
+		return lazyerrors.Error(err)
+	}
+
+	return nil
+}
+
+// uniqueFieldExpr returns SQL expression of the given (possibly dotted) field value
+// used by unique indexes; missing values are replaced with JSON null.
+func uniqueFieldExpr(field string) string {
+	return `COALESCE(` + fieldExpr(field) + `, 'null'::jsonb)`
+}
+
+// sparseWhere returns WHERE clause of PostgreSQL partial index that skips documents
+// without any of the given index key fields, like MongoDB sparse index.
+func sparseWhere(key *types.Document) string {
+	conds := make([]string, key.Len())
+	for i, field := range key.Keys() {
+		conds[i] = fieldExistsExpr(field)
+	}
+
+	return ` WHERE (` + strings.Join(conds, ` OR `) + `)`
+}
+
+// fieldExistsExpr returns SQL expression that is true if the given (possibly dotted) field is present.
+func fieldExistsExpr(field string) string {
+	parts := strings.Split(field, ".")
+
+	expr := `_jsonb`
+	for _, f := range parts[:len(parts)-1] {
+		expr += `->` + quoteString(f)
+	}
+
+	return `(` + expr + ` ? ` + quoteString(parts[len(parts)-1]) + `)`
+}
+
+// duplicateKeyError returns *DuplicateKeyError for the violation of the given PostgreSQL unique index
+// caused by the given document.
+// Violations of indexes other than unique indexes from the given list are reported as _id violations.
+func duplicateKeyError(table, constraint string, indexes []Index, doc *types.Document) *DuplicateKeyError {
+	id, _ := doc.Get("_id")
+
+	for _, index := range indexes {
+		if !index.Unique || indexName(table, index.Name) != constraint {
+			continue
+		}
+
+		key := must.NotFail(types.NewDocument())
+
+		for _, field := range index.Key.Keys() {
+			v, err := doc.GetByPath(types.NewPathFromString(field))
+			if err != nil {
+				v = types.Null
+			}
+
+			must.NoError(key.Set(field, v))
+		}
+
+		return &DuplicateKeyError{ID: id, Index: index.Name, Key: key}
+	}
+
+	return &DuplicateKeyError{ID: id, Index: "_id_", Key: must.NotFail(types.NewDocument("_id", id))}
+}
+
+// DropIndex removes index metadata from the settings table and drops PostgreSQL indexes created for it.
+//
+// It returns ErrIndexNotExist if the collection has no index with the given name,
+// and ErrTableNotExist if the collection does not exist.
+func DropIndex(ctx context.Context, querier pgxtype.Querier, db, collection, name string) error {
+	exists, err := CollectionExists(ctx, querier, db, collection)
+	if err != nil {
+		return lazyerrors.Error(err)
+	}
+
+	if !exists {
+		return ErrTableNotExist
+	}
+
+	settings, err := getSettingsTable(ctx, querier, db)
+	if err != nil {
+		return lazyerrors.Error(err)
+	}
+
+	indexes, err := settingsIndexes(settings, collection)
+	if err != nil {
+		return lazyerrors.Error(err)
+	}
+
+	i := slices.IndexFunc(indexes, func(index Index) bool { return index.Name == name })
+	if i == -1 {
+		return ErrIndexNotExist
+	}
+
+	dropped := indexes[i]
+	indexes = slices.Delete(indexes, i, i+1)
+
+	setSettingsIndexes(settings, collection, indexes)
+
+	if err = updateSettingsTable(ctx, querier, db, settings); err != nil {
+		return lazyerrors.Error(err)
+	}
+
+	table, err := getTableName(ctx, querier, db, collection)
+	if err != nil {
+		return lazyerrors.Error(err)
+	}
+
+	var names []string
+
+	switch {
+	case dropped.Numeric:
+		// numeric indexes are per-field, keep those still used by other indexes
+		for _, field := range dropped.Key.Keys() {
+			used := slices.IndexFunc(indexes, func(index Index) bool { return index.Numeric && index.Key.Has(field) })
+			if used == -1 {
+				names = append(names, numericIndexName(table, field))
+			}
+		}
+
+	case dropped.Unique, dropped.Key.Len() == 1:
+		names = append(names, indexName(table, dropped.Name))
+	}
+
+	for _, n := range names {
+		sql := `DROP INDEX IF EXISTS ` + pgx.Identifier{db, n}.Sanitize()
+		if _, err = querier.Exec(ctx, sql); err != nil {
+			return lazyerrors.Error(err)
+		}
+	}
+
+	return nil
+}
+
+// indexName returns the name of PostgreSQL index for the given table and index name.
+func indexName(table, name string) string {
+	return formatCollectionName(table + "_" + name + "_idx")
+}
+
+// fieldExpr returns SQL expression of the given (possibly dotted) field value.
+func fieldExpr(field string) string {
+	expr := `_jsonb`
+	for _, f := range strings.Split(field, ".") {
+		expr += `->` + quoteString(f)
+	}
+
+	return expr
+}
+
+// isDescending returns true if the index key value represents descending order.
+func isDescending(v any) bool {
+	switch v := v.(type) {
+	case float64:
+		return v < 0
+	case int32:
+		return v < 0
+	case int64:
+		return v < 0
+	default:
+		return false
+	}
+}
+
+// CreateIndexMetadata stores index metadata for the existing collection in the settings table.
+//
+// It returns ErrAlreadyExist if the collection already has an index with the same name,
+// and ErrTableNotExist if the collection does not exist.
+func CreateIndexMetadata(ctx context.Context, querier pgxtype.Querier, db, collection string, index *Index) error {
+	exists, err := CollectionExists(ctx, querier, db, collection)
+	if err != nil {
+		return lazyerrors.Error(err)
+	}
+
+	if !exists {
+		return ErrTableNotExist
+	}
+
+	settings, err := getSettingsTable(ctx, querier, db)
+	if err != nil {
+		return lazyerrors.Error(err)
+	}
+
+	indexes, err := settingsIndexes(settings, collection)
+	if err != nil {
+		return lazyerrors.Error(err)
+	}
+
+	for _, i := range indexes {
+		if i.Name == index.Name {
+			return ErrAlreadyExist
+		}
+	}
+
+	setSettingsIndexes(settings, collection, append(indexes, *index))
+
+	if err = updateSettingsTable(ctx, querier, db, settings); err != nil {
+		return lazyerrors.Error(err)
+	}
+
+	return nil
+}
+
+// Indexes returns metadata of the collection's indexes stored in the settings table.
+// If the database or collection does not exist, it returns no indexes and no error.
+func Indexes(ctx context.Context, querier pgxtype.Querier, db, collection string) ([]Index, error) {
+	exists, err := CollectionExists(ctx, querier, db, collection)
+	if err != nil || !exists {
+		return nil, err
+	}
+
+	settings, err := getSettingsTable(ctx, querier, db)
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	return settingsIndexes(settings, collection)
+}
+
+// TTLIndexes returns metadata of TTL indexes of all collections in all databases.
+func TTLIndexes(ctx context.Context, querier pgxtype.Querier) ([]TTLIndex, error) {
+	databases, err := Databases(ctx, querier)
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	var res []TTLIndex
+
+	for _, db := range databases {
+		collections, err := Collections(ctx, querier, db)
+		if err != nil {
+			return nil, lazyerrors.Error(err)
+		}
+
+		if len(collections) == 0 {
+			continue
+		}
+
+		settings, err := getSettingsTable(ctx, querier, db)
+		if err != nil {
+			return nil, lazyerrors.Error(err)
+		}
+
+		for _, collection := range collections {
+			indexes, err := settingsIndexes(settings, collection)
+			if err != nil {
+				return nil, lazyerrors.Error(err)
+			}
+
+			for _, index := range indexes {
+				if index.ExpireAfterSeconds != nil {
+					res = append(res, TTLIndex{DB: db, Collection: collection, Index: index})
+				}
+			}
+		}
+	}
+
+	return res, nil
+}
+
+// settingsIndexes returns metadata of the collection's indexes from the given settings document.
+//
+// Indexes are stored in the "indexes" document that maps collection names to arrays of index documents.
+// It is absent in settings of databases without indexes.
+func settingsIndexes(settings *types.Document, collection string) ([]Index, error) {
+	v, err := settings.Get("indexes")
+	if err != nil {
+		return nil, nil
+	}
+
+	all, ok := v.(*types.Document)
+	if !ok {
+		return nil, lazyerrors.Errorf("invalid settings document: indexes is %T", v)
+	}
+
+	v, err = all.Get(collection)
+	if err != nil {
+		return nil, nil
+	}
+
+	arr, ok := v.(*types.Array)
+	if !ok {
+		return nil, lazyerrors.Errorf("invalid settings document: indexes of %q is %T", collection, v)
+	}
+
+	res := make([]Index, arr.Len())
+
+	for i := 0; i < arr.Len(); i++ {
+		doc, ok := must.NotFail(arr.Get(i)).(*types.Document)
+		if !ok {
+			return nil, lazyerrors.Errorf("invalid settings document: index of %q is not a document", collection)
+		}
+
+		if res[i].Name, ok = must.NotFail(doc.Get("name")).(string); !ok {
+			return nil, lazyerrors.Errorf("invalid settings document: index name of %q is not a string", collection)
+		}
+
+		if res[i].Key, ok = must.NotFail(doc.Get("key")).(*types.Document); !ok {
+			return nil, lazyerrors.Errorf("invalid settings document: index key of %q is not a document", collection)
+		}
+
+		if v, err := doc.Get("expireAfterSeconds"); err == nil {
+			expireAfterSeconds, ok := v.(int32)
+			if !ok {
+				return nil, lazyerrors.Errorf("invalid settings document: expireAfterSeconds of %q is %T", collection, v)
+			}
+
+			res[i].ExpireAfterSeconds = &expireAfterSeconds
+		}
+
+		if v, err := doc.Get("numeric"); err == nil {
+			if res[i].Numeric, ok = v.(bool); !ok {
+				return nil, lazyerrors.Errorf("invalid settings document: numeric of %q is %T", collection, v)
+			}
+		}
+
+		if v, err := doc.Get("unique"); err == nil {
+			if res[i].Unique, ok = v.(bool); !ok {
+				return nil, lazyerrors.Errorf("invalid settings document: unique of %q is %T", collection, v)
+			}
+		}
+
+		if v, err := doc.Get("sparse"); err == nil {
+			if res[i].Sparse, ok = v.(bool); !ok {
+				return nil, lazyerrors.Errorf("invalid settings document: sparse of %q is %T", collection, v)
+			}
+		}
+
+		if v, err := doc.Get("partialFilterExpression"); err == nil {
+			if res[i].PartialFilterExpression, ok = v.(*types.Document); !ok {
+				return nil, lazyerrors.Errorf("invalid settings document: partialFilterExpression of %q is %T", collection, v)
+			}
+		}
+	}
+
+	return res, nil
+}
+
+// setSettingsIndexes replaces metadata of the collection's indexes in the given settings document.
+// Collections without indexes are removed from the "indexes" document.
+func setSettingsIndexes(settings *types.Document, collection string, indexes []Index) {
+	var all *types.Document
+	if v, err := settings.Get("indexes"); err == nil {
+		all, _ = v.(*types.Document)
+	}
+
+	if all == nil {
+		if len(indexes) == 0 {
+			return
+		}
+
+		all = must.NotFail(types.NewDocument())
+	}
+
+	if len(indexes) == 0 {
+		all.Remove(collection)
+		must.NoError(settings.Set("indexes", all))
+
+		return
+	}
+
+	arr := types.MakeArray(len(indexes))
+
+	for _, index := range indexes {
+		doc := must.NotFail(types.NewDocument("name", index.Name, "key", index.Key))
+		if index.ExpireAfterSeconds != nil {
+			must.NoError(doc.Set("expireAfterSeconds", *index.ExpireAfterSeconds))
+		}
+
+		if index.Numeric {
+			must.NoError(doc.Set("numeric", true))
+		}
+
+		if index.Unique {
+			must.NoError(doc.Set("unique", true))
+		}
+
+		if index.Sparse {
+			must.NoError(doc.Set("sparse", true))
+		}
+
+		if index.PartialFilterExpression != nil {
+			must.NoError(doc.Set("partialFilterExpression", index.PartialFilterExpression))
+		}
+
+		must.NoError(arr.Append(doc))
+	}
+
+	must.NoError(all.Set(collection, arr))
+	must.NoError(settings.Set("indexes", all))
+}
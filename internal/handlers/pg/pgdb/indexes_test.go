@@ -0,0 +1,462 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pgdb
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+	"github.com/FerretDB/FerretDB/internal/util/testutil"
+)
+
+func TestCreateNumericIndex(t *testing.T) {
+	t.Parallel()
+
+	ctx := testutil.Ctx(t)
+
+	pool := getPool(ctx, t, zaptest.NewLogger(t))
+	dbName := testutil.DatabaseName(t)
+	collectionName := testutil.CollectionName(t)
+
+	t.Cleanup(func() {
+		pool.DropDatabase(ctx, dbName)
+	})
+
+	pool.DropDatabase(ctx, dbName)
+	require.NoError(t, CreateDatabase(ctx, pool, dbName))
+
+	for i, age := range []any{int32(10), "20", int32(30), types.Null} {
+		doc := must.NotFail(types.NewDocument("_id", int32(i), "age", age))
+		require.NoError(t, InsertDocument(ctx, pool, dbName, collectionName, doc))
+	}
+
+	require.NoError(t, InsertDocument(ctx, pool, dbName, collectionName, must.NotFail(types.NewDocument("_id", int32(4)))))
+
+	require.NoError(t, CreateNumericIndex(ctx, pool, dbName, collectionName, "age"))
+
+	// creating the same index again is a no-op
+	require.NoError(t, CreateNumericIndex(ctx, pool, dbName, collectionName, "age"))
+
+	filter := must.NotFail(types.NewDocument("age", must.NotFail(types.NewDocument("$gt", int32(15), "$lte", 30.0))))
+
+	t.Run("Explain", func(t *testing.T) {
+		t.Parallel()
+
+		tx, err := pool.Begin(ctx)
+		require.NoError(t, err)
+
+		defer tx.Rollback(ctx)
+
+		// the table is too small for the planner to prefer the index otherwise
+		_, err = tx.Exec(ctx, `SET LOCAL enable_seqscan = off`)
+		require.NoError(t, err)
+
+		table, err := getTableName(ctx, tx, dbName, collectionName)
+		require.NoError(t, err)
+
+		sp := SQLParam{DB: dbName, Collection: collectionName, Filter: filter, Explain: true}
+		q, args, err := buildQuery(ctx, tx, &sp)
+		require.NoError(t, err)
+		assert.Contains(t, q, `WHERE ((_jsonb->>'age')::bigint) > $1 AND ((_jsonb->>'age')::bigint) <= $2`)
+		assert.Equal(t, []any{int64(15), int64(30)}, args)
+
+		var plan string
+		require.NoError(t, tx.QueryRow(ctx, q, args...).Scan(&plan))
+		assert.Contains(t, plan, numericIndexName(table, "age"))
+	})
+
+	t.Run("Results", func(t *testing.T) {
+		t.Parallel()
+
+		sp := SQLParam{DB: dbName, Collection: collectionName, Filter: filter}
+		fetchedChan, closeFetch, err := pool.QueryDocuments(ctx, pool, sp)
+		require.NoError(t, err)
+		defer closeFetch()
+
+		var ids []any
+		for fetched := range fetchedChan {
+			require.NoError(t, fetched.Err)
+
+			for _, doc := range fetched.Docs {
+				ids = append(ids, must.NotFail(doc.Get("_id")))
+			}
+		}
+
+		// the string is selected by PostgreSQL, but it is filtered out in memory by handlers
+		assert.ElementsMatch(t, []any{int32(1), int32(2)}, ids)
+	})
+
+	t.Run("NoIndex", func(t *testing.T) {
+		t.Parallel()
+
+		sp := SQLParam{
+			DB:         dbName,
+			Collection: collectionName,
+			Filter:     must.NotFail(types.NewDocument("v", must.NotFail(types.NewDocument("$gt", int32(15))))),
+		}
+		q, args, err := buildQuery(ctx, pool, &sp)
+		require.NoError(t, err)
+		assert.NotContains(t, q, "WHERE")
+		assert.Empty(t, args)
+	})
+
+	t.Run("InvalidValue", func(t *testing.T) {
+		t.Parallel()
+
+		collectionName := collectionName + "_invalid"
+		doc := must.NotFail(types.NewDocument("_id", int32(1), "age", "foo"))
+		require.NoError(t, InsertDocument(ctx, pool, dbName, collectionName, doc))
+
+		err := CreateNumericIndex(ctx, pool, dbName, collectionName, "age")
+		require.ErrorIs(t, err, ErrIndexInvalidValue)
+	})
+}
+
+func TestCreateIndex(t *testing.T) {
+	t.Parallel()
+
+	ctx := testutil.Ctx(t)
+
+	pool := getPool(ctx, t, zaptest.NewLogger(t))
+	dbName := testutil.DatabaseName(t)
+	collectionName := testutil.CollectionName(t)
+
+	t.Cleanup(func() {
+		pool.DropDatabase(ctx, dbName)
+	})
+
+	pool.DropDatabase(ctx, dbName)
+	require.NoError(t, CreateDatabase(ctx, pool, dbName))
+	require.NoError(t, CreateCollection(ctx, pool, dbName, collectionName))
+
+	index := Index{
+		Name: "v.foo_-1",
+		Key:  must.NotFail(types.NewDocument("v.foo", int32(-1))),
+	}
+	require.NoError(t, CreateIndex(ctx, pool, dbName, collectionName, &index))
+
+	err := CreateIndex(ctx, pool, dbName, collectionName, &index)
+	require.ErrorIs(t, err, ErrAlreadyExist)
+
+	table, err := getTableName(ctx, pool, dbName, collectionName)
+	require.NoError(t, err)
+
+	var def string
+	sql := `SELECT indexdef FROM pg_indexes WHERE schemaname = $1 AND tablename = $2 AND indexname = $3`
+	require.NoError(t, pool.QueryRow(ctx, sql, dbName, table, indexName(table, index.Name)).Scan(&def))
+	assert.Contains(t, def, `((_jsonb -> 'v'::text) -> 'foo'::text) DESC NULLS LAST`)
+
+	t.Run("Rename", func(t *testing.T) {
+		toCollection := collectionName + "_renamed"
+		require.NoError(t, RenameCollection(ctx, pool, dbName, collectionName, dbName, toCollection))
+
+		toTable, err := getTableName(ctx, pool, dbName, toCollection)
+		require.NoError(t, err)
+
+		var exists bool
+		sql := `SELECT EXISTS(SELECT 1 FROM pg_indexes WHERE schemaname = $1 AND tablename = $2 AND indexname = $3)`
+		require.NoError(t, pool.QueryRow(ctx, sql, dbName, toTable, indexName(toTable, index.Name)).Scan(&exists))
+		assert.True(t, exists)
+	})
+}
+
+func TestCreateIndexNumericName(t *testing.T) {
+	t.Parallel()
+
+	ctx := testutil.Ctx(t)
+
+	pool := getPool(ctx, t, zaptest.NewLogger(t))
+	dbName := testutil.DatabaseName(t)
+	collectionName := testutil.CollectionName(t)
+
+	t.Cleanup(func() {
+		pool.DropDatabase(ctx, dbName)
+	})
+
+	pool.DropDatabase(ctx, dbName)
+	require.NoError(t, CreateDatabase(ctx, pool, dbName))
+	require.NoError(t, CreateCollection(ctx, pool, dbName, collectionName))
+
+	table, err := getTableName(ctx, pool, dbName, collectionName)
+	require.NoError(t, err)
+
+	assert.NotEqual(t, indexName(table, "v_numeric"), numericIndexName(table, "v"))
+
+	// user index with the name that previously collided with the numeric index
+	index := Index{
+		Name: "v_numeric",
+		Key:  must.NotFail(types.NewDocument("w", int32(1))),
+	}
+	require.NoError(t, CreateIndex(ctx, pool, dbName, collectionName, &index))
+
+	exists, err := numericIndexExists(ctx, pool, dbName, table, "v")
+	require.NoError(t, err)
+	assert.False(t, exists)
+
+	require.NoError(t, CreateNumericIndex(ctx, pool, dbName, collectionName, "v"))
+
+	exists, err = numericIndexExists(ctx, pool, dbName, table, "v")
+	require.NoError(t, err)
+	assert.True(t, exists)
+}
+
+func TestCreateIndexUnique(t *testing.T) {
+	t.Parallel()
+
+	ctx := testutil.Ctx(t)
+
+	pool := getPool(ctx, t, zaptest.NewLogger(t))
+	dbName := testutil.DatabaseName(t)
+	collectionName := testutil.CollectionName(t)
+
+	t.Cleanup(func() {
+		pool.DropDatabase(ctx, dbName)
+	})
+
+	pool.DropDatabase(ctx, dbName)
+	require.NoError(t, CreateDatabase(ctx, pool, dbName))
+	require.NoError(t, CreateCollection(ctx, pool, dbName, collectionName))
+
+	index := Index{
+		Name:   "v_1_w_1",
+		Key:    must.NotFail(types.NewDocument("v", int32(1), "w", int32(1))),
+		Unique: true,
+	}
+	require.NoError(t, CreateIndex(ctx, pool, dbName, collectionName, &index))
+
+	doc := must.NotFail(types.NewDocument("_id", int32(1), "v", int32(1), "w", "a"))
+	require.NoError(t, InsertDocument(ctx, pool, dbName, collectionName, doc))
+
+	doc = must.NotFail(types.NewDocument("_id", int32(2), "v", int32(1), "w", "b"))
+	require.NoError(t, InsertDocument(ctx, pool, dbName, collectionName, doc))
+
+	doc = must.NotFail(types.NewDocument("_id", int32(3), "v", int32(1), "w", "a"))
+	err := InsertDocument(ctx, pool, dbName, collectionName, doc)
+	require.ErrorIs(t, err, ErrDuplicateKey)
+
+	var dupErr *DuplicateKeyError
+	require.True(t, errors.As(err, &dupErr))
+	assert.Equal(t, int32(3), dupErr.ID)
+	assert.Equal(t, "v_1_w_1", dupErr.Index)
+	assert.Equal(t, must.NotFail(types.NewDocument("v", int32(1), "w", "a")), dupErr.Key)
+
+	// _id violations are still reported for the _id index
+	doc = must.NotFail(types.NewDocument("_id", int32(1), "v", int32(2)))
+	err = InsertDocument(ctx, pool, dbName, collectionName, doc)
+	require.True(t, errors.As(err, &dupErr))
+	assert.Equal(t, "_id_", dupErr.Index)
+	assert.Equal(t, must.NotFail(types.NewDocument("_id", int32(1))), dupErr.Key)
+
+	indexes, err := Indexes(ctx, pool, dbName, collectionName)
+	require.NoError(t, err)
+	require.Len(t, indexes, 1)
+	assert.True(t, indexes[0].Unique)
+
+	require.NoError(t, DropIndex(ctx, pool, dbName, collectionName, "v_1_w_1"))
+
+	doc = must.NotFail(types.NewDocument("_id", int32(3), "v", int32(1), "w", "a"))
+	require.NoError(t, InsertDocument(ctx, pool, dbName, collectionName, doc))
+
+	// existing duplicates prevent index creation
+	err = CreateIndex(ctx, pool, dbName, collectionName, &index)
+	require.True(t, errors.As(err, &dupErr))
+	assert.Equal(t, "v_1_w_1", dupErr.Index)
+	assert.Nil(t, dupErr.Key)
+}
+
+func TestCreateIndexUniqueSparse(t *testing.T) {
+	t.Parallel()
+
+	ctx := testutil.Ctx(t)
+
+	pool := getPool(ctx, t, zaptest.NewLogger(t))
+	dbName := testutil.DatabaseName(t)
+	collectionName := testutil.CollectionName(t)
+
+	t.Cleanup(func() {
+		pool.DropDatabase(ctx, dbName)
+	})
+
+	pool.DropDatabase(ctx, dbName)
+	require.NoError(t, CreateDatabase(ctx, pool, dbName))
+	require.NoError(t, CreateCollection(ctx, pool, dbName, collectionName))
+
+	index := Index{
+		Name:   "v.w_1",
+		Key:    must.NotFail(types.NewDocument("v.w", int32(1))),
+		Unique: true,
+		Sparse: true,
+	}
+	require.NoError(t, CreateIndex(ctx, pool, dbName, collectionName, &index))
+
+	// documents without the field are not indexed and do not conflict
+	for i := int32(1); i <= 3; i++ {
+		doc := must.NotFail(types.NewDocument("_id", i))
+		require.NoError(t, InsertDocument(ctx, pool, dbName, collectionName, doc))
+	}
+
+	// null values are indexed
+	doc := must.NotFail(types.NewDocument("_id", int32(4), "v", must.NotFail(types.NewDocument("w", types.Null))))
+	require.NoError(t, InsertDocument(ctx, pool, dbName, collectionName, doc))
+
+	doc = must.NotFail(types.NewDocument("_id", int32(5), "v", must.NotFail(types.NewDocument("w", types.Null))))
+	err := InsertDocument(ctx, pool, dbName, collectionName, doc)
+
+	var dupErr *DuplicateKeyError
+	require.True(t, errors.As(err, &dupErr))
+	assert.Equal(t, "v.w_1", dupErr.Index)
+	assert.Equal(t, must.NotFail(types.NewDocument("v.w", types.Null)), dupErr.Key)
+
+	indexes, err := Indexes(ctx, pool, dbName, collectionName)
+	require.NoError(t, err)
+	require.Len(t, indexes, 1)
+	assert.True(t, indexes[0].Sparse)
+}
+
+func TestDropIndex(t *testing.T) {
+	t.Parallel()
+
+	ctx := testutil.Ctx(t)
+
+	pool := getPool(ctx, t, zaptest.NewLogger(t))
+	dbName := testutil.DatabaseName(t)
+	collectionName := testutil.CollectionName(t)
+
+	t.Cleanup(func() {
+		pool.DropDatabase(ctx, dbName)
+	})
+
+	pool.DropDatabase(ctx, dbName)
+	require.NoError(t, CreateDatabase(ctx, pool, dbName))
+
+	err := DropIndex(ctx, pool, dbName, collectionName, "v_1")
+	require.ErrorIs(t, err, ErrTableNotExist)
+
+	require.NoError(t, CreateCollection(ctx, pool, dbName, collectionName))
+
+	index := Index{Name: "v_1", Key: must.NotFail(types.NewDocument("v", int32(1)))}
+	require.NoError(t, CreateIndex(ctx, pool, dbName, collectionName, &index))
+
+	require.NoError(t, DropIndex(ctx, pool, dbName, collectionName, "v_1"))
+
+	err = DropIndex(ctx, pool, dbName, collectionName, "v_1")
+	require.ErrorIs(t, err, ErrIndexNotExist)
+
+	indexes, err := Indexes(ctx, pool, dbName, collectionName)
+	require.NoError(t, err)
+	assert.Empty(t, indexes)
+
+	table, err := getTableName(ctx, pool, dbName, collectionName)
+	require.NoError(t, err)
+
+	var exists bool
+	sql := `SELECT EXISTS(SELECT 1 FROM pg_indexes WHERE schemaname = $1 AND tablename = $2 AND indexname = $3)`
+	require.NoError(t, pool.QueryRow(ctx, sql, dbName, table, indexName(table, index.Name)).Scan(&exists))
+	assert.False(t, exists)
+}
+
+func TestIndexMetadata(t *testing.T) {
+	t.Parallel()
+
+	ctx := testutil.Ctx(t)
+
+	pool := getPool(ctx, t, zaptest.NewLogger(t))
+	dbName := testutil.DatabaseName(t)
+	collectionName := testutil.CollectionName(t)
+
+	t.Cleanup(func() {
+		pool.DropDatabase(ctx, dbName)
+	})
+
+	pool.DropDatabase(ctx, dbName)
+	require.NoError(t, CreateDatabase(ctx, pool, dbName))
+
+	expireAfterSeconds := int32(10)
+	ttl := Index{
+		Name:               "ttl",
+		Key:                must.NotFail(types.NewDocument("createdAt", int32(1))),
+		ExpireAfterSeconds: &expireAfterSeconds,
+	}
+	plain := Index{
+		Name: "plain",
+		Key:  must.NotFail(types.NewDocument("v", int32(-1))),
+	}
+	partial := Index{
+		Name: "partial",
+		Key:  must.NotFail(types.NewDocument("w", int32(1))),
+		PartialFilterExpression: must.NotFail(types.NewDocument(
+			"w", must.NotFail(types.NewDocument("$gt", int32(10))),
+		)),
+	}
+
+	err := CreateIndexMetadata(ctx, pool, dbName, collectionName, &ttl)
+	require.ErrorIs(t, err, ErrTableNotExist)
+
+	require.NoError(t, CreateCollection(ctx, pool, dbName, collectionName))
+	require.NoError(t, CreateIndexMetadata(ctx, pool, dbName, collectionName, &ttl))
+	require.NoError(t, CreateIndexMetadata(ctx, pool, dbName, collectionName, &plain))
+	require.NoError(t, CreateIndexMetadata(ctx, pool, dbName, collectionName, &partial))
+
+	err = CreateIndexMetadata(ctx, pool, dbName, collectionName, &plain)
+	require.ErrorIs(t, err, ErrAlreadyExist)
+
+	indexes, err := Indexes(ctx, pool, dbName, collectionName)
+	require.NoError(t, err)
+	assert.Equal(t, []Index{ttl, plain, partial}, indexes)
+
+	ttlIndexes, err := TTLIndexes(ctx, pool)
+	require.NoError(t, err)
+	assert.Contains(t, ttlIndexes, TTLIndex{DB: dbName, Collection: collectionName, Index: ttl})
+
+	t.Run("Rename", func(t *testing.T) {
+		toCollection := collectionName + "_renamed"
+		require.NoError(t, RenameCollection(ctx, pool, dbName, collectionName, dbName, toCollection))
+
+		indexes, err := Indexes(ctx, pool, dbName, toCollection)
+		require.NoError(t, err)
+		assert.Equal(t, []Index{ttl, plain, partial}, indexes)
+
+		indexes, err = Indexes(ctx, pool, dbName, collectionName)
+		require.NoError(t, err)
+		assert.Empty(t, indexes)
+
+		// index metadata is removed together with the collection
+		require.NoError(t, DropCollection(ctx, pool, dbName, toCollection))
+		require.NoError(t, CreateCollection(ctx, pool, dbName, toCollection))
+
+		indexes, err = Indexes(ctx, pool, dbName, toCollection)
+		require.NoError(t, err)
+		assert.Empty(t, indexes)
+	})
+}
+
+func TestNumericIndexFieldRe(t *testing.T) {
+	t.Parallel()
+
+	// as returned by pg_indexes.indexdef for numericFieldExpr
+	def := `CREATE INDEX "v_1_numeric_ab12cd34" ON db."coll_ab12cd34" USING btree ((((_jsonb ->> 'it''s'::text))::bigint))`
+
+	m := numericIndexFieldRe.FindStringSubmatch(def)
+	require.NotNil(t, m)
+	assert.Equal(t, `it''s`, m[1])
+
+	assert.Nil(t, numericIndexFieldRe.FindStringSubmatch(`CREATE UNIQUE INDEX "id_idx" ON db."coll" USING btree (((_jsonb -> '_id'::text)))`))
+}
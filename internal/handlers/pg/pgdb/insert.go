@@ -18,6 +18,8 @@ import (
 	"context"
 	"errors"
 
+	"github.com/jackc/pgconn"
+	"github.com/jackc/pgerrcode"
 	"github.com/jackc/pgtype/pgxtype"
 	"github.com/jackc/pgx/v4"
 
@@ -29,6 +31,9 @@ import (
 
 // InsertDocument inserts a document into FerretDB database and collection.
 // If database or collection does not exist, it will be created.
+//
+// If a document with the same _id or unique index key already exists, it returns (possibly wrapped) *DuplicateKeyError.
+// Numerically equal _id values of different types are not considered the same, unlike in MongoDB.
 func InsertDocument(ctx context.Context, querier pgxtype.Querier, db, collection string, doc *types.Document) error {
 	exists, err := CollectionExists(ctx, querier, db, collection)
 	if err != nil {
@@ -50,10 +55,21 @@ func InsertDocument(ctx context.Context, querier pgxtype.Querier, db, collection
 		return lazyerrors.Error(err)
 	}
 
+	// indexes are loaded beforehand, the transaction can't be used after the unique violation
+	indexes, err := Indexes(ctx, querier, db, collection)
+	if err != nil {
+		return lazyerrors.Error(err)
+	}
+
 	sql := `INSERT INTO ` + pgx.Identifier{db, table}.Sanitize() +
 		` (_jsonb) VALUES ($1)`
 
 	if _, err = querier.Exec(ctx, sql, must.NotFail(fjson.Marshal(doc))); err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == pgerrcode.UniqueViolation {
+			return lazyerrors.Error(duplicateKeyError(table, pgErr.ConstraintName, indexes, doc))
+		}
+
 		return lazyerrors.Error(err)
 	}
 
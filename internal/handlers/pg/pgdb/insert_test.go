@@ -0,0 +1,108 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pgdb
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/jackc/pgx/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+	"github.com/FerretDB/FerretDB/internal/util/testutil"
+)
+
+func TestInsertDocumentDuplicateKey(t *testing.T) {
+	t.Parallel()
+
+	ctx := testutil.Ctx(t)
+
+	pool := getPool(ctx, t, zaptest.NewLogger(t))
+	dbName := testutil.DatabaseName(t)
+	collectionName := testutil.CollectionName(t)
+
+	t.Cleanup(func() {
+		pool.DropDatabase(ctx, dbName)
+	})
+
+	pool.DropDatabase(ctx, dbName)
+
+	doc := must.NotFail(types.NewDocument("_id", "dup", "v", int32(1)))
+	require.NoError(t, InsertDocument(ctx, pool, dbName, collectionName, doc))
+
+	// documents without _id do not conflict
+	require.NoError(t, InsertDocument(ctx, pool, dbName, collectionName, must.NotFail(types.NewDocument("v", int32(2)))))
+	require.NoError(t, InsertDocument(ctx, pool, dbName, collectionName, must.NotFail(types.NewDocument("v", int32(3)))))
+
+	doc = must.NotFail(types.NewDocument("_id", "dup", "v", int32(4)))
+	err := InsertDocument(ctx, pool, dbName, collectionName, doc)
+	require.ErrorIs(t, err, ErrDuplicateKey)
+
+	var dupErr *DuplicateKeyError
+	require.True(t, errors.As(err, &dupErr))
+	assert.Equal(t, "dup", dupErr.ID)
+	assert.Contains(t, err.Error(), `"dup"`)
+}
+
+func TestInsertDocumentLarge(t *testing.T) {
+	t.Parallel()
+
+	ctx := testutil.Ctx(t)
+
+	pool := getPool(ctx, t, zaptest.NewLogger(t))
+	dbName := testutil.DatabaseName(t)
+	collectionName := testutil.CollectionName(t)
+
+	t.Cleanup(func() {
+		pool.DropDatabase(ctx, dbName)
+	})
+
+	pool.DropDatabase(ctx, dbName)
+
+	// much larger than the TOAST threshold (about 2 KB)
+	doc := must.NotFail(types.NewDocument(
+		"_id", "large",
+		"v", strings.Repeat("FerretDB ", 1024*1024),
+		"w", must.NotFail(types.NewArray(strings.Repeat("a", 100_000), int32(42))),
+	))
+	require.NoError(t, InsertDocument(ctx, pool, dbName, collectionName, doc))
+
+	table, err := getTableName(ctx, pool, dbName, collectionName)
+	require.NoError(t, err)
+
+	var storage string
+	sql := `SELECT attstorage FROM pg_attribute WHERE attrelid = $1::regclass AND attname = '_jsonb'`
+	err = pool.QueryRow(ctx, sql, pgx.Identifier{dbName, table}.Sanitize()).Scan(&storage)
+	require.NoError(t, err)
+	assert.Equal(t, "x", storage) // extended
+
+	fetchedChan, closeFetch, err := pool.QueryDocuments(ctx, pool, SQLParam{DB: dbName, Collection: collectionName})
+	require.NoError(t, err)
+	defer closeFetch()
+
+	var actual []*types.Document
+	for fetched := range fetchedChan {
+		require.NoError(t, fetched.Err)
+		actual = append(actual, fetched.Docs...)
+	}
+
+	require.Len(t, actual, 1)
+	assert.Equal(t, doc, actual[0])
+}
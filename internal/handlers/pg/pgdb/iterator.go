@@ -0,0 +1,105 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pgdb
+
+import (
+	"context"
+	"sync"
+
+	"github.com/FerretDB/FerretDB/internal/types"
+)
+
+// QueryIterator returns documents fetched by QueryDocuments lazily.
+//
+// Unlike QueryDocuments, it is not bound to a single request:
+// it runs the query outside of a transaction and keeps the connection until all documents are fetched
+// or it is closed, so it could be stored in a cursor and used by getMore.
+type QueryIterator struct {
+	fetched    <-chan FetchedDocs
+	closeFetch func()
+
+	// mu protects buf and serializes Next calls.
+	mu  sync.Mutex
+	buf []*types.Document
+}
+
+// NewQueryIterator runs the query for the given parameters and returns an iterator for its documents.
+//
+// The given context is used only for the start of the query;
+// fetching continues after it is canceled, until the iterator is closed.
+// The caller should always call Close.
+//
+// If the collection doesn't exist, the iterator returns no documents.
+func (pgPool *Pool) NewQueryIterator(ctx context.Context, sp SQLParam) (*QueryIterator, error) {
+	// the query should outlive the request that started it
+	fetched, closeFetch, err := pgPool.QueryDocuments(context.Background(), pgPool, sp)
+	if err != nil {
+		closeFetch()
+		return nil, err
+	}
+
+	if err = ctx.Err(); err != nil {
+		closeFetch()
+		return nil, err
+	}
+
+	return &QueryIterator{
+		fetched:    fetched,
+		closeFetch: closeFetch,
+	}, nil
+}
+
+// Next returns up to n next documents.
+// It returns fewer documents only if there are no more of them or the iterator is closed.
+func (it *QueryIterator) Next(ctx context.Context, n int) ([]*types.Document, error) {
+	it.mu.Lock()
+	defer it.mu.Unlock()
+
+	for len(it.buf) < n {
+		var item FetchedDocs
+		var ok bool
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case item, ok = <-it.fetched:
+		}
+
+		if !ok {
+			break
+		}
+
+		if item.Err != nil {
+			return nil, item.Err
+		}
+
+		it.buf = append(it.buf, item.Docs...)
+	}
+
+	if n > len(it.buf) {
+		n = len(it.buf)
+	}
+
+	res := it.buf[:n:n]
+	it.buf = it.buf[n:]
+
+	return res, nil
+}
+
+// Close stops fetching and releases the connection.
+// It is safe to call it several times and concurrently with Next.
+func (it *QueryIterator) Close() {
+	it.closeFetch()
+}
@@ -0,0 +1,114 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pgdb
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+	"github.com/FerretDB/FerretDB/internal/util/testutil"
+)
+
+func TestQueryIterator(t *testing.T) {
+	t.Parallel()
+
+	ctx := testutil.Ctx(t)
+
+	pool := getPool(ctx, t, zaptest.NewLogger(t))
+	dbName := testutil.DatabaseName(t)
+	collectionName := testutil.CollectionName(t)
+
+	t.Cleanup(func() {
+		pool.DropDatabase(ctx, dbName)
+	})
+
+	pool.DropDatabase(ctx, dbName)
+	require.NoError(t, CreateDatabase(ctx, pool, dbName))
+
+	const n = FetchedChannelBufSize * FetchedSliceCapacity * 3
+	for i := 0; i < n; i++ {
+		require.NoError(t, InsertDocument(ctx, pool, dbName, collectionName, must.NotFail(types.NewDocument("_id", int32(i)))))
+	}
+
+	sp := SQLParam{DB: dbName, Collection: collectionName}
+
+	t.Run("All", func(t *testing.T) {
+		// the iterator is not bound to the context of the request that created it
+		reqCtx, cancel := context.WithCancel(ctx)
+		iter, err := pool.NewQueryIterator(reqCtx, sp)
+		require.NoError(t, err)
+		cancel()
+
+		defer iter.Close()
+
+		var ids []any
+		for {
+			docs, err := iter.Next(ctx, 5)
+			require.NoError(t, err)
+
+			for _, doc := range docs {
+				ids = append(ids, must.NotFail(doc.Get("_id")))
+			}
+
+			if len(docs) < 5 {
+				break
+			}
+		}
+
+		assert.Len(t, ids, n)
+	})
+
+	t.Run("Close", func(t *testing.T) {
+		require.Eventually(t, func() bool {
+			return pool.Stat().AcquiredConns() == 0
+		}, 5*time.Second, 10*time.Millisecond)
+
+		iter, err := pool.NewQueryIterator(ctx, sp)
+		require.NoError(t, err)
+
+		docs, err := iter.Next(ctx, 1)
+		require.NoError(t, err)
+		require.Len(t, docs, 1)
+
+		// the connection is held until the iterator is closed
+		assert.Equal(t, int32(1), pool.Stat().AcquiredConns())
+
+		iter.Close()
+		require.Eventually(t, func() bool {
+			return pool.Stat().AcquiredConns() == 0
+		}, 5*time.Second, 10*time.Millisecond)
+
+		// closing again is a no-op
+		iter.Close()
+	})
+
+	t.Run("NonExistent", func(t *testing.T) {
+		iter, err := pool.NewQueryIterator(ctx, SQLParam{DB: dbName, Collection: "non-existent"})
+		require.NoError(t, err)
+
+		defer iter.Close()
+
+		docs, err := iter.Next(ctx, 1)
+		require.NoError(t, err)
+		assert.Empty(t, docs)
+	})
+}
@@ -15,7 +15,12 @@
 // Package pgdb provides PostgreSQL connection utilities.
 package pgdb
 
-import "fmt"
+import (
+	"fmt"
+
+	"github.com/FerretDB/FerretDB/internal/fjson"
+	"github.com/FerretDB/FerretDB/internal/types"
+)
 
 // Errors are wrapped with lazyerrors.Error,
 // so the caller needs to use errors.Is to check the error,
@@ -33,6 +38,112 @@ var (
 	// ErrInvalidTableName indicates that a schema or table didn't passed name checks.
 	ErrInvalidTableName = fmt.Errorf("invalid table name")
 
+	// ErrReservedName indicates that a table name starts with the reserved prefix.
+	ErrReservedName = fmt.Errorf("reserved table name")
+
 	// ErrInvalidDatabaseName indicates that a database name didn't passed checks.
 	ErrInvalidDatabaseName = fmt.Errorf("invalid database name")
+
+	// ErrDuplicateKey indicates that a document with the same _id or unique index key already exists.
+	// The actual error is *DuplicateKeyError.
+	ErrDuplicateKey = fmt.Errorf("duplicate key")
+
+	// ErrIndexNotExist indicates that there is no such index.
+	ErrIndexNotExist = fmt.Errorf("index does not exist")
 )
+
+// DuplicateKeyError is returned when a document with the same _id or unique index key already exists.
+type DuplicateKeyError struct {
+	// ID is the _id value of the document that caused the violation.
+	ID any
+
+	// Index is the name of the violated index; it is "_id_" for the _id index.
+	Index string
+
+	// Key contains the conflicting values of the index key fields.
+	// It is nil if the violation was caused by existing documents during index creation.
+	Key *types.Document
+}
+
+// Error implements error interface.
+func (e *DuplicateKeyError) Error() string {
+	if e.Key == nil {
+		return fmt.Sprintf("%s: index %s", ErrDuplicateKey, e.Index)
+	}
+
+	key, err := fjson.Marshal(e.Key)
+	if err != nil {
+		return fmt.Sprintf("%s: index %s: %v", ErrDuplicateKey, e.Index, e.Key)
+	}
+
+	return fmt.Sprintf("%s: index %s: %s", ErrDuplicateKey, e.Index, key)
+}
+
+// Unwrap returns ErrDuplicateKey.
+func (e *DuplicateKeyError) Unwrap() error {
+	return ErrDuplicateKey
+}
+
+// InvalidCollectionNameReason describes why a collection name didn't pass checks.
+type InvalidCollectionNameReason int
+
+// Collection name check failure reasons.
+const (
+	// CollectionNameEmpty indicates that the collection name is empty.
+	CollectionNameEmpty InvalidCollectionNameReason = iota + 1
+
+	// CollectionNameTooLong indicates that the collection name is longer than maxCollectionNameLength.
+	CollectionNameTooLong
+
+	// CollectionNameBadFirstChar indicates that the collection name doesn't start with a letter or an underscore.
+	CollectionNameBadFirstChar
+
+	// CollectionNameBadChar indicates that the collection name contains characters
+	// other than letters, digits and underscores.
+	CollectionNameBadChar
+
+	// CollectionNameReserved indicates that the collection name starts with the reserved prefix.
+	CollectionNameReserved
+)
+
+// String returns a human-readable reason.
+func (r InvalidCollectionNameReason) String() string {
+	switch r {
+	case CollectionNameEmpty:
+		return "name is empty"
+	case CollectionNameTooLong:
+		return fmt.Sprintf("name is longer than %d characters", maxCollectionNameLength)
+	case CollectionNameBadFirstChar:
+		return "name must start with a letter or an underscore"
+	case CollectionNameBadChar:
+		return "name must contain only letters, digits and underscores"
+	case CollectionNameReserved:
+		return fmt.Sprintf("name must not start with '%s'", reservedPrefix)
+	default:
+		return fmt.Sprintf("InvalidCollectionNameReason(%d)", int(r))
+	}
+}
+
+// InvalidCollectionNameError is returned by ValidateCollectionName
+// and functions creating collections when a collection name didn't pass checks.
+type InvalidCollectionNameError struct {
+	// Name is the checked collection name.
+	Name string
+
+	// Reason describes the failed check.
+	Reason InvalidCollectionNameReason
+}
+
+// Error implements error interface.
+func (e *InvalidCollectionNameError) Error() string {
+	return fmt.Sprintf("%s %q: %s", e.Unwrap(), e.Name, e.Reason)
+}
+
+// Unwrap returns ErrReservedName for CollectionNameReserved reason and ErrInvalidTableName otherwise.
+func (e *InvalidCollectionNameError) Unwrap() error {
+	if e.Reason == CollectionNameReserved {
+		return ErrReservedName
+	}
+
+	return ErrInvalidTableName
+}
@@ -0,0 +1,272 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pgdb
+
+import (
+	"strings"
+
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+)
+
+// UsableIndexes returns names of the given indexes that could be used for the given filter.
+//
+// An index could be used if the filter has a condition on the first field of the index key.
+// A partial index could be used only if the filter also implies its partial filter expression,
+// so all documents matching the filter are present in the index.
+// For the same reason, a sparse index could be used only if the filter implies that some key field exists.
+func UsableIndexes(indexes []Index, filter *types.Document) []string {
+	conds := filterConditions(filter)
+
+	var res []string
+
+	for _, index := range indexes {
+		if _, ok := conds[index.Key.Keys()[0]]; !ok {
+			continue
+		}
+
+		if index.PartialFilterExpression != nil && !filterImplies(conds, index.PartialFilterExpression) {
+			continue
+		}
+
+		if index.Sparse && !sparseImplied(conds, index.Key) {
+			continue
+		}
+
+		res = append(res, index.Name)
+	}
+
+	return res
+}
+
+// sparseImplied returns true if the given filter conditions imply that some field of the given index key exists.
+func sparseImplied(conds map[string][]any, key *types.Document) bool {
+	exists := operatorCondition{op: "$exists", value: true}
+
+	for _, field := range key.Keys() {
+		if fieldImplies(conds[field], exists) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// filterConditions returns conditions of the given filter grouped by field.
+// Conditions of top-level $and operators are included; other top-level operators are skipped.
+func filterConditions(filter *types.Document) map[string][]any {
+	conds := map[string][]any{}
+	collectConditions(conds, filter)
+
+	return conds
+}
+
+// collectConditions adds conditions of the given filter to conds; see filterConditions.
+func collectConditions(conds map[string][]any, filter *types.Document) {
+	if filter == nil {
+		return
+	}
+
+	for _, key := range filter.Keys() {
+		value := must.NotFail(filter.Get(key))
+
+		if !strings.HasPrefix(key, "$") {
+			conds[key] = append(conds[key], value)
+			continue
+		}
+
+		if key != "$and" {
+			continue
+		}
+
+		arr, ok := value.(*types.Array)
+		if !ok {
+			continue
+		}
+
+		for i := 0; i < arr.Len(); i++ {
+			if doc, ok := must.NotFail(arr.Get(i)).(*types.Document); ok {
+				collectConditions(conds, doc)
+			}
+		}
+	}
+}
+
+// filterImplies returns true if all documents matching the given filter conditions
+// also match the given partial filter expression.
+//
+// Only simple cases are handled; false is returned if that can't be proven.
+func filterImplies(conds map[string][]any, partial *types.Document) bool {
+	for _, key := range partial.Keys() {
+		value := must.NotFail(partial.Get(key))
+
+		if key == "$and" {
+			arr, ok := value.(*types.Array)
+			if !ok {
+				return false
+			}
+
+			for i := 0; i < arr.Len(); i++ {
+				doc, ok := must.NotFail(arr.Get(i)).(*types.Document)
+				if !ok || !filterImplies(conds, doc) {
+					return false
+				}
+			}
+
+			continue
+		}
+
+		if strings.HasPrefix(key, "$") {
+			return false
+		}
+
+		for _, op := range operatorConditions(value) {
+			if !fieldImplies(conds[key], op) {
+				return false
+			}
+		}
+	}
+
+	return true
+}
+
+// operatorCondition represents a single {$op: value} condition on a field.
+type operatorCondition struct {
+	op    string
+	value any
+}
+
+// operatorConditions returns operator conditions of the given field condition.
+// Values that are not operator documents are {$eq: value} conditions.
+func operatorConditions(cond any) []operatorCondition {
+	doc, ok := cond.(*types.Document)
+	if !ok || doc.Len() == 0 || !strings.HasPrefix(doc.Keys()[0], "$") {
+		return []operatorCondition{{op: "$eq", value: cond}}
+	}
+
+	res := make([]operatorCondition, 0, doc.Len())
+	for _, op := range doc.Keys() {
+		res = append(res, operatorCondition{op: op, value: must.NotFail(doc.Get(op))})
+	}
+
+	return res
+}
+
+// fieldImplies returns true if any of the given field conditions implies the given operator condition.
+// All field conditions must hold, so one of them is enough.
+func fieldImplies(fieldConds []any, expected operatorCondition) bool {
+	for _, cond := range fieldConds {
+		for _, actual := range operatorConditions(cond) {
+			if conditionImplies(actual, expected) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// conditionImplies returns true if the actual operator condition implies the expected one.
+func conditionImplies(actual, expected operatorCondition) bool {
+	if expected.op == "$exists" {
+		if v, ok := expected.value.(bool); !ok || !v {
+			return false
+		}
+
+		switch actual.op {
+		case "$exists":
+			v, ok := actual.value.(bool)
+			return ok && v
+		case "$eq":
+			_, isNull := actual.value.(types.NullType)
+			return !isNull
+		case "$gt", "$gte", "$lt", "$lte", "$type":
+			return true
+		default:
+			return false
+		}
+	}
+
+	if actual.op == "$type" || expected.op == "$type" {
+		return actual.op == expected.op && compareScalar(actual.value, expected.value) == types.Equal
+	}
+
+	res := compareScalar(actual.value, expected.value)
+	if res == types.Incomparable {
+		return false
+	}
+
+	switch actual.op {
+	case "$eq":
+		switch expected.op {
+		case "$eq":
+			return res == types.Equal
+		case "$gt":
+			return res == types.Greater
+		case "$gte":
+			return res != types.Less
+		case "$lt":
+			return res == types.Less
+		case "$lte":
+			return res != types.Greater
+		}
+
+	case "$gt":
+		switch expected.op {
+		case "$gt", "$gte":
+			return res != types.Less
+		}
+
+	case "$gte":
+		switch expected.op {
+		case "$gt":
+			return res == types.Greater
+		case "$gte":
+			return res != types.Less
+		}
+
+	case "$lt":
+		switch expected.op {
+		case "$lt", "$lte":
+			return res != types.Greater
+		}
+
+	case "$lte":
+		switch expected.op {
+		case "$lt":
+			return res == types.Less
+		case "$lte":
+			return res != types.Greater
+		}
+	}
+
+	return false
+}
+
+// compareScalar compares two scalar values of the same BSON type or two numbers.
+// It returns Incomparable for other values, including documents and arrays.
+func compareScalar(a, b any) types.CompareResult {
+	switch a.(type) {
+	case *types.Document, *types.Array:
+		return types.Incomparable
+	}
+
+	switch b.(type) {
+	case *types.Document, *types.Array:
+		return types.Incomparable
+	}
+
+	return types.Compare(a, b)[0]
+}
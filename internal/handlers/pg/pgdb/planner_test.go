@@ -0,0 +1,109 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pgdb
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+)
+
+func TestUsableIndexes(t *testing.T) {
+	t.Parallel()
+
+	// d and a are shortcuts for creating documents and arrays
+	d := func(pairs ...any) *types.Document { return must.NotFail(types.NewDocument(pairs...)) }
+	a := func(values ...any) *types.Array { return must.NotFail(types.NewArray(values...)) }
+
+	indexes := []Index{
+		{Name: "plain", Key: d("a", int32(1))},
+		{Name: "gt", Key: d("v", int32(1)), PartialFilterExpression: d("v", d("$gt", int32(10)))},
+		{Name: "lte", Key: d("v", int32(-1)), PartialFilterExpression: d("v", d("$lte", int64(100)))},
+		{Name: "exists", Key: d("w", int32(1)), PartialFilterExpression: d("w", d("$exists", true))},
+		{Name: "eq", Key: d("v", int32(1)), PartialFilterExpression: d("$and", a(d("kind", "foo")))},
+		{Name: "sparse", Key: d("s", int32(1)), Sparse: true},
+	}
+
+	for name, tc := range map[string]struct {
+		filter   *types.Document
+		expected []string
+	}{
+		"Empty": {
+			filter: d(),
+		},
+		"Nil": {},
+		"Plain": {
+			filter:   d("a", "foo"),
+			expected: []string{"plain"},
+		},
+		"Gt": {
+			filter:   d("v", d("$gt", int32(10))),
+			expected: []string{"gt"},
+		},
+		"GteGreater": {
+			filter:   d("v", d("$gte", 10.5)),
+			expected: []string{"gt"},
+		},
+		"GteEqual": {
+			filter: d("v", d("$gte", int32(10))),
+		},
+		"Range": {
+			filter:   d("v", d("$gt", int32(20), "$lt", int32(50))),
+			expected: []string{"gt", "lte"},
+		},
+		"Equality": {
+			filter:   d("v", int64(100)),
+			expected: []string{"gt", "lte"},
+		},
+		"EqualityOperator": {
+			filter:   d("v", d("$eq", int32(5))),
+			expected: []string{"lte"},
+		},
+		"DifferentType": {
+			filter: d("v", d("$gt", "foo")),
+		},
+		"Exists": {
+			filter:   d("w", d("$gte", int32(1))),
+			expected: []string{"exists"},
+		},
+		"ExistsNull": {
+			filter: d("w", types.Null),
+		},
+		"Sparse": {
+			filter:   d("s", "foo"),
+			expected: []string{"sparse"},
+		},
+		"SparseNull": {
+			filter: d("s", types.Null),
+		},
+		"And": {
+			filter:   d("$and", a(d("kind", "foo"), d("v", int32(200)))),
+			expected: []string{"gt", "eq"},
+		},
+		"Or": {
+			filter: d("$or", a(d("v", int32(20)))),
+		},
+	} {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, tc.expected, UsableIndexes(indexes, tc.filter))
+		})
+	}
+}
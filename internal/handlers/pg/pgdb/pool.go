@@ -16,6 +16,7 @@ package pgdb
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"strings"
 
@@ -24,7 +25,6 @@ import (
 	"github.com/jackc/pgx/v4/pgxpool"
 	"go.uber.org/zap"
 
-	"github.com/FerretDB/FerretDB/internal/types"
 	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
 )
 
@@ -40,6 +40,11 @@ const (
 // Pool represents PostgreSQL concurrency-safe connection pool.
 type Pool struct {
 	*pgxpool.Pool
+
+	// fetchedChannelBufSize and fetchedSliceCapacity override
+	// FetchedChannelBufSize and FetchedSliceCapacity if non-zero.
+	fetchedChannelBufSize int
+	fetchedSliceCapacity  int
 }
 
 // DBStats describes statistics for a database.
@@ -96,6 +101,42 @@ func NewPool(ctx context.Context, connString string, logger *zap.Logger, lazy bo
 	return res, err
 }
 
+// WithFetchSizes returns a copy of the pool that shares the same connections,
+// but uses given channel buffer size and slice capacity in QueryDocuments.
+// Zero values mean FetchedChannelBufSize and FetchedSliceCapacity defaults.
+func (pgPool *Pool) WithFetchSizes(channelBufSize, sliceCapacity int) (*Pool, error) {
+	if channelBufSize < 0 {
+		return nil, fmt.Errorf("pgdb.WithFetchSizes: invalid channel buffer size %d", channelBufSize)
+	}
+
+	if sliceCapacity < 0 {
+		return nil, fmt.Errorf("pgdb.WithFetchSizes: invalid slice capacity %d", sliceCapacity)
+	}
+
+	res := &Pool{
+		Pool:                  pgPool.Pool,
+		fetchedChannelBufSize: channelBufSize,
+		fetchedSliceCapacity:  sliceCapacity,
+	}
+
+	return res, nil
+}
+
+// fetchSizes returns channel buffer size and slice capacity used by QueryDocuments.
+func (pgPool *Pool) fetchSizes() (channelBufSize, sliceCapacity int) {
+	channelBufSize, sliceCapacity = FetchedChannelBufSize, FetchedSliceCapacity
+
+	if pgPool.fetchedChannelBufSize > 0 {
+		channelBufSize = pgPool.fetchedChannelBufSize
+	}
+
+	if pgPool.fetchedSliceCapacity > 0 {
+		sliceCapacity = pgPool.fetchedSliceCapacity
+	}
+
+	return
+}
+
 // isValidUTF8Locale Currently supported locale variants, compromised between https://www.postgresql.org/docs/9.3/multibyte.html
 // and https://www.gnu.org/software/libc/manual/html_node/Locale-Names.html.
 //
@@ -164,11 +205,19 @@ func (pgPool *Pool) checkConnection(ctx context.Context) error {
 
 // DropDatabase drops FerretDB database.
 //
-// It returns ErrTableNotExist if schema does not exist.
+// It returns true if the database existed and was dropped, false if it did not exist.
 //
 // Deprecated: use function instead.
-func (pgPool *Pool) DropDatabase(ctx context.Context, db string) error {
-	return DropDatabase(ctx, pgPool, db)
+func (pgPool *Pool) DropDatabase(ctx context.Context, db string) (existed bool, err error) {
+	err = DropDatabase(ctx, pgPool, db)
+	switch {
+	case err == nil:
+		return true, nil
+	case errors.Is(err, ErrSchemaNotExist):
+		return false, nil
+	default:
+		return false, lazyerrors.Error(err)
+	}
 }
 
 // CreateCollectionIfNotExist ensures that given FerretDB database / PostgreSQL schema
@@ -227,42 +276,20 @@ func (pgPool *Pool) SchemaStats(ctx context.Context, schema, collection string)
 	return &res, nil
 }
 
-// SetDocumentByID sets a document by its ID.
-//
-// Deprecated: use function instead.
-func (pgPool *Pool) SetDocumentByID(ctx context.Context, sp *SQLParam, id any, doc *types.Document) (int64, error) {
-	var n int64
-	err := pgPool.InTransaction(ctx, func(tx pgx.Tx) error {
-		var err error
-		n, err = SetDocumentByID(ctx, tx, sp, id, doc)
-		return err
-	})
-
-	return n, err
-}
-
-// DeleteDocumentsByID deletes documents by given IDs.
-//
-// Deprecated: use function instead.
-func (pgPool *Pool) DeleteDocumentsByID(ctx context.Context, sp *SQLParam, ids []any) (int64, error) {
-	var n int64
-	err := pgPool.InTransaction(ctx, func(tx pgx.Tx) error {
-		var err error
-		n, err = DeleteDocumentsByID(ctx, tx, sp, ids)
-		return err
-	})
-
-	return n, err
-}
-
 // InTransaction wraps the given function f in a transaction.
 // If f returns an error, the transaction is rolled back.
 // Errors are wrapped with lazyerrors.Error,
 // so the caller needs to use errors.Is to check the error,
 // for example, errors.Is(err, ErrSchemaNotExist).
-func (pgPool *Pool) InTransaction(ctx context.Context, f func(pgx.Tx) error) (err error) {
+func (pgPool *Pool) InTransaction(ctx context.Context, f func(pgx.Tx) error) error {
+	return pgPool.InTransactionWithOptions(ctx, pgx.TxOptions{}, f)
+}
+
+// InTransactionWithOptions is like InTransaction, but starts the transaction with the given options,
+// for example, with the REPEATABLE READ isolation level.
+func (pgPool *Pool) InTransactionWithOptions(ctx context.Context, opts pgx.TxOptions, f func(pgx.Tx) error) (err error) {
 	var tx pgx.Tx
-	if tx, err = pgPool.Begin(ctx); err != nil {
+	if tx, err = pgPool.BeginTx(ctx, opts); err != nil {
 		err = lazyerrors.Error(err)
 		return
 	}
@@ -18,7 +18,9 @@ import (
 	"context"
 	"strconv"
 	"testing"
+	"time"
 
+	"github.com/jackc/pgx/v4"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"go.uber.org/zap"
@@ -91,8 +93,9 @@ func TestCreateDrop(t *testing.T) {
 		err := DropCollection(ctx, pool, databaseName, collectionName)
 		require.Equal(t, ErrSchemaNotExist, err)
 
-		err = pool.DropDatabase(ctx, databaseName)
-		require.Equal(t, ErrSchemaNotExist, err)
+		existed, err := pool.DropDatabase(ctx, databaseName)
+		require.NoError(t, err)
+		assert.False(t, existed)
 
 		err = CreateCollection(ctx, pool, databaseName, collectionName)
 		require.ErrorIs(t, err, ErrSchemaNotExist)
@@ -137,11 +140,13 @@ func TestCreateDrop(t *testing.T) {
 		require.NoError(t, err)
 		assert.Equal(t, []string{collectionName}, tables)
 
-		err = pool.DropDatabase(ctx, databaseName)
+		existed, err := pool.DropDatabase(ctx, databaseName)
 		require.NoError(t, err)
+		assert.True(t, existed)
 
-		err = pool.DropDatabase(ctx, databaseName)
-		require.Equal(t, ErrSchemaNotExist, err)
+		existed, err = pool.DropDatabase(ctx, databaseName)
+		require.NoError(t, err)
+		assert.False(t, existed)
 	})
 
 	t.Run("SchemaExistsTableExists", func(t *testing.T) {
@@ -182,8 +187,9 @@ func TestCreateDrop(t *testing.T) {
 		err = DropCollection(ctx, pool, databaseName, collectionName)
 		require.ErrorIs(t, err, ErrTableNotExist)
 
-		err = pool.DropDatabase(ctx, databaseName)
+		existed, err := pool.DropDatabase(ctx, databaseName)
 		require.NoError(t, err)
+		assert.True(t, existed)
 	})
 }
 
@@ -383,3 +389,104 @@ func TestCreateTableIfNotExist(t *testing.T) {
 		assert.False(t, created)
 	})
 }
+
+func TestCollectionsExist(t *testing.T) {
+	t.Parallel()
+
+	ctx := testutil.Ctx(t)
+	pool := getPool(ctx, t, zaptest.NewLogger(t))
+
+	databaseName := testutil.DatabaseName(t)
+	collectionName := testutil.CollectionName(t)
+
+	t.Cleanup(func() {
+		pool.DropDatabase(ctx, databaseName)
+	})
+
+	pool.DropDatabase(ctx, databaseName)
+
+	names := []string{collectionName + "_1", collectionName + "_2", collectionName + "_missing"}
+
+	res, err := CollectionsExist(ctx, pool, databaseName, names)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]bool{names[0]: false, names[1]: false, names[2]: false}, res)
+
+	require.NoError(t, CreateDatabase(ctx, pool, databaseName))
+	require.NoError(t, CreateCollection(ctx, pool, databaseName, names[0]))
+	require.NoError(t, CreateCollection(ctx, pool, databaseName, names[1]))
+
+	res, err = CollectionsExist(ctx, pool, databaseName, names)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]bool{names[0]: true, names[1]: true, names[2]: false}, res)
+}
+
+func TestInTransactionWithOptions(t *testing.T) {
+	t.Parallel()
+
+	ctx := testutil.Ctx(t)
+	pool := getPool(ctx, t, zaptest.NewLogger(t))
+
+	for name, tc := range map[string]struct {
+		opts     pgx.TxOptions
+		expected string
+	}{
+		"Default": {
+			expected: "read committed",
+		},
+		"RepeatableRead": {
+			opts:     pgx.TxOptions{IsoLevel: pgx.RepeatableRead},
+			expected: "repeatable read",
+		},
+	} {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			var actual string
+			err := pool.InTransactionWithOptions(ctx, tc.opts, func(tx pgx.Tx) error {
+				return tx.QueryRow(ctx, "SHOW transaction_isolation").Scan(&actual)
+			})
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, actual)
+		})
+	}
+}
+
+func TestQueryCancel(t *testing.T) {
+	t.Parallel()
+
+	ctx := testutil.Ctx(t)
+	pool := getPool(ctx, t, zaptest.NewLogger(t))
+
+	// unique comment to find the query in pg_stat_activity
+	comment := testutil.DatabaseName(t) + "_" + strconv.FormatInt(time.Now().UnixNano(), 10)
+
+	queryCtx, cancel := context.WithCancel(ctx)
+	done := make(chan error, 1)
+	go func() {
+		_, err := pool.Exec(queryCtx, "SELECT pg_sleep(60) /* "+comment+" */")
+		done <- err
+	}()
+
+	// count returns the number of running queries with the comment
+	count := func() int {
+		var n int
+		q := "SELECT count(*) FROM pg_stat_activity WHERE state = 'active' AND query LIKE $1"
+		require.NoError(t, pool.QueryRow(ctx, q, "%/* "+comment+" */%").Scan(&n))
+		return n
+	}
+
+	require.Eventually(t, func() bool { return count() == 1 }, 10*time.Second, 50*time.Millisecond)
+
+	// that's what happens when the client disconnects
+	cancel()
+
+	select {
+	case err := <-done:
+		require.ErrorIs(t, err, context.Canceled)
+	case <-time.After(10 * time.Second):
+		t.Fatal("query was not canceled")
+	}
+
+	assert.Eventually(t, func() bool { return count() == 0 }, 10*time.Second, 50*time.Millisecond)
+}
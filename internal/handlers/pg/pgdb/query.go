@@ -19,11 +19,14 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/jackc/pgtype/pgxtype"
 	"github.com/jackc/pgx/v4"
 	"golang.org/x/exp/maps"
+	"golang.org/x/exp/slices"
 
 	"github.com/FerretDB/FerretDB/internal/fjson"
 	"github.com/FerretDB/FerretDB/internal/types"
@@ -32,9 +35,9 @@ import (
 )
 
 const (
-	// FetchedChannelBufSize is the size of the buffer of the channel that is used in QueryDocuments.
+	// FetchedChannelBufSize is the default size of the buffer of the channel that is used in QueryDocuments.
 	FetchedChannelBufSize = 3
-	// FetchedSliceCapacity is the capacity of the slice in FetchedDocs.
+	// FetchedSliceCapacity is the default capacity of the slice in FetchedDocs.
 	FetchedSliceCapacity = 2
 )
 
@@ -51,42 +54,80 @@ type SQLParam struct {
 	Collection string
 	Comment    string
 	Explain    bool
+
+	// Filter, if set, is partially pushed down to PostgreSQL to select fewer documents.
+	// Fetched documents still should be filtered in memory.
+	Filter *types.Document
+
+	// Sort, if set, is pushed down to PostgreSQL as ORDER BY if all sort fields are indexed,
+	// so PostgreSQL could use those indexes.
+	// Fetched documents still should be sorted in memory, as PostgreSQL order
+	// differs from MongoDB's order for some values, for example, for fields with values of different types.
+	Sort *types.Document
+
+	// SortByID, if set, makes PostgreSQL return documents ordered by _id when the Sort is not set
+	// and the collection has the unique _id index, so repeated unsorted queries return documents in the same order.
+	SortByID bool
+
+	// Skip and Limit, if set, are applied in SQL as OFFSET and LIMIT.
+	// They could be set only if the Filter is exact; see IsFilterExact.
+	Skip  int64
+	Limit int64
 }
 
 // QueryDocuments returns a channel with buffer FetchedChannelBufSize
 // to fetch list of documents for given FerretDB database and collection.
+// Each message contains up to FetchedSliceCapacity documents.
+// Both sizes could be changed with WithFetchSizes.
 //
 // If an error occurs before the fetching, the error is returned immediately.
-// The returned channel is always non-nil.
+// The returned channel and close function are always non-nil.
 //
-// The channel is closed when all documents are sent; the caller should always drain the channel.
+// The channel is closed when all documents are sent.
 // If an error occurs during fetching, the last message before closing the channel contains an error.
 // Context cancellation is not considered an error.
 //
+// The caller should always call the returned close function, typically with defer.
+// It stops fetching if it is still in progress, closes rows and releases the connection,
+// so abandoning the channel without reading the rest of it does not leak anything.
+// It is safe to call it several times.
+//
 // If the collection doesn't exist, fetch returns a closed channel and no error.
-func (pgPool *Pool) QueryDocuments(ctx context.Context, querier pgxtype.Querier, sp SQLParam) (<-chan FetchedDocs, error) {
-	fetchedChan := make(chan FetchedDocs, FetchedChannelBufSize)
+func (pgPool *Pool) QueryDocuments(ctx context.Context, querier pgxtype.Querier, sp SQLParam) (<-chan FetchedDocs, func(), error) {
+	channelBufSize, sliceCapacity := pgPool.fetchSizes()
+	fetchedChan := make(chan FetchedDocs, channelBufSize)
+
+	fetchCtx, cancel := context.WithCancel(ctx)
+	done := make(chan struct{})
+
+	closeFetch := func() {
+		cancel()
+		<-done
+	}
 
-	q, err := buildQuery(ctx, querier, &sp)
+	q, args, err := buildQuery(fetchCtx, querier, &sp)
 	if err != nil {
 		close(fetchedChan)
+		close(done)
 		if errors.Is(err, ErrTableNotExist) {
-			return fetchedChan, nil
+			return fetchedChan, closeFetch, nil
 		}
-		return fetchedChan, lazyerrors.Error(err)
+		return fetchedChan, closeFetch, lazyerrors.Error(err)
 	}
 
-	rows, err := querier.Query(ctx, q)
+	rows, err := querier.Query(fetchCtx, q, args...)
 	if err != nil {
 		close(fetchedChan)
-		return fetchedChan, lazyerrors.Error(err)
+		close(done)
+		return fetchedChan, closeFetch, lazyerrors.Error(err)
 	}
 
 	go func() {
+		defer close(done)
 		defer close(fetchedChan)
 		defer rows.Close()
 
-		err := iterateFetch(ctx, fetchedChan, rows)
+		err := iterateFetch(fetchCtx, fetchedChan, rows, sliceCapacity)
 		switch {
 		case err == nil:
 			// nothing
@@ -103,17 +144,17 @@ func (pgPool *Pool) QueryDocuments(ctx context.Context, querier pgxtype.Querier,
 		}
 	}()
 
-	return fetchedChan, nil
+	return fetchedChan, closeFetch, nil
 }
 
 // Explain returns SQL EXPLAIN results for given query parameters.
 func Explain(ctx context.Context, querier pgxtype.Querier, sp SQLParam) (*types.Array, error) {
-	q, err := buildQuery(ctx, querier, &sp)
+	q, args, err := buildQuery(ctx, querier, &sp)
 	if err != nil {
 		return nil, lazyerrors.Error(err)
 	}
 
-	rows, err := querier.Query(ctx, q)
+	rows, err := querier.Query(ctx, q, args...)
 	if err != nil {
 		return nil, lazyerrors.Error(err)
 	}
@@ -146,22 +187,38 @@ func Explain(ctx context.Context, querier pgxtype.Querier, sp SQLParam) (*types.
 	return &res, nil
 }
 
-// buildQuery builds SELECT or EXPLAIN SELECT query.
+// IsFilterPushedDown reports whether at least a part of the filter of given query parameters
+// is pushed down to the WHERE clause.
+func IsFilterPushedDown(ctx context.Context, querier pgxtype.Querier, sp SQLParam) (bool, error) {
+	table, err := getTableName(ctx, querier, sp.DB, sp.Collection)
+	if err != nil {
+		return false, lazyerrors.Error(err)
+	}
+
+	where, _, err := prepareWhereClause(ctx, querier, sp.DB, table, sp.Filter)
+	if err != nil {
+		return false, lazyerrors.Error(err)
+	}
+
+	return where != "", nil
+}
+
+// buildQuery builds SELECT or EXPLAIN SELECT query and returns it with its arguments.
 //
 // It returns (possibly wrapped) ErrSchemaNotExist or ErrTableNotExist
 // if schema/database or table/collection does not exist.
-func buildQuery(ctx context.Context, querier pgxtype.Querier, sp *SQLParam) (string, error) {
+func buildQuery(ctx context.Context, querier pgxtype.Querier, sp *SQLParam) (string, []any, error) {
 	exists, err := CollectionExists(ctx, querier, sp.DB, sp.Collection)
 	if err != nil {
-		return "", lazyerrors.Error(err)
+		return "", nil, lazyerrors.Error(err)
 	}
 	if !exists {
-		return "", lazyerrors.Error(ErrTableNotExist)
+		return "", nil, lazyerrors.Error(ErrTableNotExist)
 	}
 
 	table, err := getTableName(ctx, querier, sp.DB, sp.Collection)
 	if err != nil {
-		return "", lazyerrors.Error(err)
+		return "", nil, lazyerrors.Error(err)
 	}
 
 	q := `SELECT _jsonb `
@@ -174,20 +231,246 @@ func buildQuery(ctx context.Context, querier pgxtype.Querier, sp *SQLParam) (str
 	}
 	q += `FROM ` + pgx.Identifier{sp.DB, table}.Sanitize()
 
+	where, args, err := prepareWhereClause(ctx, querier, sp.DB, table, sp.Filter)
+	if err != nil {
+		return "", nil, lazyerrors.Error(err)
+	}
+
+	if where != "" {
+		q += ` WHERE ` + where
+	}
+
+	orderBy, err := prepareOrderBy(ctx, querier, sp.DB, sp.Collection, table, sp.Sort)
+	if err != nil {
+		return "", nil, lazyerrors.Error(err)
+	}
+
+	if orderBy == "" && sp.Sort.Len() == 0 && sp.SortByID {
+		var exists bool
+		if exists, err = idIndexExists(ctx, querier, sp.DB, table); err != nil {
+			return "", nil, lazyerrors.Error(err)
+		}
+
+		if exists {
+			orderBy = orderByExpr(fieldExpr("_id"), false)
+		}
+	}
+
+	if orderBy != "" {
+		q += ` ORDER BY ` + orderBy
+	}
+
+	if sp.Limit > 0 {
+		q += ` LIMIT ` + strconv.FormatInt(sp.Limit, 10)
+	}
+
+	if sp.Skip > 0 {
+		q += ` OFFSET ` + strconv.FormatInt(sp.Skip, 10)
+	}
+
 	if sp.Explain {
 		q = "EXPLAIN (VERBOSE true, FORMAT JSON) " + q
 	}
 
-	return q, nil
+	return q, args, nil
 }
 
-// iterateFetch iterates over the rows returned by the query and sends FetchedDocs to fetched channel.
+// IsSortPushedDown reports whether the sort of given query parameters is pushed down to the ORDER BY clause.
+//
+// Documents are sorted by PostgreSQL the same way as by MongoDB only if all their sort values
+// are accepted by IsSortExact; otherwise, they should be sorted again in memory.
+func IsSortPushedDown(ctx context.Context, querier pgxtype.Querier, sp SQLParam) (bool, error) {
+	exists, err := CollectionExists(ctx, querier, sp.DB, sp.Collection)
+	if err != nil {
+		return false, lazyerrors.Error(err)
+	}
+
+	if !exists {
+		return false, nil
+	}
+
+	table, err := getTableName(ctx, querier, sp.DB, sp.Collection)
+	if err != nil {
+		return false, lazyerrors.Error(err)
+	}
+
+	orderBy, err := prepareOrderBy(ctx, querier, sp.DB, sp.Collection, table, sp.Sort)
+	if err != nil {
+		return false, lazyerrors.Error(err)
+	}
+
+	return orderBy != "", nil
+}
+
+// IsSortExact reports whether the given document is sorted by the ORDER BY clause
+// the same way as by MongoDB for the given sort document.
+//
+// That is true if all sort values are missing, nulls, numbers, strings, ObjectIDs, booleans or dates,
+// and sort paths do not go through arrays.
+func IsSortExact(doc *types.Document, sort *types.Document) bool {
+	for _, key := range sort.Keys() {
+		var v any = doc
+
+		for _, k := range strings.Split(key, ".") {
+			d, ok := v.(*types.Document)
+			if !ok {
+				// arrays are indexed by MongoDB, but not by jsonb -> operator with a text key
+				if _, ok = v.(*types.Array); ok {
+					return false
+				}
+
+				// value of other types does not have fields, so the sort value is missing
+				v = nil
+
+				break
+			}
+
+			if v, _ = d.Get(k); v == nil {
+				break
+			}
+		}
+
+		switch v.(type) {
+		case nil, types.NullType, float64, string, types.ObjectID, bool, time.Time, int32, int64:
+		default:
+			return false
+		}
+	}
+
+	return true
+}
+
+// prepareOrderBy returns ORDER BY clause for the given sort document without the keyword,
+// or an empty string if it can't be pushed down because some sort field is not indexed.
+//
+// Only indexed fields are pushed down, including _id with the unique _id index,
+// so sorting by _id, the most common pagination key, is pushed down too; other fields are sorted in memory.
+// Sort values are compared as described in orderByExpr.
+func prepareOrderBy(ctx context.Context, querier pgxtype.Querier, db, collection, table string, sort *types.Document) (string, error) {
+	if sort.Len() == 0 {
+		return "", nil
+	}
+
+	indexes, err := Indexes(ctx, querier, db, collection)
+	if err != nil {
+		return "", lazyerrors.Error(err)
+	}
+
+	// the unique _id index is not stored in the settings table
+	if sort.Has("_id") {
+		var exists bool
+		if exists, err = idIndexExists(ctx, querier, db, table); err != nil {
+			return "", lazyerrors.Error(err)
+		}
+
+		if exists {
+			indexes = append(indexes, Index{Name: "_id_", Key: must.NotFail(types.NewDocument("_id", int32(1)))})
+		}
+	}
+
+	exprs := make([]string, 0, sort.Len())
+	for _, field := range sort.Keys() {
+		if !isSortFieldIndexed(indexes, field) {
+			return "", nil
+		}
+
+		exprs = append(exprs, orderByExpr(fieldExpr(field), isDescending(must.NotFail(sort.Get(field)))))
+	}
+
+	return strings.Join(exprs, `, `), nil
+}
+
+// isSortFieldIndexed returns true if the given field has a PostgreSQL index.
+func isSortFieldIndexed(indexes []Index, field string) bool {
+	for _, index := range indexes {
+		keys := index.Key.Keys()
+
+		// numeric indexes are created for each key field, see CreateNumericIndex
+		if index.Numeric {
+			if slices.Contains(keys, field) {
+				return true
+			}
+
+			continue
+		}
+
+		// PostgreSQL indexes are created only for single-field keys, see CreateIndex
+		if len(keys) == 1 && keys[0] == field {
+			return true
+		}
+	}
+
+	return false
+}
+
+// orderByExpr returns ORDER BY expressions for the given jsonb expression and sort direction.
+//
+// Raw jsonb values can't be used for sorting: int64 and double values are stored as objects,
+// jsonb numbers and objects are compared differently than BSON values, and strings are compared
+// with the database collation. Instead, values are sorted by:
+//   - BSON type order (missing fields and nulls are equal, int32, int64 and double values are all numbers);
+//   - NaN, -Infinity, finite numbers, and Infinity for numbers;
+//   - numeric value for numbers and dates;
+//   - bytes for strings, ObjectIDs and booleans.
+//
+// Other types (documents, arrays, binary data, etc.) are sorted after them,
+// but not ordered between themselves; see IsSortExact.
+func orderByExpr(f string, descending bool) string {
+	// documents are stored with $k key that could be followed by user keys like $l
+	typeRank := `CASE COALESCE(jsonb_typeof(` + f + `), 'null')` +
+		` WHEN 'null' THEN 1` +
+		` WHEN 'number' THEN 2` +
+		` WHEN 'string' THEN 3` +
+		` WHEN 'boolean' THEN 8` +
+		` WHEN 'object' THEN CASE` +
+		` WHEN ` + f + ` ? '$k' THEN 100` +
+		` WHEN ` + f + ` ? '$l' OR ` + f + ` ? '$f' THEN 2` +
+		` WHEN ` + f + ` ? '$o' THEN 7` +
+		` WHEN ` + f + ` ? '$d' THEN 9` +
+		` ELSE 100 END` +
+		` ELSE 100 END`
+
+	specialRank := `CASE ` + f + `->>'$f'` +
+		` WHEN 'NaN' THEN 0` +
+		` WHEN '-Infinity' THEN 1` +
+		` WHEN 'Infinity' THEN 3` +
+		` ELSE 2 END`
+
+	numeric := `CASE jsonb_typeof(` + f + `)` +
+		` WHEN 'number' THEN (` + f + `#>>'{}')::numeric` +
+		` WHEN 'object' THEN CASE` +
+		` WHEN ` + f + ` ? '$k' THEN NULL` +
+		` WHEN ` + f + ` ? '$l' THEN (` + f + `->>'$l')::numeric` +
+		` WHEN jsonb_typeof(` + f + `->'$f') = 'number' THEN (` + f + `->>'$f')::numeric` +
+		` WHEN ` + f + `->>'$f' = '-0' THEN 0` +
+		` WHEN jsonb_typeof(` + f + `->'$d') = 'number' THEN (` + f + `->>'$d')::numeric` +
+		` END END`
+
+	text := `(CASE jsonb_typeof(` + f + `)` +
+		` WHEN 'string' THEN ` + f + `#>>'{}'` +
+		` WHEN 'boolean' THEN ` + f + `#>>'{}'` +
+		` WHEN 'object' THEN ` + f + `->>'$o'` +
+		` END) COLLATE "C"`
+
+	dir := ` ASC`
+	if descending {
+		dir = ` DESC`
+	}
+
+	return typeRank + dir + `, ` + specialRank + dir + `, ` + numeric + dir + `, ` + text + dir
+}
+
+// iterateFetch iterates over the rows returned by the query and sends FetchedDocs
+// with up to sliceCapacity documents each to fetched channel.
 // It returns ctx.Err() if context cancellation was received.
-func iterateFetch(ctx context.Context, fetched chan FetchedDocs, rows pgx.Rows) error {
+//
+// If some row can't be decoded into a document, the error containing (possibly truncated) raw _jsonb
+// of that row is sent, and iteration stops.
+func iterateFetch(ctx context.Context, fetched chan FetchedDocs, rows pgx.Rows, sliceCapacity int) error {
 	for ctx.Err() == nil {
 		var allFetched bool
-		res := make([]*types.Document, 0, FetchedSliceCapacity)
-		for i := 0; i < FetchedSliceCapacity; i++ {
+		res := make([]*types.Document, 0, sliceCapacity)
+		for i := 0; i < sliceCapacity; i++ {
 			if !rows.Next() {
 				allFetched = true
 				break
@@ -198,12 +481,19 @@ func iterateFetch(ctx context.Context, fetched chan FetchedDocs, rows pgx.Rows)
 				return writeFetched(ctx, fetched, FetchedDocs{Err: lazyerrors.Error(err)})
 			}
 
-			doc, err := fjson.Unmarshal(b)
+			v, err := fjson.Unmarshal(b)
 			if err != nil {
-				return writeFetched(ctx, fetched, FetchedDocs{Err: lazyerrors.Error(err)})
+				err = lazyerrors.Errorf("invalid _jsonb %s: %w", truncateJSONB(b), err)
+				return writeFetched(ctx, fetched, FetchedDocs{Err: err})
 			}
 
-			res = append(res, doc.(*types.Document))
+			doc, ok := v.(*types.Document)
+			if !ok {
+				err = lazyerrors.Errorf("invalid _jsonb %s: expected document, got %T", truncateJSONB(b), v)
+				return writeFetched(ctx, fetched, FetchedDocs{Err: err})
+			}
+
+			res = append(res, doc)
 		}
 
 		if len(res) > 0 {
@@ -226,6 +516,17 @@ func iterateFetch(ctx context.Context, fetched chan FetchedDocs, rows pgx.Rows)
 	return ctx.Err()
 }
 
+// truncateJSONB returns raw _jsonb value truncated for error messages.
+func truncateJSONB(b []byte) string {
+	const maxLen = 100
+
+	if len(b) <= maxLen {
+		return string(b)
+	}
+
+	return string(b[:maxLen]) + "..."
+}
+
 // writeFetched sends FetchedDocs to fetched channel or handles context cancellation.
 // It returns ctx.Err() if context cancellation was received.
 func writeFetched(ctx context.Context, fetched chan FetchedDocs, doc FetchedDocs) error {
@@ -16,8 +16,12 @@ package pgdb
 
 import (
 	"fmt"
+	"math"
+	"math/rand"
 	"testing"
+	"time"
 
+	"github.com/jackc/pgx/v4"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"go.uber.org/zap/zaptest"
@@ -99,8 +103,9 @@ func TestQueryDocuments(t *testing.T) {
 			}
 
 			sp := SQLParam{DB: dbName, Collection: tc.collection}
-			fetchedChan, err := pool.QueryDocuments(ctx, tx, sp)
+			fetchedChan, closeFetch, err := pool.QueryDocuments(ctx, tx, sp)
 			require.NoError(t, err)
+			defer closeFetch()
 
 			iter := 0
 			for {
@@ -132,9 +137,10 @@ func TestQueryDocuments(t *testing.T) {
 
 		sp := SQLParam{DB: dbName, Collection: collectionName + "_cancel"}
 		ctx, cancel := context.WithCancel(context.Background())
-		fetchedChan, err := pool.QueryDocuments(ctx, pool, sp)
+		fetchedChan, closeFetch, err := pool.QueryDocuments(ctx, pool, sp)
 		cancel()
 		require.NoError(t, err)
+		defer closeFetch()
 
 		<-ctx.Done()
 		countDocs := 0
@@ -151,14 +157,80 @@ func TestQueryDocuments(t *testing.T) {
 		require.ErrorIs(t, tx.Rollback(ctx), context.Canceled)
 	})
 
+	// Special case: custom fetch sizes.
+	t.Run("custom_fetch_sizes", func(t *testing.T) {
+		tx, err := pool.Begin(ctx)
+		require.NoError(t, err)
+
+		for i := 1; i <= 7; i++ {
+			require.NoError(t, InsertDocument(ctx, tx, dbName, collectionName+"_custom",
+				must.NotFail(types.NewDocument("id", fmt.Sprintf("%d", i))),
+			))
+		}
+
+		sp := SQLParam{DB: dbName, Collection: collectionName + "_custom"}
+		customPool, err := pool.WithFetchSizes(1, 3)
+		require.NoError(t, err)
+
+		_, err = pool.WithFetchSizes(-1, 3)
+		require.Error(t, err)
+
+		fetchedChan, closeFetch, err := customPool.QueryDocuments(ctx, tx, sp)
+		require.NoError(t, err)
+		defer closeFetch()
+		assert.Equal(t, 1, cap(fetchedChan))
+
+		var docsPerIteration []int
+		for fetched := range fetchedChan {
+			assert.NoError(t, fetched.Err)
+			docsPerIteration = append(docsPerIteration, len(fetched.Docs))
+		}
+		assert.Equal(t, []int{3, 3, 1}, docsPerIteration)
+
+		require.NoError(t, tx.Commit(ctx))
+	})
+
+	// Special case: malformed _jsonb.
+	t.Run("malformed_jsonb", func(t *testing.T) {
+		tx, err := pool.Begin(ctx)
+		require.NoError(t, err)
+
+		collection := collectionName + "_malformed"
+		require.NoError(t, InsertDocument(ctx, tx, dbName, collection, must.NotFail(types.NewDocument("id", "1"))))
+
+		table, err := getTableName(ctx, tx, dbName, collection)
+		require.NoError(t, err)
+
+		sql := `INSERT INTO ` + pgx.Identifier{dbName, table}.Sanitize() + ` (_jsonb) VALUES ('"foo"')`
+		_, err = tx.Exec(ctx, sql)
+		require.NoError(t, err)
+
+		sp := SQLParam{DB: dbName, Collection: collection}
+		fetchedChan, closeFetch, err := pool.QueryDocuments(ctx, tx, sp)
+		require.NoError(t, err)
+		defer closeFetch()
+
+		var fetchErr error
+		for fetched := range fetchedChan {
+			if fetched.Err != nil {
+				fetchErr = fetched.Err
+			}
+		}
+		require.Error(t, fetchErr)
+		assert.Contains(t, fetchErr.Error(), `invalid _jsonb "foo"`)
+
+		require.NoError(t, tx.Rollback(ctx))
+	})
+
 	// Special case: querying a non-existing collection.
 	t.Run("non-existing_collection", func(t *testing.T) {
 		tx, err := pool.Begin(ctx)
 		require.NoError(t, err)
 
 		sp := SQLParam{DB: dbName, Collection: collectionName + "_non-existing"}
-		fetchedChan, err := pool.QueryDocuments(context.Background(), tx, sp)
+		fetchedChan, closeFetch, err := pool.QueryDocuments(context.Background(), tx, sp)
 		require.NoError(t, err)
+		defer closeFetch()
 		res, ok := <-fetchedChan
 		require.False(t, ok)
 		require.Nil(t, res.Docs)
@@ -167,3 +239,378 @@ func TestQueryDocuments(t *testing.T) {
 		require.NoError(t, tx.Commit(ctx))
 	})
 }
+
+func TestQueryDocumentsAbandoned(t *testing.T) {
+	t.Parallel()
+
+	ctx := testutil.Ctx(t)
+
+	pool := getPool(ctx, t, zaptest.NewLogger(t))
+	dbName := testutil.DatabaseName(t)
+	collectionName := testutil.CollectionName(t)
+
+	t.Cleanup(func() {
+		pool.DropDatabase(ctx, dbName)
+	})
+
+	pool.DropDatabase(ctx, dbName)
+	require.NoError(t, CreateDatabase(ctx, pool, dbName))
+
+	for i := 1; i <= FetchedChannelBufSize*FetchedSliceCapacity*3; i++ {
+		require.NoError(t, InsertDocument(ctx, pool, dbName, collectionName,
+			must.NotFail(types.NewDocument("id", fmt.Sprintf("%d", i))),
+		))
+	}
+
+	t.Run("Cancel", func(t *testing.T) {
+		require.Zero(t, pool.Stat().AcquiredConns())
+
+		queryCtx, cancel := context.WithCancel(ctx)
+		sp := SQLParam{DB: dbName, Collection: collectionName}
+		fetchedChan, _, err := pool.QueryDocuments(queryCtx, pool, sp)
+		require.NoError(t, err)
+
+		// read only the first batch, then abandon the channel without draining or closing it
+		fetched := <-fetchedChan
+		require.NoError(t, fetched.Err)
+		require.Len(t, fetched.Docs, FetchedSliceCapacity)
+
+		cancel()
+
+		require.Eventually(t, func() bool {
+			return pool.Stat().AcquiredConns() == 0
+		}, 5*time.Second, 10*time.Millisecond)
+	})
+
+	t.Run("Close", func(t *testing.T) {
+		require.Zero(t, pool.Stat().AcquiredConns())
+
+		sp := SQLParam{DB: dbName, Collection: collectionName}
+		fetchedChan, closeFetch, err := pool.QueryDocuments(ctx, pool, sp)
+		require.NoError(t, err)
+
+		// read only the first batch, then abandon the channel without draining it or canceling the context
+		fetched := <-fetchedChan
+		require.NoError(t, fetched.Err)
+		require.Len(t, fetched.Docs, FetchedSliceCapacity)
+
+		closeFetch()
+		require.Eventually(t, func() bool {
+			return pool.Stat().AcquiredConns() == 0
+		}, 5*time.Second, 10*time.Millisecond)
+
+		// closing again is a no-op
+		closeFetch()
+
+		// the channel is closed after the remaining buffered documents
+		for range fetchedChan {
+		}
+	})
+}
+
+func TestIsSortFieldIndexed(t *testing.T) {
+	t.Parallel()
+
+	indexes := []Index{
+		{Name: "a_1", Key: must.NotFail(types.NewDocument("a", int32(1)))},
+		{Name: "b.c_-1", Key: must.NotFail(types.NewDocument("b.c", int32(-1)))},
+		{Name: "d_1_e_1", Key: must.NotFail(types.NewDocument("d", int32(1), "e", int32(1)))},
+		{Name: "f_1_g_1", Key: must.NotFail(types.NewDocument("f", int32(1), "g", int32(1))), Numeric: true},
+	}
+
+	for field, expected := range map[string]bool{
+		"a":   true,
+		"b.c": true,
+		"d":   false, // compound keys have no PostgreSQL indexes
+		"e":   false,
+		"f":   true,
+		"g":   true,
+		"h":   false,
+	} {
+		assert.Equal(t, expected, isSortFieldIndexed(indexes, field), "field %q", field)
+	}
+}
+
+func TestIsSortExact(t *testing.T) {
+	t.Parallel()
+
+	sort := must.NotFail(types.NewDocument("v", int32(1), "a.b", int32(-1)))
+
+	for name, tc := range map[string]struct {
+		doc      *types.Document
+		expected bool
+	}{
+		"Missing": {
+			doc:      must.NotFail(types.NewDocument("_id", int32(1))),
+			expected: true,
+		},
+		"Scalars": {
+			doc: must.NotFail(types.NewDocument(
+				"v", int64(42),
+				"a", must.NotFail(types.NewDocument("b", types.NewObjectID())),
+			)),
+			expected: true,
+		},
+		"Null": {
+			doc:      must.NotFail(types.NewDocument("v", types.Null, "a", "foo")),
+			expected: true,
+		},
+		"Date": {
+			doc:      must.NotFail(types.NewDocument("v", time.Now(), "a", must.NotFail(types.NewDocument()))),
+			expected: true,
+		},
+		"Array": {
+			doc:      must.NotFail(types.NewDocument("v", must.NotFail(types.NewArray(int32(1), int32(2))))),
+			expected: false,
+		},
+		"Document": {
+			doc:      must.NotFail(types.NewDocument("v", must.NotFail(types.NewDocument("a", int32(1))))),
+			expected: false,
+		},
+		"Timestamp": {
+			doc:      must.NotFail(types.NewDocument("v", types.Timestamp(42))),
+			expected: false,
+		},
+		"PathThroughArray": {
+			doc: must.NotFail(types.NewDocument(
+				"v", "foo",
+				"a", must.NotFail(types.NewArray(must.NotFail(types.NewDocument("b", int32(1))))),
+			)),
+			expected: false,
+		},
+	} {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, tc.expected, IsSortExact(tc.doc, sort))
+		})
+	}
+}
+
+func TestQuerySortPushdown(t *testing.T) {
+	t.Parallel()
+
+	ctx := testutil.Ctx(t)
+
+	pool := getPool(ctx, t, zaptest.NewLogger(t))
+	dbName := testutil.DatabaseName(t)
+	collectionName := testutil.CollectionName(t)
+
+	t.Cleanup(func() {
+		pool.DropDatabase(ctx, dbName)
+	})
+
+	pool.DropDatabase(ctx, dbName)
+	require.NoError(t, CreateDatabase(ctx, pool, dbName))
+
+	date := time.Date(2022, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	// values in MongoDB sort order; nil means a missing field
+	values := []any{
+		nil,
+		math.NaN(),
+		math.Inf(-1),
+		int64(-5),
+		float64(-1.5),
+		int32(0),
+		float64(1.5),
+		int64(2),
+		float64(2.5),
+		int32(3),
+		int64(1 << 40),
+		float64(1e300),
+		math.Inf(+1),
+		"B",
+		"a",
+		"b",
+		"é",
+		types.ObjectID{0x01},
+		types.ObjectID{0x0a},
+		false,
+		true,
+		date.Add(-time.Hour),
+		date,
+	}
+
+	// insert in a different order, so PostgreSQL's natural order does not match
+	for _, i := range rand.New(rand.NewSource(1)).Perm(len(values)) {
+		doc := must.NotFail(types.NewDocument("_id", int32(i)))
+		if v := values[i]; v != nil {
+			must.NoError(doc.Set("v", v))
+		}
+		require.NoError(t, InsertDocument(ctx, pool, dbName, collectionName, doc))
+	}
+
+	index := Index{Name: "v_1", Key: must.NotFail(types.NewDocument("v", int32(1)))}
+	require.NoError(t, CreateIndex(ctx, pool, dbName, collectionName, &index))
+
+	asc := make([]any, len(values))
+	desc := make([]any, len(values))
+	for i := range values {
+		asc[i] = int32(i)
+		desc[len(values)-1-i] = int32(i)
+	}
+
+	for name, tc := range map[string]struct {
+		sort     *types.Document
+		orderBy  string
+		expected []any
+	}{
+		"Asc": {
+			sort:     must.NotFail(types.NewDocument("v", int32(1))),
+			orderBy:  `ORDER BY ` + orderByExpr(`_jsonb->'v'`, false),
+			expected: asc,
+		},
+		"Desc": {
+			sort:     must.NotFail(types.NewDocument("v", int32(-1))),
+			orderBy:  `ORDER BY ` + orderByExpr(`_jsonb->'v'`, true),
+			expected: desc,
+		},
+		"ID": {
+			sort:     must.NotFail(types.NewDocument("_id", int32(-1))),
+			orderBy:  `ORDER BY ` + orderByExpr(`_jsonb->'_id'`, true),
+			expected: desc,
+		},
+		"AscID": {
+			sort:     must.NotFail(types.NewDocument("v", int32(1), "_id", int32(1))),
+			orderBy:  `ORDER BY ` + orderByExpr(`_jsonb->'v'`, false) + `, ` + orderByExpr(`_jsonb->'_id'`, false),
+			expected: asc,
+		},
+		"NotIndexed": {
+			sort: must.NotFail(types.NewDocument("v", int32(1), "w", int32(1))),
+		},
+	} {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			sp := SQLParam{DB: dbName, Collection: collectionName, Sort: tc.sort}
+
+			q, _, err := buildQuery(ctx, pool, &sp)
+			require.NoError(t, err)
+
+			pushedDown, err := IsSortPushedDown(ctx, pool, sp)
+			require.NoError(t, err)
+			assert.Equal(t, tc.orderBy != "", pushedDown)
+
+			if tc.orderBy == "" {
+				assert.NotContains(t, q, "ORDER BY")
+				return
+			}
+
+			assert.Contains(t, q, tc.orderBy)
+
+			fetchedChan, closeFetch, err := pool.QueryDocuments(ctx, pool, sp)
+			require.NoError(t, err)
+			defer closeFetch()
+
+			var ids []any
+			for fetched := range fetchedChan {
+				require.NoError(t, fetched.Err)
+				for _, doc := range fetched.Docs {
+					assert.True(t, IsSortExact(doc, tc.sort))
+					ids = append(ids, must.NotFail(doc.Get("_id")))
+				}
+			}
+
+			assert.Equal(t, tc.expected, ids)
+		})
+	}
+}
+
+func TestQuerySortByID(t *testing.T) {
+	t.Parallel()
+
+	ctx := testutil.Ctx(t)
+
+	pool := getPool(ctx, t, zaptest.NewLogger(t))
+	dbName := testutil.DatabaseName(t)
+	collectionName := testutil.CollectionName(t)
+
+	t.Cleanup(func() {
+		pool.DropDatabase(ctx, dbName)
+	})
+
+	pool.DropDatabase(ctx, dbName)
+	require.NoError(t, CreateDatabase(ctx, pool, dbName))
+
+	// _id values of different types in MongoDB sort order
+	expected := []any{
+		float64(-1),
+		int32(1),
+		float64(2.5),
+		int64(3),
+		int32(10),
+		"a",
+		"b",
+		types.ObjectID{0x01, 0xff},
+		types.ObjectID{0x0a},
+	}
+
+	for _, i := range []int{6, 3, 8, 0, 5, 2, 7, 4, 1} {
+		doc := must.NotFail(types.NewDocument("_id", expected[i]))
+		require.NoError(t, InsertDocument(ctx, pool, dbName, collectionName, doc))
+	}
+
+	// update moves the row, so PostgreSQL's natural order changes
+	doc := must.NotFail(types.NewDocument("_id", "a", "v", int32(1)))
+	err := pool.InTransaction(ctx, func(tx pgx.Tx) error {
+		_, err := SetDocumentByID(ctx, tx, &SQLParam{DB: dbName, Collection: collectionName}, "a", doc)
+		return err
+	})
+	require.NoError(t, err)
+
+	sp := SQLParam{DB: dbName, Collection: collectionName, SortByID: true}
+
+	q, _, err := buildQuery(ctx, pool, &sp)
+	require.NoError(t, err)
+	assert.Contains(t, q, `ORDER BY `+orderByExpr(`_jsonb->'_id'`, false))
+
+	// explicit sort takes precedence
+	sorted := SQLParam{
+		DB:         dbName,
+		Collection: collectionName,
+		Sort:       must.NotFail(types.NewDocument("v", int32(1))),
+		SortByID:   true,
+	}
+	q, _, err = buildQuery(ctx, pool, &sorted)
+	require.NoError(t, err)
+	assert.NotContains(t, q, "ORDER BY")
+
+	for i := 0; i < 3; i++ {
+		fetchedChan, closeFetch, err := pool.QueryDocuments(ctx, pool, sp)
+		require.NoError(t, err)
+
+		var ids []any
+		for fetched := range fetchedChan {
+			require.NoError(t, fetched.Err)
+			for _, doc := range fetched.Docs {
+				ids = append(ids, must.NotFail(doc.Get("_id")))
+			}
+		}
+
+		closeFetch()
+
+		assert.Equal(t, expected, ids, "read %d", i)
+	}
+
+	// pages of the sorted query
+	var ids []any
+	for skip := int64(0); skip < int64(len(expected)); skip += 2 {
+		page := SQLParam{DB: dbName, Collection: collectionName, SortByID: true, Skip: skip, Limit: 2}
+		fetchedChan, closeFetch, err := pool.QueryDocuments(ctx, pool, page)
+		require.NoError(t, err)
+
+		for fetched := range fetchedChan {
+			require.NoError(t, fetched.Err)
+			for _, doc := range fetched.Docs {
+				ids = append(ids, must.NotFail(doc.Get("_id")))
+			}
+		}
+
+		closeFetch()
+	}
+
+	assert.Equal(t, expected, ids)
+}
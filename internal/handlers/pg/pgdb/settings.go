@@ -40,6 +40,11 @@ const (
 
 	// PostgreSQL max table name length.
 	maxTableNameLength = 63
+
+	// Current version of the settings document format.
+	// Version 0 documents don't have "version" field.
+	// Collections of version 1 databases may lack unique _id index.
+	settingsVersion = int32(2)
 )
 
 // createSettingsTable creates FerretDB settings table if it doesn't exist.
@@ -78,7 +83,10 @@ func createSettingsTable(ctx context.Context, querier pgxtype.Querier, db string
 		}
 	}
 
-	settings := must.NotFail(types.NewDocument("collections", must.NotFail(types.NewDocument())))
+	settings := must.NotFail(types.NewDocument(
+		"version", settingsVersion,
+		"collections", must.NotFail(types.NewDocument()),
+	))
 	sql = fmt.Sprintf(`INSERT INTO %s (settings) VALUES ($1)`, pgx.Identifier{db, settingsTableName}.Sanitize())
 	_, err = querier.Exec(ctx, sql, must.NotFail(fjson.Marshal(settings)))
 	if err != nil {
@@ -141,34 +149,153 @@ func getTableName(ctx context.Context, querier pgxtype.Querier, db, collection s
 }
 
 // getSettingsTable returns FerretDB settings table.
+//
+// Settings document of older format is upgraded and stored.
+// The stored document is replaced only if it was not changed concurrently;
+// otherwise, it is read and upgraded again.
 func getSettingsTable(ctx context.Context, querier pgxtype.Querier, db string) (*types.Document, error) {
+	// a few attempts are enough, concurrent migrations store the same result
+	const maxAttempts = 3
+
+	for i := 0; i < maxAttempts; i++ {
+		settings, raw, err := readSettingsTable(ctx, querier, db)
+		if err != nil {
+			return nil, lazyerrors.Error(err)
+		}
+
+		migrated, err := migrateSettings(ctx, querier, db, settings)
+		if err != nil {
+			return nil, lazyerrors.Error(err)
+		}
+
+		if !migrated {
+			return settings, nil
+		}
+
+		sql := `UPDATE ` + pgx.Identifier{db, settingsTableName}.Sanitize() + ` SET settings = $1 WHERE settings = $2`
+		tag, err := querier.Exec(ctx, sql, must.NotFail(fjson.Marshal(settings)), raw)
+		if err != nil {
+			return nil, lazyerrors.Error(err)
+		}
+
+		if tag.RowsAffected() > 0 {
+			return settings, nil
+		}
+	}
+
+	return nil, lazyerrors.Errorf("settings were changed concurrently during %d migration attempts", maxAttempts)
+}
+
+// readSettingsTable returns FerretDB settings document as is, and its raw representation.
+func readSettingsTable(ctx context.Context, querier pgxtype.Querier, db string) (*types.Document, []byte, error) {
 	sql := `SELECT settings FROM ` + pgx.Identifier{db, settingsTableName}.Sanitize()
 	rows, err := querier.Query(ctx, sql)
 	if err != nil {
-		return nil, lazyerrors.Error(err)
+		return nil, nil, lazyerrors.Error(err)
 	}
 	defer rows.Close()
 
 	if !rows.Next() {
-		return nil, lazyerrors.Errorf("no settings found")
+		return nil, nil, lazyerrors.Errorf("no settings found")
 	}
 
 	var b []byte
 	if err := rows.Scan(&b); err != nil {
-		return nil, lazyerrors.Error(err)
+		return nil, nil, lazyerrors.Error(err)
 	}
 
 	doc, err := fjson.Unmarshal(b)
 	if err != nil {
-		return nil, lazyerrors.Error(err)
+		return nil, nil, lazyerrors.Error(err)
 	}
 
 	settings, ok := doc.(*types.Document)
 	if !ok {
-		return nil, lazyerrors.Errorf("invalid settings document: %v", doc)
+		return nil, nil, lazyerrors.Errorf("invalid settings document: %v", doc)
+	}
+
+	return settings, slices.Clone(b), nil
+}
+
+// migrateSettings upgrades settings document of older format to the current settingsVersion in place,
+// performing other needed changes in the database.
+// It returns true if the document was changed and should be stored.
+func migrateSettings(ctx context.Context, querier pgxtype.Querier, db string, settings *types.Document) (bool, error) {
+	var version int32
+
+	if v, err := settings.Get("version"); err == nil {
+		var ok bool
+		if version, ok = v.(int32); !ok {
+			return false, lazyerrors.Errorf("invalid settings version: %[1]T (%[1]v)", v)
+		}
+	}
+
+	switch {
+	case version == settingsVersion:
+		return false, nil
+	case version > settingsVersion:
+		return false, lazyerrors.Errorf("unsupported settings version %d, expected up to %d", version, settingsVersion)
+	}
+
+	for ; version < settingsVersion; version++ {
+		switch version {
+		case 0:
+			// version 0 documents may lack collections mapping
+			if !settings.Has("collections") {
+				must.NoError(settings.Set("collections", must.NotFail(types.NewDocument())))
+			}
+
+		case 1:
+			if err := migrateIDIndexes(ctx, querier, db, settings); err != nil {
+				return false, lazyerrors.Error(err)
+			}
+		}
+	}
+
+	must.NoError(settings.Set("version", version))
+
+	return true, nil
+}
+
+// migrateIDIndexes creates unique _id indexes for collections created before they were introduced.
+//
+// Tables that already contain documents with duplicate _id values are left as is.
+func migrateIDIndexes(ctx context.Context, querier pgxtype.Querier, db string, settings *types.Document) error {
+	collections, ok := must.NotFail(settings.Get("collections")).(*types.Document)
+	if !ok {
+		return lazyerrors.Errorf("invalid settings document")
 	}
 
-	return settings, nil
+	existing, err := tables(ctx, querier, db)
+	if err != nil {
+		return lazyerrors.Error(err)
+	}
+
+	for _, collection := range collections.Keys() {
+		table, ok := must.NotFail(collections.Get(collection)).(string)
+		if !ok || !slices.Contains(existing, table) {
+			continue
+		}
+
+		// check duplicates first, failed index creation would abort the current transaction
+		sql := `SELECT EXISTS (SELECT 1 FROM ` + pgx.Identifier{db, table}.Sanitize() +
+			` WHERE _jsonb ? '_id' GROUP BY _jsonb->'_id' HAVING count(*) > 1)`
+
+		var duplicates bool
+		if err = querier.QueryRow(ctx, sql).Scan(&duplicates); err != nil {
+			return lazyerrors.Error(err)
+		}
+
+		if duplicates {
+			continue
+		}
+
+		if err = createIDIndex(ctx, querier, db, table); err != nil {
+			return lazyerrors.Error(err)
+		}
+	}
+
+	return nil
 }
 
 // updateSettingsTable updates FerretDB settings table.
@@ -178,7 +305,7 @@ func updateSettingsTable(ctx context.Context, querier pgxtype.Querier, db string
 	return err
 }
 
-// removeTableFromSettings removes collection from FerretDB settings table.
+// removeTableFromSettings removes collection and its index metadata from FerretDB settings table.
 func removeTableFromSettings(ctx context.Context, querier pgxtype.Querier, db, collection string) error {
 	settings, err := getSettingsTable(ctx, querier, db)
 	if err != nil {
@@ -197,6 +324,7 @@ func removeTableFromSettings(ctx context.Context, querier pgxtype.Querier, db, c
 	collections.Remove(collection)
 
 	must.NoError(settings.Set("collections", collections))
+	setSettingsIndexes(settings, collection, nil)
 
 	if err := updateSettingsTable(ctx, querier, db, settings); err != nil {
 		return lazyerrors.Error(err)
@@ -0,0 +1,159 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pgdb
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/jackc/pgx/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+
+	"github.com/FerretDB/FerretDB/internal/fjson"
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+	"github.com/FerretDB/FerretDB/internal/util/testutil"
+)
+
+func TestMigrateSettings(t *testing.T) {
+	t.Parallel()
+
+	ctx := testutil.Ctx(t)
+	pool := getPool(ctx, t, zaptest.NewLogger(t))
+	dbName := testutil.DatabaseName(t)
+
+	t.Cleanup(func() {
+		pool.DropDatabase(ctx, dbName)
+	})
+
+	pool.DropDatabase(ctx, dbName)
+	require.NoError(t, CreateDatabase(ctx, pool, dbName))
+
+	// store version 0 document
+	old := must.NotFail(types.NewDocument("collections", must.NotFail(types.NewDocument("foo", "foo_1234"))))
+	sql := `UPDATE ` + pgx.Identifier{dbName, settingsTableName}.Sanitize() + ` SET settings = $1`
+	_, err := pool.Exec(ctx, sql, must.NotFail(fjson.Marshal(old)))
+	require.NoError(t, err)
+
+	settings, err := getSettingsTable(ctx, pool, dbName)
+	require.NoError(t, err)
+	assert.Equal(t, settingsVersion, must.NotFail(settings.Get("version")))
+	assert.Equal(t, "foo_1234", must.NotFail(settings.GetByPath(types.NewPathFromString("collections.foo"))))
+
+	// check that the document was upgraded in place
+	var b []byte
+	err = pool.QueryRow(ctx, `SELECT settings FROM `+pgx.Identifier{dbName, settingsTableName}.Sanitize()).Scan(&b)
+	require.NoError(t, err)
+
+	stored, err := fjson.Unmarshal(b)
+	require.NoError(t, err)
+	assert.Equal(t, settings, stored)
+
+	// newer versions are not supported
+	_, err = migrateSettings(ctx, pool, dbName, must.NotFail(types.NewDocument("version", settingsVersion+1)))
+	assert.Error(t, err)
+}
+
+func TestMigrateSettingsConcurrentCreateCollection(t *testing.T) {
+	t.Parallel()
+
+	ctx := testutil.Ctx(t)
+	pool := getPool(ctx, t, zaptest.NewLogger(t))
+	dbName := testutil.DatabaseName(t)
+	collectionName := testutil.CollectionName(t)
+
+	t.Cleanup(func() {
+		pool.DropDatabase(ctx, dbName)
+	})
+
+	pool.DropDatabase(ctx, dbName)
+	require.NoError(t, CreateDatabase(ctx, pool, dbName))
+
+	sql := `UPDATE ` + pgx.Identifier{dbName, settingsTableName}.Sanitize() + ` SET settings = $1`
+
+	for i := 0; i < 10; i++ {
+		// store version 0 document with all collections created so far
+		collections := must.NotFail(types.NewDocument())
+		for j := 0; j < i; j++ {
+			name := fmt.Sprintf("%s_%d", collectionName, j)
+			must.NoError(collections.Set(name, formatCollectionName(name)))
+		}
+
+		old := must.NotFail(types.NewDocument("collections", collections))
+		_, err := pool.Exec(ctx, sql, must.NotFail(fjson.Marshal(old)))
+		require.NoError(t, err)
+
+		name := fmt.Sprintf("%s_%d", collectionName, i)
+
+		var wg sync.WaitGroup
+		for j := 0; j < 5; j++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+
+				_, err := getSettingsTable(ctx, pool, dbName)
+				assert.NoError(t, err)
+			}()
+		}
+
+		require.NoError(t, CreateCollection(ctx, pool, dbName, name))
+		wg.Wait()
+
+		// the collection created concurrently with migrations is not lost
+		settings, err := getSettingsTable(ctx, pool, dbName)
+		require.NoError(t, err)
+		assert.Equal(t, settingsVersion, must.NotFail(settings.Get("version")))
+		assert.True(t, must.NotFail(settings.Get("collections")).(*types.Document).Has(name), name)
+	}
+}
+
+func TestMigrateSettingsIDIndex(t *testing.T) {
+	t.Parallel()
+
+	ctx := testutil.Ctx(t)
+	pool := getPool(ctx, t, zaptest.NewLogger(t))
+	dbName := testutil.DatabaseName(t)
+	collectionName := testutil.CollectionName(t)
+
+	t.Cleanup(func() {
+		pool.DropDatabase(ctx, dbName)
+	})
+
+	pool.DropDatabase(ctx, dbName)
+	require.NoError(t, CreateDatabase(ctx, pool, dbName))
+	require.NoError(t, CreateCollection(ctx, pool, dbName, collectionName))
+
+	// make it look like a collection created before unique _id indexes were introduced
+	table := formatCollectionName(collectionName)
+	_, err := pool.Exec(ctx, `DROP INDEX `+pgx.Identifier{dbName, idIndexName(table)}.Sanitize())
+	require.NoError(t, err)
+
+	old := must.NotFail(types.NewDocument(
+		"version", int32(1),
+		"collections", must.NotFail(types.NewDocument(collectionName, table)),
+	))
+	sql := `UPDATE ` + pgx.Identifier{dbName, settingsTableName}.Sanitize() + ` SET settings = $1`
+	_, err = pool.Exec(ctx, sql, must.NotFail(fjson.Marshal(old)))
+	require.NoError(t, err)
+
+	doc := must.NotFail(types.NewDocument("_id", int32(1)))
+	require.NoError(t, InsertDocument(ctx, pool, dbName, collectionName, doc))
+
+	err = InsertDocument(ctx, pool, dbName, collectionName, doc)
+	require.ErrorIs(t, err, ErrDuplicateKey)
+}
@@ -0,0 +1,65 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pgdb
+
+import (
+	"context"
+
+	"github.com/jackc/pgtype/pgxtype"
+	"github.com/jackc/pgx/v4"
+
+	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
+)
+
+// CollStats describes statistics for a collection.
+type CollStats struct {
+	CountObjects int64 // number of documents
+	SizeData     int64 // sum of sizes of stored jsonb documents
+	SizeTotal    int64 // size of the table with its indexes and TOAST data
+	SizeIndexes  int64 // size of the table's indexes
+}
+
+// CollectionStats returns statistics for the given FerretDB collection.
+//
+// It returns (possibly wrapped) ErrTableNotExist if FerretDB database / PostgreSQL schema
+// or FerretDB collection / PostgreSQL table does not exist.
+func CollectionStats(ctx context.Context, querier pgxtype.Querier, db, collection string) (*CollStats, error) {
+	exists, err := CollectionExists(ctx, querier, db, collection)
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	if !exists {
+		return nil, lazyerrors.Error(ErrTableNotExist)
+	}
+
+	table, err := getTableName(ctx, querier, db, collection)
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	name := pgx.Identifier{db, table}.Sanitize()
+	sql := `SELECT COUNT(*), COALESCE(SUM(pg_column_size(_jsonb)), 0), ` +
+		`pg_total_relation_size($1::regclass), pg_indexes_size($1::regclass) ` +
+		`FROM ` + name
+
+	var res CollStats
+	err = querier.QueryRow(ctx, sql, name).Scan(&res.CountObjects, &res.SizeData, &res.SizeTotal, &res.SizeIndexes)
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	return &res, nil
+}
@@ -0,0 +1,65 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pgdb
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+	"github.com/FerretDB/FerretDB/internal/util/testutil"
+)
+
+func TestCollectionStats(t *testing.T) {
+	t.Parallel()
+
+	ctx := testutil.Ctx(t)
+
+	pool := getPool(ctx, t, zaptest.NewLogger(t))
+	dbName := testutil.DatabaseName(t)
+	collectionName := testutil.CollectionName(t)
+
+	t.Cleanup(func() {
+		pool.DropDatabase(ctx, dbName)
+	})
+
+	pool.DropDatabase(ctx, dbName)
+
+	// non-existing database
+	_, err := CollectionStats(ctx, pool, dbName, collectionName)
+	require.ErrorIs(t, err, ErrTableNotExist)
+
+	require.NoError(t, CreateDatabase(ctx, pool, dbName))
+
+	// non-existing collection
+	_, err = CollectionStats(ctx, pool, dbName, collectionName)
+	require.ErrorIs(t, err, ErrTableNotExist)
+
+	for i := int32(1); i <= 3; i++ {
+		doc := must.NotFail(types.NewDocument("_id", i, "v", "foo"))
+		require.NoError(t, InsertDocument(ctx, pool, dbName, collectionName, doc))
+	}
+
+	stats, err := CollectionStats(ctx, pool, dbName, collectionName)
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), stats.CountObjects)
+	assert.Positive(t, stats.SizeData)
+	assert.Positive(t, stats.SizeIndexes)
+	assert.Greater(t, stats.SizeTotal, stats.SizeIndexes)
+}
@@ -16,22 +16,47 @@ package pgdb
 
 import (
 	"context"
+	"errors"
 	"strings"
 
+	"github.com/jackc/pgconn"
+	"github.com/jackc/pgerrcode"
 	"github.com/jackc/pgx/v4"
 
 	"github.com/FerretDB/FerretDB/internal/fjson"
 	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
 	"github.com/FerretDB/FerretDB/internal/util/must"
 )
 
 // SetDocumentByID sets a document by its ID.
+//
+// If another document with the same unique index key already exists, it returns (possibly wrapped) *DuplicateKeyError.
 func SetDocumentByID(ctx context.Context, tx pgx.Tx, sp *SQLParam, id any, doc *types.Document) (int64, error) {
+	return setDocument(ctx, tx, sp, id, nil, doc)
+}
+
+// SetDocumentIfUnchanged replaces the old document with the new one only if the stored document
+// is still the same as the old one. It returns 0 if it was changed or removed concurrently.
+//
+// If another document with the same unique index key already exists, it returns (possibly wrapped) *DuplicateKeyError.
+func SetDocumentIfUnchanged(ctx context.Context, tx pgx.Tx, sp *SQLParam, old, doc *types.Document) (int64, error) {
+	return setDocument(ctx, tx, sp, must.NotFail(old.Get("_id")), old, doc)
+}
+
+// setDocument sets a document by its ID, and, if old is not nil, only if the stored document is equal to it.
+func setDocument(ctx context.Context, tx pgx.Tx, sp *SQLParam, id any, old, doc *types.Document) (int64, error) {
 	table, err := getTableName(ctx, tx, sp.DB, sp.Collection)
 	if err != nil {
 		return 0, err
 	}
 
+	// indexes are loaded beforehand, the transaction can't be used after the unique violation
+	indexes, err := Indexes(ctx, tx, sp.DB, sp.Collection)
+	if err != nil {
+		return 0, err
+	}
+
 	sql := "UPDATE "
 
 	if sp.Comment != "" {
@@ -42,9 +67,21 @@ func SetDocumentByID(ctx context.Context, tx pgx.Tx, sp *SQLParam, id any, doc *
 	}
 
 	sql += pgx.Identifier{sp.DB, table}.Sanitize() + " SET _jsonb = $1 WHERE _jsonb->'_id' = $2"
+	args := []any{must.NotFail(fjson.Marshal(doc)), must.NotFail(fjson.Marshal(id))}
 
-	tag, err := tx.Exec(ctx, sql, must.NotFail(fjson.Marshal(doc)), must.NotFail(fjson.Marshal(id)))
+	// the WHERE clause is re-evaluated for the latest row version if it was updated concurrently
+	if old != nil {
+		sql += " AND _jsonb = $3"
+		args = append(args, must.NotFail(fjson.Marshal(old)))
+	}
+
+	tag, err := tx.Exec(ctx, sql, args...)
 	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == pgerrcode.UniqueViolation {
+			return 0, lazyerrors.Error(duplicateKeyError(table, pgErr.ConstraintName, indexes, doc))
+		}
+
 		return 0, err
 	}
 
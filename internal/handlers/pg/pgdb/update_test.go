@@ -0,0 +1,72 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pgdb
+
+import (
+	"testing"
+
+	"github.com/jackc/pgx/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+	"github.com/FerretDB/FerretDB/internal/util/testutil"
+)
+
+func TestSetDocumentIfUnchanged(t *testing.T) {
+	t.Parallel()
+
+	ctx := testutil.Ctx(t)
+
+	pool := getPool(ctx, t, zaptest.NewLogger(t))
+	dbName := testutil.DatabaseName(t)
+	collectionName := testutil.CollectionName(t)
+
+	t.Cleanup(func() {
+		pool.DropDatabase(ctx, dbName)
+	})
+
+	pool.DropDatabase(ctx, dbName)
+
+	sp := &SQLParam{DB: dbName, Collection: collectionName}
+	doc := must.NotFail(types.NewDocument("_id", types.NewObjectID(), "status", "pending", "v", int64(42)))
+	require.NoError(t, InsertDocument(ctx, pool, dbName, collectionName, doc))
+
+	running := doc.DeepCopy()
+	must.NoError(running.Set("status", "running"))
+
+	done := doc.DeepCopy()
+	must.NoError(done.Set("status", "done"))
+
+	err := pool.InTransaction(ctx, func(tx pgx.Tx) error {
+		updated, err := SetDocumentIfUnchanged(ctx, tx, sp, doc, running)
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), updated)
+
+		// the stored document is not the same as the old one anymore
+		updated, err = SetDocumentIfUnchanged(ctx, tx, sp, doc, done)
+		require.NoError(t, err)
+		assert.Equal(t, int64(0), updated)
+
+		updated, err = SetDocumentIfUnchanged(ctx, tx, sp, running, done)
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), updated)
+
+		return nil
+	})
+	require.NoError(t, err)
+}
@@ -0,0 +1,383 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pgdb
+
+import (
+	"context"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/jackc/pgtype/pgxtype"
+
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+)
+
+// prepareWhereClause returns WHERE clause (without the WHERE keyword) and its arguments
+// for the given filter, or an empty string if nothing could be pushed down.
+//
+// The WHERE clause is a superset of the filter: it may select more documents than the filter matches,
+// but never less. Selected documents are always filtered again in memory.
+// Parts of the filter that can't be pushed down safely are just skipped;
+// all other conditions, including equality conditions for several fields, are combined with AND.
+func prepareWhereClause(ctx context.Context, querier pgxtype.Querier, db, table string, filter *types.Document) (string, []any, error) {
+	if filter == nil {
+		return "", nil, nil
+	}
+
+	// comparisons without indexes are pushed down only if the whole filter could be pushed down
+	safe := isFilterPushdownSafe(filter)
+
+	var p Placeholder
+	var conds []string
+	var args []any
+
+	for _, key := range filter.Keys() {
+		// skip top-level operators like $and
+		if strings.HasPrefix(key, "$") {
+			continue
+		}
+
+		value := must.NotFail(filter.Get(key))
+
+		if strings.ContainsRune(key, '.') {
+			if cond, condArgs := dottedEqualityCondition(&p, key, equalityValue(value)); cond != "" {
+				conds = append(conds, cond)
+				args = append(args, condArgs...)
+			}
+
+			continue
+		}
+
+		if cond, condArgs := scalarEqualityCondition(&p, key, equalityValue(value)); cond != "" {
+			conds = append(conds, cond)
+			args = append(args, condArgs...)
+
+			continue
+		}
+
+		expr, ok := value.(*types.Document)
+		if !ok {
+			if !safe {
+				continue
+			}
+
+			if cond, condArgs := comparisonCondition(&p, key, "$eq", value); cond != "" {
+				conds = append(conds, cond)
+				args = append(args, condArgs...)
+			}
+
+			continue
+		}
+
+		var indexChecked, indexExists bool
+		for _, op := range expr.Keys() {
+			operand := must.NotFail(expr.Get(op))
+
+			if sqlOp, ok := numericRangeOperators[op]; ok {
+				if v, ok := wholeNumber(operand); ok {
+					// check the index only once per field and only if it could be used
+					if !indexChecked {
+						var err error
+						if indexExists, err = numericIndexExists(ctx, querier, db, table, key); err != nil {
+							return "", nil, lazyerrors.Error(err)
+						}
+
+						indexChecked = true
+					}
+
+					if indexExists {
+						conds = append(conds, numericFieldExpr(key)+` `+sqlOp+` `+p.Next())
+						args = append(args, v)
+
+						continue
+					}
+				}
+			}
+
+			if !safe {
+				continue
+			}
+
+			if cond, condArgs := comparisonCondition(&p, key, op, operand); cond != "" {
+				conds = append(conds, cond)
+				args = append(args, condArgs...)
+			}
+		}
+	}
+
+	return strings.Join(conds, " AND "), args, nil
+}
+
+// IsFilterExact reports whether the WHERE clause built for the given filter
+// selects exactly the documents matching it, not a superset of them.
+// In that case, LIMIT and OFFSET could be applied in SQL.
+//
+// That is true for empty filters and filters containing only {field: value} and {field: {$eq: value}}
+// conditions for top-level fields, where values are strings or booleans.
+func IsFilterExact(filter *types.Document) bool {
+	if filter == nil {
+		return true
+	}
+
+	for _, key := range filter.Keys() {
+		if strings.HasPrefix(key, "$") || strings.ContainsRune(key, '.') {
+			return false
+		}
+
+		switch equalityValue(must.NotFail(filter.Get(key))).(type) {
+		case string, bool:
+			// nothing
+		default:
+			return false
+		}
+	}
+
+	return true
+}
+
+// equalityValue returns the value compared by {field: {$eq: value}} condition,
+// or the given value as is for {field: value} condition.
+func equalityValue(value any) any {
+	expr, ok := value.(*types.Document)
+	if !ok || expr.Len() != 1 {
+		return value
+	}
+
+	if v, err := expr.Get("$eq"); err == nil {
+		return v
+	}
+
+	return value
+}
+
+// scalarEqualityCondition returns SQL condition and its arguments for {field: value} filter
+// of the top-level field, or an empty string if it can't be pushed down.
+//
+// Only strings and booleans are supported.
+// They are stored as is, so the value could be compared with the field value
+// and with elements of the array field value; nested arrays are not traversed, as in the filter itself.
+// The condition is exact: it selects only matching documents.
+func scalarEqualityCondition(p *Placeholder, key string, value any) (string, []any) {
+	var cast string
+	switch value.(type) {
+	case string:
+		cast = `text`
+	case bool:
+		cast = `boolean`
+	default:
+		return "", nil
+	}
+
+	// casts are needed because jsonb operators are also defined for integer arguments
+	field, v := `_jsonb->`+p.Next()+`::text`, `to_jsonb(`+p.Next()+`::`+cast+`)`
+	cond := `(` + field + ` = ` + v +
+		` OR CASE WHEN jsonb_typeof(` + field + `) = 'array'` +
+		` THEN EXISTS (SELECT 1 FROM jsonb_array_elements(` + field + `) AS e WHERE e = ` + v + `)` +
+		` ELSE false END)`
+
+	return cond, []any{key, value}
+}
+
+// dottedEqualityCondition returns SQL condition and its arguments for {a.b: value} filter,
+// or an empty string if it can't be pushed down.
+//
+// Only paths of two non-numeric elements and string values are supported;
+// deeper paths, array indexes, and other values are filtered in memory only.
+// Strings are stored as is, so the text value could be compared directly.
+// If a or a.b is an array, the document is selected anyway,
+// because elements of those arrays could match the filter.
+func dottedEqualityCondition(p *Placeholder, key string, value any) (string, []any) {
+	s, ok := value.(string)
+	if !ok {
+		return "", nil
+	}
+
+	path := strings.Split(key, ".")
+	if len(path) != 2 {
+		return "", nil
+	}
+
+	for _, elem := range path {
+		if elem == "" || strings.HasPrefix(elem, "$") {
+			return "", nil
+		}
+
+		if _, err := strconv.Atoi(elem); err == nil {
+			return "", nil
+		}
+	}
+
+	// casts are needed because jsonb operators are also defined for integer arguments
+	top, sub, v := p.Next()+`::text`, p.Next()+`::text`, p.Next()
+	cond := `(_jsonb->` + top + `->>` + sub + ` = ` + v +
+		` OR jsonb_typeof(_jsonb->` + top + `) = 'array'` +
+		` OR jsonb_typeof(_jsonb->` + top + `->` + sub + `) = 'array')`
+
+	return cond, []any{path[0], path[1], s}
+}
+
+// isFilterPushdownSafe reports whether all conditions of the given filter could be pushed down.
+//
+// That is true for filters containing only {field: value} conditions and
+// {field: {$op: value, ...}} conditions with $eq, $gt, $gte, $lt, and $lte operators for top-level fields,
+// where values are strings, booleans (only for equality), or finite numbers.
+func isFilterPushdownSafe(filter *types.Document) bool {
+	for _, key := range filter.Keys() {
+		if strings.HasPrefix(key, "$") || strings.ContainsRune(key, '.') {
+			return false
+		}
+
+		expr, ok := must.NotFail(filter.Get(key)).(*types.Document)
+		if !ok {
+			if !isComparisonPushdownSafe("$eq", must.NotFail(filter.Get(key))) {
+				return false
+			}
+
+			continue
+		}
+
+		if expr.Len() == 0 {
+			return false
+		}
+
+		for _, op := range expr.Keys() {
+			if !isComparisonPushdownSafe(op, must.NotFail(expr.Get(op))) {
+				return false
+			}
+		}
+	}
+
+	return true
+}
+
+// isComparisonPushdownSafe reports whether {field: {op: value}} condition could be pushed down.
+func isComparisonPushdownSafe(op string, value any) bool {
+	if _, ok := comparisonOperators[op]; !ok {
+		return false
+	}
+
+	switch value.(type) {
+	case string:
+		return true
+	case bool:
+		return op == "$eq"
+	default:
+		_, ok := comparableNumber(value)
+		return ok
+	}
+}
+
+// comparisonCondition returns SQL condition and its arguments for {field: {op: value}} filter
+// of the top-level field, or an empty string if it can't be pushed down.
+//
+// Only strings and finite numbers are supported.
+// Strings are compared byte-wise, as in MongoDB, with the "C" collation.
+// Numbers of all types are compared as numeric values:
+// int32 values are stored as JSON numbers, int64 values as {"$l": "string"},
+// and double values as {"$f": number} or {"$f": "string"} for special values like NaN that are always selected.
+// Documents with array field values are always selected, as elements of those arrays could match the filter.
+// The condition is not exact: it selects a superset of matching documents.
+func comparisonCondition(p *Placeholder, key, op string, value any) (string, []any) {
+	sqlOp, ok := comparisonOperators[op]
+	if !ok {
+		return "", nil
+	}
+
+	if s, ok := value.(string); ok {
+		k, v := p.Next()+`::text`, p.Next()+`::text`
+		cond := `CASE jsonb_typeof(_jsonb->` + k + `)` +
+			` WHEN 'string' THEN (_jsonb->>` + k + `) COLLATE "C" ` + sqlOp + ` ` + v +
+			` WHEN 'array' THEN true` +
+			` ELSE false END`
+
+		return cond, []any{key, s}
+	}
+
+	n, ok := comparableNumber(value)
+	if !ok {
+		return "", nil
+	}
+
+	k, v := p.Next()+`::text`, p.Next()+`::numeric`
+	field := `_jsonb->` + k
+	compare := func(expr string) string {
+		return `(` + expr + `)::numeric ` + sqlOp + ` ` + v
+	}
+
+	cond := `CASE jsonb_typeof(` + field + `)` +
+		` WHEN 'number' THEN ` + compare(`_jsonb->>`+k) +
+		` WHEN 'object' THEN CASE` +
+		` WHEN ` + field + ` ? '$l' THEN ` + compare(field+`->>'$l'`) +
+		` WHEN jsonb_typeof(` + field + `->'$f') = 'number' THEN ` + compare(field+`->>'$f'`) +
+		` ELSE ` + field + ` ? '$f' END` +
+		` WHEN 'array' THEN true` +
+		` ELSE false END`
+
+	return cond, []any{key, n}
+}
+
+// comparisonOperators maps comparison operators that could be pushed down to SQL operators.
+var comparisonOperators = map[string]string{
+	"$eq":  "=",
+	"$gt":  ">",
+	"$gte": ">=",
+	"$lt":  "<",
+	"$lte": "<=",
+}
+
+// comparableNumber returns the given value as int64 (if it has no fractional part) or float64
+// if it is a finite number that could be compared with numeric values.
+func comparableNumber(v any) (any, bool) {
+	if n, ok := wholeNumber(v); ok {
+		return n, true
+	}
+
+	f, ok := v.(float64)
+	if !ok || math.IsInf(f, 0) || math.IsNaN(f) {
+		return nil, false
+	}
+
+	return f, true
+}
+
+// numericRangeOperators maps range operators that could be pushed down for numeric indexes to SQL operators.
+var numericRangeOperators = map[string]string{
+	"$gt":  ">",
+	"$gte": ">=",
+	"$lt":  "<",
+	"$lte": "<=",
+}
+
+// wholeNumber returns the given value as int64 if it is a number without fractional part
+// that could be compared with bigint values.
+func wholeNumber(v any) (int64, bool) {
+	switch v := v.(type) {
+	case int32:
+		return int64(v), true
+	case int64:
+		return v, true
+	case float64:
+		if v != math.Trunc(v) || math.IsInf(v, 0) || math.IsNaN(v) || v < math.MinInt64 || v >= math.MaxInt64 {
+			return 0, false
+		}
+
+		return int64(v), true
+	default:
+		return 0, false
+	}
+}
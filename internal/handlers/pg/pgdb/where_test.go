@@ -0,0 +1,648 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pgdb
+
+import (
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+
+	"github.com/FerretDB/FerretDB/internal/handlers/common"
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+	"github.com/FerretDB/FerretDB/internal/util/testutil"
+)
+
+func TestDottedEqualityCondition(t *testing.T) {
+	t.Parallel()
+
+	for name, tc := range map[string]struct {
+		key   string
+		value any
+		cond  string
+		args  []any
+	}{
+		"TwoElements": {
+			key:   "a.b",
+			value: "foo",
+			cond: `(_jsonb->$1::text->>$2::text = $3` +
+				` OR jsonb_typeof(_jsonb->$1::text) = 'array'` +
+				` OR jsonb_typeof(_jsonb->$1::text->$2::text) = 'array')`,
+			args: []any{"a", "b", "foo"},
+		},
+		"ThreeElements": {
+			key:   "a.b.c",
+			value: "foo",
+		},
+		"ArrayIndex": {
+			key:   "a.0",
+			value: "foo",
+		},
+		"Number": {
+			key:   "a.b",
+			value: int32(1),
+		},
+		"Document": {
+			key:   "a.b",
+			value: must.NotFail(types.NewDocument("$eq", "foo")),
+		},
+	} {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			var p Placeholder
+			cond, args := dottedEqualityCondition(&p, tc.key, tc.value)
+			assert.Equal(t, tc.cond, cond)
+			assert.Equal(t, tc.args, args)
+		})
+	}
+}
+
+func TestDottedEqualityPushdown(t *testing.T) {
+	t.Parallel()
+
+	ctx := testutil.Ctx(t)
+
+	pool := getPool(ctx, t, zaptest.NewLogger(t))
+	dbName := testutil.DatabaseName(t)
+	collectionName := testutil.CollectionName(t)
+
+	t.Cleanup(func() {
+		pool.DropDatabase(ctx, dbName)
+	})
+
+	pool.DropDatabase(ctx, dbName)
+	require.NoError(t, CreateDatabase(ctx, pool, dbName))
+
+	docs := []*types.Document{
+		must.NotFail(types.NewDocument("_id", "match", "a", must.NotFail(types.NewDocument("b", "foo")))),
+		must.NotFail(types.NewDocument("_id", "other", "a", must.NotFail(types.NewDocument("b", "bar")))),
+		must.NotFail(types.NewDocument("_id", "array", "a", must.NotFail(types.NewArray(
+			must.NotFail(types.NewDocument("b", "foo")),
+		)))),
+		must.NotFail(types.NewDocument("_id", "array-nested", "a", must.NotFail(types.NewDocument(
+			"b", must.NotFail(types.NewArray("bar", "foo")),
+		)))),
+		must.NotFail(types.NewDocument("_id", "number", "a", must.NotFail(types.NewDocument("b", int32(1))))),
+		must.NotFail(types.NewDocument("_id", "missing")),
+	}
+
+	for _, doc := range docs {
+		require.NoError(t, InsertDocument(ctx, pool, dbName, collectionName, doc))
+	}
+
+	filter := must.NotFail(types.NewDocument("a.b", "foo"))
+
+	sp := SQLParam{DB: dbName, Collection: collectionName, Filter: filter}
+	q, _, err := buildQuery(ctx, pool, &sp)
+	require.NoError(t, err)
+	assert.Contains(t, q, `WHERE (_jsonb->$1::text->>$2::text = $3`)
+
+	fetchedChan, closeFetch, err := pool.QueryDocuments(ctx, pool, sp)
+	require.NoError(t, err)
+	defer closeFetch()
+
+	var pushedDown []any
+	for fetched := range fetchedChan {
+		require.NoError(t, fetched.Err)
+
+		for _, doc := range fetched.Docs {
+			pushedDown = append(pushedDown, must.NotFail(doc.Get("_id")))
+		}
+	}
+
+	// for this data set, pushdown selects exactly the same documents as matched in memory
+	var expected []any
+	for _, doc := range docs {
+		if must.NotFail(common.FilterDocument(doc, filter)) {
+			expected = append(expected, must.NotFail(doc.Get("_id")))
+		}
+	}
+
+	assert.ElementsMatch(t, expected, pushedDown)
+	assert.ElementsMatch(t, []any{"match", "array", "array-nested"}, pushedDown)
+}
+
+func TestScalarEqualityCondition(t *testing.T) {
+	t.Parallel()
+
+	var p Placeholder
+	cond, args := scalarEqualityCondition(&p, "v", "foo")
+	expected := `(_jsonb->$1::text = to_jsonb($2::text)` +
+		` OR CASE WHEN jsonb_typeof(_jsonb->$1::text) = 'array'` +
+		` THEN EXISTS (SELECT 1 FROM jsonb_array_elements(_jsonb->$1::text) AS e WHERE e = to_jsonb($2::text))` +
+		` ELSE false END)`
+	assert.Equal(t, expected, cond)
+	assert.Equal(t, []any{"v", "foo"}, args)
+
+	p = 0
+	cond, args = scalarEqualityCondition(&p, "v", true)
+	expected = `(_jsonb->$1::text = to_jsonb($2::boolean)` +
+		` OR CASE WHEN jsonb_typeof(_jsonb->$1::text) = 'array'` +
+		` THEN EXISTS (SELECT 1 FROM jsonb_array_elements(_jsonb->$1::text) AS e WHERE e = to_jsonb($2::boolean))` +
+		` ELSE false END)`
+	assert.Equal(t, expected, cond)
+	assert.Equal(t, []any{"v", true}, args)
+
+	cond, args = scalarEqualityCondition(&p, "v", int32(1))
+	assert.Empty(t, cond)
+	assert.Nil(t, args)
+}
+
+func TestIsFilterExact(t *testing.T) {
+	t.Parallel()
+
+	for name, tc := range map[string]struct {
+		filter   *types.Document
+		expected bool
+	}{
+		"Nil": {
+			expected: true,
+		},
+		"Empty": {
+			filter:   must.NotFail(types.NewDocument()),
+			expected: true,
+		},
+		"Strings": {
+			filter:   must.NotFail(types.NewDocument("_id", "foo", "v", "bar")),
+			expected: true,
+		},
+		"Number": {
+			filter: must.NotFail(types.NewDocument("v", int32(1))),
+		},
+		"Dotted": {
+			filter: must.NotFail(types.NewDocument("v.foo", "bar")),
+		},
+		"Bool": {
+			filter:   must.NotFail(types.NewDocument("v", true)),
+			expected: true,
+		},
+		"Eq": {
+			filter:   must.NotFail(types.NewDocument("v", must.NotFail(types.NewDocument("$eq", "foo")))),
+			expected: true,
+		},
+		"EqNumber": {
+			filter: must.NotFail(types.NewDocument("v", must.NotFail(types.NewDocument("$eq", int32(1))))),
+		},
+		"Operator": {
+			filter: must.NotFail(types.NewDocument("v", must.NotFail(types.NewDocument("$ne", "foo")))),
+		},
+		"TopLevelOperator": {
+			filter: must.NotFail(types.NewDocument("$comment", "foo")),
+		},
+	} {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, tc.expected, IsFilterExact(tc.filter))
+		})
+	}
+}
+
+func TestStringEqualityPushdownLimit(t *testing.T) {
+	t.Parallel()
+
+	ctx := testutil.Ctx(t)
+
+	pool := getPool(ctx, t, zaptest.NewLogger(t))
+	dbName := testutil.DatabaseName(t)
+	collectionName := testutil.CollectionName(t)
+
+	t.Cleanup(func() {
+		pool.DropDatabase(ctx, dbName)
+	})
+
+	pool.DropDatabase(ctx, dbName)
+	require.NoError(t, CreateDatabase(ctx, pool, dbName))
+
+	docs := []*types.Document{
+		must.NotFail(types.NewDocument("_id", "match1", "v", "foo")),
+		must.NotFail(types.NewDocument("_id", "other", "v", "bar")),
+		must.NotFail(types.NewDocument("_id", "match2", "v", must.NotFail(types.NewArray("bar", "foo")))),
+		must.NotFail(types.NewDocument("_id", "array-nested", "v", must.NotFail(types.NewArray(
+			must.NotFail(types.NewArray("foo")),
+		)))),
+		must.NotFail(types.NewDocument("_id", "document", "v", must.NotFail(types.NewDocument("foo", "foo")))),
+		must.NotFail(types.NewDocument("_id", "match3", "v", "foo")),
+		must.NotFail(types.NewDocument("_id", "missing")),
+	}
+
+	for _, doc := range docs {
+		require.NoError(t, InsertDocument(ctx, pool, dbName, collectionName, doc))
+	}
+
+	filter := must.NotFail(types.NewDocument("v", "foo"))
+	require.True(t, IsFilterExact(filter))
+
+	fetchIDs := func(t *testing.T, sp SQLParam) []any {
+		t.Helper()
+
+		fetchedChan, closeFetch, err := pool.QueryDocuments(ctx, pool, sp)
+		require.NoError(t, err)
+		defer closeFetch()
+
+		var res []any
+		for fetched := range fetchedChan {
+			require.NoError(t, fetched.Err)
+
+			for _, doc := range fetched.Docs {
+				res = append(res, must.NotFail(doc.Get("_id")))
+			}
+		}
+
+		return res
+	}
+
+	t.Run("Exact", func(t *testing.T) {
+		var expected []any
+		for _, doc := range docs {
+			if must.NotFail(common.FilterDocument(doc, filter)) {
+				expected = append(expected, must.NotFail(doc.Get("_id")))
+			}
+		}
+
+		sp := SQLParam{DB: dbName, Collection: collectionName, Filter: filter}
+		assert.ElementsMatch(t, expected, fetchIDs(t, sp))
+		assert.ElementsMatch(t, []any{"match1", "match2", "match3"}, expected)
+	})
+
+	t.Run("LimitOffset", func(t *testing.T) {
+		sp := SQLParam{DB: dbName, Collection: collectionName, Filter: filter, Skip: 1, Limit: 1}
+
+		q, _, err := buildQuery(ctx, pool, &sp)
+		require.NoError(t, err)
+		assert.True(t, strings.HasSuffix(q, ` LIMIT 1 OFFSET 1`), q)
+
+		actual := fetchIDs(t, sp)
+		require.Len(t, actual, 1)
+		assert.Contains(t, []any{"match1", "match2", "match3"}, actual[0])
+	})
+}
+
+func TestEqualityPushdownMerged(t *testing.T) {
+	t.Parallel()
+
+	ctx := testutil.Ctx(t)
+
+	pool := getPool(ctx, t, zaptest.NewLogger(t))
+	dbName := testutil.DatabaseName(t)
+	collectionName := testutil.CollectionName(t)
+
+	t.Cleanup(func() {
+		pool.DropDatabase(ctx, dbName)
+	})
+
+	pool.DropDatabase(ctx, dbName)
+	require.NoError(t, CreateDatabase(ctx, pool, dbName))
+
+	docs := []*types.Document{
+		must.NotFail(types.NewDocument("_id", "match", "a", "foo", "b", true, "c", "bar")),
+		must.NotFail(types.NewDocument("_id", "match-array", "a", must.NotFail(types.NewArray("foo")), "b", true, "c", "bar")),
+		must.NotFail(types.NewDocument("_id", "other-a", "a", "baz", "b", true, "c", "bar")),
+		must.NotFail(types.NewDocument("_id", "other-b", "a", "foo", "b", false, "c", "bar")),
+		must.NotFail(types.NewDocument("_id", "other-c", "a", "foo", "b", true, "c", "baz")),
+		must.NotFail(types.NewDocument("_id", "number-b", "a", "foo", "b", int32(1), "c", "bar")),
+		must.NotFail(types.NewDocument("_id", "missing-c", "a", "foo", "b", true)),
+	}
+
+	for _, doc := range docs {
+		require.NoError(t, InsertDocument(ctx, pool, dbName, collectionName, doc))
+	}
+
+	filter := must.NotFail(types.NewDocument(
+		"a", "foo",
+		"b", true,
+		"c", must.NotFail(types.NewDocument("$eq", "bar")),
+	))
+	require.True(t, IsFilterExact(filter))
+
+	sp := SQLParam{DB: dbName, Collection: collectionName, Filter: filter}
+
+	// all equalities are pushed down as a single WHERE clause
+	q, args, err := buildQuery(ctx, pool, &sp)
+	require.NoError(t, err)
+	assert.Equal(t, 2, strings.Count(q, ") AND ("), q)
+	assert.Equal(t, []any{"a", "foo", "b", true, "c", "bar"}, args)
+
+	fetchedChan, closeFetch, err := pool.QueryDocuments(ctx, pool, sp)
+	require.NoError(t, err)
+	defer closeFetch()
+
+	var pushedDown []any
+	for fetched := range fetchedChan {
+		require.NoError(t, fetched.Err)
+
+		for _, doc := range fetched.Docs {
+			pushedDown = append(pushedDown, must.NotFail(doc.Get("_id")))
+		}
+	}
+
+	// pushdown selects exactly the same documents as matched in memory
+	var expected []any
+	for _, doc := range docs {
+		if must.NotFail(common.FilterDocument(doc, filter)) {
+			expected = append(expected, must.NotFail(doc.Get("_id")))
+		}
+	}
+
+	assert.ElementsMatch(t, expected, pushedDown)
+	assert.ElementsMatch(t, []any{"match", "match-array"}, pushedDown)
+}
+
+func TestComparisonCondition(t *testing.T) {
+	t.Parallel()
+
+	for name, tc := range map[string]struct {
+		op    string
+		value any
+		cond  string
+		args  []any
+	}{
+		"String": {
+			op:    "$gt",
+			value: "foo",
+			cond: `CASE jsonb_typeof(_jsonb->$1::text)` +
+				` WHEN 'string' THEN (_jsonb->>$1::text) COLLATE "C" > $2::text` +
+				` WHEN 'array' THEN true` +
+				` ELSE false END`,
+			args: []any{"v", "foo"},
+		},
+		"Int32": {
+			op:    "$lte",
+			value: int32(42),
+			cond: `CASE jsonb_typeof(_jsonb->$1::text)` +
+				` WHEN 'number' THEN (_jsonb->>$1::text)::numeric <= $2::numeric` +
+				` WHEN 'object' THEN CASE` +
+				` WHEN _jsonb->$1::text ? '$l' THEN (_jsonb->$1::text->>'$l')::numeric <= $2::numeric` +
+				` WHEN jsonb_typeof(_jsonb->$1::text->'$f') = 'number' THEN (_jsonb->$1::text->>'$f')::numeric <= $2::numeric` +
+				` ELSE _jsonb->$1::text ? '$f' END` +
+				` WHEN 'array' THEN true` +
+				` ELSE false END`,
+			args: []any{"v", int64(42)},
+		},
+		"Double": {
+			op:    "$eq",
+			value: 4.2,
+			cond: `CASE jsonb_typeof(_jsonb->$1::text)` +
+				` WHEN 'number' THEN (_jsonb->>$1::text)::numeric = $2::numeric` +
+				` WHEN 'object' THEN CASE` +
+				` WHEN _jsonb->$1::text ? '$l' THEN (_jsonb->$1::text->>'$l')::numeric = $2::numeric` +
+				` WHEN jsonb_typeof(_jsonb->$1::text->'$f') = 'number' THEN (_jsonb->$1::text->>'$f')::numeric = $2::numeric` +
+				` ELSE _jsonb->$1::text ? '$f' END` +
+				` WHEN 'array' THEN true` +
+				` ELSE false END`,
+			args: []any{"v", 4.2},
+		},
+		"NaN": {
+			op:    "$gt",
+			value: math.NaN(),
+		},
+		"Bool": {
+			op:    "$gt",
+			value: true,
+		},
+		"UnknownOperator": {
+			op:    "$ne",
+			value: "foo",
+		},
+	} {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			var p Placeholder
+			cond, args := comparisonCondition(&p, "v", tc.op, tc.value)
+			assert.Equal(t, tc.cond, cond)
+			assert.Equal(t, tc.args, args)
+		})
+	}
+}
+
+func TestIsFilterPushdownSafe(t *testing.T) {
+	t.Parallel()
+
+	for name, tc := range map[string]struct {
+		filter   *types.Document
+		expected bool
+	}{
+		"Nil": {
+			expected: true,
+		},
+		"Scalars": {
+			filter:   must.NotFail(types.NewDocument("a", "foo", "b", true, "c", int32(1), "d", 4.2)),
+			expected: true,
+		},
+		"Comparisons": {
+			filter: must.NotFail(types.NewDocument(
+				"a", must.NotFail(types.NewDocument("$gt", int64(1), "$lt", 4.2)),
+				"b", must.NotFail(types.NewDocument("$gte", "foo", "$lte", "foo")),
+				"c", must.NotFail(types.NewDocument("$eq", false)),
+			)),
+			expected: true,
+		},
+		"BoolComparison": {
+			filter: must.NotFail(types.NewDocument("a", must.NotFail(types.NewDocument("$gt", false)))),
+		},
+		"Infinity": {
+			filter: must.NotFail(types.NewDocument("a", must.NotFail(types.NewDocument("$lt", math.Inf(1))))),
+		},
+		"UnsupportedOperator": {
+			filter: must.NotFail(types.NewDocument("a", int32(1), "b", must.NotFail(types.NewDocument("$ne", int32(1))))),
+		},
+		"EmptyDocument": {
+			filter: must.NotFail(types.NewDocument("a", must.NotFail(types.NewDocument()))),
+		},
+		"Null": {
+			filter: must.NotFail(types.NewDocument("a", types.Null)),
+		},
+		"Dotted": {
+			filter: must.NotFail(types.NewDocument("a.b", int32(1))),
+		},
+		"TopLevelOperator": {
+			filter: must.NotFail(types.NewDocument("a", int32(1), "$comment", "foo")),
+		},
+	} {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, tc.expected, isFilterPushdownSafe(tc.filter))
+		})
+	}
+}
+
+// comparisonPushdownDocs returns documents with values of different types for comparison pushdown tests.
+func comparisonPushdownDocs() []*types.Document {
+	return []*types.Document{
+		must.NotFail(types.NewDocument("_id", "int32", "v", int32(42))),
+		must.NotFail(types.NewDocument("_id", "int32-small", "v", int32(1))),
+		must.NotFail(types.NewDocument("_id", "int64", "v", int64(43))),
+		must.NotFail(types.NewDocument("_id", "double", "v", 42.5)),
+		must.NotFail(types.NewDocument("_id", "double-small", "v", 0.5)),
+		must.NotFail(types.NewDocument("_id", "double-nan", "v", math.NaN())),
+		must.NotFail(types.NewDocument("_id", "double-inf", "v", math.Inf(1))),
+		must.NotFail(types.NewDocument("_id", "string", "v", "foo")),
+		must.NotFail(types.NewDocument("_id", "string-upper", "v", "Foo")),
+		must.NotFail(types.NewDocument("_id", "string-unicode", "v", "fóo")),
+		must.NotFail(types.NewDocument("_id", "bool", "v", true)),
+		must.NotFail(types.NewDocument("_id", "null", "v", types.Null)),
+		must.NotFail(types.NewDocument("_id", "array", "v", must.NotFail(types.NewArray(int32(1), int32(100))))),
+		must.NotFail(types.NewDocument("_id", "document", "v", must.NotFail(types.NewDocument("foo", int32(100))))),
+		must.NotFail(types.NewDocument("_id", "missing")),
+	}
+}
+
+func TestComparisonPushdown(t *testing.T) {
+	t.Parallel()
+
+	ctx := testutil.Ctx(t)
+
+	pool := getPool(ctx, t, zaptest.NewLogger(t))
+	dbName := testutil.DatabaseName(t)
+	collectionName := testutil.CollectionName(t)
+
+	t.Cleanup(func() {
+		pool.DropDatabase(ctx, dbName)
+	})
+
+	pool.DropDatabase(ctx, dbName)
+	require.NoError(t, CreateDatabase(ctx, pool, dbName))
+
+	docs := comparisonPushdownDocs()
+	for _, doc := range docs {
+		require.NoError(t, InsertDocument(ctx, pool, dbName, collectionName, doc))
+	}
+
+	for name, tc := range map[string]struct {
+		filter   *types.Document
+		expected []any // pushed down documents
+	}{
+		"GtNumber": {
+			filter:   must.NotFail(types.NewDocument("v", must.NotFail(types.NewDocument("$gt", int32(42))))),
+			expected: []any{"int64", "double", "double-nan", "double-inf", "array"},
+		},
+		"LtNumber": {
+			filter:   must.NotFail(types.NewDocument("v", must.NotFail(types.NewDocument("$lt", 1.5)))),
+			expected: []any{"int32-small", "double-small", "double-nan", "double-inf", "array"},
+		},
+		"EqNumber": {
+			filter:   must.NotFail(types.NewDocument("v", int64(42))),
+			expected: []any{"int32", "double-nan", "double-inf", "array"},
+		},
+		"RangeNumber": {
+			filter:   must.NotFail(types.NewDocument("v", must.NotFail(types.NewDocument("$gte", 42.0, "$lt", int64(43))))),
+			expected: []any{"int32", "double", "double-nan", "double-inf", "array"},
+		},
+		"GtString": {
+			filter:   must.NotFail(types.NewDocument("v", must.NotFail(types.NewDocument("$gt", "foo")))),
+			expected: []any{"string-unicode", "array"},
+		},
+		"LtString": {
+			filter:   must.NotFail(types.NewDocument("v", must.NotFail(types.NewDocument("$lt", "foo")))),
+			expected: []any{"string-upper", "array"},
+		},
+	} {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			require.True(t, isFilterPushdownSafe(tc.filter))
+
+			sp := SQLParam{DB: dbName, Collection: collectionName, Filter: tc.filter}
+
+			fetchedChan, closeFetch, err := pool.QueryDocuments(ctx, pool, sp)
+			require.NoError(t, err)
+			defer closeFetch()
+
+			var pushedDown []any
+			for fetched := range fetchedChan {
+				require.NoError(t, fetched.Err)
+
+				for _, doc := range fetched.Docs {
+					pushedDown = append(pushedDown, must.NotFail(doc.Get("_id")))
+				}
+			}
+
+			assert.ElementsMatch(t, tc.expected, pushedDown)
+
+			// pushdown selects a superset of documents matched in memory
+			for _, doc := range docs {
+				if must.NotFail(common.FilterDocument(doc, tc.filter)) {
+					assert.Contains(t, pushedDown, must.NotFail(doc.Get("_id")))
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkComparisonPushdown(b *testing.B) {
+	ctx := testutil.Ctx(b)
+
+	pool := getPool(ctx, b, zaptest.NewLogger(b))
+	dbName := testutil.DatabaseName(b)
+	collectionName := testutil.CollectionName(b)
+
+	b.Cleanup(func() {
+		pool.DropDatabase(ctx, dbName)
+	})
+
+	pool.DropDatabase(ctx, dbName)
+	require.NoError(b, CreateDatabase(ctx, pool, dbName))
+
+	for i := 0; i < 1000; i++ {
+		doc := must.NotFail(types.NewDocument("_id", int32(i), "v", int32(i)))
+		require.NoError(b, InsertDocument(ctx, pool, dbName, collectionName, doc))
+	}
+
+	filter := must.NotFail(types.NewDocument("v", must.NotFail(types.NewDocument("$gte", int32(990)))))
+
+	for name, sp := range map[string]SQLParam{
+		"Pushdown":   {DB: dbName, Collection: collectionName, Filter: filter},
+		"NoPushdown": {DB: dbName, Collection: collectionName},
+	} {
+		sp := sp
+		b.Run(name, func(b *testing.B) {
+			var fetched, matched int
+
+			for i := 0; i < b.N; i++ {
+				fetchedChan, closeFetch, err := pool.QueryDocuments(ctx, pool, sp)
+				require.NoError(b, err)
+
+				for f := range fetchedChan {
+					require.NoError(b, f.Err)
+
+					for _, doc := range f.Docs {
+						fetched++
+
+						if must.NotFail(common.FilterDocument(doc, filter)) {
+							matched++
+						}
+					}
+				}
+
+				closeFetch()
+			}
+
+			b.ReportMetric(float64(fetched)/float64(b.N), "fetched/op")
+			b.ReportMetric(float64(matched)/float64(b.N), "matched/op")
+		})
+	}
+}
@@ -0,0 +1,286 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pg
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v4"
+	"go.uber.org/zap"
+
+	"github.com/FerretDB/FerretDB/internal/handlers/common"
+	"github.com/FerretDB/FerretDB/internal/handlers/pg/pgdb"
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
+)
+
+// logicalSessionTimeoutMinutes is reported to clients, so they send logical session IDs
+// needed for multi-document transactions.
+const logicalSessionTimeoutMinutes = 30
+
+// transactionLifetimeLimit is the time after which an in-progress multi-document transaction is aborted.
+// It matches MongoDB's default value of transactionLifetimeLimitSeconds.
+const transactionLifetimeLimit = 60 * time.Second
+
+// txnParams represents multi-document transaction fields of the command.
+type txnParams struct {
+	sessionID string
+	txnNumber int64
+	start     bool
+}
+
+// getTxnParams returns multi-document transaction fields of the command,
+// or nil if the command is not a part of a multi-document transaction.
+func getTxnParams(document *types.Document) (*txnParams, error) {
+	autocommit, err := document.Get("autocommit")
+	if err != nil {
+		return nil, nil
+	}
+
+	if autocommit != false {
+		return nil, common.NewErrorMsg(common.ErrBadValue, "Specifying autocommit=true is not allowed.")
+	}
+
+	lsid, err := common.GetRequiredParam[*types.Document](document, "lsid")
+	if err != nil {
+		return nil, err
+	}
+
+	id, err := common.GetRequiredParam[types.Binary](lsid, "id")
+	if err != nil {
+		return nil, err
+	}
+
+	var params txnParams
+	params.sessionID = string(id.B)
+
+	if params.txnNumber, err = common.GetRequiredParam[int64](document, "txnNumber"); err != nil {
+		return nil, err
+	}
+
+	if params.start, err = common.GetOptionalParam(document, "startTransaction", false); err != nil {
+		return nil, err
+	}
+
+	return &params, nil
+}
+
+// errNoSuchTransaction returns NoSuchTransaction error for the given transaction number.
+func errNoSuchTransaction(txnNumber int64) error {
+	msg := fmt.Sprintf("Given transaction number %d does not match any in-progress transactions.", txnNumber)
+	return common.NewErrorMsg(common.ErrNoSuchTransaction, msg)
+}
+
+// session represents a logical session with an in-progress multi-document transaction.
+type session struct {
+	txnNumber int64
+	timer     *time.Timer
+
+	// mu protects tx; commands of a single session are executed one by one.
+	mu sync.Mutex
+	tx pgx.Tx // nil after commit or abort
+}
+
+// end commits or aborts the session's transaction.
+// It does nothing if the transaction was already committed or aborted.
+func (s *session) end(ctx context.Context, commit bool) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.tx == nil {
+		return nil
+	}
+
+	s.timer.Stop()
+
+	tx := s.tx
+	s.tx = nil
+
+	if commit {
+		return tx.Commit(ctx)
+	}
+
+	return tx.Rollback(ctx)
+}
+
+// sessions tracks in-progress multi-document transactions by logical session ID.
+type sessions struct {
+	mu sync.Mutex
+	m  map[string]*session
+}
+
+// newSessions creates a new sessions registry.
+func newSessions() *sessions {
+	return &sessions{
+		m: make(map[string]*session),
+	}
+}
+
+// begin starts a new transaction with given options for the given session, aborting the previous one, if any.
+func (ss *sessions) begin(ctx context.Context, pgPool *pgdb.Pool, params *txnParams, opts pgx.TxOptions) (*session, error) {
+	tx, err := pgPool.BeginTx(ctx, opts)
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	s := &session{
+		txnNumber: params.txnNumber,
+		tx:        tx,
+	}
+	s.timer = time.AfterFunc(transactionLifetimeLimit, func() {
+		ss.remove(params.sessionID, s)
+		_ = s.end(context.Background(), false)
+	})
+
+	ss.mu.Lock()
+	prev := ss.m[params.sessionID]
+	ss.m[params.sessionID] = s
+	ss.mu.Unlock()
+
+	if prev != nil {
+		_ = prev.end(ctx, false)
+	}
+
+	return s, nil
+}
+
+// get returns the session with the in-progress transaction matching given parameters.
+func (ss *sessions) get(params *txnParams) (*session, error) {
+	ss.mu.Lock()
+	defer ss.mu.Unlock()
+
+	s := ss.m[params.sessionID]
+	if s == nil || s.txnNumber != params.txnNumber {
+		return nil, errNoSuchTransaction(params.txnNumber)
+	}
+
+	return s, nil
+}
+
+// remove removes the given session if it is still registered.
+func (ss *sessions) remove(sessionID string, s *session) {
+	ss.mu.Lock()
+	defer ss.mu.Unlock()
+
+	if ss.m[sessionID] == s {
+		delete(ss.m, sessionID)
+	}
+}
+
+// end commits or aborts the transaction matching given parameters and removes it.
+func (ss *sessions) end(ctx context.Context, params *txnParams, commit bool) error {
+	s, err := ss.get(params)
+	if err != nil {
+		return err
+	}
+
+	ss.remove(params.sessionID, s)
+
+	if err = s.end(ctx, commit); err != nil {
+		return lazyerrors.Error(err)
+	}
+
+	return nil
+}
+
+// abortAll aborts all in-progress transactions.
+func (ss *sessions) abortAll(ctx context.Context) {
+	ss.mu.Lock()
+	m := ss.m
+	ss.m = make(map[string]*session)
+	ss.mu.Unlock()
+
+	for _, s := range m {
+		_ = s.end(ctx, false)
+	}
+}
+
+// txOptions returns options of the transaction for the command's read concern.
+//
+// Snapshot reads are executed in REPEATABLE READ transactions,
+// so all queries of the command see the same snapshot of data.
+func txOptions(document *types.Document) (pgx.TxOptions, error) {
+	level, err := common.GetReadConcernLevel(document)
+	if err != nil {
+		return pgx.TxOptions{}, err
+	}
+
+	if level == common.ReadConcernSnapshot {
+		return pgx.TxOptions{IsoLevel: pgx.RepeatableRead}, nil
+	}
+
+	return pgx.TxOptions{}, nil
+}
+
+// inTransaction calls f with the transaction the command should be executed in.
+//
+// If the command is a part of a multi-document transaction, f is called with the transaction
+// of the command's logical session; it is committed or aborted by commitTransaction or abortTransaction,
+// and aborted immediately if f fails.
+// Otherwise, f is called with a new transaction that is committed if f succeeds.
+// In both cases, the transaction isolation level depends on the command's read concern.
+func (h *Handler) inTransaction(ctx context.Context, document *types.Document, f func(pgx.Tx) error) error {
+	params, err := getTxnParams(document)
+	if err != nil {
+		return err
+	}
+
+	opts, err := txOptions(document)
+	if err != nil {
+		return err
+	}
+
+	if params == nil {
+		return h.pgPool.InTransactionWithOptions(ctx, opts, f)
+	}
+
+	var s *session
+	if params.start {
+		s, err = h.sessions.begin(ctx, h.pgPool, params, opts)
+	} else {
+		if document.Has("readConcern") {
+			return common.NewErrorMsg(common.ErrInvalidOptions, "Only the first command in a transaction may specify a readConcern")
+		}
+
+		s, err = h.sessions.get(params)
+	}
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.tx == nil {
+		return errNoSuchTransaction(params.txnNumber)
+	}
+
+	if err = f(s.tx); err == nil {
+		return nil
+	}
+
+	s.timer.Stop()
+	h.sessions.remove(params.sessionID, s)
+
+	if rerr := s.tx.Rollback(ctx); rerr != nil {
+		h.l.Error("Failed to abort transaction", zap.Error(rerr))
+	}
+	s.tx = nil
+
+	return err
+}
@@ -0,0 +1,92 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pg
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v4"
+	"go.uber.org/zap"
+
+	"github.com/FerretDB/FerretDB/internal/handlers/pg/pgdb"
+	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
+)
+
+// defaultTTLMonitorInterval is the default interval between TTL monitor runs, the same as MongoDB's.
+const defaultTTLMonitorInterval = 60 * time.Second
+
+// runTTLMonitor periodically deletes expired documents of collections with TTL indexes
+// until ctx is canceled.
+func (h *Handler) runTTLMonitor(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		if err := h.deleteExpired(ctx, h.clock()); err != nil && ctx.Err() == nil {
+			h.l.Warn("TTL monitor failed", zap.Error(err))
+		}
+	}
+}
+
+// deleteExpired deletes documents that are expired at the given time according to TTL indexes.
+//
+// A document expires when its indexed field value is a date (or an array containing a date)
+// earlier than the given time minus index's expireAfterSeconds.
+// Documents without the field or with non-date values never expire.
+func (h *Handler) deleteExpired(ctx context.Context, now time.Time) error {
+	indexes, err := pgdb.TTLIndexes(ctx, h.pgPool)
+	if err != nil {
+		return lazyerrors.Error(err)
+	}
+
+	for _, index := range indexes {
+		// TTL indexes are single-field indexes, see parseIndexSpecs
+		field := index.Key.Keys()[0]
+		expireAt := now.Add(-time.Duration(*index.ExpireAfterSeconds) * time.Second)
+
+		var deleted int64
+		err := h.pgPool.InTransaction(ctx, func(tx pgx.Tx) error {
+			var err error
+			deleted, err = pgdb.DeleteExpired(ctx, tx, index.DB, index.Collection, field, expireAt)
+			return err
+		})
+		if err != nil {
+			// the collection was dropped after we got the list of TTL indexes
+			if errors.Is(err, pgdb.ErrTableNotExist) {
+				continue
+			}
+
+			return lazyerrors.Error(err)
+		}
+
+		if deleted > 0 {
+			h.l.Debug(
+				"Expired documents deleted",
+				zap.String("db", index.DB), zap.String("collection", index.Collection),
+				zap.String("index", index.Name), zap.Int64("deleted", deleted),
+			)
+		}
+	}
+
+	return nil
+}
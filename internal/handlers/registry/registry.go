@@ -18,6 +18,7 @@ package registry
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"go.uber.org/zap"
 	"golang.org/x/exp/maps"
@@ -44,9 +45,25 @@ type NewHandlerOpts struct {
 	Ctx    context.Context
 	Logger *zap.Logger
 
+	// for all handlers; zero value means common.DefaultMaxDocumentDepth
+	MaxDocumentDepth int
+
+	// for all handlers; zero value means common.DefaultBatchSize
+	DefaultBatchSize int32
+
 	// for `pg` handler
 	PostgreSQLURL string
 
+	// for `pg` handler; zero values mean pgdb defaults
+	PostgreSQLFetchChannelBufSize int
+	PostgreSQLFetchSliceCapacity  int
+
+	// for `pg` handler; zero value means the handler's default
+	PostgreSQLTTLMonitorInterval time.Duration
+
+	// for `pg` handler
+	PostgreSQLSortByID bool
+
 	// for `tigris` handler
 	TigrisURL string
 }
@@ -87,10 +104,29 @@ func init() {
 			return nil, err
 		}
 
+		sizedPool, err := pgPool.WithFetchSizes(opts.PostgreSQLFetchChannelBufSize, opts.PostgreSQLFetchSliceCapacity)
+		if err != nil {
+			pgPool.Close()
+			return nil, err
+		}
+		pgPool = sizedPool
+
 		handlerOpts := &pg.NewOpts{
-			PgPool: pgPool,
-			L:      opts.Logger,
+			PgPool:           pgPool,
+			L:                opts.Logger,
+			MaxDocumentDepth: opts.MaxDocumentDepth,
+			DefaultBatchSize: opts.DefaultBatchSize,
+
+			TTLMonitorInterval: opts.PostgreSQLTTLMonitorInterval,
+			SortByID:           opts.PostgreSQLSortByID,
+		}
+
+		h, err := pg.New(handlerOpts)
+		if err != nil {
+			pgPool.Close()
+			return nil, err
 		}
-		return pg.New(handlerOpts)
+
+		return h, nil
 	}
 }
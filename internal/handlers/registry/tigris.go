@@ -25,8 +25,10 @@ import (
 func init() {
 	registry["tigris"] = func(opts *NewHandlerOpts) (handlers.Interface, error) {
 		handlerOpts := &tigris.NewOpts{
-			TigrisURL: opts.TigrisURL,
-			L:         opts.Logger,
+			TigrisURL:        opts.TigrisURL,
+			L:                opts.Logger,
+			MaxDocumentDepth: opts.MaxDocumentDepth,
+			DefaultBatchSize: opts.DefaultBatchSize,
 		}
 		return tigris.New(handlerOpts)
 	}
@@ -30,22 +30,29 @@ func (h *Handler) CmdQuery(ctx context.Context, query *wire.OpQuery) (*wire.OpRe
 	if query.FullCollectionName == "admin.$cmd" {
 		switch cmd := query.Query.Command(); cmd {
 		case "ismaster", "isMaster": // both are valid
+			res := must.NotFail(types.NewDocument(
+				"ismaster", true, // only lowercase
+				// topologyVersion
+				"maxBsonObjectSize", int32(types.MaxDocumentLen),
+				"maxMessageSizeBytes", int32(wire.MaxMsgLen),
+				"maxWriteBatchSize", int32(100000),
+				"localTime", time.Now(),
+				// logicalSessionTimeoutMinutes
+				// connectionId
+				"minWireVersion", int32(13),
+				"maxWireVersion", int32(13),
+				"readOnly", false,
+			))
+
+			if compression := common.NegotiateCompression(query.Query); compression != nil {
+				must.NoError(res.Set("compression", compression))
+			}
+
+			must.NoError(res.Set("ok", float64(1)))
+
 			reply := &wire.OpReply{
 				NumberReturned: 1,
-				Documents: []*types.Document{must.NotFail(types.NewDocument(
-					"ismaster", true, // only lowercase
-					// topologyVersion
-					"maxBsonObjectSize", int32(types.MaxDocumentLen),
-					"maxMessageSizeBytes", int32(wire.MaxMsgLen),
-					"maxWriteBatchSize", int32(100000),
-					"localTime", time.Now(),
-					// logicalSessionTimeoutMinutes
-					// connectionId
-					"minWireVersion", int32(13),
-					"maxWireVersion", int32(13),
-					"readOnly", false,
-					"ok", float64(1),
-				))},
+				Documents:      []*types.Document{res},
 			}
 			return reply, nil
 
@@ -16,12 +16,102 @@ package tigris
 
 import (
 	"context"
+	"time"
 
 	"github.com/FerretDB/FerretDB/internal/handlers/common"
+	"github.com/FerretDB/FerretDB/internal/handlers/common/aggregations"
+	"github.com/FerretDB/FerretDB/internal/handlers/tigris/tigrisdb"
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
+	"github.com/FerretDB/FerretDB/internal/util/must"
 	"github.com/FerretDB/FerretDB/internal/wire"
 )
 
 // MsgAggregate implements HandlerInterface.
 func (h *Handler) MsgAggregate(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg, error) {
-	return common.MsgAggregate(ctx, msg)
+	document, err := msg.Document()
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	unimplementedFields := []string{
+		"explain",
+		"collation",
+		"let",
+	}
+	if err = common.Unimplemented(document, unimplementedFields...); err != nil {
+		return nil, err
+	}
+
+	ignoredFields := []string{
+		"allowDiskUse",
+		"bypassDocumentValidation",
+		"comment",
+		"hint",
+		"writeConcern",
+	}
+	common.Ignored(document, h.L, ignoredFields...)
+
+	// the read concern level is validated, but not used yet
+	if _, err = common.GetReadConcernLevel(document); err != nil {
+		return nil, err
+	}
+
+	params, err := aggregations.ParseParams(ctx, document)
+	if err != nil {
+		return nil, err
+	}
+
+	if params.ChangeStream != nil {
+		return nil, common.NewErrorMsg(common.ErrNotImplemented, "`aggregate` stage \"$changeStream\" is not implemented yet")
+	}
+
+	if params.Merge != nil {
+		return nil, common.NewErrorMsg(common.ErrNotImplemented, "`aggregate` stage \"$merge\" is not implemented yet")
+	}
+
+	maxTimeMS, err := common.GetOptionalPositiveNumber(document, "maxTimeMS")
+	if err != nil {
+		return nil, err
+	}
+
+	if maxTimeMS != 0 {
+		ctxWithTimeout, cancel := context.WithTimeout(ctx, time.Duration(maxTimeMS)*time.Millisecond)
+		defer cancel()
+
+		ctx = ctxWithTimeout
+	}
+
+	var docs []*types.Document
+	stages := params.Stages
+
+	if params.Collection != "" {
+		// Tigris selects a superset of documents matching the filter, so $skip and $limit are not pushed down
+		pushdown := aggregations.NewPushdown(params.Stages, func(*types.Document) bool { return false })
+		stages = pushdown.Stages
+
+		fp := tigrisdb.FetchParam{
+			DB:         params.DB,
+			Collection: params.Collection,
+			Filter:     pushdown.Filter,
+		}
+
+		if docs, err = h.db.QueryDocuments(ctx, h.db.Driver.UseDatabase(fp.DB), fp); err != nil {
+			return nil, err
+		}
+	}
+
+	if docs, err = aggregations.Process(ctx, stages, docs); err != nil {
+		return nil, err
+	}
+
+	var reply wire.OpMsg
+	must.NoError(reply.SetSections(wire.OpMsgSection{
+		Documents: []*types.Document{must.NotFail(types.NewDocument(
+			"cursor", h.cursors.FirstBatch(ctx, params.Namespace(), docs, params.BatchSize, false),
+			"ok", float64(1),
+		))},
+	}))
+
+	return &reply, nil
 }
@@ -34,7 +34,6 @@ func (h *Handler) MsgCount(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg, e
 	}
 
 	unimplementedFields := []string{
-		"skip",
 		"collation",
 	}
 	if err := common.Unimplemented(document, unimplementedFields...); err != nil {
@@ -42,21 +41,29 @@ func (h *Handler) MsgCount(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg, e
 	}
 	ignoredFields := []string{
 		"hint",
-		"readConcern",
 		"comment",
 	}
 	common.Ignored(document, h.L, ignoredFields...)
 
+	// the read concern level is validated, but not used yet
+	if _, err = common.GetReadConcernLevel(document); err != nil {
+		return nil, err
+	}
+
 	var filter *types.Document
 	if filter, err = common.GetOptionalParam(document, "query", filter); err != nil {
 		return nil, err
 	}
 
-	var limit int64
-	if l, _ := document.Get("limit"); l != nil {
-		if limit, err = common.GetWholeNumberParam(l); err != nil {
-			return nil, err
-		}
+	skip, err := common.GetSkipParam(document)
+	if err != nil {
+		return nil, err
+	}
+
+	// negative limit is the same as positive for counting
+	limit, _, err := common.GetLimitParam(document)
+	if err != nil {
+		return nil, err
 	}
 
 	var fp tigrisdb.FetchParam
@@ -77,7 +84,9 @@ func (h *Handler) MsgCount(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg, e
 		)
 	}
 
-	fetchedDocs, err := h.db.QueryDocuments(ctx, fp)
+	fp.Filter = filter
+
+	fetchedDocs, err := h.db.QueryDocuments(ctx, h.db.Driver.UseDatabase(fp.DB), fp)
 	if err != nil {
 		return nil, err
 	}
@@ -96,6 +105,7 @@ func (h *Handler) MsgCount(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg, e
 		resDocs = append(resDocs, doc)
 	}
 
+	resDocs = common.SkipDocuments(resDocs, skip)
 	if resDocs, err = common.LimitDocuments(resDocs, limit); err != nil {
 		return nil, err
 	}
@@ -17,6 +17,7 @@ package tigris
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 
 	"github.com/tigrisdata/tigris-client-go/driver"
@@ -41,7 +42,6 @@ func (h *Handler) MsgDelete(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg,
 	if err := common.Unimplemented(document, "let"); err != nil {
 		return nil, err
 	}
-	common.Ignored(document, h.L, "ordered") // TODO https://github.com/FerretDB/FerretDB/issues/848
 	common.Ignored(document, h.L, "writeConcern")
 
 	var deletes *types.Array
@@ -55,7 +55,7 @@ func (h *Handler) MsgDelete(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg,
 	}
 
 	var deleted int32
-	processQuery := func(i int) error {
+	processQuery := func(querier driver.Database, i int) error {
 		// get document with filter
 		d, err := common.AssertType[*types.Document](must.NotFail(deletes.Get(i)))
 		if err != nil {
@@ -98,8 +98,10 @@ func (h *Handler) MsgDelete(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg,
 			)
 		}
 
+		fp.Filter = filter
+
 		// fetch current items from collection
-		fetchedDocs, err := h.db.QueryDocuments(ctx, fp)
+		fetchedDocs, err := h.db.QueryDocuments(ctx, querier, fp)
 		if err != nil {
 			return err
 		}
@@ -129,7 +131,7 @@ func (h *Handler) MsgDelete(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg,
 			return nil
 		}
 
-		res, err := h.delete(ctx, fp, resDocs)
+		res, err := h.delete(ctx, querier, fp, resDocs)
 		if err != nil {
 			return err
 		}
@@ -143,20 +145,48 @@ func (h *Handler) MsgDelete(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg,
 
 	var reply wire.OpMsg
 
-	// process every delete filter
-	for i := 0; i < deletes.Len(); i++ {
-		err = processQuery(i)
-		if err != nil {
-			delErrors.Append(err, int32(i))
-
-			// Delete statements in the `deletes` field are not transactional.
-			// It means that we run each delete statement separately.
-			// If `ordered` is set as `true`, we don't execute the remaining statements
-			// after the first failure.
-			// If `ordered` is set as `false`,  we execute all the statements and return
-			// the list of errors corresponding to the failed statements.
-			if ordered {
-				break
+	var db string
+	if db, err = common.GetRequiredParam[string](document, "$db"); err != nil {
+		return nil, err
+	}
+
+	if ordered {
+		// If `ordered` is set as `true`, all delete statements are executed in a single transaction.
+		// We don't execute the remaining statements after the first failure,
+		// and all the changes made by the previous statements are rolled back.
+		err = h.db.InTransaction(ctx, db, func(tx driver.Tx) error {
+			for i := 0; i < deletes.Len(); i++ {
+				if err := processQuery(tx, i); err != nil {
+					delErrors.Append(err, int32(i))
+					return err
+				}
+			}
+
+			return nil
+		})
+
+		switch {
+		case err == nil:
+			// do nothing
+		case len(*delErrors) > 0:
+			// all deletes were rolled back
+			deleted = 0
+		default:
+			// the error is not related to delete statements:
+			// the transaction could not be started or committed
+			var driverErr *driver.Error
+			if !errors.As(err, &driverErr) || !tigrisdb.IsNotFound(driverErr) {
+				return nil, lazyerrors.Error(err)
+			}
+
+			// the database doesn't exist, there is nothing to delete
+		}
+	} else {
+		// If `ordered` is set as `false`, we execute all the statements separately and return
+		// the list of errors corresponding to the failed statements.
+		for i := 0; i < deletes.Len(); i++ {
+			if err = processQuery(h.db.Driver.UseDatabase(db), i); err != nil {
+				delErrors.Append(err, int32(i))
 			}
 		}
 	}
@@ -184,8 +214,8 @@ func (h *Handler) MsgDelete(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg,
 	return &reply, nil
 }
 
-// delete deletes documents by _id.
-func (h *Handler) delete(ctx context.Context, fp tigrisdb.FetchParam, docs []*types.Document) (int, error) {
+// delete deletes documents by _id using the given querier.
+func (h *Handler) delete(ctx context.Context, querier driver.Database, fp tigrisdb.FetchParam, docs []*types.Document) (int, error) {
 	ids := make([]map[string]any, len(docs))
 	for i, doc := range docs {
 		id := must.NotFail(tjson.Marshal(must.NotFail(doc.Get("_id"))))
@@ -204,7 +234,7 @@ func (h *Handler) delete(ctx context.Context, fp tigrisdb.FetchParam, docs []*ty
 
 	h.L.Sugar().Debugf("Delete filter: %s", f)
 
-	_, err := h.db.Driver.UseDatabase(fp.DB).Delete(ctx, fp.Collection, f)
+	_, err := querier.Delete(ctx, fp.Collection, f)
 	if err != nil {
 		return 0, lazyerrors.Error(err)
 	}
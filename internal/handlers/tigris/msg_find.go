@@ -35,7 +35,6 @@ func (h *Handler) MsgFind(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg, er
 	}
 
 	unimplementedFields := []string{
-		"skip",
 		"returnKey",
 		"showRecordId",
 		"tailable",
@@ -52,14 +51,16 @@ func (h *Handler) MsgFind(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg, er
 	}
 	ignoredFields := []string{
 		"hint",
-		"batchSize",
-		"singleBatch",
-		"readConcern",
 		"max",
 		"min",
 	}
 	common.Ignored(document, h.L, ignoredFields...)
 
+	// the read concern level is validated, but not used yet
+	if _, err = common.GetReadConcernLevel(document); err != nil {
+		return nil, err
+	}
+
 	var filter, sort, projection *types.Document
 	if filter, err = common.GetOptionalParam(document, "filter", filter); err != nil {
 		return nil, err
@@ -83,13 +84,33 @@ func (h *Handler) MsgFind(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg, er
 		ctx = ctxWithTimeout
 	}
 
-	var limit int64
-	if l, _ := document.Get("limit"); l != nil {
-		if limit, err = common.GetWholeNumberParam(l); err != nil {
+	batchSize := int32(-1) // the handler's default
+	if document.Has("batchSize") {
+		if batchSize, err = common.GetOptionalPositiveNumber(document, "batchSize"); err != nil {
 			return nil, err
 		}
 	}
 
+	singleBatch, err := common.GetBoolOptionalParam(document, "singleBatch")
+	if err != nil {
+		return nil, err
+	}
+
+	skip, err := common.GetSkipParam(document)
+	if err != nil {
+		return nil, err
+	}
+
+	// negative limit means that all results should be returned in a single batch
+	limit, negativeLimit, err := common.GetLimitParam(document)
+	if err != nil {
+		return nil, err
+	}
+
+	if negativeLimit {
+		singleBatch = true
+	}
+
 	var fp tigrisdb.FetchParam
 
 	if fp.DB, err = common.GetRequiredParam[string](document, "$db"); err != nil {
@@ -108,7 +129,9 @@ func (h *Handler) MsgFind(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg, er
 		)
 	}
 
-	fetchedDocs, err := h.db.QueryDocuments(ctx, fp)
+	fp.Filter = filter
+
+	fetchedDocs, err := h.db.QueryDocuments(ctx, h.db.Driver.UseDatabase(fp.DB), fp)
 	if err != nil {
 		return nil, err
 	}
@@ -130,6 +153,7 @@ func (h *Handler) MsgFind(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg, er
 	if err = common.SortDocuments(resDocs, sort); err != nil {
 		return nil, err
 	}
+	resDocs = common.SkipDocuments(resDocs, skip)
 	if resDocs, err = common.LimitDocuments(resDocs, limit); err != nil {
 		return nil, err
 	}
@@ -137,21 +161,10 @@ func (h *Handler) MsgFind(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg, er
 		return nil, err
 	}
 
-	firstBatch := types.MakeArray(len(resDocs))
-	for _, doc := range resDocs {
-		if err = firstBatch.Append(doc); err != nil {
-			return nil, err
-		}
-	}
-
 	var reply wire.OpMsg
 	must.NoError(reply.SetSections(wire.OpMsgSection{
 		Documents: []*types.Document{must.NotFail(types.NewDocument(
-			"cursor", must.NotFail(types.NewDocument(
-				"firstBatch", firstBatch,
-				"id", int64(0), // TODO
-				"ns", fp.DB+"."+fp.Collection,
-			)),
+			"cursor", h.cursors.FirstBatch(ctx, fp.DB+"."+fp.Collection, resDocs, batchSize, singleBatch),
 			"ok", float64(1),
 		))},
 	}))
@@ -16,13 +16,407 @@ package tigris
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"time"
 
+	"github.com/tigrisdata/tigris-client-go/driver"
+
+	"github.com/FerretDB/FerretDB/internal/handlers/common"
+	"github.com/FerretDB/FerretDB/internal/handlers/tigris/tigrisdb"
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
 	"github.com/FerretDB/FerretDB/internal/util/must"
 	"github.com/FerretDB/FerretDB/internal/wire"
 )
 
 // MsgFindAndModify implements HandlerInterface.
 func (h *Handler) MsgFindAndModify(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg, error) {
-	// TODO https://github.com/FerretDB/FerretDB/issues/775
-	return nil, notImplemented(must.NotFail(msg.Document()).Command())
+	document, err := msg.Document()
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	unimplementedFields := []string{
+		"arrayFilters",
+		"let",
+		"fields",
+	}
+	if err := common.Unimplemented(document, unimplementedFields...); err != nil {
+		return nil, err
+	}
+
+	ignoredFields := []string{
+		"bypassDocumentValidation",
+		"writeConcern",
+		"collation",
+		"hint",
+		"comment",
+	}
+	common.Ignored(document, h.L, ignoredFields...)
+
+	params, err := prepareFindAndModifyParams(document)
+	if err != nil {
+		return nil, err
+	}
+
+	if params.maxTimeMS != 0 {
+		ctxWithTimeout, cancel := context.WithTimeout(ctx, time.Duration(params.maxTimeMS)*time.Millisecond)
+		defer cancel()
+
+		ctx = ctxWithTimeout
+	}
+
+	// the database should exist to start a transaction; upsert may need to create it
+	if params.upsert {
+		if _, err = h.db.CreateDatabaseIfNotExists(ctx, params.fetchParam.DB); err != nil {
+			return nil, lazyerrors.Error(err)
+		}
+	}
+
+	// Fetching, modifying and returning the document are done in a single transaction,
+	// so the document can't be changed concurrently between those steps.
+	var resDoc *types.Document
+	err = h.db.InTransaction(ctx, params.fetchParam.DB, func(tx driver.Tx) error {
+		var err error
+		resDoc, err = h.findAndModify(ctx, tx, params)
+		return err
+	})
+
+	var driverErr *driver.Error
+
+	switch {
+	case err == nil:
+		// do nothing
+	case !params.upsert && errors.As(err, &driverErr) && tigrisdb.IsNotFound(driverErr):
+		// the database doesn't exist, there is nothing to modify
+		lastErrorObject := must.NotFail(types.NewDocument("n", int32(0)))
+		if params.update != nil {
+			must.NoError(lastErrorObject.Set("updatedExisting", false))
+		}
+
+		resDoc = must.NotFail(types.NewDocument(
+			"lastErrorObject", lastErrorObject,
+			"ok", float64(1),
+		))
+	default:
+		return nil, err
+	}
+
+	var reply wire.OpMsg
+	must.NoError(reply.SetSections(wire.OpMsgSection{
+		Documents: []*types.Document{resDoc},
+	}))
+
+	return &reply, nil
+}
+
+// findAndModify fetches, modifies and returns the document using the given querier.
+// It returns the reply document.
+func (h *Handler) findAndModify(ctx context.Context, querier driver.Database, params *findAndModifyParams) (*types.Document, error) {
+	// This is not very optimal as we need to fetch everything from the database to have a proper sort.
+	// We might consider rewriting it later.
+	fetchedDocs, err := h.db.QueryDocuments(ctx, querier, params.fetchParam)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = common.SortDocuments(fetchedDocs, params.sort); err != nil {
+		return nil, err
+	}
+
+	resDocs := make([]*types.Document, 0, 16)
+	for _, doc := range fetchedDocs {
+		matches, err := common.FilterDocument(doc, params.query)
+		if err != nil {
+			return nil, err
+		}
+
+		if !matches {
+			continue
+		}
+
+		resDocs = append(resDocs, doc)
+	}
+
+	// findAndModify always works with a single document
+	if resDocs, err = common.LimitDocuments(resDocs, 1); err != nil {
+		return nil, err
+	}
+
+	if params.update != nil { // we have update part
+		var upsert *types.Document
+		var upserted bool
+
+		if params.upsert { //  we have upsert flag
+			p := &upsertParams{
+				hasUpdateOperators: params.hasUpdateOperators,
+				query:              params.query,
+				update:             params.update,
+				fetchParam:         params.fetchParam,
+			}
+			upsert, upserted, err = h.upsert(ctx, querier, resDocs, p)
+			if err != nil {
+				return nil, err
+			}
+		} else { // process update as usual
+			if len(resDocs) == 0 {
+				return must.NotFail(types.NewDocument(
+					"lastErrorObject", must.NotFail(types.NewDocument("n", int32(0), "updatedExisting", false)),
+					"ok", float64(1),
+				)), nil
+			}
+
+			if params.hasUpdateOperators {
+				upsert = resDocs[0].DeepCopy()
+
+				update, err := common.ResolvePositionalOperator(upsert, params.query, params.update)
+				if err != nil {
+					return nil, err
+				}
+
+				if _, err = common.UpdateDocument(upsert, update); err != nil {
+					return nil, err
+				}
+			} else {
+				upsert = params.update
+
+				if !upsert.Has("_id") {
+					must.NoError(upsert.Set("_id", must.NotFail(resDocs[0].Get("_id"))))
+				}
+
+				if err = common.CheckImmutableID(must.NotFail(resDocs[0].Get("_id")), upsert); err != nil {
+					return nil, err
+				}
+			}
+
+			if _, err = h.update(ctx, querier, params.fetchParam, upsert); err != nil {
+				return nil, err
+			}
+		}
+
+		var resultDoc *types.Document
+		if params.returnNewDocument || len(resDocs) == 0 {
+			resultDoc = upsert
+		} else {
+			resultDoc = resDocs[0]
+		}
+
+		lastErrorObject := must.NotFail(types.NewDocument(
+			"n", int32(1),
+			"updatedExisting", len(resDocs) > 0,
+		))
+
+		if upserted {
+			must.NoError(lastErrorObject.Set("upserted", must.NotFail(resultDoc.Get("_id"))))
+		}
+
+		return must.NotFail(types.NewDocument(
+			"lastErrorObject", lastErrorObject,
+			"value", resultDoc,
+			"ok", float64(1),
+		)), nil
+	}
+
+	if params.remove {
+		if len(resDocs) == 0 {
+			return must.NotFail(types.NewDocument(
+				"lastErrorObject", must.NotFail(types.NewDocument("n", int32(0))),
+				"ok", float64(1),
+			)), nil
+		}
+
+		if _, err = h.delete(ctx, querier, params.fetchParam, resDocs); err != nil {
+			return nil, err
+		}
+
+		return must.NotFail(types.NewDocument(
+			"lastErrorObject", must.NotFail(types.NewDocument("n", int32(1))),
+			"value", resDocs[0],
+			"ok", float64(1),
+		)), nil
+	}
+
+	return nil, lazyerrors.New("bad flags combination")
+}
+
+// upsertParams represent parameters for Handler.upsert method.
+type upsertParams struct {
+	hasUpdateOperators bool
+	query, update      *types.Document
+	fetchParam         tigrisdb.FetchParam
+}
+
+// upsert inserts new document if no documents in query result or updates given document.
+// When inserting new document we must check that `_id` is present, so we must extract `_id` from query or generate a new one.
+func (h *Handler) upsert(ctx context.Context, querier driver.Database, docs []*types.Document, params *upsertParams) (*types.Document, bool, error) {
+	if len(docs) == 0 {
+		upsert := must.NotFail(types.NewDocument())
+
+		if params.hasUpdateOperators {
+			// the positional operator can't be resolved for the inserted document; that returns an error
+			update, err := common.ResolvePositionalOperator(upsert, params.query, params.update)
+			if err != nil {
+				return nil, false, err
+			}
+
+			if _, err = common.UpdateDocument(upsert, update); err != nil {
+				return nil, false, err
+			}
+		} else {
+			upsert = params.update
+		}
+
+		if !upsert.Has("_id") {
+			if params.query.Has("_id") {
+				must.NoError(upsert.Set("_id", must.NotFail(params.query.Get("_id"))))
+			} else {
+				must.NoError(upsert.Set("_id", types.NewObjectID()))
+			}
+		}
+
+		if err := h.insert(ctx, querier, params.fetchParam, upsert); err != nil {
+			return nil, false, err
+		}
+
+		return upsert, true, nil
+	}
+
+	upsert := docs[0].DeepCopy()
+
+	if params.hasUpdateOperators {
+		update, err := common.ResolvePositionalOperator(upsert, params.query, params.update)
+		if err != nil {
+			return nil, false, err
+		}
+
+		if _, err = common.UpdateDocument(upsert, update); err != nil {
+			return nil, false, err
+		}
+	} else {
+		for _, k := range params.update.Keys() {
+			must.NoError(upsert.Set(k, must.NotFail(params.update.Get(k))))
+		}
+
+		if err := common.CheckImmutableID(must.NotFail(docs[0].Get("_id")), upsert); err != nil {
+			return nil, false, err
+		}
+	}
+
+	if _, err := h.update(ctx, querier, params.fetchParam, upsert); err != nil {
+		return nil, false, err
+	}
+
+	return upsert, false, nil
+}
+
+// findAndModifyParams represent all findAndModify requests' fields.
+// It's filled by calling prepareFindAndModifyParams.
+type findAndModifyParams struct {
+	fetchParam                            tigrisdb.FetchParam
+	query, sort, update                   *types.Document
+	remove, upsert                        bool
+	returnNewDocument, hasUpdateOperators bool
+	maxTimeMS                             int32
+}
+
+// prepareFindAndModifyParams prepares findAndModify request fields.
+func prepareFindAndModifyParams(document *types.Document) (*findAndModifyParams, error) {
+	var err error
+
+	command := document.Command()
+
+	var db, collection string
+	if db, err = common.GetRequiredParam[string](document, "$db"); err != nil {
+		return nil, err
+	}
+	if collection, err = common.GetRequiredParam[string](document, command); err != nil {
+		return nil, err
+	}
+
+	if collection == "" {
+		return nil, common.NewErrorMsg(
+			common.ErrInvalidNamespace,
+			fmt.Sprintf("Invalid namespace specified '%s.'", db),
+		)
+	}
+
+	var remove bool
+	if remove, err = common.GetBoolOptionalParam(document, "remove"); err != nil {
+		return nil, err
+	}
+	var returnNewDocument bool
+	if returnNewDocument, err = common.GetBoolOptionalParam(document, "new"); err != nil {
+		return nil, err
+	}
+	var upsert bool
+	if upsert, err = common.GetBoolOptionalParam(document, "upsert"); err != nil {
+		return nil, err
+	}
+
+	var query *types.Document
+	if query, err = common.GetOptionalParam(document, "query", query); err != nil {
+		return nil, err
+	}
+
+	var sort *types.Document
+	if sort, err = common.GetOptionalParam(document, "sort", sort); err != nil {
+		return nil, err
+	}
+
+	maxTimeMS, err := common.GetOptionalPositiveNumber(document, "maxTimeMS")
+	if err != nil {
+		return nil, err
+	}
+
+	var update *types.Document
+	updateParam, err := document.Get("update")
+	if err != nil && !remove {
+		return nil, common.NewErrorMsg(common.ErrFailedToParse, "Either an update or remove=true must be specified")
+	}
+	if err == nil {
+		switch updateParam := updateParam.(type) {
+		case *types.Document:
+			update = updateParam
+		case *types.Array:
+			// TODO aggregation pipeline stages metrics
+			return nil, common.NewErrorMsg(common.ErrNotImplemented, "Aggregation pipelines are not supported yet")
+		default:
+			return nil, common.NewErrorMsg(common.ErrFailedToParse, "Update argument must be either an object or an array")
+		}
+	}
+
+	if update != nil && remove {
+		return nil, common.NewErrorMsg(common.ErrFailedToParse, "Cannot specify both an update and remove=true")
+	}
+	if upsert && remove {
+		return nil, common.NewErrorMsg(common.ErrFailedToParse, "Cannot specify both upsert=true and remove=true")
+	}
+	if returnNewDocument && remove {
+		return nil, common.NewErrorMsg(
+			common.ErrFailedToParse,
+			"Cannot specify both new=true and remove=true; 'remove' always returns the deleted document",
+		)
+	}
+
+	hasUpdateOperators, err := common.HasSupportedUpdateModifiers(update)
+	if err != nil {
+		return nil, err
+	}
+
+	return &findAndModifyParams{
+		fetchParam: tigrisdb.FetchParam{
+			DB:         db,
+			Collection: collection,
+			Filter:     query,
+		},
+		query:              query,
+		update:             update,
+		sort:               sort,
+		remove:             remove,
+		upsert:             upsert,
+		returnNewDocument:  returnNewDocument,
+		hasUpdateOperators: hasUpdateOperators,
+		maxTimeMS:          maxTimeMS,
+	}, nil
 }
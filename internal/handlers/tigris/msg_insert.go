@@ -16,6 +16,7 @@ package tigris
 
 import (
 	"context"
+	"errors"
 	"fmt"
 
 	"github.com/tigrisdata/tigris-client-go/driver"
@@ -36,7 +37,7 @@ func (h *Handler) MsgInsert(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg,
 		return nil, lazyerrors.Error(err)
 	}
 
-	common.Ignored(document, h.L, "ordered", "writeConcern", "bypassDocumentValidation", "comment")
+	common.Ignored(document, h.L, "writeConcern", "bypassDocumentValidation", "comment")
 
 	var fp tigrisdb.FetchParam
 
@@ -61,56 +62,161 @@ func (h *Handler) MsgInsert(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg,
 		return nil, err
 	}
 
-	var inserted int32
+	ordered := true
+	if ordered, err = common.GetOptionalParam(document, "ordered", ordered); err != nil {
+		return nil, err
+	}
+
+	batch := make([]*types.Document, docs.Len())
+	depthErrs := make([]error, docs.Len())
+	valid := make([]*types.Document, 0, docs.Len())
+
 	for i := 0; i < docs.Len(); i++ {
 		doc, err := docs.Get(i)
 		if err != nil {
 			return nil, lazyerrors.Error(err)
 		}
 
-		err = h.insert(ctx, fp, doc.(*types.Document))
-		if err != nil {
+		if batch[i], ok = doc.(*types.Document); !ok {
+			return nil, common.NewErrorMsg(
+				common.ErrBadValue,
+				fmt.Sprintf("document has invalid type %s", common.AliasFromType(doc)),
+			)
+		}
+
+		// too deeply nested documents are reported as write errors and don't affect the schema
+		if depthErrs[i] = common.ValidateDocumentDepth(batch[i], h.MaxDocumentDepth); depthErrs[i] == nil {
+			valid = append(valid, batch[i])
+		}
+	}
+
+	// describe and update the collection schema once for the whole batch
+	if err = h.ensureSchema(ctx, fp, valid...); err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	var inserted int32
+	insErrors := new(common.WriteErrors)
+
+	for i, doc := range batch {
+		if err = depthErrs[i]; err == nil {
+			err = h.insertDocument(ctx, h.db.Driver.UseDatabase(fp.DB), fp, doc)
+		}
+
+		var cmdErr *common.CommandError
+		switch {
+		case err == nil:
+			inserted++
+			continue
+		case errors.As(err, &cmdErr) && (cmdErr.Code() == common.ErrDocumentValidationFailure || cmdErr.Code() == common.ErrOverflow):
+			// the document doesn't match the collection schema or is too deeply nested,
+			// report it and go on with the rest of the batch
+			insErrors.Append(err, int32(i))
+		default:
 			return nil, lazyerrors.Error(err)
 		}
 
-		inserted++
+		// If `ordered` is set as `true`, we don't insert the remaining documents
+		// after the first failure.
+		if ordered {
+			break
+		}
 	}
 
+	var replyDoc *types.Document
+
+	// if there are insert errors append writeErrors field
+	if len(*insErrors) > 0 {
+		replyDoc = insErrors.Document()
+	} else {
+		replyDoc = must.NotFail(types.NewDocument(
+			"ok", float64(1),
+		))
+	}
+
+	must.NoError(replyDoc.Set("n", inserted))
+
 	var reply wire.OpMsg
 	must.NoError(reply.SetSections(wire.OpMsgSection{
-		Documents: []*types.Document{must.NotFail(types.NewDocument(
-			"n", inserted,
-			"ok", float64(1),
-		))},
+		Documents: []*types.Document{replyDoc},
 	}))
 
 	return &reply, nil
 }
 
 // insert checks if database and collection exist, create them if needed and attempts to insert the given doc.
-func (h *Handler) insert(ctx context.Context, fp tigrisdb.FetchParam, doc *types.Document) error {
-	schema, err := tjson.DocumentSchema(doc)
-	if err != nil {
+// If the collection exists, new fields of the given doc are added to the collection schema.
+//
+// If the document doesn't match the collection schema, the DocumentValidationFailure error is returned.
+func (h *Handler) insert(ctx context.Context, querier driver.Database, fp tigrisdb.FetchParam, doc *types.Document) error {
+	if err := h.ensureSchema(ctx, fp, doc); err != nil {
 		return lazyerrors.Error(err)
 	}
-	schema.Title = fp.Collection
-	b := must.NotFail(schema.Marshal())
+
+	return h.insertDocument(ctx, querier, fp, doc)
+}
+
+// ensureSchema checks if database and collection exist and creates them if needed.
+// If the collection exists, new fields of the given docs are added to the collection schema.
+//
+// The collection schema is fetched and updated at most once for all given docs.
+func (h *Handler) ensureSchema(ctx context.Context, fp tigrisdb.FetchParam, docs ...*types.Document) error {
+	if len(docs) == 0 {
+		return nil
+	}
+
+	schemas := make([]*tjson.Schema, len(docs))
+	for i, doc := range docs {
+		schema, err := tjson.DocumentSchema(doc)
+		if err != nil {
+			return lazyerrors.Error(err)
+		}
+		schema.Title = fp.Collection
+		schemas[i] = schema
+	}
+
+	b := must.NotFail(schemas[0].Marshal())
 	h.L.Sugar().Debugf("Schema:\n%s", b)
 
-	_, err = h.db.CreateCollectionIfNotExist(ctx, fp.DB, fp.Collection, b)
+	created, err := h.db.CreateCollectionIfNotExist(ctx, fp.DB, fp.Collection, b)
 	if err != nil {
 		return lazyerrors.Error(err)
 	}
 
-	b, err = tjson.Marshal(doc)
+	// the collection was created with the first schema, there is no need to merge it again
+	if created {
+		schemas = schemas[1:]
+	}
+
+	// evolve the schema of the collection if the documents have new fields
+	if _, err = h.db.AddSchemaProperties(ctx, fp.DB, fp.Collection, schemas...); err != nil {
+		return lazyerrors.Error(err)
+	}
+
+	return nil
+}
+
+// insertDocument attempts to insert the given doc into the existing collection.
+//
+// If the document doesn't match the collection schema, the DocumentValidationFailure error is returned.
+func (h *Handler) insertDocument(ctx context.Context, querier driver.Database, fp tigrisdb.FetchParam, doc *types.Document) error {
+	b, err := tjson.Marshal(doc)
 	if err != nil {
 		return lazyerrors.Error(err)
 	}
 	h.L.Sugar().Debugf("Document:\n%s", b)
 
-	_, err = h.db.Driver.UseDatabase(fp.DB).Insert(ctx, fp.Collection, []driver.Document{b})
-	if err != nil {
+	_, err = querier.Insert(ctx, fp.Collection, []driver.Document{b})
+	switch err := err.(type) {
+	case nil:
+		return nil
+	case *driver.Error:
+		if tigrisdb.IsInvalidArgument(err) {
+			return common.NewError(common.ErrDocumentValidationFailure, err)
+		}
+
+		return lazyerrors.Error(err)
+	default:
 		return lazyerrors.Error(err)
 	}
-	return nil
 }
@@ -17,7 +17,10 @@ package tigris
 import (
 	"context"
 
+	"github.com/tigrisdata/tigris-client-go/driver"
+
 	"github.com/FerretDB/FerretDB/internal/handlers/common"
+	"github.com/FerretDB/FerretDB/internal/handlers/tigris/tigrisdb"
 	"github.com/FerretDB/FerretDB/internal/types"
 	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
 	"github.com/FerretDB/FerretDB/internal/util/must"
@@ -38,13 +41,10 @@ func (h *Handler) MsgListCollections(ctx context.Context, msg *wire.OpMsg) (*wir
 		return nil, err
 	}
 
-	// TODO https://github.com/FerretDB/FerretDB/issues/301
-	// if err = common.UnimplementedNonDefault(document, "nameOnly", func(v any) bool {
-	// 	nameOnly, ok := v.(bool)
-	// 	return ok && !nameOnly
-	// }); err != nil {
-	// 	return nil, err
-	// }
+	var nameOnly bool
+	if nameOnly, err = common.GetOptionalParam(document, "nameOnly", nameOnly); err != nil {
+		return nil, err
+	}
 
 	common.Ignored(document, h.L, "comment", "authorizedCollections")
 
@@ -54,7 +54,15 @@ func (h *Handler) MsgListCollections(ctx context.Context, msg *wire.OpMsg) (*wir
 	}
 
 	names, err := h.db.Driver.UseDatabase(db).ListCollections(ctx)
-	if err != nil {
+	switch err := err.(type) {
+	case nil:
+		// do nothing
+	case *driver.Error:
+		if !tigrisdb.IsNotFound(err) {
+			return nil, lazyerrors.Error(err)
+		}
+		// database doesn't exist, return empty list
+	default:
 		return nil, lazyerrors.Error(err)
 	}
 
@@ -64,6 +72,12 @@ func (h *Handler) MsgListCollections(ctx context.Context, msg *wire.OpMsg) (*wir
 			"name", n,
 			"type", "collection",
 		))
+
+		if !nameOnly {
+			must.NoError(d.Set("options", must.NotFail(types.NewDocument())))
+			must.NoError(d.Set("info", must.NotFail(types.NewDocument("readOnly", false))))
+		}
+
 		if err = collections.Append(d); err != nil {
 			return nil, lazyerrors.Error(err)
 		}
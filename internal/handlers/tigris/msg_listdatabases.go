@@ -37,7 +37,13 @@ func (h *Handler) MsgListDatabases(ctx context.Context, msg *wire.OpMsg) (*wire.
 		return nil, err
 	}
 
-	common.Ignored(document, h.L, "comment", "authorizedDatabases")
+	common.Ignored(document, h.L, "comment")
+
+	// there is no authentication yet, so all databases are authorized;
+	// the value is only validated
+	if _, err = common.GetBoolOptionalParam(document, "authorizedDatabases"); err != nil {
+		return nil, err
+	}
 
 	databaseNames, err := h.db.Driver.ListDatabases(ctx)
 	if err != nil {
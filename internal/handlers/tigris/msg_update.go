@@ -106,7 +106,9 @@ func (h *Handler) MsgUpdate(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg,
 			return nil, err
 		}
 
-		fetchedDocs, err := h.db.QueryDocuments(ctx, fp)
+		fp.Filter = q
+
+		fetchedDocs, err := h.db.QueryDocuments(ctx, h.db.Driver.UseDatabase(fp.DB), fp)
 		if err != nil {
 			return nil, err
 		}
@@ -132,7 +134,14 @@ func (h *Handler) MsgUpdate(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg,
 			}
 
 			doc := q.DeepCopy()
-			if _, err = common.UpdateDocument(doc, u); err != nil {
+
+			// the positional operator can't be resolved for the inserted document; that returns an error
+			du, err := common.ResolvePositionalOperator(doc, q, u)
+			if err != nil {
+				return nil, err
+			}
+
+			if _, err = common.UpdateDocument(doc, du); err != nil {
 				return nil, err
 			}
 			if !doc.Has("_id") {
@@ -144,7 +153,7 @@ func (h *Handler) MsgUpdate(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg,
 				"_id", must.NotFail(doc.Get("_id")),
 			))))
 
-			if err = h.insert(ctx, fp, doc); err != nil {
+			if err = h.insert(ctx, h.db.Driver.UseDatabase(fp.DB), fp, doc); err != nil {
 				return nil, err
 			}
 
@@ -159,7 +168,12 @@ func (h *Handler) MsgUpdate(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg,
 		matched += int32(len(resDocs))
 
 		for _, doc := range resDocs {
-			changed, err := common.UpdateDocument(doc, u)
+			du, err := common.ResolvePositionalOperator(doc, q, u)
+			if err != nil {
+				return nil, err
+			}
+
+			changed, err := common.UpdateDocument(doc, du)
 			if err != nil {
 				return nil, err
 			}
@@ -168,7 +182,7 @@ func (h *Handler) MsgUpdate(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg,
 				continue
 			}
 
-			res, err := h.update(ctx, fp, doc)
+			res, err := h.update(ctx, h.db.Driver.UseDatabase(fp.DB), fp, doc)
 			if err != nil {
 				return nil, err
 			}
@@ -197,14 +211,14 @@ func (h *Handler) MsgUpdate(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg,
 }
 
 // update replaces given document.
-func (h *Handler) update(ctx context.Context, fp tigrisdb.FetchParam, doc *types.Document) (int, error) {
+func (h *Handler) update(ctx context.Context, querier driver.Database, fp tigrisdb.FetchParam, doc *types.Document) (int, error) {
 	u, err := tjson.Marshal(doc)
 	if err != nil {
 		return 0, lazyerrors.Error(err)
 	}
 	h.L.Sugar().Debugf("Update: %s", u)
 
-	_, err = h.db.Driver.UseDatabase(fp.DB).Replace(ctx, fp.Collection, []driver.Document{u})
+	_, err = querier.Replace(ctx, fp.Collection, []driver.Document{u})
 	switch err := err.(type) {
 	case nil:
 		return 1, nil
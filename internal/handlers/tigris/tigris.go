@@ -36,6 +36,14 @@ func notImplemented(command string) error {
 type NewOpts struct {
 	TigrisURL string
 	L         *zap.Logger
+
+	// MaxDocumentDepth is the maximum nesting depth of inserted documents;
+	// zero value means common.DefaultMaxDocumentDepth.
+	MaxDocumentDepth int
+
+	// DefaultBatchSize is the number of documents in the first batch of find and aggregate cursors
+	// when the client does not specify it; zero value means common.DefaultBatchSize.
+	DefaultBatchSize int32
 }
 
 // Handler implements handlers.Interface on top of Tigris.
@@ -43,6 +51,7 @@ type Handler struct {
 	*NewOpts
 	db        *tigrisdb.TigrisDB
 	startTime time.Time
+	cursors   *common.Cursors
 }
 
 // New returns a new handler.
@@ -59,6 +68,7 @@ func New(opts *NewOpts) (handlers.Interface, error) {
 		NewOpts:   opts,
 		db:        db,
 		startTime: time.Now(),
+		cursors:   common.NewCursors(opts.DefaultBatchSize),
 	}
 	return h, nil
 }
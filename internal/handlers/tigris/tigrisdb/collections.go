@@ -19,14 +19,16 @@ import (
 
 	"github.com/tigrisdata/tigris-client-go/driver"
 
+	"github.com/FerretDB/FerretDB/internal/tjson"
 	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
+	"github.com/FerretDB/FerretDB/internal/util/must"
 )
 
 // CreateCollectionIfNotExist ensures that given collection exist.
 // If needed, it creates both database and collection.
 // It returns true if the collection was created.
 func (tdb *TigrisDB) CreateCollectionIfNotExist(ctx context.Context, db, collection string, schema driver.Schema) (bool, error) {
-	_, err := tdb.createDatabaseIfNotExists(ctx, db)
+	_, err := tdb.CreateDatabaseIfNotExists(ctx, db)
 	if err != nil {
 		return false, lazyerrors.Error(err)
 	}
@@ -71,3 +73,99 @@ func (tdb *TigrisDB) collectionExists(ctx context.Context, db, collection string
 		return false, lazyerrors.Error(err)
 	}
 }
+
+// AddSchemaProperties adds properties of the given schemas that are absent in the collection schema.
+// It returns true if the collection schema was updated.
+//
+// The collection is described once, all schemas are merged in memory,
+// and the collection schema is updated at most once, only if it actually changed.
+// A schema is merged only when it is safe to do so:
+// properties present in both schemas must have equal subschemas (or be objects with compatible properties).
+// Otherwise, that schema is skipped, and the subsequent insert is expected to fail validation.
+func (tdb *TigrisDB) AddSchemaProperties(ctx context.Context, db, collection string, schemas ...*tjson.Schema) (bool, error) {
+	if len(schemas) == 0 {
+		return false, nil
+	}
+
+	info, err := tdb.Driver.UseDatabase(db).DescribeCollection(ctx, collection)
+	if err != nil {
+		return false, lazyerrors.Error(err)
+	}
+
+	var current tjson.Schema
+	if err = current.Unmarshal(info.Schema); err != nil {
+		return false, lazyerrors.Error(err)
+	}
+
+	var changed bool
+
+	for _, schema := range schemas {
+		// addProperties may partially change dst on incompatible schemas, so merge into a copy
+		var merged tjson.Schema
+		if err = merged.Unmarshal(must.NotFail(current.Marshal())); err != nil {
+			return false, lazyerrors.Error(err)
+		}
+
+		c, compatible := addProperties(&merged, schema)
+		if !c || !compatible {
+			continue
+		}
+
+		current = merged
+		changed = true
+	}
+
+	if !changed {
+		return false, nil
+	}
+
+	b, err := current.Marshal()
+	if err != nil {
+		return false, lazyerrors.Error(err)
+	}
+
+	tdb.L.Sugar().Debugf("Updated schema:\n%s", b)
+
+	if err = tdb.Driver.UseDatabase(db).CreateOrUpdateCollection(ctx, collection, b); err != nil {
+		return false, lazyerrors.Error(err)
+	}
+
+	return true, nil
+}
+
+// addProperties adds properties of src that are absent in dst to dst, recursing into object properties.
+// It returns true as the first value if dst was changed,
+// and false as the second value if schemas have incompatible properties;
+// in that case dst may be partially changed and should be discarded.
+func addProperties(dst, src *tjson.Schema) (changed, compatible bool) {
+	for k, s := range src.Properties {
+		d, ok := dst.Properties[k]
+		if !ok {
+			if dst.Properties == nil {
+				dst.Properties = make(map[string]*tjson.Schema, len(src.Properties))
+			}
+
+			dst.Properties[k] = s
+			changed = true
+
+			continue
+		}
+
+		if d.Type == tjson.Object && s.Type == tjson.Object {
+			c, ok := addProperties(d, s)
+			if !ok {
+				return false, false
+			}
+
+			changed = changed || c
+
+			continue
+		}
+
+		if !d.Equal(s) {
+			return false, false
+		}
+	}
+
+	return changed, true
+}
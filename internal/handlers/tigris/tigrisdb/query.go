@@ -16,6 +16,9 @@ package tigrisdb
 
 import (
 	"context"
+	"encoding/json"
+	"math"
+	"strings"
 
 	"github.com/tigrisdata/tigris-client-go/driver"
 	"go.uber.org/zap"
@@ -23,19 +26,27 @@ import (
 	"github.com/FerretDB/FerretDB/internal/tjson"
 	"github.com/FerretDB/FerretDB/internal/types"
 	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
+	"github.com/FerretDB/FerretDB/internal/util/must"
 )
 
 // FetchParam represents options/parameters used by the fetch/query.
 type FetchParam struct {
 	DB         string
 	Collection string
+
+	// Filter is the query filter; supported conditions are pushed down to Tigris.
+	// The caller should still apply the whole filter to fetched documents.
+	Filter *types.Document
 }
 
 // QueryDocuments fetches documents from the given collection.
-func (tdb *TigrisDB) QueryDocuments(ctx context.Context, param FetchParam) ([]*types.Document, error) {
-	db := tdb.Driver.UseDatabase(param.DB)
-
-	collection, err := db.DescribeCollection(ctx, param.Collection)
+// Documents are read using the given querier: either the database returned by Driver.UseDatabase
+// or a transaction returned by Driver.BeginTx.
+//
+// Conditions of param.Filter that could be handled by Tigris (see buildFilter) are pushed down,
+// so the result may contain fewer documents than the whole collection, but never fewer than the filter matches.
+func (tdb *TigrisDB) QueryDocuments(ctx context.Context, querier driver.Database, param FetchParam) ([]*types.Document, error) {
+	collection, err := tdb.Driver.UseDatabase(param.DB).DescribeCollection(ctx, param.Collection)
 	switch err := err.(type) {
 	case nil:
 		// do nothing
@@ -59,7 +70,10 @@ func (tdb *TigrisDB) QueryDocuments(ctx context.Context, param FetchParam) ([]*t
 		return nil, lazyerrors.Error(err)
 	}
 
-	iter, err := db.Read(ctx, param.Collection, driver.Filter(`{}`), nil)
+	filter := buildFilter(param.Filter, &schema)
+	tdb.L.Sugar().Debugf("Read filter: %s", filter)
+
+	iter, err := querier.Read(ctx, param.Collection, filter, nil)
 	if err != nil {
 		return nil, lazyerrors.Error(err)
 	}
@@ -79,3 +93,135 @@ func (tdb *TigrisDB) QueryDocuments(ctx context.Context, param FetchParam) ([]*t
 
 	return res, iter.Err()
 }
+
+// buildFilter returns Tigris filter for conditions of the given filter that could be pushed down.
+//
+// Only top-level fields with $eq, $gt, $lt, and $in operators (and implicit equality) are supported,
+// and only if the value type matches the field type in the collection schema;
+// all other conditions are left for the in-memory filtering.
+func buildFilter(filter *types.Document, schema *tjson.Schema) driver.Filter {
+	if filter == nil {
+		return driver.Filter(`{}`)
+	}
+
+	var conds []map[string]any
+
+	for _, k := range filter.Keys() {
+		if k == "" || strings.HasPrefix(k, "$") || strings.Contains(k, ".") {
+			continue
+		}
+
+		fieldSchema, ok := schema.Properties[k]
+		if !ok {
+			continue
+		}
+
+		v := must.NotFail(filter.Get(k))
+
+		expr, ok := v.(*types.Document)
+		if !ok {
+			if val, ok := pushdownValue(v, fieldSchema); ok {
+				conds = append(conds, map[string]any{k: map[string]json.RawMessage{"$eq": val}})
+			}
+
+			continue
+		}
+
+		for _, op := range expr.Keys() {
+			opValue := must.NotFail(expr.Get(op))
+
+			switch op {
+			case "$eq", "$gt", "$lt":
+				if val, ok := pushdownValue(opValue, fieldSchema); ok {
+					conds = append(conds, map[string]any{k: map[string]json.RawMessage{op: val}})
+				}
+
+			case "$in":
+				if cond, ok := pushdownIn(k, opValue, fieldSchema); ok {
+					conds = append(conds, cond)
+				}
+			}
+		}
+	}
+
+	switch len(conds) {
+	case 0:
+		return driver.Filter(`{}`)
+	case 1:
+		return must.NotFail(json.Marshal(conds[0]))
+	default:
+		return must.NotFail(json.Marshal(map[string]any{"$and": conds}))
+	}
+}
+
+// pushdownIn returns Tigris condition for $in operator with the given value.
+// All array elements should be pushed down, otherwise the condition is not returned.
+func pushdownIn(key string, v any, fieldSchema *tjson.Schema) (map[string]any, bool) {
+	arr, ok := v.(*types.Array)
+	if !ok || arr.Len() == 0 {
+		return nil, false
+	}
+
+	or := make([]map[string]any, arr.Len())
+	for i := 0; i < arr.Len(); i++ {
+		val, ok := pushdownValue(must.NotFail(arr.Get(i)), fieldSchema)
+		if !ok {
+			return nil, false
+		}
+
+		or[i] = map[string]any{key: map[string]json.RawMessage{"$eq": val}}
+	}
+
+	if len(or) == 1 {
+		return or[0], true
+	}
+
+	return map[string]any{"$or": or}, true
+}
+
+// pushdownValue returns tjson-encoded value if it could be used in Tigris filter
+// for the field with the given schema.
+//
+// Values of other types would be compared differently by Tigris (or not compared at all),
+// so they are not pushed down.
+func pushdownValue(v any, fieldSchema *tjson.Schema) (json.RawMessage, bool) {
+	var valueSchema *tjson.Schema
+
+	switch v := v.(type) {
+	case float64:
+		if math.IsNaN(v) {
+			return nil, false
+		}
+		valueSchema = &tjson.Schema{Type: tjson.Number, Format: tjson.Double}
+	case string:
+		valueSchema = &tjson.Schema{Type: tjson.String}
+	case types.ObjectID:
+		valueSchema = &tjson.Schema{Type: tjson.String, Format: tjson.Byte}
+	case bool:
+		valueSchema = &tjson.Schema{Type: tjson.Boolean}
+	case int32:
+		valueSchema = &tjson.Schema{Type: tjson.Integer, Format: tjson.Int32}
+	case int64:
+		valueSchema = &tjson.Schema{Type: tjson.Integer, Format: tjson.Int64}
+	default:
+		return nil, false
+	}
+
+	switch fieldSchema.Type {
+	case tjson.Integer, tjson.Number, tjson.String, tjson.Boolean:
+		// scalar field, compare below
+	default:
+		return nil, false
+	}
+
+	if !valueSchema.Equal(fieldSchema) {
+		return nil, false
+	}
+
+	b, err := tjson.Marshal(v)
+	if err != nil {
+		return nil, false
+	}
+
+	return b, true
+}
@@ -0,0 +1,159 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tigrisdb
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tigrisdata/tigris-client-go/config"
+	"github.com/tigrisdata/tigris-client-go/driver"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest"
+
+	"github.com/FerretDB/FerretDB/internal/handlers/common"
+	"github.com/FerretDB/FerretDB/internal/tjson"
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+	"github.com/FerretDB/FerretDB/internal/util/testutil"
+)
+
+// setupCollection creates a collection with the given documents and returns TigrisDB and database name.
+func setupCollection(t *testing.T, collName string, docs []*types.Document) (*TigrisDB, string) {
+	t.Helper()
+
+	ctx := testutil.Ctx(t)
+	cfg := &config.Driver{
+		URL: testutil.TigrisURL(t),
+	}
+
+	logger := zaptest.NewLogger(t, zaptest.WrapOptions(zap.AddCaller()))
+	tdb, err := New(cfg, logger)
+	require.NoError(t, err)
+
+	dbName := testutil.DatabaseName(t)
+
+	t.Cleanup(func() {
+		require.NoError(t, tdb.Driver.DropDatabase(ctx, dbName))
+	})
+
+	schema, err := tjson.DocumentSchema(docs[0])
+	require.NoError(t, err)
+	schema.Title = collName
+
+	_, err = tdb.CreateCollectionIfNotExist(ctx, dbName, collName, must.NotFail(schema.Marshal()))
+	require.NoError(t, err)
+
+	for _, doc := range docs {
+		_, err = tdb.Driver.UseDatabase(dbName).Insert(ctx, collName, []driver.Document{must.NotFail(tjson.Marshal(doc))})
+		require.NoError(t, err)
+	}
+
+	return tdb, dbName
+}
+
+// filteredIDs returns _id values of documents matching the given filter.
+func filteredIDs(t *testing.T, docs []*types.Document, filter *types.Document) []any {
+	t.Helper()
+
+	var res []any
+	for _, doc := range docs {
+		matches, err := common.FilterDocument(doc, filter)
+		require.NoError(t, err)
+
+		if matches {
+			res = append(res, must.NotFail(doc.Get("_id")))
+		}
+	}
+
+	return res
+}
+
+func TestQueryDocumentsPushdown(t *testing.T) {
+	t.Parallel()
+
+	ctx := testutil.Ctx(t)
+	collName := testutil.CollectionName(t)
+
+	docs := []*types.Document{
+		must.NotFail(types.NewDocument("_id", "a", "v", int32(1), "s", "foo")),
+		must.NotFail(types.NewDocument("_id", "b", "v", int32(2), "s", "bar")),
+		must.NotFail(types.NewDocument("_id", "c", "v", int32(3), "s", "baz")),
+		must.NotFail(types.NewDocument("_id", "d", "v", int32(42), "s", "foo")),
+	}
+	tdb, dbName := setupCollection(t, collName, docs)
+
+	all, err := tdb.QueryDocuments(ctx, tdb.Driver.UseDatabase(dbName), FetchParam{DB: dbName, Collection: collName})
+	require.NoError(t, err)
+	require.Len(t, all, len(docs))
+
+	for name, tc := range map[string]struct {
+		filter   *types.Document
+		pushdown bool // true if at least one condition is pushed down
+	}{
+		"Implicit": {
+			filter:   must.NotFail(types.NewDocument("s", "foo")),
+			pushdown: true,
+		},
+		"Eq": {
+			filter:   must.NotFail(types.NewDocument("v", must.NotFail(types.NewDocument("$eq", int32(2))))),
+			pushdown: true,
+		},
+		"GtLt": {
+			filter: must.NotFail(types.NewDocument(
+				"v", must.NotFail(types.NewDocument("$gt", int32(1), "$lt", int32(42))),
+			)),
+			pushdown: true,
+		},
+		"In": {
+			filter: must.NotFail(types.NewDocument(
+				"s", must.NotFail(types.NewDocument("$in", must.NotFail(types.NewArray("foo", "baz")))),
+			)),
+			pushdown: true,
+		},
+		"PartiallyPushed": {
+			filter: must.NotFail(types.NewDocument(
+				"s", "foo",
+				"v", must.NotFail(types.NewDocument("$ne", int32(1))),
+			)),
+			pushdown: true,
+		},
+		"TypeMismatch": {
+			filter:   must.NotFail(types.NewDocument("v", must.NotFail(types.NewDocument("$gt", float64(1))))),
+			pushdown: false,
+		},
+		"UnknownField": {
+			filter:   must.NotFail(types.NewDocument("foo", "bar")),
+			pushdown: false,
+		},
+	} {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			pushed, err := tdb.QueryDocuments(ctx, tdb.Driver.UseDatabase(dbName), FetchParam{DB: dbName, Collection: collName, Filter: tc.filter})
+			require.NoError(t, err)
+
+			assert.ElementsMatch(t, filteredIDs(t, all, tc.filter), filteredIDs(t, pushed, tc.filter))
+
+			if tc.pushdown {
+				assert.Less(t, len(pushed), len(all))
+			} else {
+				assert.Len(t, pushed, len(all))
+			}
+		})
+	}
+}
@@ -43,3 +43,36 @@ func New(cfg *config.Driver, logger *zap.Logger) (*TigrisDB, error) {
 		L:      logger,
 	}, nil
 }
+
+// InTransaction wraps the given function f in a transaction for the given database.
+// If f returns an error, the transaction is rolled back.
+// Errors are wrapped with lazyerrors.Error,
+// so the caller needs to use errors.As to check the error.
+func (tdb *TigrisDB) InTransaction(ctx context.Context, db string, f func(driver.Tx) error) (err error) {
+	var tx driver.Tx
+	if tx, err = tdb.Driver.BeginTx(ctx, db); err != nil {
+		err = lazyerrors.Error(err)
+		return
+	}
+
+	defer func() {
+		if err == nil {
+			return
+		}
+		if rerr := tx.Rollback(ctx); rerr != nil {
+			tdb.L.Error("failed to perform rollback", zap.Error(rerr))
+		}
+	}()
+
+	if err = f(tx); err != nil {
+		err = lazyerrors.Error(err)
+		return
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		err = lazyerrors.Error(err)
+		return
+	}
+
+	return
+}
@@ -74,7 +74,7 @@ func TestCreateCollectionIfNotExist(t *testing.T) {
 			require.NoError(t, tdb.Driver.DropDatabase(ctx, dbName))
 		})
 
-		created, err := tdb.createDatabaseIfNotExists(ctx, dbName)
+		created, err := tdb.CreateDatabaseIfNotExists(ctx, dbName)
 		require.NoError(t, err)
 		assert.False(t, created)
 
@@ -104,7 +104,7 @@ func TestCreateCollectionIfNotExist(t *testing.T) {
 			require.NoError(t, tdb.Driver.DropDatabase(ctx, dbName))
 		})
 
-		created, err := tdb.createDatabaseIfNotExists(ctx, dbName)
+		created, err := tdb.CreateDatabaseIfNotExists(ctx, dbName)
 		require.NoError(t, err)
 		assert.False(t, created)
 
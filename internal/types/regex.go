@@ -18,12 +18,10 @@ import (
 	"fmt"
 	"regexp"
 	"regexp/syntax"
+	"strings"
 )
 
 var (
-	// ErrOptionNotImplemented indicates unimplemented regex option.
-	ErrOptionNotImplemented = fmt.Errorf("regex: option not implemented")
-
 	// ErrMissingParen indicates missing parentheses in regex expression.
 	ErrMissingParen = fmt.Errorf("Regular expression is invalid: missing )")
 
@@ -62,21 +60,28 @@ type Regex struct {
 }
 
 // Compile returns Go Regexp object.
+//
+// Options i, m, and s are passed to Go as flags; option x is handled by removing
+// whitespace and comments from the pattern; other options are ignored.
 func (r Regex) Compile() (*regexp.Regexp, error) {
 	var opts string
+	var extended bool
 	for _, o := range r.Options {
 		switch o {
 		case 'i', 'm', 's':
 			opts += string(o)
 		case 'x':
-			// TODO: https://github.com/FerretDB/FerretDB/issues/592
-			return nil, ErrOptionNotImplemented
+			extended = true
 		default:
 			continue
 		}
 	}
 
 	expr := r.Pattern
+	if extended {
+		expr = stripExtended(expr)
+	}
+
 	if opts != "" {
 		expr = "(?" + opts + ")" + expr
 	}
@@ -116,3 +121,76 @@ func (r Regex) Compile() (*regexp.Regexp, error) {
 	}
 	return nil, fmt.Errorf("types.Regex.Compile: %w", err)
 }
+
+// stripExtended returns the pattern without unescaped whitespace and #-comments
+// outside of character classes, as PCRE does for the extended (x) option.
+func stripExtended(pattern string) string {
+	var res strings.Builder
+	var inClass, inComment bool
+
+	for i := 0; i < len(pattern); i++ {
+		c := pattern[i]
+
+		switch {
+		case inComment:
+			if c == '\n' {
+				inComment = false
+			}
+
+		case c == '\\':
+			if i+1 == len(pattern) {
+				res.WriteByte(c)
+				break
+			}
+
+			// Go does not allow escaping whitespace, but it is a literal without a backslash
+			i++
+			if !isExtendedSpace(pattern[i]) {
+				res.WriteByte(c)
+			}
+			res.WriteByte(pattern[i])
+
+		case inClass:
+			if c == ']' {
+				inClass = false
+			}
+
+			res.WriteByte(c)
+
+		case c == '[':
+			inClass = true
+			res.WriteByte(c)
+
+			// ] right after [ or [^ is a literal
+			if i+1 < len(pattern) && pattern[i+1] == '^' {
+				i++
+				res.WriteByte(pattern[i])
+			}
+			if i+1 < len(pattern) && pattern[i+1] == ']' {
+				i++
+				res.WriteByte(pattern[i])
+			}
+
+		case c == '#':
+			inComment = true
+
+		case isExtendedSpace(c):
+			// skip
+
+		default:
+			res.WriteByte(c)
+		}
+	}
+
+	return res.String()
+}
+
+// isExtendedSpace returns true if c is a whitespace character ignored by the extended (x) option.
+func isExtendedSpace(c byte) bool {
+	switch c {
+	case ' ', '\t', '\n', '\r', '\f', '\v':
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,99 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package types
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRegexCompile(t *testing.T) {
+	t.Parallel()
+
+	for name, tc := range map[string]struct {
+		regex    Regex
+		match    []string
+		notMatch []string
+		err      error
+	}{
+		"NoOptions": {
+			regex:    Regex{Pattern: "^foo"},
+			match:    []string{"foo", "foobar"},
+			notMatch: []string{"Foo", "bar\nfoo"},
+		},
+		"CaseInsensitive": {
+			regex: Regex{Pattern: "^foo", Options: "i"},
+			match: []string{"FOO", "Foo"},
+		},
+		"Multiline": {
+			regex:    Regex{Pattern: "^foo$", Options: "m"},
+			match:    []string{"bar\nfoo"},
+			notMatch: []string{"bar\nfoobar"},
+		},
+		"DotAll": {
+			regex:    Regex{Pattern: "bar.foo", Options: "s"},
+			match:    []string{"bar\nfoo"},
+			notMatch: []string{"barfoo"},
+		},
+		"Extended": {
+			regex:    Regex{Pattern: "^ f o o # comment\n b a r $", Options: "x"},
+			match:    []string{"foobar"},
+			notMatch: []string{"foo bar"},
+		},
+		"ExtendedEscapedSpace": {
+			regex:    Regex{Pattern: `^foo\ bar\#$`, Options: "x"},
+			match:    []string{"foo bar#"},
+			notMatch: []string{"foobar#"},
+		},
+		"ExtendedClass": {
+			regex:    Regex{Pattern: "^[ #]+$", Options: "x"},
+			match:    []string{" #"},
+			notMatch: []string{"a"},
+		},
+		"ExtendedClassBracket": {
+			regex: Regex{Pattern: "^[] ]+ x$", Options: "xi"},
+			match: []string{"] X", "]]x"},
+		},
+		"UnknownOption": {
+			regex: Regex{Pattern: "foo", Options: "123"},
+			match: []string{"foo"},
+		},
+		"Invalid": {
+			regex: Regex{Pattern: "(foo"},
+			err:   ErrMissingParen,
+		},
+	} {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			re, err := tc.regex.Compile()
+			if tc.err != nil {
+				assert.Equal(t, tc.err, err)
+				return
+			}
+			require.NoError(t, err)
+
+			for _, s := range tc.match {
+				assert.True(t, re.MatchString(s), "%q should match %q", tc.regex.Pattern, s)
+			}
+			for _, s := range tc.notMatch {
+				assert.False(t, re.MatchString(s), "%q should not match %q", tc.regex.Pattern, s)
+			}
+		})
+	}
+}
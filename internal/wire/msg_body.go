@@ -75,6 +75,14 @@ func ReadMessage(r *bufio.Reader) (*MsgHeader, MsgBody, error) {
 
 		return &header, &query, nil
 
+	case OpCodeCompressed:
+		var compressed OpCompressed
+		if err := compressed.UnmarshalBinary(b); err != nil {
+			return nil, nil, lazyerrors.Error(err)
+		}
+
+		return &header, &compressed, nil
+
 	case OpCodeUpdate:
 		fallthrough
 	case OpCodeInsert:
@@ -86,8 +94,6 @@ func ReadMessage(r *bufio.Reader) (*MsgHeader, MsgBody, error) {
 	case OpCodeDelete:
 		fallthrough
 	case OpCodeKillCursors:
-		fallthrough
-	case OpCodeCompressed:
 		return nil, nil, lazyerrors.Errorf("unhandled opcode %s", header.OpCode)
 
 	default:
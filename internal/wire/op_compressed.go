@@ -0,0 +1,330 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package wire
+
+import (
+	"bufio"
+	"bytes"
+	"compress/zlib"
+	"encoding/binary"
+	"encoding/json"
+	"io"
+
+	"github.com/klauspost/compress/zstd"
+
+	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+)
+
+//go:generate ../../bin/stringer -linecomment -type CompressorID
+
+// CompressorID represents OP_COMPRESSED compressor identifier.
+type CompressorID uint8
+
+const (
+	// CompressorNoop does not compress messages.
+	CompressorNoop = CompressorID(0) // noop
+
+	// CompressorSnappy is not implemented yet.
+	CompressorSnappy = CompressorID(1) // snappy
+
+	// CompressorZlib compresses messages with zlib.
+	CompressorZlib = CompressorID(2) // zlib
+
+	// CompressorZstd compresses messages with zstd.
+	CompressorZstd = CompressorID(3) // zstd
+)
+
+// supportedCompressors contains compressors that could be negotiated with clients,
+// in the server's order of preference.
+var supportedCompressors = []CompressorID{CompressorZstd, CompressorZlib}
+
+var (
+	// zstdEncoder is used for compressing messages with zstd; it is safe for concurrent use with EncodeAll.
+	zstdEncoder = must.NotFail(zstd.NewWriter(nil))
+
+	// zstdDecoder is used for decompressing messages with zstd; it is safe for concurrent use with DecodeAll.
+	zstdDecoder = must.NotFail(zstd.NewReader(nil, zstd.WithDecoderMaxMemory(MaxMsgLen)))
+)
+
+// NegotiateCompressors returns names of compressors requested by the client that are supported,
+// in the client's order of preference.
+//
+// Unknown and unsupported names are ignored.
+func NegotiateCompressors(requested []string) []string {
+	var res []string
+
+	for _, name := range requested {
+		for _, c := range supportedCompressors {
+			if c.String() == name {
+				res = append(res, name)
+				break
+			}
+		}
+	}
+
+	return res
+}
+
+// OpCompressed is a message that wraps another message compressed with one of compressors.
+type OpCompressed struct {
+	OriginalOpCode OpCode
+	CompressorID   CompressorID
+
+	// msg is a wrapped message
+	msg MsgBody
+
+	// compressed is a compressed wrapped message as it was received;
+	// it is nil for messages created by NewOpCompressed
+	compressed []byte
+}
+
+// NewOpCompressed returns a new OP_COMPRESSED message that wraps the given OP_MSG, OP_QUERY, or OP_REPLY message
+// compressed with the given compressor.
+func NewOpCompressed(compressorID CompressorID, msg MsgBody) (*OpCompressed, error) {
+	var opCode OpCode
+
+	switch msg.(type) {
+	case *OpMsg:
+		opCode = OpCodeMsg
+	case *OpQuery:
+		opCode = OpCodeQuery
+	case *OpReply:
+		opCode = OpCodeReply
+	default:
+		return nil, lazyerrors.Errorf("wire.NewOpCompressed: unexpected message type %T", msg)
+	}
+
+	return &OpCompressed{
+		OriginalOpCode: opCode,
+		CompressorID:   compressorID,
+		msg:            msg,
+	}, nil
+}
+
+// Message returns the wrapped message.
+func (msg *OpCompressed) Message() MsgBody {
+	return msg.msg
+}
+
+func (msg *OpCompressed) msgbody() {}
+
+func (msg *OpCompressed) readFrom(bufr *bufio.Reader) error {
+	if err := binary.Read(bufr, binary.LittleEndian, &msg.OriginalOpCode); err != nil {
+		return lazyerrors.Error(err)
+	}
+
+	var uncompressedSize int32
+	if err := binary.Read(bufr, binary.LittleEndian, &uncompressedSize); err != nil {
+		return lazyerrors.Error(err)
+	}
+
+	if uncompressedSize <= 0 || uncompressedSize > MaxMsgLen-MsgHeaderLen {
+		return lazyerrors.Errorf("wire.OpCompressed.readFrom: invalid uncompressed size %d", uncompressedSize)
+	}
+
+	if err := binary.Read(bufr, binary.LittleEndian, &msg.CompressorID); err != nil {
+		return lazyerrors.Error(err)
+	}
+
+	compressed, err := io.ReadAll(bufr)
+	if err != nil {
+		return lazyerrors.Error(err)
+	}
+
+	b, err := decompress(msg.CompressorID, compressed, int(uncompressedSize))
+	if err != nil {
+		return lazyerrors.Error(err)
+	}
+
+	switch msg.OriginalOpCode {
+	case OpCodeMsg:
+		msg.msg = new(OpMsg)
+	case OpCodeQuery:
+		msg.msg = new(OpQuery)
+	case OpCodeReply:
+		msg.msg = new(OpReply)
+	default:
+		return lazyerrors.Errorf("wire.OpCompressed.readFrom: unexpected original opcode %s", msg.OriginalOpCode)
+	}
+
+	if err = msg.msg.UnmarshalBinary(b); err != nil {
+		return lazyerrors.Error(err)
+	}
+
+	msg.compressed = compressed
+
+	return nil
+}
+
+// UnmarshalBinary reads an OpCompressed from a byte array.
+func (msg *OpCompressed) UnmarshalBinary(b []byte) error {
+	br := bytes.NewReader(b)
+	bufr := bufio.NewReader(br)
+
+	if err := msg.readFrom(bufr); err != nil {
+		return lazyerrors.Error(err)
+	}
+
+	if _, err := bufr.Peek(1); err != io.EOF {
+		return lazyerrors.Errorf("unexpected end of the OpCompressed: %v", err)
+	}
+
+	return nil
+}
+
+// MarshalBinary writes an OpCompressed to a byte array.
+//
+// The received message is written as it was received;
+// the message created by NewOpCompressed is compressed.
+func (msg *OpCompressed) MarshalBinary() ([]byte, error) {
+	b, err := msg.msg.MarshalBinary()
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	compressed := msg.compressed
+	if compressed == nil {
+		if compressed, err = compress(msg.CompressorID, b); err != nil {
+			return nil, lazyerrors.Error(err)
+		}
+	}
+
+	var buf bytes.Buffer
+	bufw := bufio.NewWriter(&buf)
+
+	if err := binary.Write(bufw, binary.LittleEndian, msg.OriginalOpCode); err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	if err := binary.Write(bufw, binary.LittleEndian, int32(len(b))); err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	if err := binary.Write(bufw, binary.LittleEndian, msg.CompressorID); err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	if _, err := bufw.Write(compressed); err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	if err := bufw.Flush(); err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	return buf.Bytes(), nil
+}
+
+// String returns a string representation for logging.
+func (msg *OpCompressed) String() string {
+	if msg == nil {
+		return "<nil>"
+	}
+
+	m := map[string]any{
+		"OriginalOpCode": msg.OriginalOpCode.String(),
+		"CompressorID":   msg.CompressorID.String(),
+	}
+
+	if msg.msg != nil {
+		m["Message"] = json.RawMessage(msg.msg.String())
+	}
+
+	return string(must.NotFail(json.MarshalIndent(m, "", "  ")))
+}
+
+// compress compresses the given message body with the given compressor.
+func compress(compressorID CompressorID, b []byte) ([]byte, error) {
+	switch compressorID {
+	case CompressorNoop:
+		return b, nil
+
+	case CompressorZlib:
+		var buf bytes.Buffer
+
+		w := zlib.NewWriter(&buf)
+		if _, err := w.Write(b); err != nil {
+			return nil, lazyerrors.Error(err)
+		}
+
+		if err := w.Close(); err != nil {
+			return nil, lazyerrors.Error(err)
+		}
+
+		return buf.Bytes(), nil
+
+	case CompressorZstd:
+		return zstdEncoder.EncodeAll(b, nil), nil
+
+	case CompressorSnappy:
+		fallthrough
+
+	default:
+		return nil, lazyerrors.Errorf("unsupported compressor %s", compressorID)
+	}
+}
+
+// decompress decompresses the given data with the given compressor,
+// checking that the result has the expected size.
+func decompress(compressorID CompressorID, compressed []byte, size int) ([]byte, error) {
+	var r io.Reader
+
+	switch compressorID {
+	case CompressorNoop:
+		r = bytes.NewReader(compressed)
+
+	case CompressorZlib:
+		zr, err := zlib.NewReader(bytes.NewReader(compressed))
+		if err != nil {
+			return nil, lazyerrors.Error(err)
+		}
+
+		defer zr.Close()
+
+		r = zr
+
+	case CompressorZstd:
+		b, err := zstdDecoder.DecodeAll(compressed, make([]byte, 0, size))
+		if err != nil {
+			return nil, lazyerrors.Error(err)
+		}
+
+		r = bytes.NewReader(b)
+
+	case CompressorSnappy:
+		fallthrough
+
+	default:
+		return nil, lazyerrors.Errorf("unsupported compressor %s", compressorID)
+	}
+
+	// read one more byte to detect messages larger than expected without reading them completely
+	b, err := io.ReadAll(io.LimitReader(r, int64(size)+1))
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	if len(b) != size {
+		return nil, lazyerrors.Errorf("expected uncompressed size %d, got %d", size, len(b))
+	}
+
+	return b, nil
+}
+
+// check interfaces
+var (
+	_ MsgBody = (*OpCompressed)(nil)
+)
@@ -0,0 +1,168 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package wire
+
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding/binary"
+	"testing"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/FerretDB/FerretDB/internal/util/must"
+	"github.com/FerretDB/FerretDB/internal/util/testutil"
+)
+
+// handshake5B is a body of OP_MSG message from handshake5 test case.
+var handshake5B = testutil.MustParseDumpFile("testdata", "handshake5_body.hex")
+
+// zlibCompress compresses the given data with zlib.
+func zlibCompress(b []byte) []byte {
+	var buf bytes.Buffer
+	w := zlib.NewWriter(&buf)
+	must.NotFail(w.Write(b))
+	must.NoError(w.Close())
+
+	return buf.Bytes()
+}
+
+// zstdCompress compresses the given data with zstd.
+func zstdCompress(b []byte) []byte {
+	w := must.NotFail(zstd.NewWriter(nil))
+	defer w.Close()
+
+	return w.EncodeAll(b, nil)
+}
+
+// compressedMessage returns OP_COMPRESSED message dump that wraps OP_MSG message body
+// of the given uncompressed size.
+func compressedMessage(requestID int32, compressorID CompressorID, size int, compressed []byte) []byte {
+	var buf bytes.Buffer
+
+	for _, v := range []any{
+		int32(MsgHeaderLen + 9 + len(compressed)), requestID, int32(0), OpCodeCompressed,
+		OpCodeMsg, int32(size), compressorID,
+	} {
+		must.NoError(binary.Write(&buf, binary.LittleEndian, v))
+	}
+
+	must.NotFail(buf.Write(compressed))
+
+	return buf.Bytes()
+}
+
+var compressedTestCases = []testCase{{
+	name:      "zlib",
+	expectedB: compressedMessage(3, CompressorZlib, len(handshake5B), zlibCompress(handshake5B)),
+	msgHeader: &MsgHeader{
+		MessageLength: int32(MsgHeaderLen + 9 + len(zlibCompress(handshake5B))),
+		RequestID:     3,
+		OpCode:        OpCodeCompressed,
+	},
+	msgBody: &OpCompressed{
+		OriginalOpCode: OpCodeMsg,
+		CompressorID:   CompressorZlib,
+		msg:            msgTestCases[0].msgBody,
+		compressed:     zlibCompress(handshake5B),
+	},
+}, {
+	name:      "noop",
+	expectedB: compressedMessage(4, CompressorNoop, len(handshake5B), handshake5B),
+	msgHeader: &MsgHeader{
+		MessageLength: int32(MsgHeaderLen + 9 + len(handshake5B)),
+		RequestID:     4,
+		OpCode:        OpCodeCompressed,
+	},
+	msgBody: &OpCompressed{
+		OriginalOpCode: OpCodeMsg,
+		CompressorID:   CompressorNoop,
+		msg:            msgTestCases[0].msgBody,
+		compressed:     handshake5B,
+	},
+}, {
+	name:      "zstd",
+	expectedB: compressedMessage(7, CompressorZstd, len(handshake5B), zstdCompress(handshake5B)),
+	msgHeader: &MsgHeader{
+		MessageLength: int32(MsgHeaderLen + 9 + len(zstdCompress(handshake5B))),
+		RequestID:     7,
+		OpCode:        OpCodeCompressed,
+	},
+	msgBody: &OpCompressed{
+		OriginalOpCode: OpCodeMsg,
+		CompressorID:   CompressorZstd,
+		msg:            msgTestCases[0].msgBody,
+		compressed:     zstdCompress(handshake5B),
+	},
+}, {
+	name:      "WrongSize",
+	expectedB: compressedMessage(5, CompressorZlib, len(handshake5B)+1, zlibCompress(handshake5B)),
+	err:       `expected uncompressed size 77, got 76`,
+}, {
+	name:      "WrongSizeZstd",
+	expectedB: compressedMessage(8, CompressorZstd, len(handshake5B)-1, zstdCompress(handshake5B)),
+	err:       `expected uncompressed size 75, got 76`,
+}, {
+	name:      "Snappy",
+	expectedB: compressedMessage(6, CompressorSnappy, len(handshake5B), handshake5B),
+	err:       `unsupported compressor snappy`,
+}}
+
+func TestCompressed(t *testing.T) {
+	t.Parallel()
+	testMessages(t, compressedTestCases)
+}
+
+func TestCompressedRoundTrip(t *testing.T) {
+	t.Parallel()
+
+	inner := msgTestCases[0].msgBody.(*OpMsg)
+
+	for _, compressorID := range []CompressorID{CompressorNoop, CompressorZlib, CompressorZstd} {
+		compressorID := compressorID
+		t.Run(compressorID.String(), func(t *testing.T) {
+			t.Parallel()
+
+			msg, err := NewOpCompressed(compressorID, inner)
+			require.NoError(t, err)
+
+			b, err := msg.MarshalBinary()
+			require.NoError(t, err)
+
+			var actual OpCompressed
+			require.NoError(t, actual.UnmarshalBinary(b))
+			assert.Equal(t, OpCodeMsg, actual.OriginalOpCode)
+			assert.Equal(t, compressorID, actual.CompressorID)
+			assert.Equal(t, inner, actual.Message())
+
+			// the received message is written back as is
+			actualB, err := actual.MarshalBinary()
+			require.NoError(t, err)
+			assert.Equal(t, b, actualB)
+		})
+	}
+}
+
+func TestNegotiateCompressors(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, []string{"zlib"}, NegotiateCompressors([]string{"snappy", "zlib", "foo"}))
+	assert.Equal(t, []string{"zlib", "zstd"}, NegotiateCompressors([]string{"zlib", "zstd"}))
+	assert.Equal(t, []string{"zstd", "zlib"}, NegotiateCompressors([]string{"zstd", "snappy", "zlib"}))
+	assert.Nil(t, NegotiateCompressors([]string{"snappy"}))
+	assert.Nil(t, NegotiateCompressors(nil))
+}